@@ -17,31 +17,384 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"slices"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 )
 
 // AzureValidatorSpec defines the desired state of AzureValidator
 type AzureValidatorSpec struct {
 	// Rules for validating that the correct role assignments have been created in Azure RBAC to
 	// provide needed permissions.
+	// +optional
 	// +kubebuilder:validation:MaxItems=5
 	// +kubebuilder:validation:XValidation:message="RBACRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
-	RBACRules []RBACRule `json:"rbacRules" yaml:"rbacRules"`
-	Auth      AzureAuth  `json:"auth" yaml:"auth"`
+	RBACRules []RBACRule `json:"rbacRules,omitempty" yaml:"rbacRules,omitempty"`
+	// Rules for validating that certificates stored in Azure Key Vault exist, are enabled, and
+	// don't expire soon.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="KeyVaultCertificateRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	KeyVaultCertificateRules []KeyVaultCertificateRule `json:"keyVaultCertificateRules,omitempty" yaml:"keyVaultCertificateRules,omitempty"`
+	// Rules for validating that existing AKS clusters are configured as expected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="AKSClusterRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	AKSClusterRules []AKSClusterRule `json:"aksClusterRules,omitempty" yaml:"aksClusterRules,omitempty"`
+	// Rules for validating that subnets have outbound connectivity through a NAT gateway.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="NATGatewayRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	NATGatewayRules []NATGatewayRule `json:"natGatewayRules,omitempty" yaml:"natGatewayRules,omitempty"`
+	// Rules for validating that virtual networks are peered with other virtual networks as
+	// expected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VNetPeeringRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VNetPeeringRules []VNetPeeringRule `json:"vnetPeeringRules,omitempty" yaml:"vnetPeeringRules,omitempty"`
+	// Rules for validating that route tables have the routes that a subnet's traffic requires
+	// (e.g. for forced tunneling through a virtual appliance).
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="RouteTableRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	RouteTableRules []RouteTableRule `json:"routeTableRules,omitempty" yaml:"routeTableRules,omitempty"`
+	// Rules for validating that compute gallery images can run on VM sizes.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ImageCompatibilityRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ImageCompatibilityRules []ImageCompatibilityRule `json:"imageCompatibilityRules,omitempty" yaml:"imageCompatibilityRules,omitempty"`
+	// Rules for validating that compute gallery image versions are replicated to regions.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ImageReplicationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ImageReplicationRules []ImageReplicationRule `json:"imageReplicationRules,omitempty" yaml:"imageReplicationRules,omitempty"`
+	// Rules for validating that VM sizes, and optionally a compute gallery image, support trusted
+	// launch or confidential VMs.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VMSecurityRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VMSecurityRules []VMSecurityRule `json:"vmSecurityRules,omitempty" yaml:"vmSecurityRules,omitempty"`
+	// Rules for validating that VM sizes have the networking and compute capabilities that
+	// workloads need.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VMSizeRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VMSizeRules []VMSizeRule `json:"vmSizeRules,omitempty" yaml:"vmSizeRules,omitempty"`
+	// Rules for validating that disks of a zonal disk type can be attached to VMs of a size in
+	// availability zones.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DiskZoneRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DiskZoneRules []DiskZoneRule `json:"diskZoneRules,omitempty" yaml:"diskZoneRules,omitempty"`
+	// Rules for validating that a principal has the permissions needed to deploy an ARM template.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="TemplatePermissionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	TemplatePermissionRules []TemplatePermissionRule `json:"templatePermissionRules,omitempty" yaml:"templatePermissionRules,omitempty"`
+	// Rules for validating that Azure Policy exemptions exist for a scope and don't expire soon.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="PolicyExemptionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	PolicyExemptionRules []PolicyExemptionRule `json:"policyExemptionRules,omitempty" yaml:"policyExemptionRules,omitempty"`
+	// Rules for validating that Microsoft Defender for Cloud plans are enabled for a subscription.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DefenderPlanRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DefenderPlanRules []DefenderPlanRule `json:"defenderPlanRules,omitempty" yaml:"defenderPlanRules,omitempty"`
+	// Rules for validating that a budget with alerts exists for a subscription or resource group.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BudgetRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BudgetRules []BudgetRule `json:"budgetRules,omitempty" yaml:"budgetRules,omitempty"`
+	// Rules for validating that resource groups and resources have management locks.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ResourceLockRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ResourceLockRules []ResourceLockRule `json:"resourceLockRules,omitempty" yaml:"resourceLockRules,omitempty"`
+	// Rules for validating that Azure Firewall policies allow traffic, e.g. the egress that AKS
+	// clusters need.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="FirewallPolicyRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	FirewallPolicyRules []FirewallPolicyRule `json:"firewallPolicyRules,omitempty" yaml:"firewallPolicyRules,omitempty"`
+	// Rules for validating that virtual networks have an Azure Bastion host, e.g. because clusters
+	// may only be provisioned in virtual networks that can be reached through one.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BastionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BastionRules []BastionRule `json:"bastionRules,omitempty" yaml:"bastionRules,omitempty"`
+	// Rules for validating that virtual networks are protected by a DDoS protection plan.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DDoSProtectionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DDoSProtectionRules []DDoSProtectionRule `json:"ddosProtectionRules,omitempty" yaml:"ddosProtectionRules,omitempty"`
+	// Rules for validating that public IP prefixes have enough free addresses, e.g. for load
+	// balancers that must get their addresses from them.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="PublicIPPrefixRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	PublicIPPrefixRules []PublicIPPrefixRule `json:"publicIPPrefixRules,omitempty" yaml:"publicIPPrefixRules,omitempty"`
+	// Rules for validating that an Event Hubs namespace has an event hub that clients can send to,
+	// e.g. for streaming audit logs.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="EventHubRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	EventHubRules []EventHubRule `json:"eventHubRules,omitempty" yaml:"eventHubRules,omitempty"`
+	// Rules for validating application gateways and their WAF policies, e.g. for clusters that
+	// use the Application Gateway Ingress Controller.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ApplicationGatewayRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ApplicationGatewayRules []ApplicationGatewayRule `json:"applicationGatewayRules,omitempty" yaml:"applicationGatewayRules,omitempty"`
+	// Rules for validating the configuration of pre-provisioned Cosmos DB accounts.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="CosmosDBRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	CosmosDBRules []CosmosDBRule `json:"cosmosDBRules,omitempty" yaml:"cosmosDBRules,omitempty"`
+	// Rules for validating the authentication and network configuration of Azure SQL logical
+	// servers.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="SQLServerRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	SQLServerRules []SQLServerRule `json:"sqlServerRules,omitempty" yaml:"sqlServerRules,omitempty"`
+	// Rules for validating that global entry points, such as Traffic Manager profiles, are
+	// enabled and have healthy endpoints.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="GlobalEndpointRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	GlobalEndpointRules []GlobalEndpointRule `json:"globalEndpointRules,omitempty" yaml:"globalEndpointRules,omitempty"`
+	// Rules for validating that ExpressRoute circuits are provisioned, and optionally that the
+	// virtual network gateway connections to them are connected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ExpressRouteRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ExpressRouteRules []ExpressRouteRule `json:"expressRouteRules,omitempty" yaml:"expressRouteRules,omitempty"`
+	// Rules for validating that VPN gateways are configured as expected and that their
+	// site-to-site connections are connected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VPNGatewayRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VPNGatewayRules []VPNGatewayRule `json:"vpnGatewayRules,omitempty" yaml:"vpnGatewayRules,omitempty"`
+	// Rules for validating that Network Watcher is enabled in a location, and that network
+	// security groups have flow logs configured.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="NetworkWatcherRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	NetworkWatcherRules []NetworkWatcherRule `json:"networkWatcherRules,omitempty" yaml:"networkWatcherRules,omitempty"`
+	// Rules for validating that proximity placement groups are colocated and allow the VM sizes
+	// that will be deployed into them.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ProximityPlacementGroupRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ProximityPlacementGroupRules []ProximityPlacementGroupRule `json:"proximityPlacementGroupRules,omitempty" yaml:"proximityPlacementGroupRules,omitempty"`
+	// Rules for validating that VMs of some sizes can be created with encryption at host.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="EncryptionAtHostRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	EncryptionAtHostRules []EncryptionAtHostRule `json:"encryptionAtHostRules,omitempty" yaml:"encryptionAtHostRules,omitempty"`
+	// Rules for validating that Microsoft Entra ID (Azure AD) groups exist and have members.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="GroupMembershipRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	GroupMembershipRules []GroupMembershipRule `json:"groupMembershipRules,omitempty" yaml:"groupMembershipRules,omitempty"`
+	// Rules for validating that app registrations request API permissions and have admin consent
+	// for them.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="AppPermissionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	AppPermissionRules []AppPermissionRule `json:"appPermissionRules,omitempty" yaml:"appPermissionRules,omitempty"`
+	// Rules for validating that blob containers have locked immutability policies and
+	// lifecycle management rules.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BlobContainerRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BlobContainerRules []BlobContainerRule `json:"blobContainerRules,omitempty" yaml:"blobContainerRules,omitempty"`
+	// Rules for validating that storage accounts deny network access by default, and allow it
+	// from subnets and IP ranges.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="StorageNetworkRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	StorageNetworkRules []StorageNetworkRule `json:"storageNetworkRules,omitempty" yaml:"storageNetworkRules,omitempty"`
+	// Rules for validating that storage accounts have file shares with enough quota and the
+	// expected access tier.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="FileShareRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	FileShareRules []FileShareRule `json:"fileShareRules,omitempty" yaml:"fileShareRules,omitempty"`
+	// Rules for validating that subnets have no conflicting delegations or service association
+	// links, and the expected network policies.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="SubnetDelegationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	SubnetDelegationRules []SubnetDelegationRule `json:"subnetDelegationRules,omitempty" yaml:"subnetDelegationRules,omitempty"`
+	// Rules for validating that Azure Monitor action groups have the expected receivers, and that
+	// metric alert rules exist and are enabled.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="MonitorAlertRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	MonitorAlertRules []MonitorAlertRule `json:"monitorAlertRules,omitempty" yaml:"monitorAlertRules,omitempty"`
+	// Rules for validating that subscriptions export their Activity Log to a Log Analytics
+	// workspace or a storage account.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ActivityLogExportRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ActivityLogExportRules []ActivityLogExportRule `json:"activityLogExportRules,omitempty" yaml:"activityLogExportRules,omitempty"`
+	// Rules for validating that platform VM images aren't deprecated or scheduled for
+	// deprecation.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ImageDeprecationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ImageDeprecationRules []ImageDeprecationRule `json:"imageDeprecationRules,omitempty" yaml:"imageDeprecationRules,omitempty"`
+	// Rules for validating that locations have availability zones for zonal deployments.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ZoneRedundancyRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ZoneRedundancyRules []ZoneRedundancyRule `json:"zoneRedundancyRules,omitempty" yaml:"zoneRedundancyRules,omitempty"`
+	// Rules for validating that Azure Arc custom locations are provisioned, with their cluster
+	// extensions provisioned and their hosts connected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="CustomLocationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	CustomLocationRules []CustomLocationRule `json:"customLocationRules,omitempty" yaml:"customLocationRules,omitempty"`
+	// Rules for validating that Key Vault names are free of, or held by, soft-deleted vaults, and
+	// that vaults have soft delete and purge protection.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="KeyVaultRecoveryRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	KeyVaultRecoveryRules []KeyVaultRecoveryRule `json:"keyVaultRecoveryRules,omitempty" yaml:"keyVaultRecoveryRules,omitempty"`
+	Auth                  AzureAuth              `json:"auth" yaml:"auth"`
+	// Labels set on the AzureValidator's ValidationResult, and added to the details of each
+	// rule's condition so that sinks can include them in notifications. They're re-applied on
+	// each reconcile, so edits to the ValidationResult's labels don't last.
+	// +optional
+	ResultLabels map[string]string `json:"resultLabels,omitempty" yaml:"resultLabels,omitempty"`
+	// If set, a rule's previous result is reused, without querying Azure, until the result is
+	// older than this. Changing the spec always causes all rules to be re-evaluated. If not set,
+	// all rules are re-evaluated on each reconcile.
+	// +optional
+	ResultMaxAge *metav1.Duration `json:"resultMaxAge,omitempty" yaml:"resultMaxAge,omitempty"`
+	// If set, how long a reconcile may spend evaluating rules. Rules that haven't been evaluated
+	// by then fail as not evaluated, and are evaluated first by the next reconcile, which follows
+	// shortly. A rule that is being evaluated when the deadline elapses is finished. If not set,
+	// all rules are evaluated on each reconcile, however long that takes.
+	// +optional
+	RunDeadline *metav1.Duration `json:"runDeadline,omitempty" yaml:"runDeadline,omitempty"`
+	// The severity of rules that don't have one of their own, which only v1alpha2 rules can. Each
+	// rule's condition reports its severity in a detail. A failing rule of severity Low or Medium
+	// is a warning, which doesn't fail the ValidationResult. If not set, rules are High, so any
+	// failing rule fails it.
+	// +optional
+	DefaultSeverity Severity `json:"defaultSeverity,omitempty" yaml:"defaultSeverity,omitempty"`
+	// If true, the AzureValidator is re-validated as soon as a role assignment is created or
+	// deleted for the principal of one of its RBAC or template permission rules, or at one of
+	// their scopes or a scope containing it, rather than at its next scheduled re-validation.
+	// The previous results of those rules aren't reused then. Only takes effect if the plugin
+	// polls the Activity Log (--activity-log-poll-interval).
+	// +optional
+	RevalidateOnRoleAssignmentChanges bool `json:"revalidateOnRoleAssignmentChanges,omitempty" yaml:"revalidateOnRoleAssignmentChanges,omitempty"`
+	// If true, the plugin doesn't check, before evaluating the other rules, that its own identity
+	// can read role assignments and role definitions at the scopes of the RBAC and template
+	// permission rules.
+	// +optional
+	SkipPreflight bool `json:"skipPreflight,omitempty" yaml:"skipPreflight,omitempty"`
+	// If provided, the Azure region (e.g. australiaeast) whose regional ARM endpoint the rules'
+	// ARM requests are sent to, instead of the global endpoint, which may route them to a region
+	// far from the subscriptions. Requests fall back to the global endpoint for a while if the
+	// regional endpoint rejects one. RBAC rules can override it.
+	// +optional
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9]*$`
+	ARMRegion string `json:"armRegion,omitempty" yaml:"armRegion,omitempty"`
+	// ConfigMaps, in the same namespace as the AzureValidator, with more rules. Each key holds a
+	// group of rules, as YAML with the same fields as the spec's rule lists (e.g. rbacRules). They
+	// are merged with the spec's rules on each reconcile. Rules from ConfigMaps aren't limited to 5
+	// per type, but their names must not conflict with other rules of the same type. Whether they
+	// could be loaded is reported in a condition of its own.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	RulesFrom []RulesSource `json:"rulesFrom,omitempty" yaml:"rulesFrom,omitempty"`
+	// If set, each reconcile that evaluates rules writes a snapshot of what they observed in
+	// Azure, e.g. the role assignments of each principal, quotas, and SKU capabilities, as the
+	// JSON of ARM's and Microsoft Graph's responses. Writing it is best effort: failures are
+	// logged, and don't fail validation.
+	// +optional
+	Snapshot *SnapshotConfig `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
 }
 
 func (s AzureValidatorSpec) ResultCount() int {
-	return len(s.RBACRules)
+	n := 0
+	if len(s.PreflightScopes()) > 0 {
+		n++
+	}
+	if len(s.RulesFrom) > 0 {
+		n++
+	}
+	return n + len(s.RBACRules) + len(s.KeyVaultCertificateRules) + len(s.AKSClusterRules) + len(s.NATGatewayRules) + len(s.VNetPeeringRules) + len(s.RouteTableRules) + len(s.ImageCompatibilityRules) + len(s.ImageReplicationRules) + len(s.VMSecurityRules) + len(s.VMSizeRules) + len(s.DiskZoneRules) + len(s.TemplatePermissionRules) + len(s.PolicyExemptionRules) + len(s.DefenderPlanRules) + len(s.BudgetRules) + len(s.ResourceLockRules) + len(s.FirewallPolicyRules) + len(s.BastionRules) + len(s.DDoSProtectionRules) + len(s.PublicIPPrefixRules) + len(s.EventHubRules) + len(s.ApplicationGatewayRules) + len(s.CosmosDBRules) + len(s.SQLServerRules) + len(s.GlobalEndpointRules) + len(s.ExpressRouteRules) + len(s.VPNGatewayRules) + len(s.NetworkWatcherRules) + len(s.ProximityPlacementGroupRules) + len(s.EncryptionAtHostRules) + len(s.GroupMembershipRules) + len(s.AppPermissionRules) + len(s.BlobContainerRules) + len(s.StorageNetworkRules) + len(s.FileShareRules) + len(s.SubnetDelegationRules) + len(s.MonitorAlertRules) + len(s.ActivityLogExportRules) + len(s.ImageDeprecationRules) + len(s.ZoneRedundancyRules) + len(s.CustomLocationRules) + len(s.KeyVaultRecoveryRules)
+}
+
+// PreflightScopes returns the scopes at which the plugin's own identity must be able to read role
+// assignments and role definitions to evaluate the RBAC and template permission rules, in the
+// order of the rules, without duplicates. Returns nil if the preflight check is skipped.
+func (s AzureValidatorSpec) PreflightScopes() []string {
+	if s.SkipPreflight {
+		return nil
+	}
+	var scopes []string
+	seen := map[string]bool{}
+	add := func(scope string) {
+		if key := strings.ToLower(scope); !seen[key] {
+			seen[key] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	for _, rule := range s.RBACRules {
+		for _, set := range rule.Permissions {
+			for _, scope := range set.BaseScopes() {
+				add(scope)
+			}
+		}
+	}
+	for _, rule := range s.TemplatePermissionRules {
+		add(rule.Scope)
+	}
+	return scopes
+}
+
+// Severity is how serious a rule's failures are.
+// +kubebuilder:validation:Enum=Low;Medium;High;Critical
+type Severity string
+
+const (
+	SeverityLow      Severity = "Low"
+	SeverityMedium   Severity = "Medium"
+	SeverityHigh     Severity = "High"
+	SeverityCritical Severity = "Critical"
+)
+
+// Warns reports whether a failing rule of the severity is only a warning, which fails the rule's
+// condition but not the ValidationResult.
+func (s Severity) Warns() bool {
+	return s == SeverityLow || s == SeverityMedium
 }
 
 // Conveys that a specified security principal (aka principal) should have the specified
 // permissions, via roles. It doesn't matter which roles provide the permissions as long as enough
 // role assignments exist that the principal has all of the permissions and no deny assignments
 // exist that deny the permissions.
+// +kubebuilder:validation:XValidation:message="principalId, principalIds, or principalClientId must be set",rule="(has(self.principalId) && size(self.principalId) > 0) || (has(self.principalIds) && size(self.principalIds) > 0) || (has(self.principalClientId) && size(self.principalClientId) > 0)"
+// +kubebuilder:validation:XValidation:message="principalClientId can't be combined with principalId or principalIds",rule="!has(self.principalClientId) || size(self.principalClientId) == 0 || ((!has(self.principalId) || size(self.principalId) == 0) && (!has(self.principalIds) || size(self.principalIds) == 0))"
+// +kubebuilder:validation:XValidation:message="rawFilter can't be combined with expandPrincipalGroups",rule="!has(self.rawFilter) || size(self.rawFilter) == 0 || !has(self.expandPrincipalGroups) || !self.expandPrincipalGroups"
 type RBACRule struct {
 	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
 	// each other.
 	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 	// The permissions that the principal must have. If the principal has permissions less than
 	// this, validation will fail. If the principal has permissions equal to or more than this
 	// (e.g., inherited permissions from higher level scope, more roles than needed) validation
@@ -52,17 +405,1707 @@ type RBACRule struct {
 	Permissions []PermissionSet `json:"permissionSets" yaml:"permissionSets"`
 	// The principal being validated. This can be any type of principal - Device, ForeignGroup,
 	// Group, ServicePrincipal, or User.
+	// +optional
+	PrincipalID string `json:"principalId,omitempty" yaml:"principalId,omitempty"`
+	// More principals being validated against the same permission sets, alongside or instead of
+	// principalId. Each principal is validated separately, and when the rule has more than one,
+	// each of its failures starts with the principal it's about.
+	// +optional
+	//+kubebuilder:validation:MaxItems=10
+	//+listType=set
+	PrincipalIDs []string `json:"principalIds,omitempty" yaml:"principalIds,omitempty"`
+	// The application (client) ID of a service principal being validated, instead of principalId
+	// and principalIds. It's resolved to the object ID of the application's service principal in
+	// Microsoft Graph before role assignments are listed, and the rule fails if the tenant has no
+	// such service principal. Role assignments only name object IDs, so
+	// revalidateOnRoleAssignmentChanges doesn't apply to it.
+	// +optional
+	PrincipalClientID string `json:"principalClientId,omitempty" yaml:"principalClientId,omitempty"`
+	// If true, the principal also has the permissions of the role assignments of the groups it's a
+	// member of, directly or transitively. Role assignments are then listed with ARM's assignedTo()
+	// filter instead of principalId eq. Deny assignments are still only matched on the principal.
+	// +optional
+	ExpandPrincipalGroups bool `json:"expandPrincipalGroups,omitempty" yaml:"expandPrincipalGroups,omitempty"`
+	// Advanced: if provided, role assignments are listed with this $filter instead of the one the
+	// plugin builds (principalId eq), e.g. atScope(), for experimenting with filters that newer API
+	// versions support. It's sent to ARM verbatim, without URL escaping, so characters that aren't
+	// allowed in a URL query must already be percent-encoded (e.g. spaces as %20). Role
+	// assignments it lists for other principals are dropped by the plugin, so a filter that's too
+	// broad only costs requests, but one that's too narrow misses role assignments. It's used for
+	// every principal of the rule, and can't be combined with expandPrincipalGroups.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:Pattern=`^[^\s&#]*$`
+	RawFilter string `json:"rawFilter,omitempty" yaml:"rawFilter,omitempty"`
+	// If provided, the rule also fails when a subscription of one of its permission sets' scopes
+	// is too close to Azure's limit on the number of role assignments per subscription, because
+	// creating the role assignments that the rule's failures call for would then fail too.
+	// +optional
+	RoleAssignmentQuota *RoleAssignmentQuota `json:"roleAssignmentQuota,omitempty" yaml:"roleAssignmentQuota,omitempty"`
+	// If true, and the principal is the plugin's own identity, the permission sets whose scopes
+	// are resource groups or resources are validated against the effective permissions that ARM
+	// reports for the plugin at their scopes, instead of against the principal's role assignments
+	// and their role definitions. Other permission sets are validated as usual.
+	// +optional
+	SelfCheck bool `json:"selfCheck,omitempty" yaml:"selfCheck,omitempty"`
+	// If true, the principal is looked up in Microsoft Graph, and its type and display name are
+	// added to the details of the rule's condition. If the principal then has no role assignments
+	// at the rule's scopes, the details also list the other principals of its type with its display
+	// name that do, which usually means that the principal was deleted and re-created, leaving its
+	// role assignments behind with its old object ID.
+	// +optional
+	VerifyPrincipal bool `json:"verifyPrincipal,omitempty" yaml:"verifyPrincipal,omitempty"`
+	// A Go template that the rule's failures about Actions and DataActions that the principal
+	// lacks are rendered with, instead of the built-in one. It has access to .Kind (Action or
+	// DataAction), .Action, .Scope, .PrincipalID, .Roles (the names of the principal's roles at
+	// the scope), .DenyAssignment (the ID of the deny assignment denying the Action, if any),
+	// .MultiScope, .Scopes (the scopes of identical failures merged into this one), and
+	// .SelfCheck, and may call join (e.g. {{join .Roles ", "}}).
+	// +optional
+	//+kubebuilder:validation:MaxLength=2048
+	FailureMessageTemplate string `json:"failureMessageTemplate,omitempty" yaml:"failureMessageTemplate,omitempty"`
+	// If set, permissions that the principal lacks are attributed to Azure's delay in propagating
+	// new role assignments, rather than failing the rule, until this long after the AzureValidator
+	// was created, the rule was created or changed, or the rule last passed. Meanwhile, the rule is
+	// reported as pending, with its condition's status Unknown, and re-validated sooner. Failures
+	// other than missing permissions fail the rule right away. If not set, missing permissions fail
+	// the rule right away.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty" yaml:"gracePeriod,omitempty"`
+	// If provided, the Azure region whose regional ARM endpoint the rule's ARM requests are sent
+	// to, overriding the spec's armRegion, e.g. for permission sets in subscriptions homed
+	// elsewhere.
+	// +optional
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9]*$`
+	ARMRegion string `json:"armRegion,omitempty" yaml:"armRegion,omitempty"`
+}
+
+// Principals returns the principals of the rule: principalId, if set, followed by principalIds,
+// without duplicates. Principal IDs are compared case-insensitively.
+func (r RBACRule) Principals() []string {
+	var principals []string
+	for _, id := range append([]string{r.PrincipalID}, r.PrincipalIDs...) {
+		if id != "" && !slices.ContainsFunc(principals, func(p string) bool { return strings.EqualFold(p, id) }) {
+			principals = append(principals, id)
+		}
+	}
+	return principals
+}
+
+// RoleAssignmentQuota conveys how many more role assignments each subscription must have room for.
+// All role assignments in a subscription (at the subscription scope, or at any resource group or
+// resource in it) count towards its limit, no matter which principal they're for.
+type RoleAssignmentQuota struct {
+	// The minimum number of role assignments that can still be created in each subscription.
+	//+kubebuilder:validation:Minimum=1
+	MinAvailable int `json:"minAvailable" yaml:"minAvailable"`
+	// The maximum number of role assignments per subscription. Defaults to 4000, Azure's limit for
+	// most subscriptions.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	Limit int `json:"limit,omitempty" yaml:"limit,omitempty"`
+}
+
+// Conveys that certificates stored in an Azure Key Vault must exist, be enabled, and remain valid
+// for at least a minimum amount of time, so that they can be renewed before anything that uses
+// them breaks.
+type KeyVaultCertificateRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The URI of the vault (e.g. https://myvault.vault.azure.net/).
+	//+kubebuilder:validation:Pattern=`^https://`
+	VaultURI string `json:"vaultUri" yaml:"vaultUri"`
+	// The names of the certificates in the vault. The latest version of each certificate is
+	// validated.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	Certificates []string `json:"certificates" yaml:"certificates"`
+	// How long each certificate must remain valid for (e.g. 720h for 30 days). Validation fails for
+	// certificates that expire sooner than this.
+	MinRemainingValidity metav1.Duration `json:"minRemainingValidity" yaml:"minRemainingValidity"`
+	// If provided, DNS names that each certificate must be valid for, either as the common name of
+	// its subject or as one of its subject alternative names.
+	// +optional
+	//+kubebuilder:validation:MaxItems=50
+	DNSNames []string `json:"dnsNames,omitempty" yaml:"dnsNames,omitempty"`
+}
+
+// Conveys that an existing AKS cluster must have the expected properties. Only the properties that
+// are provided are validated.
+type AKSClusterRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the cluster (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.ContainerService/managedClusters/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.ContainerService/managedClusters/[^/]+$`
+	ClusterID string `json:"clusterId" yaml:"clusterId"`
+	// The properties the cluster must have.
+	ExpectedProperties AKSClusterProperties `json:"expectedProperties" yaml:"expectedProperties"`
+}
+
+// AKSClusterProperties are the properties of an AKS cluster that can be validated.
+type AKSClusterProperties struct {
+	// The provisioning state of the cluster (e.g. Succeeded).
+	// +optional
+	ProvisioningState *string `json:"provisioningState,omitempty" yaml:"provisioningState,omitempty"`
+	// Whether the cluster's OIDC issuer is enabled.
+	// +optional
+	OIDCIssuerEnabled *bool `json:"oidcIssuerEnabled,omitempty" yaml:"oidcIssuerEnabled,omitempty"`
+	// Whether workload identity is enabled for the cluster.
+	// +optional
+	WorkloadIdentityEnabled *bool `json:"workloadIdentityEnabled,omitempty" yaml:"workloadIdentityEnabled,omitempty"`
+	// Whether the cluster's API server is only reachable through a private endpoint.
+	// +optional
+	PrivateCluster *bool `json:"privateCluster,omitempty" yaml:"privateCluster,omitempty"`
+	// The network plugin of the cluster (e.g. azure, kubenet, or none).
+	// +optional
+	NetworkPlugin *string `json:"networkPlugin,omitempty" yaml:"networkPlugin,omitempty"`
+	// The minimum Kubernetes version the cluster must run (e.g. 1.28 or 1.28.5).
+	// +optional
+	//+kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	MinKubernetesVersion *string `json:"minKubernetesVersion,omitempty" yaml:"minKubernetesVersion,omitempty"`
+	// The addon profiles the cluster must have, keyed by addon name (e.g.
+	// azureKeyvaultSecretsProvider).
+	// +optional
+	AddonProfiles map[string]AKSAddonProfile `json:"addonProfiles,omitempty" yaml:"addonProfiles,omitempty"`
+}
+
+// AKSAddonProfile is the expected configuration of an AKS cluster's addon.
+type AKSAddonProfile struct {
+	// Whether the addon must be enabled.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// If provided, configuration the addon must have (e.g. enableSecretRotation: "true"). Other
+	// configuration of the addon is ignored.
+	// +optional
+	Config map[string]string `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// Conveys that subnets (e.g. those a cluster's nodes will be placed in) must have a NAT gateway
+// attached, through which their outbound traffic leaves with the gateway's public IPs.
+type NATGatewayRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource IDs of the subnets that must have a NAT gateway attached.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	SubnetIDs []SubnetID `json:"subnetIds" yaml:"subnetIds"`
+	// The minimum number of public IP addresses and public IP prefixes, combined, that each
+	// subnet's NAT gateway must have. A NAT gateway without any is always a failure, because it
+	// can't provide outbound connectivity.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinOutboundIPs int `json:"minOutboundIPs,omitempty" yaml:"minOutboundIPs,omitempty"`
+	// If true, each subnet's NAT gateway must be in the Succeeded provisioning state.
+	// +optional
+	RequireProvisioned bool `json:"requireProvisioned,omitempty" yaml:"requireProvisioned,omitempty"`
+}
+
+// SubnetID is the resource ID of a subnet (e.g.
+// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/{vnet}/subnets/{name}).
+// Alias exists to enable kubebuilder pattern validation for arrays of these.
+// +kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/virtualNetworks/[^/]+/subnets/[^/]+$`
+type SubnetID string
+
+// Conveys that a virtual network (e.g. a spoke in a hub-and-spoke topology) must be peered with
+// other virtual networks, and that each of those peerings is connected and configured as expected.
+type VNetPeeringRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the virtual network whose peerings are validated (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/virtualNetworks/[^/]+$`
+	VirtualNetworkID string `json:"virtualNetworkId" yaml:"virtualNetworkId"`
+	// The peerings the virtual network must have. Each of them must be in the Connected state.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	//+kubebuilder:validation:XValidation:message="Peerings must have unique remote virtual networks",rule="self.all(e, size(self.filter(x, x.remoteVirtualNetworkId == e.remoteVirtualNetworkId)) == 1)"
+	Peerings []VNetPeering `json:"peerings" yaml:"peerings"`
+}
+
+// VNetPeering is the expected configuration of a virtual network's peering with a remote virtual
+// network. Only the settings that are provided are validated.
+type VNetPeering struct {
+	// The resource ID of the remote virtual network.
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/virtualNetworks/[^/]+$`
+	RemoteVirtualNetworkID string `json:"remoteVirtualNetworkId" yaml:"remoteVirtualNetworkId"`
+	// Whether VMs in the virtual network must be able to access VMs in the remote virtual network.
+	// +optional
+	AllowVirtualNetworkAccess *bool `json:"allowVirtualNetworkAccess,omitempty" yaml:"allowVirtualNetworkAccess,omitempty"`
+	// Whether traffic that the remote virtual network forwards (e.g. from a network virtual
+	// appliance in a hub) must be allowed into the virtual network.
+	// +optional
+	AllowForwardedTraffic *bool `json:"allowForwardedTraffic,omitempty" yaml:"allowForwardedTraffic,omitempty"`
+	// Whether the virtual network must use the remote virtual network's gateways.
+	// +optional
+	UseRemoteGateways *bool `json:"useRemoteGateways,omitempty" yaml:"useRemoteGateways,omitempty"`
+}
+
+// Conveys that a route table must have the required routes. The route table is either given
+// directly or discovered from the subnet it's associated with.
+// +kubebuilder:validation:XValidation:message="Exactly one of routeTableId and subnetId must be provided",rule="has(self.routeTableId) != has(self.subnetId)"
+// +kubebuilder:validation:XValidation:message="At least one route must be required, or forbidDefaultRoute must be true",rule="(has(self.routes) && size(self.routes) > 0) || (has(self.forbidDefaultRoute) && self.forbidDefaultRoute)"
+type RouteTableRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the route table (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/routeTables/{name}).
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/routeTables/[^/]+$`
+	RouteTableID string `json:"routeTableId,omitempty" yaml:"routeTableId,omitempty"`
+	// The subnet whose associated route table is validated. A subnet without a route table is a
+	// failure.
+	// +optional
+	SubnetID SubnetID `json:"subnetId,omitempty" yaml:"subnetId,omitempty"`
+	// The routes the route table must have.
+	// +optional
+	//+kubebuilder:validation:MaxItems=50
+	Routes []RequiredRoute `json:"routes,omitempty" yaml:"routes,omitempty"`
+	// If true, the route table must not have a route for 0.0.0.0/0 other than one of the required
+	// routes.
+	// +optional
+	ForbidDefaultRoute bool `json:"forbidDefaultRoute,omitempty" yaml:"forbidDefaultRoute,omitempty"`
+}
+
+// RequiredRoute is a route that a route table must have.
+type RequiredRoute struct {
+	// The destination of the route, as a CIDR (e.g. 0.0.0.0/0) or a service tag (e.g.
+	// AzureCloud). CIDRs are compared by the network they denote, so 10.1.0.0/16 matches a route
+	// for 10.1.2.3/16.
+	AddressPrefix string `json:"addressPrefix" yaml:"addressPrefix"`
+	// The type of hop that the route's traffic must be sent to.
+	//+kubebuilder:validation:Enum=VirtualNetworkGateway;VnetLocal;Internet;VirtualAppliance;None
+	NextHopType string `json:"nextHopType" yaml:"nextHopType"`
+	// If provided, the IP address that the route's traffic must be forwarded to. Only applies to
+	// routes whose next hop type is VirtualAppliance.
+	// +optional
+	NextHopIPAddress string `json:"nextHopIpAddress,omitempty" yaml:"nextHopIpAddress,omitempty"`
+}
+
+// Conveys that VMs of each of the VM sizes must be able to run a compute gallery image. That is,
+// each size must support the image definition's Hyper-V generation and CPU architecture, and the
+// security type (trusted launch or confidential VM) that the image requires, if any.
+type ImageCompatibilityRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the image definition (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Compute/galleries/{gallery}/images/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Compute/galleries/[^/]+/images/[^/]+$`
+	ImageDefinitionID string `json:"imageDefinitionId" yaml:"imageDefinitionId"`
+	// The location that VMs will be created in (e.g. eastus). The capabilities of VM sizes are
+	// looked up in this location.
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The ID of the subscription that VMs will be created in. Defaults to the subscription of the
+	// image definition.
+	// +optional
+	SubscriptionID string `json:"subscriptionId,omitempty" yaml:"subscriptionId,omitempty"`
+	// The VM sizes that must be able to run the image (e.g. Standard_D4s_v5).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	VMSizes []string `json:"vmSizes" yaml:"vmSizes"`
+}
+
+// Conveys that a compute gallery image version must be usable in each of the regions. That is, each
+// region must be one of the version's target regions, with enough replicas, and the version's
+// replication to it must have completed.
+type ImageReplicationRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the image version (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Compute/galleries/{gallery}/images/{name}/versions/{version}).
+	// The version may also be latest, or a version range (e.g. >=1.27.0 <1.29.0), which resolve to
+	// the image definition's highest version that satisfies them and isn't excluded from latest.
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Compute/galleries/[^/]+/images/[^/]+/versions/[^/]+$`
+	ImageVersionID string `json:"imageVersionId" yaml:"imageVersionId"`
+	// The regions that VMs will be created from the image version in (e.g. eastus).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	Regions []string `json:"regions" yaml:"regions"`
+	// If provided, the image version must have at least this many replicas in each of the
+	// regions.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinReplicas int `json:"minReplicas,omitempty" yaml:"minReplicas,omitempty"`
+}
+
+// Conveys that VMs of each of the VM sizes must be able to be created with a security type in a
+// location. That is, each size must support Hyper-V generation 2 and the security type, and, if an
+// image definition is given, the image must support the security type too.
+// +kubebuilder:validation:XValidation:message="subscriptionId must be provided if imageDefinitionId isn't",rule="has(self.subscriptionId) || has(self.imageDefinitionId)"
+type VMSecurityRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The security type that VMs will be created with.
+	//+kubebuilder:validation:Enum=TrustedLaunch;ConfidentialVM
+	SecurityType string `json:"securityType" yaml:"securityType"`
+	// The confidential computing type (e.g. SNP or TDX) that the VM sizes must support, for the
+	// ConfidentialVM security type. If not provided, any type is accepted.
+	// +optional
+	ConfidentialComputingType string `json:"confidentialComputingType,omitempty" yaml:"confidentialComputingType,omitempty"`
+	// The resource ID of an image definition that VMs will be created from, which must support the
+	// security type (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Compute/galleries/{gallery}/images/{name}).
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Compute/galleries/[^/]+/images/[^/]+$`
+	ImageDefinitionID string `json:"imageDefinitionId,omitempty" yaml:"imageDefinitionId,omitempty"`
+	// The location that VMs will be created in (e.g. eastus). The capabilities of VM sizes are
+	// looked up in this location.
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The ID of the subscription that VMs will be created in. Defaults to the subscription of the
+	// image definition.
+	// +optional
+	SubscriptionID string `json:"subscriptionId,omitempty" yaml:"subscriptionId,omitempty"`
+	// The VM sizes that must support the security type (e.g. Standard_D4s_v5).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	VMSizes []string `json:"vmSizes" yaml:"vmSizes"`
+}
+
+// Conveys that each of the VM sizes must have capabilities in a location, e.g. so that nodes
+// support accelerated networking and enough NICs for the planned pod density.
+// +kubebuilder:validation:XValidation:message="At least one of acceleratedNetworking, minNetworkInterfaces, and minVCPUs must be provided",rule="(has(self.acceleratedNetworking) && self.acceleratedNetworking) || has(self.minNetworkInterfaces) || has(self.minVCPUs)"
+type VMSizeRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that VMs will be created in.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that VMs will be created in (e.g. eastus). The capabilities of VM sizes are
+	// looked up in this location.
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The VM sizes that must have the capabilities (e.g. Standard_D4s_v5).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	VMSizes []string `json:"vmSizes" yaml:"vmSizes"`
+	// If true, the sizes must support accelerated networking.
+	// +optional
+	AcceleratedNetworking bool `json:"acceleratedNetworking,omitempty" yaml:"acceleratedNetworking,omitempty"`
+	// If provided, the sizes must support at least this many network interfaces.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinNetworkInterfaces int `json:"minNetworkInterfaces,omitempty" yaml:"minNetworkInterfaces,omitempty"`
+	// If provided, the sizes must have at least this many vCPUs.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinVCPUs int `json:"minVCPUs,omitempty" yaml:"minVCPUs,omitempty"`
+}
+
+// Conveys that, in each of the availability zones of a location, VMs of a size must be able to
+// attach disks of a disk type that's only offered in some zones. That is, the subscription must be
+// offered both the VM size and the disk type in the zone, and, for UltraSSD_LRS, the VM size must
+// support ultra disks there. For PremiumV2_LRS, the VM size must support premium storage.
+type DiskZoneRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that VMs and disks will be created in.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that VMs and disks will be created in (e.g. eastus).
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The availability zones that VMs and disks will be created in (e.g. 1).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=3
+	Zones []string `json:"zones" yaml:"zones"`
+	// The VM size of the VMs that the disks will be attached to (e.g. Standard_E4s_v5).
+	//+kubebuilder:validation:MinLength=1
+	VMSize string `json:"vmSize" yaml:"vmSize"`
+	// The disk type of the disks.
+	//+kubebuilder:validation:Enum=UltraSSD_LRS;PremiumV2_LRS
+	DiskSKU string `json:"diskSku" yaml:"diskSku"`
+}
+
+// Conveys that a principal must have the permissions needed to deploy an ARM template at a scope,
+// rather than a list of permissions. The Actions needed are derived from the types of the
+// template's resources (write on each type, read on each existing resource, and Actions that
+// resources commonly need implicitly, such as joining a subnet), and are then validated like the
+// permissions of an RBAC rule.
+// +kubebuilder:validation:XValidation:message="Exactly one of template and templateConfigMapRef must be provided",rule="has(self.template) != has(self.templateConfigMapRef)"
+type TemplatePermissionRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The principal being validated. This can be any type of principal - Device, ForeignGroup,
+	// Group, ServicePrincipal, or User.
 	PrincipalID string `json:"principalId" yaml:"principalId"`
+	// If true, the principal also has the permissions of the role assignments of the groups it's a
+	// member of, directly or transitively. Role assignments are then listed with ARM's assignedTo()
+	// filter instead of principalId eq. Deny assignments are still only matched on the principal.
+	// +optional
+	ExpandPrincipalGroups bool `json:"expandPrincipalGroups,omitempty" yaml:"expandPrincipalGroups,omitempty"`
+	// The scope that the template would be deployed at, usually a resource group (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}). All Actions are validated at this scope.
+	Scope string `json:"scope" yaml:"scope"`
+	// The ARM template, as JSON. Bicep files must be built into an ARM template first (e.g. with
+	// az bicep build).
+	// +optional
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	// A key of a ConfigMap, in the same namespace as the AzureValidator, whose value is the ARM
+	// template.
+	// +optional
+	TemplateConfigMapRef *ConfigMapKeyRef `json:"templateConfigMapRef,omitempty" yaml:"templateConfigMapRef,omitempty"`
+	// Actions needed to deploy resources of a type, in addition to the ones derived for it.
+	// Extends the built-in mapping for types it doesn't know enough about.
+	// +optional
+	//+kubebuilder:validation:MaxItems=50
+	ResourceTypeActions []ResourceTypeActions `json:"resourceTypeActions,omitempty" yaml:"resourceTypeActions,omitempty"`
+	// A Go template that the rule's failures about Actions that the principal lacks are rendered
+	// with, like an RBAC rule's failureMessageTemplate.
+	// +optional
+	//+kubebuilder:validation:MaxLength=2048
+	FailureMessageTemplate string `json:"failureMessageTemplate,omitempty" yaml:"failureMessageTemplate,omitempty"`
+}
+
+// RulesSource refers to a ConfigMap with rules.
+type RulesSource struct {
+	// The name of the ConfigMap.
+	ConfigMapName string `json:"configMapName" yaml:"configMapName"`
+	// The keys of the ConfigMap's data to load rules from, in order. If not set, rules are
+	// loaded from all of its keys, in lexical order.
+	// +optional
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// SnapshotConfig configures where the snapshots of what an AzureValidator's rules observed in
+// Azure are written.
+// +kubebuilder:validation:XValidation:message="Exactly one of configMapName and blobContainerUrl must be provided",rule="has(self.configMapName) != has(self.blobContainerUrl)"
+type SnapshotConfig struct {
+	// The name of a ConfigMap, in the same namespace as the AzureValidator, that each snapshot
+	// is written to, under the key snapshot.json. It's created if it doesn't exist.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	ConfigMapName string `json:"configMapName,omitempty" yaml:"configMapName,omitempty"`
+	// The URL of a blob container (e.g. https://myaccount.blob.core.windows.net/snapshots) that
+	// each snapshot is uploaded to, as a blob named after the AzureValidator and the time of the
+	// reconcile. It's uploaded with the plugin's Azure credential, which needs the Storage Blob
+	// Data Contributor role on the container.
+	// +optional
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:Pattern=`^https://[^/]+/[^/]+/?$`
+	BlobContainerURL string `json:"blobContainerUrl,omitempty" yaml:"blobContainerUrl,omitempty"`
+	// The most bytes of responses that a snapshot holds. Responses beyond it are left out, and
+	// counted. Defaults to 512KiB. ConfigMaps can't hold much more than 900KiB.
+	// +optional
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=921600
+	MaxBytes int `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+}
+
+// DefaultSnapshotMaxBytes is how many bytes of responses a snapshot holds, unless its
+// configuration's MaxBytes is set.
+const DefaultSnapshotMaxBytes = 512 * 1024
+
+// ByteLimit returns how many bytes of responses a snapshot holds.
+func (c SnapshotConfig) ByteLimit() int {
+	if c.MaxBytes > 0 {
+		return c.MaxBytes
+	}
+	return DefaultSnapshotMaxBytes
+}
+
+// RuleSet is a group of rules in a key of a ConfigMap referred to by spec.rulesFrom.
+type RuleSet struct {
+	RBACRules                    []RBACRule                    `json:"rbacRules,omitempty" yaml:"rbacRules,omitempty"`
+	KeyVaultCertificateRules     []KeyVaultCertificateRule     `json:"keyVaultCertificateRules,omitempty" yaml:"keyVaultCertificateRules,omitempty"`
+	AKSClusterRules              []AKSClusterRule              `json:"aksClusterRules,omitempty" yaml:"aksClusterRules,omitempty"`
+	NATGatewayRules              []NATGatewayRule              `json:"natGatewayRules,omitempty" yaml:"natGatewayRules,omitempty"`
+	VNetPeeringRules             []VNetPeeringRule             `json:"vnetPeeringRules,omitempty" yaml:"vnetPeeringRules,omitempty"`
+	RouteTableRules              []RouteTableRule              `json:"routeTableRules,omitempty" yaml:"routeTableRules,omitempty"`
+	ImageCompatibilityRules      []ImageCompatibilityRule      `json:"imageCompatibilityRules,omitempty" yaml:"imageCompatibilityRules,omitempty"`
+	ImageReplicationRules        []ImageReplicationRule        `json:"imageReplicationRules,omitempty" yaml:"imageReplicationRules,omitempty"`
+	VMSecurityRules              []VMSecurityRule              `json:"vmSecurityRules,omitempty" yaml:"vmSecurityRules,omitempty"`
+	VMSizeRules                  []VMSizeRule                  `json:"vmSizeRules,omitempty" yaml:"vmSizeRules,omitempty"`
+	DiskZoneRules                []DiskZoneRule                `json:"diskZoneRules,omitempty" yaml:"diskZoneRules,omitempty"`
+	TemplatePermissionRules      []TemplatePermissionRule      `json:"templatePermissionRules,omitempty" yaml:"templatePermissionRules,omitempty"`
+	PolicyExemptionRules         []PolicyExemptionRule         `json:"policyExemptionRules,omitempty" yaml:"policyExemptionRules,omitempty"`
+	DefenderPlanRules            []DefenderPlanRule            `json:"defenderPlanRules,omitempty" yaml:"defenderPlanRules,omitempty"`
+	BudgetRules                  []BudgetRule                  `json:"budgetRules,omitempty" yaml:"budgetRules,omitempty"`
+	ResourceLockRules            []ResourceLockRule            `json:"resourceLockRules,omitempty" yaml:"resourceLockRules,omitempty"`
+	FirewallPolicyRules          []FirewallPolicyRule          `json:"firewallPolicyRules,omitempty" yaml:"firewallPolicyRules,omitempty"`
+	BastionRules                 []BastionRule                 `json:"bastionRules,omitempty" yaml:"bastionRules,omitempty"`
+	DDoSProtectionRules          []DDoSProtectionRule          `json:"ddosProtectionRules,omitempty" yaml:"ddosProtectionRules,omitempty"`
+	PublicIPPrefixRules          []PublicIPPrefixRule          `json:"publicIPPrefixRules,omitempty" yaml:"publicIPPrefixRules,omitempty"`
+	EventHubRules                []EventHubRule                `json:"eventHubRules,omitempty" yaml:"eventHubRules,omitempty"`
+	ApplicationGatewayRules      []ApplicationGatewayRule      `json:"applicationGatewayRules,omitempty" yaml:"applicationGatewayRules,omitempty"`
+	CosmosDBRules                []CosmosDBRule                `json:"cosmosDBRules,omitempty" yaml:"cosmosDBRules,omitempty"`
+	SQLServerRules               []SQLServerRule               `json:"sqlServerRules,omitempty" yaml:"sqlServerRules,omitempty"`
+	GlobalEndpointRules          []GlobalEndpointRule          `json:"globalEndpointRules,omitempty" yaml:"globalEndpointRules,omitempty"`
+	ExpressRouteRules            []ExpressRouteRule            `json:"expressRouteRules,omitempty" yaml:"expressRouteRules,omitempty"`
+	VPNGatewayRules              []VPNGatewayRule              `json:"vpnGatewayRules,omitempty" yaml:"vpnGatewayRules,omitempty"`
+	NetworkWatcherRules          []NetworkWatcherRule          `json:"networkWatcherRules,omitempty" yaml:"networkWatcherRules,omitempty"`
+	ProximityPlacementGroupRules []ProximityPlacementGroupRule `json:"proximityPlacementGroupRules,omitempty" yaml:"proximityPlacementGroupRules,omitempty"`
+	EncryptionAtHostRules        []EncryptionAtHostRule        `json:"encryptionAtHostRules,omitempty" yaml:"encryptionAtHostRules,omitempty"`
+	GroupMembershipRules         []GroupMembershipRule         `json:"groupMembershipRules,omitempty" yaml:"groupMembershipRules,omitempty"`
+	AppPermissionRules           []AppPermissionRule           `json:"appPermissionRules,omitempty" yaml:"appPermissionRules,omitempty"`
+	BlobContainerRules           []BlobContainerRule           `json:"blobContainerRules,omitempty" yaml:"blobContainerRules,omitempty"`
+	StorageNetworkRules          []StorageNetworkRule          `json:"storageNetworkRules,omitempty" yaml:"storageNetworkRules,omitempty"`
+	FileShareRules               []FileShareRule               `json:"fileShareRules,omitempty" yaml:"fileShareRules,omitempty"`
+	SubnetDelegationRules        []SubnetDelegationRule        `json:"subnetDelegationRules,omitempty" yaml:"subnetDelegationRules,omitempty"`
+	MonitorAlertRules            []MonitorAlertRule            `json:"monitorAlertRules,omitempty" yaml:"monitorAlertRules,omitempty"`
+	ActivityLogExportRules       []ActivityLogExportRule       `json:"activityLogExportRules,omitempty" yaml:"activityLogExportRules,omitempty"`
+	ImageDeprecationRules        []ImageDeprecationRule        `json:"imageDeprecationRules,omitempty" yaml:"imageDeprecationRules,omitempty"`
+	ZoneRedundancyRules          []ZoneRedundancyRule          `json:"zoneRedundancyRules,omitempty" yaml:"zoneRedundancyRules,omitempty"`
+	CustomLocationRules          []CustomLocationRule          `json:"customLocationRules,omitempty" yaml:"customLocationRules,omitempty"`
+	KeyVaultRecoveryRules        []KeyVaultRecoveryRule        `json:"keyVaultRecoveryRules,omitempty" yaml:"keyVaultRecoveryRules,omitempty"`
+}
+
+// ConfigMapKeyRef refers to a key of a ConfigMap.
+type ConfigMapKeyRef struct {
+	// The name of the ConfigMap.
+	Name string `json:"name" yaml:"name"`
+	// The key of the ConfigMap's data.
+	Key string `json:"key" yaml:"key"`
+}
+
+// ResourceTypeActions are Actions needed to deploy resources of a type.
+type ResourceTypeActions struct {
+	// The resource type (e.g. Microsoft.Compute/virtualMachines).
+	ResourceType string `json:"resourceType" yaml:"resourceType"`
+	// The Actions needed to deploy a resource of the type. Must not contain any wildcards.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	//+kubebuilder:validation:XValidation:message="Actions cannot have wildcards.",rule="self.all(item, !item.contains('*'))"
+	Actions []ActionStr `json:"actions" yaml:"actions"`
+}
+
+// Conveys that Azure Policy exemptions must apply to a scope, e.g. so that a policy assignment
+// that denies public IP addresses doesn't block provisioning in a resource group. Exemptions that
+// apply to a containing scope (e.g. the subscription of a resource group) count.
+type PolicyExemptionRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The subscription or resource group that the exemptions must apply to (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+(/resource[Gg]roups/[^/]+)?$`
+	Scope string `json:"scope" yaml:"scope"`
+	// The exemptions that must apply to the scope.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	//+kubebuilder:validation:XValidation:message="Each policy assignment may only be expected once",rule="self.all(e, size(self.filter(x, x.policyAssignmentId == e.policyAssignmentId)) == 1)"
+	Exemptions []ExpectedPolicyExemption `json:"exemptions" yaml:"exemptions"`
+	// If provided, how long each exemption that expires must remain valid for (e.g. 720h for 30
+	// days). Validation fails for exemptions that expire sooner than this. Exemptions without an
+	// expiry always pass.
+	// +optional
+	MinRemainingValidity *metav1.Duration `json:"minRemainingValidity,omitempty" yaml:"minRemainingValidity,omitempty"`
+}
+
+// ExpectedPolicyExemption identifies an exemption by the policy assignment it exempts from.
+type ExpectedPolicyExemption struct {
+	// The resource ID of the policy assignment that must be exempted from (e.g.
+	// /subscriptions/{id}/providers/Microsoft.Authorization/policyAssignments/{name}).
+	//+kubebuilder:validation:Pattern=`/providers/Microsoft\.Authorization/policyAssignments/[^/]+$`
+	PolicyAssignmentID string `json:"policyAssignmentId" yaml:"policyAssignmentId"`
+	// The category the exemption must have.
+	//+kubebuilder:validation:Enum=Waiver;Mitigated
+	Category string `json:"category" yaml:"category"`
+}
+
+// Conveys that Microsoft Defender for Cloud plans (e.g. VirtualMachines for Defender for Servers)
+// must have a pricing tier, and optionally a sub-plan, in a subscription.
+type DefenderPlanRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that the plans must be enabled for.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The plans and the pricing tier each must have.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	//+kubebuilder:validation:XValidation:message="Each plan may only be expected once",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	Plans []ExpectedDefenderPlan `json:"plans" yaml:"plans"`
+}
+
+// ExpectedDefenderPlan is a Defender for Cloud plan and the pricing configuration it must have.
+type ExpectedDefenderPlan struct {
+	// The name of the plan's pricing configuration (e.g. VirtualMachines, Containers, or
+	// KeyVaults).
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name" yaml:"name"`
+	// The pricing tier the plan must have. Standard enables the plan, and Free disables it.
+	// +optional
+	//+kubebuilder:validation:Enum=Standard;Free
+	//+kubebuilder:default=Standard
+	PricingTier string `json:"pricingTier,omitempty" yaml:"pricingTier,omitempty"`
+	// If provided, the sub-plan the plan must have (e.g. P2 for VirtualMachines). Only applies to
+	// plans with the Standard pricing tier.
+	// +optional
+	SubPlan string `json:"subPlan,omitempty" yaml:"subPlan,omitempty"`
+}
+
+// Conveys that at least one Cost Management budget defined at a scope must meet all of the
+// rule's expectations, so that spending in the scope is tracked and alerted on.
+// +kubebuilder:validation:XValidation:message="minAmount must not be greater than maxAmount",rule="!has(self.minAmount) || !has(self.maxAmount) || self.minAmount <= self.maxAmount"
+type BudgetRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The subscription or resource group that the budget must be defined at (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}). Budgets of containing scopes don't count.
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+(/resource[Gg]roups/[^/]+)?$`
+	Scope string `json:"scope" yaml:"scope"`
+	// If provided, the budget's amount must be at least this, in the billing currency.
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	MinAmount *int64 `json:"minAmount,omitempty" yaml:"minAmount,omitempty"`
+	// If provided, the budget's amount must be at most this, in the billing currency.
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	MaxAmount *int64 `json:"maxAmount,omitempty" yaml:"maxAmount,omitempty"`
+	// The time grain the budget must have, i.e. the period its amount applies to.
+	// +optional
+	//+kubebuilder:validation:Enum=Monthly;Quarterly;Annually;BillingMonth;BillingQuarter;BillingAnnual
+	//+kubebuilder:default=Monthly
+	TimeGrain string `json:"timeGrain,omitempty" yaml:"timeGrain,omitempty"`
+	// Whether the budget must have at least one enabled notification with a contact email, group,
+	// or role. Defaults to true.
+	// +optional
+	//+kubebuilder:default=true
+	RequireNotification *bool `json:"requireNotification,omitempty" yaml:"requireNotification,omitempty"`
+}
+
+// Conveys that management locks must, or must not, apply to resource groups or resources. Locks
+// inherited from a containing scope (e.g. the subscription of a resource group) count.
+// +kubebuilder:validation:XValidation:message="At least one of level and forbidReadOnly must be provided",rule="has(self.level) || (has(self.forbidReadOnly) && self.forbidReadOnly)"
+// +kubebuilder:validation:XValidation:message="A ReadOnly lock can't be both required and forbidden",rule="!has(self.level) || self.level != 'ReadOnly' || !has(self.forbidReadOnly) || !self.forbidReadOnly"
+type ResourceLockRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource IDs of the resource groups or resources that the locks must apply to (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	Scopes []string `json:"scopes" yaml:"scopes"`
+	// If provided, the lock level that a lock applying to each scope must have. A ReadOnly lock
+	// also prevents deletion, so it satisfies CanNotDelete.
+	// +optional
+	//+kubebuilder:validation:Enum=CanNotDelete;ReadOnly
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+	// If true, no ReadOnly lock may apply to any scope. ReadOnly locks block many provisioning
+	// operations, e.g. listing a storage account's keys.
+	// +optional
+	ForbidReadOnly bool `json:"forbidReadOnly,omitempty" yaml:"forbidReadOnly,omitempty"`
+}
+
+// Conveys that an Azure Firewall policy must allow traffic, e.g. to the FQDNs that AKS clusters
+// and image pulls need in environments whose egress is forced through the firewall. Rules are
+// matched the way the firewall processes them: network rules before application rules, each by
+// the priority of their rule collection group and then of their rule collection, so traffic only
+// counts as allowed if no rule denies it first. Rules inherited from a parent policy, DNAT rules,
+// and rules that only refer to IP groups, FQDN tags, or web categories aren't considered.
+type FirewallPolicyRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the firewall policy (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/firewallPolicies/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/firewallPolicies/[^/]+$`
+	FirewallPolicyID string `json:"firewallPolicyId" yaml:"firewallPolicyId"`
+	// If provided, only the rules of the policy's rule collection groups with these names count.
+	// Otherwise, the rules of all of its rule collection groups do.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	RuleCollectionGroups []string `json:"ruleCollectionGroups,omitempty" yaml:"ruleCollectionGroups,omitempty"`
+	// The traffic that the policy must allow.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	ExpectedRules []ExpectedFirewallRule `json:"expectedRules" yaml:"expectedRules"`
+}
+
+// ExpectedFirewallRule is traffic that a firewall policy must allow, to each destination, on each
+// port, with each protocol, and from each source.
+// +kubebuilder:validation:XValidation:message="Application rules must have destinationFqdns and no destinationAddresses, and network rules destinationFqdns, destinationAddresses, or both",rule="self.type == 'Application' ? (has(self.destinationFqdns) && !has(self.destinationAddresses)) : (has(self.destinationFqdns) || has(self.destinationAddresses))"
+// +kubebuilder:validation:XValidation:message="The protocols of application rules must be Http or Https, and those of network rules TCP, UDP, or ICMP",rule="self.type == 'Application' ? self.protocols.all(p, p in ['Http', 'Https']) : self.protocols.all(p, p in ['TCP', 'UDP', 'ICMP'])"
+type ExpectedFirewallRule struct {
+	// The type of firewall rule that must allow the traffic: Application, for HTTP and HTTPS
+	// traffic to FQDNs, or Network.
+	//+kubebuilder:validation:Enum=Application;Network
+	Type string `json:"type" yaml:"type"`
+	// The FQDNs that the traffic is to (e.g. mcr.microsoft.com). An FQDN can start with a
+	// wildcard (e.g. *.ubuntu.com) to require that traffic to all of its subdomains is allowed,
+	// which only a firewall rule with the same wildcard, or a broader one, does.
+	// +optional
+	//+kubebuilder:validation:MaxItems=50
+	DestinationFQDNs []string `json:"destinationFqdns,omitempty" yaml:"destinationFqdns,omitempty"`
+	// The IP addresses, CIDRs, or service tags that the traffic is to. Network rules only.
+	// +optional
+	//+kubebuilder:validation:MaxItems=50
+	DestinationAddresses []string `json:"destinationAddresses,omitempty" yaml:"destinationAddresses,omitempty"`
+	// The ports that the traffic is to.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	//+kubebuilder:validation:XValidation:message="Ports must be between 1 and 65535",rule="self.all(p, p >= 1 && p <= 65535)"
+	Ports []int32 `json:"ports" yaml:"ports"`
+	// The protocols of the traffic: Http or Https for application rules, and TCP, UDP, or ICMP
+	// for network rules.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=3
+	Protocols []string `json:"protocols" yaml:"protocols"`
+	// If provided, the IP addresses or CIDRs that the traffic is from (e.g. the address prefix of
+	// an AKS cluster's subnet). Otherwise, firewall rules that allow the traffic from any source
+	// count, and rules that deny it only count if they deny it from all sources (*).
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	SourceAddresses []string `json:"sourceAddresses,omitempty" yaml:"sourceAddresses,omitempty"`
+}
+
+// Conveys that a virtual network must have an AzureBastionSubnet that's large enough for Azure
+// Bastion (/26 or larger), and a Bastion host in that subnet that's in the Succeeded provisioning
+// state.
+type BastionRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the virtual network (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/virtualNetworks/[^/]+$`
+	VirtualNetworkID string `json:"virtualNetworkId" yaml:"virtualNetworkId"`
+	// If provided, the SKU that the Bastion host must have.
+	// +optional
+	//+kubebuilder:validation:Enum=Developer;Basic;Standard;Premium
+	SKU string `json:"sku,omitempty" yaml:"sku,omitempty"`
+}
+
+// Conveys that virtual networks must have DDoS protection enabled, optionally with a particular
+// DDoS protection plan.
+type DDoSProtectionRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource IDs of the virtual networks that must have DDoS protection enabled.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	VirtualNetworkIDs []VirtualNetworkID `json:"virtualNetworkIds" yaml:"virtualNetworkIds"`
+	// If provided, the resource ID of the DDoS protection plan that each virtual network must be
+	// associated with (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/ddosProtectionPlans/{name}).
+	// Resource IDs are compared ignoring case.
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/ddosProtectionPlans/[^/]+$`
+	DDoSProtectionPlanID string `json:"ddosProtectionPlanId,omitempty" yaml:"ddosProtectionPlanId,omitempty"`
+}
+
+// VirtualNetworkID is the resource ID of a virtual network (e.g.
+// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/{name}).
+// Alias exists to enable kubebuilder pattern validation for arrays of these.
+// +kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/virtualNetworks/[^/]+$`
+type VirtualNetworkID string
+
+// Conveys that a public IP prefix must have a minimum number of addresses that aren't allocated
+// to public IP addresses yet, and optionally that it's in zones or has a SKU tier.
+type PublicIPPrefixRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the public IP prefix (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/publicIPPrefixes/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/publicIPPrefixes/[^/]+$`
+	PublicIPPrefixID string `json:"publicIPPrefixId" yaml:"publicIPPrefixId"`
+	// The minimum number of the prefix's addresses that must not be allocated to public IP
+	// addresses.
+	//+kubebuilder:validation:Minimum=1
+	MinFreeAddresses int `json:"minFreeAddresses" yaml:"minFreeAddresses"`
+	// If provided, the availability zones that the prefix must be in (e.g. 1, 2, and 3).
+	// +optional
+	//+kubebuilder:validation:MaxItems=3
+	Zones []string `json:"zones,omitempty" yaml:"zones,omitempty"`
+	// If provided, the SKU tier that the prefix must have. Public IP prefixes only have the
+	// Standard SKU, so its tier is what sets them apart.
+	// +optional
+	//+kubebuilder:validation:Enum=Regional;Global
+	SKUTier string `json:"skuTier,omitempty" yaml:"skuTier,omitempty"`
+}
+
+// Conveys that an Event Hubs namespace must have an event hub, optionally with a minimum number of
+// partitions and an authorization rule with some rights.
+type EventHubRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the Event Hubs namespace (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.EventHub/namespaces/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.EventHub/namespaces/[^/]+$`
+	NamespaceID string `json:"namespaceId" yaml:"namespaceId"`
+	// The name of the event hub that the namespace must have.
+	//+kubebuilder:validation:MinLength=1
+	EventHubName string `json:"eventHubName" yaml:"eventHubName"`
+	// If provided, the minimum number of partitions that the event hub must have.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinPartitionCount int64 `json:"minPartitionCount,omitempty" yaml:"minPartitionCount,omitempty"`
+	// If provided, an authorization rule that the event hub, or the namespace, must have.
+	// +optional
+	AuthorizationRule *EventHubAuthorizationRule `json:"authorizationRule,omitempty" yaml:"authorizationRule,omitempty"`
+}
+
+// EventHubAuthorizationRule is a shared access authorization rule and the rights it must grant.
+type EventHubAuthorizationRule struct {
+	// The name of the authorization rule. It's looked up on the event hub first, and then on the
+	// namespace, whose authorization rules apply to all of its event hubs.
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name" yaml:"name"`
+	// The rights that the authorization rule must grant.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=3
+	Rights []EventHubAccessRight `json:"rights" yaml:"rights"`
+}
+
+// EventHubAccessRight is a right that an Event Hubs authorization rule grants.
+// Alias exists to enable kubebuilder enum validation for arrays of these.
+// +kubebuilder:validation:Enum=Listen;Send;Manage
+type EventHubAccessRight string
+
+// Conveys that an application gateway must be in the Succeeded provisioning state, and
+// optionally that it has a SKU tier, a WAF policy in a mode, and listeners.
+type ApplicationGatewayRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the application gateway (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/applicationGateways/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/applicationGateways/[^/]+$`
+	ApplicationGatewayID string `json:"applicationGatewayId" yaml:"applicationGatewayId"`
+	// If provided, the SKU tier that the application gateway must have.
+	// +optional
+	//+kubebuilder:validation:Enum=Basic;Standard;Standard_v2;WAF;WAF_v2
+	SKUTier string `json:"skuTier,omitempty" yaml:"skuTier,omitempty"`
+	// If provided, the resource ID of the WAF policy that must be attached to the application
+	// gateway. Resource IDs are compared ignoring case.
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/[Aa]pplicationGatewayWebApplicationFirewallPolicies/[^/]+$`
+	FirewallPolicyID string `json:"firewallPolicyId,omitempty" yaml:"firewallPolicyId,omitempty"`
+	// If provided, the mode that the application gateway's WAF policy must be enabled in. The
+	// application gateway must have a WAF policy attached.
+	// +optional
+	//+kubebuilder:validation:Enum=Prevention;Detection
+	FirewallPolicyMode string `json:"firewallPolicyMode,omitempty" yaml:"firewallPolicyMode,omitempty"`
+	// The listeners that the application gateway must have.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	Listeners []ApplicationGatewayListener `json:"listeners,omitempty" yaml:"listeners,omitempty"`
+}
+
+// ApplicationGatewayListener is a listener that an application gateway must have, on a frontend
+// port and for a protocol.
+type ApplicationGatewayListener struct {
+	// The frontend port that the listener must listen on.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port" yaml:"port"`
+	// The protocol that the listener must be for. Http and Https listeners are the application
+	// gateway's HTTP listeners; Tcp and Tls listeners are its layer 4 listeners.
+	//+kubebuilder:validation:Enum=Http;Https;Tcp;Tls
+	Protocol string `json:"protocol" yaml:"protocol"`
+}
+
+// Conveys that a Cosmos DB account must have the given configuration. Only the settings that are
+// provided are validated.
+type CosmosDBRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the Cosmos DB account (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.DocumentDB/databaseAccounts/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.DocumentDB/databaseAccounts/[^/]+$`
+	DatabaseAccountID string `json:"databaseAccountId" yaml:"databaseAccountId"`
+	// The capabilities that the account must have (e.g. EnableServerless), in any order. The
+	// account can have others too.
+	// +optional
+	//+kubebuilder:validation:MaxItems=10
+	Capabilities []string `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	// If provided, the default consistency level that the account must have.
+	// +optional
+	//+kubebuilder:validation:Enum=Strong;BoundedStaleness;Session;ConsistentPrefix;Eventual
+	ConsistencyLevel string `json:"consistencyLevel,omitempty" yaml:"consistencyLevel,omitempty"`
+	// If provided, whether the account must allow access from public networks.
+	// +optional
+	//+kubebuilder:validation:Enum=Enabled;Disabled;SecuredByPerimeter
+	PublicNetworkAccess string `json:"publicNetworkAccess,omitempty" yaml:"publicNetworkAccess,omitempty"`
+	// The regions that the account must be enabled in (e.g. eastus), in any order. Region
+	// display names (e.g. East US) are accepted too.
+	// +optional
+	//+kubebuilder:validation:MaxItems=10
+	Locations []string `json:"locations,omitempty" yaml:"locations,omitempty"`
+	// If true, the account must have at least one approved private endpoint connection.
+	// +optional
+	RequirePrivateEndpoint bool `json:"requirePrivateEndpoint,omitempty" yaml:"requirePrivateEndpoint,omitempty"`
+}
+
+// Conveys that an Azure SQL logical server must have the given authentication and network
+// configuration. Only the settings that are provided are validated.
+type SQLServerRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the SQL server (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Sql/servers/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Sql/servers/[^/]+$`
+	ServerID string `json:"serverId" yaml:"serverId"`
+	// If true, the server must only allow Azure AD (Microsoft Entra ID) authentication.
+	// +optional
+	RequireAzureADOnlyAuthentication bool `json:"requireAzureADOnlyAuthentication,omitempty" yaml:"requireAzureADOnlyAuthentication,omitempty"`
+	// If provided, the object ID of the user, group, or service principal that must be the
+	// server's Azure AD administrator.
+	// +optional
+	AzureADAdministratorID string `json:"azureADAdministratorId,omitempty" yaml:"azureADAdministratorId,omitempty"`
+	// If provided, whether the server must allow access from public networks.
+	// +optional
+	//+kubebuilder:validation:Enum=Enabled;Disabled
+	PublicNetworkAccess string `json:"publicNetworkAccess,omitempty" yaml:"publicNetworkAccess,omitempty"`
+	// If provided, the lowest minimal TLS version that the server can have. Servers with a
+	// higher one pass.
+	// +optional
+	//+kubebuilder:validation:Enum="1.0";"1.1";"1.2";"1.3"
+	MinimalTLSVersion string `json:"minimalTlsVersion,omitempty" yaml:"minimalTlsVersion,omitempty"`
+}
+
+// Conveys that a global entry point must exist, be enabled, and have enabled and healthy
+// endpoints. A rule validates one kind of profile; only Traffic Manager profiles are supported so
+// far.
+// +kubebuilder:validation:XValidation:message="trafficManagerProfileId must be provided",rule="has(self.trafficManagerProfileId)"
+type GlobalEndpointRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the Traffic Manager profile (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/trafficManagerProfiles/{name}).
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/trafficManagerProfiles/[^/]+$`
+	TrafficManagerProfileID string `json:"trafficManagerProfileId,omitempty" yaml:"trafficManagerProfileId,omitempty"`
+	// The names of the profile's endpoints that must be enabled and healthy.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	// The monitor statuses that count as healthy. Defaults to Online only; add Degraded to accept
+	// endpoints that fail some of their health probes.
+	// +optional
+	//+kubebuilder:validation:MaxItems=3
+	HealthyMonitorStatuses []EndpointMonitorStatus `json:"healthyMonitorStatuses,omitempty" yaml:"healthyMonitorStatuses,omitempty"`
+}
+
+// EndpointMonitorStatus is the monitor status of a global entry point's endpoint.
+// Alias exists to enable kubebuilder enum validation for arrays of these.
+// +kubebuilder:validation:Enum=Online;Degraded;CheckingEndpoint
+type EndpointMonitorStatus string
+
+// Conveys that an ExpressRoute circuit must be provisioned by its service provider and enabled,
+// and, optionally, that a virtual network gateway connection to it must be connected.
+type ExpressRouteRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the ExpressRoute circuit (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/expressRouteCircuits/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/expressRouteCircuits/[^/]+$`
+	CircuitID string `json:"circuitId" yaml:"circuitId"`
+	// If provided, the resource ID of a virtual network gateway connection (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/connections/{name})
+	// that must be to the circuit and connected.
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/connections/[^/]+$`
+	ConnectionID string `json:"connectionId,omitempty" yaml:"connectionId,omitempty"`
+}
+
+// Conveys that a VPN gateway must exist with the given configuration, and that its named
+// connections must be connected.
+type VPNGatewayRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the virtual network gateway (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworkGateways/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Network/virtualNetworkGateways/[^/]+$`
+	GatewayID string `json:"gatewayId" yaml:"gatewayId"`
+	// If provided, the SKU that the gateway must have (e.g. VpnGw2AZ).
+	// +optional
+	SKU string `json:"sku,omitempty" yaml:"sku,omitempty"`
+	// If provided, the generation that the gateway must be.
+	// +optional
+	//+kubebuilder:validation:Enum=Generation1;Generation2
+	Generation string `json:"generation,omitempty" yaml:"generation,omitempty"`
+	// If true, the gateway must be active-active.
+	// +optional
+	RequireActiveActive bool `json:"requireActiveActive,omitempty" yaml:"requireActiveActive,omitempty"`
+	// The names of connections, in the gateway's resource group, that must be of the gateway and
+	// connected.
+	// +optional
+	//+kubebuilder:validation:MaxItems=10
+	Connections []string `json:"connections,omitempty" yaml:"connections,omitempty"`
+	// If true, each connection must also have transferred bytes both ways, i.e. have ingress and
+	// egress bytes above zero.
+	// +optional
+	RequireTraffic bool `json:"requireTraffic,omitempty" yaml:"requireTraffic,omitempty"`
+}
+
+// Conveys that a subscription must have a network watcher in a location, and that network
+// security groups in the location must have flow logs with the given configuration.
+type NetworkWatcherRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that must have a network watcher.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that the network watcher must be in (e.g. eastus).
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The resource IDs of network security groups in the location that must have enabled flow
+	// logs in the network watcher.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	NetworkSecurityGroupIDs []string `json:"networkSecurityGroupIds,omitempty" yaml:"networkSecurityGroupIds,omitempty"`
+	// If provided, the resource ID of the storage account that the flow logs must store logs in.
+	// +optional
+	StorageAccountID string `json:"storageAccountId,omitempty" yaml:"storageAccountId,omitempty"`
+	// If provided, the fewest days that the flow logs must keep logs for. Flow logs without a
+	// retention policy keep logs forever.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinRetentionDays int32 `json:"minRetentionDays,omitempty" yaml:"minRetentionDays,omitempty"`
+	// If true, the flow logs must have Traffic Analytics enabled.
+	// +optional
+	RequireTrafficAnalytics bool `json:"requireTrafficAnalytics,omitempty" yaml:"requireTrafficAnalytics,omitempty"`
+}
+
+// Conveys that a proximity placement group must exist, have its members colocated, and allow the
+// VM sizes that will be deployed into it.
+type ProximityPlacementGroupRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the proximity placement group.
+	//+kubebuilder:validation:MinLength=1
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Compute/proximityPlacementGroups/[^/]+$`
+	ProximityPlacementGroupID string `json:"proximityPlacementGroupId" yaml:"proximityPlacementGroupId"`
+	// If provided, the availability zone (e.g. 1) that the proximity placement group must be in.
+	// +optional
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+	// If provided, the VM sizes (e.g. Standard_D4s_v3) that will be deployed into the proximity
+	// placement group. If the group has an intent, each of them must be one of its intended VM
+	// sizes.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	VMSizes []string `json:"vmSizes,omitempty" yaml:"vmSizes,omitempty"`
+}
+
+// Conveys that VMs of some sizes can be created with encryption at host in a location. That is,
+// the feature that enables encryption at host must be registered in the subscription, and each of
+// the sizes must support it in the location.
+type EncryptionAtHostRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that VMs will be created in.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that VMs will be created in (e.g. eastus). The capabilities of VM sizes are
+	// looked up in this location.
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The VM sizes that must support encryption at host (e.g. Standard_D4s_v5).
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	VMSizes []string `json:"vmSizes" yaml:"vmSizes"`
+	// The resource provider namespace of the feature that must be registered.
+	// +optional
+	//+kubebuilder:default=Microsoft.Compute
+	FeatureNamespace string `json:"featureNamespace,omitempty" yaml:"featureNamespace,omitempty"`
+	// The name of the feature that must be registered.
+	// +optional
+	//+kubebuilder:default=EncryptionAtHost
+	FeatureName string `json:"featureName,omitempty" yaml:"featureName,omitempty"`
+}
+
+// Conveys that a Microsoft Entra ID (Azure AD) group must exist and have security principals as
+// members, so that role assignments to the group apply to them.
+// +kubebuilder:validation:XValidation:message="Exactly one of groupId and groupDisplayName must be provided",rule="has(self.groupId) != has(self.groupDisplayName)"
+type GroupMembershipRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The object ID of the group.
+	// +optional
+	GroupID string `json:"groupId,omitempty" yaml:"groupId,omitempty"`
+	// The display name of the group. Display names aren't unique, so exactly one group must have
+	// it.
+	// +optional
+	GroupDisplayName string `json:"groupDisplayName,omitempty" yaml:"groupDisplayName,omitempty"`
+	// The object IDs of the security principals (e.g. the managed identities of a cluster) that
+	// must be members of the group.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=100
+	MemberIDs []string `json:"memberIds" yaml:"memberIds"`
+	// If true, the principals must be direct members of the group. Otherwise, members of groups
+	// nested in it count too, like they do for role assignments.
+	// +optional
+	DirectOnly bool `json:"directOnly,omitempty" yaml:"directOnly,omitempty"`
+}
+
+// Conveys that an app registration must request API permissions of resource applications, and
+// that an administrator must have consented to them in the tenant, so that tokens issued to the
+// application carry them.
+type AppPermissionRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The application (client) ID of the app registration.
+	//+kubebuilder:validation:MinLength=1
+	ApplicationID string `json:"applicationId" yaml:"applicationId"`
+	// The permissions that the app registration must request and have admin consent for.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=50
+	Permissions []AppPermission `json:"permissions" yaml:"permissions"`
+}
+
+// AppPermission is an API permission that a resource application defines.
+type AppPermission struct {
+	// The application ID of the resource application that defines the permission (e.g.
+	// 00000003-0000-0000-c000-000000000000 for Microsoft Graph).
+	//+kubebuilder:validation:MinLength=1
+	ResourceAppID string `json:"resourceAppId" yaml:"resourceAppId"`
+	// The name of the permission (e.g. User.Read.All).
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name" yaml:"name"`
+	// Application for a permission that the application has itself, or Delegated for one that it
+	// has on behalf of signed-in users.
+	//+kubebuilder:validation:Enum=Application;Delegated
+	Type string `json:"type" yaml:"type"`
+}
+
+// Conveys that a blob container has a locked, time-based immutability (WORM) policy that retains
+// blobs for a minimum number of days, and optionally that its storage account has a lifecycle
+// management rule for the container's blobs.
+type BlobContainerRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the storage account (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Storage/storageAccounts/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Storage/storageAccounts/[^/]+$`
+	StorageAccountID string `json:"storageAccountId" yaml:"storageAccountId"`
+	// The name of the blob container.
+	//+kubebuilder:validation:MinLength=3
+	//+kubebuilder:validation:MaxLength=63
+	ContainerName string `json:"containerName" yaml:"containerName"`
+	// The minimum number of days since their creation that the container's immutability policy
+	// must retain blobs for. The policy must also be locked, since an unlocked policy can be
+	// shortened or deleted.
+	//+kubebuilder:validation:Minimum=1
+	MinImmutabilityDays int32 `json:"minImmutabilityDays" yaml:"minImmutabilityDays"`
+	// If provided, a lifecycle management rule that the storage account must have for the
+	// container's blobs.
+	// +optional
+	LifecycleRule *BlobLifecycleRule `json:"lifecycleRule,omitempty" yaml:"lifecycleRule,omitempty"`
+}
+
+// BlobLifecycleRule is a lifecycle management rule's filter and the actions it must take.
+// +kubebuilder:validation:XValidation:message="at least one of tierToCoolAfterDays and deleteAfterDays must be set",rule="has(self.tierToCoolAfterDays) || has(self.deleteAfterDays)"
+type BlobLifecycleRule struct {
+	// If provided, the prefix of the blobs, within the container, that the lifecycle rule must
+	// apply to. A lifecycle rule applies to them if it's enabled and has no prefix filters, or one
+	// of its prefix filters is a prefix of the container name followed by this prefix. Defaults to
+	// all of the container's blobs.
+	// +optional
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// If provided, the maximum number of days since their last modification after which the
+	// lifecycle rule must move blobs to cool storage.
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	TierToCoolAfterDays *int32 `json:"tierToCoolAfterDays,omitempty" yaml:"tierToCoolAfterDays,omitempty"`
+	// If provided, the maximum number of days since their last modification after which the
+	// lifecycle rule must delete blobs. Should be at least minImmutabilityDays, since immutable
+	// blobs can't be deleted.
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	DeleteAfterDays *int32 `json:"deleteAfterDays,omitempty" yaml:"deleteAfterDays,omitempty"`
+}
+
+// Conveys that a storage account's firewall denies network access by default, and that it allows
+// access from subnets and IP ranges.
+type StorageNetworkRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the storage account (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Storage/storageAccounts/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Storage/storageAccounts/[^/]+$`
+	StorageAccountID string `json:"storageAccountId" yaml:"storageAccountId"`
+	// The resource IDs of the subnets that the storage account's virtual network rules must allow,
+	// e.g. the subnets of a cluster's nodes. They reach the account via service endpoints.
+	// Resource IDs are compared ignoring case.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	SubnetIDs []string `json:"subnetIds,omitempty" yaml:"subnetIds,omitempty"`
+	// The IPv4 addresses or CIDRs (e.g. 203.0.113.0/24) that the storage account's IP rules must
+	// allow. CIDRs are compared by the network they denote, and an address matches a rule for it
+	// alone, e.g. 203.0.113.7 or 203.0.113.7/32.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	IPRanges []string `json:"ipRanges,omitempty" yaml:"ipRanges,omitempty"`
+}
+
+// Conveys that a storage account has file shares, and optionally that they have a minimum quota
+// and an access tier.
+type FileShareRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the storage account (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Storage/storageAccounts/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Storage/storageAccounts/[^/]+$`
+	StorageAccountID string `json:"storageAccountId" yaml:"storageAccountId"`
+	// The names of the file shares that the storage account must have.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	ShareNames []string `json:"shareNames" yaml:"shareNames"`
+	// If provided, the minimum quota, in GiB, that each of the file shares must have.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MinQuotaGiB int32 `json:"minQuotaGiB,omitempty" yaml:"minQuotaGiB,omitempty"`
+	// If provided, the access tier that each of the file shares must have. Shares of premium
+	// (FileStorage) accounts are in the Premium tier.
+	// +optional
+	//+kubebuilder:validation:Enum=TransactionOptimized;Hot;Cool;Premium
+	AccessTier string `json:"accessTier,omitempty" yaml:"accessTier,omitempty"`
+}
+
+// Conveys that subnets, such as those of AKS node pools, aren't delegated to other services or
+// linked to them, and optionally that their private endpoint and private link service network
+// policies are set as expected.
+type SubnetDelegationRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource IDs of the subnets to validate.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	SubnetIDs []SubnetID `json:"subnetIds" yaml:"subnetIds"`
+	// If provided, the service that the subnets may be delegated to (e.g.
+	// Microsoft.ContainerService/managedClusters). Otherwise, the subnets must have no
+	// delegations. Service association links must always belong to this service.
+	// +optional
+	AllowedDelegation string `json:"allowedDelegation,omitempty" yaml:"allowedDelegation,omitempty"`
+	// If provided, the private endpoint network policies that the subnets must have.
+	// +optional
+	//+kubebuilder:validation:Enum=Disabled;Enabled;NetworkSecurityGroupEnabled;RouteTableEnabled
+	PrivateEndpointNetworkPolicies string `json:"privateEndpointNetworkPolicies,omitempty" yaml:"privateEndpointNetworkPolicies,omitempty"`
+	// If provided, the private link service network policies that the subnets must have.
+	// +optional
+	//+kubebuilder:validation:Enum=Disabled;Enabled
+	PrivateLinkServiceNetworkPolicies string `json:"privateLinkServiceNetworkPolicies,omitempty" yaml:"privateLinkServiceNetworkPolicies,omitempty"`
+}
+
+// Conveys that an Azure Monitor action group exists, is enabled, and has receivers of some types,
+// and that metric alert rules exist and are enabled.
+type MonitorAlertRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that the action group and the metric alert rules are in.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The resource group that the action group and the metric alert rules are in.
+	//+kubebuilder:validation:MinLength=1
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// The name of the action group.
+	//+kubebuilder:validation:MinLength=1
+	ActionGroupName string `json:"actionGroupName" yaml:"actionGroupName"`
+	// The types of receivers that the action group must have at least one of each of.
+	// +optional
+	//+kubebuilder:validation:MaxItems=11
+	ReceiverTypes []ActionGroupReceiverType `json:"receiverTypes,omitempty" yaml:"receiverTypes,omitempty"`
+	// The names of the metric alert rules that must exist and be enabled.
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	MetricAlertNames []string `json:"metricAlertNames,omitempty" yaml:"metricAlertNames,omitempty"`
+}
+
+// ActionGroupReceiverType is a type of receiver that an action group notifies.
+// Alias exists to enable kubebuilder enum validation for arrays of these.
+// +kubebuilder:validation:Enum=Email;Sms;Webhook;Voice;AzureAppPush;ArmRole;AzureFunction;LogicApp;AutomationRunbook;EventHub;Itsm
+type ActionGroupReceiverType string
+
+// Conveys that a subscription's Activity Log must be exported, i.e. that one of its diagnostic
+// settings sends the expected categories to a Log Analytics workspace or a storage account.
+// +kubebuilder:validation:XValidation:message="At least one of workspaceId and storageAccountId must be provided",rule="has(self.workspaceId) || has(self.storageAccountId)"
+type ActivityLogExportRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription whose Activity Log must be exported.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// If provided, the resource ID of the Log Analytics workspace that a diagnostic setting must
+	// export the Activity Log to.
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.OperationalInsights/workspaces/[^/]+$`
+	WorkspaceID string `json:"workspaceId,omitempty" yaml:"workspaceId,omitempty"`
+	// If provided, the resource ID of the storage account that a diagnostic setting must export
+	// the Activity Log to.
+	// +optional
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.Storage/storageAccounts/[^/]+$`
+	StorageAccountID string `json:"storageAccountId,omitempty" yaml:"storageAccountId,omitempty"`
+	// The Activity Log categories that the diagnostic setting must export to each destination. If
+	// not provided, Administrative and Security.
+	// +optional
+	//+kubebuilder:validation:MaxItems=8
+	Categories []ActivityLogCategory `json:"categories,omitempty" yaml:"categories,omitempty"`
+}
+
+// ActivityLogCategory is a category of Activity Log events.
+// Alias exists to enable kubebuilder enum validation for arrays of these.
+// +kubebuilder:validation:Enum=Administrative;Security;ServiceHealth;Alert;Recommendation;Policy;Autoscale;ResourceHealth
+type ActivityLogCategory string
+
+// Conveys that platform (marketplace) VM images must not be deprecated, nor scheduled for
+// deprecation soon, so that clusters aren't built from images that are about to be removed.
+type ImageDeprecationRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that the images are queried with.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that the images are queried in (e.g. eastus).
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The URNs of the images, as publisher:offer:sku:version (e.g.
+	// Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest). The version may be an exact
+	// version, latest, or a version range (e.g. >=22.04.202401010), which resolve to the image's
+	// highest version in the location that satisfies them.
+	//+kubebuilder:validation:MinItems=1
+	//+kubebuilder:validation:MaxItems=20
+	ImageURNs []PlatformImageURN `json:"imageUrns" yaml:"imageUrns"`
+	// If provided, how long before an image's scheduled deprecation validation starts failing
+	// (e.g. 2160h for 90 days). Images scheduled for deprecation further in the future pass. If
+	// not provided, validation fails for every image that's scheduled for deprecation. Deprecated
+	// images always fail.
+	// +optional
+	DeprecationHorizon *metav1.Duration `json:"deprecationHorizon,omitempty" yaml:"deprecationHorizon,omitempty"`
+}
+
+// PlatformImageURN is the URN of a platform image, as publisher:offer:sku:version.
+// Alias exists to enable kubebuilder pattern validation for arrays of these.
+// +kubebuilder:validation:Pattern=`^[^:\s]+:[^:\s]+:[^:\s]+:[^:]+$`
+type PlatformImageURN string
+
+// Conveys that a location must have availability zones for zonal deployments: that compute is
+// offered in enough zones, and optionally that VM sizes are, and that services can be
+// zone-redundant there.
+type ZoneRedundancyRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that resources will be deployed in. Restrictions on the
+	// subscription count, so zones that it can't deploy VMs in aren't counted.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that resources will be deployed in (e.g. eastus).
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The minimum number of availability zones that the location must offer compute in, and that
+	// each of vmSizes must be offered in. If not provided, 3.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=3
+	MinZones int32 `json:"minZones,omitempty" yaml:"minZones,omitempty"`
+	// If provided, the VM sizes that must each be offered in at least minZones zones of the
+	// location (e.g. Standard_D4s_v5).
+	// +optional
+	//+kubebuilder:validation:MaxItems=20
+	VMSizes []string `json:"vmSizes,omitempty" yaml:"vmSizes,omitempty"`
+	// If provided, the services that must be able to be zone-redundant in the location.
+	// +optional
+	//+kubebuilder:validation:MaxItems=2
+	Services []ZoneRedundantService `json:"services,omitempty" yaml:"services,omitempty"`
+}
+
+// ZoneRedundantService is a service that can be zone-redundant in locations with availability
+// zones. StandardLoadBalancer is a Standard SKU load balancer with zone-redundant frontends, and
+// ZoneRedundantStorage is a storage account with the Standard_ZRS SKU.
+// Alias exists to enable kubebuilder enum validation for arrays of these.
+// +kubebuilder:validation:Enum=StandardLoadBalancer;ZoneRedundantStorage
+type ZoneRedundantService string
+
+// Conveys that an Azure Arc custom location, e.g. one that AKS hybrid deploys to, must be
+// provisioned, that its cluster extensions must be provisioned, and that its host, an Arc resource
+// bridge or an Arc-enabled Kubernetes cluster, must be connected.
+type CustomLocationRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The resource ID of the custom location (e.g.
+	// /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.ExtendedLocation/customLocations/{name}).
+	//+kubebuilder:validation:Pattern=`^/subscriptions/[^/]+/resource[Gg]roups/[^/]+/providers/Microsoft\.ExtendedLocation/customLocations/[^/]+$`
+	CustomLocationID string `json:"customLocationId" yaml:"customLocationId"`
+	// The types of the cluster extensions that the custom location must have, each provisioned
+	// (e.g. microsoft.azstackhci.operator). If not provided, each of the custom location's
+	// cluster extensions must be provisioned.
+	// +optional
+	//+kubebuilder:validation:MaxItems=10
+	ExtensionTypes []string `json:"extensionTypes,omitempty" yaml:"extensionTypes,omitempty"`
+}
+
+// Conveys that a Key Vault name must be free for automation to create a vault with, or must be
+// held by a soft-deleted vault for automation to recover, and optionally that the vault with the
+// name must have soft delete and purge protection, e.g. because customer-managed keys depend on
+// its keys.
+// +kubebuilder:validation:XValidation:message="vault can't be combined with deletedVault MustExist, since a name held by a soft-deleted vault can't be in use",rule="!has(self.vault) || !has(self.deletedVault) || self.deletedVault != 'MustExist'"
+type KeyVaultRecoveryRule struct {
+	// Unique identifier for the rule in the validator. Used to ensure conditions do not overwrite
+	// each other.
+	Name string `json:"name" yaml:"name"`
+	// Labels added to the details of the rule's condition, on top of spec.resultLabels. A label
+	// set in both takes its value from here.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// The ID of the subscription that the vault is, or was, in.
+	//+kubebuilder:validation:MinLength=1
+	SubscriptionID string `json:"subscriptionId" yaml:"subscriptionId"`
+	// The location that the vault is, or was, in (e.g. eastus). Soft-deleted vaults are kept by
+	// location.
+	//+kubebuilder:validation:MinLength=1
+	Location string `json:"location" yaml:"location"`
+	// The name of the vault.
+	//+kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9-]{1,22}[a-zA-Z0-9]$`
+	VaultName string `json:"vaultName" yaml:"vaultName"`
+	// Whether a soft-deleted vault may hold the name in the location: MustNotExist, for
+	// automation that creates the vault, which fails while a soft-deleted vault holds its name, or
+	// MustExist, for automation that recovers it. If not provided, MustNotExist.
+	// +optional
+	DeletedVault DeletedVaultExpectation `json:"deletedVault,omitempty" yaml:"deletedVault,omitempty"`
+	// If provided, the vault with the name must exist, with these settings.
+	// +optional
+	Vault *KeyVaultSettings `json:"vault,omitempty" yaml:"vault,omitempty"`
+}
+
+// DeletedVaultExpectation is whether a soft-deleted Key Vault must hold a name.
+// +kubebuilder:validation:Enum=MustNotExist;MustExist
+type DeletedVaultExpectation string
+
+const (
+	DeletedVaultMustNotExist DeletedVaultExpectation = "MustNotExist"
+	DeletedVaultMustExist    DeletedVaultExpectation = "MustExist"
+)
+
+// KeyVaultSettings are the recovery settings that a Key Vault must have.
+type KeyVaultSettings struct {
+	// The resource group of the vault.
+	//+kubebuilder:validation:MinLength=1
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+	// If true, the vault must have soft delete enabled.
+	// +optional
+	RequireSoftDelete bool `json:"requireSoftDelete,omitempty" yaml:"requireSoftDelete,omitempty"`
+	// If true, the vault must have purge protection enabled, so that it and its keys can't be
+	// purged before their retention period ends.
+	// +optional
+	RequirePurgeProtection bool `json:"requirePurgeProtection,omitempty" yaml:"requirePurgeProtection,omitempty"`
+	// If provided, the minimum number of days that the vault must retain itself and its objects
+	// for once they're soft-deleted.
+	// +optional
+	//+kubebuilder:validation:Minimum=7
+	//+kubebuilder:validation:Maximum=90
+	MinSoftDeleteRetentionDays int32 `json:"minSoftDeleteRetentionDays,omitempty" yaml:"minSoftDeleteRetentionDays,omitempty"`
 }
 
 type AzureAuth struct {
 	// If true, the AzureValidator will use the Azure SDK's default credential chain to authenticate.
 	// Set to true if using WorkloadIdentityCredentials.
 	Implicit bool `json:"implicit" yaml:"implicit"`
-	// Name of a Secret in the same namespace as the AzureValidator that contains Azure credentials.
+	// Name of a Secret in secretNamespace that contains Azure credentials.
 	// The secret data's keys and values are expected to align with valid Azure environment variable credentials,
 	// per the options defined in https://pkg.go.dev/github.com/Azure/azure-sdk-for-go/sdk/azidentity#readme-environment-variables.
 	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+	// Namespace of the Secret named by secretName. Defaults to the AzureValidator's namespace.
+	// Other namespaces must be allowed by the plugin's --allowed-secret-namespaces flag.
+	// +optional
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	SecretNamespace string `json:"secretNamespace,omitempty" yaml:"secretNamespace,omitempty"`
+}
+
+// AuthSecretNamespace returns the namespace of the AzureValidator's auth Secret.
+func (r *AzureValidator) AuthSecretNamespace() string {
+	if r.Spec.Auth.SecretNamespace != "" {
+		return r.Spec.Auth.SecretNamespace
+	}
+	return r.Namespace
 }
 
 // ActionStr is a type used for Action strings and DataAction strings. Alias exists to enable
@@ -72,7 +2115,10 @@ type ActionStr string
 
 // Conveys that the security principal should be the member of a role assignment that provides the
 // specified role for the specified scope. Scope can be either subscription, resource group, or
-// resource.
+// resource. The same permissions can be required at several scopes with scopes, scopePatterns, and
+// managementGroupId.
+// +kubebuilder:validation:XValidation:message="At least one of scope, scopes, scopePatterns, and managementGroupId must be provided",rule="has(self.scope) || has(self.scopes) || has(self.scopePatterns) || has(self.managementGroupId)"
+// +kubebuilder:validation:XValidation:message="createdAfter must be before createdBefore",rule="!has(self.createdAfter) || !has(self.createdBefore) || self.createdAfter < self.createdBefore"
 type PermissionSet struct {
 	// If provided, the actions that the role must be able to perform. Must not contain any
 	// wildcards. If not specified, the role is assumed to already be able to perform all required
@@ -89,14 +2135,182 @@ type PermissionSet struct {
 	// The minimum scope of the role. Role assignments found at higher level scopes will satisfy
 	// this. For example, a role assignment found with subscription scope will satisfy a permission
 	// set where the role scope specified is a resource group within that subscription.
-	Scope string `json:"scope" yaml:"scope"`
+	// +optional
+	Scope string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// More scopes at which the permissions are validated, each like scope.
+	// +optional
+	// +kubebuilder:validation:MaxItems=50
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// Patterns of resource group scopes at which the permissions are validated, in the form
+	// /subscriptions/<id>/resourceGroups/<glob>. The glob is matched, case-insensitively, against
+	// the names of the subscription's resource groups, and may contain the wildcards *, ? and
+	// character classes ([...]).
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:XValidation:message="Scope patterns must be in the form /subscriptions/<id>/resourceGroups/<glob>",rule="self.all(item, item.matches('^(?i)/subscriptions/[^/]+/resourceGroups/[^/]+$'))"
+	ScopePatterns []string `json:"scopePatterns,omitempty" yaml:"scopePatterns,omitempty"`
+	// If true, a scope pattern that matches no resource groups is only noted in the details of the
+	// rule's condition. Otherwise, the rule fails.
+	// +optional
+	AllowEmptyScopePatterns bool `json:"allowEmptyScopePatterns,omitempty" yaml:"allowEmptyScopePatterns,omitempty"`
+	// The ID of a management group (e.g. platform, rather than its resource ID) at each of whose
+	// subscriptions the permissions are validated, including the subscriptions of its nested
+	// management groups. The subscriptions are listed on each evaluation, so ones added to the
+	// management group are validated without changing the spec. A management group without
+	// subscriptions fails the rule.
+	// +optional
+	// +kubebuilder:validation:MaxLength=90
+	// +kubebuilder:validation:XValidation:message="managementGroupId must be the ID of a management group, not its resource ID",rule="!self.contains('/')"
+	ManagementGroupID string `json:"managementGroupId,omitempty" yaml:"managementGroupId,omitempty"`
+	// If provided, each Action and DataAction must be permitted by a role assignment created at
+	// or after this time, e.g. to check that privileged access was granted recently by a
+	// just-in-time process. Role assignments whose creation time ARM doesn't report don't count.
+	// +optional
+	CreatedAfter *metav1.Time `json:"createdAfter,omitempty" yaml:"createdAfter,omitempty"`
+	// If provided, each Action and DataAction must be permitted by a role assignment created
+	// before this time. Role assignments whose creation time ARM doesn't report don't count.
+	// +optional
+	CreatedBefore *metav1.Time `json:"createdBefore,omitempty" yaml:"createdBefore,omitempty"`
+	// If provided, a regular expression (RE2 syntax) that the description of a role assignment
+	// permitting each Action and DataAction must match, e.g. to check that it carries a governance
+	// tag. A role assignment without a description has an empty one.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	DescriptionPattern string `json:"descriptionPattern,omitempty" yaml:"descriptionPattern,omitempty"`
+	// If provided, the ABAC condition that a role assignment permitting each Action and DataAction
+	// must have, exactly. If empty, the role assignment must have no condition, i.e. be
+	// unrestricted.
+	// +optional
+	// +kubebuilder:validation:MaxLength=8192
+	Condition *string `json:"condition,omitempty" yaml:"condition,omitempty"`
+}
+
+// HasCreationWindow returns whether the permission set restricts when the role assignments that
+// permit its Actions and DataActions were created.
+func (p PermissionSet) HasCreationWindow() bool {
+	return p.CreatedAfter != nil || p.CreatedBefore != nil
+}
+
+// HasAssignmentAssertions returns whether the permission set requires more of the role
+// assignments that permit its Actions than permitting them: a creation window, a description
+// pattern, or a condition.
+func (p PermissionSet) HasAssignmentAssertions() bool {
+	return p.HasCreationWindow() || p.DescriptionPattern != "" || p.Condition != nil
+}
+
+// BaseScopes returns the scopes of the permission set that are known without listing resource
+// groups: scope, scopes, and the subscriptions of scopePatterns, in that order. The subscriptions of
+// managementGroupId aren't known without listing them, so they aren't included.
+func (p PermissionSet) BaseScopes() []string {
+	var scopes []string
+	if p.Scope != "" {
+		scopes = append(scopes, p.Scope)
+	}
+	scopes = append(scopes, p.Scopes...)
+	for _, pattern := range p.ScopePatterns {
+		if i := strings.Index(strings.ToLower(pattern), "/resourcegroups/"); i > 0 {
+			scopes = append(scopes, pattern[:i])
+		}
+	}
+	return scopes
 }
 
 // AzureValidatorStatus defines the observed state of AzureValidator
-type AzureValidatorStatus struct{}
+type AzureValidatorStatus struct {
+	// Conditions describe the state of the AzureValidator itself (as opposed to the results of its
+	// rules, which are stored in its ValidationResult). For example, whether validation is paused.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the generation of the AzureValidator when its rules were most recently
+	// evaluated. Once it equals metadata.generation, the rule results (and the ValidationResult)
+	// reflect the latest change to the spec.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// RuleResults describe the most recent evaluation of each of the AzureValidator's rules.
+	// +optional
+	// +listType=map
+	// +listMapKey=validationType
+	// +listMapKey=name
+	RuleResults []RuleResult `json:"ruleResults,omitempty"`
+	// LastRunAPIRequests counts the ARM requests made during the most recent evaluation of the
+	// AzureValidator's rules, e.g. to budget for ARM's limits on requests per principal. Rules
+	// whose previous results were reused made none.
+	// +optional
+	LastRunAPIRequests *APIRequests `json:"lastRunAPIRequests,omitempty"`
+	// DryRun is the outcome of the most recent evaluation of the AzureValidator's rules while its
+	// dry-run annotation was set, which was not written to its ValidationResult. It is cleared once
+	// the rules are evaluated with the annotation removed.
+	// +optional
+	DryRun *DryRunResult `json:"dryRun,omitempty"`
+}
+
+// DryRunResult is what an AzureValidator's ValidationResult would have reported, had its rules not
+// been evaluated in dry-run mode.
+type DryRunResult struct {
+	// When the rules were evaluated.
+	EvaluationTime metav1.Time `json:"evaluationTime"`
+	// The generation of the AzureValidator that was evaluated.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The state the ValidationResult would have had.
+	State vapi.ValidationState `json:"state"`
+	// The conditions the ValidationResult would have had, one per rule.
+	// +optional
+	Conditions []vapi.ValidationCondition `json:"conditions,omitempty"`
+}
+
+// APIRequests counts ARM requests, including retries.
+type APIRequests struct {
+	// The number of requests.
+	Total int `json:"total"`
+	// The number of requests made by each type of Azure client (e.g. RoleAssignments).
+	// +optional
+	ByClientType map[string]int `json:"byClientType,omitempty"`
+}
+
+// RuleResult describes the most recent evaluation of a rule, and when the rule's state last
+// changed. A recent LastTransitionTime on a rule that is currently passing means the rule has
+// recently been failing.
+type RuleResult struct {
+	// The name of the rule.
+	Name string `json:"name"`
+	// The validation type of the rule (e.g. azure-rbac).
+	ValidationType string `json:"validationType"`
+	// The state of the rule after its most recent evaluation. InProgress means that the rule is
+	// pending within its grace period.
+	// +kubebuilder:validation:Enum=Succeeded;Failed;InProgress
+	State vapi.ValidationState `json:"state"`
+	// How long the most recent evaluation of the rule took.
+	Duration metav1.Duration `json:"duration"`
+	// When the rule was most recently evaluated.
+	LastEvaluationTime metav1.Time `json:"lastEvaluationTime"`
+	// When the rule's state last changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// A hash of the rule's spec when it was most recently evaluated. Empty if the evaluation failed
+	// with an error, in which case the result is never reused.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// The generation of the AzureValidator when the rule was most recently evaluated.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// When the grace period of a pending rule ends. The rule fails if it's still missing
+	// permissions when next evaluated after this.
+	// +optional
+	PendingUntil *metav1.Time `json:"pendingUntil,omitempty"`
+	// Whether the rule wasn't evaluated, because spec.runDeadline elapsed before it was. The rule
+	// is then Failed, its LastEvaluationTime is when it was skipped, and the next reconcile
+	// evaluates it before the rules that were evaluated.
+	// +optional
+	NotEvaluated bool `json:"notEvaluated,omitempty"`
+}
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // AzureValidator is the Schema for the azurevalidators API
 type AzureValidator struct {
@@ -0,0 +1,407 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/versions"
+)
+
+// uuidPattern matches the UUIDs that Azure uses as the IDs of principals and subscriptions.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate checks the spec without a cluster or Azure access: that rule names are unique, that
+// principal and subscription IDs are UUIDs, that scopes are ARM resource IDs, that IP ranges are
+// IPv4 addresses or CIDRs, that permission sets have Actions or DataActions, that image versions
+// are versions, version ranges, or latest, and that failure message templates parse and only
+// refer to fields that failures have. Some of this is also
+// enforced by the CRD's schema, which isn't available offline. Errors are reported under the path of the spec that's passed in.
+func (s AzureValidatorSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	validateNames(&errs, path.Child("rbacRules"), s.RBACRules, func(r RBACRule) string { return r.Name })
+	validateNames(&errs, path.Child("keyVaultCertificateRules"), s.KeyVaultCertificateRules, func(r KeyVaultCertificateRule) string { return r.Name })
+	validateNames(&errs, path.Child("aksClusterRules"), s.AKSClusterRules, func(r AKSClusterRule) string { return r.Name })
+	validateNames(&errs, path.Child("natGatewayRules"), s.NATGatewayRules, func(r NATGatewayRule) string { return r.Name })
+	validateNames(&errs, path.Child("vnetPeeringRules"), s.VNetPeeringRules, func(r VNetPeeringRule) string { return r.Name })
+	validateNames(&errs, path.Child("routeTableRules"), s.RouteTableRules, func(r RouteTableRule) string { return r.Name })
+	validateNames(&errs, path.Child("imageCompatibilityRules"), s.ImageCompatibilityRules, func(r ImageCompatibilityRule) string { return r.Name })
+	validateNames(&errs, path.Child("imageReplicationRules"), s.ImageReplicationRules, func(r ImageReplicationRule) string { return r.Name })
+	validateNames(&errs, path.Child("vmSecurityRules"), s.VMSecurityRules, func(r VMSecurityRule) string { return r.Name })
+	validateNames(&errs, path.Child("vmSizeRules"), s.VMSizeRules, func(r VMSizeRule) string { return r.Name })
+	validateNames(&errs, path.Child("diskZoneRules"), s.DiskZoneRules, func(r DiskZoneRule) string { return r.Name })
+	validateNames(&errs, path.Child("templatePermissionRules"), s.TemplatePermissionRules, func(r TemplatePermissionRule) string { return r.Name })
+	validateNames(&errs, path.Child("policyExemptionRules"), s.PolicyExemptionRules, func(r PolicyExemptionRule) string { return r.Name })
+	validateNames(&errs, path.Child("defenderPlanRules"), s.DefenderPlanRules, func(r DefenderPlanRule) string { return r.Name })
+	validateNames(&errs, path.Child("budgetRules"), s.BudgetRules, func(r BudgetRule) string { return r.Name })
+	validateNames(&errs, path.Child("resourceLockRules"), s.ResourceLockRules, func(r ResourceLockRule) string { return r.Name })
+	validateNames(&errs, path.Child("firewallPolicyRules"), s.FirewallPolicyRules, func(r FirewallPolicyRule) string { return r.Name })
+	validateNames(&errs, path.Child("bastionRules"), s.BastionRules, func(r BastionRule) string { return r.Name })
+	validateNames(&errs, path.Child("ddosProtectionRules"), s.DDoSProtectionRules, func(r DDoSProtectionRule) string { return r.Name })
+	validateNames(&errs, path.Child("publicIPPrefixRules"), s.PublicIPPrefixRules, func(r PublicIPPrefixRule) string { return r.Name })
+	validateNames(&errs, path.Child("eventHubRules"), s.EventHubRules, func(r EventHubRule) string { return r.Name })
+	validateNames(&errs, path.Child("applicationGatewayRules"), s.ApplicationGatewayRules, func(r ApplicationGatewayRule) string { return r.Name })
+	validateNames(&errs, path.Child("cosmosDBRules"), s.CosmosDBRules, func(r CosmosDBRule) string { return r.Name })
+	validateNames(&errs, path.Child("sqlServerRules"), s.SQLServerRules, func(r SQLServerRule) string { return r.Name })
+	validateNames(&errs, path.Child("globalEndpointRules"), s.GlobalEndpointRules, func(r GlobalEndpointRule) string { return r.Name })
+	validateNames(&errs, path.Child("expressRouteRules"), s.ExpressRouteRules, func(r ExpressRouteRule) string { return r.Name })
+	validateNames(&errs, path.Child("vpnGatewayRules"), s.VPNGatewayRules, func(r VPNGatewayRule) string { return r.Name })
+	validateNames(&errs, path.Child("networkWatcherRules"), s.NetworkWatcherRules, func(r NetworkWatcherRule) string { return r.Name })
+	validateNames(&errs, path.Child("proximityPlacementGroupRules"), s.ProximityPlacementGroupRules, func(r ProximityPlacementGroupRule) string { return r.Name })
+	validateNames(&errs, path.Child("encryptionAtHostRules"), s.EncryptionAtHostRules, func(r EncryptionAtHostRule) string { return r.Name })
+	validateNames(&errs, path.Child("groupMembershipRules"), s.GroupMembershipRules, func(r GroupMembershipRule) string { return r.Name })
+	validateNames(&errs, path.Child("appPermissionRules"), s.AppPermissionRules, func(r AppPermissionRule) string { return r.Name })
+	validateNames(&errs, path.Child("blobContainerRules"), s.BlobContainerRules, func(r BlobContainerRule) string { return r.Name })
+	validateNames(&errs, path.Child("storageNetworkRules"), s.StorageNetworkRules, func(r StorageNetworkRule) string { return r.Name })
+	validateNames(&errs, path.Child("fileShareRules"), s.FileShareRules, func(r FileShareRule) string { return r.Name })
+	validateNames(&errs, path.Child("subnetDelegationRules"), s.SubnetDelegationRules, func(r SubnetDelegationRule) string { return r.Name })
+	validateNames(&errs, path.Child("monitorAlertRules"), s.MonitorAlertRules, func(r MonitorAlertRule) string { return r.Name })
+	validateNames(&errs, path.Child("activityLogExportRules"), s.ActivityLogExportRules, func(r ActivityLogExportRule) string { return r.Name })
+	validateNames(&errs, path.Child("imageDeprecationRules"), s.ImageDeprecationRules, func(r ImageDeprecationRule) string { return r.Name })
+	validateNames(&errs, path.Child("zoneRedundancyRules"), s.ZoneRedundancyRules, func(r ZoneRedundancyRule) string { return r.Name })
+	validateNames(&errs, path.Child("customLocationRules"), s.CustomLocationRules, func(r CustomLocationRule) string { return r.Name })
+	validateNames(&errs, path.Child("keyVaultRecoveryRules"), s.KeyVaultRecoveryRules, func(r KeyVaultRecoveryRule) string { return r.Name })
+
+	for i, rule := range s.RBACRules {
+		rulePath := path.Child("rbacRules").Index(i)
+		errs = append(errs, rule.PrincipalErrors(rulePath)...)
+		if rule.PrincipalID != "" {
+			validateUUID(&errs, rulePath.Child("principalId"), rule.PrincipalID)
+		}
+		for j, id := range rule.PrincipalIDs {
+			validateUUID(&errs, rulePath.Child("principalIds").Index(j), id)
+		}
+		if rule.PrincipalClientID != "" {
+			validateUUID(&errs, rulePath.Child("principalClientId"), rule.PrincipalClientID)
+		}
+		if len(rule.Permissions) == 0 {
+			errs = append(errs, field.Required(rulePath.Child("permissionSets"), "at least one permission set must be provided"))
+		}
+		for j, set := range rule.Permissions {
+			setPath := rulePath.Child("permissionSets").Index(j)
+			if len(set.Actions) == 0 && len(set.DataActions) == 0 {
+				errs = append(errs, field.Required(setPath, "each permission set must have Actions, DataActions, or both defined"))
+			}
+			if set.Scope == "" && len(set.Scopes) == 0 && len(set.ScopePatterns) == 0 && set.ManagementGroupID == "" {
+				errs = append(errs, field.Required(setPath.Child("scope"), "at least one of scope, scopes, scopePatterns, and managementGroupId must be provided"))
+			}
+			if set.Scope != "" {
+				validateScope(&errs, setPath.Child("scope"), set.Scope)
+			}
+			for k, scope := range set.Scopes {
+				validateScope(&errs, setPath.Child("scopes").Index(k), scope)
+			}
+			if strings.Contains(set.ManagementGroupID, "/") {
+				errs = append(errs, field.Invalid(setPath.Child("managementGroupId"), set.ManagementGroupID, "must be the ID of a management group, not its resource ID"))
+			}
+		}
+		errs = append(errs, rule.RawFilterErrors(rulePath)...)
+		validateTemplate(&errs, rulePath.Child("failureMessageTemplate"), rule.FailureMessageTemplate)
+	}
+	for i, rule := range s.TemplatePermissionRules {
+		rulePath := path.Child("templatePermissionRules").Index(i)
+		validateUUID(&errs, rulePath.Child("principalId"), rule.PrincipalID)
+		validateScope(&errs, rulePath.Child("scope"), rule.Scope)
+		validateTemplate(&errs, rulePath.Child("failureMessageTemplate"), rule.FailureMessageTemplate)
+	}
+	for i, rule := range s.PolicyExemptionRules {
+		validateScope(&errs, path.Child("policyExemptionRules").Index(i).Child("scope"), rule.Scope)
+	}
+	for i, rule := range s.BudgetRules {
+		validateScope(&errs, path.Child("budgetRules").Index(i).Child("scope"), rule.Scope)
+	}
+	for i, rule := range s.ResourceLockRules {
+		for j, scope := range rule.Scopes {
+			validateScope(&errs, path.Child("resourceLockRules").Index(i).Child("scopes").Index(j), scope)
+		}
+	}
+	for i, rule := range s.FirewallPolicyRules {
+		validateScope(&errs, path.Child("firewallPolicyRules").Index(i).Child("firewallPolicyId"), rule.FirewallPolicyID)
+	}
+	for i, rule := range s.BastionRules {
+		validateScope(&errs, path.Child("bastionRules").Index(i).Child("virtualNetworkId"), rule.VirtualNetworkID)
+	}
+	for i, rule := range s.DDoSProtectionRules {
+		rulePath := path.Child("ddosProtectionRules").Index(i)
+		for j, id := range rule.VirtualNetworkIDs {
+			validateScope(&errs, rulePath.Child("virtualNetworkIds").Index(j), string(id))
+		}
+		if rule.DDoSProtectionPlanID != "" {
+			validateScope(&errs, rulePath.Child("ddosProtectionPlanId"), rule.DDoSProtectionPlanID)
+		}
+	}
+	for i, rule := range s.PublicIPPrefixRules {
+		validateScope(&errs, path.Child("publicIPPrefixRules").Index(i).Child("publicIPPrefixId"), rule.PublicIPPrefixID)
+	}
+	for i, rule := range s.EventHubRules {
+		validateScope(&errs, path.Child("eventHubRules").Index(i).Child("namespaceId"), rule.NamespaceID)
+	}
+	for i, rule := range s.ApplicationGatewayRules {
+		rulePath := path.Child("applicationGatewayRules").Index(i)
+		validateScope(&errs, rulePath.Child("applicationGatewayId"), rule.ApplicationGatewayID)
+		if rule.FirewallPolicyID != "" {
+			validateScope(&errs, rulePath.Child("firewallPolicyId"), rule.FirewallPolicyID)
+		}
+	}
+	for i, rule := range s.CosmosDBRules {
+		validateScope(&errs, path.Child("cosmosDBRules").Index(i).Child("databaseAccountId"), rule.DatabaseAccountID)
+	}
+	for i, rule := range s.SQLServerRules {
+		rulePath := path.Child("sqlServerRules").Index(i)
+		validateScope(&errs, rulePath.Child("serverId"), rule.ServerID)
+		if rule.AzureADAdministratorID != "" {
+			validateUUID(&errs, rulePath.Child("azureADAdministratorId"), rule.AzureADAdministratorID)
+		}
+	}
+	for i, rule := range s.GlobalEndpointRules {
+		rulePath := path.Child("globalEndpointRules").Index(i)
+		if rule.TrafficManagerProfileID == "" {
+			errs = append(errs, field.Required(rulePath.Child("trafficManagerProfileId"), "a profile must be provided"))
+		} else {
+			validateScope(&errs, rulePath.Child("trafficManagerProfileId"), rule.TrafficManagerProfileID)
+		}
+	}
+	for i, rule := range s.ExpressRouteRules {
+		rulePath := path.Child("expressRouteRules").Index(i)
+		validateScope(&errs, rulePath.Child("circuitId"), rule.CircuitID)
+		if rule.ConnectionID != "" {
+			validateScope(&errs, rulePath.Child("connectionId"), rule.ConnectionID)
+		}
+	}
+	for i, rule := range s.VPNGatewayRules {
+		validateScope(&errs, path.Child("vpnGatewayRules").Index(i).Child("gatewayId"), rule.GatewayID)
+	}
+	for i, rule := range s.NetworkWatcherRules {
+		rulePath := path.Child("networkWatcherRules").Index(i)
+		validateUUID(&errs, rulePath.Child("subscriptionId"), rule.SubscriptionID)
+		for j, id := range rule.NetworkSecurityGroupIDs {
+			validateScope(&errs, rulePath.Child("networkSecurityGroupIds").Index(j), id)
+		}
+		if rule.StorageAccountID != "" {
+			validateScope(&errs, rulePath.Child("storageAccountId"), rule.StorageAccountID)
+		}
+	}
+	for i, rule := range s.ProximityPlacementGroupRules {
+		validateScope(&errs, path.Child("proximityPlacementGroupRules").Index(i).Child("proximityPlacementGroupId"), rule.ProximityPlacementGroupID)
+	}
+	for i, rule := range s.ImageCompatibilityRules {
+		if rule.SubscriptionID != "" {
+			validateUUID(&errs, path.Child("imageCompatibilityRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+		}
+	}
+	for i, rule := range s.VMSecurityRules {
+		if rule.SubscriptionID != "" {
+			validateUUID(&errs, path.Child("vmSecurityRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+		}
+	}
+	for i, rule := range s.VMSizeRules {
+		validateUUID(&errs, path.Child("vmSizeRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	for i, rule := range s.DiskZoneRules {
+		validateUUID(&errs, path.Child("diskZoneRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	for i, rule := range s.DefenderPlanRules {
+		validateUUID(&errs, path.Child("defenderPlanRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	for i, rule := range s.EncryptionAtHostRules {
+		validateUUID(&errs, path.Child("encryptionAtHostRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	for i, rule := range s.GroupMembershipRules {
+		rulePath := path.Child("groupMembershipRules").Index(i)
+		if rule.GroupID != "" {
+			validateUUID(&errs, rulePath.Child("groupId"), rule.GroupID)
+		}
+		for j, id := range rule.MemberIDs {
+			validateUUID(&errs, rulePath.Child("memberIds").Index(j), id)
+		}
+	}
+	for i, rule := range s.AppPermissionRules {
+		rulePath := path.Child("appPermissionRules").Index(i)
+		validateUUID(&errs, rulePath.Child("applicationId"), rule.ApplicationID)
+		for j, p := range rule.Permissions {
+			validateUUID(&errs, rulePath.Child("permissions").Index(j).Child("resourceAppId"), p.ResourceAppID)
+		}
+	}
+	for i, rule := range s.BlobContainerRules {
+		validateScope(&errs, path.Child("blobContainerRules").Index(i).Child("storageAccountId"), rule.StorageAccountID)
+	}
+	for i, rule := range s.StorageNetworkRules {
+		rulePath := path.Child("storageNetworkRules").Index(i)
+		validateScope(&errs, rulePath.Child("storageAccountId"), rule.StorageAccountID)
+		for j, id := range rule.SubnetIDs {
+			validateScope(&errs, rulePath.Child("subnetIds").Index(j), id)
+		}
+		for j, r := range rule.IPRanges {
+			validateIPv4Range(&errs, rulePath.Child("ipRanges").Index(j), r)
+		}
+	}
+	for i, rule := range s.FileShareRules {
+		validateScope(&errs, path.Child("fileShareRules").Index(i).Child("storageAccountId"), rule.StorageAccountID)
+	}
+	for i, rule := range s.SubnetDelegationRules {
+		for j, id := range rule.SubnetIDs {
+			validateScope(&errs, path.Child("subnetDelegationRules").Index(i).Child("subnetIds").Index(j), string(id))
+		}
+	}
+	for i, rule := range s.MonitorAlertRules {
+		validateUUID(&errs, path.Child("monitorAlertRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	for i, rule := range s.ActivityLogExportRules {
+		rulePath := path.Child("activityLogExportRules").Index(i)
+		validateUUID(&errs, rulePath.Child("subscriptionId"), rule.SubscriptionID)
+		if rule.WorkspaceID == "" && rule.StorageAccountID == "" {
+			errs = append(errs, field.Required(rulePath.Child("workspaceId"), "at least one of workspaceId and storageAccountId must be provided"))
+		}
+		if rule.WorkspaceID != "" {
+			validateScope(&errs, rulePath.Child("workspaceId"), rule.WorkspaceID)
+		}
+		if rule.StorageAccountID != "" {
+			validateScope(&errs, rulePath.Child("storageAccountId"), rule.StorageAccountID)
+		}
+	}
+	for i, rule := range s.ImageReplicationRules {
+		rulePath := path.Child("imageReplicationRules").Index(i).Child("imageVersionId")
+		if j := strings.LastIndex(rule.ImageVersionID, "/versions/"); j >= 0 {
+			validateVersionConstraint(&errs, rulePath, rule.ImageVersionID, rule.ImageVersionID[j+len("/versions/"):])
+		}
+	}
+	for i, rule := range s.ImageDeprecationRules {
+		rulePath := path.Child("imageDeprecationRules").Index(i)
+		validateUUID(&errs, rulePath.Child("subscriptionId"), rule.SubscriptionID)
+		for j, urn := range rule.ImageURNs {
+			if parts := strings.Split(string(urn), ":"); len(parts) == 4 {
+				validateVersionConstraint(&errs, rulePath.Child("imageUrns").Index(j), string(urn), parts[3])
+			}
+		}
+	}
+	for i, rule := range s.ZoneRedundancyRules {
+		validateUUID(&errs, path.Child("zoneRedundancyRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	for i, rule := range s.CustomLocationRules {
+		validateScope(&errs, path.Child("customLocationRules").Index(i).Child("customLocationId"), rule.CustomLocationID)
+	}
+	for i, rule := range s.KeyVaultRecoveryRules {
+		validateUUID(&errs, path.Child("keyVaultRecoveryRules").Index(i).Child("subscriptionId"), rule.SubscriptionID)
+	}
+	return errs
+}
+
+// Warnings returns warnings about parts of the spec that are valid but probably not what was
+// meant, under the path of the spec that's passed in: raw filters of RBAC rules that don't filter
+// on the rules' principals, and may therefore list many role assignments of unrelated principals.
+func (s AzureValidatorSpec) Warnings(path *field.Path) []string {
+	var warnings []string
+	for i, rule := range s.RBACRules {
+		if rule.RawFilter == "" || slices.ContainsFunc(rule.Principals(), func(id string) bool {
+			return strings.Contains(strings.ToLower(rule.RawFilter), strings.ToLower(id))
+		}) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s: doesn't filter on the rule's principals, so its results may include role assignments of unrelated principals, which are listed and then dropped",
+			path.Child("rbacRules").Index(i).Child("rawFilter")))
+	}
+	return warnings
+}
+
+// PrincipalErrors returns the problems with an RBAC rule's principals, under the rule's path: having
+// none, and having a principalClientId alongside principalId or principalIds, which would leave it
+// unclear which principal the rule is about.
+func (r RBACRule) PrincipalErrors(rulePath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	principals := r.Principals()
+	if len(principals) == 0 && r.PrincipalClientID == "" {
+		errs = append(errs, field.Required(rulePath.Child("principalId"), "principalId, principalIds, or principalClientId must be set"))
+	}
+	if len(principals) > 0 && r.PrincipalClientID != "" {
+		errs = append(errs, field.Forbidden(rulePath.Child("principalClientId"), "principalClientId can't be combined with principalId or principalIds"))
+	}
+	return errs
+}
+
+// rawFilterInvalid matches the characters that an RBAC rule's rawFilter can't have, because it's
+// added to the URL query of role assignment requests without escaping.
+var rawFilterInvalid = regexp.MustCompile(`[\s&#]`)
+
+// RawFilterErrors returns the problems with an RBAC rule's rawFilter, under the rule's path:
+// characters that would break the URL query it's added to, and being combined with
+// expandPrincipalGroups, whose role assignments of groups the plugin can't tell from those of
+// unrelated principals.
+func (r RBACRule) RawFilterErrors(rulePath *field.Path) field.ErrorList {
+	if r.RawFilter == "" {
+		return nil
+	}
+	var errs field.ErrorList
+	if rawFilterInvalid.MatchString(r.RawFilter) {
+		errs = append(errs, field.Invalid(rulePath.Child("rawFilter"), r.RawFilter, "must be URL-encoded, without whitespace, & or #"))
+	}
+	if r.ExpandPrincipalGroups {
+		errs = append(errs, field.Forbidden(rulePath.Child("rawFilter"), "rawFilter can't be combined with expandPrincipalGroups"))
+	}
+	return errs
+}
+
+// validateNames checks that each of a list's rules has a name, and that no two have the same one.
+func validateNames[T any](errs *field.ErrorList, path *field.Path, rules []T, name func(T) string) {
+	seen := map[string]bool{}
+	for i, rule := range rules {
+		ruleName := name(rule)
+		switch {
+		case ruleName == "":
+			*errs = append(*errs, field.Required(path.Index(i).Child("name"), "rules must have a name"))
+		case seen[ruleName]:
+			*errs = append(*errs, field.Duplicate(path.Index(i).Child("name"), ruleName))
+		}
+		seen[ruleName] = true
+	}
+}
+
+func validateUUID(errs *field.ErrorList, path *field.Path, id string) {
+	if !uuidPattern.MatchString(id) {
+		*errs = append(*errs, field.Invalid(path, id, "must be a UUID"))
+	}
+}
+
+// validateIPv4Range checks that a range is an IPv4 address or CIDR, which is all that storage
+// account IP rules accept.
+func validateIPv4Range(errs *field.ErrorList, path *field.Path, r string) {
+	if addr, err := netip.ParseAddr(r); err == nil && addr.Is4() {
+		return
+	}
+	if p, err := netip.ParsePrefix(r); err == nil && p.Addr().Is4() {
+		return
+	}
+	*errs = append(*errs, field.Invalid(path, r, "must be an IPv4 address or CIDR"))
+}
+
+// validateScope checks that a scope is an ARM resource ID (e.g. /subscriptions/{id}, or a
+// management group, resource group, or resource in one), whose subscription ID, if it has one, is
+// a UUID.
+func validateScope(errs *field.ErrorList, path *field.Path, scope string) {
+	id, err := arm.ParseResourceID(scope)
+	switch {
+	case err != nil:
+		*errs = append(*errs, field.Invalid(path, scope, "must be an ARM resource ID, e.g. /subscriptions/{id}/resourceGroups/{rg}"))
+	case id.SubscriptionID != "" && !uuidPattern.MatchString(id.SubscriptionID):
+		*errs = append(*errs, field.Invalid(path, scope, "must have a UUID as its subscription ID"))
+	}
+}
+
+// validateVersionConstraint checks that the version of an image is an exact version, latest, or a
+// version range.
+func validateVersionConstraint(errs *field.ErrorList, path *field.Path, value, constraint string) {
+	if _, err := versions.ParseConstraint(constraint); err != nil {
+		*errs = append(*errs, field.Invalid(path, value, err.Error()))
+	}
+}
+
+func validateTemplate(errs *field.ErrorList, path *field.Path, text string) {
+	if _, err := messages.NewTemplates(text); err != nil {
+		*errs = append(*errs, field.Invalid(path, text, err.Error()))
+	}
+}
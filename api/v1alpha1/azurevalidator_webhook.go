@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the AzureValidator validating webhook with a manager, and the
+// conversion webhook if the manager's scheme has other versions of AzureValidator. AzureValidators
+// whose auth Secret is in another namespace are rejected unless the namespace is one of
+// allowedSecretNamespaces.
+func (r *AzureValidator) SetupWebhookWithManager(mgr ctrl.Manager, allowedSecretNamespaces []string) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&Validator{AllowedSecretNamespaces: allowedSecretNamespaces}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-validation-spectrocloud-labs-v1alpha1-azurevalidator,mutating=false,failurePolicy=fail,sideEffects=None,groups=validation.spectrocloud.labs,resources=azurevalidators,verbs=create;update,versions=v1alpha1,name=vazurevalidator.kb.io,admissionReviewVersions=v1
+
+// Validator validates AzureValidators on admission.
+type Validator struct {
+	// AllowedSecretNamespaces are the namespaces, besides their own, that AzureValidators may read
+	// their auth Secret from.
+	AllowedSecretNamespaces []string
+}
+
+var _ webhook.CustomValidator = &Validator{}
+
+// ValidateCreate implements webhook.CustomValidator.
+func (v *Validator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (v *Validator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (v *Validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects AzureValidators whose spec fails Validate, or whose auth Secret is in a
+// namespace that isn't allowed. Otherwise, it returns the spec's Warnings.
+func (v *Validator) validate(obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*AzureValidator)
+	if !ok {
+		return nil, fmt.Errorf("expected an AzureValidator, got %T", obj)
+	}
+	errs := r.Spec.Validate(field.NewPath("spec"))
+	if err := v.ValidateSecretNamespace(r); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return r.Spec.Warnings(field.NewPath("spec")), nil
+	}
+	return nil, apierrs.NewInvalid(GroupVersion.WithKind("AzureValidator").GroupKind(), r.Name, errs)
+}
+
+// ValidateSecretNamespace returns an error for spec.auth.secretNamespace if an AzureValidator's
+// auth Secret is in a namespace other than its own that isn't one of AllowedSecretNamespaces.
+func (v *Validator) ValidateSecretNamespace(r *AzureValidator) *field.Error {
+	ns := r.AuthSecretNamespace()
+	if r.Spec.Auth.Implicit || ns == r.Namespace || slices.Contains(v.AllowedSecretNamespaces, ns) {
+		return nil
+	}
+	allowed := "none"
+	if len(v.AllowedSecretNamespaces) > 0 {
+		allowed = strings.Join(v.AllowedSecretNamespaces, ", ")
+	}
+	return field.Forbidden(field.NewPath("spec", "auth", "secretNamespace"),
+		fmt.Sprintf("Secrets may only be read from the AzureValidator's namespace and the plugin's --allowed-secret-namespaces (%s)", allowed))
+}
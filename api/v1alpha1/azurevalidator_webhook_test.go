@@ -0,0 +1,206 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAzureValidator_ValidateCreate(t *testing.T) {
+	const principalID = "a83574a7-53ef-4b37-b85e-99f956f0985a"
+	const subscriptionID = "9b16dd0b-1bea-4c9a-a291-65e6f44c4745"
+	subscription := "/subscriptions/" + subscriptionID
+	permissions := []PermissionSet{{Scope: subscription, Actions: []ActionStr{"Microsoft.Compute/virtualMachines/read"}}}
+	tests := []struct {
+		name         string
+		spec         AzureValidatorSpec
+		wantFields   []string
+		wantWarnings []string
+	}{
+		{
+			name: "Accepts rules without failure message templates, and with valid ones.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalID: principalID, Permissions: permissions},
+					{Name: "rule-2", PrincipalID: principalID, Permissions: permissions, FailureMessageTemplate: `{{.PrincipalID}} lacks {{.Action}} at {{.Scope}}.`},
+				},
+			},
+		},
+		{
+			name: "Rejects failure message templates that don't parse or refer to unknown fields.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalID: principalID, Permissions: permissions, FailureMessageTemplate: `{{.Action`},
+					{Name: "rule-2", PrincipalID: principalID, Permissions: permissions},
+				},
+				TemplatePermissionRules: []TemplatePermissionRule{
+					{Name: "rule-3", PrincipalID: principalID, Scope: subscription, FailureMessageTemplate: `{{.Role}} lacks {{.Action}}.`},
+				},
+			},
+			wantFields: []string{
+				"spec.rbacRules[0].failureMessageTemplate",
+				"spec.templatePermissionRules[0].failureMessageTemplate",
+			},
+		},
+		{
+			name: "Rejects duplicate rule names, principal IDs that aren't UUIDs, malformed scopes, and empty permission sets.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalIDs: []string{principalID, "not-a-uuid"}, Permissions: []PermissionSet{
+						{Scope: "subscriptions/" + subscriptionID, Actions: []ActionStr{"Microsoft.Compute/virtualMachines/read"}},
+						{Scopes: []string{"/subscriptions/sub-1"}},
+					}},
+					{Name: "rule-1", PrincipalID: principalID, Permissions: permissions},
+				},
+			},
+			wantFields: []string{
+				"spec.rbacRules[1].name",
+				"spec.rbacRules[0].principalIds[1]",
+				"spec.rbacRules[0].permissionSets[0].scope",
+				"spec.rbacRules[0].permissionSets[1]",
+				"spec.rbacRules[0].permissionSets[1].scopes[0]",
+			},
+		},
+		{
+			name: "Accepts principal client IDs, but not alongside principal IDs, and only if they're UUIDs.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalClientID: principalID, Permissions: permissions},
+					{Name: "rule-2", PrincipalID: principalID, PrincipalClientID: principalID, Permissions: permissions},
+					{Name: "rule-3", PrincipalIDs: []string{principalID}, PrincipalClientID: principalID, Permissions: permissions},
+					{Name: "rule-4", PrincipalClientID: "my-app", Permissions: permissions},
+				},
+			},
+			wantFields: []string{
+				"spec.rbacRules[1].principalClientId",
+				"spec.rbacRules[2].principalClientId",
+				"spec.rbacRules[3].principalClientId",
+			},
+		},
+		{
+			name: "Accepts permission sets scoped to management groups, but not by their resource IDs.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalID: principalID, Permissions: []PermissionSet{
+						{ManagementGroupID: "platform", Actions: []ActionStr{"Microsoft.Resources/subscriptions/read"}},
+						{ManagementGroupID: "/providers/Microsoft.Management/managementGroups/platform", Actions: []ActionStr{"Microsoft.Resources/subscriptions/read"}},
+					}},
+				},
+			},
+			wantFields: []string{
+				"spec.rbacRules[0].permissionSets[1].managementGroupId",
+			},
+		},
+		{
+			name: "Rejects raw filters that aren't URL-encoded, or are combined with expandPrincipalGroups.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalID: principalID, Permissions: permissions, RawFilter: "principalId eq '" + principalID + "'"},
+					{Name: "rule-2", PrincipalID: principalID, Permissions: permissions, RawFilter: "atScope()", ExpandPrincipalGroups: true},
+				},
+			},
+			wantFields: []string{
+				"spec.rbacRules[0].rawFilter",
+				"spec.rbacRules[1].rawFilter",
+			},
+		},
+		{
+			name: "Warns about raw filters that don't filter on the rule's principals.",
+			spec: AzureValidatorSpec{
+				RBACRules: []RBACRule{
+					{Name: "rule-1", PrincipalID: principalID, Permissions: permissions, RawFilter: "principalId%20eq%20%27" + principalID + "%27"},
+					{Name: "rule-2", PrincipalID: principalID, Permissions: permissions, RawFilter: "atScope()"},
+				},
+			},
+			wantWarnings: []string{
+				"spec.rbacRules[1].rawFilter: doesn't filter on the rule's principals, so its results may include role assignments of unrelated principals, which are listed and then dropped",
+			},
+		},
+		{
+			name: "Rejects storage network rules with IP ranges that aren't IPv4 addresses or CIDRs.",
+			spec: AzureValidatorSpec{
+				StorageNetworkRules: []StorageNetworkRule{{
+					Name:             "rule-1",
+					StorageAccountID: "/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+					IPRanges:         []string{"203.0.113.7", "203.0.113.0/24", "2001:db8::/32", "office"},
+				}},
+			},
+			wantFields: []string{
+				"spec.storageNetworkRules[0].ipRanges[2]",
+				"spec.storageNetworkRules[0].ipRanges[3]",
+			},
+		},
+		{
+			name: "Rejects image versions that aren't versions, version ranges, or latest.",
+			spec: AzureValidatorSpec{
+				ImageReplicationRules: []ImageReplicationRule{
+					{Name: "rule-1", ImageVersionID: subscription + "/resourceGroups/rg/providers/Microsoft.Compute/galleries/g/images/ubuntu/versions/>=1.27.0 <1.29.0", Regions: []string{"eastus"}},
+					{Name: "rule-2", ImageVersionID: subscription + "/resourceGroups/rg/providers/Microsoft.Compute/galleries/g/images/ubuntu/versions/1.27.*", Regions: []string{"eastus"}},
+				},
+				ImageDeprecationRules: []ImageDeprecationRule{{
+					Name:           "rule-3",
+					SubscriptionID: subscriptionID,
+					Location:       "eastus",
+					ImageURNs:      []PlatformImageURN{"Canonical:ubuntu:22_04-lts:latest", "Canonical:ubuntu:22_04-lts:>=22.04.202401010", "Canonical:ubuntu:22_04-lts:>=22.04 <"},
+				}},
+			},
+			wantFields: []string{
+				"spec.imageReplicationRules[1].imageVersionId",
+				"spec.imageDeprecationRules[0].imageUrns[2]",
+			},
+		},
+		{
+			name: "Accepts auth Secrets in the AzureValidator's namespace by default.",
+			spec: AzureValidatorSpec{Auth: AzureAuth{SecretName: "azure-creds"}},
+		},
+		{
+			name: "Accepts auth Secrets in the AzureValidator's namespace when it's set explicitly.",
+			spec: AzureValidatorSpec{Auth: AzureAuth{SecretName: "azure-creds", SecretNamespace: "team-a"}},
+		},
+		{
+			name: "Accepts auth Secrets in allowed namespaces.",
+			spec: AzureValidatorSpec{Auth: AzureAuth{SecretName: "azure-creds", SecretNamespace: "cloud-creds"}},
+		},
+		{
+			name:       "Rejects auth Secrets in namespaces that aren't allowed.",
+			spec:       AzureValidatorSpec{Auth: AzureAuth{SecretName: "azure-creds", SecretNamespace: "team-b"}},
+			wantFields: []string{"spec.auth.secretNamespace"},
+		},
+	}
+	v := &Validator{AllowedSecretNamespaces: []string{"cloud-creds"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &AzureValidator{ObjectMeta: metav1.ObjectMeta{Name: "validator", Namespace: "team-a"}, Spec: tt.spec}
+			warnings, err := v.ValidateCreate(context.Background(), validator)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual([]string(warnings), tt.wantWarnings) {
+					t.Errorf("got warnings %q, want %q", warnings, tt.wantWarnings)
+				}
+				return
+			}
+			var status apierrs.APIStatus
+			if !apierrs.IsInvalid(err) {
+				t.Fatalf("expected an Invalid error, got %v", err)
+			}
+			status = err.(apierrs.APIStatus)
+			var fields []string
+			for _, cause := range status.Status().Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Fatalf("got invalid fields %v, want %v", fields, tt.wantFields)
+			}
+			for i := range fields {
+				if fields[i] != tt.wantFields[i] {
+					t.Errorf("got invalid fields %v, want %v", fields, tt.wantFields)
+				}
+			}
+		})
+	}
+}
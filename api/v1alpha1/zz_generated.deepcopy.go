@@ -22,9 +22,241 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiv1alpha1 "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKSAddonProfile) DeepCopyInto(out *AKSAddonProfile) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSAddonProfile.
+func (in *AKSAddonProfile) DeepCopy() *AKSAddonProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(AKSAddonProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKSClusterProperties) DeepCopyInto(out *AKSClusterProperties) {
+	*out = *in
+	if in.ProvisioningState != nil {
+		in, out := &in.ProvisioningState, &out.ProvisioningState
+		*out = new(string)
+		**out = **in
+	}
+	if in.OIDCIssuerEnabled != nil {
+		in, out := &in.OIDCIssuerEnabled, &out.OIDCIssuerEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WorkloadIdentityEnabled != nil {
+		in, out := &in.WorkloadIdentityEnabled, &out.WorkloadIdentityEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PrivateCluster != nil {
+		in, out := &in.PrivateCluster, &out.PrivateCluster
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NetworkPlugin != nil {
+		in, out := &in.NetworkPlugin, &out.NetworkPlugin
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinKubernetesVersion != nil {
+		in, out := &in.MinKubernetesVersion, &out.MinKubernetesVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.AddonProfiles != nil {
+		in, out := &in.AddonProfiles, &out.AddonProfiles
+		*out = make(map[string]AKSAddonProfile, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSClusterProperties.
+func (in *AKSClusterProperties) DeepCopy() *AKSClusterProperties {
+	if in == nil {
+		return nil
+	}
+	out := new(AKSClusterProperties)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKSClusterRule) DeepCopyInto(out *AKSClusterRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.ExpectedProperties.DeepCopyInto(&out.ExpectedProperties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSClusterRule.
+func (in *AKSClusterRule) DeepCopy() *AKSClusterRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AKSClusterRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIRequests) DeepCopyInto(out *APIRequests) {
+	*out = *in
+	if in.ByClientType != nil {
+		in, out := &in.ByClientType, &out.ByClientType
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRequests.
+func (in *APIRequests) DeepCopy() *APIRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(APIRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActivityLogExportRule) DeepCopyInto(out *ActivityLogExportRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make([]ActivityLogCategory, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActivityLogExportRule.
+func (in *ActivityLogExportRule) DeepCopy() *ActivityLogExportRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ActivityLogExportRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPermission) DeepCopyInto(out *AppPermission) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPermission.
+func (in *AppPermission) DeepCopy() *AppPermission {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPermission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPermissionRule) DeepCopyInto(out *AppPermissionRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]AppPermission, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPermissionRule.
+func (in *AppPermissionRule) DeepCopy() *AppPermissionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPermissionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationGatewayListener) DeepCopyInto(out *ApplicationGatewayListener) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationGatewayListener.
+func (in *ApplicationGatewayListener) DeepCopy() *ApplicationGatewayListener {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationGatewayListener)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationGatewayRule) DeepCopyInto(out *ApplicationGatewayRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]ApplicationGatewayListener, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationGatewayRule.
+func (in *ApplicationGatewayRule) DeepCopy() *ApplicationGatewayRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationGatewayRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureAuth) DeepCopyInto(out *AzureAuth) {
 	*out = *in
@@ -46,7 +278,7 @@ func (in *AzureValidator) DeepCopyInto(out *AzureValidator) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidator.
@@ -109,77 +341,2147 @@ func (in *AzureValidatorSpec) DeepCopyInto(out *AzureValidatorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Auth = in.Auth
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidatorSpec.
-func (in *AzureValidatorSpec) DeepCopy() *AzureValidatorSpec {
-	if in == nil {
-		return nil
+	if in.KeyVaultCertificateRules != nil {
+		in, out := &in.KeyVaultCertificateRules, &out.KeyVaultCertificateRules
+		*out = make([]KeyVaultCertificateRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(AzureValidatorSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AzureValidatorStatus) DeepCopyInto(out *AzureValidatorStatus) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidatorStatus.
-func (in *AzureValidatorStatus) DeepCopy() *AzureValidatorStatus {
-	if in == nil {
-		return nil
+	if in.AKSClusterRules != nil {
+		in, out := &in.AKSClusterRules, &out.AKSClusterRules
+		*out = make([]AKSClusterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(AzureValidatorStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PermissionSet) DeepCopyInto(out *PermissionSet) {
-	*out = *in
-	if in.Actions != nil {
-		in, out := &in.Actions, &out.Actions
-		*out = make([]ActionStr, len(*in))
-		copy(*out, *in)
+	if in.NATGatewayRules != nil {
+		in, out := &in.NATGatewayRules, &out.NATGatewayRules
+		*out = make([]NATGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.DataActions != nil {
-		in, out := &in.DataActions, &out.DataActions
-		*out = make([]ActionStr, len(*in))
-		copy(*out, *in)
+	if in.VNetPeeringRules != nil {
+		in, out := &in.VNetPeeringRules, &out.VNetPeeringRules
+		*out = make([]VNetPeeringRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionSet.
-func (in *PermissionSet) DeepCopy() *PermissionSet {
-	if in == nil {
-		return nil
+	if in.RouteTableRules != nil {
+		in, out := &in.RouteTableRules, &out.RouteTableRules
+		*out = make([]RouteTableRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(PermissionSet)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RBACRule) DeepCopyInto(out *RBACRule) {
-	*out = *in
-	if in.Permissions != nil {
-		in, out := &in.Permissions, &out.Permissions
-		*out = make([]PermissionSet, len(*in))
+	if in.ImageCompatibilityRules != nil {
+		in, out := &in.ImageCompatibilityRules, &out.ImageCompatibilityRules
+		*out = make([]ImageCompatibilityRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACRule.
-func (in *RBACRule) DeepCopy() *RBACRule {
-	if in == nil {
-		return nil
+	if in.ImageReplicationRules != nil {
+		in, out := &in.ImageReplicationRules, &out.ImageReplicationRules
+		*out = make([]ImageReplicationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(RBACRule)
+	if in.VMSecurityRules != nil {
+		in, out := &in.VMSecurityRules, &out.VMSecurityRules
+		*out = make([]VMSecurityRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMSizeRules != nil {
+		in, out := &in.VMSizeRules, &out.VMSizeRules
+		*out = make([]VMSizeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DiskZoneRules != nil {
+		in, out := &in.DiskZoneRules, &out.DiskZoneRules
+		*out = make([]DiskZoneRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TemplatePermissionRules != nil {
+		in, out := &in.TemplatePermissionRules, &out.TemplatePermissionRules
+		*out = make([]TemplatePermissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PolicyExemptionRules != nil {
+		in, out := &in.PolicyExemptionRules, &out.PolicyExemptionRules
+		*out = make([]PolicyExemptionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefenderPlanRules != nil {
+		in, out := &in.DefenderPlanRules, &out.DefenderPlanRules
+		*out = make([]DefenderPlanRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BudgetRules != nil {
+		in, out := &in.BudgetRules, &out.BudgetRules
+		*out = make([]BudgetRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceLockRules != nil {
+		in, out := &in.ResourceLockRules, &out.ResourceLockRules
+		*out = make([]ResourceLockRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FirewallPolicyRules != nil {
+		in, out := &in.FirewallPolicyRules, &out.FirewallPolicyRules
+		*out = make([]FirewallPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BastionRules != nil {
+		in, out := &in.BastionRules, &out.BastionRules
+		*out = make([]BastionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DDoSProtectionRules != nil {
+		in, out := &in.DDoSProtectionRules, &out.DDoSProtectionRules
+		*out = make([]DDoSProtectionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PublicIPPrefixRules != nil {
+		in, out := &in.PublicIPPrefixRules, &out.PublicIPPrefixRules
+		*out = make([]PublicIPPrefixRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EventHubRules != nil {
+		in, out := &in.EventHubRules, &out.EventHubRules
+		*out = make([]EventHubRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApplicationGatewayRules != nil {
+		in, out := &in.ApplicationGatewayRules, &out.ApplicationGatewayRules
+		*out = make([]ApplicationGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CosmosDBRules != nil {
+		in, out := &in.CosmosDBRules, &out.CosmosDBRules
+		*out = make([]CosmosDBRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SQLServerRules != nil {
+		in, out := &in.SQLServerRules, &out.SQLServerRules
+		*out = make([]SQLServerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GlobalEndpointRules != nil {
+		in, out := &in.GlobalEndpointRules, &out.GlobalEndpointRules
+		*out = make([]GlobalEndpointRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExpressRouteRules != nil {
+		in, out := &in.ExpressRouteRules, &out.ExpressRouteRules
+		*out = make([]ExpressRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VPNGatewayRules != nil {
+		in, out := &in.VPNGatewayRules, &out.VPNGatewayRules
+		*out = make([]VPNGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkWatcherRules != nil {
+		in, out := &in.NetworkWatcherRules, &out.NetworkWatcherRules
+		*out = make([]NetworkWatcherRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProximityPlacementGroupRules != nil {
+		in, out := &in.ProximityPlacementGroupRules, &out.ProximityPlacementGroupRules
+		*out = make([]ProximityPlacementGroupRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EncryptionAtHostRules != nil {
+		in, out := &in.EncryptionAtHostRules, &out.EncryptionAtHostRules
+		*out = make([]EncryptionAtHostRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GroupMembershipRules != nil {
+		in, out := &in.GroupMembershipRules, &out.GroupMembershipRules
+		*out = make([]GroupMembershipRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppPermissionRules != nil {
+		in, out := &in.AppPermissionRules, &out.AppPermissionRules
+		*out = make([]AppPermissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BlobContainerRules != nil {
+		in, out := &in.BlobContainerRules, &out.BlobContainerRules
+		*out = make([]BlobContainerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageNetworkRules != nil {
+		in, out := &in.StorageNetworkRules, &out.StorageNetworkRules
+		*out = make([]StorageNetworkRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FileShareRules != nil {
+		in, out := &in.FileShareRules, &out.FileShareRules
+		*out = make([]FileShareRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubnetDelegationRules != nil {
+		in, out := &in.SubnetDelegationRules, &out.SubnetDelegationRules
+		*out = make([]SubnetDelegationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MonitorAlertRules != nil {
+		in, out := &in.MonitorAlertRules, &out.MonitorAlertRules
+		*out = make([]MonitorAlertRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ActivityLogExportRules != nil {
+		in, out := &in.ActivityLogExportRules, &out.ActivityLogExportRules
+		*out = make([]ActivityLogExportRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageDeprecationRules != nil {
+		in, out := &in.ImageDeprecationRules, &out.ImageDeprecationRules
+		*out = make([]ImageDeprecationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZoneRedundancyRules != nil {
+		in, out := &in.ZoneRedundancyRules, &out.ZoneRedundancyRules
+		*out = make([]ZoneRedundancyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomLocationRules != nil {
+		in, out := &in.CustomLocationRules, &out.CustomLocationRules
+		*out = make([]CustomLocationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeyVaultRecoveryRules != nil {
+		in, out := &in.KeyVaultRecoveryRules, &out.KeyVaultRecoveryRules
+		*out = make([]KeyVaultRecoveryRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Auth = in.Auth
+	if in.ResultLabels != nil {
+		in, out := &in.ResultLabels, &out.ResultLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResultMaxAge != nil {
+		in, out := &in.ResultMaxAge, &out.ResultMaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RunDeadline != nil {
+		in, out := &in.RunDeadline, &out.RunDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RulesFrom != nil {
+		in, out := &in.RulesFrom, &out.RulesFrom
+		*out = make([]RulesSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(SnapshotConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidatorSpec.
+func (in *AzureValidatorSpec) DeepCopy() *AzureValidatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureValidatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureValidatorStatus) DeepCopyInto(out *AzureValidatorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuleResults != nil {
+		in, out := &in.RuleResults, &out.RuleResults
+		*out = make([]RuleResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRunAPIRequests != nil {
+		in, out := &in.LastRunAPIRequests, &out.LastRunAPIRequests
+		*out = new(APIRequests)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunResult)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidatorStatus.
+func (in *AzureValidatorStatus) DeepCopy() *AzureValidatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureValidatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BastionRule) DeepCopyInto(out *BastionRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BastionRule.
+func (in *BastionRule) DeepCopy() *BastionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BastionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlobContainerRule) DeepCopyInto(out *BlobContainerRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LifecycleRule != nil {
+		in, out := &in.LifecycleRule, &out.LifecycleRule
+		*out = new(BlobLifecycleRule)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlobContainerRule.
+func (in *BlobContainerRule) DeepCopy() *BlobContainerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BlobContainerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlobLifecycleRule) DeepCopyInto(out *BlobLifecycleRule) {
+	*out = *in
+	if in.TierToCoolAfterDays != nil {
+		in, out := &in.TierToCoolAfterDays, &out.TierToCoolAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DeleteAfterDays != nil {
+		in, out := &in.DeleteAfterDays, &out.DeleteAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlobLifecycleRule.
+func (in *BlobLifecycleRule) DeepCopy() *BlobLifecycleRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BlobLifecycleRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BudgetRule) DeepCopyInto(out *BudgetRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MinAmount != nil {
+		in, out := &in.MinAmount, &out.MinAmount
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxAmount != nil {
+		in, out := &in.MaxAmount, &out.MaxAmount
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RequireNotification != nil {
+		in, out := &in.RequireNotification, &out.RequireNotification
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetRule.
+func (in *BudgetRule) DeepCopy() *BudgetRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BudgetRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyRef) DeepCopyInto(out *ConfigMapKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyRef.
+func (in *ConfigMapKeyRef) DeepCopy() *ConfigMapKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CosmosDBRule) DeepCopyInto(out *CosmosDBRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Locations != nil {
+		in, out := &in.Locations, &out.Locations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosmosDBRule.
+func (in *CosmosDBRule) DeepCopy() *CosmosDBRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CosmosDBRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomLocationRule) DeepCopyInto(out *CustomLocationRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtensionTypes != nil {
+		in, out := &in.ExtensionTypes, &out.ExtensionTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomLocationRule.
+func (in *CustomLocationRule) DeepCopy() *CustomLocationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomLocationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DDoSProtectionRule) DeepCopyInto(out *DDoSProtectionRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VirtualNetworkIDs != nil {
+		in, out := &in.VirtualNetworkIDs, &out.VirtualNetworkIDs
+		*out = make([]VirtualNetworkID, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DDoSProtectionRule.
+func (in *DDoSProtectionRule) DeepCopy() *DDoSProtectionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DDoSProtectionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefenderPlanRule) DeepCopyInto(out *DefenderPlanRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Plans != nil {
+		in, out := &in.Plans, &out.Plans
+		*out = make([]ExpectedDefenderPlan, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefenderPlanRule.
+func (in *DefenderPlanRule) DeepCopy() *DefenderPlanRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DefenderPlanRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskZoneRule) DeepCopyInto(out *DiskZoneRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskZoneRule.
+func (in *DiskZoneRule) DeepCopy() *DiskZoneRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskZoneRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunResult) DeepCopyInto(out *DryRunResult) {
+	*out = *in
+	in.EvaluationTime.DeepCopyInto(&out.EvaluationTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]apiv1alpha1.ValidationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunResult.
+func (in *DryRunResult) DeepCopy() *DryRunResult {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionAtHostRule) DeepCopyInto(out *EncryptionAtHostRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionAtHostRule.
+func (in *EncryptionAtHostRule) DeepCopy() *EncryptionAtHostRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionAtHostRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventHubAuthorizationRule) DeepCopyInto(out *EventHubAuthorizationRule) {
+	*out = *in
+	if in.Rights != nil {
+		in, out := &in.Rights, &out.Rights
+		*out = make([]EventHubAccessRight, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventHubAuthorizationRule.
+func (in *EventHubAuthorizationRule) DeepCopy() *EventHubAuthorizationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EventHubAuthorizationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventHubRule) DeepCopyInto(out *EventHubRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AuthorizationRule != nil {
+		in, out := &in.AuthorizationRule, &out.AuthorizationRule
+		*out = new(EventHubAuthorizationRule)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventHubRule.
+func (in *EventHubRule) DeepCopy() *EventHubRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EventHubRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpectedDefenderPlan) DeepCopyInto(out *ExpectedDefenderPlan) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpectedDefenderPlan.
+func (in *ExpectedDefenderPlan) DeepCopy() *ExpectedDefenderPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpectedDefenderPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpectedFirewallRule) DeepCopyInto(out *ExpectedFirewallRule) {
+	*out = *in
+	if in.DestinationFQDNs != nil {
+		in, out := &in.DestinationFQDNs, &out.DestinationFQDNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationAddresses != nil {
+		in, out := &in.DestinationAddresses, &out.DestinationAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Protocols != nil {
+		in, out := &in.Protocols, &out.Protocols
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceAddresses != nil {
+		in, out := &in.SourceAddresses, &out.SourceAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpectedFirewallRule.
+func (in *ExpectedFirewallRule) DeepCopy() *ExpectedFirewallRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpectedFirewallRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpectedPolicyExemption) DeepCopyInto(out *ExpectedPolicyExemption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpectedPolicyExemption.
+func (in *ExpectedPolicyExemption) DeepCopy() *ExpectedPolicyExemption {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpectedPolicyExemption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpressRouteRule) DeepCopyInto(out *ExpressRouteRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpressRouteRule.
+func (in *ExpressRouteRule) DeepCopy() *ExpressRouteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpressRouteRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileShareRule) DeepCopyInto(out *FileShareRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ShareNames != nil {
+		in, out := &in.ShareNames, &out.ShareNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileShareRule.
+func (in *FileShareRule) DeepCopy() *FileShareRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FileShareRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirewallPolicyRule) DeepCopyInto(out *FirewallPolicyRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RuleCollectionGroups != nil {
+		in, out := &in.RuleCollectionGroups, &out.RuleCollectionGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedRules != nil {
+		in, out := &in.ExpectedRules, &out.ExpectedRules
+		*out = make([]ExpectedFirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallPolicyRule.
+func (in *FirewallPolicyRule) DeepCopy() *FirewallPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FirewallPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalEndpointRule) DeepCopyInto(out *GlobalEndpointRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthyMonitorStatuses != nil {
+		in, out := &in.HealthyMonitorStatuses, &out.HealthyMonitorStatuses
+		*out = make([]EndpointMonitorStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalEndpointRule.
+func (in *GlobalEndpointRule) DeepCopy() *GlobalEndpointRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalEndpointRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupMembershipRule) DeepCopyInto(out *GroupMembershipRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MemberIDs != nil {
+		in, out := &in.MemberIDs, &out.MemberIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupMembershipRule.
+func (in *GroupMembershipRule) DeepCopy() *GroupMembershipRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupMembershipRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCompatibilityRule) DeepCopyInto(out *ImageCompatibilityRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCompatibilityRule.
+func (in *ImageCompatibilityRule) DeepCopy() *ImageCompatibilityRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCompatibilityRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDeprecationRule) DeepCopyInto(out *ImageDeprecationRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImageURNs != nil {
+		in, out := &in.ImageURNs, &out.ImageURNs
+		*out = make([]PlatformImageURN, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeprecationHorizon != nil {
+		in, out := &in.DeprecationHorizon, &out.DeprecationHorizon
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeprecationRule.
+func (in *ImageDeprecationRule) DeepCopy() *ImageDeprecationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDeprecationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplicationRule) DeepCopyInto(out *ImageReplicationRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplicationRule.
+func (in *ImageReplicationRule) DeepCopy() *ImageReplicationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplicationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultCertificateRule) DeepCopyInto(out *KeyVaultCertificateRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.MinRemainingValidity = in.MinRemainingValidity
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultCertificateRule.
+func (in *KeyVaultCertificateRule) DeepCopy() *KeyVaultCertificateRule {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultCertificateRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultRecoveryRule) DeepCopyInto(out *KeyVaultRecoveryRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(KeyVaultSettings)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultRecoveryRule.
+func (in *KeyVaultRecoveryRule) DeepCopy() *KeyVaultRecoveryRule {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultRecoveryRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultSettings) DeepCopyInto(out *KeyVaultSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultSettings.
+func (in *KeyVaultSettings) DeepCopy() *KeyVaultSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorAlertRule) DeepCopyInto(out *MonitorAlertRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReceiverTypes != nil {
+		in, out := &in.ReceiverTypes, &out.ReceiverTypes
+		*out = make([]ActionGroupReceiverType, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetricAlertNames != nil {
+		in, out := &in.MetricAlertNames, &out.MetricAlertNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorAlertRule.
+func (in *MonitorAlertRule) DeepCopy() *MonitorAlertRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorAlertRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATGatewayRule) DeepCopyInto(out *NATGatewayRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SubnetIDs != nil {
+		in, out := &in.SubnetIDs, &out.SubnetIDs
+		*out = make([]SubnetID, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATGatewayRule.
+func (in *NATGatewayRule) DeepCopy() *NATGatewayRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NATGatewayRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkWatcherRule) DeepCopyInto(out *NetworkWatcherRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NetworkSecurityGroupIDs != nil {
+		in, out := &in.NetworkSecurityGroupIDs, &out.NetworkSecurityGroupIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkWatcherRule.
+func (in *NetworkWatcherRule) DeepCopy() *NetworkWatcherRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkWatcherRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionSet) DeepCopyInto(out *PermissionSet) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataActions != nil {
+		in, out := &in.DataActions, &out.DataActions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScopePatterns != nil {
+		in, out := &in.ScopePatterns, &out.ScopePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CreatedAfter != nil {
+		in, out := &in.CreatedAfter, &out.CreatedAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.CreatedBefore != nil {
+		in, out := &in.CreatedBefore, &out.CreatedBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionSet.
+func (in *PermissionSet) DeepCopy() *PermissionSet {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyExemptionRule) DeepCopyInto(out *PolicyExemptionRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Exemptions != nil {
+		in, out := &in.Exemptions, &out.Exemptions
+		*out = make([]ExpectedPolicyExemption, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinRemainingValidity != nil {
+		in, out := &in.MinRemainingValidity, &out.MinRemainingValidity
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyExemptionRule.
+func (in *PolicyExemptionRule) DeepCopy() *PolicyExemptionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyExemptionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProximityPlacementGroupRule) DeepCopyInto(out *ProximityPlacementGroupRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProximityPlacementGroupRule.
+func (in *ProximityPlacementGroupRule) DeepCopy() *ProximityPlacementGroupRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ProximityPlacementGroupRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicIPPrefixRule) DeepCopyInto(out *PublicIPPrefixRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicIPPrefixRule.
+func (in *PublicIPPrefixRule) DeepCopy() *PublicIPPrefixRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicIPPrefixRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACRule) DeepCopyInto(out *RBACRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]PermissionSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PrincipalIDs != nil {
+		in, out := &in.PrincipalIDs, &out.PrincipalIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RoleAssignmentQuota != nil {
+		in, out := &in.RoleAssignmentQuota, &out.RoleAssignmentQuota
+		*out = new(RoleAssignmentQuota)
+		**out = **in
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACRule.
+func (in *RBACRule) DeepCopy() *RBACRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequiredRoute) DeepCopyInto(out *RequiredRoute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequiredRoute.
+func (in *RequiredRoute) DeepCopy() *RequiredRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(RequiredRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceLockRule) DeepCopyInto(out *ResourceLockRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceLockRule.
+func (in *ResourceLockRule) DeepCopy() *ResourceLockRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceLockRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTypeActions) DeepCopyInto(out *ResourceTypeActions) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]ActionStr, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTypeActions.
+func (in *ResourceTypeActions) DeepCopy() *ResourceTypeActions {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTypeActions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleAssignmentQuota) DeepCopyInto(out *RoleAssignmentQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleAssignmentQuota.
+func (in *RoleAssignmentQuota) DeepCopy() *RoleAssignmentQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleAssignmentQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteTableRule) DeepCopyInto(out *RouteTableRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]RequiredRoute, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteTableRule.
+func (in *RouteTableRule) DeepCopy() *RouteTableRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteTableRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleResult) DeepCopyInto(out *RuleResult) {
+	*out = *in
+	out.Duration = in.Duration
+	in.LastEvaluationTime.DeepCopyInto(&out.LastEvaluationTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.PendingUntil != nil {
+		in, out := &in.PendingUntil, &out.PendingUntil
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleResult.
+func (in *RuleResult) DeepCopy() *RuleResult {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleSet) DeepCopyInto(out *RuleSet) {
+	*out = *in
+	if in.RBACRules != nil {
+		in, out := &in.RBACRules, &out.RBACRules
+		*out = make([]RBACRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeyVaultCertificateRules != nil {
+		in, out := &in.KeyVaultCertificateRules, &out.KeyVaultCertificateRules
+		*out = make([]KeyVaultCertificateRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AKSClusterRules != nil {
+		in, out := &in.AKSClusterRules, &out.AKSClusterRules
+		*out = make([]AKSClusterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NATGatewayRules != nil {
+		in, out := &in.NATGatewayRules, &out.NATGatewayRules
+		*out = make([]NATGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VNetPeeringRules != nil {
+		in, out := &in.VNetPeeringRules, &out.VNetPeeringRules
+		*out = make([]VNetPeeringRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RouteTableRules != nil {
+		in, out := &in.RouteTableRules, &out.RouteTableRules
+		*out = make([]RouteTableRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageCompatibilityRules != nil {
+		in, out := &in.ImageCompatibilityRules, &out.ImageCompatibilityRules
+		*out = make([]ImageCompatibilityRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageReplicationRules != nil {
+		in, out := &in.ImageReplicationRules, &out.ImageReplicationRules
+		*out = make([]ImageReplicationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMSecurityRules != nil {
+		in, out := &in.VMSecurityRules, &out.VMSecurityRules
+		*out = make([]VMSecurityRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMSizeRules != nil {
+		in, out := &in.VMSizeRules, &out.VMSizeRules
+		*out = make([]VMSizeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DiskZoneRules != nil {
+		in, out := &in.DiskZoneRules, &out.DiskZoneRules
+		*out = make([]DiskZoneRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TemplatePermissionRules != nil {
+		in, out := &in.TemplatePermissionRules, &out.TemplatePermissionRules
+		*out = make([]TemplatePermissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PolicyExemptionRules != nil {
+		in, out := &in.PolicyExemptionRules, &out.PolicyExemptionRules
+		*out = make([]PolicyExemptionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefenderPlanRules != nil {
+		in, out := &in.DefenderPlanRules, &out.DefenderPlanRules
+		*out = make([]DefenderPlanRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BudgetRules != nil {
+		in, out := &in.BudgetRules, &out.BudgetRules
+		*out = make([]BudgetRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceLockRules != nil {
+		in, out := &in.ResourceLockRules, &out.ResourceLockRules
+		*out = make([]ResourceLockRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FirewallPolicyRules != nil {
+		in, out := &in.FirewallPolicyRules, &out.FirewallPolicyRules
+		*out = make([]FirewallPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BastionRules != nil {
+		in, out := &in.BastionRules, &out.BastionRules
+		*out = make([]BastionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DDoSProtectionRules != nil {
+		in, out := &in.DDoSProtectionRules, &out.DDoSProtectionRules
+		*out = make([]DDoSProtectionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PublicIPPrefixRules != nil {
+		in, out := &in.PublicIPPrefixRules, &out.PublicIPPrefixRules
+		*out = make([]PublicIPPrefixRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EventHubRules != nil {
+		in, out := &in.EventHubRules, &out.EventHubRules
+		*out = make([]EventHubRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApplicationGatewayRules != nil {
+		in, out := &in.ApplicationGatewayRules, &out.ApplicationGatewayRules
+		*out = make([]ApplicationGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CosmosDBRules != nil {
+		in, out := &in.CosmosDBRules, &out.CosmosDBRules
+		*out = make([]CosmosDBRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SQLServerRules != nil {
+		in, out := &in.SQLServerRules, &out.SQLServerRules
+		*out = make([]SQLServerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GlobalEndpointRules != nil {
+		in, out := &in.GlobalEndpointRules, &out.GlobalEndpointRules
+		*out = make([]GlobalEndpointRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExpressRouteRules != nil {
+		in, out := &in.ExpressRouteRules, &out.ExpressRouteRules
+		*out = make([]ExpressRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VPNGatewayRules != nil {
+		in, out := &in.VPNGatewayRules, &out.VPNGatewayRules
+		*out = make([]VPNGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkWatcherRules != nil {
+		in, out := &in.NetworkWatcherRules, &out.NetworkWatcherRules
+		*out = make([]NetworkWatcherRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProximityPlacementGroupRules != nil {
+		in, out := &in.ProximityPlacementGroupRules, &out.ProximityPlacementGroupRules
+		*out = make([]ProximityPlacementGroupRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EncryptionAtHostRules != nil {
+		in, out := &in.EncryptionAtHostRules, &out.EncryptionAtHostRules
+		*out = make([]EncryptionAtHostRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GroupMembershipRules != nil {
+		in, out := &in.GroupMembershipRules, &out.GroupMembershipRules
+		*out = make([]GroupMembershipRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppPermissionRules != nil {
+		in, out := &in.AppPermissionRules, &out.AppPermissionRules
+		*out = make([]AppPermissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BlobContainerRules != nil {
+		in, out := &in.BlobContainerRules, &out.BlobContainerRules
+		*out = make([]BlobContainerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageNetworkRules != nil {
+		in, out := &in.StorageNetworkRules, &out.StorageNetworkRules
+		*out = make([]StorageNetworkRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FileShareRules != nil {
+		in, out := &in.FileShareRules, &out.FileShareRules
+		*out = make([]FileShareRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubnetDelegationRules != nil {
+		in, out := &in.SubnetDelegationRules, &out.SubnetDelegationRules
+		*out = make([]SubnetDelegationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MonitorAlertRules != nil {
+		in, out := &in.MonitorAlertRules, &out.MonitorAlertRules
+		*out = make([]MonitorAlertRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ActivityLogExportRules != nil {
+		in, out := &in.ActivityLogExportRules, &out.ActivityLogExportRules
+		*out = make([]ActivityLogExportRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageDeprecationRules != nil {
+		in, out := &in.ImageDeprecationRules, &out.ImageDeprecationRules
+		*out = make([]ImageDeprecationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZoneRedundancyRules != nil {
+		in, out := &in.ZoneRedundancyRules, &out.ZoneRedundancyRules
+		*out = make([]ZoneRedundancyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomLocationRules != nil {
+		in, out := &in.CustomLocationRules, &out.CustomLocationRules
+		*out = make([]CustomLocationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeyVaultRecoveryRules != nil {
+		in, out := &in.KeyVaultRecoveryRules, &out.KeyVaultRecoveryRules
+		*out = make([]KeyVaultRecoveryRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleSet.
+func (in *RuleSet) DeepCopy() *RuleSet {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RulesSource) DeepCopyInto(out *RulesSource) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RulesSource.
+func (in *RulesSource) DeepCopy() *RulesSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RulesSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLServerRule) DeepCopyInto(out *SQLServerRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLServerRule.
+func (in *SQLServerRule) DeepCopy() *SQLServerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLServerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotConfig) DeepCopyInto(out *SnapshotConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotConfig.
+func (in *SnapshotConfig) DeepCopy() *SnapshotConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageNetworkRule) DeepCopyInto(out *StorageNetworkRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SubnetIDs != nil {
+		in, out := &in.SubnetIDs, &out.SubnetIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPRanges != nil {
+		in, out := &in.IPRanges, &out.IPRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageNetworkRule.
+func (in *StorageNetworkRule) DeepCopy() *StorageNetworkRule {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageNetworkRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetDelegationRule) DeepCopyInto(out *SubnetDelegationRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SubnetIDs != nil {
+		in, out := &in.SubnetIDs, &out.SubnetIDs
+		*out = make([]SubnetID, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetDelegationRule.
+func (in *SubnetDelegationRule) DeepCopy() *SubnetDelegationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetDelegationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplatePermissionRule) DeepCopyInto(out *TemplatePermissionRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TemplateConfigMapRef != nil {
+		in, out := &in.TemplateConfigMapRef, &out.TemplateConfigMapRef
+		*out = new(ConfigMapKeyRef)
+		**out = **in
+	}
+	if in.ResourceTypeActions != nil {
+		in, out := &in.ResourceTypeActions, &out.ResourceTypeActions
+		*out = make([]ResourceTypeActions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatePermissionRule.
+func (in *TemplatePermissionRule) DeepCopy() *TemplatePermissionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatePermissionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMSecurityRule) DeepCopyInto(out *VMSecurityRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMSecurityRule.
+func (in *VMSecurityRule) DeepCopy() *VMSecurityRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VMSecurityRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMSizeRule) DeepCopyInto(out *VMSizeRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMSizeRule.
+func (in *VMSizeRule) DeepCopy() *VMSizeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VMSizeRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VNetPeering) DeepCopyInto(out *VNetPeering) {
+	*out = *in
+	if in.AllowVirtualNetworkAccess != nil {
+		in, out := &in.AllowVirtualNetworkAccess, &out.AllowVirtualNetworkAccess
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowForwardedTraffic != nil {
+		in, out := &in.AllowForwardedTraffic, &out.AllowForwardedTraffic
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseRemoteGateways != nil {
+		in, out := &in.UseRemoteGateways, &out.UseRemoteGateways
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VNetPeering.
+func (in *VNetPeering) DeepCopy() *VNetPeering {
+	if in == nil {
+		return nil
+	}
+	out := new(VNetPeering)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VNetPeeringRule) DeepCopyInto(out *VNetPeeringRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Peerings != nil {
+		in, out := &in.Peerings, &out.Peerings
+		*out = make([]VNetPeering, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VNetPeeringRule.
+func (in *VNetPeeringRule) DeepCopy() *VNetPeeringRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VNetPeeringRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNGatewayRule) DeepCopyInto(out *VPNGatewayRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNGatewayRule.
+func (in *VPNGatewayRule) DeepCopy() *VPNGatewayRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNGatewayRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Validator) DeepCopyInto(out *Validator) {
+	*out = *in
+	if in.AllowedSecretNamespaces != nil {
+		in, out := &in.AllowedSecretNamespaces, &out.AllowedSecretNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Validator.
+func (in *Validator) DeepCopy() *Validator {
+	if in == nil {
+		return nil
+	}
+	out := new(Validator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneRedundancyRule) DeepCopyInto(out *ZoneRedundancyRule) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VMSizes != nil {
+		in, out := &in.VMSizes, &out.VMSizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]ZoneRedundantService, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneRedundancyRule.
+func (in *ZoneRedundancyRule) DeepCopy() *ZoneRedundancyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneRedundancyRule)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,306 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// RuleMetaAnnotation is the annotation of v1alpha1 AzureValidators that keeps the fields that
+// v1alpha2 adds to rules, so that converting to v1alpha1 and back doesn't lose them.
+const RuleMetaAnnotation = "validation.spectrocloud.labs/v1alpha2-rule-meta"
+
+var _ conversion.Convertible = &AzureValidator{}
+
+// ConvertTo converts an AzureValidator to the hub version, v1alpha1.
+func (r *AzureValidator) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.AzureValidator)
+	src := r.DeepCopy()
+
+	dst.ObjectMeta = src.ObjectMeta
+	meta := ruleMetaAnnotation{}
+	dst.Spec.RBACRules = rulesToHub[v1alpha1.RBACRule](src.Spec.RBAC.RBACRules, "rbacRules", meta)
+	dst.Spec.TemplatePermissionRules = rulesToHub[v1alpha1.TemplatePermissionRule](src.Spec.RBAC.TemplatePermissionRules, "templatePermissionRules", meta)
+	dst.Spec.KeyVaultCertificateRules = rulesToHub[v1alpha1.KeyVaultCertificateRule](src.Spec.Identity.KeyVaultCertificateRules, "keyVaultCertificateRules", meta)
+	dst.Spec.KeyVaultRecoveryRules = rulesToHub[v1alpha1.KeyVaultRecoveryRule](src.Spec.Identity.KeyVaultRecoveryRules, "keyVaultRecoveryRules", meta)
+	dst.Spec.GroupMembershipRules = rulesToHub[v1alpha1.GroupMembershipRule](src.Spec.Identity.GroupMembershipRules, "groupMembershipRules", meta)
+	dst.Spec.AppPermissionRules = rulesToHub[v1alpha1.AppPermissionRule](src.Spec.Identity.AppPermissionRules, "appPermissionRules", meta)
+	dst.Spec.AKSClusterRules = rulesToHub[v1alpha1.AKSClusterRule](src.Spec.Compute.AKSClusterRules, "aksClusterRules", meta)
+	dst.Spec.ImageCompatibilityRules = rulesToHub[v1alpha1.ImageCompatibilityRule](src.Spec.Compute.ImageCompatibilityRules, "imageCompatibilityRules", meta)
+	dst.Spec.ImageReplicationRules = rulesToHub[v1alpha1.ImageReplicationRule](src.Spec.Compute.ImageReplicationRules, "imageReplicationRules", meta)
+	dst.Spec.VMSecurityRules = rulesToHub[v1alpha1.VMSecurityRule](src.Spec.Compute.VMSecurityRules, "vmSecurityRules", meta)
+	dst.Spec.VMSizeRules = rulesToHub[v1alpha1.VMSizeRule](src.Spec.Compute.VMSizeRules, "vmSizeRules", meta)
+	dst.Spec.DiskZoneRules = rulesToHub[v1alpha1.DiskZoneRule](src.Spec.Compute.DiskZoneRules, "diskZoneRules", meta)
+	dst.Spec.ProximityPlacementGroupRules = rulesToHub[v1alpha1.ProximityPlacementGroupRule](src.Spec.Compute.ProximityPlacementGroupRules, "proximityPlacementGroupRules", meta)
+	dst.Spec.EncryptionAtHostRules = rulesToHub[v1alpha1.EncryptionAtHostRule](src.Spec.Compute.EncryptionAtHostRules, "encryptionAtHostRules", meta)
+	dst.Spec.ImageDeprecationRules = rulesToHub[v1alpha1.ImageDeprecationRule](src.Spec.Compute.ImageDeprecationRules, "imageDeprecationRules", meta)
+	dst.Spec.ZoneRedundancyRules = rulesToHub[v1alpha1.ZoneRedundancyRule](src.Spec.Compute.ZoneRedundancyRules, "zoneRedundancyRules", meta)
+	dst.Spec.CustomLocationRules = rulesToHub[v1alpha1.CustomLocationRule](src.Spec.Compute.CustomLocationRules, "customLocationRules", meta)
+	dst.Spec.NATGatewayRules = rulesToHub[v1alpha1.NATGatewayRule](src.Spec.Network.NATGatewayRules, "natGatewayRules", meta)
+	dst.Spec.VNetPeeringRules = rulesToHub[v1alpha1.VNetPeeringRule](src.Spec.Network.VNetPeeringRules, "vnetPeeringRules", meta)
+	dst.Spec.RouteTableRules = rulesToHub[v1alpha1.RouteTableRule](src.Spec.Network.RouteTableRules, "routeTableRules", meta)
+	dst.Spec.FirewallPolicyRules = rulesToHub[v1alpha1.FirewallPolicyRule](src.Spec.Network.FirewallPolicyRules, "firewallPolicyRules", meta)
+	dst.Spec.BastionRules = rulesToHub[v1alpha1.BastionRule](src.Spec.Network.BastionRules, "bastionRules", meta)
+	dst.Spec.DDoSProtectionRules = rulesToHub[v1alpha1.DDoSProtectionRule](src.Spec.Network.DDoSProtectionRules, "ddosProtectionRules", meta)
+	dst.Spec.PublicIPPrefixRules = rulesToHub[v1alpha1.PublicIPPrefixRule](src.Spec.Network.PublicIPPrefixRules, "publicIPPrefixRules", meta)
+	dst.Spec.ApplicationGatewayRules = rulesToHub[v1alpha1.ApplicationGatewayRule](src.Spec.Network.ApplicationGatewayRules, "applicationGatewayRules", meta)
+	dst.Spec.GlobalEndpointRules = rulesToHub[v1alpha1.GlobalEndpointRule](src.Spec.Network.GlobalEndpointRules, "globalEndpointRules", meta)
+	dst.Spec.ExpressRouteRules = rulesToHub[v1alpha1.ExpressRouteRule](src.Spec.Network.ExpressRouteRules, "expressRouteRules", meta)
+	dst.Spec.VPNGatewayRules = rulesToHub[v1alpha1.VPNGatewayRule](src.Spec.Network.VPNGatewayRules, "vpnGatewayRules", meta)
+	dst.Spec.NetworkWatcherRules = rulesToHub[v1alpha1.NetworkWatcherRule](src.Spec.Network.NetworkWatcherRules, "networkWatcherRules", meta)
+	dst.Spec.SubnetDelegationRules = rulesToHub[v1alpha1.SubnetDelegationRule](src.Spec.Network.SubnetDelegationRules, "subnetDelegationRules", meta)
+	dst.Spec.BlobContainerRules = rulesToHub[v1alpha1.BlobContainerRule](src.Spec.Storage.BlobContainerRules, "blobContainerRules", meta)
+	dst.Spec.StorageNetworkRules = rulesToHub[v1alpha1.StorageNetworkRule](src.Spec.Storage.StorageNetworkRules, "storageNetworkRules", meta)
+	dst.Spec.FileShareRules = rulesToHub[v1alpha1.FileShareRule](src.Spec.Storage.FileShareRules, "fileShareRules", meta)
+	dst.Spec.CosmosDBRules = rulesToHub[v1alpha1.CosmosDBRule](src.Spec.Storage.CosmosDBRules, "cosmosDBRules", meta)
+	dst.Spec.SQLServerRules = rulesToHub[v1alpha1.SQLServerRule](src.Spec.Storage.SQLServerRules, "sqlServerRules", meta)
+	dst.Spec.EventHubRules = rulesToHub[v1alpha1.EventHubRule](src.Spec.Storage.EventHubRules, "eventHubRules", meta)
+	dst.Spec.PolicyExemptionRules = rulesToHub[v1alpha1.PolicyExemptionRule](src.Spec.Governance.PolicyExemptionRules, "policyExemptionRules", meta)
+	dst.Spec.DefenderPlanRules = rulesToHub[v1alpha1.DefenderPlanRule](src.Spec.Governance.DefenderPlanRules, "defenderPlanRules", meta)
+	dst.Spec.BudgetRules = rulesToHub[v1alpha1.BudgetRule](src.Spec.Governance.BudgetRules, "budgetRules", meta)
+	dst.Spec.ResourceLockRules = rulesToHub[v1alpha1.ResourceLockRule](src.Spec.Governance.ResourceLockRules, "resourceLockRules", meta)
+	dst.Spec.MonitorAlertRules = rulesToHub[v1alpha1.MonitorAlertRule](src.Spec.Governance.MonitorAlertRules, "monitorAlertRules", meta)
+	dst.Spec.ActivityLogExportRules = rulesToHub[v1alpha1.ActivityLogExportRule](src.Spec.Governance.ActivityLogExportRules, "activityLogExportRules", meta)
+	dst.Spec.Auth = src.Spec.Auth
+	dst.Spec.ResultLabels = src.Spec.ResultLabels
+	dst.Spec.ResultMaxAge = src.Spec.ResultMaxAge
+	dst.Spec.RunDeadline = src.Spec.RunDeadline
+	dst.Spec.DefaultSeverity = v1alpha1.Severity(src.Spec.DefaultSeverity)
+	dst.Spec.RevalidateOnRoleAssignmentChanges = src.Spec.RevalidateOnRoleAssignmentChanges
+	dst.Spec.SkipPreflight = src.Spec.SkipPreflight
+	dst.Spec.ARMRegion = src.Spec.ARMRegion
+	dst.Spec.RulesFrom = src.Spec.RulesFrom
+	dst.Spec.Snapshot = src.Spec.Snapshot
+	dst.Status = src.Status
+	return meta.marshalInto(&dst.ObjectMeta)
+}
+
+// ConvertFrom converts an AzureValidator from the hub version, v1alpha1.
+func (r *AzureValidator) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.AzureValidator).DeepCopy()
+
+	r.ObjectMeta = src.ObjectMeta
+	meta, err := unmarshalRuleMeta(&r.ObjectMeta)
+	if err != nil {
+		return fmt.Errorf("failed to parse the %s annotation: %w", RuleMetaAnnotation, err)
+	}
+	r.Spec.RBAC.RBACRules = rulesFromHub[RBACRule](src.Spec.RBACRules, "rbacRules", meta)
+	r.Spec.RBAC.TemplatePermissionRules = rulesFromHub[TemplatePermissionRule](src.Spec.TemplatePermissionRules, "templatePermissionRules", meta)
+	r.Spec.Identity.KeyVaultCertificateRules = rulesFromHub[KeyVaultCertificateRule](src.Spec.KeyVaultCertificateRules, "keyVaultCertificateRules", meta)
+	r.Spec.Identity.KeyVaultRecoveryRules = rulesFromHub[KeyVaultRecoveryRule](src.Spec.KeyVaultRecoveryRules, "keyVaultRecoveryRules", meta)
+	r.Spec.Identity.GroupMembershipRules = rulesFromHub[GroupMembershipRule](src.Spec.GroupMembershipRules, "groupMembershipRules", meta)
+	r.Spec.Identity.AppPermissionRules = rulesFromHub[AppPermissionRule](src.Spec.AppPermissionRules, "appPermissionRules", meta)
+	r.Spec.Compute.AKSClusterRules = rulesFromHub[AKSClusterRule](src.Spec.AKSClusterRules, "aksClusterRules", meta)
+	r.Spec.Compute.ImageCompatibilityRules = rulesFromHub[ImageCompatibilityRule](src.Spec.ImageCompatibilityRules, "imageCompatibilityRules", meta)
+	r.Spec.Compute.ImageReplicationRules = rulesFromHub[ImageReplicationRule](src.Spec.ImageReplicationRules, "imageReplicationRules", meta)
+	r.Spec.Compute.VMSecurityRules = rulesFromHub[VMSecurityRule](src.Spec.VMSecurityRules, "vmSecurityRules", meta)
+	r.Spec.Compute.VMSizeRules = rulesFromHub[VMSizeRule](src.Spec.VMSizeRules, "vmSizeRules", meta)
+	r.Spec.Compute.DiskZoneRules = rulesFromHub[DiskZoneRule](src.Spec.DiskZoneRules, "diskZoneRules", meta)
+	r.Spec.Compute.ProximityPlacementGroupRules = rulesFromHub[ProximityPlacementGroupRule](src.Spec.ProximityPlacementGroupRules, "proximityPlacementGroupRules", meta)
+	r.Spec.Compute.EncryptionAtHostRules = rulesFromHub[EncryptionAtHostRule](src.Spec.EncryptionAtHostRules, "encryptionAtHostRules", meta)
+	r.Spec.Compute.ImageDeprecationRules = rulesFromHub[ImageDeprecationRule](src.Spec.ImageDeprecationRules, "imageDeprecationRules", meta)
+	r.Spec.Compute.ZoneRedundancyRules = rulesFromHub[ZoneRedundancyRule](src.Spec.ZoneRedundancyRules, "zoneRedundancyRules", meta)
+	r.Spec.Compute.CustomLocationRules = rulesFromHub[CustomLocationRule](src.Spec.CustomLocationRules, "customLocationRules", meta)
+	r.Spec.Network.NATGatewayRules = rulesFromHub[NATGatewayRule](src.Spec.NATGatewayRules, "natGatewayRules", meta)
+	r.Spec.Network.VNetPeeringRules = rulesFromHub[VNetPeeringRule](src.Spec.VNetPeeringRules, "vnetPeeringRules", meta)
+	r.Spec.Network.RouteTableRules = rulesFromHub[RouteTableRule](src.Spec.RouteTableRules, "routeTableRules", meta)
+	r.Spec.Network.FirewallPolicyRules = rulesFromHub[FirewallPolicyRule](src.Spec.FirewallPolicyRules, "firewallPolicyRules", meta)
+	r.Spec.Network.BastionRules = rulesFromHub[BastionRule](src.Spec.BastionRules, "bastionRules", meta)
+	r.Spec.Network.DDoSProtectionRules = rulesFromHub[DDoSProtectionRule](src.Spec.DDoSProtectionRules, "ddosProtectionRules", meta)
+	r.Spec.Network.PublicIPPrefixRules = rulesFromHub[PublicIPPrefixRule](src.Spec.PublicIPPrefixRules, "publicIPPrefixRules", meta)
+	r.Spec.Network.ApplicationGatewayRules = rulesFromHub[ApplicationGatewayRule](src.Spec.ApplicationGatewayRules, "applicationGatewayRules", meta)
+	r.Spec.Network.GlobalEndpointRules = rulesFromHub[GlobalEndpointRule](src.Spec.GlobalEndpointRules, "globalEndpointRules", meta)
+	r.Spec.Network.ExpressRouteRules = rulesFromHub[ExpressRouteRule](src.Spec.ExpressRouteRules, "expressRouteRules", meta)
+	r.Spec.Network.VPNGatewayRules = rulesFromHub[VPNGatewayRule](src.Spec.VPNGatewayRules, "vpnGatewayRules", meta)
+	r.Spec.Network.NetworkWatcherRules = rulesFromHub[NetworkWatcherRule](src.Spec.NetworkWatcherRules, "networkWatcherRules", meta)
+	r.Spec.Network.SubnetDelegationRules = rulesFromHub[SubnetDelegationRule](src.Spec.SubnetDelegationRules, "subnetDelegationRules", meta)
+	r.Spec.Storage.BlobContainerRules = rulesFromHub[BlobContainerRule](src.Spec.BlobContainerRules, "blobContainerRules", meta)
+	r.Spec.Storage.StorageNetworkRules = rulesFromHub[StorageNetworkRule](src.Spec.StorageNetworkRules, "storageNetworkRules", meta)
+	r.Spec.Storage.FileShareRules = rulesFromHub[FileShareRule](src.Spec.FileShareRules, "fileShareRules", meta)
+	r.Spec.Storage.CosmosDBRules = rulesFromHub[CosmosDBRule](src.Spec.CosmosDBRules, "cosmosDBRules", meta)
+	r.Spec.Storage.SQLServerRules = rulesFromHub[SQLServerRule](src.Spec.SQLServerRules, "sqlServerRules", meta)
+	r.Spec.Storage.EventHubRules = rulesFromHub[EventHubRule](src.Spec.EventHubRules, "eventHubRules", meta)
+	r.Spec.Governance.PolicyExemptionRules = rulesFromHub[PolicyExemptionRule](src.Spec.PolicyExemptionRules, "policyExemptionRules", meta)
+	r.Spec.Governance.DefenderPlanRules = rulesFromHub[DefenderPlanRule](src.Spec.DefenderPlanRules, "defenderPlanRules", meta)
+	r.Spec.Governance.BudgetRules = rulesFromHub[BudgetRule](src.Spec.BudgetRules, "budgetRules", meta)
+	r.Spec.Governance.ResourceLockRules = rulesFromHub[ResourceLockRule](src.Spec.ResourceLockRules, "resourceLockRules", meta)
+	r.Spec.Governance.MonitorAlertRules = rulesFromHub[MonitorAlertRule](src.Spec.MonitorAlertRules, "monitorAlertRules", meta)
+	r.Spec.Governance.ActivityLogExportRules = rulesFromHub[ActivityLogExportRule](src.Spec.ActivityLogExportRules, "activityLogExportRules", meta)
+	r.Spec.Auth = src.Spec.Auth
+	r.Spec.ResultLabels = src.Spec.ResultLabels
+	r.Spec.ResultMaxAge = src.Spec.ResultMaxAge
+	r.Spec.RunDeadline = src.Spec.RunDeadline
+	r.Spec.DefaultSeverity = Severity(src.Spec.DefaultSeverity)
+	r.Spec.RevalidateOnRoleAssignmentChanges = src.Spec.RevalidateOnRoleAssignmentChanges
+	r.Spec.SkipPreflight = src.Spec.SkipPreflight
+	r.Spec.ARMRegion = src.Spec.ARMRegion
+	r.Spec.RulesFrom = src.Spec.RulesFrom
+	r.Spec.Snapshot = src.Spec.Snapshot
+	r.Status = src.Status
+	return nil
+}
+
+// ruleMetaData is what RuleMetaAnnotation keeps of a rule: the fields that v1alpha2 adds to it,
+// and its name, to match it with its rule.
+type ruleMetaData struct {
+	Name           string   `json:"name"`
+	Severity       Severity `json:"severity,omitempty"`
+	SubscriptionID string   `json:"subscriptionId,omitempty"`
+}
+
+// ruleMetaAnnotation is the content of RuleMetaAnnotation: the ruleMetaData of each rule of the
+// rule lists that have a rule with v1alpha2 fields set, in the order of the rules.
+// keys = the rule lists' JSON names (e.g. rbacRules)
+type ruleMetaAnnotation map[string][]ruleMetaData
+
+// lookup returns the ruleMetaData of the rule at index i of a rule list, which is the ith one if
+// it has the rule's name, and otherwise the first one that does, so that rules reordered or
+// removed in v1alpha1 keep their v1alpha2 fields.
+func lookup(data []ruleMetaData, i int, name string) (ruleMetaData, bool) {
+	if i < len(data) && data[i].Name == name {
+		return data[i], true
+	}
+	for _, d := range data {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return ruleMetaData{}, false
+}
+
+// RuleSeverities returns the severities that RuleMetaAnnotation keeps of the rules of a v1alpha1
+// AzureValidator, by the JSON name of their rule list (e.g. rbacRules) and their name. Rules
+// without a severity are left out.
+func RuleSeverities(meta metav1.ObjectMeta) (map[string]map[string]Severity, error) {
+	a, err := unmarshalRuleMeta(meta.DeepCopy())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the %s annotation: %w", RuleMetaAnnotation, err)
+	}
+	severities := map[string]map[string]Severity{}
+	for list, data := range a {
+		for _, d := range data {
+			if d.Severity == "" {
+				continue
+			}
+			if severities[list] == nil {
+				severities[list] = map[string]Severity{}
+			}
+			severities[list][d.Name] = d.Severity
+		}
+	}
+	return severities, nil
+}
+
+// marshalInto sets RuleMetaAnnotation on an object, or removes it if no rule has v1alpha2 fields
+// set.
+func (a ruleMetaAnnotation) marshalInto(meta *metav1.ObjectMeta) error {
+	if len(a) == 0 {
+		delete(meta.Annotations, RuleMetaAnnotation)
+		return nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[RuleMetaAnnotation] = string(data)
+	return nil
+}
+
+// unmarshalRuleMeta removes RuleMetaAnnotation from an object and returns its content.
+func unmarshalRuleMeta(meta *metav1.ObjectMeta) (ruleMetaAnnotation, error) {
+	data, ok := meta.Annotations[RuleMetaAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	delete(meta.Annotations, RuleMetaAnnotation)
+	a := ruleMetaAnnotation{}
+	if err := json.Unmarshal([]byte(data), &a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (m RuleMeta) v1alpha2Fields() ruleMetaData {
+	return ruleMetaData{Severity: m.Severity}
+}
+
+func (m *RuleMeta) setV1alpha2Fields(d ruleMetaData) {
+	m.Severity = d.Severity
+}
+
+func (m SubscriptionRuleMeta) v1alpha2Fields() ruleMetaData {
+	d := m.RuleMeta.v1alpha2Fields()
+	d.SubscriptionID = m.SubscriptionID
+	return d
+}
+
+func (m *SubscriptionRuleMeta) setV1alpha2Fields(d ruleMetaData) {
+	m.RuleMeta.setV1alpha2Fields(d)
+	m.SubscriptionID = d.SubscriptionID
+}
+
+// rulesToHub converts v1alpha2 rules to their v1alpha1 type H, and records their v1alpha2 fields
+// in meta, if any are set. Each v1alpha2 rule type embeds its v1alpha1 type as its first field.
+func rulesToHub[H any, S interface{ v1alpha2Fields() ruleMetaData }](rules []S, list string, meta ruleMetaAnnotation) []H {
+	if rules == nil {
+		return nil
+	}
+	hub := make([]H, len(rules))
+	data := make([]ruleMetaData, len(rules))
+	set := false
+	for i, rule := range rules {
+		v := reflect.ValueOf(rule).Field(0)
+		hub[i] = v.Interface().(H)
+		data[i] = rule.v1alpha2Fields()
+		set = set || data[i] != ruleMetaData{}
+		data[i].Name = v.FieldByName("Name").String()
+	}
+	if set {
+		meta[list] = data
+	}
+	return hub
+}
+
+// rulesFromHub converts v1alpha1 rules of type H to their v1alpha2 type S, with the v1alpha2
+// fields that meta recorded for them.
+func rulesFromHub[S any, PS interface {
+	*S
+	setV1alpha2Fields(ruleMetaData)
+}, H any](hub []H, list string, meta ruleMetaAnnotation) []S {
+	if hub == nil {
+		return nil
+	}
+	rules := make([]S, len(hub))
+	for i, rule := range hub {
+		v := reflect.ValueOf(&rules[i]).Elem().Field(0)
+		v.Set(reflect.ValueOf(rule))
+		if d, ok := lookup(meta[list], i, v.FieldByName("Name").String()); ok {
+			PS(&rules[i]).setV1alpha2Fields(d)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,120 @@
+package v1alpha2
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+func TestFuzzyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("for AzureValidator", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme: scheme,
+		Hub:    &v1alpha1.AzureValidator{},
+		Spoke:  &AzureValidator{},
+	}))
+}
+
+func TestConversion(t *testing.T) {
+	const subscriptionID = "9b16dd0b-1bea-4c9a-a291-65e6f44c4745"
+	natGatewayRule := func(name string) v1alpha1.NATGatewayRule {
+		return v1alpha1.NATGatewayRule{Name: name, SubnetIDs: []v1alpha1.SubnetID{v1alpha1.SubnetID("/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/" + name)}}
+	}
+	spoke := &AzureValidator{
+		ObjectMeta: metav1.ObjectMeta{Name: "azurevalidator", Annotations: map[string]string{"team": "platform"}},
+		Spec: AzureValidatorSpec{
+			Auth: v1alpha1.AzureAuth{Implicit: true},
+			Network: NetworkCategory{
+				NATGatewayRules: []NATGatewayRule{
+					{NATGatewayRule: natGatewayRule("subnet-1"), SubscriptionRuleMeta: SubscriptionRuleMeta{RuleMeta: RuleMeta{Severity: SeverityHigh}, SubscriptionID: subscriptionID}},
+					{NATGatewayRule: natGatewayRule("subnet-2")},
+				},
+			},
+			Compute: ComputeCategory{
+				VMSizeRules: []VMSizeRule{{VMSizeRule: v1alpha1.VMSizeRule{Name: "sizes", SubscriptionID: subscriptionID}}},
+			},
+		},
+	}
+
+	hub := &v1alpha1.AzureValidator{}
+	if err := spoke.ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	want := &v1alpha1.AzureValidator{
+		ObjectMeta: metav1.ObjectMeta{Name: "azurevalidator", Annotations: map[string]string{
+			"team":             "platform",
+			RuleMetaAnnotation: `{"natGatewayRules":[{"name":"subnet-1","severity":"High","subscriptionId":"` + subscriptionID + `"},{"name":"subnet-2"}]}`,
+		}},
+		Spec: v1alpha1.AzureValidatorSpec{
+			Auth:            v1alpha1.AzureAuth{Implicit: true},
+			NATGatewayRules: []v1alpha1.NATGatewayRule{natGatewayRule("subnet-1"), natGatewayRule("subnet-2")},
+			VMSizeRules:     []v1alpha1.VMSizeRule{{Name: "sizes", SubscriptionID: subscriptionID}},
+		},
+	}
+	if diff := cmp.Diff(want, hub); diff != "" {
+		t.Errorf("unexpected v1alpha1 AzureValidator (-want +got):\n%s", diff)
+	}
+	if len(spoke.Annotations) != 1 {
+		t.Errorf("got annotations %v on the converted object, want them unchanged", spoke.Annotations)
+	}
+
+	// Rules keep their v1alpha2 fields when rules before them are removed in v1alpha1.
+	hub.Spec.NATGatewayRules = append([]v1alpha1.NATGatewayRule{natGatewayRule("subnet-0")}, hub.Spec.NATGatewayRules[1:]...)
+	hub.Spec.NATGatewayRules = append(hub.Spec.NATGatewayRules, natGatewayRule("subnet-1"))
+	got := &AzureValidator{}
+	if err := got.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	wantRules := []NATGatewayRule{
+		{NATGatewayRule: natGatewayRule("subnet-0")},
+		{NATGatewayRule: natGatewayRule("subnet-2")},
+		{NATGatewayRule: natGatewayRule("subnet-1"), SubscriptionRuleMeta: SubscriptionRuleMeta{RuleMeta: RuleMeta{Severity: SeverityHigh}, SubscriptionID: subscriptionID}},
+	}
+	if diff := cmp.Diff(wantRules, got.Spec.Network.NATGatewayRules); diff != "" {
+		t.Errorf("unexpected NAT gateway rules (-want +got):\n%s", diff)
+	}
+	if _, ok := got.Annotations[RuleMetaAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be removed", RuleMetaAnnotation)
+	}
+
+	hub.Annotations[RuleMetaAnnotation] = "not json"
+	if err := (&AzureValidator{}).ConvertFrom(hub); err == nil {
+		t.Error("expected an invalid annotation to fail the conversion")
+	}
+}
+
+func TestRuleSeverities(t *testing.T) {
+	meta := metav1.ObjectMeta{Annotations: map[string]string{
+		RuleMetaAnnotation: `{"rbacRules":[{"name":"rule-1","severity":"Low"},{"name":"rule-2"}],"natGatewayRules":[{"name":"subnet-1","severity":"Critical","subscriptionId":"s"}]}`,
+	}}
+	got, err := RuleSeverities(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]Severity{
+		"rbacRules":       {"rule-1": SeverityLow},
+		"natGatewayRules": {"subnet-1": SeverityCritical},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected severities (-want +got):\n%s", diff)
+	}
+	if _, ok := meta.Annotations[RuleMetaAnnotation]; !ok {
+		t.Errorf("expected the %s annotation to be kept", RuleMetaAnnotation)
+	}
+
+	meta.Annotations[RuleMetaAnnotation] = "not json"
+	if _, err := RuleSeverities(meta); err == nil {
+		t.Error("expected an invalid annotation to fail")
+	}
+}
@@ -0,0 +1,687 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// AzureValidatorSpec defines the desired state of AzureValidator. Rules are grouped by category.
+type AzureValidatorSpec struct {
+	// Rules for validating that principals have the Azure RBAC permissions they need.
+	// +optional
+	RBAC RBACCategory `json:"rbac,omitempty" yaml:"rbac,omitempty"`
+	// Rules for validating Microsoft Entra ID groups and app registrations, and the certificates
+	// that workloads authenticate with.
+	// +optional
+	Identity IdentityCategory `json:"identity,omitempty" yaml:"identity,omitempty"`
+	// Rules for validating VM sizes, images, disks, and AKS clusters.
+	// +optional
+	Compute ComputeCategory `json:"compute,omitempty" yaml:"compute,omitempty"`
+	// Rules for validating virtual networks, gateways, and their connectivity.
+	// +optional
+	Network NetworkCategory `json:"network,omitempty" yaml:"network,omitempty"`
+	// Rules for validating storage accounts, databases, and event hubs.
+	// +optional
+	Storage StorageCategory `json:"storage,omitempty" yaml:"storage,omitempty"`
+	// Rules for validating policy exemptions, Defender plans, budgets, locks, and monitoring.
+	// +optional
+	Governance GovernanceCategory `json:"governance,omitempty" yaml:"governance,omitempty"`
+	// How the plugin authenticates with Azure.
+	Auth v1alpha1.AzureAuth `json:"auth" yaml:"auth"`
+	// Labels set on the AzureValidator's ValidationResult, and added to the details of each
+	// rule's condition so that sinks can include them in notifications. They're re-applied on
+	// each reconcile, so edits to the ValidationResult's labels don't last.
+	// +optional
+	ResultLabels map[string]string `json:"resultLabels,omitempty" yaml:"resultLabels,omitempty"`
+	// If set, a rule's previous result is reused, without querying Azure, until the result is
+	// older than this. Changing the spec always causes all rules to be re-evaluated. If not set,
+	// all rules are re-evaluated on each reconcile.
+	// +optional
+	ResultMaxAge *metav1.Duration `json:"resultMaxAge,omitempty" yaml:"resultMaxAge,omitempty"`
+	// If set, how long a reconcile may spend evaluating rules. Rules that haven't been evaluated
+	// by then fail as not evaluated, and are evaluated first by the next reconcile, which follows
+	// shortly. A rule that is being evaluated when the deadline elapses is finished. If not set,
+	// all rules are evaluated on each reconcile, however long that takes.
+	// +optional
+	RunDeadline *metav1.Duration `json:"runDeadline,omitempty" yaml:"runDeadline,omitempty"`
+	// The severity of rules that don't have one of their own. Each rule's condition reports its
+	// severity in a detail. A failing rule of severity Low or Medium is a warning, which doesn't
+	// fail the ValidationResult. If not set, rules are High, so any failing rule fails it.
+	// +optional
+	DefaultSeverity Severity `json:"defaultSeverity,omitempty" yaml:"defaultSeverity,omitempty"`
+	// If true, the AzureValidator is re-validated as soon as a role assignment is created or
+	// deleted for the principal of one of its RBAC or template permission rules, or at one of
+	// their scopes or a scope containing it, rather than at its next scheduled re-validation.
+	// The previous results of those rules aren't reused then. Only takes effect if the plugin
+	// polls the Activity Log (--activity-log-poll-interval).
+	// +optional
+	RevalidateOnRoleAssignmentChanges bool `json:"revalidateOnRoleAssignmentChanges,omitempty" yaml:"revalidateOnRoleAssignmentChanges,omitempty"`
+	// If true, the plugin doesn't check, before evaluating the other rules, that its own identity
+	// can read role assignments and role definitions at the scopes of the RBAC and template
+	// permission rules.
+	// +optional
+	SkipPreflight bool `json:"skipPreflight,omitempty" yaml:"skipPreflight,omitempty"`
+	// If provided, the Azure region (e.g. australiaeast) whose regional ARM endpoint the rules'
+	// ARM requests are sent to, instead of the global endpoint, which may route them to a region
+	// far from the subscriptions. Requests fall back to the global endpoint for a while if the
+	// regional endpoint rejects one. RBAC rules can override it.
+	// +optional
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9]*$`
+	ARMRegion string `json:"armRegion,omitempty" yaml:"armRegion,omitempty"`
+	// ConfigMaps, in the same namespace as the AzureValidator, with more rules. Each key holds a
+	// group of rules, as YAML with the same fields as the rule lists of the v1alpha1 spec (e.g.
+	// rbacRules), without the fields that v1alpha2 adds. They are merged with the spec's rules on
+	// each reconcile. Rules from ConfigMaps aren't limited to 5 per type, but their names must not
+	// conflict with other rules of the same type. Whether they could be loaded is reported in a
+	// condition of its own.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	RulesFrom []v1alpha1.RulesSource `json:"rulesFrom,omitempty" yaml:"rulesFrom,omitempty"`
+	// If set, each reconcile that evaluates rules writes a snapshot of what they observed in
+	// Azure, e.g. the role assignments of each principal, quotas, and SKU capabilities, as the
+	// JSON of ARM's and Microsoft Graph's responses. Writing it is best effort: failures are
+	// logged, and don't fail validation.
+	// +optional
+	Snapshot *v1alpha1.SnapshotConfig `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+}
+
+// RBACCategory holds the RBAC rules of an AzureValidator.
+type RBACCategory struct {
+	// Rules for validating that the correct role assignments have been created in Azure RBAC to
+	// provide needed permissions.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="RBACRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	RBACRules []RBACRule `json:"rbacRules,omitempty" yaml:"rbacRules,omitempty"`
+	// Rules for validating that a principal has the permissions needed to deploy an ARM template.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="TemplatePermissionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	TemplatePermissionRules []TemplatePermissionRule `json:"templatePermissionRules,omitempty" yaml:"templatePermissionRules,omitempty"`
+}
+
+// IdentityCategory holds the identity rules of an AzureValidator.
+type IdentityCategory struct {
+	// Rules for validating that certificates stored in Azure Key Vault exist, are enabled, and
+	// don't expire soon.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="KeyVaultCertificateRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	KeyVaultCertificateRules []KeyVaultCertificateRule `json:"keyVaultCertificateRules,omitempty" yaml:"keyVaultCertificateRules,omitempty"`
+	// Rules for validating that Key Vault names are free of, or held by, soft-deleted vaults, and
+	// that vaults have soft delete and purge protection.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="KeyVaultRecoveryRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	KeyVaultRecoveryRules []KeyVaultRecoveryRule `json:"keyVaultRecoveryRules,omitempty" yaml:"keyVaultRecoveryRules,omitempty"`
+	// Rules for validating that Microsoft Entra ID (Azure AD) groups exist and have members.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="GroupMembershipRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	GroupMembershipRules []GroupMembershipRule `json:"groupMembershipRules,omitempty" yaml:"groupMembershipRules,omitempty"`
+	// Rules for validating that app registrations request API permissions and have admin consent
+	// for them.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="AppPermissionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	AppPermissionRules []AppPermissionRule `json:"appPermissionRules,omitempty" yaml:"appPermissionRules,omitempty"`
+}
+
+// ComputeCategory holds the compute rules of an AzureValidator.
+type ComputeCategory struct {
+	// Rules for validating that existing AKS clusters are configured as expected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="AKSClusterRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	AKSClusterRules []AKSClusterRule `json:"aksClusterRules,omitempty" yaml:"aksClusterRules,omitempty"`
+	// Rules for validating that compute gallery images can run on VM sizes.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ImageCompatibilityRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ImageCompatibilityRules []ImageCompatibilityRule `json:"imageCompatibilityRules,omitempty" yaml:"imageCompatibilityRules,omitempty"`
+	// Rules for validating that compute gallery image versions are replicated to regions.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ImageReplicationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ImageReplicationRules []ImageReplicationRule `json:"imageReplicationRules,omitempty" yaml:"imageReplicationRules,omitempty"`
+	// Rules for validating that VM sizes, and optionally a compute gallery image, support trusted
+	// launch or confidential VMs.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VMSecurityRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VMSecurityRules []VMSecurityRule `json:"vmSecurityRules,omitempty" yaml:"vmSecurityRules,omitempty"`
+	// Rules for validating that VM sizes have the networking and compute capabilities that
+	// workloads need.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VMSizeRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VMSizeRules []VMSizeRule `json:"vmSizeRules,omitempty" yaml:"vmSizeRules,omitempty"`
+	// Rules for validating that disks of a zonal disk type can be attached to VMs of a size in
+	// availability zones.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DiskZoneRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DiskZoneRules []DiskZoneRule `json:"diskZoneRules,omitempty" yaml:"diskZoneRules,omitempty"`
+	// Rules for validating that proximity placement groups are colocated and allow the VM sizes
+	// that will be deployed into them.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ProximityPlacementGroupRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ProximityPlacementGroupRules []ProximityPlacementGroupRule `json:"proximityPlacementGroupRules,omitempty" yaml:"proximityPlacementGroupRules,omitempty"`
+	// Rules for validating that VMs of some sizes can be created with encryption at host.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="EncryptionAtHostRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	EncryptionAtHostRules []EncryptionAtHostRule `json:"encryptionAtHostRules,omitempty" yaml:"encryptionAtHostRules,omitempty"`
+	// Rules for validating that platform VM images aren't deprecated or scheduled for
+	// deprecation.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ImageDeprecationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ImageDeprecationRules []ImageDeprecationRule `json:"imageDeprecationRules,omitempty" yaml:"imageDeprecationRules,omitempty"`
+	// Rules for validating that locations have availability zones for zonal deployments.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ZoneRedundancyRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ZoneRedundancyRules []ZoneRedundancyRule `json:"zoneRedundancyRules,omitempty" yaml:"zoneRedundancyRules,omitempty"`
+	// Rules for validating that Azure Arc custom locations are provisioned, with their cluster
+	// extensions provisioned and their hosts connected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="CustomLocationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	CustomLocationRules []CustomLocationRule `json:"customLocationRules,omitempty" yaml:"customLocationRules,omitempty"`
+}
+
+// NetworkCategory holds the network rules of an AzureValidator.
+type NetworkCategory struct {
+	// Rules for validating that subnets have outbound connectivity through a NAT gateway.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="NATGatewayRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	NATGatewayRules []NATGatewayRule `json:"natGatewayRules,omitempty" yaml:"natGatewayRules,omitempty"`
+	// Rules for validating that virtual networks are peered with other virtual networks as
+	// expected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VNetPeeringRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VNetPeeringRules []VNetPeeringRule `json:"vnetPeeringRules,omitempty" yaml:"vnetPeeringRules,omitempty"`
+	// Rules for validating that route tables have the routes that a subnet's traffic requires
+	// (e.g. for forced tunneling through a virtual appliance).
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="RouteTableRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	RouteTableRules []RouteTableRule `json:"routeTableRules,omitempty" yaml:"routeTableRules,omitempty"`
+	// Rules for validating that Azure Firewall policies allow traffic, e.g. the egress that AKS
+	// clusters need.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="FirewallPolicyRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	FirewallPolicyRules []FirewallPolicyRule `json:"firewallPolicyRules,omitempty" yaml:"firewallPolicyRules,omitempty"`
+	// Rules for validating that virtual networks have an Azure Bastion host, e.g. because clusters
+	// may only be provisioned in virtual networks that can be reached through one.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BastionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BastionRules []BastionRule `json:"bastionRules,omitempty" yaml:"bastionRules,omitempty"`
+	// Rules for validating that virtual networks are protected by a DDoS protection plan.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DDoSProtectionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DDoSProtectionRules []DDoSProtectionRule `json:"ddosProtectionRules,omitempty" yaml:"ddosProtectionRules,omitempty"`
+	// Rules for validating that public IP prefixes have enough free addresses, e.g. for load
+	// balancers that must get their addresses from them.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="PublicIPPrefixRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	PublicIPPrefixRules []PublicIPPrefixRule `json:"publicIPPrefixRules,omitempty" yaml:"publicIPPrefixRules,omitempty"`
+	// Rules for validating application gateways and their WAF policies, e.g. for clusters that
+	// use the Application Gateway Ingress Controller.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ApplicationGatewayRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ApplicationGatewayRules []ApplicationGatewayRule `json:"applicationGatewayRules,omitempty" yaml:"applicationGatewayRules,omitempty"`
+	// Rules for validating that global entry points, such as Traffic Manager profiles, are
+	// enabled and have healthy endpoints.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="GlobalEndpointRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	GlobalEndpointRules []GlobalEndpointRule `json:"globalEndpointRules,omitempty" yaml:"globalEndpointRules,omitempty"`
+	// Rules for validating that ExpressRoute circuits are provisioned, and optionally that the
+	// virtual network gateway connections to them are connected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ExpressRouteRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ExpressRouteRules []ExpressRouteRule `json:"expressRouteRules,omitempty" yaml:"expressRouteRules,omitempty"`
+	// Rules for validating that VPN gateways are configured as expected and that their
+	// site-to-site connections are connected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="VPNGatewayRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	VPNGatewayRules []VPNGatewayRule `json:"vpnGatewayRules,omitempty" yaml:"vpnGatewayRules,omitempty"`
+	// Rules for validating that Network Watcher is enabled in a location, and that network
+	// security groups have flow logs configured.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="NetworkWatcherRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	NetworkWatcherRules []NetworkWatcherRule `json:"networkWatcherRules,omitempty" yaml:"networkWatcherRules,omitempty"`
+	// Rules for validating that subnets have no conflicting delegations or service association
+	// links, and the expected network policies.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="SubnetDelegationRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	SubnetDelegationRules []SubnetDelegationRule `json:"subnetDelegationRules,omitempty" yaml:"subnetDelegationRules,omitempty"`
+}
+
+// StorageCategory holds the storage rules of an AzureValidator.
+type StorageCategory struct {
+	// Rules for validating that blob containers have locked immutability policies and
+	// lifecycle management rules.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BlobContainerRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BlobContainerRules []BlobContainerRule `json:"blobContainerRules,omitempty" yaml:"blobContainerRules,omitempty"`
+	// Rules for validating that storage accounts deny network access by default, and allow it
+	// from subnets and IP ranges.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="StorageNetworkRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	StorageNetworkRules []StorageNetworkRule `json:"storageNetworkRules,omitempty" yaml:"storageNetworkRules,omitempty"`
+	// Rules for validating that storage accounts have file shares with enough quota and the
+	// expected access tier.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="FileShareRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	FileShareRules []FileShareRule `json:"fileShareRules,omitempty" yaml:"fileShareRules,omitempty"`
+	// Rules for validating the configuration of pre-provisioned Cosmos DB accounts.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="CosmosDBRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	CosmosDBRules []CosmosDBRule `json:"cosmosDBRules,omitempty" yaml:"cosmosDBRules,omitempty"`
+	// Rules for validating the authentication and network configuration of Azure SQL logical
+	// servers.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="SQLServerRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	SQLServerRules []SQLServerRule `json:"sqlServerRules,omitempty" yaml:"sqlServerRules,omitempty"`
+	// Rules for validating that an Event Hubs namespace has an event hub that clients can send to,
+	// e.g. for streaming audit logs.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="EventHubRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	EventHubRules []EventHubRule `json:"eventHubRules,omitempty" yaml:"eventHubRules,omitempty"`
+}
+
+// GovernanceCategory holds the governance rules of an AzureValidator.
+type GovernanceCategory struct {
+	// Rules for validating that Azure Policy exemptions exist for a scope and don't expire soon.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="PolicyExemptionRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	PolicyExemptionRules []PolicyExemptionRule `json:"policyExemptionRules,omitempty" yaml:"policyExemptionRules,omitempty"`
+	// Rules for validating that Microsoft Defender for Cloud plans are enabled for a subscription.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="DefenderPlanRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	DefenderPlanRules []DefenderPlanRule `json:"defenderPlanRules,omitempty" yaml:"defenderPlanRules,omitempty"`
+	// Rules for validating that a budget with alerts exists for a subscription or resource group.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="BudgetRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	BudgetRules []BudgetRule `json:"budgetRules,omitempty" yaml:"budgetRules,omitempty"`
+	// Rules for validating that resource groups and resources have management locks.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ResourceLockRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ResourceLockRules []ResourceLockRule `json:"resourceLockRules,omitempty" yaml:"resourceLockRules,omitempty"`
+	// Rules for validating that Azure Monitor action groups have the expected receivers, and that
+	// metric alert rules exist and are enabled.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="MonitorAlertRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	MonitorAlertRules []MonitorAlertRule `json:"monitorAlertRules,omitempty" yaml:"monitorAlertRules,omitempty"`
+	// Rules for validating that subscriptions export their Activity Log to a Log Analytics
+	// workspace or a storage account.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="ActivityLogExportRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	ActivityLogExportRules []ActivityLogExportRule `json:"activityLogExportRules,omitempty" yaml:"activityLogExportRules,omitempty"`
+}
+
+// Severity is how serious a rule's failures are.
+// +kubebuilder:validation:Enum=Low;Medium;High;Critical
+type Severity string
+
+const (
+	SeverityLow      Severity = "Low"
+	SeverityMedium   Severity = "Medium"
+	SeverityHigh     Severity = "High"
+	SeverityCritical Severity = "Critical"
+)
+
+// RuleMeta are the fields that v1alpha2 adds to every rule.
+type RuleMeta struct {
+	// How serious the rule's failures are, for sinks and dashboards to prioritize them. It
+	// doesn't affect how the rule is evaluated, but a failing rule of severity Low or Medium is a
+	// warning, which doesn't fail the ValidationResult. If not set, spec.defaultSeverity applies.
+	// +optional
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// SubscriptionRuleMeta are the fields that v1alpha2 adds to the rules whose v1alpha1 version
+// identifies resources by resource ID only, so that every rule has a subscriptionId.
+type SubscriptionRuleMeta struct {
+	RuleMeta `json:",inline" yaml:",inline"`
+	// The subscription of the resources that the rule validates, for sinks and dashboards to
+	// group rules by. The rule's resource IDs still identify the resources it validates.
+	// +optional
+	// +kubebuilder:validation:MaxLength=36
+	SubscriptionID string `json:"subscriptionId,omitempty" yaml:"subscriptionId,omitempty"`
+}
+
+// RBACRule is a v1alpha1 RBACRule, with the v1alpha2 rule fields.
+type RBACRule struct {
+	v1alpha1.RBACRule    `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta `json:",inline" yaml:",inline"`
+}
+
+// TemplatePermissionRule is a v1alpha1 TemplatePermissionRule, with the v1alpha2 rule fields.
+type TemplatePermissionRule struct {
+	v1alpha1.TemplatePermissionRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta            `json:",inline" yaml:",inline"`
+}
+
+// KeyVaultCertificateRule is a v1alpha1 KeyVaultCertificateRule, with the v1alpha2 rule fields.
+type KeyVaultCertificateRule struct {
+	v1alpha1.KeyVaultCertificateRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta             `json:",inline" yaml:",inline"`
+}
+
+// KeyVaultRecoveryRule is a v1alpha1 KeyVaultRecoveryRule, with the v1alpha2 rule fields.
+type KeyVaultRecoveryRule struct {
+	v1alpha1.KeyVaultRecoveryRule `json:",inline" yaml:",inline"`
+	RuleMeta                      `json:",inline" yaml:",inline"`
+}
+
+// GroupMembershipRule is a v1alpha1 GroupMembershipRule, with the v1alpha2 rule fields.
+type GroupMembershipRule struct {
+	v1alpha1.GroupMembershipRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta         `json:",inline" yaml:",inline"`
+}
+
+// AppPermissionRule is a v1alpha1 AppPermissionRule, with the v1alpha2 rule fields.
+type AppPermissionRule struct {
+	v1alpha1.AppPermissionRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta       `json:",inline" yaml:",inline"`
+}
+
+// AKSClusterRule is a v1alpha1 AKSClusterRule, with the v1alpha2 rule fields.
+type AKSClusterRule struct {
+	v1alpha1.AKSClusterRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta    `json:",inline" yaml:",inline"`
+}
+
+// ImageCompatibilityRule is a v1alpha1 ImageCompatibilityRule, with the v1alpha2 rule fields.
+type ImageCompatibilityRule struct {
+	v1alpha1.ImageCompatibilityRule `json:",inline" yaml:",inline"`
+	RuleMeta                        `json:",inline" yaml:",inline"`
+}
+
+// ImageReplicationRule is a v1alpha1 ImageReplicationRule, with the v1alpha2 rule fields.
+type ImageReplicationRule struct {
+	v1alpha1.ImageReplicationRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta          `json:",inline" yaml:",inline"`
+}
+
+// VMSecurityRule is a v1alpha1 VMSecurityRule, with the v1alpha2 rule fields.
+type VMSecurityRule struct {
+	v1alpha1.VMSecurityRule `json:",inline" yaml:",inline"`
+	RuleMeta                `json:",inline" yaml:",inline"`
+}
+
+// VMSizeRule is a v1alpha1 VMSizeRule, with the v1alpha2 rule fields.
+type VMSizeRule struct {
+	v1alpha1.VMSizeRule `json:",inline" yaml:",inline"`
+	RuleMeta            `json:",inline" yaml:",inline"`
+}
+
+// DiskZoneRule is a v1alpha1 DiskZoneRule, with the v1alpha2 rule fields.
+type DiskZoneRule struct {
+	v1alpha1.DiskZoneRule `json:",inline" yaml:",inline"`
+	RuleMeta              `json:",inline" yaml:",inline"`
+}
+
+// ProximityPlacementGroupRule is a v1alpha1 ProximityPlacementGroupRule, with the v1alpha2 rule fields.
+type ProximityPlacementGroupRule struct {
+	v1alpha1.ProximityPlacementGroupRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta                 `json:",inline" yaml:",inline"`
+}
+
+// EncryptionAtHostRule is a v1alpha1 EncryptionAtHostRule, with the v1alpha2 rule fields.
+type EncryptionAtHostRule struct {
+	v1alpha1.EncryptionAtHostRule `json:",inline" yaml:",inline"`
+	RuleMeta                      `json:",inline" yaml:",inline"`
+}
+
+// ImageDeprecationRule is a v1alpha1 ImageDeprecationRule, with the v1alpha2 rule fields.
+type ImageDeprecationRule struct {
+	v1alpha1.ImageDeprecationRule `json:",inline" yaml:",inline"`
+	RuleMeta                      `json:",inline" yaml:",inline"`
+}
+
+// ZoneRedundancyRule is a v1alpha1 ZoneRedundancyRule, with the v1alpha2 rule fields.
+type ZoneRedundancyRule struct {
+	v1alpha1.ZoneRedundancyRule `json:",inline" yaml:",inline"`
+	RuleMeta                    `json:",inline" yaml:",inline"`
+}
+
+// CustomLocationRule is a v1alpha1 CustomLocationRule, with the v1alpha2 rule fields.
+type CustomLocationRule struct {
+	v1alpha1.CustomLocationRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta        `json:",inline" yaml:",inline"`
+}
+
+// NATGatewayRule is a v1alpha1 NATGatewayRule, with the v1alpha2 rule fields.
+type NATGatewayRule struct {
+	v1alpha1.NATGatewayRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta    `json:",inline" yaml:",inline"`
+}
+
+// VNetPeeringRule is a v1alpha1 VNetPeeringRule, with the v1alpha2 rule fields.
+type VNetPeeringRule struct {
+	v1alpha1.VNetPeeringRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta     `json:",inline" yaml:",inline"`
+}
+
+// RouteTableRule is a v1alpha1 RouteTableRule, with the v1alpha2 rule fields.
+type RouteTableRule struct {
+	v1alpha1.RouteTableRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta    `json:",inline" yaml:",inline"`
+}
+
+// FirewallPolicyRule is a v1alpha1 FirewallPolicyRule, with the v1alpha2 rule fields.
+type FirewallPolicyRule struct {
+	v1alpha1.FirewallPolicyRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta        `json:",inline" yaml:",inline"`
+}
+
+// BastionRule is a v1alpha1 BastionRule, with the v1alpha2 rule fields.
+type BastionRule struct {
+	v1alpha1.BastionRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta `json:",inline" yaml:",inline"`
+}
+
+// DDoSProtectionRule is a v1alpha1 DDoSProtectionRule, with the v1alpha2 rule fields.
+type DDoSProtectionRule struct {
+	v1alpha1.DDoSProtectionRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta        `json:",inline" yaml:",inline"`
+}
+
+// PublicIPPrefixRule is a v1alpha1 PublicIPPrefixRule, with the v1alpha2 rule fields.
+type PublicIPPrefixRule struct {
+	v1alpha1.PublicIPPrefixRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta        `json:",inline" yaml:",inline"`
+}
+
+// ApplicationGatewayRule is a v1alpha1 ApplicationGatewayRule, with the v1alpha2 rule fields.
+type ApplicationGatewayRule struct {
+	v1alpha1.ApplicationGatewayRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta            `json:",inline" yaml:",inline"`
+}
+
+// GlobalEndpointRule is a v1alpha1 GlobalEndpointRule, with the v1alpha2 rule fields.
+type GlobalEndpointRule struct {
+	v1alpha1.GlobalEndpointRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta        `json:",inline" yaml:",inline"`
+}
+
+// ExpressRouteRule is a v1alpha1 ExpressRouteRule, with the v1alpha2 rule fields.
+type ExpressRouteRule struct {
+	v1alpha1.ExpressRouteRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta      `json:",inline" yaml:",inline"`
+}
+
+// VPNGatewayRule is a v1alpha1 VPNGatewayRule, with the v1alpha2 rule fields.
+type VPNGatewayRule struct {
+	v1alpha1.VPNGatewayRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta    `json:",inline" yaml:",inline"`
+}
+
+// NetworkWatcherRule is a v1alpha1 NetworkWatcherRule, with the v1alpha2 rule fields.
+type NetworkWatcherRule struct {
+	v1alpha1.NetworkWatcherRule `json:",inline" yaml:",inline"`
+	RuleMeta                    `json:",inline" yaml:",inline"`
+}
+
+// SubnetDelegationRule is a v1alpha1 SubnetDelegationRule, with the v1alpha2 rule fields.
+type SubnetDelegationRule struct {
+	v1alpha1.SubnetDelegationRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta          `json:",inline" yaml:",inline"`
+}
+
+// BlobContainerRule is a v1alpha1 BlobContainerRule, with the v1alpha2 rule fields.
+type BlobContainerRule struct {
+	v1alpha1.BlobContainerRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta       `json:",inline" yaml:",inline"`
+}
+
+// StorageNetworkRule is a v1alpha1 StorageNetworkRule, with the v1alpha2 rule fields.
+type StorageNetworkRule struct {
+	v1alpha1.StorageNetworkRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta        `json:",inline" yaml:",inline"`
+}
+
+// FileShareRule is a v1alpha1 FileShareRule, with the v1alpha2 rule fields.
+type FileShareRule struct {
+	v1alpha1.FileShareRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta   `json:",inline" yaml:",inline"`
+}
+
+// CosmosDBRule is a v1alpha1 CosmosDBRule, with the v1alpha2 rule fields.
+type CosmosDBRule struct {
+	v1alpha1.CosmosDBRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta  `json:",inline" yaml:",inline"`
+}
+
+// SQLServerRule is a v1alpha1 SQLServerRule, with the v1alpha2 rule fields.
+type SQLServerRule struct {
+	v1alpha1.SQLServerRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta   `json:",inline" yaml:",inline"`
+}
+
+// EventHubRule is a v1alpha1 EventHubRule, with the v1alpha2 rule fields.
+type EventHubRule struct {
+	v1alpha1.EventHubRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta  `json:",inline" yaml:",inline"`
+}
+
+// PolicyExemptionRule is a v1alpha1 PolicyExemptionRule, with the v1alpha2 rule fields.
+type PolicyExemptionRule struct {
+	v1alpha1.PolicyExemptionRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta         `json:",inline" yaml:",inline"`
+}
+
+// DefenderPlanRule is a v1alpha1 DefenderPlanRule, with the v1alpha2 rule fields.
+type DefenderPlanRule struct {
+	v1alpha1.DefenderPlanRule `json:",inline" yaml:",inline"`
+	RuleMeta                  `json:",inline" yaml:",inline"`
+}
+
+// BudgetRule is a v1alpha1 BudgetRule, with the v1alpha2 rule fields.
+type BudgetRule struct {
+	v1alpha1.BudgetRule  `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta `json:",inline" yaml:",inline"`
+}
+
+// ResourceLockRule is a v1alpha1 ResourceLockRule, with the v1alpha2 rule fields.
+type ResourceLockRule struct {
+	v1alpha1.ResourceLockRule `json:",inline" yaml:",inline"`
+	SubscriptionRuleMeta      `json:",inline" yaml:",inline"`
+}
+
+// MonitorAlertRule is a v1alpha1 MonitorAlertRule, with the v1alpha2 rule fields.
+type MonitorAlertRule struct {
+	v1alpha1.MonitorAlertRule `json:",inline" yaml:",inline"`
+	RuleMeta                  `json:",inline" yaml:",inline"`
+}
+
+// ActivityLogExportRule is a v1alpha1 ActivityLogExportRule, with the v1alpha2 rule fields.
+type ActivityLogExportRule struct {
+	v1alpha1.ActivityLogExportRule `json:",inline" yaml:",inline"`
+	RuleMeta                       `json:",inline" yaml:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:unservedversion
+
+// AzureValidator is the Schema for the azurevalidators API. In v1alpha2, rules are grouped by
+// category, and every rule has a name, a severity, and a subscriptionId.
+//
+// v1alpha1 is the storage version, and the version the controller reconciles. The conversion
+// webhook converts between the versions without losing data: the severities and subscriptionIds
+// that v1alpha1 has no field for are kept in the validation.spectrocloud.labs/v1alpha2-rule-meta
+// annotation of the stored object. v1alpha2 is only served once the conversion webhook is
+// deployed.
+//
+// Storage version migration: before v1alpha2 becomes the storage version, every AzureValidator
+// must be rewritten after the CRD is updated to store v1alpha2 (e.g. with the
+// kube-storage-version-migrator, or by a no-op update of each object), and v1alpha1 removed from
+// the CRD's status.storedVersions. Only then can v1alpha1 stop being served.
+type AzureValidator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AzureValidatorSpec            `json:"spec,omitempty"`
+	Status v1alpha1.AzureValidatorStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AzureValidatorList contains a list of AzureValidator
+type AzureValidatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AzureValidator `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AzureValidator{}, &AzureValidatorList{})
+}
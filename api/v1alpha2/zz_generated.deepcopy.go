@@ -0,0 +1,1267 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKSClusterRule) DeepCopyInto(out *AKSClusterRule) {
+	*out = *in
+	in.AKSClusterRule.DeepCopyInto(&out.AKSClusterRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSClusterRule.
+func (in *AKSClusterRule) DeepCopy() *AKSClusterRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AKSClusterRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActivityLogExportRule) DeepCopyInto(out *ActivityLogExportRule) {
+	*out = *in
+	in.ActivityLogExportRule.DeepCopyInto(&out.ActivityLogExportRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActivityLogExportRule.
+func (in *ActivityLogExportRule) DeepCopy() *ActivityLogExportRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ActivityLogExportRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPermissionRule) DeepCopyInto(out *AppPermissionRule) {
+	*out = *in
+	in.AppPermissionRule.DeepCopyInto(&out.AppPermissionRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPermissionRule.
+func (in *AppPermissionRule) DeepCopy() *AppPermissionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPermissionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationGatewayRule) DeepCopyInto(out *ApplicationGatewayRule) {
+	*out = *in
+	in.ApplicationGatewayRule.DeepCopyInto(&out.ApplicationGatewayRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationGatewayRule.
+func (in *ApplicationGatewayRule) DeepCopy() *ApplicationGatewayRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationGatewayRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureValidator) DeepCopyInto(out *AzureValidator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidator.
+func (in *AzureValidator) DeepCopy() *AzureValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AzureValidator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureValidatorList) DeepCopyInto(out *AzureValidatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AzureValidator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidatorList.
+func (in *AzureValidatorList) DeepCopy() *AzureValidatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureValidatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AzureValidatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureValidatorSpec) DeepCopyInto(out *AzureValidatorSpec) {
+	*out = *in
+	in.RBAC.DeepCopyInto(&out.RBAC)
+	in.Identity.DeepCopyInto(&out.Identity)
+	in.Compute.DeepCopyInto(&out.Compute)
+	in.Network.DeepCopyInto(&out.Network)
+	in.Storage.DeepCopyInto(&out.Storage)
+	in.Governance.DeepCopyInto(&out.Governance)
+	out.Auth = in.Auth
+	if in.ResultLabels != nil {
+		in, out := &in.ResultLabels, &out.ResultLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResultMaxAge != nil {
+		in, out := &in.ResultMaxAge, &out.ResultMaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RunDeadline != nil {
+		in, out := &in.RunDeadline, &out.RunDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RulesFrom != nil {
+		in, out := &in.RulesFrom, &out.RulesFrom
+		*out = make([]v1alpha1.RulesSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(v1alpha1.SnapshotConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureValidatorSpec.
+func (in *AzureValidatorSpec) DeepCopy() *AzureValidatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureValidatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BastionRule) DeepCopyInto(out *BastionRule) {
+	*out = *in
+	in.BastionRule.DeepCopyInto(&out.BastionRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BastionRule.
+func (in *BastionRule) DeepCopy() *BastionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BastionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlobContainerRule) DeepCopyInto(out *BlobContainerRule) {
+	*out = *in
+	in.BlobContainerRule.DeepCopyInto(&out.BlobContainerRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlobContainerRule.
+func (in *BlobContainerRule) DeepCopy() *BlobContainerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BlobContainerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BudgetRule) DeepCopyInto(out *BudgetRule) {
+	*out = *in
+	in.BudgetRule.DeepCopyInto(&out.BudgetRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetRule.
+func (in *BudgetRule) DeepCopy() *BudgetRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BudgetRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComputeCategory) DeepCopyInto(out *ComputeCategory) {
+	*out = *in
+	if in.AKSClusterRules != nil {
+		in, out := &in.AKSClusterRules, &out.AKSClusterRules
+		*out = make([]AKSClusterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageCompatibilityRules != nil {
+		in, out := &in.ImageCompatibilityRules, &out.ImageCompatibilityRules
+		*out = make([]ImageCompatibilityRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageReplicationRules != nil {
+		in, out := &in.ImageReplicationRules, &out.ImageReplicationRules
+		*out = make([]ImageReplicationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMSecurityRules != nil {
+		in, out := &in.VMSecurityRules, &out.VMSecurityRules
+		*out = make([]VMSecurityRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMSizeRules != nil {
+		in, out := &in.VMSizeRules, &out.VMSizeRules
+		*out = make([]VMSizeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DiskZoneRules != nil {
+		in, out := &in.DiskZoneRules, &out.DiskZoneRules
+		*out = make([]DiskZoneRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProximityPlacementGroupRules != nil {
+		in, out := &in.ProximityPlacementGroupRules, &out.ProximityPlacementGroupRules
+		*out = make([]ProximityPlacementGroupRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EncryptionAtHostRules != nil {
+		in, out := &in.EncryptionAtHostRules, &out.EncryptionAtHostRules
+		*out = make([]EncryptionAtHostRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageDeprecationRules != nil {
+		in, out := &in.ImageDeprecationRules, &out.ImageDeprecationRules
+		*out = make([]ImageDeprecationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZoneRedundancyRules != nil {
+		in, out := &in.ZoneRedundancyRules, &out.ZoneRedundancyRules
+		*out = make([]ZoneRedundancyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomLocationRules != nil {
+		in, out := &in.CustomLocationRules, &out.CustomLocationRules
+		*out = make([]CustomLocationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComputeCategory.
+func (in *ComputeCategory) DeepCopy() *ComputeCategory {
+	if in == nil {
+		return nil
+	}
+	out := new(ComputeCategory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CosmosDBRule) DeepCopyInto(out *CosmosDBRule) {
+	*out = *in
+	in.CosmosDBRule.DeepCopyInto(&out.CosmosDBRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosmosDBRule.
+func (in *CosmosDBRule) DeepCopy() *CosmosDBRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CosmosDBRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomLocationRule) DeepCopyInto(out *CustomLocationRule) {
+	*out = *in
+	in.CustomLocationRule.DeepCopyInto(&out.CustomLocationRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomLocationRule.
+func (in *CustomLocationRule) DeepCopy() *CustomLocationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomLocationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DDoSProtectionRule) DeepCopyInto(out *DDoSProtectionRule) {
+	*out = *in
+	in.DDoSProtectionRule.DeepCopyInto(&out.DDoSProtectionRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DDoSProtectionRule.
+func (in *DDoSProtectionRule) DeepCopy() *DDoSProtectionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DDoSProtectionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefenderPlanRule) DeepCopyInto(out *DefenderPlanRule) {
+	*out = *in
+	in.DefenderPlanRule.DeepCopyInto(&out.DefenderPlanRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefenderPlanRule.
+func (in *DefenderPlanRule) DeepCopy() *DefenderPlanRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DefenderPlanRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskZoneRule) DeepCopyInto(out *DiskZoneRule) {
+	*out = *in
+	in.DiskZoneRule.DeepCopyInto(&out.DiskZoneRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskZoneRule.
+func (in *DiskZoneRule) DeepCopy() *DiskZoneRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskZoneRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionAtHostRule) DeepCopyInto(out *EncryptionAtHostRule) {
+	*out = *in
+	in.EncryptionAtHostRule.DeepCopyInto(&out.EncryptionAtHostRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionAtHostRule.
+func (in *EncryptionAtHostRule) DeepCopy() *EncryptionAtHostRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionAtHostRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventHubRule) DeepCopyInto(out *EventHubRule) {
+	*out = *in
+	in.EventHubRule.DeepCopyInto(&out.EventHubRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventHubRule.
+func (in *EventHubRule) DeepCopy() *EventHubRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EventHubRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpressRouteRule) DeepCopyInto(out *ExpressRouteRule) {
+	*out = *in
+	in.ExpressRouteRule.DeepCopyInto(&out.ExpressRouteRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpressRouteRule.
+func (in *ExpressRouteRule) DeepCopy() *ExpressRouteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpressRouteRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileShareRule) DeepCopyInto(out *FileShareRule) {
+	*out = *in
+	in.FileShareRule.DeepCopyInto(&out.FileShareRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileShareRule.
+func (in *FileShareRule) DeepCopy() *FileShareRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FileShareRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirewallPolicyRule) DeepCopyInto(out *FirewallPolicyRule) {
+	*out = *in
+	in.FirewallPolicyRule.DeepCopyInto(&out.FirewallPolicyRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallPolicyRule.
+func (in *FirewallPolicyRule) DeepCopy() *FirewallPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FirewallPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalEndpointRule) DeepCopyInto(out *GlobalEndpointRule) {
+	*out = *in
+	in.GlobalEndpointRule.DeepCopyInto(&out.GlobalEndpointRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalEndpointRule.
+func (in *GlobalEndpointRule) DeepCopy() *GlobalEndpointRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalEndpointRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GovernanceCategory) DeepCopyInto(out *GovernanceCategory) {
+	*out = *in
+	if in.PolicyExemptionRules != nil {
+		in, out := &in.PolicyExemptionRules, &out.PolicyExemptionRules
+		*out = make([]PolicyExemptionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefenderPlanRules != nil {
+		in, out := &in.DefenderPlanRules, &out.DefenderPlanRules
+		*out = make([]DefenderPlanRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BudgetRules != nil {
+		in, out := &in.BudgetRules, &out.BudgetRules
+		*out = make([]BudgetRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceLockRules != nil {
+		in, out := &in.ResourceLockRules, &out.ResourceLockRules
+		*out = make([]ResourceLockRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MonitorAlertRules != nil {
+		in, out := &in.MonitorAlertRules, &out.MonitorAlertRules
+		*out = make([]MonitorAlertRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ActivityLogExportRules != nil {
+		in, out := &in.ActivityLogExportRules, &out.ActivityLogExportRules
+		*out = make([]ActivityLogExportRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GovernanceCategory.
+func (in *GovernanceCategory) DeepCopy() *GovernanceCategory {
+	if in == nil {
+		return nil
+	}
+	out := new(GovernanceCategory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupMembershipRule) DeepCopyInto(out *GroupMembershipRule) {
+	*out = *in
+	in.GroupMembershipRule.DeepCopyInto(&out.GroupMembershipRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupMembershipRule.
+func (in *GroupMembershipRule) DeepCopy() *GroupMembershipRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupMembershipRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentityCategory) DeepCopyInto(out *IdentityCategory) {
+	*out = *in
+	if in.KeyVaultCertificateRules != nil {
+		in, out := &in.KeyVaultCertificateRules, &out.KeyVaultCertificateRules
+		*out = make([]KeyVaultCertificateRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeyVaultRecoveryRules != nil {
+		in, out := &in.KeyVaultRecoveryRules, &out.KeyVaultRecoveryRules
+		*out = make([]KeyVaultRecoveryRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GroupMembershipRules != nil {
+		in, out := &in.GroupMembershipRules, &out.GroupMembershipRules
+		*out = make([]GroupMembershipRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppPermissionRules != nil {
+		in, out := &in.AppPermissionRules, &out.AppPermissionRules
+		*out = make([]AppPermissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityCategory.
+func (in *IdentityCategory) DeepCopy() *IdentityCategory {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentityCategory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCompatibilityRule) DeepCopyInto(out *ImageCompatibilityRule) {
+	*out = *in
+	in.ImageCompatibilityRule.DeepCopyInto(&out.ImageCompatibilityRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCompatibilityRule.
+func (in *ImageCompatibilityRule) DeepCopy() *ImageCompatibilityRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCompatibilityRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDeprecationRule) DeepCopyInto(out *ImageDeprecationRule) {
+	*out = *in
+	in.ImageDeprecationRule.DeepCopyInto(&out.ImageDeprecationRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDeprecationRule.
+func (in *ImageDeprecationRule) DeepCopy() *ImageDeprecationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDeprecationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageReplicationRule) DeepCopyInto(out *ImageReplicationRule) {
+	*out = *in
+	in.ImageReplicationRule.DeepCopyInto(&out.ImageReplicationRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageReplicationRule.
+func (in *ImageReplicationRule) DeepCopy() *ImageReplicationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageReplicationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultCertificateRule) DeepCopyInto(out *KeyVaultCertificateRule) {
+	*out = *in
+	in.KeyVaultCertificateRule.DeepCopyInto(&out.KeyVaultCertificateRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultCertificateRule.
+func (in *KeyVaultCertificateRule) DeepCopy() *KeyVaultCertificateRule {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultCertificateRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultRecoveryRule) DeepCopyInto(out *KeyVaultRecoveryRule) {
+	*out = *in
+	in.KeyVaultRecoveryRule.DeepCopyInto(&out.KeyVaultRecoveryRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultRecoveryRule.
+func (in *KeyVaultRecoveryRule) DeepCopy() *KeyVaultRecoveryRule {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultRecoveryRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorAlertRule) DeepCopyInto(out *MonitorAlertRule) {
+	*out = *in
+	in.MonitorAlertRule.DeepCopyInto(&out.MonitorAlertRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorAlertRule.
+func (in *MonitorAlertRule) DeepCopy() *MonitorAlertRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorAlertRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATGatewayRule) DeepCopyInto(out *NATGatewayRule) {
+	*out = *in
+	in.NATGatewayRule.DeepCopyInto(&out.NATGatewayRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATGatewayRule.
+func (in *NATGatewayRule) DeepCopy() *NATGatewayRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NATGatewayRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkCategory) DeepCopyInto(out *NetworkCategory) {
+	*out = *in
+	if in.NATGatewayRules != nil {
+		in, out := &in.NATGatewayRules, &out.NATGatewayRules
+		*out = make([]NATGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VNetPeeringRules != nil {
+		in, out := &in.VNetPeeringRules, &out.VNetPeeringRules
+		*out = make([]VNetPeeringRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RouteTableRules != nil {
+		in, out := &in.RouteTableRules, &out.RouteTableRules
+		*out = make([]RouteTableRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FirewallPolicyRules != nil {
+		in, out := &in.FirewallPolicyRules, &out.FirewallPolicyRules
+		*out = make([]FirewallPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BastionRules != nil {
+		in, out := &in.BastionRules, &out.BastionRules
+		*out = make([]BastionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DDoSProtectionRules != nil {
+		in, out := &in.DDoSProtectionRules, &out.DDoSProtectionRules
+		*out = make([]DDoSProtectionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PublicIPPrefixRules != nil {
+		in, out := &in.PublicIPPrefixRules, &out.PublicIPPrefixRules
+		*out = make([]PublicIPPrefixRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApplicationGatewayRules != nil {
+		in, out := &in.ApplicationGatewayRules, &out.ApplicationGatewayRules
+		*out = make([]ApplicationGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GlobalEndpointRules != nil {
+		in, out := &in.GlobalEndpointRules, &out.GlobalEndpointRules
+		*out = make([]GlobalEndpointRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExpressRouteRules != nil {
+		in, out := &in.ExpressRouteRules, &out.ExpressRouteRules
+		*out = make([]ExpressRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VPNGatewayRules != nil {
+		in, out := &in.VPNGatewayRules, &out.VPNGatewayRules
+		*out = make([]VPNGatewayRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkWatcherRules != nil {
+		in, out := &in.NetworkWatcherRules, &out.NetworkWatcherRules
+		*out = make([]NetworkWatcherRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubnetDelegationRules != nil {
+		in, out := &in.SubnetDelegationRules, &out.SubnetDelegationRules
+		*out = make([]SubnetDelegationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkCategory.
+func (in *NetworkCategory) DeepCopy() *NetworkCategory {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkCategory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkWatcherRule) DeepCopyInto(out *NetworkWatcherRule) {
+	*out = *in
+	in.NetworkWatcherRule.DeepCopyInto(&out.NetworkWatcherRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkWatcherRule.
+func (in *NetworkWatcherRule) DeepCopy() *NetworkWatcherRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkWatcherRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyExemptionRule) DeepCopyInto(out *PolicyExemptionRule) {
+	*out = *in
+	in.PolicyExemptionRule.DeepCopyInto(&out.PolicyExemptionRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyExemptionRule.
+func (in *PolicyExemptionRule) DeepCopy() *PolicyExemptionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyExemptionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProximityPlacementGroupRule) DeepCopyInto(out *ProximityPlacementGroupRule) {
+	*out = *in
+	in.ProximityPlacementGroupRule.DeepCopyInto(&out.ProximityPlacementGroupRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProximityPlacementGroupRule.
+func (in *ProximityPlacementGroupRule) DeepCopy() *ProximityPlacementGroupRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ProximityPlacementGroupRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicIPPrefixRule) DeepCopyInto(out *PublicIPPrefixRule) {
+	*out = *in
+	in.PublicIPPrefixRule.DeepCopyInto(&out.PublicIPPrefixRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicIPPrefixRule.
+func (in *PublicIPPrefixRule) DeepCopy() *PublicIPPrefixRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicIPPrefixRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACCategory) DeepCopyInto(out *RBACCategory) {
+	*out = *in
+	if in.RBACRules != nil {
+		in, out := &in.RBACRules, &out.RBACRules
+		*out = make([]RBACRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TemplatePermissionRules != nil {
+		in, out := &in.TemplatePermissionRules, &out.TemplatePermissionRules
+		*out = make([]TemplatePermissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACCategory.
+func (in *RBACCategory) DeepCopy() *RBACCategory {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACCategory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACRule) DeepCopyInto(out *RBACRule) {
+	*out = *in
+	in.RBACRule.DeepCopyInto(&out.RBACRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACRule.
+func (in *RBACRule) DeepCopy() *RBACRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceLockRule) DeepCopyInto(out *ResourceLockRule) {
+	*out = *in
+	in.ResourceLockRule.DeepCopyInto(&out.ResourceLockRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceLockRule.
+func (in *ResourceLockRule) DeepCopy() *ResourceLockRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceLockRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteTableRule) DeepCopyInto(out *RouteTableRule) {
+	*out = *in
+	in.RouteTableRule.DeepCopyInto(&out.RouteTableRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteTableRule.
+func (in *RouteTableRule) DeepCopy() *RouteTableRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteTableRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleMeta) DeepCopyInto(out *RuleMeta) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleMeta.
+func (in *RuleMeta) DeepCopy() *RuleMeta {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleMeta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLServerRule) DeepCopyInto(out *SQLServerRule) {
+	*out = *in
+	in.SQLServerRule.DeepCopyInto(&out.SQLServerRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLServerRule.
+func (in *SQLServerRule) DeepCopy() *SQLServerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLServerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageCategory) DeepCopyInto(out *StorageCategory) {
+	*out = *in
+	if in.BlobContainerRules != nil {
+		in, out := &in.BlobContainerRules, &out.BlobContainerRules
+		*out = make([]BlobContainerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageNetworkRules != nil {
+		in, out := &in.StorageNetworkRules, &out.StorageNetworkRules
+		*out = make([]StorageNetworkRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FileShareRules != nil {
+		in, out := &in.FileShareRules, &out.FileShareRules
+		*out = make([]FileShareRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CosmosDBRules != nil {
+		in, out := &in.CosmosDBRules, &out.CosmosDBRules
+		*out = make([]CosmosDBRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SQLServerRules != nil {
+		in, out := &in.SQLServerRules, &out.SQLServerRules
+		*out = make([]SQLServerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EventHubRules != nil {
+		in, out := &in.EventHubRules, &out.EventHubRules
+		*out = make([]EventHubRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageCategory.
+func (in *StorageCategory) DeepCopy() *StorageCategory {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageCategory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageNetworkRule) DeepCopyInto(out *StorageNetworkRule) {
+	*out = *in
+	in.StorageNetworkRule.DeepCopyInto(&out.StorageNetworkRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageNetworkRule.
+func (in *StorageNetworkRule) DeepCopy() *StorageNetworkRule {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageNetworkRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetDelegationRule) DeepCopyInto(out *SubnetDelegationRule) {
+	*out = *in
+	in.SubnetDelegationRule.DeepCopyInto(&out.SubnetDelegationRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetDelegationRule.
+func (in *SubnetDelegationRule) DeepCopy() *SubnetDelegationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetDelegationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionRuleMeta) DeepCopyInto(out *SubscriptionRuleMeta) {
+	*out = *in
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionRuleMeta.
+func (in *SubscriptionRuleMeta) DeepCopy() *SubscriptionRuleMeta {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionRuleMeta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplatePermissionRule) DeepCopyInto(out *TemplatePermissionRule) {
+	*out = *in
+	in.TemplatePermissionRule.DeepCopyInto(&out.TemplatePermissionRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatePermissionRule.
+func (in *TemplatePermissionRule) DeepCopy() *TemplatePermissionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatePermissionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMSecurityRule) DeepCopyInto(out *VMSecurityRule) {
+	*out = *in
+	in.VMSecurityRule.DeepCopyInto(&out.VMSecurityRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMSecurityRule.
+func (in *VMSecurityRule) DeepCopy() *VMSecurityRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VMSecurityRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMSizeRule) DeepCopyInto(out *VMSizeRule) {
+	*out = *in
+	in.VMSizeRule.DeepCopyInto(&out.VMSizeRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMSizeRule.
+func (in *VMSizeRule) DeepCopy() *VMSizeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VMSizeRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VNetPeeringRule) DeepCopyInto(out *VNetPeeringRule) {
+	*out = *in
+	in.VNetPeeringRule.DeepCopyInto(&out.VNetPeeringRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VNetPeeringRule.
+func (in *VNetPeeringRule) DeepCopy() *VNetPeeringRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VNetPeeringRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNGatewayRule) DeepCopyInto(out *VPNGatewayRule) {
+	*out = *in
+	in.VPNGatewayRule.DeepCopyInto(&out.VPNGatewayRule)
+	out.SubscriptionRuleMeta = in.SubscriptionRuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNGatewayRule.
+func (in *VPNGatewayRule) DeepCopy() *VPNGatewayRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNGatewayRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneRedundancyRule) DeepCopyInto(out *ZoneRedundancyRule) {
+	*out = *in
+	in.ZoneRedundancyRule.DeepCopyInto(&out.ZoneRedundancyRule)
+	out.RuleMeta = in.RuleMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneRedundancyRule.
+func (in *ZoneRedundancyRule) DeepCopy() *ZoneRedundancyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneRedundancyRule)
+	in.DeepCopyInto(out)
+	return out
+}
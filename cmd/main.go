@@ -17,22 +17,32 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	validationv1alpha1 "github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	validationv1alpha2 "github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha2"
 	"github.com/spectrocloud-labs/validator-plugin-azure/internal/controller"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/lint"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/tracing"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	"github.com/spectrocloud-labs/validator-plugin-azure/pkg/schema"
 	validatorv1alpha1 "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
@@ -46,16 +56,81 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(validatorv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(validationv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(validationv1alpha2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(lint.Main(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		if _, err := os.Stdout.Write(schema.JSON()); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	var enableLeaderElection bool
 	var probeAddr string
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var maxConcurrentReconciles int
+	var azureAPIQPS float64
+	var azureAPIBurst int
+	var circuitBreakerThreshold int
+	var circuitBreakerWindow time.Duration
+	var circuitBreakerCooldown time.Duration
+	var activityLogPollInterval time.Duration
+	var remainingReadsWarningThreshold int
+	var enableWebhooks bool
+	var selfTest bool
+	var selfTestSubscriptions string
+	var allowedSecretNamespaces string
+	var workloadIdentityCheck bool
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The host:port of an OTLP/HTTP endpoint to export traces to. Tracing is disabled if empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Export traces over plain HTTP rather than HTTPS.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The maximum number of AzureValidators reconciled in parallel.")
+	flag.Float64Var(&azureAPIQPS, "azure-api-qps", 0,
+		"The maximum number of Azure API calls per second, across all AzureValidators. Unlimited if 0.")
+	flag.IntVar(&azureAPIBurst, "azure-api-burst", 10,
+		"The maximum burst of Azure API calls when --azure-api-qps is set.")
+	flag.IntVar(&circuitBreakerThreshold, "circuit-breaker-threshold", 5,
+		"The number of consecutive failed Azure API calls for a subscription, within --circuit-breaker-window, "+
+			"after which calls for the subscription are skipped for --circuit-breaker-cooldown. Disabled if 0.")
+	flag.DurationVar(&circuitBreakerWindow, "circuit-breaker-window", time.Minute,
+		"The window within which failed Azure API calls for a subscription count towards --circuit-breaker-threshold.")
+	flag.DurationVar(&circuitBreakerCooldown, "circuit-breaker-cooldown", 5*time.Minute,
+		"How long Azure API calls for a subscription are skipped once --circuit-breaker-threshold is reached.")
+	flag.DurationVar(&activityLogPollInterval, "activity-log-poll-interval", 0,
+		"How often to poll the Activity Log for role assignment changes, to re-validate the AzureValidators "+
+			"with spec.revalidateOnRoleAssignmentChanges that they affect right away. Disabled if 0.")
+	flag.IntVar(&remainingReadsWarningThreshold, "remaining-reads-warning-threshold", 1000,
+		"Log a warning when ARM reports fewer remaining read requests than this for a subscription during a reconcile. "+
+			"Disabled if 0.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the validating admission webhook for AzureValidators, which rejects invalid specs (see the lint subcommand), "+
+			"and the conversion webhook between v1alpha1 and v1alpha2. Requires a serving certificate in the webhook server's "+
+			"certificate directory.")
+	flag.BoolVar(&selfTest, "self-test", false,
+		"Check the deployment instead of starting the manager: that the AzureValidator CRD is installed, that the Azure "+
+			"credentials of the AzureValidators can acquire tokens, and that the plugin can read role assignments and role "+
+			"definitions in their subscriptions. Prints a report and exits with 0 if all checks pass, 1 otherwise.")
+	flag.StringVar(&selfTestSubscriptions, "self-test-subscriptions", "",
+		"A comma-separated list of subscription IDs that --self-test also checks, with the plugin's implicit credential.")
+	flag.StringVar(&allowedSecretNamespaces, "allowed-secret-namespaces", "",
+		"A comma-separated list of namespaces, besides their own, that AzureValidators may read their auth Secret from "+
+			"(spec.auth.secretNamespace). AzureValidators whose auth Secret is in any other namespace aren't evaluated. "+
+			"Only the plugin enforces this: its ClusterRole can still read Secrets in every namespace.")
+	flag.BoolVar(&workloadIdentityCheck, "workload-identity-check", false,
+		"Check that the plugin's projected service account token matches a federated identity credential of its workload "+
+			"identity (AZURE_CLIENT_ID), in an azure-workload-identity condition of each AzureValidator with implicit auth.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -64,11 +139,40 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if selfTest {
+		os.Exit(runSelfTest(splitList(selfTestSubscriptions), splitList(allowedSecretNamespaces)))
+	}
+
+	if otlpEndpoint != "" {
+		shutdown, err := tracing.Setup(context.Background(), otlpEndpoint, otlpInsecure)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				setupLog.Error(err, "failed to shut down tracing")
+			}
+		}()
+		setupLog.Info("tracing enabled", "endpoint", otlpEndpoint)
+	}
+
+	// The api-versions that ARM serves are recorded in each rule's condition details.
+	setupLog.Info("Azure SDK modules", "versions", azure_utils.SDKModuleVersions())
+
+	azure_utils.SetRateLimit(azureAPIQPS, azureAPIBurst)
+	azure_utils.SetCircuitBreaker(circuitBreakerThreshold, circuitBreakerWindow, circuitBreakerCooldown)
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "e3aa68a8.spectrocloud.labs",
+		// Auth Secrets are read directly rather than cached, so that their data isn't held in
+		// memory. Only their metadata is watched, to requeue the AzureValidators that refer to them.
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -86,14 +190,34 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The pod's workload identity is read before any AzureValidator's auth Secret is configured
+	// as environment variables, which may override it.
+	var workloadIdentity *azure_utils.WorkloadIdentity
+	if workloadIdentityCheck {
+		identity := azure_utils.WorkloadIdentityFromEnv()
+		workloadIdentity = &identity
+	}
+
 	if err = (&controller.AzureValidatorReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
-		Scheme: mgr.GetScheme(),
+		Client:                         mgr.GetClient(),
+		Log:                            ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+		Scheme:                         mgr.GetScheme(),
+		Recorder:                       mgr.GetEventRecorderFor("azurevalidator-controller"),
+		MaxConcurrentReconciles:        maxConcurrentReconciles,
+		ActivityLogPollInterval:        activityLogPollInterval,
+		RemainingReadsWarningThreshold: remainingReadsWarningThreshold,
+		AllowedSecretNamespaces:        splitList(allowedSecretNamespaces),
+		WorkloadIdentity:               workloadIdentity,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AzureValidator")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&validationv1alpha1.AzureValidator{}).SetupWebhookWithManager(mgr, splitList(allowedSecretNamespaces)); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AzureValidator")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -111,3 +235,34 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// runSelfTest runs the self-test against the cluster of the kubeconfig, printing its report, and
+// returns the exit code.
+func runSelfTest(subscriptionIDs, allowedSecretNamespaces []string) int {
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+	ok := controller.SelfTest(ctrl.SetupSignalHandler(), controller.SelfTestOptions{
+		Client:                  c,
+		Log:                     ctrl.Log.WithName("self-test"),
+		SubscriptionIDs:         subscriptionIDs,
+		AllowedSecretNamespaces: allowedSecretNamespaces,
+	}, os.Stdout)
+	if !ok {
+		return 1
+	}
+	return 0
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
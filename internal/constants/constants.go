@@ -3,5 +3,88 @@ package constants
 const (
 	PluginCode string = "Azure"
 
-	ValidationTypeRBAC string = "azure-rbac"
+	ValidationTypeRBAC                    string = "azure-rbac"
+	ValidationTypeKeyVaultCertificate     string = "azure-keyvault-certificate"
+	ValidationTypeAKSCluster              string = "azure-aks-cluster"
+	ValidationTypeNATGateway              string = "azure-nat-gateway"
+	ValidationTypeVNetPeering             string = "azure-vnet-peering"
+	ValidationTypeRouteTable              string = "azure-route-table"
+	ValidationTypeImageCompatibility      string = "azure-image-compatibility"
+	ValidationTypeImageReplication        string = "azure-image-replication"
+	ValidationTypeVMSecurity              string = "azure-vm-security"
+	ValidationTypeVMSize                  string = "azure-vm-size"
+	ValidationTypeDiskZone                string = "azure-disk-zone"
+	ValidationTypeTemplatePermission      string = "azure-template-permission"
+	ValidationTypePolicyExemption         string = "azure-policy-exemption"
+	ValidationTypeDefenderPlan            string = "azure-defender-plan"
+	ValidationTypeBudget                  string = "azure-budget"
+	ValidationTypeResourceLock            string = "azure-resource-lock"
+	ValidationTypeFirewallPolicy          string = "azure-firewall-policy"
+	ValidationTypeBastion                 string = "azure-bastion"
+	ValidationTypeDDoSProtection          string = "azure-ddos-protection"
+	ValidationTypePublicIPPrefix          string = "azure-public-ip-prefix"
+	ValidationTypeEventHub                string = "azure-event-hub"
+	ValidationTypeApplicationGateway      string = "azure-application-gateway"
+	ValidationTypeCosmosDB                string = "azure-cosmos-db"
+	ValidationTypeSQLServer               string = "azure-sql-server"
+	ValidationTypeGlobalEndpoint          string = "azure-global-endpoint"
+	ValidationTypeExpressRoute            string = "azure-expressroute"
+	ValidationTypeVPNGateway              string = "azure-vpn-gateway"
+	ValidationTypeNetworkWatcher          string = "azure-network-watcher"
+	ValidationTypeProximityPlacementGroup string = "azure-proximity-placement-group"
+	ValidationTypeEncryptionAtHost        string = "azure-encryption-at-host"
+	ValidationTypeGroupMembership         string = "azure-group-membership"
+	ValidationTypeAppPermission           string = "azure-app-permission"
+	ValidationTypeBlobContainer           string = "azure-blob-container"
+	ValidationTypeStorageNetwork          string = "azure-storage-network"
+	ValidationTypeFileShare               string = "azure-file-share"
+	ValidationTypeSubnetDelegation        string = "azure-subnet-delegation"
+	ValidationTypeMonitorAlert            string = "azure-monitor-alert"
+	ValidationTypeActivityLogExport       string = "azure-activity-log-export"
+	ValidationTypeImageDeprecation        string = "azure-image-deprecation"
+	ValidationTypeZoneRedundancy          string = "azure-zone-redundancy"
+	ValidationTypeCustomLocation          string = "azure-custom-location"
+	ValidationTypeKeyVaultRecovery        string = "azure-keyvault-recovery"
+	ValidationTypePreflight               string = "azure-preflight"
+	ValidationTypeWorkloadIdentity        string = "azure-workload-identity"
+	ValidationTypeSpecLoad                string = "azure-spec-load"
+	ValidationTypeSpec                    string = "azure-spec"
+
+	// PreflightRuleName is the rule name of the preflight check's condition, which checks that the
+	// plugin itself can read role assignments and role definitions.
+	PreflightRuleName string = "azure-preflight"
+	// WorkloadIdentityRuleName is the rule name of the condition that checks the plugin's own
+	// service account token against the federated identity credentials of its workload identity.
+	WorkloadIdentityRuleName string = "azure-workload-identity"
+	// SpecLoadRuleName is the rule name of the condition that reports whether the rules in an
+	// AzureValidator's rulesFrom ConfigMaps could be loaded.
+	SpecLoadRuleName string = "azure-spec-load"
+	// SpecRuleName is the rule name of the condition that lists the problems with the specs of
+	// rules that keep them from being evaluated.
+	SpecRuleName string = "azure-spec"
+
+	// PausedAnnotation is the annotation that, when set to "true" on an AzureValidator, stops its
+	// rules from being evaluated until it is removed or set to any other value.
+	PausedAnnotation string = "validation.spectrocloud.labs/paused"
+
+	// DryRunAnnotation is the annotation that, when set to "true" on an AzureValidator, evaluates its
+	// rules without writing their results to its ValidationResult. The outcome is recorded in the
+	// AzureValidator's status and in an event instead.
+	DryRunAnnotation string = "validation.spectrocloud.labs/dry-run"
+
+	// LastValidatedAnnotation is the annotation that records when an AzureValidator's rules were
+	// last evaluated on its ValidationResult, in RFC 3339.
+	LastValidatedAnnotation string = "validation.spectrocloud.labs/last-validated-time"
+
+	// ConditionTypePaused is the type of the AzureValidator status condition that records whether
+	// validation is paused, and since when.
+	ConditionTypePaused string = "Paused"
+
+	// ConditionTypeSecretNamespaceAllowed is the type of the AzureValidator status condition that
+	// records whether the plugin may read the AzureValidator's auth Secret from another namespace.
+	ConditionTypeSecretNamespaceAllowed string = "SecretNamespaceAllowed"
+
+	// ValidationResultFinalizer is the finalizer that keeps an AzureValidator from being removed
+	// until its ValidationResult has been deleted.
+	ValidationResultFinalizer string = "validation.spectrocloud.labs/validationresult-cleanup"
 )
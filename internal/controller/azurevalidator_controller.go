@@ -20,24 +20,43 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha2"
 	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/tracing"
 	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
 	"github.com/spectrocloud-labs/validator-plugin-azure/internal/validators"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
 	"github.com/spectrocloud-labs/validator/pkg/types"
 	"github.com/spectrocloud-labs/validator/pkg/util"
 	vres "github.com/spectrocloud-labs/validator/pkg/validationresult"
@@ -45,22 +64,102 @@ import (
 
 var ErrSecretNameRequired = errors.New("auth.secretName is required")
 
+// Span attribute keys used for reconciles and rules.
+const (
+	attrValidatorName      = attribute.Key("validator.name")
+	attrValidatorNamespace = attribute.Key("validator.namespace")
+	attrRuleName           = attribute.Key("validator.rule.name")
+	attrValidationType     = attribute.Key("validator.validation_type")
+)
+
+// tracedEval evaluates a rule in its own span, named spanName and with the rule's name and
+// validation type as attributes, recording the error eval returns, if any. eval gets the span's
+// context, so that the Azure calls it makes are traced as children of the span.
+func tracedEval(ctx context.Context, spanName, validationType, ruleName string, eval func(ctx context.Context) (*types.ValidationRuleResult, error)) (*types.ValidationRuleResult, error) {
+	ctx, span := tracing.Start(ctx, spanName)
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(attrRuleName.String(ruleName), attrValidationType.String(validationType))
+	}
+
+	vrr, err := eval(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return vrr, err
+}
+
 // AzureValidatorReconciler reconciles an AzureValidator object
 type AzureValidatorReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// MaxConcurrentReconciles is the maximum number of AzureValidators reconciled in parallel.
+	// Defaults to 1.
+	MaxConcurrentReconciles int
+	// ActivityLogPollInterval is how often the Activity Log is polled for role assignment changes
+	// that affect AzureValidators with spec.revalidateOnRoleAssignmentChanges. Polling is disabled
+	// if 0.
+	ActivityLogPollInterval time.Duration
+	// Azure configures the Azure service clients that rules are evaluated with. The zero value
+	// creates clients for the Azure public cloud with a DefaultAzureCredential. Setting its
+	// Transport substitutes what serves every client's requests, e.g. a fake of ARM in tests, or
+	// an offline inventory export instead of live ARM.
+	Azure azure_utils.ClientFactoryOptions
+	// RemainingReadsWarningThreshold is how few remaining read requests ARM may report for a
+	// subscription during a reconcile before a warning is logged, as the plugin is then about to
+	// be throttled. Disabled if 0.
+	RemainingReadsWarningThreshold int
+	// AllowedSecretNamespaces are the namespaces, besides their own, that AzureValidators may read
+	// their auth Secret from. Evaluation of AzureValidators whose auth Secret is in any other
+	// namespace is refused.
+	AllowedSecretNamespaces []string
+	// WorkloadIdentity is the plugin's own workload identity configuration. If set, the plugin's
+	// service account token is checked against the identity's federated identity credentials, in
+	// a condition of each AzureValidator that uses the implicit credential.
+	WorkloadIdentity *azure_utils.WorkloadIdentity
+
+	// clientFactory creates the Azure service clients, with Azure, on first use.
+	clientFactory     *azure_utils.ClientFactory
+	clientFactoryOnce sync.Once
+	// clock is used to time rule evaluations and to check how long certificates remain valid. If
+	// nil, the real clock is used.
+	clock clock.PassiveClock
+
+	azureEnvMu sync.Mutex
+
+	// revalidating holds the AzureValidators whose next reconcile must re-evaluate their rules that
+	// depend on role assignments, because a role assignment change affected them.
+	revalidating   map[ktypes.NamespacedName]bool
+	revalidatingMu sync.Mutex
 }
 
 //+kubebuilder:rbac:groups=validation.spectrocloud.labs,resources=azurevalidators,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=validation.spectrocloud.labs,resources=azurevalidators/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=validation.spectrocloud.labs,resources=azurevalidators/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
 
 // Reconcile reconciles each rule found in each AzureValidator in the cluster and creates ValidationResults accordingly
 func (r *AzureValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := r.Log.V(0).WithValues("name", req.Name, "namespace", req.Namespace)
+	if ctxLog, err := logr.FromContext(ctx); err == nil {
+		// The logger controller-runtime provides already identifies the AzureValidator, and adds a
+		// reconcileID that correlates all logs of the reconcile.
+		l = ctxLog
+	}
 	l.Info("Reconciling AzureValidator")
 
+	ctx, span := tracing.Start(ctx, "Reconcile")
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(attrValidatorName.String(req.Name), attrValidatorNamespace.String(req.Namespace))
+	}
+
 	validator := &v1alpha1.AzureValidator{}
 	if err := r.Get(ctx, req.NamespacedName, validator); err != nil {
 		if !apierrs.IsNotFound(err) {
@@ -69,17 +168,46 @@ func (r *AzureValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Configure Azure environment variable credentials from a secret, if applicable
-	if !validator.Spec.Auth.Implicit {
-		if validator.Spec.Auth.SecretName == "" {
-			l.Error(ErrSecretNameRequired, "failed to reconcile AzureValidator with empty auth.secretName")
-			return ctrl.Result{}, ErrSecretNameRequired
-		}
-		if err := r.envFromSecret(validator.Spec.Auth.SecretName, req.Namespace); err != nil {
-			l.Error(err, "failed to configure environment from secret")
-			return ctrl.Result{}, err
-		}
+	if !validator.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.reconcileDelete(ctx, l, validator)
+	}
+	if err := r.ensureFinalizer(ctx, validator); err != nil {
+		l.Error(err, "failed to add finalizer")
+		return ctrl.Result{}, err
+	}
+
+	// Skip validation entirely while paused. We don't requeue, because removing the annotation
+	// updates the AzureValidator, which triggers a new reconcile right away.
+	paused := isPaused(validator)
+	if err := r.updatePausedCondition(ctx, validator, paused); err != nil {
+		l.Error(err, "failed to update Paused condition")
+		return ctrl.Result{}, err
+	}
+	if paused {
+		l.Info("AzureValidator is paused. Skipping validation.", "annotation", constants.PausedAnnotation)
+		return ctrl.Result{}, nil
+	}
+
+	if !validator.Spec.Auth.Implicit && validator.Spec.Auth.SecretName == "" {
+		l.Error(ErrSecretNameRequired, "failed to reconcile AzureValidator with empty auth.secretName")
+		return ctrl.Result{}, ErrSecretNameRequired
 	}
+	allowed := r.secretNamespaceAllowed(validator)
+	if err := r.updateSecretNamespaceCondition(ctx, validator, allowed); err != nil {
+		l.Error(err, "failed to update SecretNamespaceAllowed condition")
+		return ctrl.Result{}, err
+	}
+	if !allowed {
+		// The AzureValidator is reconciled again once its spec or the plugin's flags change.
+		l.Error(ErrSecretNamespaceNotAllowed, "Refusing to read auth Secret. Skipping validation.", "secretNamespace", validator.AuthSecretNamespace(), "allowedSecretNamespaces", r.AllowedSecretNamespaces)
+		r.recordSecretNamespaceNotAllowed(validator)
+		return ctrl.Result{}, nil
+	}
+
+	// Merge the rules of the rulesFrom ConfigMaps into the spec. Only this reconcile sees them;
+	// nothing is written back to the AzureValidator's spec.
+	loadStart := r.now()
+	validator, specLoad := r.loadRulesFrom(ctx, l, validator)
 
 	// Get the active validator's validation result
 	vr := &vapi.ValidationResult{}
@@ -88,31 +216,111 @@ func (r *AzureValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		l.Error(err, "failed to create patch helper")
 		return ctrl.Result{}, err
 	}
-	nn := ktypes.NamespacedName{
-		Name:      validationResultName(validator),
-		Namespace: req.Namespace,
+	err = r.Get(ctx, validationResultKey(validator), vr)
+	if err != nil && !apierrs.IsNotFound(err) {
+		l.Error(err, "unexpected error getting ValidationResult")
 	}
-	if err := r.Get(ctx, nn, vr); err == nil {
-		vres.HandleExistingValidationResult(vr, r.Log)
-	} else {
-		if !apierrs.IsNotFound(err) {
-			l.Error(err, "unexpected error getting ValidationResult")
+	var existing *vapi.ValidationResult
+	if err == nil {
+		existing = vr
+	}
+
+	// Results that can't be written are lost, so don't spend ARM requests evaluating rules until
+	// the plugin can write the ValidationResult, e.g. once its RBAC in the cluster is fixed. In
+	// dry-run mode, nothing is written to the ValidationResult, which isn't even created.
+	dryRun := isDryRun(validator)
+	if !dryRun {
+		if err := r.checkResultWritable(ctx, validator, existing); err != nil {
+			l.Error(err, "ValidationResult isn't writable. Skipping validation.", "after", unwritableRequeueAfter)
+			r.recordResultNotWritable(validator, err)
+			return ctrl.Result{RequeueAfter: unwritableRequeueAfter}, nil
 		}
+	}
+
+	var prev *vapi.ValidationResult
+	switch {
+	case existing != nil:
+		prev = vr.DeepCopy()
+		vres.HandleExistingValidationResult(vr, r.Log)
+	case dryRun:
+		vr = buildValidationResult(validator)
+	default:
 		if err := vres.HandleNewValidationResult(ctx, r.Client, p, buildValidationResult(validator), r.Log); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{RequeueAfter: time.Millisecond}, nil
 	}
 
+	// Template permission rules are checked for spec problems, and evaluated, with their templates
+	// resolved.
+	templateRules := make([]resolvedTemplateRule, 0, len(validator.Spec.TemplatePermissionRules))
+	for _, rule := range validator.Spec.TemplatePermissionRules {
+		resolved, err := r.resolveTemplate(ctx, req.Namespace, rule)
+		templateRules = append(templateRules, resolvedTemplateRule{rule: resolved, err: err})
+	}
+
+	// Problems with the specs of rules fail the rules without evaluating them, and are listed by
+	// the spec condition. Once reported, the spec condition is kept, so that it passes once the
+	// problems have been fixed.
+	specStart := r.now()
+	specProblems := ruleSpecProblems(validator, templateRules)
+	reportSpec := len(specProblems) > 0 || (existing != nil && hasValidationType(existing, constants.ValidationTypeSpec))
+
 	// Always update the expected result count in case the validator's rules have changed
 	vr.Spec.ExpectedResults = validator.Spec.ResultCount()
+	if reportSpec {
+		vr.Spec.ExpectedResults++
+	}
+	checkWorkloadIdentity := r.WorkloadIdentity != nil && validator.Spec.Auth.Implicit
+	if checkWorkloadIdentity {
+		vr.Spec.ExpectedResults++
+	}
 
 	resp := types.ValidationResponse{
 		ValidationRuleResults: make([]*types.ValidationRuleResult, 0, vr.Spec.ExpectedResults),
 		ValidationRuleErrors:  make([]error, 0, vr.Spec.ExpectedResults),
 	}
 
-	azureAPI, err := azure_utils.NewAzureAPI()
+	outcomes := make([]ruleOutcome, 0, vr.Spec.ExpectedResults)
+
+	// The spec-load condition, which doesn't depend on Azure, so it's reported even if the Azure
+	// API can't be created.
+	if specLoad != nil {
+		setLabelDetails(specLoad.Condition, ruleLabels(validator.Spec.ResultLabels, nil))
+		setSeverityDetail(specLoad.Condition, v1alpha1.SeverityHigh)
+		resp.AddResult(specLoad, nil)
+		outcome := newRuleOutcome(constants.SpecLoadRuleName, constants.ValidationTypeSpecLoad, specLoad, nil, loadStart, r.now())
+		outcome.generation = validator.Generation
+		outcomes = append(outcomes, outcome)
+	}
+	if reportSpec {
+		spec := validators.SpecResult(specProblems)
+		setLabelDetails(spec.Condition, ruleLabels(validator.Spec.ResultLabels, nil))
+		setSeverityDetail(spec.Condition, v1alpha1.SeverityHigh)
+		resp.AddResult(spec, nil)
+		outcome := newRuleOutcome(constants.SpecRuleName, constants.ValidationTypeSpec, spec, nil, specStart, r.now())
+		outcome.generation = validator.Generation
+		outcomes = append(outcomes, outcome)
+	}
+
+	// Configure Azure environment variable credentials from a secret, if applicable. The Azure SDK
+	// reads them when the credential is created, and they're shared by all concurrent reconciles,
+	// so configuring them and getting the Azure API must happen under the same lock. Credentials
+	// are cached by the secret they're configured from, until the secret changes.
+	r.azureEnvMu.Lock()
+	credentialVersion := ""
+	if !validator.Spec.Auth.Implicit {
+		secret, err := r.envFromSecret(validator.Spec.Auth.SecretName, validator.AuthSecretNamespace())
+		if err != nil {
+			r.azureEnvMu.Unlock()
+			l.Error(err, "failed to configure environment from secret")
+			return ctrl.Result{}, err
+		}
+		credentialVersion = secret.ResourceVersion
+	}
+	azureAPI, err := r.azureClients().API(credentialID(validator), credentialVersion)
+	r.azureEnvMu.Unlock()
+	var apiRequests *azure_utils.RequestCounts
 	if err != nil {
 		l.Error(err, "failed to create Azure API object")
 	} else {
@@ -122,79 +330,1577 @@ func (r *AzureValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			azureCtx, cancel = context.WithDeadline(ctx, time.Now().Add(azure_utils.TestClientTimeout))
 			defer cancel()
 		}
+		azureCtx, apiRequests = azure_utils.WithRequestCounts(azureCtx)
+		// If the spec asks for snapshots, the responses to the rules' requests are recorded, for
+		// the snapshot that's written once they've been evaluated.
+		var snapshot *azure_utils.Snapshot
+		if validator.Spec.Snapshot != nil {
+			azureCtx, snapshot = azure_utils.WithSnapshot(azureCtx, validator.Spec.Snapshot.ByteLimit())
+		}
+		// ARM requests go to the spec's regional ARM endpoint, if any, which logs with l when it
+		// falls back to the global endpoint or measures their latency.
+		azureCtx = azure_utils.WithARMRegion(logr.NewContext(azureCtx, l), validator.Spec.ARMRegion)
 
-		daClient := azure_utils.NewAzureDenyAssignmentsClient(azureCtx, azureAPI.DenyAssignments)
-		raClient := azure_utils.NewAzureRoleAssignmentsClient(azureCtx, azureAPI.RoleAssignments)
-		rdClient := azure_utils.NewAzureRoleDefinitionsClient(azureCtx, azureAPI.RoleDefinitions)
+		// The severities of rules that were created or updated as v1alpha2. If they can't be
+		// read, rules have the spec's defaultSeverity.
+		severities, err := v1alpha2.RuleSeverities(validator.ObjectMeta)
+		if err != nil {
+			l.Error(err, "failed to read rule severities")
+		}
+
+		// queue queues a rule to be evaluated with eval, once all rules have been queued. Since eval
+		// runs after the loop that queued it, each loop below copies its rule for eval to capture.
+		var evals []ruleEvaluation
+		queue := func(name, validationType string, labels map[string]string, rule any, eval func() (*types.ValidationRuleResult, error)) {
+			evals = append(evals, ruleEvaluation{
+				name:           name,
+				validationType: validationType,
+				labels:         labels,
+				severity:       ruleSeverity(validator, severities, name, validationType),
+				hash:           hashRule(rule),
+				eval:           eval,
+			})
+		}
+
+		// reuse reuses a rule's previous result, if it can be reused. Results of rules that depend
+		// on role assignments aren't reused after a role assignment change triggered the reconcile.
+		// evaluate evaluates a rule. Either way, the result's condition is labeled with the rule's
+		// labels merged into the spec's, and with its severity.
+		revalidating := r.takeRevalidation(req.NamespacedName)
+		reuse := func(e ruleEvaluation) (ruleRun, bool) {
+			if revalidating && dependsOnRoleAssignments(e.validationType) {
+				l.Info("Re-evaluating rule after a role assignment change", "ruleName", e.name, "validationType", e.validationType)
+				return ruleRun{}, false
+			}
+			vrr, prev := r.reusableResult(validator, vr, e.name, e.validationType, e.hash)
+			if vrr == nil {
+				return ruleRun{}, false
+			}
+			l.Info("Reusing previous result of rule", "ruleName", e.name, "validationType", e.validationType, "lastEvaluationTime", prev.LastEvaluationTime)
+			setLabelDetails(vrr.Condition, ruleLabels(validator.Spec.ResultLabels, e.labels))
+			setSeverityDetail(vrr.Condition, e.severity)
+			return ruleRun{vrr: vrr, outcome: reusedRuleOutcome(prev)}, true
+		}
+		evaluated := false
+		evaluate := func(e ruleEvaluation) ruleRun {
+			evaluated = true
+			if snapshot != nil {
+				snapshot.StartRule(e.name, e.validationType)
+			}
+			start := r.now()
+			vrr, err := e.eval()
+			// A rule that failed before building its condition, e.g. because its clients couldn't be
+			// created, still fails its own condition, rather than an unnamed one.
+			if vrr == nil && err != nil {
+				vrr = erroredRuleResult(e.name, e.validationType)
+			}
+			if vrr != nil && vrr.Condition != nil {
+				validators.FinalizeFailures(vrr.Condition)
+				setLabelDetails(vrr.Condition, ruleLabels(validator.Spec.ResultLabels, e.labels))
+				setSeverityDetail(vrr.Condition, e.severity)
+			}
+			outcome := newRuleOutcome(e.name, e.validationType, vrr, err, start, r.now())
+			outcome.generation = validator.Generation
+			if err == nil {
+				outcome.hash = e.hash
+			}
+			if outcome.state == vapi.ValidationInProgress {
+				outcome.pendingUntil = e.graceEnd
+			}
+			return ruleRun{vrr: vrr, err: err, outcome: outcome}
+		}
+
+		// The workload identity check, which diagnoses the implicit credential before any rule
+		// authenticates with it.
+		if checkWorkloadIdentity {
+			queue(constants.WorkloadIdentityRuleName, constants.ValidationTypeWorkloadIdentity, nil, *r.WorkloadIdentity, func() (*types.ValidationRuleResult, error) {
+				return reconcileWorkloadIdentity(azureCtx, l, azureAPI, *r.WorkloadIdentity)
+			})
+		}
+
+		// The preflight check, which runs before the rules whose permission checks it covers.
+		if scopes := validator.Spec.PreflightScopes(); len(scopes) > 0 {
+			queue(constants.PreflightRuleName, constants.ValidationTypePreflight, nil, scopes, func() (*types.ValidationRuleResult, error) {
+				return reconcilePreflight(azureCtx, l, azureAPI, scopes)
+			})
+		}
 
-		// RBAC rules
-		svc := validators.NewRBACRuleService(daClient, raClient, rdClient)
+		// RBAC rules. Subscriptions' role assignments are counted at most once per reconcile, no
+		// matter how many rules check their quota. Rules pending within their grace period record
+		// when it ends. A rule's ARM region overrides the spec's.
+		raCounts := validators.NewRoleAssignmentCounts()
 		for _, rule := range validator.Spec.RBACRules {
-			vrr, err := svc.ReconcileRBACRule(rule)
+			rule := rule
+			graceEnd := gracePeriodEnd(validator, rule, r.now())
+			queue(rule.Name, constants.ValidationTypeRBAC, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileRBACRule(azure_utils.WithARMRegion(azureCtx, rule.ARMRegion), l, azureAPI, raCounts, r.passiveClock(), graceEnd, rule)
+			})
+			evals[len(evals)-1].graceEnd = graceEnd
+		}
+
+		// Key Vault certificate rules
+		for _, rule := range validator.Spec.KeyVaultCertificateRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeKeyVaultCertificate, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileKeyVaultCertificateRule(azureCtx, l, azureAPI, r.passiveClock(), rule)
+			})
+		}
+
+		// AKS cluster rules
+		for _, rule := range validator.Spec.AKSClusterRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeAKSCluster, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileAKSClusterRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// NAT gateway rules
+		for _, rule := range validator.Spec.NATGatewayRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeNATGateway, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileNATGatewayRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// VNet peering rules
+		for _, rule := range validator.Spec.VNetPeeringRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeVNetPeering, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileVNetPeeringRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Route table rules
+		for _, rule := range validator.Spec.RouteTableRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeRouteTable, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileRouteTableRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Image compatibility rules
+		for _, rule := range validator.Spec.ImageCompatibilityRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeImageCompatibility, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileImageCompatibilityRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Image replication rules
+		for _, rule := range validator.Spec.ImageReplicationRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeImageReplication, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileImageReplicationRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// VM security rules
+		for _, rule := range validator.Spec.VMSecurityRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeVMSecurity, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileVMSecurityRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// VM size rules
+		for _, rule := range validator.Spec.VMSizeRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeVMSize, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileVMSizeRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Disk zone rules
+		for _, rule := range validator.Spec.DiskZoneRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeDiskZone, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileDiskZoneRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Template permission rules. The rule is evaluated, and hashed, with its template resolved,
+		// so that editing a ConfigMap with a template invalidates the rule's previous result. A rule
+		// whose template can't be resolved fails its own condition with the error.
+		for _, tr := range templateRules {
+			tr, rule := tr, tr.rule
+			queue(rule.Name, constants.ValidationTypeTemplatePermission, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				if tr.err != nil {
+					return erroredRuleResult(rule.Name, constants.ValidationTypeTemplatePermission), tr.err
+				}
+				return reconcileTemplatePermissionRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Policy exemption rules
+		for _, rule := range validator.Spec.PolicyExemptionRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypePolicyExemption, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcilePolicyExemptionRule(azureCtx, l, azureAPI, r.passiveClock(), rule)
+			})
+		}
+
+		// Defender plan rules
+		for _, rule := range validator.Spec.DefenderPlanRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeDefenderPlan, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileDefenderPlanRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Budget rules
+		for _, rule := range validator.Spec.BudgetRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeBudget, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileBudgetRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Resource lock rules
+		for _, rule := range validator.Spec.ResourceLockRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeResourceLock, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileResourceLockRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Firewall policy rules
+		for _, rule := range validator.Spec.FirewallPolicyRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeFirewallPolicy, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileFirewallPolicyRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Bastion rules
+		for _, rule := range validator.Spec.BastionRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeBastion, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileBastionRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// DDoS protection rules
+		for _, rule := range validator.Spec.DDoSProtectionRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeDDoSProtection, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileDDoSProtectionRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Public IP prefix rules
+		for _, rule := range validator.Spec.PublicIPPrefixRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypePublicIPPrefix, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcilePublicIPPrefixRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Event hub rules
+		for _, rule := range validator.Spec.EventHubRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeEventHub, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileEventHubRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Application gateway rules
+		for _, rule := range validator.Spec.ApplicationGatewayRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeApplicationGateway, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileApplicationGatewayRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Cosmos DB rules
+		for _, rule := range validator.Spec.CosmosDBRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeCosmosDB, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileCosmosDBRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// SQL server rules
+		for _, rule := range validator.Spec.SQLServerRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeSQLServer, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileSQLServerRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Global endpoint rules
+		for _, rule := range validator.Spec.GlobalEndpointRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeGlobalEndpoint, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileGlobalEndpointRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// ExpressRoute rules
+		for _, rule := range validator.Spec.ExpressRouteRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeExpressRoute, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileExpressRouteRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// VPN gateway rules
+		for _, rule := range validator.Spec.VPNGatewayRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeVPNGateway, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileVPNGatewayRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Network watcher rules
+		for _, rule := range validator.Spec.NetworkWatcherRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeNetworkWatcher, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileNetworkWatcherRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Proximity placement group rules
+		for _, rule := range validator.Spec.ProximityPlacementGroupRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeProximityPlacementGroup, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileProximityPlacementGroupRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Encryption at host rules
+		for _, rule := range validator.Spec.EncryptionAtHostRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeEncryptionAtHost, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileEncryptionAtHostRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Group membership rules
+		for _, rule := range validator.Spec.GroupMembershipRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeGroupMembership, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileGroupMembershipRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// App permission rules
+		for _, rule := range validator.Spec.AppPermissionRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeAppPermission, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileAppPermissionRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Blob container rules
+		for _, rule := range validator.Spec.BlobContainerRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeBlobContainer, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileBlobContainerRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Storage network rules
+		for _, rule := range validator.Spec.StorageNetworkRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeStorageNetwork, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileStorageNetworkRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// File share rules
+		for _, rule := range validator.Spec.FileShareRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeFileShare, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileFileShareRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Subnet delegation rules
+		for _, rule := range validator.Spec.SubnetDelegationRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeSubnetDelegation, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileSubnetDelegationRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Monitor alert rules
+		for _, rule := range validator.Spec.MonitorAlertRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeMonitorAlert, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileMonitorAlertRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Activity Log export rules
+		for _, rule := range validator.Spec.ActivityLogExportRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeActivityLogExport, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileActivityLogExportRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Image deprecation rules
+		for _, rule := range validator.Spec.ImageDeprecationRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeImageDeprecation, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileImageDeprecationRule(azureCtx, l, azureAPI, r.passiveClock(), rule)
+			})
+		}
+
+		// Zone redundancy rules
+		for _, rule := range validator.Spec.ZoneRedundancyRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeZoneRedundancy, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileZoneRedundancyRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Custom location rules
+		for _, rule := range validator.Spec.CustomLocationRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeCustomLocation, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileCustomLocationRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Key Vault recovery rules
+		for _, rule := range validator.Spec.KeyVaultRecoveryRules {
+			rule := rule
+			queue(rule.Name, constants.ValidationTypeKeyVaultRecovery, rule.Labels, rule, func() (*types.ValidationRuleResult, error) {
+				return reconcileKeyVaultRecoveryRule(azureCtx, l, azureAPI, rule)
+			})
+		}
+
+		// Evaluate the rules, until the run deadline, if any, elapses. Their results are added in
+		// the order of the spec's rules, whatever order they were evaluated in.
+		for i, run := range runRules(evals, validator.Status.RuleResults, runDeadline(validator), r.passiveClock(), reuse, evaluate) {
+			if run.outcome.notEvaluated {
+				l.Info("Rule wasn't evaluated before the run deadline", "ruleName", run.outcome.name, "validationType", run.outcome.validationType, "runDeadline", validator.Spec.RunDeadline.Duration)
+				setLabelDetails(run.vrr.Condition, ruleLabels(validator.Spec.ResultLabels, evals[i].labels))
+				setSeverityDetail(run.vrr.Condition, evals[i].severity)
+				run.outcome.generation = validator.Generation
+			}
+			resp.AddResult(run.vrr, run.err)
+			outcomes = append(outcomes, run.outcome)
+		}
+
+		// Write the snapshot of what the evaluated rules observed. There's none if all results
+		// were reused.
+		if snapshot != nil && evaluated {
+			r.writeSnapshot(ctx, l, validator, azureAPI, snapshot)
+		}
+	}
+
+	// Patch the ValidationResult with the latest ValidationRuleResults, in the order of the spec's
+	// rules, and undo any edits to its result labels. Its state only fails if a rule that isn't a
+	// warning failed. It isn't patched if nothing changed. In
+	// dry-run mode, what it would be is recorded in the AzureValidator's status instead, and the
+	// rule results are left as they were last written to the ValidationResult.
+	orderConditions(vr, resp.ValidationRuleResults)
+	applyResultLabels(vr, validator)
+	if dryRun {
+		if err := vres.SafeUpdateValidationResult(ctx, severityPatcher{p: dryRunPatcher{}}, vr, resp, r.Log); err != nil {
+			return ctrl.Result{}, err
+		}
+		l.Info("Dry run: recorded the outcome in the AzureValidator's status instead of the ValidationResult.", "annotation", constants.DryRunAnnotation, "state", vr.Status.State)
+		if err := r.recordDryRun(ctx, validator, vr); err != nil {
+			l.Error(err, "failed to record dry-run outcome")
+			return ctrl.Result{}, err
+		}
+	} else {
+		rp := &resultPatcher{p: p, prev: prev, now: r.now()}
+		if err := vres.SafeUpdateValidationResult(ctx, severityPatcher{p: rp}, vr, resp, r.Log); err != nil {
+			return ctrl.Result{}, err
+		}
+		if rp.skipped {
+			l.V(1).Info("ValidationResult is unchanged. Skipped patching it.")
+		}
+	}
+
+	// The rules weren't evaluated if the Azure API couldn't be created, so keep the previous results.
+	if azureAPI != nil && !dryRun {
+		if remaining, ok := apiRequests.RemainingReads(); ok && remaining < r.RemainingReadsWarningThreshold {
+			l.Info("ARM is close to throttling the plugin's read requests", "remainingReads", remaining, "threshold", r.RemainingReadsWarningThreshold)
+		}
+		if err := r.updateRuleResults(ctx, validator, outcomes, recordAPIRequests(validator, apiRequests)); err != nil {
+			l.Error(err, "failed to update rule results")
+			return ctrl.Result{}, err
+		}
+	}
+
+	requeueAfter, requeue := requeueAfterErrors(resp.ValidationRuleErrors, r.now())
+	if after, ok := requeueAfterPending(outcomes, r.now()); ok && (!requeue || after < requeueAfter) {
+		requeueAfter, requeue = after, true
+	}
+	if after, ok := requeueAfterNotEvaluated(outcomes); ok && (!requeue || after < requeueAfter) {
+		requeueAfter, requeue = after, true
+	}
+	if !requeue {
+		l.Info("Not requeuing for re-validation, because all rules failed with invalid requests. Re-validation will occur once the spec changes.")
+		return ctrl.Result{}, nil
+	}
+	l.Info("Requeuing for re-validation.", "after", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+const (
+	// defaultRequeueAfter is how long to wait before re-validating rules that didn't fail with an
+	// error.
+	defaultRequeueAfter = 2 * time.Minute
+	// transientRequeueAfter is how long to wait before re-validating rules that failed with
+	// transient errors.
+	transientRequeueAfter = azure_errors.TransientRequeueAfter
+	// throttledRequeueAfter is how long to wait before re-validating rules that failed because ARM
+	// throttled the plugin, if ARM didn't say when to retry.
+	throttledRequeueAfter = azure_errors.ThrottledRequeueAfter
+	// forbiddenRequeueAfter is how long to wait before re-validating rules that failed because the
+	// plugin lacks permissions or the scope doesn't exist, which is unlikely to change soon.
+	forbiddenRequeueAfter = 10 * time.Minute
+	// unwritableRequeueAfter is how long to wait before checking again whether an AzureValidator's
+	// ValidationResult can be written, when it couldn't be. The check doesn't use ARM.
+	unwritableRequeueAfter = 30 * time.Second
+)
+
+// requeueAfterErrors determines how long to wait before re-validating, given the errors (nil for
+// success) that each rule's evaluation resulted in. The soonest re-validation needed by any rule
+// wins. Errors with a requeue hint (azure_errors.RequeueHinter), e.g. throttled errors, which are
+// hinted with ARM's Retry-After, are re-validated as they hint; the others as their category
+// implies. Rules that failed with terminal errors, e.g. invalid requests, don't need to be
+// re-validated until the spec changes, so if all rules did, it returns false. Rules skipped by the
+// circuit breaker are re-validated once it allows requests again.
+func requeueAfterErrors(errs []error, now time.Time) (time.Duration, bool) {
+	if len(errs) == 0 {
+		return defaultRequeueAfter, true
+	}
+
+	var requeueAfter time.Duration
+	requeue := false
+	for _, err := range errs {
+		after, ok := requeueAfterError(err, now)
+		if !ok {
+			continue
+		}
+		if !requeue || after < requeueAfter {
+			requeueAfter = after
+		}
+		requeue = true
+	}
+	return requeueAfter, requeue
+}
+
+// requeueAfterError determines how long to wait before re-validating a rule whose evaluation
+// resulted in err (nil for success), or false if it doesn't need to be re-validated until the spec
+// changes. Errors without a requeue hint get the hint their category implies.
+func requeueAfterError(err error, now time.Time) (time.Duration, bool) {
+	if err == nil {
+		return defaultRequeueAfter, true
+	}
+	if cerr, ok := azure_errors.CircuitOpen(err); ok {
+		return max(cerr.RetryAt.Sub(now), transientRequeueAfter), true
+	}
+	var hinter azure_errors.RequeueHinter
+	if errors.As(azure_errors.WithRequeueHint(err), &hinter) {
+		after := hinter.RequeueAfter()
+		return after, after > 0
+	}
+	switch azure_errors.Classify(err) {
+	case azure_errors.CategoryForbidden, azure_errors.CategoryNotFound:
+		return forbiddenRequeueAfter, true
+	}
+	return defaultRequeueAfter, true
+}
+
+// reconcilePreflight checks, in its own span, that the plugin can read role assignments and role
+// definitions at the scopes of the RBAC and template permission rules.
+func reconcilePreflight(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, scopes []string) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcilePreflight", constants.ValidationTypePreflight, constants.PreflightRuleName, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		raClient, err := azureAPI.RoleAssignments()
+		if err != nil {
+			return nil, err
+		}
+		rdClient, err := azureAPI.RoleDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		principalID, err := azureAPI.PrincipalID(ctx)
+		if err != nil {
+			l.Error(err, "failed to determine the plugin's identity")
+		}
+
+		svc := validators.NewPreflightService(
+			l,
+			azure_utils.NewAzurePermissionsClient(ctx, azureAPI.Permissions),
+			azure_utils.NewAzureRoleAssignmentsClient(ctx, raClient),
+			azure_utils.NewAzureRoleDefinitionsClient(ctx, rdClient),
+			principalID,
+		)
+		return svc.ReconcilePreflight(scopes)
+	})
+}
+
+// reconcileWorkloadIdentity checks, in its own span, that the plugin's service account token
+// matches a federated identity credential of its workload identity.
+func reconcileWorkloadIdentity(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, identity azure_utils.WorkloadIdentity) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileWorkloadIdentity", constants.ValidationTypeWorkloadIdentity, constants.WorkloadIdentityRuleName, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		graphClient, err := azureAPI.Graph()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewWorkloadIdentityService(l, azure_utils.NewAzureApplicationsClient(ctx, graphClient), identity)
+		return svc.ReconcileWorkloadIdentity()
+	})
+}
+
+// reconcileRBACRule evaluates a single RBAC rule in its own span. The facades are created per rule
+// so that the Azure calls made for the rule are traced as children of the rule's span.
+func reconcileRBACRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, raCounts *validators.RoleAssignmentCounts, clk clock.PassiveClock, graceEnd time.Time, rule v1alpha1.RBACRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileRBACRule", constants.ValidationTypeRBAC, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		daClient, err := azureAPI.DenyAssignments()
+		if err != nil {
+			return nil, err
+		}
+		raClient, err := azureAPI.RoleAssignments()
+		if err != nil {
+			return nil, err
+		}
+		rdClient, err := azureAPI.RoleDefinitions()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewRBACRuleService(
+			l,
+			azure_utils.NewAzureDenyAssignmentsClient(ctx, daClient),
+			azure_utils.NewAzureRoleAssignmentsClient(ctx, raClient),
+			azure_utils.NewAzureRoleDefinitionsClient(ctx, rdClient),
+			raCounts,
+		).WithResourceGroups(azure_utils.NewAzureResourceGroupsClient(ctx, azureAPI.ResourceGroups)).
+			WithManagementGroups(azure_utils.NewAzureManagementGroupsClient(ctx, azureAPI.ManagementGroups))
+		if rule.SelfCheck {
+			principalID, err := azureAPI.PrincipalID(ctx)
+			if err != nil {
+				l.Error(err, "failed to determine the plugin's identity; selfCheck will be ignored", "ruleName", rule.Name)
+			}
+			svc.WithSelfCheck(azure_utils.NewAzurePermissionsClient(ctx, azureAPI.Permissions), principalID)
+		}
+		if rule.VerifyPrincipal || rule.PrincipalClientID != "" {
+			graphClient, err := azureAPI.Graph()
 			if err != nil {
-				l.Error(err, "failed to reconcile RBAC rule")
+				l.Error(err, "failed to create Microsoft Graph client; principals won't be looked up", "ruleName", rule.Name)
+			} else {
+				svc.WithPrincipals(azure_utils.NewAzurePrincipalsClient(ctx, graphClient))
 			}
-			resp.AddResult(vrr, err)
 		}
+		if !graceEnd.IsZero() {
+			svc.WithGracePeriod(clk.Now(), graceEnd)
+		}
+		return svc.ReconcileRBACRule(rule)
+	})
+}
+
+// reconcileKeyVaultCertificateRule evaluates a single Key Vault certificate rule in its own span.
+func reconcileKeyVaultCertificateRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, clk clock.PassiveClock, rule v1alpha1.KeyVaultCertificateRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileKeyVaultCertificateRule", constants.ValidationTypeKeyVaultCertificate, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		certClient, err := azureAPI.Certificates(rule.VaultURI)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewKeyVaultCertificateRuleService(
+			l,
+			azure_utils.NewAzureCertificatesClient(ctx, certClient, rule.VaultURI),
+			clk,
+		)
+		return svc.ReconcileKeyVaultCertificateRule(rule)
+	})
+}
+
+// reconcileAKSClusterRule evaluates a single AKS cluster rule in its own span.
+func reconcileAKSClusterRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.AKSClusterRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileAKSClusterRule", constants.ValidationTypeAKSCluster, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		subscriptionID := azure_utils.SubscriptionIDFromScope(rule.ClusterID)
+		mcClient, err := azureAPI.ManagedClusters(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewAKSClusterRuleService(
+			l,
+			azure_utils.NewAzureManagedClustersClient(ctx, mcClient, subscriptionID),
+		)
+		return svc.ReconcileAKSClusterRule(rule)
+	})
+}
+
+// reconcileNATGatewayRule evaluates a single NAT gateway rule in its own span.
+func reconcileNATGatewayRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.NATGatewayRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileNATGatewayRule", constants.ValidationTypeNATGateway, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		// The subnets of a rule may be in different subscriptions, so the facades get the clients for
+		// each subscription as they need them.
+		svc := validators.NewNATGatewayRuleService(
+			l,
+			azure_utils.NewAzureSubnetsClient(ctx, azureAPI.Subnets),
+			azure_utils.NewAzureNatGatewaysClient(ctx, azureAPI.NatGateways),
+		)
+		return svc.ReconcileNATGatewayRule(rule)
+	})
+}
+
+// reconcileVNetPeeringRule evaluates a single VNet peering rule in its own span.
+func reconcileVNetPeeringRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.VNetPeeringRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileVNetPeeringRule", constants.ValidationTypeVNetPeering, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewVNetPeeringRuleService(
+			l,
+			azure_utils.NewAzureVirtualNetworkPeeringsClient(ctx, azureAPI.VirtualNetworkPeerings),
+		)
+		return svc.ReconcileVNetPeeringRule(rule)
+	})
+}
+
+// reconcileRouteTableRule evaluates a single route table rule in its own span.
+func reconcileRouteTableRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.RouteTableRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileRouteTableRule", constants.ValidationTypeRouteTable, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewRouteTableRuleService(
+			l,
+			azure_utils.NewAzureSubnetsClient(ctx, azureAPI.Subnets),
+			azure_utils.NewAzureRouteTablesClient(ctx, azureAPI.RouteTables),
+		)
+		return svc.ReconcileRouteTableRule(rule)
+	})
+}
+
+// reconcileImageCompatibilityRule evaluates a single image compatibility rule in its own span.
+func reconcileImageCompatibilityRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ImageCompatibilityRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileImageCompatibilityRule", constants.ValidationTypeImageCompatibility, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		subscriptionID := rule.SubscriptionID
+		if subscriptionID == "" {
+			subscriptionID = azure_utils.SubscriptionIDFromScope(rule.ImageDefinitionID)
+		}
+		skuClient, err := azureAPI.ResourceSKUs(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewImageCompatibilityRuleService(
+			l,
+			azure_utils.NewAzureGalleryImagesClient(ctx, azureAPI.GalleryImages),
+			azure_utils.NewAzureResourceSKUsClient(ctx, skuClient, subscriptionID),
+		)
+		return svc.ReconcileImageCompatibilityRule(rule)
+	})
+}
+
+// reconcileImageReplicationRule evaluates a single image replication rule in its own span.
+func reconcileImageReplicationRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ImageReplicationRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileImageReplicationRule", constants.ValidationTypeImageReplication, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewImageReplicationRuleService(l, azure_utils.NewAzureGalleryImageVersionsClient(ctx, azureAPI.GalleryImageVersions))
+		return svc.ReconcileImageReplicationRule(rule)
+	})
+}
+
+// reconcileVMSecurityRule evaluates a single VM security rule in its own span.
+func reconcileVMSecurityRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.VMSecurityRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileVMSecurityRule", constants.ValidationTypeVMSecurity, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		subscriptionID := rule.SubscriptionID
+		if subscriptionID == "" {
+			subscriptionID = azure_utils.SubscriptionIDFromScope(rule.ImageDefinitionID)
+		}
+		skuClient, err := azureAPI.ResourceSKUs(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewVMSecurityRuleService(
+			l,
+			azure_utils.NewAzureGalleryImagesClient(ctx, azureAPI.GalleryImages),
+			azure_utils.NewAzureResourceSKUsClient(ctx, skuClient, subscriptionID),
+		)
+		return svc.ReconcileVMSecurityRule(rule)
+	})
+}
+
+// reconcileVMSizeRule evaluates a single VM size rule in its own span.
+func reconcileVMSizeRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.VMSizeRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileVMSizeRule", constants.ValidationTypeVMSize, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		skuClient, err := azureAPI.ResourceSKUs(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewVMSizeRuleService(l, azure_utils.NewAzureResourceSKUsClient(ctx, skuClient, rule.SubscriptionID))
+		return svc.ReconcileVMSizeRule(rule)
+	})
+}
+
+// reconcileDiskZoneRule evaluates a single disk zone rule in its own span.
+func reconcileDiskZoneRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.DiskZoneRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileDiskZoneRule", constants.ValidationTypeDiskZone, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		skuClient, err := azureAPI.ResourceSKUs(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewDiskZoneRuleService(l, azure_utils.NewAzureResourceSKUsClient(ctx, skuClient, rule.SubscriptionID))
+		return svc.ReconcileDiskZoneRule(rule)
+	})
+}
+
+// reconcileTemplatePermissionRule evaluates a single template permission rule in its own span.
+func reconcileTemplatePermissionRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.TemplatePermissionRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileTemplatePermissionRule", constants.ValidationTypeTemplatePermission, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		daClient, err := azureAPI.DenyAssignments()
+		if err != nil {
+			return nil, err
+		}
+		raClient, err := azureAPI.RoleAssignments()
+		if err != nil {
+			return nil, err
+		}
+		rdClient, err := azureAPI.RoleDefinitions()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewTemplatePermissionRuleService(
+			l,
+			azure_utils.NewAzureDenyAssignmentsClient(ctx, daClient),
+			azure_utils.NewAzureRoleAssignmentsClient(ctx, raClient),
+			azure_utils.NewAzureRoleDefinitionsClient(ctx, rdClient),
+		)
+		return svc.ReconcileTemplatePermissionRule(rule)
+	})
+}
+
+// reconcilePolicyExemptionRule evaluates a single policy exemption rule in its own span.
+func reconcilePolicyExemptionRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, clk clock.PassiveClock, rule v1alpha1.PolicyExemptionRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcilePolicyExemptionRule", constants.ValidationTypePolicyExemption, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		peClient, err := azureAPI.PolicyExemptions(azure_utils.SubscriptionIDFromScope(rule.Scope))
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewPolicyExemptionRuleService(
+			l,
+			azure_utils.NewAzurePolicyExemptionsClient(ctx, peClient),
+			clk,
+		)
+		return svc.ReconcilePolicyExemptionRule(rule)
+	})
+}
+
+// reconcileDefenderPlanRule evaluates a single Defender plan rule in its own span.
+func reconcileDefenderPlanRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.DefenderPlanRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileDefenderPlanRule", constants.ValidationTypeDefenderPlan, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		pricingsClient, err := azureAPI.Pricings()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewDefenderPlanRuleService(
+			l,
+			azure_utils.NewAzurePricingsClient(ctx, pricingsClient, rule.SubscriptionID),
+		)
+		return svc.ReconcileDefenderPlanRule(rule)
+	})
+}
+
+// reconcileBudgetRule evaluates a single budget rule in its own span.
+func reconcileBudgetRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.BudgetRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileBudgetRule", constants.ValidationTypeBudget, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		budgetsClient, err := azureAPI.Budgets()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewBudgetRuleService(
+			l,
+			azure_utils.NewAzureBudgetsClient(ctx, budgetsClient),
+		)
+		return svc.ReconcileBudgetRule(rule)
+	})
+}
+
+// reconcileResourceLockRule evaluates a single resource lock rule in its own span.
+func reconcileResourceLockRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ResourceLockRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileResourceLockRule", constants.ValidationTypeResourceLock, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewResourceLockRuleService(
+			l,
+			azure_utils.NewAzureManagementLocksClient(ctx, azureAPI.ManagementLocks),
+		)
+		return svc.ReconcileResourceLockRule(rule)
+	})
+}
+
+// reconcileFirewallPolicyRule evaluates a single firewall policy rule in its own span.
+func reconcileFirewallPolicyRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.FirewallPolicyRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileFirewallPolicyRule", constants.ValidationTypeFirewallPolicy, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewFirewallPolicyRuleService(
+			l,
+			azure_utils.NewAzureFirewallPolicyRuleCollectionGroupsClient(ctx, azureAPI.FirewallPolicyRuleCollectionGroups),
+		)
+		return svc.ReconcileFirewallPolicyRule(rule)
+	})
+}
+
+// reconcileBastionRule evaluates a single Bastion rule in its own span.
+func reconcileBastionRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.BastionRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileBastionRule", constants.ValidationTypeBastion, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewBastionRuleService(
+			l,
+			azure_utils.NewAzureSubnetsClient(ctx, azureAPI.Subnets),
+			azure_utils.NewAzureBastionHostsClient(ctx, azureAPI.BastionHosts),
+		)
+		return svc.ReconcileBastionRule(rule)
+	})
+}
+
+// reconcileDDoSProtectionRule evaluates a single DDoS protection rule in its own span.
+func reconcileDDoSProtectionRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.DDoSProtectionRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileDDoSProtectionRule", constants.ValidationTypeDDoSProtection, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewDDoSProtectionRuleService(l, azure_utils.NewAzureVirtualNetworksClient(ctx, azureAPI.VirtualNetworks))
+		return svc.ReconcileDDoSProtectionRule(rule)
+	})
+}
+
+// reconcilePublicIPPrefixRule evaluates a single public IP prefix rule in its own span.
+func reconcilePublicIPPrefixRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.PublicIPPrefixRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcilePublicIPPrefixRule", constants.ValidationTypePublicIPPrefix, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewPublicIPPrefixRuleService(l, azure_utils.NewAzurePublicIPPrefixesClient(ctx, azureAPI.PublicIPPrefixes))
+		return svc.ReconcilePublicIPPrefixRule(rule)
+	})
+}
+
+// reconcileEventHubRule evaluates a single event hub rule in its own span.
+func reconcileEventHubRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.EventHubRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileEventHubRule", constants.ValidationTypeEventHub, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewEventHubRuleService(l, azure_utils.NewAzureEventHubsClient(ctx, azureAPI.EventHubNamespaces, azureAPI.EventHubs))
+		return svc.ReconcileEventHubRule(rule)
+	})
+}
+
+// reconcileApplicationGatewayRule evaluates a single application gateway rule in its own span.
+func reconcileApplicationGatewayRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ApplicationGatewayRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileApplicationGatewayRule", constants.ValidationTypeApplicationGateway, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewApplicationGatewayRuleService(
+			l,
+			azure_utils.NewAzureApplicationGatewaysClient(ctx, azureAPI.ApplicationGateways),
+			azure_utils.NewAzureWebApplicationFirewallPoliciesClient(ctx, azureAPI.WebApplicationFirewallPolicies),
+		)
+		return svc.ReconcileApplicationGatewayRule(rule)
+	})
+}
+
+// reconcileCosmosDBRule evaluates a single Cosmos DB rule in its own span.
+func reconcileCosmosDBRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.CosmosDBRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileCosmosDBRule", constants.ValidationTypeCosmosDB, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewCosmosDBRuleService(l, azure_utils.NewAzureDatabaseAccountsClient(ctx, azureAPI.DatabaseAccounts))
+		return svc.ReconcileCosmosDBRule(rule)
+	})
+}
+
+// reconcileSQLServerRule evaluates a single SQL server rule in its own span.
+func reconcileSQLServerRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.SQLServerRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileSQLServerRule", constants.ValidationTypeSQLServer, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewSQLServerRuleService(l, azure_utils.NewAzureSQLServersClient(ctx, azureAPI.SQLServers, azureAPI.SQLServerAzureADAdministrators))
+		return svc.ReconcileSQLServerRule(rule)
+	})
+}
+
+// reconcileGlobalEndpointRule evaluates a single global endpoint rule in its own span.
+func reconcileGlobalEndpointRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.GlobalEndpointRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileGlobalEndpointRule", constants.ValidationTypeGlobalEndpoint, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewGlobalEndpointRuleService(l, azure_utils.NewAzureTrafficManagerProfilesClient(ctx, azureAPI.TrafficManagerProfiles))
+		return svc.ReconcileGlobalEndpointRule(rule)
+	})
+}
+
+// reconcileExpressRouteRule evaluates a single ExpressRoute rule in its own span.
+func reconcileExpressRouteRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ExpressRouteRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileExpressRouteRule", constants.ValidationTypeExpressRoute, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewExpressRouteRuleService(
+			l,
+			azure_utils.NewAzureExpressRouteCircuitsClient(ctx, azureAPI.ExpressRouteCircuits),
+			azure_utils.NewAzureVirtualNetworkGatewayConnectionsClient(ctx, azureAPI.VirtualNetworkGatewayConnections),
+		)
+		return svc.ReconcileExpressRouteRule(rule)
+	})
+}
+
+// reconcileVPNGatewayRule evaluates a single VPN gateway rule in its own span.
+func reconcileVPNGatewayRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.VPNGatewayRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileVPNGatewayRule", constants.ValidationTypeVPNGateway, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewVPNGatewayRuleService(
+			l,
+			azure_utils.NewAzureVirtualNetworkGatewaysClient(ctx, azureAPI.VirtualNetworkGateways),
+			azure_utils.NewAzureVirtualNetworkGatewayConnectionsClient(ctx, azureAPI.VirtualNetworkGatewayConnections),
+		)
+		return svc.ReconcileVPNGatewayRule(rule)
+	})
+}
+
+// reconcileNetworkWatcherRule evaluates a single network watcher rule in its own span.
+func reconcileNetworkWatcherRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.NetworkWatcherRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileNetworkWatcherRule", constants.ValidationTypeNetworkWatcher, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewNetworkWatcherRuleService(l, azure_utils.NewAzureNetworkWatchersClient(ctx, azureAPI.NetworkWatchers, azureAPI.FlowLogs))
+		return svc.ReconcileNetworkWatcherRule(rule)
+	})
+}
+
+// reconcileProximityPlacementGroupRule evaluates a single proximity placement group rule in its
+// own span.
+func reconcileProximityPlacementGroupRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ProximityPlacementGroupRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileProximityPlacementGroupRule", constants.ValidationTypeProximityPlacementGroup, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewProximityPlacementGroupRuleService(l, azure_utils.NewAzureProximityPlacementGroupsClient(ctx, azureAPI.ProximityPlacementGroups))
+		return svc.ReconcileProximityPlacementGroupRule(rule)
+	})
+}
+
+// reconcileEncryptionAtHostRule evaluates a single encryption at host rule in its own span.
+func reconcileEncryptionAtHostRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.EncryptionAtHostRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileEncryptionAtHostRule", constants.ValidationTypeEncryptionAtHost, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		featuresClient, err := azureAPI.Features(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		skuClient, err := azureAPI.ResourceSKUs(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewEncryptionAtHostRuleService(
+			l,
+			azure_utils.NewAzureFeaturesClient(ctx, featuresClient, rule.SubscriptionID),
+			azure_utils.NewAzureResourceSKUsClient(ctx, skuClient, rule.SubscriptionID),
+		)
+		return svc.ReconcileEncryptionAtHostRule(rule)
+	})
+}
+
+// reconcileGroupMembershipRule evaluates a single group membership rule in its own span.
+func reconcileGroupMembershipRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.GroupMembershipRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileGroupMembershipRule", constants.ValidationTypeGroupMembership, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		graphClient, err := azureAPI.Graph()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewGroupMembershipRuleService(l, azure_utils.NewAzureGroupsClient(ctx, graphClient))
+		return svc.ReconcileGroupMembershipRule(rule)
+	})
+}
+
+// reconcileAppPermissionRule evaluates a single app permission rule in its own span.
+func reconcileAppPermissionRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.AppPermissionRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileAppPermissionRule", constants.ValidationTypeAppPermission, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		graphClient, err := azureAPI.Graph()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewAppPermissionRuleService(l, azure_utils.NewAzureApplicationsClient(ctx, graphClient))
+		return svc.ReconcileAppPermissionRule(rule)
+	})
+}
+
+// reconcileBlobContainerRule evaluates a single blob container rule in its own span.
+func reconcileBlobContainerRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.BlobContainerRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileBlobContainerRule", constants.ValidationTypeBlobContainer, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewBlobContainerRuleService(l, azure_utils.NewAzureStorageClient(ctx, azureAPI.StorageAccounts, azureAPI.BlobContainers, azureAPI.FileShares, azureAPI.ManagementPolicies))
+		return svc.ReconcileBlobContainerRule(rule)
+	})
+}
+
+// reconcileStorageNetworkRule evaluates a single storage network rule in its own span.
+func reconcileStorageNetworkRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.StorageNetworkRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileStorageNetworkRule", constants.ValidationTypeStorageNetwork, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewStorageNetworkRuleService(l, azure_utils.NewAzureStorageClient(ctx, azureAPI.StorageAccounts, azureAPI.BlobContainers, azureAPI.FileShares, azureAPI.ManagementPolicies))
+		return svc.ReconcileStorageNetworkRule(rule)
+	})
+}
+
+// reconcileFileShareRule evaluates a single file share rule in its own span.
+func reconcileFileShareRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.FileShareRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileFileShareRule", constants.ValidationTypeFileShare, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewFileShareRuleService(l, azure_utils.NewAzureStorageClient(ctx, azureAPI.StorageAccounts, azureAPI.BlobContainers, azureAPI.FileShares, azureAPI.ManagementPolicies))
+		return svc.ReconcileFileShareRule(rule)
+	})
+}
+
+// reconcileSubnetDelegationRule evaluates a single subnet delegation rule in its own span.
+func reconcileSubnetDelegationRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.SubnetDelegationRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileSubnetDelegationRule", constants.ValidationTypeSubnetDelegation, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewSubnetDelegationRuleService(l, azure_utils.NewAzureSubnetsClient(ctx, azureAPI.Subnets))
+		return svc.ReconcileSubnetDelegationRule(rule)
+	})
+}
+
+// reconcileMonitorAlertRule evaluates a single monitor alert rule in its own span.
+func reconcileMonitorAlertRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.MonitorAlertRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileMonitorAlertRule", constants.ValidationTypeMonitorAlert, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewMonitorAlertRuleService(l, azure_utils.NewAzureMonitorAlertsClient(ctx, azureAPI.ActionGroups, azureAPI.MetricAlerts))
+		return svc.ReconcileMonitorAlertRule(rule)
+	})
+}
+
+// reconcileActivityLogExportRule evaluates a single Activity Log export rule in its own span.
+func reconcileActivityLogExportRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ActivityLogExportRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileActivityLogExportRule", constants.ValidationTypeActivityLogExport, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		diagnosticSettingsClient, err := azureAPI.DiagnosticSettings()
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewActivityLogExportRuleService(l, azure_utils.NewAzureDiagnosticSettingsClient(ctx, diagnosticSettingsClient))
+		return svc.ReconcileActivityLogExportRule(rule)
+	})
+}
+
+// reconcileImageDeprecationRule evaluates a single image deprecation rule in its own span.
+func reconcileImageDeprecationRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, clk clock.PassiveClock, rule v1alpha1.ImageDeprecationRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileImageDeprecationRule", constants.ValidationTypeImageDeprecation, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		vmImagesClient, err := azureAPI.VirtualMachineImages(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewImageDeprecationRuleService(
+			l,
+			azure_utils.NewAzureVirtualMachineImagesClient(ctx, vmImagesClient, rule.SubscriptionID),
+			clk,
+		)
+		return svc.ReconcileImageDeprecationRule(rule)
+	})
+}
+
+// reconcileZoneRedundancyRule evaluates a single zone redundancy rule in its own span.
+func reconcileZoneRedundancyRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.ZoneRedundancyRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileZoneRedundancyRule", constants.ValidationTypeZoneRedundancy, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		skuClient, err := azureAPI.ResourceSKUs(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		storageSKUClient, err := azureAPI.StorageSKUs(rule.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		svc := validators.NewZoneRedundancyRuleService(
+			l,
+			azure_utils.NewAzureResourceSKUsClient(ctx, skuClient, rule.SubscriptionID),
+			azure_utils.NewAzureStorageSKUsClient(ctx, storageSKUClient, rule.SubscriptionID),
+		)
+		return svc.ReconcileZoneRedundancyRule(rule)
+	})
+}
+
+// reconcileCustomLocationRule evaluates a single custom location rule in its own span.
+func reconcileCustomLocationRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.CustomLocationRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileCustomLocationRule", constants.ValidationTypeCustomLocation, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewCustomLocationRuleService(l, azure_utils.NewAzureArcClient(ctx, azureAPI.Resources))
+		return svc.ReconcileCustomLocationRule(rule)
+	})
+}
+
+// reconcileKeyVaultRecoveryRule evaluates a single Key Vault recovery rule in its own span.
+func reconcileKeyVaultRecoveryRule(ctx context.Context, l logr.Logger, azureAPI *azure_utils.AzureAPI, rule v1alpha1.KeyVaultRecoveryRule) (*types.ValidationRuleResult, error) {
+	return tracedEval(ctx, "ReconcileKeyVaultRecoveryRule", constants.ValidationTypeKeyVaultRecovery, rule.Name, func(ctx context.Context) (*types.ValidationRuleResult, error) {
+		svc := validators.NewKeyVaultRecoveryRuleService(l, azure_utils.NewAzureKeyVaultsClient(ctx, azureAPI.Resources))
+		return svc.ReconcileKeyVaultRecoveryRule(rule)
+	})
+}
+
+// ruleOutcome is the outcome of a single evaluation of a rule.
+type ruleOutcome struct {
+	name           string
+	validationType string
+	state          vapi.ValidationState
+	start          time.Time
+	duration       time.Duration
+	hash           string
+	generation     int64
+	// pendingUntil is when the grace period of a pending rule ends.
+	pendingUntil time.Time
+	// notEvaluated is whether the rule wasn't evaluated, because the run deadline elapsed first.
+	notEvaluated bool
+}
+
+// erroredRuleResult builds the result of a rule whose evaluation returned an error without a
+// result. SafeUpdateValidationResult adds the error to its condition's failures, like it does for
+// the results that rule services return with their errors.
+func erroredRuleResult(name, validationType string) *types.ValidationRuleResult {
+	state := vapi.ValidationFailed
+	condition := vapi.DefaultValidationCondition()
+	condition.Failures = []string{}
+	condition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, name)
+	condition.ValidationType = validationType
+	condition.Status = corev1.ConditionFalse
+	return &types.ValidationRuleResult{Condition: &condition, State: &state}
+}
+
+// newRuleOutcome builds the outcome of a rule evaluation that ran from start to end. Rules that
+// couldn't be evaluated because of an error are considered failed.
+func newRuleOutcome(name, validationType string, vrr *types.ValidationRuleResult, err error, start, end time.Time) ruleOutcome {
+	state := vapi.ValidationFailed
+	if err == nil && vrr != nil && vrr.State != nil {
+		state = *vrr.State
 	}
+	return ruleOutcome{
+		name:           name,
+		validationType: validationType,
+		state:          state,
+		start:          start,
+		duration:       end.Sub(start),
+	}
+}
 
-	// Patch the ValidationResult with the latest ValidationRuleResults
-	if err := vres.SafeUpdateValidationResult(ctx, p, vr, resp, r.Log); err != nil {
-		return ctrl.Result{}, err
+func (r *AzureValidatorReconciler) now() time.Time {
+	return r.passiveClock().Now()
+}
+
+// passiveClock returns the clock used to time rule evaluations.
+func (r *AzureValidatorReconciler) passiveClock() clock.PassiveClock {
+	if r.clock == nil {
+		return clock.RealClock{}
+	}
+	return r.clock
+}
+
+// buildRuleResults builds the RuleResults for the latest evaluation of an AzureValidator's rules
+// from their previous results. A rule's LastTransitionTime only moves when its state changes. Rules
+// that no longer exist in the spec are dropped.
+func buildRuleResults(prev []v1alpha1.RuleResult, outcomes []ruleOutcome) []v1alpha1.RuleResult {
+	results := make([]v1alpha1.RuleResult, 0, len(outcomes))
+	for _, o := range outcomes {
+		result := v1alpha1.RuleResult{
+			Name:               o.name,
+			ValidationType:     o.validationType,
+			State:              o.state,
+			Duration:           metav1.Duration{Duration: o.duration},
+			LastEvaluationTime: metav1.NewTime(o.start),
+			LastTransitionTime: metav1.NewTime(o.start),
+			Hash:               o.hash,
+			ObservedGeneration: o.generation,
+		}
+		if !o.pendingUntil.IsZero() {
+			result.PendingUntil = &metav1.Time{Time: o.pendingUntil}
+		}
+		result.NotEvaluated = o.notEvaluated
+		for _, p := range prev {
+			if p.Name == o.name && p.ValidationType == o.validationType && p.State == o.state {
+				result.LastTransitionTime = p.LastTransitionTime
+				break
+			}
+		}
+		results = append(results, result)
 	}
+	return results
+}
 
-	l.Info("Requeuing for re-validation in two minutes.")
-	return ctrl.Result{RequeueAfter: time.Second * 120}, nil
+// updateRuleResults records the latest evaluation of an AzureValidator's rules in its status, along
+// with the generation that was evaluated and the ARM requests it made. Any dry-run outcome is
+// cleared, since it's superseded by the results written to the ValidationResult.
+func (r *AzureValidatorReconciler) updateRuleResults(ctx context.Context, validator *v1alpha1.AzureValidator, outcomes []ruleOutcome, apiRequests *v1alpha1.APIRequests) error {
+	p, err := patch.NewHelper(validator, r.Client)
+	if err != nil {
+		return err
+	}
+	validator.Status.RuleResults = buildRuleResults(validator.Status.RuleResults, outcomes)
+	validator.Status.ObservedGeneration = validator.Generation
+	validator.Status.LastRunAPIRequests = apiRequests
+	validator.Status.DryRun = nil
+	return p.Patch(ctx, validator)
 }
 
-// envFromSecret sets environment variables from a secret to configure Azure credentials
-func (r *AzureValidatorReconciler) envFromSecret(name, namespace string) error {
+// orderConditions orders the conditions of a ValidationResult like the results of the latest
+// evaluation, which are in the order of the AzureValidator's rules, so that the ValidationResult
+// doesn't change between reconciles unless the results do. New rules' conditions are placed
+// right away rather than appended once their results are recorded. Conditions without a result,
+// e.g. of rules that were removed from the spec, follow in their previous order.
+func orderConditions(vr *vapi.ValidationResult, results []*types.ValidationRuleResult) {
+	conditions := make([]vapi.ValidationCondition, 0, len(vr.Status.ValidationConditions)+len(results))
+	placed := make(map[string]bool, len(results))
+	for _, vrr := range results {
+		if vrr == nil || vrr.Condition == nil || placed[vrr.Condition.ValidationRule] {
+			continue
+		}
+		placed[vrr.Condition.ValidationRule] = true
+		conditions = append(conditions, *vrr.Condition)
+	}
+	for _, c := range vr.Status.ValidationConditions {
+		if !placed[c.ValidationRule] {
+			conditions = append(conditions, c)
+		}
+	}
+	vr.Status.ValidationConditions = conditions
+}
+
+// isPaused returns whether validation has been paused for an AzureValidator via its paused
+// annotation.
+func isPaused(validator *v1alpha1.AzureValidator) bool {
+	return validator.Annotations[constants.PausedAnnotation] == "true"
+}
+
+// updatePausedCondition records whether an AzureValidator is paused in its status. The status is
+// only patched when the paused state changes, so the condition's LastTransitionTime is the time
+// validation was paused (or resumed). Nothing is recorded for AzureValidators that have never been
+// paused.
+func (r *AzureValidatorReconciler) updatePausedCondition(ctx context.Context, validator *v1alpha1.AzureValidator, paused bool) error {
+	condition := metav1.Condition{
+		Type:               constants.ConditionTypePaused,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: validator.Generation,
+		Reason:             "Resumed",
+		Message:            "Validation is active.",
+	}
+	if paused {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PausedByAnnotation"
+		condition.Message = fmt.Sprintf("Validation is paused because the %s annotation is set to \"true\".", constants.PausedAnnotation)
+	}
+
+	existing := meta.FindStatusCondition(validator.Status.Conditions, constants.ConditionTypePaused)
+	if (existing == nil && !paused) || (existing != nil && existing.Status == condition.Status) {
+		return nil
+	}
+
+	p, err := patch.NewHelper(validator, r.Client)
+	if err != nil {
+		return err
+	}
+	meta.SetStatusCondition(&validator.Status.Conditions, condition)
+	return p.Patch(ctx, validator)
+}
+
+// envFromSecret sets environment variables from a secret to configure Azure credentials. Returns
+// the secret.
+func (r *AzureValidatorReconciler) envFromSecret(name, namespace string) (*corev1.Secret, error) {
 	r.Log.Info("Configuring environment from secret", "name", name, "namespace", namespace)
 
 	nn := ktypes.NamespacedName{Name: name, Namespace: namespace}
 	secret := &corev1.Secret{}
 	if err := r.Get(context.Background(), nn, secret); err != nil {
-		return err
+		return nil, err
 	}
 
 	for k, v := range secret.Data {
 		if err := os.Setenv(k, string(v)); err != nil {
-			return err
+			return nil, err
 		}
 		r.Log.Info("Set environment variable", "key", k)
 	}
-	return nil
+	return secret, nil
+}
+
+// resolveTemplate returns a template permission rule with its template read from its ConfigMap, if
+// it refers to one.
+func (r *AzureValidatorReconciler) resolveTemplate(ctx context.Context, namespace string, rule v1alpha1.TemplatePermissionRule) (v1alpha1.TemplatePermissionRule, error) {
+	ref := rule.TemplateConfigMapRef
+	if ref == nil {
+		return rule, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, ktypes.NamespacedName{Name: ref.Name, Namespace: namespace}, cm); err != nil {
+		return rule, fmt.Errorf("failed to get ConfigMap %s with template: %w", ref.Name, err)
+	}
+	template, ok := cm.Data[ref.Key]
+	if !ok {
+		return rule, fmt.Errorf("ConfigMap %s has no key %s", ref.Name, ref.Key)
+	}
+	rule.Template = template
+	rule.TemplateConfigMapRef = nil
+	return rule, nil
+}
+
+// resolvedTemplateRule is a template permission rule with its template resolved, or the error
+// that resolving it returned.
+type resolvedTemplateRule struct {
+	rule v1alpha1.TemplatePermissionRule
+	err  error
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// ruleSpecProblems returns the problems with the specs of an AzureValidator's RBAC and template
+// permission rules, in the order of the rules. Template permission rules whose templates couldn't
+// be resolved aren't checked, as they fail with the error that resolving them returned.
+func ruleSpecProblems(validator *v1alpha1.AzureValidator, templateRules []resolvedTemplateRule) []validators.SpecProblem {
+	var problems []validators.SpecProblem
+	add := func(ruleName string, errs field.ErrorList) {
+		for _, err := range errs {
+			problems = append(problems, validators.SpecProblem{RuleName: ruleName, Err: err})
+		}
+	}
+	spec := field.NewPath("spec")
+	for i, rule := range validator.Spec.RBACRules {
+		add(rule.Name, validators.RBACRuleSpecErrors(rule, spec.Child("rbacRules").Index(i)))
+	}
+	for i, tr := range templateRules {
+		if tr.err == nil {
+			add(tr.rule.Name, validators.TemplatePermissionRuleSpecErrors(tr.rule, spec.Child("templatePermissionRules").Index(i)))
+		}
+	}
+	return problems
+}
+
+// hasValidationType returns whether a ValidationResult has a condition of a validation type.
+func hasValidationType(vr *vapi.ValidationResult, validationType string) bool {
+	for _, c := range vr.Status.ValidationConditions {
+		if c.ValidationType == validationType {
+			return true
+		}
+	}
+	return false
+}
+
+// azureAPI configures the Azure credential of an AzureValidator, like Reconcile does, and returns
+// the AzureAPI that uses it.
+func (r *AzureValidatorReconciler) azureAPI(validator *v1alpha1.AzureValidator) (*azure_utils.AzureAPI, error) {
+	r.azureEnvMu.Lock()
+	defer r.azureEnvMu.Unlock()
+	credentialVersion := ""
+	if !validator.Spec.Auth.Implicit {
+		if !r.secretNamespaceAllowed(validator) {
+			return nil, fmt.Errorf("%w: %s", ErrSecretNamespaceNotAllowed, validator.AuthSecretNamespace())
+		}
+		secret, err := r.envFromSecret(validator.Spec.Auth.SecretName, validator.AuthSecretNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure environment from secret: %w", err)
+		}
+		credentialVersion = secret.ResourceVersion
+	}
+	return r.azureClients().API(credentialID(validator), credentialVersion)
+}
+
+// azureClients returns the factory of the Azure service clients used to evaluate rules.
+func (r *AzureValidatorReconciler) azureClients() *azure_utils.ClientFactory {
+	r.clientFactoryOnce.Do(func() {
+		r.clientFactory = azure_utils.NewClientFactory(r.Azure)
+	})
+	return r.clientFactory
+}
+
+// reconcileTriggers filters the AzureValidator events that trigger a reconcile. Creations, deletions
+// and spec changes (which change the generation) trigger one immediately, rather than at the next
+// scheduled re-validation. Status updates (e.g. rule results) must not, otherwise every reconcile
+// would immediately trigger another. Annotation changes must, so that pausing and dry-run mode
+// take effect.
+func reconcileTriggers() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
+// SetupWithManager sets up the controller with the Manager. Changes to the Secrets and ConfigMaps
+// that AzureValidators refer to trigger the reconciles of only those AzureValidators. If
+// ActivityLogPollInterval is set, it also adds the poller of role assignment changes, whose events
+// trigger reconciles.
 func (r *AzureValidatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.AzureValidator{}).
-		Complete(r)
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.AzureValidator{}, indexConfigMaps, indexConfigMapNames); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.AzureValidator{}, indexSecrets, indexSecretNames); err != nil {
+		return err
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.AzureValidator{}, builder.WithPredicates(reconcileTriggers())).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.validatorsForConfigMap)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.validatorsForSecret), builder.OnlyMetadata).
+		WithOptions(crcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+
+	if r.ActivityLogPollInterval > 0 {
+		indexer := mgr.GetFieldIndexer()
+		if err := indexer.IndexField(context.Background(), &v1alpha1.AzureValidator{}, indexRolePrincipals, indexRolePrincipalIDs); err != nil {
+			return err
+		}
+		if err := indexer.IndexField(context.Background(), &v1alpha1.AzureValidator{}, indexRoleScopes, indexRoleScopeIDs); err != nil {
+			return err
+		}
+		revalidations := make(chan event.GenericEvent)
+		if err := mgr.Add(newRoleAssignmentPoller(r, r.ActivityLogPollInterval, revalidations)); err != nil {
+			return err
+		}
+		b = b.WatchesRawSource(&source.Channel{Source: revalidations}, &handler.EnqueueRequestForObject{})
+	}
+	return b.Complete(r)
 }
 
 func buildValidationResult(validator *v1alpha1.AzureValidator) *vapi.ValidationResult {
-	return &vapi.ValidationResult{
+	key := validationResultKey(validator)
+	vr := &vapi.ValidationResult{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      validationResultName(validator),
-			Namespace: validator.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion: validator.APIVersion,
-					Kind:       validator.Kind,
-					Name:       validator.Name,
-					UID:        validator.UID,
-					Controller: util.Ptr(true),
-				},
-			},
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    maps.Clone(validator.Spec.ResultLabels),
 		},
 		Spec: vapi.ValidationResultSpec{
 			Plugin:          constants.PluginCode,
 			ExpectedResults: validator.Spec.ResultCount(),
 		},
 	}
+	// The finalizer deletes the ValidationResult. The owner reference additionally lets the garbage
+	// collector delete it if the finalizer was removed by hand.
+	vr.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "AzureValidator",
+			Name:       validator.Name,
+			UID:        validator.UID,
+			Controller: util.Ptr(true),
+		},
+	}
+	return vr
 }
 
 func validationResultName(validator *v1alpha1.AzureValidator) string {
 	return fmt.Sprintf("validator-plugin-azure-%s", validator.Name)
 }
+
+// validationResultKey returns the name and namespace of an AzureValidator's ValidationResult, which
+// is always in the AzureValidator's namespace, so that it can be owned by the AzureValidator.
+func validationResultKey(validator *v1alpha1.AzureValidator) ktypes.NamespacedName {
+	return ktypes.NamespacedName{
+		Name:      validationResultName(validator),
+		Namespace: validator.Namespace,
+	}
+}
+
+// ensureFinalizer adds the finalizer that cleans up an AzureValidator's ValidationResult, if the
+// AzureValidator doesn't have it yet.
+func (r *AzureValidatorReconciler) ensureFinalizer(ctx context.Context, validator *v1alpha1.AzureValidator) error {
+	if controllerutil.ContainsFinalizer(validator, constants.ValidationResultFinalizer) {
+		return nil
+	}
+	base := client.MergeFrom(validator.DeepCopy())
+	controllerutil.AddFinalizer(validator, constants.ValidationResultFinalizer)
+	return r.Patch(ctx, validator, base)
+}
+
+// reconcileDelete deletes the ValidationResult of an AzureValidator that is being deleted, so that
+// sinks stop reporting its last state, records an event saying so, and then removes the finalizer
+// to let the deletion complete. A ValidationResult that is already gone doesn't block the deletion.
+func (r *AzureValidatorReconciler) reconcileDelete(ctx context.Context, l logr.Logger, validator *v1alpha1.AzureValidator) error {
+	if !controllerutil.ContainsFinalizer(validator, constants.ValidationResultFinalizer) {
+		return nil
+	}
+
+	key := validationResultKey(validator)
+	l = l.WithValues("validationResult", key)
+	vr := &vapi.ValidationResult{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Delete(ctx, vr); err != nil {
+		if !apierrs.IsNotFound(err) {
+			l.Error(err, "failed to delete ValidationResult of deleted AzureValidator")
+			return err
+		}
+		l.Info("ValidationResult of deleted AzureValidator is already gone")
+	} else {
+		l.Info("Deleted ValidationResult of deleted AzureValidator")
+		r.recordResultDeleted(validator, key)
+	}
+
+	forgetMetrics(validator)
+
+	base := client.MergeFrom(validator.DeepCopy())
+	controllerutil.RemoveFinalizer(validator, constants.ValidationResultFinalizer)
+	if err := r.Patch(ctx, validator, base); err != nil {
+		l.Error(err, "failed to remove finalizer")
+		return client.IgnoreNotFound(err)
+	}
+	return nil
+}
@@ -2,15 +2,43 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha2"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -140,6 +168,7 @@ var _ = Describe("AzureValidator controller", Ordered, func() {
 				Namespace: validatorNamespace,
 			},
 			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
 				Auth: v1alpha1.AzureAuth{
 					Implicit:   false,
 					SecretName: "azure-creds",
@@ -183,4 +212,2583 @@ var _ = Describe("AzureValidator controller", Ordered, func() {
 			return stateOk
 		}, timeout, interval).Should(BeTrue(), "failed to create a ValidationResult")
 	})
-})
+
+	It("Should delete the ValidationResult when the AzureValidator is deleted", func() {
+		By("By creating a new AzureValidator and waiting for its ValidationResult")
+
+		ctx := context.Background()
+
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-deleted", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		valKey := types.NamespacedName{Name: val.Name, Namespace: val.Namespace}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+		Expect(k8sClient.Create(ctx, val)).Should(Succeed())
+
+		vr := &vapi.ValidationResult{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, vrKey, vr)
+		}, timeout, interval).Should(Succeed(), "failed to create a ValidationResult")
+		Expect(vr.OwnerReferences).To(HaveLen(1))
+		Expect(vr.OwnerReferences[0].UID).To(Equal(val.UID))
+
+		By("By deleting the AzureValidator")
+
+		Expect(k8sClient.Delete(ctx, val)).Should(Succeed())
+		Eventually(func() bool {
+			return apierrs.IsNotFound(k8sClient.Get(ctx, vrKey, &vapi.ValidationResult{}))
+		}, timeout, interval).Should(BeTrue(), "failed to delete the ValidationResult")
+		Eventually(func() bool {
+			return apierrs.IsNotFound(k8sClient.Get(ctx, valKey, &v1alpha1.AzureValidator{}))
+		}, timeout, interval).Should(BeTrue(), "failed to remove the finalizer")
+	})
+
+	It("Should reconcile right away when the spec changes and record the evaluated generation", func() {
+		By("By creating a new AzureValidator")
+
+		ctx := context.Background()
+
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-generation", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		valKey := types.NamespacedName{Name: val.Name, Namespace: val.Namespace}
+		Expect(k8sClient.Create(ctx, val)).Should(Succeed())
+
+		// observedGeneration returns the generation of the AzureValidator and the generation its
+		// status says was evaluated.
+		observedGeneration := func() (int64, int64) {
+			if err := k8sClient.Get(ctx, valKey, val); err != nil {
+				return -1, -2
+			}
+			return val.Generation, val.Status.ObservedGeneration
+		}
+		Eventually(func() bool {
+			generation, observed := observedGeneration()
+			return observed == generation
+		}, timeout, interval).Should(BeTrue(), "failed to evaluate the new AzureValidator")
+		Expect(val.Status.ObservedGeneration).To(Equal(int64(1)))
+
+		By("By fixing the rule's principal ID")
+
+		// The timeout is much shorter than the interval between scheduled re-validations, so the
+		// spec change itself must trigger the reconcile that evaluates it.
+		Expect(timeout).To(BeNumerically("<", defaultRequeueAfter))
+		val.Spec.RBACRules[0].PrincipalID = "fixed_p_id"
+		Expect(k8sClient.Update(ctx, val)).Should(Succeed())
+		Eventually(func() int64 {
+			_, observed := observedGeneration()
+			return observed
+		}, timeout, interval).Should(Equal(int64(2)), "failed to evaluate the changed spec")
+
+		Expect(k8sClient.Delete(ctx, val)).Should(Succeed())
+	})
+
+	It("Should finalize a deleted AzureValidator whether or not its ValidationResult still exists", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		newValidator := func(name string) *v1alpha1.AzureValidator {
+			return &v1alpha1.AzureValidator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: validatorNamespace,
+				},
+				Spec: v1alpha1.AzureValidatorSpec{
+					SkipPreflight: true,
+					Auth: v1alpha1.AzureAuth{
+						Implicit: true,
+					},
+					RBACRules: []v1alpha1.RBACRule{
+						{
+							Name: "rule-1",
+							Permissions: []v1alpha1.PermissionSet{
+								{
+									Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+									Actions: []v1alpha1.ActionStr{"action_1"},
+								},
+							},
+							PrincipalID: "p_id",
+						},
+					},
+				},
+			}
+		}
+		withResult := newValidator(fmt.Sprintf("%s-with-result", azureValidatorName))
+		withoutResult := newValidator(fmt.Sprintf("%s-without-result", azureValidatorName))
+		c := newFakeClient(withResult, withoutResult)
+		recorder := record.NewFakeRecorder(10)
+		r := &AzureValidatorReconciler{
+			Client:   c,
+			Log:      ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+			Azure:    azure.options(),
+		}
+
+		for _, val := range []*v1alpha1.AzureValidator{withResult, withoutResult} {
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+			vrKey := validationResultKey(val)
+
+			By("Reconciling until the ValidationResult exists")
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+			Expect(val.Finalizers).To(ContainElement(constants.ValidationResultFinalizer))
+			vr := &vapi.ValidationResult{}
+			Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+			Expect(vr.OwnerReferences).To(ConsistOf(HaveField("UID", val.UID)))
+
+			if val == withoutResult {
+				Expect(c.Delete(ctx, vr)).To(Succeed())
+			}
+
+			By("Deleting the AzureValidator")
+
+			Expect(c.Delete(ctx, val)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrs.IsNotFound(c.Get(ctx, vrKey, &vapi.ValidationResult{}))).To(BeTrue(), "the ValidationResult must be deleted")
+			Expect(apierrs.IsNotFound(c.Get(ctx, req.NamespacedName, &v1alpha1.AzureValidator{}))).To(BeTrue(), "the AzureValidator's deletion must complete")
+
+			if val == withResult {
+				var event string
+				Expect(recorder.Events).To(Receive(&event))
+				Expect(event).To(Equal(fmt.Sprintf("Normal %s Deleted ValidationResult %s, since the AzureValidator is being deleted.", reasonResultDeleted, vrKey)))
+			}
+			Expect(recorder.Events).NotTo(Receive(), "only deleting a ValidationResult records an event")
+		}
+		Expect(azure.requestCount()).To(BeZero(), "only creating the ValidationResult doesn't evaluate rules")
+	})
+
+	It("Should skip rule evaluation while paused and resume once the paused annotation is removed", func() {
+		By("Reconciling an AzureValidator until its rules have been evaluated once")
+
+		ctx := context.Background()
+
+		// A dedicated reconciler backed by a fake client is used so that the shared manager's
+		// reconciler can't contribute to the Azure call count.
+		azureCalls := 0
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-paused", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure: azure_utils.ClientFactoryOptions{
+				Credential: func() (azcore.TokenCredential, error) {
+					azureCalls++
+					return nil, errors.New("Azure is not available in this test")
+				},
+			},
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+		// The first reconcile only creates the ValidationResult. The second evaluates the rules.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		res, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).NotTo(BeZero())
+		Expect(azureCalls).To(Equal(1))
+
+		By("Pausing the AzureValidator")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Annotations = map[string]string{constants.PausedAnnotation: "true"}
+		Expect(c.Update(ctx, val)).To(Succeed())
+
+		for i := 0; i < 2; i++ {
+			res, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{}), "paused AzureValidators must not be requeued")
+		}
+		Expect(azureCalls).To(Equal(1), "Azure must not be contacted while paused")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(val.Status.Conditions, constants.ConditionTypePaused)).To(BeTrue())
+		pausedAt := meta.FindStatusCondition(val.Status.Conditions, constants.ConditionTypePaused).LastTransitionTime
+
+		By("Resuming the AzureValidator")
+
+		delete(val.Annotations, constants.PausedAnnotation)
+		Expect(c.Update(ctx, val)).To(Succeed())
+
+		res, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).NotTo(BeZero())
+		Expect(azureCalls).To(Equal(2))
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		cond := meta.FindStatusCondition(val.Status.Conditions, constants.ConditionTypePaused)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.LastTransitionTime.Before(&pausedAt)).To(BeFalse())
+	})
+
+	It("Should skip rule evaluation, with a condition and an event, while the auth Secret is in a namespace that isn't allowed", func() {
+		ctx := context.Background()
+
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-secret-namespace", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{SecretName: "azure-creds", SecretNamespace: "cloud-creds"},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "azure-creds", Namespace: "cloud-creds"},
+			Data:       map[string][]byte{"AZURE_PLUGIN_TEST_CREDENTIAL": []byte("1")},
+		}
+		c := newFakeClient(val, secret)
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		recorder := record.NewFakeRecorder(10)
+		r := &AzureValidatorReconciler{
+			Client:   c,
+			Log:      ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+			Azure:    azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+		By("Reconciling without allowing the auth Secret's namespace")
+
+		for i := 0; i < 2; i++ {
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{}), "AzureValidators whose auth Secret may not be read must not be requeued")
+		}
+		Expect(azure.requestCount()).To(BeZero(), "rules must not be evaluated")
+		Expect(c.Get(ctx, validationResultKey(val), &vapi.ValidationResult{})).NotTo(Succeed())
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		cond := meta.FindStatusCondition(val.Status.Conditions, constants.ConditionTypeSecretNamespaceAllowed)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Message).To(ContainSubstring("cloud-creds"))
+
+		var event string
+		Expect(recorder.Events).To(Receive(&event))
+		Expect(event).To(HavePrefix("Warning " + reasonSecretNamespaceNotAllowed))
+
+		By("Allowing the auth Secret's namespace")
+
+		r.AllowedSecretNamespaces = []string{"cloud-creds"}
+		for i := 0; i < 2; i++ {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(azure.requestCount()).NotTo(BeZero())
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(val.Status.Conditions, constants.ConditionTypeSecretNamespaceAllowed)).To(BeTrue())
+	})
+
+	It("Should reuse Azure credentials across rules and reconciles until their secret changes", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		rule := func(name string) v1alpha1.RBACRule {
+			return v1alpha1.RBACRule{
+				Name: name,
+				Permissions: []v1alpha1.PermissionSet{
+					{
+						Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+						Actions: []v1alpha1.ActionStr{"action_1"},
+					},
+				},
+				PrincipalID: "p_id",
+			}
+		}
+		implicit := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-implicit-credential", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules:     []v1alpha1.RBACRule{rule("rule-1"), rule("rule-2")},
+			},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "azure-creds-cached",
+				Namespace: validatorNamespace,
+			},
+			Data: map[string][]byte{"AZURE_PLUGIN_TEST_CREDENTIAL": []byte("1")},
+		}
+		explicit := implicit.DeepCopy()
+		explicit.Name = fmt.Sprintf("%s-secret-credential", azureValidatorName)
+		explicit.Spec.Auth = v1alpha1.AzureAuth{SecretName: secret.Name}
+
+		c := newFakeClient(implicit, explicit, secret)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		reconcile := func(val *v1alpha1.AzureValidator) {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		for i := 0; i < 4; i++ {
+			reconcile(implicit)
+		}
+		Expect(azure.requestCount()).NotTo(BeZero())
+		Expect(azure.credentialCount()).To(Equal(1), "rules and reconciles with the same credential must share it")
+
+		By("Reconciling an AzureValidator whose credential is configured from a secret")
+
+		for i := 0; i < 3; i++ {
+			reconcile(explicit)
+		}
+		Expect(azure.credentialCount()).To(Equal(2))
+
+		By("Changing the secret")
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+		secret.Data["AZURE_PLUGIN_TEST_CREDENTIAL"] = []byte("2")
+		Expect(c.Update(ctx, secret)).To(Succeed())
+		reconcile(explicit)
+		reconcile(explicit)
+		Expect(azure.credentialCount()).To(Equal(3), "a changed secret must replace the credential configured from it")
+
+		reconcile(implicit)
+		Expect(azure.credentialCount()).To(Equal(3))
+	})
+
+	It("Should only requeue the AzureValidators that refer to a Secret when it changes", func() {
+		ctx := context.Background()
+
+		var vals []*v1alpha1.AzureValidator
+		var secrets []*corev1.Secret
+		for i := 1; i <= 3; i++ {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("azure-creds-mapped-%d", i),
+					Namespace: validatorNamespace,
+				},
+				Data: map[string][]byte{"AZURE_PLUGIN_TEST_CREDENTIAL": []byte("1")},
+			}
+			val := &v1alpha1.AzureValidator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-mapped-secret-%d", azureValidatorName, i),
+					Namespace: validatorNamespace,
+				},
+				Spec: v1alpha1.AzureValidatorSpec{
+					SkipPreflight: true,
+					Auth:          v1alpha1.AzureAuth{SecretName: secret.Name},
+					RBACRules: []v1alpha1.RBACRule{
+						{
+							Permissions: []v1alpha1.PermissionSet{
+								{
+									Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+									Actions: []v1alpha1.ActionStr{"action_1"},
+								},
+							},
+							PrincipalID: "p_id",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, val)).Should(Succeed())
+			secrets = append(secrets, secret)
+			vals = append(vals, val)
+		}
+		for _, val := range vals {
+			vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, vrKey, &vapi.ValidationResult{})
+			}, timeout, interval).Should(Succeed(), "failed to create a ValidationResult")
+		}
+		requeues := func(val *v1alpha1.AzureValidator) float64 {
+			return testutil.ToFloat64(mappedRequeues.WithLabelValues(val.Namespace, val.Name, "Secret"))
+		}
+		before := make([]float64, len(vals))
+		for i, val := range vals {
+			before[i] = requeues(val)
+		}
+
+		By("Changing the Secret of the second AzureValidator")
+
+		secrets[1].Data["AZURE_PLUGIN_TEST_CREDENTIAL"] = []byte("2")
+		Expect(k8sClient.Update(ctx, secrets[1])).Should(Succeed())
+		Eventually(func() float64 {
+			return requeues(vals[1])
+		}, timeout, interval).Should(Equal(before[1]+1), "the AzureValidator that refers to the Secret must be requeued")
+		Consistently(func() []float64 {
+			return []float64{requeues(vals[0]), requeues(vals[2])}
+		}, 2*time.Second, interval).Should(Equal([]float64{before[0], before[2]}), "AzureValidators that refer to other Secrets must not be requeued")
+	})
+
+	It("Should evaluate rules against the Azure clients configured through the reconciler's options", func() {
+		By("Reconciling an AzureValidator with clients that serve an offline inventory instead of ARM")
+
+		ctx := context.Background()
+
+		const sub = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		inventory := inventoryTransport{
+			"denyAssignments": `{"value": []}`,
+			"roleAssignments": `{"value": [{
+				"id": "` + sub + `/providers/Microsoft.Authorization/roleAssignments/ra",
+				"properties": {"roleDefinitionId": "` + sub + `/providers/Microsoft.Authorization/roleDefinitions/reader"}
+			}]}`,
+			"roleDefinitions": `{
+				"id": "` + sub + `/providers/Microsoft.Authorization/roleDefinitions/reader",
+				"properties": {
+					"roleName": "Reader",
+					"permissions": [{"actions": ["*/read"], "notActions": [], "dataActions": [], "notDataActions": []}]
+				}
+			}`,
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-offline-inventory", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules: []v1alpha1.RBACRule{{
+					Name:        "rule-1",
+					Permissions: []v1alpha1.PermissionSet{{Scope: sub, Actions: []v1alpha1.ActionStr{"Microsoft.Compute/virtualMachines/read", "Microsoft.Compute/virtualMachines/write"}}},
+					PrincipalID: "p_id",
+				}},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure: azure_utils.ClientFactoryOptions{
+				Credential: func() (azcore.TokenCredential, error) { return &azfake.TokenCredential{}, nil },
+				Transport:  inventory,
+				Retry:      policy.RetryOptions{MaxRetries: -1},
+			},
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+		// The first reconcile only creates the ValidationResult.
+		for i := 0; i < 2; i++ {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, validationResultKey(val), vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions).To(HaveLen(1))
+		condition := vr.Status.ValidationConditions[0]
+		Expect(condition.Failures).To(Equal([]string{"Action Microsoft.Compute/virtualMachines/write unpermitted because no role assignment permits it."}))
+		Expect(condition.Details).To(ContainElement("Action Microsoft.Compute/virtualMachines/read at scope " + sub + " permitted by role assignment " + sub + "/providers/Microsoft.Authorization/roleAssignments/ra."))
+	})
+
+	It("Should record rule results and only move a rule's LastTransitionTime when its state changes", func() {
+		By("Reconciling an AzureValidator whose principal has the required permissions")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-rule-results", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+			clock:  clk,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+		// reconcile runs a reconcile at the given time and returns the rule's latest result.
+		reconcile := func(at time.Time) v1alpha1.RuleResult {
+			clk.SetTime(at)
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+			Expect(val.Status.RuleResults).To(HaveLen(1))
+			return val.Status.RuleResults[0]
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		t1 := clk.Now().Add(time.Minute)
+		result := reconcile(t1)
+		Expect(result.Name).To(Equal("rule-1"))
+		Expect(result.ValidationType).To(Equal(constants.ValidationTypeRBAC))
+		Expect(result.State).To(Equal(vapi.ValidationSucceeded))
+		Expect(result.LastEvaluationTime.Time).To(BeTemporally("==", t1))
+		Expect(result.LastTransitionTime.Time).To(BeTemporally("==", t1))
+		Expect(val.Status.LastRunAPIRequests).To(Equal(&v1alpha1.APIRequests{
+			Total:        3,
+			ByClientType: map[string]int{"DenyAssignments": 1, "RoleAssignments": 1, "RoleDefinitions": 1},
+		}), "each ARM request of the evaluation must be counted, by client type")
+
+		By("Reconciling again with an unchanged outcome")
+
+		t2 := t1.Add(time.Minute)
+		result = reconcile(t2)
+		Expect(result.State).To(Equal(vapi.ValidationSucceeded))
+		Expect(result.LastEvaluationTime.Time).To(BeTemporally("==", t2))
+		Expect(result.LastTransitionTime.Time).To(BeTemporally("==", t1), "LastTransitionTime must not move while the state is unchanged")
+
+		By("Reconciling after the principal loses its permissions")
+
+		azure.setActions()
+		t3 := t2.Add(time.Minute)
+		result = reconcile(t3)
+		Expect(result.State).To(Equal(vapi.ValidationFailed))
+		Expect(result.LastTransitionTime.Time).To(BeTemporally("==", t3))
+
+		t4 := t3.Add(time.Minute)
+		result = reconcile(t4)
+		Expect(result.State).To(Equal(vapi.ValidationFailed))
+		Expect(result.LastTransitionTime.Time).To(BeTemporally("==", t3))
+
+		By("Reconciling after the principal regains its permissions")
+
+		azure.setActions("action_1")
+		t5 := t4.Add(time.Minute)
+		result = reconcile(t5)
+		Expect(result.State).To(Equal(vapi.ValidationSucceeded))
+		Expect(result.LastTransitionTime.Time).To(BeTemporally("==", t5))
+	})
+
+	It("Should only patch the ValidationResult when its results change, refreshing its last-validated time less often", func() {
+		By("Reconciling an AzureValidator whose principal lacks one of the required permissions")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-unchanged-result", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1", "action_2"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+
+		// patches counts the patches of the ValidationResult, including its status, but not the
+		// dry-run patches that check whether it's writable.
+		patches := 0
+		c := interceptor.NewClient(newFakeClient(val).(client.WithWatch), interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*vapi.ValidationResult); ok && len((&client.PatchOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					patches++
+				}
+				return c.Patch(ctx, obj, p, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, p client.Patch, opts ...client.SubResourcePatchOption) error {
+				if _, ok := obj.(*vapi.ValidationResult); ok && len((&client.SubResourcePatchOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					patches++
+				}
+				return c.SubResource(subResource).Patch(ctx, obj, p, opts...)
+			},
+		})
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+			clock:  clk,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+		// reconcile runs a reconcile at the given time and returns the ValidationResult, along with
+		// how many times it was patched.
+		reconcile := func(at time.Time) (*vapi.ValidationResult, int) {
+			clk.SetTime(at)
+			patches = 0
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			vr := &vapi.ValidationResult{}
+			Expect(c.Get(ctx, validationResultKey(val), vr)).To(Succeed())
+			return vr, patches
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		t1 := clk.Now().Add(time.Minute)
+		vr, n := reconcile(t1)
+		Expect(n).To(BeNumerically(">", 0))
+		Expect(vr.Status.ValidationConditions).To(HaveLen(1))
+		Expect(vr.Status.ValidationConditions[0].Failures).To(ConsistOf(ContainSubstring("action_2")))
+		Expect(vr.Annotations).To(HaveKeyWithValue(constants.LastValidatedAnnotation, t1.Format(time.RFC3339)))
+		resourceVersion := vr.ResourceVersion
+
+		By("Reconciling again with identical results")
+
+		vr, n = reconcile(t1.Add(time.Minute))
+		Expect(n).To(BeZero(), "identical results must not be patched")
+		Expect(vr.ResourceVersion).To(Equal(resourceVersion))
+		Expect(vr.Annotations).To(HaveKeyWithValue(constants.LastValidatedAnnotation, t1.Format(time.RFC3339)))
+
+		By("Reconciling after the failure changes, though the rule still fails")
+
+		azure.setActions("action_2")
+		t3 := t1.Add(2 * time.Minute)
+		vr, n = reconcile(t3)
+		Expect(n).To(BeNumerically(">", 0))
+		Expect(vr.ResourceVersion).NotTo(Equal(resourceVersion))
+		Expect(vr.Status.State).To(Equal(vapi.ValidationFailed))
+		Expect(vr.Status.ValidationConditions[0].Failures).To(ConsistOf(ContainSubstring("action_1")))
+		Expect(vr.Annotations).To(HaveKeyWithValue(constants.LastValidatedAnnotation, t3.Format(time.RFC3339)))
+		lastValidation := vr.Status.ValidationConditions[0].LastValidationTime
+
+		By("Reconciling with identical results once the last-validated time is due for a refresh")
+
+		t4 := t3.Add(lastValidatedRefreshInterval)
+		vr, n = reconcile(t4)
+		Expect(n).To(BeNumerically(">", 0))
+		Expect(vr.Annotations).To(HaveKeyWithValue(constants.LastValidatedAnnotation, t4.Format(time.RFC3339)))
+		Expect(vr.Status.ValidationConditions[0].LastValidationTime).To(Equal(lastValidation), "only the annotation may change")
+	})
+
+	DescribeTable("Deciding which AzureValidator changes trigger a reconcile",
+		func(change func(val *v1alpha1.AzureValidator), want bool) {
+			old := &v1alpha1.AzureValidator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       azureValidatorName,
+					Namespace:  validatorNamespace,
+					Generation: 1,
+				},
+			}
+			val := old.DeepCopy()
+			change(val)
+			Expect(reconcileTriggers().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: val})).To(Equal(want))
+		},
+		Entry("triggers a reconcile when the spec changes", func(val *v1alpha1.AzureValidator) {
+			val.Spec.RBACRules = []v1alpha1.RBACRule{{Name: "rule-1"}}
+			val.Generation++
+		}, true),
+		Entry("triggers a reconcile when the paused annotation changes", func(val *v1alpha1.AzureValidator) {
+			val.Annotations = map[string]string{constants.PausedAnnotation: "true"}
+		}, true),
+		Entry("doesn't trigger a reconcile when only the status changes", func(val *v1alpha1.AzureValidator) {
+			val.Status.ObservedGeneration = 1
+			val.Status.RuleResults = []v1alpha1.RuleResult{{Name: "rule-1", State: vapi.ValidationSucceeded}}
+		}, false),
+		Entry("doesn't trigger a reconcile when only a finalizer is added", func(val *v1alpha1.AzureValidator) {
+			val.Finalizers = []string{constants.ValidationResultFinalizer}
+		}, false),
+	)
+
+	It("Should trigger a reconcile when an AzureValidator is created", func() {
+		val := &v1alpha1.AzureValidator{ObjectMeta: metav1.ObjectMeta{Name: azureValidatorName, Namespace: validatorNamespace}}
+		Expect(reconcileTriggers().Create(event.CreateEvent{Object: val})).To(BeTrue())
+	})
+
+	It("Should evaluate rules in dry-run mode without touching the ValidationResult, and persist them once the annotation is removed", func() {
+		By("Reconciling a new AzureValidator in dry-run mode")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("%s-dry-run", azureValidatorName),
+				Namespace:   validatorNamespace,
+				Annotations: map[string]string{constants.DryRunAnnotation: "true"},
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1", "action_2"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+
+		// writes counts the writes of the ValidationResult, including its status, but not the
+		// dry-run requests that check whether it's writable.
+		writes := 0
+		c := interceptor.NewClient(newFakeClient(val).(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*vapi.ValidationResult); ok && len((&client.CreateOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					writes++
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*vapi.ValidationResult); ok && len((&client.PatchOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					writes++
+				}
+				return c.Patch(ctx, obj, p, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, p client.Patch, opts ...client.SubResourcePatchOption) error {
+				if _, ok := obj.(*vapi.ValidationResult); ok && len((&client.SubResourcePatchOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					writes++
+				}
+				return c.SubResource(subResource).Patch(ctx, obj, p, opts...)
+			},
+		})
+		recorder := record.NewFakeRecorder(10)
+		r := &AzureValidatorReconciler{
+			Client:   c,
+			Log:      ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+			Azure:    azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := validationResultKey(val)
+
+		// reconcile runs a reconcile, and returns the AzureValidator and how many times the
+		// ValidationResult was written.
+		reconcile := func() (*v1alpha1.AzureValidator, int) {
+			writes = 0
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).NotTo(BeZero())
+			val := &v1alpha1.AzureValidator{}
+			Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+			return val, writes
+		}
+		setDryRun := func(dryRun bool) {
+			val := &v1alpha1.AzureValidator{}
+			Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+			if dryRun {
+				val.Annotations = map[string]string{constants.DryRunAnnotation: "true"}
+			} else {
+				delete(val.Annotations, constants.DryRunAnnotation)
+			}
+			Expect(c.Update(ctx, val)).To(Succeed())
+		}
+		expectEvent := func(substr string) {
+			var event string
+			Expect(recorder.Events).To(Receive(&event))
+			Expect(event).To(HavePrefix("Normal " + reasonDryRun))
+			Expect(event).To(ContainSubstring(substr))
+		}
+
+		got, n := reconcile()
+		Expect(n).To(BeZero(), "the ValidationResult must not be created in dry-run mode")
+		Expect(apierrs.IsNotFound(c.Get(ctx, vrKey, &vapi.ValidationResult{}))).To(BeTrue())
+		Expect(got.Status.DryRun).NotTo(BeNil())
+		Expect(got.Status.DryRun.State).To(Equal(vapi.ValidationFailed))
+		Expect(got.Status.DryRun.ObservedGeneration).To(Equal(got.Generation))
+		Expect(got.Status.DryRun.Conditions).To(HaveLen(1))
+		Expect(got.Status.DryRun.Conditions[0].Failures).To(ConsistOf(ContainSubstring("action_2")))
+		Expect(got.Status.RuleResults).To(BeEmpty(), "dry-run outcomes aren't rule results")
+		expectEvent("would be Failed, with 1 of 1 rule(s) failing")
+
+		By("Removing the dry-run annotation")
+
+		setDryRun(false)
+		// The first reconcile creates the ValidationResult. The second writes the results to it.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		got, n = reconcile()
+		Expect(n).To(BeNumerically(">", 0))
+		Expect(got.Status.DryRun).To(BeNil(), "the dry-run outcome must be cleared once results are written")
+		Expect(got.Status.RuleResults).To(HaveLen(1))
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.State).To(Equal(vapi.ValidationFailed))
+		Expect(vr.Status.ValidationConditions[0].Failures).To(ConsistOf(ContainSubstring("action_2")))
+		written := vr.DeepCopy()
+
+		By("Granting the missing permission while in dry-run mode")
+
+		azure.setActions("action_1", "action_2")
+		setDryRun(true)
+		got, n = reconcile()
+		Expect(n).To(BeZero(), "the ValidationResult must not be written in dry-run mode")
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr).To(Equal(written))
+		Expect(got.Status.DryRun).NotTo(BeNil())
+		Expect(got.Status.DryRun.State).To(Equal(vapi.ValidationSucceeded))
+		Expect(got.Status.DryRun.Conditions[0].Failures).To(BeEmpty())
+		Expect(got.Status.RuleResults[0].State).To(Equal(vapi.ValidationFailed), "rule results must reflect the ValidationResult")
+		expectEvent("would be Succeeded, with 0 of 1 rule(s) failing")
+
+		By("Removing the dry-run annotation again")
+
+		setDryRun(false)
+		got, n = reconcile()
+		Expect(n).To(BeNumerically(">", 0))
+		Expect(got.Status.DryRun).To(BeNil())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.State).To(Equal(vapi.ValidationSucceeded))
+		Expect(recorder.Events).NotTo(Receive(), "no dry-run events are recorded outside of dry-run mode")
+	})
+
+	It("Should report RBAC rules lacking permissions as pending within their grace period, and fail them once it ends", func() {
+		By("Reconciling an AzureValidator whose principal lacks a permission, with and without a grace period")
+
+		ctx := context.Background()
+
+		// The fake clock starts after the AzureValidator is created, so that the grace periods
+		// start when the rules are first evaluated.
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		t0 := time.Now().Add(time.Second).Truncate(time.Second)
+		clk := clocktesting.NewFakePassiveClock(t0)
+		permissions := []v1alpha1.PermissionSet{
+			{
+				Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+				Actions: []v1alpha1.ActionStr{"action_1", "action_2"},
+			},
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-grace-period", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{Name: "rule-1", Permissions: permissions, PrincipalID: "p_id", GracePeriod: &metav1.Duration{Duration: 5 * time.Minute}},
+					{Name: "rule-2", Permissions: permissions, PrincipalID: "p_id"},
+				},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+			clock:  clk,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+		// reconcile runs a reconcile at the given time, and returns when it requeues, the conditions
+		// of the rules, and the RuleResult of rule-1.
+		reconcile := func(at time.Time) (time.Duration, []vapi.ValidationCondition, v1alpha1.RuleResult) {
+			clk.SetTime(at)
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			vr := &vapi.ValidationResult{}
+			Expect(c.Get(ctx, validationResultKey(val), vr)).To(Succeed())
+			Expect(vr.Status.ValidationConditions).To(HaveLen(2))
+			Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+			Expect(val.Status.RuleResults).To(HaveLen(2))
+			return res.RequeueAfter, vr.Status.ValidationConditions, val.Status.RuleResults[0]
+		}
+		expectPending := func(c vapi.ValidationCondition) {
+			Expect(c.Status).To(Equal(corev1.ConditionUnknown))
+			Expect(c.Message).To(Equal(messages.RBACPending))
+			Expect(c.Failures).To(ConsistOf(ContainSubstring("action_2")))
+		}
+		expectFailed := func(c vapi.ValidationCondition) {
+			Expect(c.Status).To(Equal(corev1.ConditionFalse))
+			Expect(c.Message).To(Equal(messages.RBACFailed))
+			Expect(c.Failures).To(ConsistOf(ContainSubstring("action_2")))
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		end := t0.Add(5 * time.Minute)
+		after, conditions, result := reconcile(t0)
+		expectPending(conditions[0])
+		Expect(conditions[0].Details).To(ContainElement(ContainSubstring(end.UTC().Format(time.RFC3339))))
+		expectFailed(conditions[1])
+		Expect(result.State).To(Equal(vapi.ValidationInProgress))
+		Expect(result.PendingUntil).NotTo(BeNil())
+		Expect(result.PendingUntil.Time).To(BeTemporally("==", end))
+		Expect(after).To(Equal(pendingRequeueAfter), "pending rules must be re-validated sooner")
+
+		By("Reconciling within the grace period")
+
+		after, conditions, result = reconcile(t0.Add(2 * time.Minute))
+		expectPending(conditions[0])
+		Expect(result.PendingUntil.Time).To(BeTemporally("==", end), "re-validating must not extend the grace period")
+		Expect(after).To(Equal(pendingRequeueAfter))
+
+		after, _, _ = reconcile(end.Add(-10 * time.Second))
+		Expect(after).To(Equal(10*time.Second), "the rule must be re-validated as soon as the grace period ends")
+
+		By("Reconciling once the grace period has ended")
+
+		after, conditions, result = reconcile(end)
+		expectFailed(conditions[0])
+		Expect(result.State).To(Equal(vapi.ValidationFailed))
+		Expect(result.PendingUntil).To(BeNil())
+		Expect(after).To(Equal(defaultRequeueAfter))
+
+		after, conditions, _ = reconcile(end.Add(time.Minute))
+		expectFailed(conditions[0])
+		Expect(after).To(Equal(defaultRequeueAfter))
+
+		By("Reconciling after the rule passed, once the permission is missing again")
+
+		azure.setActions("action_1", "action_2")
+		passed := end.Add(2 * time.Minute)
+		_, conditions, result = reconcile(passed)
+		Expect(conditions[0].Status).To(Equal(corev1.ConditionTrue))
+		Expect(result.State).To(Equal(vapi.ValidationSucceeded))
+
+		azure.setActions("action_1")
+		_, conditions, result = reconcile(passed.Add(2 * time.Minute))
+		expectPending(conditions[0])
+		Expect(result.PendingUntil.Time).To(BeTemporally("==", passed.Add(5*time.Minute)), "the grace period starts when the rule last passed")
+
+		_, conditions, _ = reconcile(passed.Add(5 * time.Minute))
+		expectFailed(conditions[0])
+	})
+
+	DescribeTable("Deciding whether a rule's previous result can be reused",
+		func(prev *v1alpha1.RuleResult, hash string, generation int64, maxAge *metav1.Duration, want bool) {
+			now := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+			Expect(canReuseResult(prev, hash, generation, maxAge, now)).To(Equal(want))
+		},
+		Entry("reuses a fresh result of an unchanged rule",
+			&v1alpha1.RuleResult{Hash: "h", ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))},
+			"h", int64(1), &metav1.Duration{Duration: time.Hour}, true,
+		),
+		Entry("re-evaluates a stale result",
+			&v1alpha1.RuleResult{Hash: "h", ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+			"h", int64(1), &metav1.Duration{Duration: time.Hour}, false,
+		),
+		Entry("re-evaluates a changed rule",
+			&v1alpha1.RuleResult{Hash: "h", ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))},
+			"h2", int64(1), &metav1.Duration{Duration: time.Hour}, false,
+		),
+		Entry("re-evaluates after the spec changes",
+			&v1alpha1.RuleResult{Hash: "h", ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))},
+			"h", int64(2), &metav1.Duration{Duration: time.Hour}, false,
+		),
+		Entry("re-evaluates a result whose evaluation failed with an error",
+			&v1alpha1.RuleResult{ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))},
+			"h", int64(1), &metav1.Duration{Duration: time.Hour}, false,
+		),
+		Entry("re-evaluates a rule that was pending within its grace period",
+			&v1alpha1.RuleResult{Hash: "h", State: vapi.ValidationInProgress, ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))},
+			"h", int64(1), &metav1.Duration{Duration: time.Hour}, false,
+		),
+		Entry("re-evaluates when result reuse is disabled",
+			&v1alpha1.RuleResult{Hash: "h", ObservedGeneration: 1, LastEvaluationTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))},
+			"h", int64(1), nil, false,
+		),
+		Entry("evaluates a rule that has never been evaluated",
+			nil, "h", int64(1), &metav1.Duration{Duration: time.Hour}, false,
+		),
+	)
+
+	It("Should reuse a rule's previous result until it's older than resultMaxAge", func() {
+		By("Reconciling an AzureValidator with resultMaxAge set")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-result-max-age", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth: v1alpha1.AzureAuth{
+					Implicit: true,
+				},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+				ResultMaxAge: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+			clock:  clk,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vr := &vapi.ValidationResult{}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// reconcile runs a reconcile at the given time and returns the rule's latest condition.
+		reconcile := func(at time.Time) vapi.ValidationCondition {
+			clk.SetTime(at)
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+			Expect(vr.Status.ValidationConditions).To(HaveLen(1))
+			return vr.Status.ValidationConditions[0]
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		t1 := clk.Now()
+		cond := reconcile(t1)
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		Expect(cond.Details).To(ContainElement(HavePrefix("Examined ")), "evaluated results carry evidence")
+		Expect(cond.Details[len(cond.Details)-1]).To(Equal("Severity: High"), "the severity detail comes last")
+		evidence := cond.Details[:len(cond.Details)-1]
+		requests := azure.requestCount()
+		Expect(requests).NotTo(BeZero())
+
+		By("Reconciling again before the result is older than resultMaxAge")
+
+		cond = reconcile(t1.Add(5 * time.Minute))
+		Expect(azure.requestCount()).To(Equal(requests), "Azure must not be queried for a reusable result")
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		Expect(cond.Details).To(HaveLen(len(evidence) + 2))
+		Expect(cond.Details[:len(evidence)]).To(Equal(evidence), "reused results keep their evidence")
+		Expect(cond.Details[len(evidence)]).To(HavePrefix(reusedResultDetail))
+		Expect(cond.Details[len(evidence)+1]).To(Equal("Severity: High"))
+
+		cond = reconcile(t1.Add(6 * time.Minute))
+		Expect(azure.requestCount()).To(Equal(requests))
+		Expect(cond.Details).To(HaveLen(len(evidence)+2), "reuse details must not accumulate")
+
+		By("Reconciling after the result is older than resultMaxAge")
+
+		cond = reconcile(t1.Add(11 * time.Minute))
+		Expect(azure.requestCount()).To(BeNumerically(">", requests))
+		Expect(cond.Details).NotTo(ContainElement(HavePrefix(reusedResultDetail)))
+		requests = azure.requestCount()
+
+		By("Reconciling after the rule changes")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.RBACRules[0].PrincipalID = "p_id_2"
+		Expect(c.Update(ctx, val)).To(Succeed())
+		reconcile(t1.Add(12 * time.Minute))
+		Expect(azure.requestCount()).To(BeNumerically(">", requests), "a changed rule must be re-evaluated")
+	})
+
+	It("Should report the rules it didn't evaluate before the run deadline, and evaluate them first next time", func() {
+		By("Reconciling an AzureValidator with four RBAC rules that each take a minute to evaluate")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		rules := make([]v1alpha1.RBACRule, 0, 4)
+		for i := 1; i <= 4; i++ {
+			rules = append(rules, v1alpha1.RBACRule{
+				Name: fmt.Sprintf("rule-%d", i),
+				Permissions: []v1alpha1.PermissionSet{{
+					Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+					Actions: []v1alpha1.ActionStr{"action_1"},
+				}},
+				PrincipalID: fmt.Sprintf("p_id_%d", i),
+			})
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-run-deadline", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules:     rules,
+				RunDeadline:   &metav1.Duration{Duration: 90 * time.Second},
+			},
+		}
+		c := newFakeClient(val)
+		options := azure.options()
+		options.Transport = slowTransport{Transporter: azure, clk: clk}
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  options,
+			clock:  clk,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vr := &vapi.ValidationResult{}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+		notEvaluated := fmt.Sprintf(messages.RunDeadlineFailure, 90*time.Second)
+
+		// reconcile runs a reconcile and returns when it requeues, the rules' latest conditions,
+		// and which rules weren't evaluated.
+		reconcile := func() (time.Duration, []vapi.ValidationCondition, []string) {
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+			Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+			var skipped []string
+			for _, rr := range val.Status.RuleResults {
+				if rr.NotEvaluated {
+					skipped = append(skipped, rr.Name)
+				}
+			}
+			return res.RequeueAfter, vr.Status.ValidationConditions, skipped
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		requeueAfter, conditions, skipped := reconcile()
+		Expect(conditions).To(HaveLen(4))
+		for i, cond := range conditions[:2] {
+			Expect(cond.ValidationRule).To(HaveSuffix(rules[i].Name))
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue), "rules evaluated before the deadline keep their results")
+		}
+		for i, cond := range conditions[2:] {
+			Expect(cond.ValidationRule).To(HaveSuffix(rules[i+2].Name))
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Message).To(Equal(messages.NotEvaluated))
+			Expect(cond.Failures).To(Equal([]string{notEvaluated}))
+		}
+		Expect(skipped).To(Equal([]string{"rule-3", "rule-4"}))
+		Expect(requeueAfter).To(Equal(notEvaluatedRequeueAfter))
+
+		By("Reconciling again, which evaluates the skipped rules first")
+
+		requeueAfter, conditions, skipped = reconcile()
+		Expect(conditions).To(HaveLen(4), "conditions stay in the order of the spec's rules")
+		for i, cond := range conditions {
+			Expect(cond.ValidationRule).To(HaveSuffix(rules[i].Name))
+		}
+		Expect(conditions[2].Status).To(Equal(corev1.ConditionTrue))
+		Expect(conditions[3].Status).To(Equal(corev1.ConditionTrue))
+		Expect(conditions[0].Failures).To(Equal([]string{notEvaluated}))
+		Expect(conditions[1].Failures).To(Equal([]string{notEvaluated}))
+		Expect(skipped).To(Equal([]string{"rule-1", "rule-2"}))
+		Expect(requeueAfter).To(Equal(notEvaluatedRequeueAfter))
+
+		By("Reconciling without a run deadline")
+
+		val.Spec.RunDeadline = nil
+		Expect(c.Update(ctx, val)).To(Succeed())
+		requeueAfter, conditions, skipped = reconcile()
+		for _, cond := range conditions {
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		}
+		Expect(skipped).To(BeEmpty())
+		Expect(requeueAfter).To(Equal(defaultRequeueAfter))
+	})
+
+	It("Should re-validate the AzureValidators affected by role assignment changes, without reusing their results", func() {
+		By("Reconciling AzureValidators that revalidate on role assignment changes")
+
+		ctx := context.Background()
+
+		const sub = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		newValidator := func(name, principalID, scope string, optIn bool) *v1alpha1.AzureValidator {
+			return &v1alpha1.AzureValidator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       fmt.Sprintf("%s-%s", azureValidatorName, name),
+					Namespace:  validatorNamespace,
+					Generation: 1,
+				},
+				Spec: v1alpha1.AzureValidatorSpec{
+					SkipPreflight: true,
+					Auth: v1alpha1.AzureAuth{
+						Implicit: true,
+					},
+					RBACRules: []v1alpha1.RBACRule{
+						{
+							Name: "rule-1",
+							Permissions: []v1alpha1.PermissionSet{
+								{
+									Scope:   scope,
+									Actions: []v1alpha1.ActionStr{"action_1"},
+								},
+							},
+							PrincipalID: principalID,
+						},
+					},
+					ResultMaxAge:                      &metav1.Duration{Duration: time.Hour},
+					RevalidateOnRoleAssignmentChanges: optIn,
+				},
+			}
+		}
+		byPrincipal := newValidator("by-principal", "p_id", sub+"/resourceGroups/rg-a", true)
+		byScope := newValidator("by-scope", "p_id_2", sub+"/resourceGroups/rg-b/providers/Microsoft.Storage/storageAccounts/sa", true)
+		unaffected := newValidator("unaffected", "p_id_3", sub+"/resourceGroups/rg-c", true)
+		optedOut := newValidator("opted-out", "p_id", sub+"/resourceGroups/rg-a", false)
+		validators := []*v1alpha1.AzureValidator{byPrincipal, byScope, unaffected, optedOut}
+		c := newFakeClient(byPrincipal, byScope, unaffected, optedOut)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+			clock:  clk,
+		}
+		reconcile := func(val *v1alpha1.AzureValidator) {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		for _, val := range validators {
+			// The first reconcile only creates the ValidationResult.
+			reconcile(val)
+			reconcile(val)
+		}
+		requests := azure.requestCount()
+		reconcile(byPrincipal)
+		Expect(azure.requestCount()).To(Equal(requests), "the result is reused until a role assignment changes")
+
+		By("Polling the Activity Log for role assignment changes")
+
+		at := clk.Now().Add(-time.Minute)
+		azure.setActivityLogEvents(
+			// Matches byPrincipal by principal.
+			activityLogEvent("ra-1", "Microsoft.Authorization/roleAssignments/write", "Succeeded", sub+"/resourceGroups/rg-z", "p_id", at),
+			// Matches byScope, because the role assignment is at a scope containing its rule's scope.
+			activityLogEvent("ra-2", "Microsoft.Authorization/roleAssignments/delete", "Succeeded", sub+"/resourceGroups/rg-b", "p_id_9", at),
+			// Neither succeeded, nor is a role assignment change, nor contains a rule's scope.
+			activityLogEvent("ra-3", "Microsoft.Authorization/roleAssignments/write", "Started", sub+"/resourceGroups/rg-c", "p_id_3", at),
+			activityLogEvent("rd-1", "Microsoft.Authorization/roleDefinitions/write", "Succeeded", sub+"/resourceGroups/rg-c", "p_id_3", at),
+			activityLogEvent("ra-4", "Microsoft.Authorization/roleAssignments/write", "Succeeded", sub+"/resourceGroups/rg-b/providers/Microsoft.Storage/storageAccounts/sa-2", "p_id_9", at),
+		)
+		events := make(chan event.GenericEvent, len(validators))
+		poller := newRoleAssignmentPoller(r, time.Minute, events)
+		requeued := func() []string {
+			var names []string
+			for {
+				select {
+				case e := <-events:
+					names = append(names, e.Object.GetName())
+				default:
+					return names
+				}
+			}
+		}
+		poller.poll(ctx)
+		Expect(requeued()).To(ConsistOf(byPrincipal.Name, byScope.Name))
+
+		By("Reconciling an affected AzureValidator")
+
+		requests = azure.requestCount()
+		reconcile(byPrincipal)
+		Expect(azure.requestCount()).To(BeNumerically(">", requests), "the rule must be re-evaluated after a role assignment change")
+		requests = azure.requestCount()
+		reconcile(byPrincipal)
+		Expect(azure.requestCount()).To(Equal(requests), "the new result is reused again")
+
+		By("Polling again")
+
+		poller.poll(ctx)
+		Expect(requeued()).To(BeEmpty(), "events must only be handled once")
+	})
+
+	It("Should propagate result labels to the ValidationResult and rule labels to conditions, and re-apply them on each reconcile", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-result-labels", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				ResultLabels:  map[string]string{"team": "payments", "tier": "critical"},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name:   "rule-1",
+						Labels: map[string]string{"team": "platform", "owner": "alice"},
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vr := &vapi.ValidationResult{}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Labels).To(Equal(map[string]string{"team": "payments", "tier": "critical"}), "the ValidationResult must be created with the result labels")
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions).To(HaveLen(1))
+		labelDetails := []string{"Label: owner=alice", "Label: team=platform", "Label: tier=critical"}
+		Expect(vr.Status.ValidationConditions[0].Details).To(ContainElements(labelDetails), "rule labels must win over result labels")
+		details := vr.Status.ValidationConditions[0].Details
+
+		By("Editing the ValidationResult's labels")
+
+		vr.Labels = map[string]string{"team": "edited", "other": "kept"}
+		Expect(c.Update(ctx, vr)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Labels).To(Equal(map[string]string{"team": "payments", "tier": "critical", "other": "kept"}))
+		Expect(vr.Status.ValidationConditions[0].Details).To(Equal(details), "label details must not accumulate")
+	})
+
+	It("Should report each rule's severity in its condition, and only fail the ValidationResult for failing rules that aren't warnings", func() {
+		By("Reconciling an AzureValidator whose failing rules are a Low rule and one of the Medium default severity")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		rbacRule := func(name, action string, severity v1alpha2.Severity) v1alpha2.RBACRule {
+			return v1alpha2.RBACRule{
+				RBACRule: v1alpha1.RBACRule{
+					Name: name,
+					Permissions: []v1alpha1.PermissionSet{{
+						Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+						Actions: []v1alpha1.ActionStr{v1alpha1.ActionStr(action)},
+					}},
+					PrincipalID: "p_id",
+				},
+				SubscriptionRuleMeta: v1alpha2.SubscriptionRuleMeta{RuleMeta: v1alpha2.RuleMeta{Severity: severity}},
+			}
+		}
+		spoke := &v1alpha2.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-severity", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha2.AzureValidatorSpec{
+				SkipPreflight:   true,
+				Auth:            v1alpha1.AzureAuth{Implicit: true},
+				DefaultSeverity: v1alpha2.SeverityMedium,
+				RBAC: v1alpha2.RBACCategory{RBACRules: []v1alpha2.RBACRule{
+					rbacRule("rule-1", "action_1", v1alpha2.SeverityCritical),
+					rbacRule("rule-2", "action_2", v1alpha2.SeverityLow),
+					rbacRule("rule-3", "action_2", ""),
+				}},
+			},
+		}
+		val := &v1alpha1.AzureValidator{}
+		Expect(spoke.ConvertTo(val)).To(Succeed())
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vr := &vapi.ValidationResult{}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions).To(HaveLen(3))
+		for i, want := range []struct {
+			status   corev1.ConditionStatus
+			severity string
+		}{
+			{corev1.ConditionTrue, "Severity: Critical"},
+			{corev1.ConditionFalse, "Severity: Low"},
+			{corev1.ConditionFalse, "Severity: Medium"},
+		} {
+			cond := vr.Status.ValidationConditions[i]
+			Expect(cond.Status).To(Equal(want.status))
+			Expect(cond.Details).To(ContainElement(want.severity))
+		}
+		Expect(vr.Status.State).To(Equal(vapi.ValidationSucceeded), "failing warnings don't fail the ValidationResult")
+
+		By("Removing the default severity, so that the rule without a severity of its own is High")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.DefaultSeverity = ""
+		Expect(c.Update(ctx, val)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions[2].Details).To(ContainElement("Severity: High"))
+		Expect(vr.Status.ValidationConditions[2].Details).NotTo(ContainElement("Severity: Medium"), "severity details must not accumulate")
+		Expect(vr.Status.State).To(Equal(vapi.ValidationFailed))
+	})
+
+	It("Should fail each rule's own condition, with its labels and severity, when its clients can't be created", func() {
+		ctx := context.Background()
+
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-client-errors", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight:   true,
+				Auth:            v1alpha1.AzureAuth{Implicit: true},
+				DefaultSeverity: v1alpha1.SeverityLow,
+				RBACRules: []v1alpha1.RBACRule{{
+					Name:   "rule-1",
+					Labels: map[string]string{"team": "platform"},
+					Permissions: []v1alpha1.PermissionSet{{
+						Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+						Actions: []v1alpha1.ActionStr{"action_1"},
+					}},
+					PrincipalID: "p_id",
+				}},
+				DefenderPlanRules: []v1alpha1.DefenderPlanRule{{
+					Name:           "rule-2",
+					SubscriptionID: "00000000-0000-0000-0000-000000000000",
+					Plans:          []v1alpha1.ExpectedDefenderPlan{{Name: "VirtualMachines", PricingTier: "Standard"}},
+				}},
+			},
+		}
+		c := newFakeClient(val)
+		azure := &fakeAzure{}
+		opts := azure.options()
+		// A cloud without an ARM endpoint, with which no ARM client can be created.
+		opts.Cloud = cloud.Configuration{ActiveDirectoryAuthorityHost: cloud.AzurePublic.ActiveDirectoryAuthorityHost}
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  opts,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vr := &vapi.ValidationResult{}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions).To(HaveLen(2))
+		for i, want := range []struct {
+			validationRule string
+			validationType string
+			client         string
+		}{
+			{"validation-rule-1", constants.ValidationTypeRBAC, "DenyAssignments"},
+			{"validation-rule-2", constants.ValidationTypeDefenderPlan, "Pricings"},
+		} {
+			cond := vr.Status.ValidationConditions[i]
+			Expect(cond.ValidationRule).To(Equal(want.validationRule))
+			Expect(cond.ValidationType).To(Equal(want.validationType))
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Failures).To(ConsistOf(HavePrefix(fmt.Sprintf("failed to create Azure %s client", want.client))))
+			Expect(cond.Details).To(ContainElement("Severity: Low"))
+		}
+		Expect(vr.Status.ValidationConditions[0].Details).To(ContainElement("Label: team=platform"))
+		Expect(vr.Status.State).To(Equal(vapi.ValidationSucceeded), "failing warnings don't fail the ValidationResult")
+	})
+
+	It("Should write a snapshot of what the rules observed to a ConfigMap, up to its maxBytes", func() {
+		By("Reconciling an AzureValidator with a snapshot ConfigMap, which has another key already")
+
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		rbacRule := func(name string) v1alpha1.RBACRule {
+			return v1alpha1.RBACRule{
+				Name: name,
+				Permissions: []v1alpha1.PermissionSet{{
+					Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+					Actions: []v1alpha1.ActionStr{"action_1"},
+				}},
+				PrincipalID: "p_id",
+			}
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-snapshot", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules:     []v1alpha1.RBACRule{rbacRule("rule-1"), rbacRule("rule-2")},
+				Snapshot:      &v1alpha1.SnapshotConfig{ConfigMapName: "snapshot"},
+			},
+		}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: validatorNamespace},
+			Data:       map[string]string{"notes": "kept"},
+		}
+		c := newFakeClient(val, cm)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		cmKey := types.NamespacedName{Name: "snapshot", Namespace: validatorNamespace}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, cmKey, cm)).To(Succeed())
+		Expect(cm.Data).To(HaveKeyWithValue("notes", "kept"))
+		Expect(cm.Data).To(HaveKey(snapshotDataKey))
+		Expect(strings.ToLower(cm.Data[snapshotDataKey])).NotTo(ContainSubstring("bearer"), "snapshots must never include credentials")
+		doc := snapshotDocument{}
+		Expect(json.Unmarshal([]byte(cm.Data[snapshotDataKey]), &doc)).To(Succeed())
+		Expect(doc.Name).To(Equal(val.Name))
+		Expect(doc.OmittedResponses).To(BeZero())
+		rules := map[string]bool{}
+		for _, resp := range doc.Responses {
+			Expect(resp.ValidationType).To(Equal(constants.ValidationTypeRBAC))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Body).NotTo(BeEmpty())
+			rules[resp.RuleName] = true
+		}
+		Expect(rules).To(Equal(map[string]bool{"rule-1": true, "rule-2": true}), "each rule contributes what it observed")
+		Expect(doc.Responses).To(ContainElement(HaveField("URL", ContainSubstring("/roleAssignments"))))
+		full := len(cm.Data[snapshotDataKey])
+
+		By("Capping the snapshot's size with maxBytes")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.Snapshot.MaxBytes = 1024
+		Expect(full).To(BeNumerically(">", val.Spec.Snapshot.MaxBytes), "the uncapped snapshot must exceed the cap for this test")
+		Expect(c.Update(ctx, val)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, cmKey, cm)).To(Succeed())
+		doc = snapshotDocument{}
+		Expect(json.Unmarshal([]byte(cm.Data[snapshotDataKey]), &doc)).To(Succeed())
+		Expect(doc.OmittedResponses).To(BeNumerically(">", 0))
+		responses, err := json.Marshal(doc.Responses)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(responses)).To(BeNumerically("<=", val.Spec.Snapshot.MaxBytes))
+
+		By("Not failing validation if the snapshot can't be written")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.Snapshot = &v1alpha1.SnapshotConfig{BlobContainerURL: "https://account.blob.core.windows.net/snapshots"}
+		Expect(c.Update(ctx, val)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}, vr)).To(Succeed())
+		Expect(vr.Status.State).To(Equal(vapi.ValidationSucceeded))
+	})
+
+	DescribeTable("Deciding the state of a ValidationResult from the severities of its conditions",
+		func(want vapi.ValidationState, conditions ...vapi.ValidationCondition) {
+			// The validator library fails the ValidationResult if any condition failed.
+			vr := &vapi.ValidationResult{Status: vapi.ValidationResultStatus{State: vapi.ValidationSucceeded, ValidationConditions: conditions}}
+			for _, c := range conditions {
+				if c.Status == corev1.ConditionFalse {
+					vr.Status.State = vapi.ValidationFailed
+				}
+			}
+			applySeverities(vr)
+			Expect(vr.Status.State).To(Equal(want))
+		},
+		Entry("succeeds when all rules succeed", vapi.ValidationSucceeded,
+			severityCondition(corev1.ConditionTrue, v1alpha1.SeverityCritical), severityCondition(corev1.ConditionTrue, v1alpha1.SeverityLow),
+		),
+		Entry("succeeds when only warnings fail", vapi.ValidationSucceeded,
+			severityCondition(corev1.ConditionFalse, v1alpha1.SeverityLow), severityCondition(corev1.ConditionTrue, v1alpha1.SeverityHigh), severityCondition(corev1.ConditionFalse, v1alpha1.SeverityMedium),
+		),
+		Entry("succeeds when all rules fail as warnings", vapi.ValidationSucceeded,
+			severityCondition(corev1.ConditionFalse, v1alpha1.SeverityLow), severityCondition(corev1.ConditionFalse, v1alpha1.SeverityMedium),
+		),
+		Entry("fails when a High rule fails among warnings", vapi.ValidationFailed,
+			severityCondition(corev1.ConditionFalse, v1alpha1.SeverityLow), severityCondition(corev1.ConditionFalse, v1alpha1.SeverityHigh), severityCondition(corev1.ConditionTrue, v1alpha1.SeverityCritical),
+		),
+		Entry("fails when a Critical rule fails", vapi.ValidationFailed,
+			severityCondition(corev1.ConditionTrue, v1alpha1.SeverityLow), severityCondition(corev1.ConditionFalse, v1alpha1.SeverityCritical),
+		),
+		Entry("fails when a condition without a severity fails", vapi.ValidationFailed,
+			severityCondition(corev1.ConditionFalse, v1alpha1.SeverityLow), vapi.ValidationCondition{Status: corev1.ConditionFalse},
+		),
+		Entry("is in progress when warnings fail and the other rules are pending", vapi.ValidationInProgress,
+			severityCondition(corev1.ConditionFalse, v1alpha1.SeverityMedium), severityCondition(corev1.ConditionUnknown, v1alpha1.SeverityHigh),
+		),
+	)
+
+	It("Should produce identical conditions, in the order of the spec's rules, no matter the order Azure lists assignments in", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{
+			actions:              []string{"action_1"},
+			deniedActions:        []string{"action_2", "action_3"},
+			extraRoleAssignments: true,
+		}
+		rule := func(name string) v1alpha1.RBACRule {
+			return v1alpha1.RBACRule{
+				Name: name,
+				Permissions: []v1alpha1.PermissionSet{
+					{
+						Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+						Actions: []v1alpha1.ActionStr{"action_5", "action_1", "action_3", "action_4", "action_2"},
+					},
+				},
+				PrincipalID: "p_id",
+			}
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-deterministic", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules:     []v1alpha1.RBACRule{rule("rule-2"), rule("rule-1")},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// conditions reconciles and returns the ValidationResult's conditions as JSON, without the
+		// times they were validated at.
+		conditions := func() string {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			vr := &vapi.ValidationResult{}
+			Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+			for i := range vr.Status.ValidationConditions {
+				vr.Status.ValidationConditions[i].LastValidationTime = metav1.Time{}
+			}
+			data, err := json.Marshal(vr.Status.ValidationConditions)
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		first := conditions()
+		azure.setReverse(true)
+		Expect(conditions()).To(Equal(first), "conditions must not depend on the order of ARM's lists")
+
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions).To(HaveLen(2))
+		Expect(vr.Status.ValidationConditions[0].ValidationRule).To(HaveSuffix("rule-2"))
+		Expect(vr.Status.ValidationConditions[0].Failures).To(Equal([]string{
+			"Action action_3 denied by deny assignment /subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/denyAssignments/da-2.",
+			"Action action_2 denied by deny assignment /subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/denyAssignments/da-2.",
+			"Action action_5 unpermitted because no role assignment permits it.",
+			"Action action_3 unpermitted because no role assignment permits it.",
+			"Action action_4 unpermitted because no role assignment permits it.",
+			"Action action_2 unpermitted because no role assignment permits it.",
+		}), "failures must be in the order of the rule's Actions")
+		Expect(vr.Status.ValidationConditions[0].Details).To(ContainElement(HaveSuffix("permitted by role assignment /subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleAssignments/ra-1.")))
+
+		By("Reordering the spec's rules")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.RBACRules = []v1alpha1.RBACRule{rule("rule-1"), val.Spec.RBACRules[0]}
+		Expect(c.Update(ctx, val)).To(Succeed())
+		conditions()
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Status.ValidationConditions).To(HaveLen(2))
+		Expect(vr.Status.ValidationConditions[0].ValidationRule).To(HaveSuffix("rule-1"))
+		Expect(vr.Status.ValidationConditions[1].ValidationRule).To(HaveSuffix("rule-2"))
+	})
+
+	It("Should evaluate a template permission rule with its template from a ConfigMap, again once the ConfigMap changes", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"Microsoft.Resources/deployments/*", "Microsoft.Storage/storageAccounts/write"}}
+		clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployment-template",
+				Namespace: validatorNamespace,
+			},
+			Data: map[string]string{"template.json": `{"resources": [{"type": "Microsoft.Storage/storageAccounts", "name": "sa"}]}`},
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-template-permissions", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				TemplatePermissionRules: []v1alpha1.TemplatePermissionRule{
+					{
+						Name:                 "rule-1",
+						PrincipalID:          "p_id",
+						Scope:                "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg",
+						TemplateConfigMapRef: &v1alpha1.ConfigMapKeyRef{Name: cm.Name, Key: "template.json"},
+					},
+				},
+				ResultMaxAge: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		}
+		c := newFakeClient(val, cm)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+			clock:  clk,
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vr := &vapi.ValidationResult{}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+		reconcile := func(at time.Time) vapi.ValidationCondition {
+			clk.SetTime(at)
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+			Expect(vr.Status.ValidationConditions).To(HaveLen(1))
+			return vr.Status.ValidationConditions[0]
+		}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		t1 := clk.Now()
+		cond := reconcile(t1)
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		requests := azure.requestCount()
+		Expect(requests).NotTo(BeZero())
+
+		reconcile(t1.Add(time.Minute))
+		Expect(azure.requestCount()).To(Equal(requests), "the result must be reused while the template is unchanged")
+
+		By("Changing the template in the ConfigMap")
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, cm)).To(Succeed())
+		cm.Data["template.json"] = `{"resources": [{"type": "Microsoft.Storage/storageAccounts", "name": "sa"}, {"type": "Microsoft.Network/virtualNetworks", "name": "vnet"}]}`
+		Expect(c.Update(ctx, cm)).To(Succeed())
+		cond = reconcile(t1.Add(2 * time.Minute))
+		Expect(azure.requestCount()).To(BeNumerically(">", requests), "a changed template must be re-evaluated")
+		Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+		Expect(cond.Failures).To(ConsistOf("Action Microsoft.Network/virtualNetworks/write unpermitted because no role assignment permits it."))
+	})
+
+	DescribeTable("Should fail a template permission rule's own condition when its template can't be read",
+		func(breakTemplate func(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error, failure string) {
+			ctx := context.Background()
+
+			azure := &fakeAzure{actions: []string{"Microsoft.Resources/deployments/*", "Microsoft.Storage/storageAccounts/write"}}
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment-template",
+					Namespace: validatorNamespace,
+				},
+				Data: map[string]string{"template.json": `{"resources": [{"type": "Microsoft.Storage/storageAccounts", "name": "sa"}]}`},
+			}
+			val := &v1alpha1.AzureValidator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       fmt.Sprintf("%s-broken-template", azureValidatorName),
+					Namespace:  validatorNamespace,
+					Generation: 1,
+				},
+				Spec: v1alpha1.AzureValidatorSpec{
+					SkipPreflight:   true,
+					Auth:            v1alpha1.AzureAuth{Implicit: true},
+					DefaultSeverity: v1alpha1.SeverityLow,
+					TemplatePermissionRules: []v1alpha1.TemplatePermissionRule{
+						{
+							Name:                 "rule-1",
+							PrincipalID:          "p_id",
+							Scope:                "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg",
+							TemplateConfigMapRef: &v1alpha1.ConfigMapKeyRef{Name: cm.Name, Key: "template.json"},
+						},
+					},
+				},
+			}
+			c := newFakeClient(val, cm)
+			r := &AzureValidatorReconciler{
+				Client: c,
+				Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+				Scheme: scheme.Scheme,
+				Azure:  azure.options(),
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+			vr := &vapi.ValidationResult{}
+			vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+			reconcile := func() vapi.ValidationCondition {
+				_, err := r.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+				Expect(vr.Status.ValidationConditions).To(HaveLen(1), "there must be no condition other than the rule's")
+				return vr.Status.ValidationConditions[0]
+			}
+
+			// The first reconcile only creates the ValidationResult.
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconcile().Status).To(Equal(corev1.ConditionTrue))
+
+			By("Breaking the template")
+
+			Expect(c.Get(ctx, types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, cm)).To(Succeed())
+			Expect(breakTemplate(ctx, c, cm)).To(Succeed())
+			cond := reconcile()
+			Expect(cond.ValidationRule).To(Equal("validation-rule-1"))
+			Expect(cond.ValidationType).To(Equal(constants.ValidationTypeTemplatePermission))
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Failures).To(ConsistOf(ContainSubstring(failure)))
+			Expect(cond.Details).To(ContainElement("Severity: Low"))
+		},
+		Entry("a missing ConfigMap", func(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error {
+			return c.Delete(ctx, cm)
+		}, "failed to get ConfigMap deployment-template with template"),
+		Entry("a missing key", func(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error {
+			cm.Data = map[string]string{"other.json": "{}"}
+			return c.Update(ctx, cm)
+		}, "ConfigMap deployment-template has no key template.json"),
+	)
+
+	It("Should merge rules from rulesFrom ConfigMaps after the spec's own, rejecting conflicting names", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "more-rules",
+				Namespace: validatorNamespace,
+			},
+			Data: map[string]string{
+				"b.yaml": `
+rbacRules:
+- name: rule-3
+  principalId: p_id
+  permissionSets:
+  - scope: /subscriptions/00000000-0000-0000-0000-000000000000
+    actions: [action_1]
+`,
+				"a.yaml": `
+rbacRules:
+- name: rule-1
+  principalId: p_id
+  permissionSets:
+  - scope: /subscriptions/00000000-0000-0000-0000-000000000000
+    actions: [action_2]
+- name: rule-2
+  principalId: p_id
+  permissionSets:
+  - scope: /subscriptions/00000000-0000-0000-0000-000000000000
+    actions: [action_1]
+`,
+			},
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-rules-from", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{Scope: "/subscriptions/00000000-0000-0000-0000-000000000000", Actions: []v1alpha1.ActionStr{"action_1"}},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+				RulesFrom: []v1alpha1.RulesSource{{ConfigMapName: cm.Name}},
+			},
+		}
+		c := newFakeClient(val, cm)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Spec.ExpectedResults).To(Equal(4))
+		Expect(vr.Status.ValidationConditions).To(HaveLen(4))
+		specLoad := vr.Status.ValidationConditions[0]
+		Expect(specLoad.ValidationType).To(Equal(constants.ValidationTypeSpecLoad))
+		Expect(specLoad.Status).To(Equal(corev1.ConditionFalse))
+		Expect(specLoad.Failures).To(Equal([]string{
+			"Rule rule-1 in rbacRules of key a.yaml of ConfigMap more-rules wasn't loaded, because its name conflicts with another rule's.",
+		}))
+		Expect(specLoad.Details).To(Equal([]string{
+			"Loaded 1 rule(s) from key a.yaml of ConfigMap more-rules.",
+			"Loaded 1 rule(s) from key b.yaml of ConfigMap more-rules.",
+			"Severity: High",
+		}), "keys must be loaded in lexical order")
+		for i, name := range []string{"rule-1", "rule-2", "rule-3"} {
+			cond := vr.Status.ValidationConditions[i+1]
+			Expect(cond.ValidationRule).To(HaveSuffix(name))
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue), "the spec's own rule-1 must take precedence")
+		}
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		Expect(val.Spec.RBACRules).To(HaveLen(1), "loaded rules must not be written back to the spec")
+
+		By("Mapping changes to the ConfigMap to the AzureValidator")
+
+		Expect(r.validatorsForConfigMap(ctx, cm)).To(Equal([]ctrl.Request{req}))
+		other := cm.DeepCopy()
+		other.Name = "other"
+		Expect(r.validatorsForConfigMap(ctx, other)).To(BeEmpty())
+	})
+
+	It("Should fail the spec-load condition, and evaluate the other rules, when a ConfigMap is missing or has malformed YAML", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "more-rules",
+				Namespace: validatorNamespace,
+			},
+			Data: map[string]string{
+				"malformed.yaml": "rbacRules: [name: rule-2",
+				"unknown.yaml":   "rbacRule: []",
+				"good.yaml": `
+rbacRules:
+- name: rule-2
+  principalId: p_id
+  permissionSets:
+  - scope: /subscriptions/00000000-0000-0000-0000-000000000000
+    actions: [action_1]
+`,
+			},
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-rules-from-invalid", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{Scope: "/subscriptions/00000000-0000-0000-0000-000000000000", Actions: []v1alpha1.ActionStr{"action_1"}},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+				RulesFrom: []v1alpha1.RulesSource{
+					{ConfigMapName: "missing"},
+					{ConfigMapName: cm.Name, Keys: []string{"malformed.yaml", "unknown.yaml", "good.yaml", "absent.yaml"}},
+				},
+			},
+		}
+		c := newFakeClient(val, cm)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Spec.ExpectedResults).To(Equal(3))
+		Expect(vr.Status.ValidationConditions).To(HaveLen(3))
+		specLoad := vr.Status.ValidationConditions[0]
+		Expect(specLoad.Status).To(Equal(corev1.ConditionFalse))
+		Expect(specLoad.Failures).To(HaveLen(4))
+		Expect(specLoad.Failures[0]).To(Equal("ConfigMap missing not found."))
+		Expect(specLoad.Failures[1]).To(HavePrefix("Failed to parse key malformed.yaml of ConfigMap more-rules: "))
+		Expect(specLoad.Failures[2]).To(HavePrefix("Failed to parse key unknown.yaml of ConfigMap more-rules: "))
+		Expect(specLoad.Failures[2]).To(ContainSubstring("rbacRule"), "unknown fields must be rejected")
+		Expect(specLoad.Failures[3]).To(Equal("ConfigMap more-rules has no key absent.yaml."))
+		Expect(specLoad.Details).To(Equal([]string{"Loaded 1 rule(s) from key good.yaml of ConfigMap more-rules.", "Severity: High"}))
+		Expect(vr.Status.ValidationConditions[1].ValidationRule).To(HaveSuffix("rule-1"))
+		Expect(vr.Status.ValidationConditions[2].ValidationRule).To(HaveSuffix("rule-2"))
+		Expect(vr.Status.ValidationConditions[2].Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("Should fail rules whose specs have problems without evaluating them, list the problems in the spec condition, and evaluate the other rules", func() {
+		ctx := context.Background()
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		rule := func(name string) v1alpha1.RBACRule {
+			return v1alpha1.RBACRule{
+				Name: name,
+				Permissions: []v1alpha1.PermissionSet{
+					{Scope: "/subscriptions/00000000-0000-0000-0000-000000000000", Actions: []v1alpha1.ActionStr{"action_1"}},
+				},
+				PrincipalID: "p_id",
+			}
+		}
+		broken := rule("rule-2")
+		broken.Permissions[0].Scope = "subscriptions/00000000-0000-0000-0000-000000000000"
+		broken.FailureMessageTemplate = "{{.Action"
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-spec-problems", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules:     []v1alpha1.RBACRule{rule("rule-1"), broken, rule("rule-3")},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := validationResultKey(val)
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Spec.ExpectedResults).To(Equal(4))
+		Expect(vr.Status.ValidationConditions).To(HaveLen(4))
+		spec := vr.Status.ValidationConditions[0]
+		Expect(spec.ValidationType).To(Equal(constants.ValidationTypeSpec))
+		Expect(spec.Status).To(Equal(corev1.ConditionFalse))
+		Expect(spec.Failures).To(Equal([]string{
+			`Rule rule-2: spec.rbacRules[1].permissionSets[0].scope: Invalid value: "subscriptions/00000000-0000-0000-0000-000000000000": must be an ARM resource ID, e.g. /subscriptions/{id}/resourceGroups/{rg}`,
+			`Rule rule-2: spec.rbacRules[1].failureMessageTemplate: Invalid value: "{{.Action": template: failureMessageTemplate:1: unclosed action`,
+		}))
+		for i, name := range []string{"rule-1", "rule-2", "rule-3"} {
+			cond := vr.Status.ValidationConditions[i+1]
+			Expect(cond.ValidationRule).To(HaveSuffix(name))
+			if name == "rule-2" {
+				Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+				Expect(cond.Message).To(Equal(messages.RuleSpecInvalid))
+				Expect(cond.Failures).To(Equal([]string{"Rule's spec has 2 problem(s), which the azure-spec condition lists."}))
+			} else {
+				Expect(cond.Status).To(Equal(corev1.ConditionTrue), "well-formed rules must be evaluated")
+			}
+		}
+
+		By("Fixing the broken rule")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.RBACRules[1] = rule("rule-2")
+		Expect(c.Update(ctx, val)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Spec.ExpectedResults).To(Equal(4), "the spec condition must be kept once reported")
+		Expect(vr.Status.ValidationConditions[0].ValidationType).To(Equal(constants.ValidationTypeSpec))
+		Expect(vr.Status.ValidationConditions[0].Status).To(Equal(corev1.ConditionTrue))
+		Expect(vr.Status.ValidationConditions[2].Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	DescribeTable("Requeuing according to the category of the errors rules fail with",
+		func(statusCode int, errorCode string, want ctrl.Result) {
+			ctx := context.Background()
+
+			azure := &fakeAzure{actions: []string{"action_1"}}
+			if statusCode != 0 {
+				azure.fail(statusCode, errorCode)
+			}
+			val := &v1alpha1.AzureValidator{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-requeue", azureValidatorName),
+					Namespace: validatorNamespace,
+				},
+				Spec: v1alpha1.AzureValidatorSpec{
+					SkipPreflight: true,
+					Auth: v1alpha1.AzureAuth{
+						Implicit: true,
+					},
+					RBACRules: []v1alpha1.RBACRule{
+						{
+							Name: "rule-1",
+							Permissions: []v1alpha1.PermissionSet{
+								{
+									Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+									Actions: []v1alpha1.ActionStr{"action_1"},
+								},
+							},
+							PrincipalID: "p_id",
+						},
+					},
+				},
+			}
+			r := &AzureValidatorReconciler{
+				Client: newFakeClient(val),
+				Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+				Scheme: scheme.Scheme,
+				Azure:  azure.options(),
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+
+			// The first reconcile only creates the ValidationResult.
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(want))
+		},
+		Entry("requeues after the default interval when rules succeed", 0, "", ctrl.Result{RequeueAfter: defaultRequeueAfter}),
+		Entry("requeues quickly after transient errors", http.StatusServiceUnavailable, "ServiceUnavailable", ctrl.Result{RequeueAfter: transientRequeueAfter}),
+		Entry("requeues quickly after throttling", http.StatusTooManyRequests, "TooManyRequests", ctrl.Result{RequeueAfter: throttledRequeueAfter}),
+		Entry("requeues slowly after authorization failures", http.StatusForbidden, "AuthorizationFailed", ctrl.Result{RequeueAfter: forbiddenRequeueAfter}),
+		Entry("doesn't requeue after invalid requests", http.StatusBadRequest, "InvalidSubscriptionId", ctrl.Result{}),
+	)
+
+	It("Should check the plugin's access at the scopes of RBAC rules before evaluating them, in a single condition", func() {
+		ctx := context.Background()
+
+		const (
+			subA = "00000000-0000-0000-0000-00000000000a"
+			subB = "00000000-0000-0000-0000-00000000000b"
+			rgC  = "/subscriptions/00000000-0000-0000-0000-00000000000c/resourceGroups/rg"
+		)
+		azure := &fakeAzure{
+			actions:                []string{"Microsoft.Authorization/roleAssignments/read"},
+			principalID:            "plugin_id",
+			forbiddenSubscriptions: []string{subB},
+		}
+		scopes := []v1alpha1.PermissionSet{
+			{Scope: "/subscriptions/" + subA, Actions: []v1alpha1.ActionStr{"Microsoft.Authorization/roleAssignments/read"}},
+			{Scope: "/subscriptions/" + subB, Actions: []v1alpha1.ActionStr{"Microsoft.Authorization/roleAssignments/read"}},
+			{Scope: rgC, Actions: []v1alpha1.ActionStr{"Microsoft.Authorization/roleAssignments/read"}},
+		}
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("%s-preflight", azureValidatorName),
+				Namespace:  validatorNamespace,
+				Generation: 1,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				Auth: v1alpha1.AzureAuth{Implicit: true},
+				RBACRules: []v1alpha1.RBACRule{
+					{Name: "rule-1", Permissions: scopes[:1], PrincipalID: "p_id"},
+					{Name: "rule-2", Permissions: scopes, PrincipalID: "p_id"},
+				},
+			},
+		}
+		c := newFakeClient(val)
+		r := &AzureValidatorReconciler{
+			Client: c,
+			Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+			Scheme: scheme.Scheme,
+			Azure:  azure.options(),
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := types.NamespacedName{Name: validationResultName(val), Namespace: validatorNamespace}
+
+		// The first reconcile only creates the ValidationResult.
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		vr := &vapi.ValidationResult{}
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Spec.ExpectedResults).To(Equal(3))
+		Expect(vr.Status.ValidationConditions).To(HaveLen(3))
+		preflight := vr.Status.ValidationConditions[0]
+		Expect(preflight.ValidationType).To(Equal(constants.ValidationTypePreflight))
+		Expect(preflight.Failures).To(Equal([]string{
+			"Plugin lacks Microsoft.Authorization/roleAssignments/read, Microsoft.Authorization/roleDefinitions/read at scope /subscriptions/" + subB + ".",
+			"Plugin lacks Microsoft.Authorization/roleDefinitions/read at scope " + rgC + ".",
+		}), "each scope must be checked once, in the order of the rules")
+		Expect(vr.Status.ValidationConditions[1].ValidationRule).To(HaveSuffix("rule-1"))
+		Expect(vr.Status.ValidationConditions[1].Status).To(Equal(corev1.ConditionTrue))
+
+		By("Skipping the preflight check")
+
+		Expect(c.Get(ctx, req.NamespacedName, val)).To(Succeed())
+		val.Spec.SkipPreflight = true
+		Expect(c.Update(ctx, val)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, vrKey, vr)).To(Succeed())
+		Expect(vr.Spec.ExpectedResults).To(Equal(2))
+	})
+
+	It("Should requeue as soon as the soonest rule needs it", func() {
+		invalid := &azcore.ResponseError{StatusCode: http.StatusBadRequest}
+		forbidden := &azcore.ResponseError{StatusCode: http.StatusForbidden}
+		throttled := &azcore.ResponseError{StatusCode: http.StatusTooManyRequests}
+
+		after, requeue := requeueAfterErrors([]error{invalid, forbidden, nil, throttled}, time.Now())
+		Expect(requeue).To(BeTrue())
+		Expect(after).To(Equal(throttledRequeueAfter))
+
+		after, requeue = requeueAfterErrors([]error{invalid, forbidden}, time.Now())
+		Expect(requeue).To(BeTrue())
+		Expect(after).To(Equal(forbiddenRequeueAfter))
+
+		_, requeue = requeueAfterErrors([]error{invalid, invalid}, time.Now())
+		Expect(requeue).To(BeFalse())
+
+		// Rules skipped by the circuit breaker are requeued once it allows requests again.
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		skipped := fmt.Errorf("failed to get deny assignments: %w", &azure_errors.CircuitOpenError{RetryAt: now.Add(3 * time.Minute)})
+		after, requeue = requeueAfterErrors([]error{skipped, forbidden}, now)
+		Expect(requeue).To(BeTrue())
+		Expect(after).To(Equal(3 * time.Minute))
+
+		skipped = &azure_errors.CircuitOpenError{RetryAt: now.Add(time.Second)}
+		after, _ = requeueAfterErrors([]error{skipped}, now)
+		Expect(after).To(Equal(transientRequeueAfter))
+
+		By("Honoring the Retry-After that ARM responded with")
+
+		retryAfter := &azcore.ResponseError{StatusCode: http.StatusTooManyRequests, RawResponse: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"5"}},
+		}}
+		after, requeue = requeueAfterErrors([]error{forbidden, fmt.Errorf("failed to get role assignments: %w", retryAfter)}, now)
+		Expect(requeue).To(BeTrue())
+		Expect(after).To(Equal(5 * time.Second))
+
+		By("Not requeueing rules that can't be evaluated until their spec changes")
+
+		_, requeue = requeueAfterErrors([]error{azure_errors.Terminal(errors.New("invalid template"))}, now)
+		Expect(requeue).To(BeFalse())
+		after, requeue = requeueAfterErrors([]error{azure_errors.Terminal(errors.New("invalid template")), forbidden}, now)
+		Expect(requeue).To(BeTrue())
+		Expect(after).To(Equal(forbiddenRequeueAfter))
+	})
+
+	It("Should skip rule evaluation, with an event, while the plugin can't write the ValidationResult", func() {
+		ctx := context.Background()
+
+		By("Creating a service account that can read ValidationResults but not write them")
+
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "restricted-plugin", Namespace: validatorNamespace}}
+		role := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: sa.Name, Namespace: sa.Namespace},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{v1alpha1.GroupVersion.Group},
+					Resources: []string{"azurevalidators", "azurevalidators/status"},
+					Verbs:     []string{"get", "list", "watch", "update", "patch"},
+				},
+				{
+					APIGroups: []string{vapi.GroupVersion.Group},
+					Resources: []string{"validationresults"},
+					Verbs:     []string{"get", "list", "watch"},
+				},
+			},
+		}
+		binding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: sa.Name, Namespace: sa.Namespace},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa.Name, Namespace: sa.Namespace}},
+		}
+		for _, obj := range []client.Object{sa, role, binding} {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+		}
+		restrictedCfg := rest.CopyConfig(cfg)
+		restrictedCfg.Impersonate = rest.ImpersonationConfig{UserName: fmt.Sprintf("system:serviceaccount:%s:%s", sa.Namespace, sa.Name)}
+		restricted, err := client.NewWithWatch(restrictedCfg, client.Options{Scheme: scheme.Scheme})
+		Expect(err).NotTo(HaveOccurred())
+
+		val := &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-unwritable-result", azureValidatorName),
+				Namespace: validatorNamespace,
+			},
+			Spec: v1alpha1.AzureValidatorSpec{
+				SkipPreflight: true,
+				Auth:          v1alpha1.AzureAuth{Implicit: true},
+				RBACRules: []v1alpha1.RBACRule{
+					{
+						Name: "rule-1",
+						Permissions: []v1alpha1.PermissionSet{
+							{
+								Scope:   "/subscriptions/00000000-0000-0000-0000-000000000000",
+								Actions: []v1alpha1.ActionStr{"action_1"},
+							},
+						},
+						PrincipalID: "p_id",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, val)).To(Succeed())
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: val.Name, Namespace: val.Namespace}}
+		vrKey := validationResultKey(val)
+
+		// The role binding takes effect once the API server's authorizer has seen it.
+		Eventually(func() error {
+			return restricted.Get(ctx, req.NamespacedName, &v1alpha1.AzureValidator{})
+		}, timeout, interval).Should(Succeed())
+
+		azure := &fakeAzure{actions: []string{"action_1"}}
+		recorder := record.NewFakeRecorder(10)
+		newReconciler := func(c client.Client) *AzureValidatorReconciler {
+			return &AzureValidatorReconciler{
+				Client:   c,
+				Log:      ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+				Scheme:   scheme.Scheme,
+				Recorder: recorder,
+				Azure:    azure.options(),
+			}
+		}
+		expectSkipped := func(r *AzureValidatorReconciler) {
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(unwritableRequeueAfter))
+			Expect(azure.requestCount()).To(BeZero(), "rules must not be evaluated")
+
+			var event string
+			Expect(recorder.Events).To(Receive(&event))
+			Expect(event).To(HavePrefix("Warning " + reasonResultNotWritable))
+			Expect(event).To(ContainSubstring("forbidden"))
+		}
+
+		By("Reconciling as the service account before the ValidationResult exists")
+
+		// The manager's reconciler creates the ValidationResults of all AzureValidators, so the
+		// service account's reconciler is kept from seeing this one, as if it didn't exist yet.
+		hidden := interceptor.NewClient(restricted, interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*vapi.ValidationResult); ok && key == vrKey {
+					return apierrs.NewNotFound(vapi.GroupVersion.WithResource("validationresults").GroupResource(), key.Name)
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		expectSkipped(newReconciler(hidden))
+
+		By("Reconciling as the service account once the ValidationResult exists")
+
+		Eventually(func() error {
+			return k8sClient.Get(ctx, vrKey, &vapi.ValidationResult{})
+		}, timeout, interval).Should(Succeed())
+		expectSkipped(newReconciler(restricted))
+	})
+})
+
+// inventoryTransport serves ARM's responses from an offline inventory of Azure resources, keyed by
+// the type of resource requested (e.g. roleAssignments), rather than from ARM. Requests for other
+// types fail with a 404.
+type inventoryTransport map[string]string
+
+// Do implements policy.Transporter.
+func (t inventoryTransport) Do(req *http.Request) (*http.Response, error) {
+	statusCode, body := http.StatusNotFound, `{"error": {"code": "ResourceNotFound", "message": "not in the inventory"}}`
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if data, ok := t[segments[i]]; ok {
+			statusCode, body = http.StatusOK, data
+			break
+		}
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// severityCondition returns a condition with a status and a severity detail.
+func severityCondition(status corev1.ConditionStatus, severity v1alpha1.Severity) vapi.ValidationCondition {
+	c := vapi.ValidationCondition{Status: status}
+	setSeverityDetail(&c, severity)
+	return c
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// reasonDryRun is the reason of the events recorded with the outcome of evaluating an
+// AzureValidator's rules in dry-run mode.
+const reasonDryRun = "DryRun"
+
+// isDryRun returns whether an AzureValidator's rules are evaluated in dry-run mode, via its dry-run
+// annotation.
+func isDryRun(validator *v1alpha1.AzureValidator) bool {
+	return validator.Annotations[constants.DryRunAnnotation] == "true"
+}
+
+// dryRunPatcher patches nothing, so that in dry-run mode the validator library only computes what
+// the ValidationResult would be.
+type dryRunPatcher struct{}
+
+func (dryRunPatcher) Patch(context.Context, client.Object, ...patch.Option) error {
+	return nil
+}
+
+// recordDryRun records what an AzureValidator's ValidationResult would be, vr, in its status and in
+// an event.
+func (r *AzureValidatorReconciler) recordDryRun(ctx context.Context, validator *v1alpha1.AzureValidator, vr *vapi.ValidationResult) error {
+	p, err := patch.NewHelper(validator, r.Client)
+	if err != nil {
+		return err
+	}
+	validator.Status.DryRun = &v1alpha1.DryRunResult{
+		EvaluationTime:     metav1.NewTime(r.now()),
+		ObservedGeneration: validator.Generation,
+		State:              vr.Status.State,
+		Conditions:         vr.Status.ValidationConditions,
+	}
+	if err := p.Patch(ctx, validator); err != nil {
+		return err
+	}
+
+	if r.Recorder == nil {
+		return nil
+	}
+	var failing []string
+	for _, c := range vr.Status.ValidationConditions {
+		if c.Status == corev1.ConditionFalse {
+			failing = append(failing, c.ValidationRule)
+		}
+	}
+	msg := fmt.Sprintf("Dry run: ValidationResult %s would be %s, with %d of %d rule(s) failing",
+		validationResultKey(validator), vr.Status.State, len(failing), len(vr.Status.ValidationConditions))
+	if len(failing) > 0 {
+		msg += ": " + strings.Join(failing, ", ")
+	}
+	r.Recorder.Event(validator, corev1.EventTypeNormal, reasonDryRun, msg+".")
+	return nil
+}
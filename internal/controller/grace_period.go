@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// pendingRequeueAfter is how long to wait before re-validating rules that are pending within their
+// grace period, at most, so that they're reported as soon as role assignments propagate.
+const pendingRequeueAfter = 30 * time.Second
+
+// gracePeriodEnd returns when the grace period of an RBAC rule ends, for its evaluation at now, or
+// the zero time if the rule has no grace period. The grace period lasts spec.gracePeriod from the
+// latest of when the AzureValidator was created, when the rule was created or changed (i.e. first
+// evaluated with its current spec), and when the rule last passed. While the rule is pending, the
+// end recorded in its RuleResult stands, so that re-validating it doesn't extend the grace period.
+func gracePeriodEnd(validator *v1alpha1.AzureValidator, rule v1alpha1.RBACRule, now time.Time) time.Time {
+	if rule.GracePeriod == nil || rule.GracePeriod.Duration <= 0 {
+		return time.Time{}
+	}
+	grace := rule.GracePeriod.Duration
+	end := validator.CreationTimestamp.Add(grace)
+	later := func(t time.Time) {
+		if t.After(end) {
+			end = t
+		}
+	}
+
+	prev := findRuleResult(validator.Status.RuleResults, rule.Name, constants.ValidationTypeRBAC)
+	switch {
+	case prev == nil || (prev.Hash != "" && prev.Hash != hashRule(rule)):
+		later(now.Add(grace))
+	case prev.State == vapi.ValidationSucceeded:
+		later(prev.LastEvaluationTime.Add(grace))
+	case prev.State == vapi.ValidationInProgress && prev.PendingUntil != nil:
+		later(prev.PendingUntil.Time)
+	}
+	return end
+}
+
+// requeueAfterPending determines how long to wait before re-validating rules that are pending
+// within their grace period: pendingRequeueAfter, or until the soonest grace period ends, if that's
+// sooner. Returns false if no rule is pending.
+func requeueAfterPending(outcomes []ruleOutcome, now time.Time) (time.Duration, bool) {
+	var requeueAfter time.Duration
+	pending := false
+	for _, o := range outcomes {
+		if o.state != vapi.ValidationInProgress || o.pendingUntil.IsZero() {
+			continue
+		}
+		after := min(pendingRequeueAfter, max(o.pendingUntil.Sub(now), time.Second))
+		if !pending || after < requeueAfter {
+			requeueAfter = after
+		}
+		pending = true
+	}
+	return requeueAfter, pending
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+)
+
+// armRequests counts the ARM requests made while evaluating each AzureValidator's rules. It's
+// served by the manager's metrics endpoint.
+var armRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "validator_plugin_azure_arm_requests_total",
+	Help: "Number of ARM requests made while evaluating AzureValidators' rules, including retries.",
+}, []string{"namespace", "name", "client_type"})
+
+// mappedRequeues counts the reconciles of each AzureValidator that were triggered by changes to the
+// Secrets and ConfigMaps it refers to, by the kind of the changed object.
+var mappedRequeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "validator_plugin_azure_mapped_requeues_total",
+	Help: "Number of AzureValidator reconciles triggered by changes to the Secrets and ConfigMaps they refer to.",
+}, []string{"namespace", "name", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(armRequests, mappedRequeues)
+}
+
+// recordAPIRequests adds the ARM requests made during a reconcile of an AzureValidator to
+// armRequests, and returns them for its status.
+func recordAPIRequests(validator *v1alpha1.AzureValidator, counts *azure_utils.RequestCounts) *v1alpha1.APIRequests {
+	byClientType := counts.ByClientType()
+	for clientType, n := range byClientType {
+		armRequests.WithLabelValues(validator.Namespace, validator.Name, clientType).Add(float64(n))
+	}
+	return &v1alpha1.APIRequests{Total: counts.Total(), ByClientType: byClientType}
+}
+
+// forgetMetrics drops the armRequests and mappedRequeues series of a deleted AzureValidator.
+func forgetMetrics(validator *v1alpha1.AzureValidator) {
+	labels := prometheus.Labels{"namespace": validator.Namespace, "name": validator.Name}
+	armRequests.DeletePartialMatch(labels)
+	mappedRequeues.DeletePartialMatch(labels)
+}
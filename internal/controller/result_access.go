@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// reasonResultNotWritable is the reason of the events recorded when an AzureValidator's rules
+// aren't evaluated because its ValidationResult can't be written.
+const reasonResultNotWritable = "ValidationResultNotWritable"
+
+// reasonResultDeleted is the reason of the events recorded when an AzureValidator's ValidationResult
+// is deleted along with it.
+const reasonResultDeleted = "ValidationResultDeleted"
+
+// checkResultWritable checks that the plugin can write an AzureValidator's ValidationResult, using
+// dry-run requests that aren't persisted. It checks that the ValidationResult can be created if
+// existing is nil, and otherwise that it and its status can be patched, like the validator
+// library's patch helper does. A ValidationResult that already exists when checking that it can be
+// created doesn't count as a failure, since the check is for access rather than state.
+func (r *AzureValidatorReconciler) checkResultWritable(ctx context.Context, validator *v1alpha1.AzureValidator, existing *vapi.ValidationResult) error {
+	if existing == nil {
+		if err := r.Create(ctx, buildValidationResult(validator), client.DryRunAll); err != nil && !apierrs.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create ValidationResult: %w", err)
+		}
+		return nil
+	}
+
+	empty := client.RawPatch(ktypes.MergePatchType, []byte("{}"))
+	if err := r.Patch(ctx, existing.DeepCopy(), empty, client.DryRunAll); err != nil {
+		return fmt.Errorf("failed to patch ValidationResult: %w", err)
+	}
+	if err := r.Status().Patch(ctx, existing.DeepCopy(), empty, client.DryRunAll); err != nil {
+		return fmt.Errorf("failed to patch ValidationResult status: %w", err)
+	}
+	return nil
+}
+
+// recordResultNotWritable records a warning event on an AzureValidator whose rules aren't being
+// evaluated because its ValidationResult can't be written.
+func (r *AzureValidatorReconciler) recordResultNotWritable(validator *v1alpha1.AzureValidator, err error) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(validator, corev1.EventTypeWarning, reasonResultNotWritable,
+		"Skipping validation, because ValidationResult %s can't be written: %v", validationResultKey(validator), err)
+}
+
+// recordResultDeleted records an event on an AzureValidator that is being deleted, once its
+// ValidationResult has been deleted, as a final notification that its results are gone.
+func (r *AzureValidatorReconciler) recordResultDeleted(validator *v1alpha1.AzureValidator, key ktypes.NamespacedName) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(validator, corev1.EventTypeNormal, reasonResultDeleted,
+		"Deleted ValidationResult %s, since the AzureValidator is being deleted.", key)
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// reusedResultDetail prefixes the detail added to the conditions of reused results.
+const reusedResultDetail = "Result reused from the evaluation at"
+
+// hashRule returns a hash of a rule's spec. Returns an empty string, which never matches a previous
+// hash, if the rule can't be hashed.
+func hashRule(rule any) string {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canReuseResult returns whether a rule's previous result can be reused instead of re-evaluating the
+// rule. This is only the case when result reuse is enabled, the rule and the rest of the spec
+// haven't changed since the previous evaluation, the previous evaluation didn't fail with an error,
+// the previous result isn't older than maxAge, and the rule wasn't pending, which it may no longer be.
+func canReuseResult(prev *v1alpha1.RuleResult, hash string, generation int64, maxAge *metav1.Duration, now time.Time) bool {
+	if prev == nil || maxAge == nil || maxAge.Duration <= 0 || prev.State == vapi.ValidationInProgress {
+		return false
+	}
+	if prev.Hash == "" || prev.Hash != hash || prev.ObservedGeneration != generation {
+		return false
+	}
+	return now.Sub(prev.LastEvaluationTime.Time) < maxAge.Duration
+}
+
+// reusableResult returns the previous result of a rule, and its previous RuleResult, if the
+// previous result can be reused. Returns nil if the rule must be re-evaluated.
+func (r *AzureValidatorReconciler) reusableResult(validator *v1alpha1.AzureValidator, vr *vapi.ValidationResult, name, validationType, hash string) (*types.ValidationRuleResult, *v1alpha1.RuleResult) {
+	prev := findRuleResult(validator.Status.RuleResults, name, validationType)
+	if !canReuseResult(prev, hash, validator.Generation, validator.Spec.ResultMaxAge, r.now()) {
+		return nil, nil
+	}
+
+	validationRule := fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, name)
+	for _, c := range vr.Status.ValidationConditions {
+		if c.ValidationRule != validationRule || c.ValidationType != validationType {
+			continue
+		}
+
+		condition := c.DeepCopy()
+		details := make([]string, 0, len(condition.Details)+1)
+		for _, d := range condition.Details {
+			if !strings.HasPrefix(d, reusedResultDetail) {
+				details = append(details, d)
+			}
+		}
+		condition.Details = append(details, fmt.Sprintf(
+			"%s %s, because the rule hasn't changed and the result is younger than spec.resultMaxAge.",
+			reusedResultDetail, prev.LastEvaluationTime.UTC().Format(time.RFC3339),
+		))
+
+		state := vapi.ValidationSucceeded
+		if condition.Status != corev1.ConditionTrue {
+			state = vapi.ValidationFailed
+		}
+		return &types.ValidationRuleResult{Condition: condition, State: &state}, prev
+	}
+
+	// The previous condition is gone (e.g. the ValidationResult was recreated), so there's nothing
+	// to reuse.
+	return nil, nil
+}
+
+// reusedRuleOutcome builds the outcome of a rule whose previous result was reused. The outcome is
+// identical to the previous one.
+func reusedRuleOutcome(prev *v1alpha1.RuleResult) ruleOutcome {
+	return ruleOutcome{
+		name:           prev.Name,
+		validationType: prev.ValidationType,
+		state:          prev.State,
+		start:          prev.LastEvaluationTime.Time,
+		duration:       prev.Duration.Duration,
+		hash:           prev.Hash,
+		generation:     prev.ObservedGeneration,
+	}
+}
+
+func findRuleResult(results []v1alpha1.RuleResult, name, validationType string) *v1alpha1.RuleResult {
+	for i, rr := range results {
+		if rr.Name == name && rr.ValidationType == validationType {
+			return &results[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// labelDetail prefixes the details that carry a rule's labels.
+const labelDetail = "Label:"
+
+// ruleLabels merges the labels of an AzureValidator's spec with those of one of its rules. The
+// rule's labels win.
+func ruleLabels(specLabels, labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(specLabels)+len(labels))
+	maps.Copy(merged, specLabels)
+	maps.Copy(merged, labels)
+	return merged
+}
+
+// setLabelDetails replaces the label details of a condition with one detail per label, in order of
+// label key. Details of labels that were removed since the condition was last labeled are dropped.
+func setLabelDetails(condition *vapi.ValidationCondition, labels map[string]string) {
+	details := make([]string, 0, len(condition.Details)+len(labels))
+	for _, d := range condition.Details {
+		if !strings.HasPrefix(d, labelDetail) {
+			details = append(details, d)
+		}
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		details = append(details, fmt.Sprintf("%s %s=%s", labelDetail, k, labels[k]))
+	}
+	condition.Details = details
+}
+
+// applyResultLabels sets an AzureValidator's result labels on its ValidationResult, overwriting any
+// edits made to them since. Other labels are left alone.
+func applyResultLabels(vr *vapi.ValidationResult, validator *v1alpha1.AzureValidator) {
+	if len(validator.Spec.ResultLabels) == 0 {
+		return
+	}
+	if vr.Labels == nil {
+		vr.Labels = make(map[string]string, len(validator.Spec.ResultLabels))
+	}
+	maps.Copy(vr.Labels, validator.Spec.ResultLabels)
+}
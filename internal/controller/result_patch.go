@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vres "github.com/spectrocloud-labs/validator/pkg/validationresult"
+)
+
+// lastValidatedRefreshInterval is how often the LastValidatedAnnotation of a ValidationResult is
+// refreshed while its results don't change. Refreshing it bumps the ValidationResult's
+// resourceVersion, so it happens far less often than re-validation.
+const lastValidatedRefreshInterval = 15 * time.Minute
+
+// hashResult returns a hash of what a ValidationResult reports: its labels, expected results,
+// state, and conditions, but not when the conditions were validated. Returns an empty string, which
+// never matches another hash, if the ValidationResult can't be hashed.
+func hashResult(vr *vapi.ValidationResult) string {
+	conditions := make([]vapi.ValidationCondition, len(vr.Status.ValidationConditions))
+	for i, c := range vr.Status.ValidationConditions {
+		c.LastValidationTime = metav1.Time{}
+		conditions[i] = c
+	}
+	data, err := json.Marshal(struct {
+		Labels          map[string]string
+		ExpectedResults int
+		State           vapi.ValidationState
+		Conditions      []vapi.ValidationCondition
+	}{vr.Labels, vr.Spec.ExpectedResults, vr.Status.State, conditions})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// resultPatcher patches a ValidationResult only if its results changed since it was read as prev,
+// so that re-validation with the same results doesn't notify sinks or bump its resourceVersion.
+// While they don't change, only its LastValidatedAnnotation is patched, once it's older than
+// lastValidatedRefreshInterval.
+type resultPatcher struct {
+	p    vres.Patcher
+	prev *vapi.ValidationResult
+	now  time.Time
+
+	// skipped is whether the ValidationResult wasn't patched.
+	skipped bool
+}
+
+func (p *resultPatcher) Patch(ctx context.Context, obj client.Object, opts ...patch.Option) error {
+	vr, ok := obj.(*vapi.ValidationResult)
+	if !ok || p.prev == nil || hashResult(vr) != hashResult(p.prev) {
+		setLastValidated(vr, p.now)
+		return p.p.Patch(ctx, obj, opts...)
+	}
+	last, err := time.Parse(time.RFC3339, vr.Annotations[constants.LastValidatedAnnotation])
+	if err == nil && p.now.Sub(last) < lastValidatedRefreshInterval {
+		p.skipped = true
+		return nil
+	}
+
+	// Keep when the conditions were validated, so that only the annotation changes.
+	vr.Status.ValidationConditions = p.prev.DeepCopy().Status.ValidationConditions
+	setLastValidated(vr, p.now)
+	return p.p.Patch(ctx, obj, opts...)
+}
+
+func setLastValidated(vr *vapi.ValidationResult, now time.Time) {
+	if vr == nil {
+		return
+	}
+	if vr.Annotations == nil {
+		vr.Annotations = map[string]string{}
+	}
+	vr.Annotations[constants.LastValidatedAnnotation] = now.UTC().Format(time.RFC3339)
+}
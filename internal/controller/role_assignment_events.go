@@ -0,0 +1,391 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/go-logr/logr"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+)
+
+// Field indexes over AzureValidators, used to find the AzureValidators affected by a role
+// assignment change.
+const (
+	// indexRolePrincipals indexes AzureValidators by the (lowercase) principal IDs of their RBAC
+	// and template permission rules.
+	indexRolePrincipals = "spec.roleAssignmentPrincipals"
+	// indexRoleScopes indexes AzureValidators by the (lowercase) scopes of their RBAC and template
+	// permission rules, and all scopes containing them. A role assignment at any of these scopes
+	// applies at a rule's scope.
+	indexRoleScopes = "spec.roleAssignmentScopes"
+)
+
+const (
+	opRoleAssignmentWrite  = "microsoft.authorization/roleassignments/write"
+	opRoleAssignmentDelete = "microsoft.authorization/roleassignments/delete"
+
+	// activityLogLookback is how far before the end of the previous poll each poll starts. Events
+	// only become available in the Activity Log minutes after they occur, so a poll of just the
+	// time since the previous one would miss them.
+	activityLogLookback = 15 * time.Minute
+)
+
+// indexRolePrincipalIDs returns the values of indexRolePrincipals for an AzureValidator.
+func indexRolePrincipalIDs(obj client.Object) []string {
+	validator, ok := obj.(*v1alpha1.AzureValidator)
+	if !ok {
+		return nil
+	}
+	var principals []string
+	for _, rule := range validator.Spec.RBACRules {
+		for _, principalID := range rule.Principals() {
+			principals = appendUnique(principals, strings.ToLower(principalID))
+		}
+	}
+	for _, rule := range validator.Spec.TemplatePermissionRules {
+		principals = appendUnique(principals, strings.ToLower(rule.PrincipalID))
+	}
+	return principals
+}
+
+// indexRoleScopeIDs returns the values of indexRoleScopes for an AzureValidator.
+func indexRoleScopeIDs(obj client.Object) []string {
+	validator, ok := obj.(*v1alpha1.AzureValidator)
+	if !ok {
+		return nil
+	}
+	var scopes []string
+	for _, scope := range roleAssignmentRuleScopes(validator) {
+		for _, s := range scopeAndAncestors(scope) {
+			scopes = appendUnique(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// roleAssignmentRuleScopes returns the scopes of an AzureValidator's rules that depend on role
+// assignments.
+func roleAssignmentRuleScopes(validator *v1alpha1.AzureValidator) []string {
+	var scopes []string
+	for _, rule := range validator.Spec.RBACRules {
+		for _, ps := range rule.Permissions {
+			scopes = append(scopes, ps.BaseScopes()...)
+		}
+	}
+	for _, rule := range validator.Spec.TemplatePermissionRules {
+		scopes = append(scopes, rule.Scope)
+	}
+	return scopes
+}
+
+// scopeAndAncestors returns a scope and the scopes containing it (e.g. its resource group and
+// subscription), in lowercase. The tenant root isn't included. Returns just the scope if it can't
+// be parsed.
+func scopeAndAncestors(scope string) []string {
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+	id, err := arm.ParseResourceID(scope)
+	if err != nil {
+		return []string{scope}
+	}
+	var scopes []string
+	for ; id != nil && id.String() != "/"; id = id.Parent {
+		scopes = appendUnique(scopes, strings.ToLower(id.String()))
+	}
+	return scopes
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// revalidatesOnRoleAssignmentChanges returns whether role assignment changes trigger the
+// re-validation of an AzureValidator.
+func revalidatesOnRoleAssignmentChanges(validator *v1alpha1.AzureValidator) bool {
+	return validator.Spec.RevalidateOnRoleAssignmentChanges && validator.DeletionTimestamp.IsZero() && !isPaused(validator)
+}
+
+// dependsOnRoleAssignments returns whether the results of rules of a validation type depend on
+// role assignments, and thus must not be reused after a role assignment change.
+func dependsOnRoleAssignments(validationType string) bool {
+	switch validationType {
+	case constants.ValidationTypeRBAC, constants.ValidationTypeTemplatePermission, constants.ValidationTypePreflight:
+		return true
+	}
+	return false
+}
+
+// markRevalidation records that the next reconcile of an AzureValidator must re-evaluate its
+// rules that depend on role assignments.
+func (r *AzureValidatorReconciler) markRevalidation(nn ktypes.NamespacedName) {
+	r.revalidatingMu.Lock()
+	defer r.revalidatingMu.Unlock()
+	if r.revalidating == nil {
+		r.revalidating = map[ktypes.NamespacedName]bool{}
+	}
+	r.revalidating[nn] = true
+}
+
+// takeRevalidation returns whether a role assignment change was detected for an AzureValidator
+// since its previous reconcile, and clears the mark.
+func (r *AzureValidatorReconciler) takeRevalidation(nn ktypes.NamespacedName) bool {
+	r.revalidatingMu.Lock()
+	defer r.revalidatingMu.Unlock()
+	marked := r.revalidating[nn]
+	delete(r.revalidating, nn)
+	return marked
+}
+
+// pollTarget is a subscription whose Activity Log is polled with a credential.
+type pollTarget struct {
+	credentialID   string
+	subscriptionID string
+}
+
+// roleAssignmentPoller polls the Activity Log of the subscriptions that the rules of AzureValidators
+// with spec.revalidateOnRoleAssignmentChanges refer to, and triggers the re-validation of the
+// AzureValidators that role assignment writes and deletes affect. A change affects an
+// AzureValidator if it's for the principal of one of its RBAC or template permission rules, or at
+// one of their scopes or a scope containing it.
+type roleAssignmentPoller struct {
+	r        *AzureValidatorReconciler
+	log      logr.Logger
+	interval time.Duration
+	events   chan<- event.GenericEvent
+
+	// lastPolled holds when each target was last polled successfully.
+	lastPolled map[pollTarget]time.Time
+	// handled holds the IDs of the events that have been handled, with their timestamps, because
+	// consecutive polls overlap.
+	handled map[string]time.Time
+}
+
+func newRoleAssignmentPoller(r *AzureValidatorReconciler, interval time.Duration, events chan<- event.GenericEvent) *roleAssignmentPoller {
+	return &roleAssignmentPoller{
+		r:          r,
+		log:        r.Log.WithName("RoleAssignmentPoller"),
+		interval:   interval,
+		events:     events,
+		lastPolled: map[pollTarget]time.Time{},
+		handled:    map[string]time.Time{},
+	}
+}
+
+// Start implements manager.Runnable. It polls until ctx is cancelled.
+func (p *roleAssignmentPoller) Start(ctx context.Context) error {
+	p.log.Info("Polling the Activity Log for role assignment changes", "interval", p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+// poll polls each target once, with the credential of the first AzureValidator that refers to it,
+// and triggers the re-validation of the AzureValidators that the new events affect. A target that
+// fails to be polled is polled from its previous poll again next time.
+func (p *roleAssignmentPoller) poll(ctx context.Context) {
+	validators := &v1alpha1.AzureValidatorList{}
+	if err := p.r.List(ctx, validators); err != nil {
+		p.log.Error(err, "failed to list AzureValidators")
+		return
+	}
+
+	now := p.r.now()
+	polled := map[pollTarget]bool{}
+	for i := range validators.Items {
+		validator := &validators.Items[i]
+		if !revalidatesOnRoleAssignmentChanges(validator) || (!validator.Spec.Auth.Implicit && validator.Spec.Auth.SecretName == "") {
+			continue
+		}
+		for _, scope := range roleAssignmentRuleScopes(validator) {
+			target := pollTarget{credentialID: credentialID(validator), subscriptionID: azure_utils.SubscriptionIDFromScope(scope)}
+			if target.subscriptionID == "" || polled[target] {
+				continue
+			}
+			polled[target] = true
+
+			l := p.log.WithValues("subscriptionID", target.subscriptionID, "credential", target.credentialID)
+			events, err := p.listEvents(ctx, validator, target, now)
+			if err != nil {
+				l.Error(err, "failed to list role assignment changes")
+				continue
+			}
+			p.lastPolled[target] = now
+			for _, e := range events {
+				p.handle(ctx, l, e)
+			}
+		}
+	}
+
+	// Forget events that are older than the start of any future poll.
+	for id, ts := range p.handled {
+		if now.Sub(ts) > p.interval+2*activityLogLookback {
+			delete(p.handled, id)
+		}
+	}
+}
+
+// listEvents lists the Activity Log events of a target since its previous poll (or, the first
+// time, since one interval ago), with the credential of an AzureValidator.
+func (p *roleAssignmentPoller) listEvents(ctx context.Context, validator *v1alpha1.AzureValidator, target pollTarget, now time.Time) ([]*armmonitor.EventData, error) {
+	since, ok := p.lastPolled[target]
+	if !ok {
+		since = now.Add(-p.interval)
+	}
+	since = since.Add(-activityLogLookback)
+
+	azureAPI, err := p.r.azureAPI(validator)
+	if err != nil {
+		return nil, err
+	}
+	azClient, err := azureAPI.ActivityLogs(target.subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return azure_utils.NewAzureActivityLogsClient(ctx, azClient, target.subscriptionID).ListAuthorizationEvents(since, now)
+}
+
+// handle triggers the re-validation of the AzureValidators that an event affects, unless the event
+// isn't a successful role assignment write or delete, or has been handled already.
+func (p *roleAssignmentPoller) handle(ctx context.Context, l logr.Logger, e *armmonitor.EventData) {
+	operation := localizedValue(e.OperationName)
+	if !strings.EqualFold(operation, opRoleAssignmentWrite) && !strings.EqualFold(operation, opRoleAssignmentDelete) {
+		return
+	}
+	if !strings.EqualFold(localizedValue(e.Status), "Succeeded") || e.ResourceID == nil {
+		return
+	}
+	if e.EventDataID != nil {
+		if _, ok := p.handled[*e.EventDataID]; ok {
+			return
+		}
+		ts := p.r.now()
+		if e.EventTimestamp != nil {
+			ts = *e.EventTimestamp
+		}
+		p.handled[*e.EventDataID] = ts
+	}
+
+	scope := roleAssignmentScope(*e.ResourceID)
+	principalID := eventPrincipalID(e.Properties)
+	affected, err := p.affectedValidators(ctx, scope, principalID)
+	if err != nil {
+		l.Error(err, "failed to find AzureValidators affected by role assignment change", "roleAssignment", *e.ResourceID)
+		return
+	}
+	for _, validator := range affected {
+		nn := ktypes.NamespacedName{Name: validator.Name, Namespace: validator.Namespace}
+		l.Info("Role assignment changed. Re-validating AzureValidator.", "validator", nn, "operation", operation, "roleAssignment", *e.ResourceID, "principalID", principalID)
+		p.r.markRevalidation(nn)
+		select {
+		case p.events <- event.GenericEvent{Object: validator}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// affectedValidators returns the AzureValidators that revalidate on role assignment changes and
+// have a rule for a principal, or at a scope or a scope it contains. principalID may be empty if the
+// event doesn't say.
+func (p *roleAssignmentPoller) affectedValidators(ctx context.Context, scope, principalID string) ([]*v1alpha1.AzureValidator, error) {
+	lookups := []client.MatchingFields{{indexRoleScopes: strings.ToLower(scope)}}
+	if principalID != "" {
+		lookups = append(lookups, client.MatchingFields{indexRolePrincipals: strings.ToLower(principalID)})
+	}
+
+	var affected []*v1alpha1.AzureValidator
+	found := map[ktypes.NamespacedName]bool{}
+	for _, fields := range lookups {
+		validators := &v1alpha1.AzureValidatorList{}
+		if err := p.r.List(ctx, validators, fields); err != nil {
+			return nil, err
+		}
+		for i := range validators.Items {
+			validator := &validators.Items[i]
+			nn := ktypes.NamespacedName{Name: validator.Name, Namespace: validator.Namespace}
+			if found[nn] || !revalidatesOnRoleAssignmentChanges(validator) {
+				continue
+			}
+			found[nn] = true
+			affected = append(affected, validator)
+		}
+	}
+	return affected, nil
+}
+
+// roleAssignmentScope returns the scope of a role assignment from its ID.
+func roleAssignmentScope(roleAssignmentID string) string {
+	const marker = "/providers/microsoft.authorization/roleassignments/"
+	if i := strings.LastIndex(strings.ToLower(roleAssignmentID), marker); i >= 0 {
+		return roleAssignmentID[:i]
+	}
+	return roleAssignmentID
+}
+
+// eventPrincipalID returns the principal ID of a role assignment from the properties of an Activity
+// Log event of a write (whose request body holds the role assignment) or delete (whose response
+// body does). Returns an empty string if neither does.
+func eventPrincipalID(properties map[string]*string) string {
+	for key, body := range properties {
+		if body == nil || (!strings.EqualFold(key, "requestbody") && !strings.EqualFold(key, "responseBody")) {
+			continue
+		}
+		var roleAssignment map[string]any
+		if err := json.Unmarshal([]byte(*body), &roleAssignment); err != nil {
+			continue
+		}
+		props, _ := valueFold(roleAssignment, "properties").(map[string]any)
+		if principalID, ok := valueFold(props, "principalId").(string); ok && principalID != "" {
+			return principalID
+		}
+	}
+	return ""
+}
+
+// valueFold returns the value of a key of m, matched case-insensitively. The bodies in Activity
+// Log events aren't consistently cased.
+func valueFold(m map[string]any, key string) any {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+func localizedValue(s *armmonitor.LocalizableString) string {
+	if s == nil || s.Value == nil {
+		return ""
+	}
+	return *s.Value
+}
+
+// credentialID returns the ID that the Azure credential of an AzureValidator is cached by.
+func credentialID(validator *v1alpha1.AzureValidator) string {
+	if validator.Spec.Auth.Implicit {
+		return "implicit"
+	}
+	return fmt.Sprintf("secret/%s/%s", validator.AuthSecretNamespace(), validator.Spec.Auth.SecretName)
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// indexConfigMaps indexes AzureValidators by the names of the ConfigMaps they load rules or
+// templates from, so that changes to the ConfigMaps trigger their reconciles.
+const indexConfigMaps = "spec.configMaps"
+
+// indexConfigMapNames returns the values of indexConfigMaps for an AzureValidator.
+func indexConfigMapNames(obj client.Object) []string {
+	validator, ok := obj.(*v1alpha1.AzureValidator)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, src := range validator.Spec.RulesFrom {
+		names = appendUnique(names, src.ConfigMapName)
+	}
+	for _, rule := range validator.Spec.TemplatePermissionRules {
+		if rule.TemplateConfigMapRef != nil {
+			names = appendUnique(names, rule.TemplateConfigMapRef.Name)
+		}
+	}
+	return names
+}
+
+// validatorsForConfigMap maps a ConfigMap to reconcile requests for the AzureValidators in its
+// namespace that load rules or templates from it.
+func (r *AzureValidatorReconciler) validatorsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	validators := &v1alpha1.AzureValidatorList{}
+	if err := r.List(ctx, validators, client.InNamespace(obj.GetNamespace()), client.MatchingFields{indexConfigMaps: obj.GetName()}); err != nil {
+		r.Log.Error(err, "failed to list AzureValidators referring to ConfigMap", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+	return mappedRequests(validators.Items, "ConfigMap")
+}
+
+// loadRulesFrom returns a copy of an AzureValidator whose spec has the rules of its rulesFrom
+// ConfigMaps appended to its own, and the result of the spec-load condition, which is nil if it has
+// no rulesFrom. A key that can't be read or parsed contributes no rules, and rules whose names
+// conflict with earlier rules of the same type are left out, so the AzureValidator's own rules take
+// precedence. Either is reported as a failure of the spec-load condition.
+func (r *AzureValidatorReconciler) loadRulesFrom(ctx context.Context, l logr.Logger, validator *v1alpha1.AzureValidator) (*v1alpha1.AzureValidator, *types.ValidationRuleResult) {
+	if len(validator.Spec.RulesFrom) == 0 {
+		return validator, nil
+	}
+	validator = validator.DeepCopy()
+
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = "All rules were loaded from rulesFrom."
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, constants.SpecLoadRuleName)
+	latestCondition.ValidationType = constants.ValidationTypeSpecLoad
+	validationResult := &types.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	for _, src := range validator.Spec.RulesFrom {
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, ktypes.NamespacedName{Name: src.ConfigMapName, Namespace: validator.Namespace}, cm); err != nil {
+			if apierrs.IsNotFound(err) {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("ConfigMap %s not found.", src.ConfigMapName))
+			} else {
+				l.Error(err, "failed to get ConfigMap with rules", "configMap", src.ConfigMapName)
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Failed to get ConfigMap %s: %v.", src.ConfigMapName, err))
+			}
+			continue
+		}
+
+		keys := src.Keys
+		if len(keys) == 0 {
+			for key := range cm.Data {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+		}
+		for _, key := range keys {
+			data, ok := cm.Data[key]
+			if !ok {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("ConfigMap %s has no key %s.", src.ConfigMapName, key))
+				continue
+			}
+			var set v1alpha1.RuleSet
+			if err := yaml.UnmarshalStrict([]byte(data), &set); err != nil {
+				latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf("Failed to parse key %s of ConfigMap %s: %v.", key, src.ConfigMapName, err))
+				continue
+			}
+			origin := fmt.Sprintf("key %s of ConfigMap %s", key, src.ConfigMapName)
+			n := mergeRuleSet(&validator.Spec, set, origin, &latestCondition.Failures)
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Loaded %d rule(s) from %s.", n, origin))
+		}
+	}
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = "Some rules couldn't be loaded from rulesFrom. See failures for details."
+		latestCondition.Status = corev1.ConditionFalse
+	}
+	return validator, validationResult
+}
+
+// mergeRuleSet appends the rules of a RuleSet loaded from origin to a spec's rules, and returns how
+// many were appended.
+func mergeRuleSet(spec *v1alpha1.AzureValidatorSpec, set v1alpha1.RuleSet, origin string, failures *[]string) int {
+	n := mergeRules(&spec.RBACRules, set.RBACRules, func(r v1alpha1.RBACRule) string { return r.Name }, "rbacRules", origin, failures)
+	n += mergeRules(&spec.KeyVaultCertificateRules, set.KeyVaultCertificateRules, func(r v1alpha1.KeyVaultCertificateRule) string { return r.Name }, "keyVaultCertificateRules", origin, failures)
+	n += mergeRules(&spec.AKSClusterRules, set.AKSClusterRules, func(r v1alpha1.AKSClusterRule) string { return r.Name }, "aksClusterRules", origin, failures)
+	n += mergeRules(&spec.NATGatewayRules, set.NATGatewayRules, func(r v1alpha1.NATGatewayRule) string { return r.Name }, "natGatewayRules", origin, failures)
+	n += mergeRules(&spec.VNetPeeringRules, set.VNetPeeringRules, func(r v1alpha1.VNetPeeringRule) string { return r.Name }, "vnetPeeringRules", origin, failures)
+	n += mergeRules(&spec.RouteTableRules, set.RouteTableRules, func(r v1alpha1.RouteTableRule) string { return r.Name }, "routeTableRules", origin, failures)
+	n += mergeRules(&spec.ImageCompatibilityRules, set.ImageCompatibilityRules, func(r v1alpha1.ImageCompatibilityRule) string { return r.Name }, "imageCompatibilityRules", origin, failures)
+	n += mergeRules(&spec.ImageReplicationRules, set.ImageReplicationRules, func(r v1alpha1.ImageReplicationRule) string { return r.Name }, "imageReplicationRules", origin, failures)
+	n += mergeRules(&spec.VMSecurityRules, set.VMSecurityRules, func(r v1alpha1.VMSecurityRule) string { return r.Name }, "vmSecurityRules", origin, failures)
+	n += mergeRules(&spec.VMSizeRules, set.VMSizeRules, func(r v1alpha1.VMSizeRule) string { return r.Name }, "vmSizeRules", origin, failures)
+	n += mergeRules(&spec.DiskZoneRules, set.DiskZoneRules, func(r v1alpha1.DiskZoneRule) string { return r.Name }, "diskZoneRules", origin, failures)
+	n += mergeRules(&spec.TemplatePermissionRules, set.TemplatePermissionRules, func(r v1alpha1.TemplatePermissionRule) string { return r.Name }, "templatePermissionRules", origin, failures)
+	n += mergeRules(&spec.PolicyExemptionRules, set.PolicyExemptionRules, func(r v1alpha1.PolicyExemptionRule) string { return r.Name }, "policyExemptionRules", origin, failures)
+	n += mergeRules(&spec.DefenderPlanRules, set.DefenderPlanRules, func(r v1alpha1.DefenderPlanRule) string { return r.Name }, "defenderPlanRules", origin, failures)
+	n += mergeRules(&spec.BudgetRules, set.BudgetRules, func(r v1alpha1.BudgetRule) string { return r.Name }, "budgetRules", origin, failures)
+	n += mergeRules(&spec.ResourceLockRules, set.ResourceLockRules, func(r v1alpha1.ResourceLockRule) string { return r.Name }, "resourceLockRules", origin, failures)
+	n += mergeRules(&spec.FirewallPolicyRules, set.FirewallPolicyRules, func(r v1alpha1.FirewallPolicyRule) string { return r.Name }, "firewallPolicyRules", origin, failures)
+	n += mergeRules(&spec.BastionRules, set.BastionRules, func(r v1alpha1.BastionRule) string { return r.Name }, "bastionRules", origin, failures)
+	n += mergeRules(&spec.DDoSProtectionRules, set.DDoSProtectionRules, func(r v1alpha1.DDoSProtectionRule) string { return r.Name }, "ddosProtectionRules", origin, failures)
+	n += mergeRules(&spec.PublicIPPrefixRules, set.PublicIPPrefixRules, func(r v1alpha1.PublicIPPrefixRule) string { return r.Name }, "publicIPPrefixRules", origin, failures)
+	n += mergeRules(&spec.EventHubRules, set.EventHubRules, func(r v1alpha1.EventHubRule) string { return r.Name }, "eventHubRules", origin, failures)
+	n += mergeRules(&spec.ApplicationGatewayRules, set.ApplicationGatewayRules, func(r v1alpha1.ApplicationGatewayRule) string { return r.Name }, "applicationGatewayRules", origin, failures)
+	n += mergeRules(&spec.CosmosDBRules, set.CosmosDBRules, func(r v1alpha1.CosmosDBRule) string { return r.Name }, "cosmosDBRules", origin, failures)
+	n += mergeRules(&spec.SQLServerRules, set.SQLServerRules, func(r v1alpha1.SQLServerRule) string { return r.Name }, "sqlServerRules", origin, failures)
+	n += mergeRules(&spec.GlobalEndpointRules, set.GlobalEndpointRules, func(r v1alpha1.GlobalEndpointRule) string { return r.Name }, "globalEndpointRules", origin, failures)
+	n += mergeRules(&spec.ExpressRouteRules, set.ExpressRouteRules, func(r v1alpha1.ExpressRouteRule) string { return r.Name }, "expressRouteRules", origin, failures)
+	n += mergeRules(&spec.VPNGatewayRules, set.VPNGatewayRules, func(r v1alpha1.VPNGatewayRule) string { return r.Name }, "vpnGatewayRules", origin, failures)
+	n += mergeRules(&spec.NetworkWatcherRules, set.NetworkWatcherRules, func(r v1alpha1.NetworkWatcherRule) string { return r.Name }, "networkWatcherRules", origin, failures)
+	n += mergeRules(&spec.ProximityPlacementGroupRules, set.ProximityPlacementGroupRules, func(r v1alpha1.ProximityPlacementGroupRule) string { return r.Name }, "proximityPlacementGroupRules", origin, failures)
+	n += mergeRules(&spec.EncryptionAtHostRules, set.EncryptionAtHostRules, func(r v1alpha1.EncryptionAtHostRule) string { return r.Name }, "encryptionAtHostRules", origin, failures)
+	n += mergeRules(&spec.GroupMembershipRules, set.GroupMembershipRules, func(r v1alpha1.GroupMembershipRule) string { return r.Name }, "groupMembershipRules", origin, failures)
+	n += mergeRules(&spec.AppPermissionRules, set.AppPermissionRules, func(r v1alpha1.AppPermissionRule) string { return r.Name }, "appPermissionRules", origin, failures)
+	n += mergeRules(&spec.BlobContainerRules, set.BlobContainerRules, func(r v1alpha1.BlobContainerRule) string { return r.Name }, "blobContainerRules", origin, failures)
+	n += mergeRules(&spec.StorageNetworkRules, set.StorageNetworkRules, func(r v1alpha1.StorageNetworkRule) string { return r.Name }, "storageNetworkRules", origin, failures)
+	n += mergeRules(&spec.FileShareRules, set.FileShareRules, func(r v1alpha1.FileShareRule) string { return r.Name }, "fileShareRules", origin, failures)
+	n += mergeRules(&spec.SubnetDelegationRules, set.SubnetDelegationRules, func(r v1alpha1.SubnetDelegationRule) string { return r.Name }, "subnetDelegationRules", origin, failures)
+	n += mergeRules(&spec.MonitorAlertRules, set.MonitorAlertRules, func(r v1alpha1.MonitorAlertRule) string { return r.Name }, "monitorAlertRules", origin, failures)
+	n += mergeRules(&spec.ActivityLogExportRules, set.ActivityLogExportRules, func(r v1alpha1.ActivityLogExportRule) string { return r.Name }, "activityLogExportRules", origin, failures)
+	n += mergeRules(&spec.ImageDeprecationRules, set.ImageDeprecationRules, func(r v1alpha1.ImageDeprecationRule) string { return r.Name }, "imageDeprecationRules", origin, failures)
+	n += mergeRules(&spec.ZoneRedundancyRules, set.ZoneRedundancyRules, func(r v1alpha1.ZoneRedundancyRule) string { return r.Name }, "zoneRedundancyRules", origin, failures)
+	n += mergeRules(&spec.CustomLocationRules, set.CustomLocationRules, func(r v1alpha1.CustomLocationRule) string { return r.Name }, "customLocationRules", origin, failures)
+	n += mergeRules(&spec.KeyVaultRecoveryRules, set.KeyVaultRecoveryRules, func(r v1alpha1.KeyVaultRecoveryRule) string { return r.Name }, "keyVaultRecoveryRules", origin, failures)
+	return n
+}
+
+// mergeRules appends loaded rules of a type to the rules, except those without a name or with the
+// name of a rule already there, which are reported as failures. Returns how many were appended.
+func mergeRules[T any](rules *[]T, loaded []T, name func(T) string, field, origin string, failures *[]string) int {
+	n := 0
+	for _, rule := range loaded {
+		ruleName := name(rule)
+		switch {
+		case ruleName == "":
+			*failures = append(*failures, fmt.Sprintf("A rule in %s of %s has no name.", field, origin))
+		case slices.ContainsFunc(*rules, func(r T) bool { return name(r) == ruleName }):
+			*failures = append(*failures, fmt.Sprintf("Rule %s in %s of %s wasn't loaded, because its name conflicts with another rule's.", ruleName, field, origin))
+		default:
+			*rules = append(*rules, rule)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// notEvaluatedRequeueAfter is how long to wait before continuing with the rules that a reconcile
+// didn't evaluate before its run deadline.
+const notEvaluatedRequeueAfter = time.Second
+
+// ruleEvaluation is a rule that a reconcile evaluates with eval.
+type ruleEvaluation struct {
+	name           string
+	validationType string
+	labels         map[string]string
+	severity       v1alpha1.Severity
+	hash           string
+	eval           func() (*types.ValidationRuleResult, error)
+	// graceEnd is when the rule's grace period ends, if it's pending within one.
+	graceEnd time.Time
+}
+
+// ruleRun is the result of a rule in a reconcile, and its outcome.
+type ruleRun struct {
+	vrr     *types.ValidationRuleResult
+	err     error
+	outcome ruleOutcome
+}
+
+// runDeadline returns how long a reconcile of an AzureValidator may spend evaluating rules, or zero
+// if there's no limit.
+func runDeadline(validator *v1alpha1.AzureValidator) time.Duration {
+	if validator.Spec.RunDeadline == nil {
+		return 0
+	}
+	return max(validator.Spec.RunDeadline.Duration, 0)
+}
+
+// runOrder returns the order in which a reconcile evaluates rules, as indices into evals: the
+// rules that the previous reconcile didn't evaluate before its run deadline first, then the
+// others, each in the order of the spec's rules.
+func runOrder(evals []ruleEvaluation, prev []v1alpha1.RuleResult) []int {
+	order := make([]int, len(evals))
+	for i := range evals {
+		order[i] = i
+	}
+	notEvaluated := func(i int) bool {
+		p := findRuleResult(prev, evals[i].name, evals[i].validationType)
+		return p != nil && p.NotEvaluated
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch na, nb := notEvaluated(a), notEvaluated(b); {
+		case na && !nb:
+			return -1
+		case nb && !na:
+			return 1
+		}
+		return 0
+	})
+	return order
+}
+
+// runRules runs the rules of a reconcile in their runOrder, and returns their runs in the order of
+// evals. A rule's previous result is reused if reuse can; otherwise, the rule is evaluated with
+// evaluate, unless the deadline (none if zero) has elapsed since the first rule was, in which case
+// it fails as not evaluated. A rule that is being evaluated when the deadline elapses is finished.
+func runRules(evals []ruleEvaluation, prev []v1alpha1.RuleResult, deadline time.Duration, clk clock.PassiveClock, reuse func(ruleEvaluation) (ruleRun, bool), evaluate func(ruleEvaluation) ruleRun) []ruleRun {
+	runs := make([]ruleRun, len(evals))
+	start := clk.Now()
+	for _, i := range runOrder(evals, prev) {
+		if run, ok := reuse(evals[i]); ok {
+			runs[i] = run
+			continue
+		}
+		if now := clk.Now(); deadline > 0 && now.Sub(start) >= deadline {
+			runs[i] = notEvaluatedRun(evals[i], deadline, now)
+			continue
+		}
+		runs[i] = evaluate(evals[i])
+	}
+	return runs
+}
+
+// notEvaluatedRun builds the run of a rule that wasn't evaluated, because the run deadline elapsed
+// before it was. Its outcome has no hash, so it is never reused.
+func notEvaluatedRun(e ruleEvaluation, deadline time.Duration, now time.Time) ruleRun {
+	state := vapi.ValidationFailed
+	condition := vapi.DefaultValidationCondition()
+	condition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, e.name)
+	condition.ValidationType = e.validationType
+	condition.Message = messages.NotEvaluated
+	condition.Failures = []string{fmt.Sprintf(messages.RunDeadlineFailure, deadline)}
+	condition.Status = corev1.ConditionFalse
+	return ruleRun{
+		vrr: &types.ValidationRuleResult{Condition: &condition, State: &state},
+		outcome: ruleOutcome{
+			name:           e.name,
+			validationType: e.validationType,
+			state:          state,
+			start:          now,
+			notEvaluated:   true,
+		},
+	}
+}
+
+// requeueAfterNotEvaluated determines how long to wait before continuing with the rules that
+// weren't evaluated before the run deadline. Returns false if all rules were evaluated.
+func requeueAfterNotEvaluated(outcomes []ruleOutcome) (time.Duration, bool) {
+	for _, o := range outcomes {
+		if o.notEvaluated {
+			return notEvaluatedRequeueAfter, true
+		}
+	}
+	return 0, false
+}
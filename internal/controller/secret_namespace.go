@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/patch"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+)
+
+// ErrSecretNamespaceNotAllowed is returned when an AzureValidator's auth Secret is in a namespace
+// that the plugin may not read Secrets from.
+var ErrSecretNamespaceNotAllowed = errors.New("auth.secretNamespace isn't in --allowed-secret-namespaces")
+
+// reasonSecretNamespaceNotAllowed is the reason of the events recorded when an AzureValidator's
+// rules aren't evaluated, because its auth Secret is in a namespace that isn't allowed.
+const reasonSecretNamespaceNotAllowed = "SecretNamespaceNotAllowed"
+
+// secretNamespaceAllowed returns whether the plugin may read an AzureValidator's auth Secret: it's
+// in the AzureValidator's namespace, or in one of AllowedSecretNamespaces.
+func (r *AzureValidatorReconciler) secretNamespaceAllowed(validator *v1alpha1.AzureValidator) bool {
+	ns := validator.AuthSecretNamespace()
+	return validator.Spec.Auth.Implicit || ns == validator.Namespace || slices.Contains(r.AllowedSecretNamespaces, ns)
+}
+
+// updateSecretNamespaceCondition records in an AzureValidator's status whether its auth Secret may
+// be read from another namespace. The status is only patched when that changes. Nothing is recorded
+// for AzureValidators whose auth Secret has always been in their own namespace.
+func (r *AzureValidatorReconciler) updateSecretNamespaceCondition(ctx context.Context, validator *v1alpha1.AzureValidator, allowed bool) error {
+	ns := validator.AuthSecretNamespace()
+	condition := metav1.Condition{
+		Type:               constants.ConditionTypeSecretNamespaceAllowed,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: validator.Generation,
+		Reason:             "Allowed",
+		Message:            fmt.Sprintf("The auth Secret may be read from namespace %s.", ns),
+	}
+	if !allowed {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NotAllowed"
+		condition.Message = fmt.Sprintf("Validation is skipped, because the auth Secret is in namespace %s, which isn't in the plugin's --allowed-secret-namespaces (%s).",
+			ns, allowedSecretNamespacesString(r.AllowedSecretNamespaces))
+	}
+
+	existing := meta.FindStatusCondition(validator.Status.Conditions, constants.ConditionTypeSecretNamespaceAllowed)
+	crossNamespace := !validator.Spec.Auth.Implicit && ns != validator.Namespace
+	if (existing == nil && !crossNamespace) || (existing != nil && existing.Status == condition.Status && existing.Message == condition.Message) {
+		return nil
+	}
+
+	p, err := patch.NewHelper(validator, r.Client)
+	if err != nil {
+		return err
+	}
+	meta.SetStatusCondition(&validator.Status.Conditions, condition)
+	return p.Patch(ctx, validator)
+}
+
+// recordSecretNamespaceNotAllowed records an event about an AzureValidator whose rules aren't
+// evaluated, because its auth Secret is in a namespace that isn't allowed.
+func (r *AzureValidatorReconciler) recordSecretNamespaceNotAllowed(validator *v1alpha1.AzureValidator) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(validator, corev1.EventTypeWarning, reasonSecretNamespaceNotAllowed,
+		"Skipping validation, because auth Secret %s/%s is in a namespace that isn't in the plugin's --allowed-secret-namespaces (%s)",
+		validator.AuthSecretNamespace(), validator.Spec.Auth.SecretName, allowedSecretNamespacesString(r.AllowedSecretNamespaces))
+}
+
+// allowedSecretNamespacesString lists allowed secret namespaces for condition messages and events.
+func allowedSecretNamespacesString(namespaces []string) string {
+	if len(namespaces) == 0 {
+		return "none"
+	}
+	return strings.Join(namespaces, ", ")
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// indexSecrets indexes AzureValidators by the namespaced names of the Secrets they refer to, so
+// that changes to a Secret only trigger the reconciles of the AzureValidators that use it. Secrets
+// may be in other namespaces than the AzureValidators, so the values include their namespaces.
+const indexSecrets = "spec.secrets"
+
+// indexSecretNames returns the values of indexSecrets for an AzureValidator: its auth Secret, unless
+// it uses implicit auth. Any other Secret that the spec comes to refer to belongs here too.
+func indexSecretNames(obj client.Object) []string {
+	validator, ok := obj.(*v1alpha1.AzureValidator)
+	if !ok {
+		return nil
+	}
+	var names []string
+	if !validator.Spec.Auth.Implicit && validator.Spec.Auth.SecretName != "" {
+		names = appendUnique(names, secretIndexValue(validator.AuthSecretNamespace(), validator.Spec.Auth.SecretName))
+	}
+	return names
+}
+
+func secretIndexValue(namespace, name string) string {
+	return ktypes.NamespacedName{Namespace: namespace, Name: name}.String()
+}
+
+// validatorsForSecret maps a Secret to reconcile requests for the AzureValidators that refer to it,
+// in any namespace. Only the Secret's metadata is watched, so its data is never cached.
+func (r *AzureValidatorReconciler) validatorsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	validators := &v1alpha1.AzureValidatorList{}
+	if err := r.List(ctx, validators, client.MatchingFields{indexSecrets: secretIndexValue(obj.GetNamespace(), obj.GetName())}); err != nil {
+		r.Log.Error(err, "failed to list AzureValidators referring to Secret", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+	return mappedRequests(validators.Items, "Secret")
+}
+
+// mappedRequests returns reconcile requests for the AzureValidators that refer to a changed object
+// of the given kind, and counts them in mappedRequeues.
+func mappedRequests(validators []v1alpha1.AzureValidator, kind string) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(validators))
+	for _, v := range validators {
+		requests = append(requests, reconcile.Request{NamespacedName: ktypes.NamespacedName{Name: v.Name, Namespace: v.Namespace}})
+		mappedRequeues.WithLabelValues(v.Namespace, v.Name, kind).Inc()
+	}
+	return requests
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+)
+
+// SelfTestOptions configures SelfTest.
+type SelfTestOptions struct {
+	// Client reads AzureValidators, and the secrets and ConfigMaps they refer to.
+	Client client.Client
+	Log    logr.Logger
+	// Azure configures the Azure service clients, like AzureValidatorReconciler.Azure.
+	Azure azure_utils.ClientFactoryOptions
+	// SubscriptionIDs are checked with the plugin's implicit credential, on top of the
+	// subscriptions of existing AzureValidators.
+	SubscriptionIDs []string
+	// AllowedSecretNamespaces are the namespaces, besides their own, that AzureValidators may read
+	// their auth Secret from, like AzureValidatorReconciler.AllowedSecretNamespaces.
+	AllowedSecretNamespaces []string
+}
+
+// SelfTest checks that the plugin is deployed so that it can evaluate rules, without starting the
+// controller: that the AzureValidator CRD serves v1alpha1, that each Azure credential of the
+// existing AzureValidators (and the implicit credential, if SubscriptionIDs are given) can acquire
+// an ARM access token, and that with it, the plugin can read role assignments and role definitions
+// in each subscription, as the preflight check does. Credentials are configured as the reconciler
+// configures them. It writes a line per check to w, then a summary, and returns whether all checks
+// passed.
+func SelfTest(ctx context.Context, o SelfTestOptions, w io.Writer) bool {
+	r := &AzureValidatorReconciler{Client: o.Client, Log: o.Log, Azure: o.Azure, AllowedSecretNamespaces: o.AllowedSecretNamespaces}
+	report := &selfTestReport{w: w}
+
+	validators := &v1alpha1.AzureValidatorList{}
+	if err := o.Client.List(ctx, validators); err != nil {
+		report.fail("AzureValidator CRD", "AzureValidators of %s can't be listed, so the CRD may be missing or outdated: %v", v1alpha1.GroupVersion, err)
+	} else {
+		report.pass("AzureValidator CRD", "%s is served, with %d AzureValidator(s).", v1alpha1.GroupVersion, len(validators.Items))
+	}
+
+	var targets []*selfTestTarget
+	byCredential := map[string]*selfTestTarget{}
+	target := func(validator *v1alpha1.AzureValidator) *selfTestTarget {
+		id := credentialID(validator)
+		if t, ok := byCredential[id]; ok {
+			return t
+		}
+		t := &selfTestTarget{validator: validator, seen: map[string]bool{}}
+		byCredential[id] = t
+		targets = append(targets, t)
+		return t
+	}
+	if len(o.SubscriptionIDs) > 0 {
+		implicit := &v1alpha1.AzureValidator{Spec: v1alpha1.AzureValidatorSpec{Auth: v1alpha1.AzureAuth{Implicit: true}}}
+		target(implicit).add(o.SubscriptionIDs...)
+	}
+	for i := range validators.Items {
+		validator, _ := r.loadRulesFrom(ctx, o.Log, &validators.Items[i])
+		if !validator.Spec.Auth.Implicit && validator.Spec.Auth.SecretName == "" {
+			report.fail("AzureValidator "+validator.Namespace+"/"+validator.Name, "%v", ErrSecretNameRequired)
+			continue
+		}
+		target(validator).add(specSubscriptionIDs(validator.Spec)...)
+	}
+
+	for _, t := range targets {
+		credential := "Credential " + credentialID(t.validator)
+		azureAPI, err := r.azureAPI(t.validator)
+		if err != nil {
+			report.fail(credential, "%v", err)
+			continue
+		}
+		principalID, err := azureAPI.PrincipalID(ctx)
+		if err != nil {
+			report.fail(credential, "%v", err)
+			continue
+		}
+		report.pass(credential, "Acquired an ARM access token for principal %s.", principalID)
+
+		for _, sub := range t.subscriptions {
+			check := fmt.Sprintf("Subscription %s (%s)", sub, credentialID(t.validator))
+			vrr, err := reconcilePreflight(ctx, o.Log, azureAPI, []string{"/subscriptions/" + sub})
+			switch {
+			case err != nil:
+				report.fail(check, "%v", err)
+			case len(vrr.Condition.Failures) > 0:
+				report.fail(check, "%s", strings.Join(vrr.Condition.Failures, " "))
+			default:
+				report.pass(check, "%s", vrr.Condition.Message)
+			}
+		}
+	}
+
+	report.summarize()
+	return report.failed == 0
+}
+
+// selfTestTarget is a credential to check, and the subscriptions to check with it.
+type selfTestTarget struct {
+	validator     *v1alpha1.AzureValidator
+	subscriptions []string
+	// keys = lowercase subscription IDs
+	seen map[string]bool
+}
+
+func (t *selfTestTarget) add(subscriptionIDs ...string) {
+	for _, sub := range subscriptionIDs {
+		if key := strings.ToLower(sub); !t.seen[key] {
+			t.seen[key] = true
+			t.subscriptions = append(t.subscriptions, sub)
+		}
+	}
+}
+
+// subscriptionIDPattern matches the subscription IDs of the resource IDs and scopes of rules, and
+// their subscriptionId fields.
+var subscriptionIDPattern = regexp.MustCompile(`(?i)(?:/subscriptions/|"subscriptionId":")([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
+
+// specSubscriptionIDs returns the subscriptions that an AzureValidator's rules refer to, in the
+// order they're first referred to.
+func specSubscriptionIDs(spec v1alpha1.AzureValidatorSpec) []string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+	var subs []string
+	for _, m := range subscriptionIDPattern.FindAllStringSubmatch(string(data), -1) {
+		subs = append(subs, m[1])
+	}
+	return subs
+}
+
+// selfTestReport writes the result of each check of a self-test, and counts them.
+type selfTestReport struct {
+	w              io.Writer
+	checks, failed int
+}
+
+func (r *selfTestReport) pass(check, format string, args ...any) {
+	r.checks++
+	fmt.Fprintf(r.w, "PASS %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (r *selfTestReport) fail(check, format string, args ...any) {
+	r.checks++
+	r.failed++
+	fmt.Fprintf(r.w, "FAIL %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (r *selfTestReport) summarize() {
+	if r.failed > 0 {
+		fmt.Fprintf(r.w, "Self-test failed: %d of %d check(s) failed.\n", r.failed, r.checks)
+		return
+	}
+	fmt.Fprintf(r.w, "Self-test passed: %d check(s).\n", r.checks)
+}
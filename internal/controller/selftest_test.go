@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+var _ = Describe("Self-test", func() {
+	const (
+		ruleSubscription = "00000000-0000-0000-0000-000000000000"
+		flagSubscription = "11111111-1111-1111-1111-111111111111"
+		pluginID         = "22222222-2222-2222-2222-222222222222"
+	)
+	validator := func() *v1alpha1.AzureValidator {
+		return &v1alpha1.AzureValidator{
+			ObjectMeta: metav1.ObjectMeta{Name: azureValidatorName + "-self-test", Namespace: validatorNamespace},
+			Spec: v1alpha1.AzureValidatorSpec{
+				Auth: v1alpha1.AzureAuth{Implicit: true},
+				RBACRules: []v1alpha1.RBACRule{{
+					Name:        "rule-1",
+					PrincipalID: "p_id",
+					Permissions: []v1alpha1.PermissionSet{{
+						Scope:   "/subscriptions/" + ruleSubscription + "/resourceGroups/rg",
+						Actions: []v1alpha1.ActionStr{"action_1"},
+					}},
+				}},
+			},
+		}
+	}
+
+	// selfTest runs the self-test and returns whether it passed, and its report's lines.
+	selfTest := func(c client.Client, azure *fakeAzure) (bool, []string) {
+		var out bytes.Buffer
+		ok := SelfTest(context.Background(), SelfTestOptions{
+			Client:          c,
+			Log:             ctrl.Log.WithName("self-test"),
+			Azure:           azure.options(),
+			SubscriptionIDs: []string{flagSubscription},
+		}, &out)
+		return ok, strings.Split(strings.TrimSpace(out.String()), "\n")
+	}
+
+	It("Should pass when the CRD is installed, the credential acquires tokens, and every subscription is readable", func() {
+		ok, report := selfTest(newFakeClient(validator()), &fakeAzure{principalID: pluginID})
+		Expect(report).To(Equal([]string{
+			"PASS AzureValidator CRD: validation.spectrocloud.labs/v1alpha1 is served, with 1 AzureValidator(s).",
+			"PASS Credential implicit: Acquired an ARM access token for principal " + pluginID + ".",
+			"PASS Subscription " + flagSubscription + " (implicit): Plugin can read role assignments and role definitions at all scopes.",
+			"PASS Subscription " + ruleSubscription + " (implicit): Plugin can read role assignments and role definitions at all scopes.",
+			"Self-test passed: 4 check(s).",
+		}))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("Should fail when the plugin can't read a subscription", func() {
+		azure := &fakeAzure{principalID: pluginID, forbiddenSubscriptions: []string{flagSubscription}}
+		ok, report := selfTest(newFakeClient(validator()), azure)
+		Expect(report).To(ContainElement("FAIL Subscription " + flagSubscription + " (implicit): Plugin lacks " +
+			"Microsoft.Authorization/roleAssignments/read, Microsoft.Authorization/roleDefinitions/read at scope /subscriptions/" + flagSubscription + "."))
+		Expect(report).To(HaveExactElements(
+			HavePrefix("PASS AzureValidator CRD:"),
+			HavePrefix("PASS Credential implicit:"),
+			HavePrefix("FAIL Subscription "+flagSubscription),
+			HavePrefix("PASS Subscription "+ruleSubscription),
+			Equal("Self-test failed: 1 of 4 check(s) failed."),
+		))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Should fail, without checking subscriptions, when the credential's token doesn't identify the plugin", func() {
+		ok, report := selfTest(newFakeClient(validator()), &fakeAzure{})
+		Expect(report).To(Equal([]string{
+			"PASS AzureValidator CRD: validation.spectrocloud.labs/v1alpha1 is served, with 1 AzureValidator(s).",
+			"FAIL Credential implicit: access token is not a JWT",
+			"Self-test failed: 1 of 2 check(s) failed.",
+		}))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Should fail when the CRD isn't installed, and still check the subscriptions it's given", func() {
+		c := interceptor.NewClient(newFakeClient().(client.WithWatch), interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				return &meta.NoKindMatchError{GroupKind: v1alpha1.GroupVersion.WithKind("AzureValidator").GroupKind(), SearchedVersions: []string{v1alpha1.GroupVersion.Version}}
+			},
+		})
+		ok, report := selfTest(c, &fakeAzure{principalID: pluginID})
+		Expect(report).To(HaveExactElements(
+			HavePrefix("FAIL AzureValidator CRD: AzureValidators of validation.spectrocloud.labs/v1alpha1 can't be listed"),
+			HavePrefix("PASS Credential implicit:"),
+			HavePrefix("PASS Subscription "+flagSubscription),
+			Equal("Self-test failed: 1 of 3 check(s) failed."),
+		))
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha2"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vres "github.com/spectrocloud-labs/validator/pkg/validationresult"
+)
+
+// severityDetail prefixes the detail that carries a rule's severity.
+const severityDetail = "Severity:"
+
+// ruleLists are the JSON names of the spec's rule lists, which v1alpha2 keeps the severities of
+// rules by, keyed by the validation type of their rules.
+var ruleLists = map[string]string{
+	constants.ValidationTypeRBAC:                    "rbacRules",
+	constants.ValidationTypeKeyVaultCertificate:     "keyVaultCertificateRules",
+	constants.ValidationTypeAKSCluster:              "aksClusterRules",
+	constants.ValidationTypeNATGateway:              "natGatewayRules",
+	constants.ValidationTypeVNetPeering:             "vnetPeeringRules",
+	constants.ValidationTypeRouteTable:              "routeTableRules",
+	constants.ValidationTypeImageCompatibility:      "imageCompatibilityRules",
+	constants.ValidationTypeImageReplication:        "imageReplicationRules",
+	constants.ValidationTypeVMSecurity:              "vmSecurityRules",
+	constants.ValidationTypeVMSize:                  "vmSizeRules",
+	constants.ValidationTypeDiskZone:                "diskZoneRules",
+	constants.ValidationTypeTemplatePermission:      "templatePermissionRules",
+	constants.ValidationTypePolicyExemption:         "policyExemptionRules",
+	constants.ValidationTypeDefenderPlan:            "defenderPlanRules",
+	constants.ValidationTypeBudget:                  "budgetRules",
+	constants.ValidationTypeResourceLock:            "resourceLockRules",
+	constants.ValidationTypeFirewallPolicy:          "firewallPolicyRules",
+	constants.ValidationTypeBastion:                 "bastionRules",
+	constants.ValidationTypeDDoSProtection:          "ddosProtectionRules",
+	constants.ValidationTypePublicIPPrefix:          "publicIPPrefixRules",
+	constants.ValidationTypeEventHub:                "eventHubRules",
+	constants.ValidationTypeApplicationGateway:      "applicationGatewayRules",
+	constants.ValidationTypeCosmosDB:                "cosmosDBRules",
+	constants.ValidationTypeSQLServer:               "sqlServerRules",
+	constants.ValidationTypeGlobalEndpoint:          "globalEndpointRules",
+	constants.ValidationTypeExpressRoute:            "expressRouteRules",
+	constants.ValidationTypeVPNGateway:              "vpnGatewayRules",
+	constants.ValidationTypeNetworkWatcher:          "networkWatcherRules",
+	constants.ValidationTypeProximityPlacementGroup: "proximityPlacementGroupRules",
+	constants.ValidationTypeEncryptionAtHost:        "encryptionAtHostRules",
+	constants.ValidationTypeGroupMembership:         "groupMembershipRules",
+	constants.ValidationTypeAppPermission:           "appPermissionRules",
+	constants.ValidationTypeBlobContainer:           "blobContainerRules",
+	constants.ValidationTypeStorageNetwork:          "storageNetworkRules",
+	constants.ValidationTypeFileShare:               "fileShareRules",
+	constants.ValidationTypeSubnetDelegation:        "subnetDelegationRules",
+	constants.ValidationTypeMonitorAlert:            "monitorAlertRules",
+	constants.ValidationTypeActivityLogExport:       "activityLogExportRules",
+	constants.ValidationTypeImageDeprecation:        "imageDeprecationRules",
+	constants.ValidationTypeZoneRedundancy:          "zoneRedundancyRules",
+	constants.ValidationTypeCustomLocation:          "customLocationRules",
+	constants.ValidationTypeKeyVaultRecovery:        "keyVaultRecoveryRules",
+}
+
+// ruleSeverity returns the severity of a rule: the one it was given in v1alpha2, if any, or else the
+// spec's defaultSeverity, or else High. The plugin's own checks, such as the preflight check,
+// aren't rules of a list, and are always High, since rules can't be trusted when they fail.
+func ruleSeverity(validator *v1alpha1.AzureValidator, severities map[string]map[string]v1alpha2.Severity, name, validationType string) v1alpha1.Severity {
+	list, ok := ruleLists[validationType]
+	if !ok {
+		return v1alpha1.SeverityHigh
+	}
+	if s := severities[list][name]; s != "" {
+		return v1alpha1.Severity(s)
+	}
+	if validator.Spec.DefaultSeverity != "" {
+		return validator.Spec.DefaultSeverity
+	}
+	return v1alpha1.SeverityHigh
+}
+
+// setSeverityDetail replaces the severity detail of a condition, so that sinks can route its
+// failures by severity.
+func setSeverityDetail(condition *vapi.ValidationCondition, severity v1alpha1.Severity) {
+	details := make([]string, 0, len(condition.Details)+1)
+	for _, d := range condition.Details {
+		if !strings.HasPrefix(d, severityDetail) {
+			details = append(details, d)
+		}
+	}
+	condition.Details = append(details, fmt.Sprintf("%s %s", severityDetail, severity))
+}
+
+// conditionSeverity returns the severity in a condition's severity detail. Conditions without one,
+// e.g. those reported before severities were, are High.
+func conditionSeverity(condition vapi.ValidationCondition) v1alpha1.Severity {
+	for _, d := range condition.Details {
+		if s, ok := strings.CutPrefix(d, severityDetail); ok {
+			return v1alpha1.Severity(strings.TrimSpace(s))
+		}
+	}
+	return v1alpha1.SeverityHigh
+}
+
+// applySeverities corrects the state of a ValidationResult that the validator library failed
+// because of failing conditions that are only warnings. It fails only if a condition of severity
+// High or Critical failed. Otherwise, it succeeds if a condition succeeded or all conditions
+// failed as warnings, and is in progress if the others are pending.
+func applySeverities(vr *vapi.ValidationResult) {
+	if vr.Status.State != vapi.ValidationFailed {
+		return
+	}
+	state := vapi.ValidationSucceeded
+	pending, succeeded := false, false
+	for _, c := range vr.Status.ValidationConditions {
+		switch c.Status {
+		case corev1.ConditionFalse:
+			if !conditionSeverity(c).Warns() {
+				return
+			}
+		case corev1.ConditionTrue:
+			succeeded = true
+		default:
+			pending = true
+		}
+	}
+	if pending && !succeeded {
+		state = vapi.ValidationInProgress
+	}
+	vr.Status.State = state
+}
+
+// severityPatcher applies the severities of a ValidationResult's conditions to its state before
+// patching it with p.
+type severityPatcher struct {
+	p vres.Patcher
+}
+
+func (p severityPatcher) Patch(ctx context.Context, obj client.Object, opts ...patch.Option) error {
+	if vr, ok := obj.(*vapi.ValidationResult); ok {
+		applySeverities(vr)
+	}
+	return p.p.Patch(ctx, obj, opts...)
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+)
+
+const (
+	// snapshotDataKey is the key of a snapshot ConfigMap's data that holds the snapshot.
+	snapshotDataKey = "snapshot.json"
+	// snapshotBlobTimeFormat formats the times in the names of snapshot blobs, so that they sort
+	// by time.
+	snapshotBlobTimeFormat = "20060102T150405Z"
+)
+
+// snapshotDocument is the JSON of a snapshot of what a reconcile's rules observed in Azure.
+type snapshotDocument struct {
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	Generation int64       `json:"generation"`
+	TakenAt    metav1.Time `json:"takenAt"`
+	// The responses to the rules' GET requests, in the order they were received.
+	Responses []azure_utils.SnapshotResponse `json:"responses"`
+	// How many responses were left out, because the snapshot would have been larger than its
+	// maxBytes with them.
+	OmittedResponses int `json:"omittedResponses,omitempty"`
+}
+
+// snapshotBlobName returns the name of the blob that a snapshot taken at a time is uploaded as.
+func snapshotBlobName(validator *v1alpha1.AzureValidator, doc snapshotDocument) string {
+	return fmt.Sprintf("%s/%s/%s.json", validator.Namespace, validator.Name, doc.TakenAt.UTC().Format(snapshotBlobTimeFormat))
+}
+
+// writeSnapshot writes the snapshot of what a reconcile's rules observed in Azure to the target
+// of the AzureValidator's snapshot configuration: a ConfigMap in its namespace, or a blob in a
+// blob container. Snapshots are best effort, so failing to write one is only logged.
+func (r *AzureValidatorReconciler) writeSnapshot(ctx context.Context, l logr.Logger, validator *v1alpha1.AzureValidator, azureAPI *azure_utils.AzureAPI, snapshot *azure_utils.Snapshot) {
+	cfg := validator.Spec.Snapshot
+	doc := snapshotDocument{
+		Namespace:        validator.Namespace,
+		Name:             validator.Name,
+		Generation:       validator.Generation,
+		TakenAt:          metav1.NewTime(r.now()),
+		Responses:        snapshot.Responses(),
+		OmittedResponses: snapshot.Omitted(),
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		l.Error(err, "failed to marshal snapshot")
+		return
+	}
+
+	if cfg.ConfigMapName != "" {
+		err = r.writeSnapshotConfigMap(ctx, validator, cfg.ConfigMapName, data)
+	} else {
+		err = uploadSnapshot(ctx, azureAPI, cfg.BlobContainerURL, snapshotBlobName(validator, doc), data)
+	}
+	if err != nil {
+		l.Error(err, "failed to write snapshot", "configMapName", cfg.ConfigMapName, "blobContainerUrl", cfg.BlobContainerURL)
+		return
+	}
+	l.Info("Wrote snapshot of what the rules observed", "configMapName", cfg.ConfigMapName, "blobContainerUrl", cfg.BlobContainerURL,
+		"responses", len(doc.Responses), "omittedResponses", doc.OmittedResponses, "bytes", len(data))
+}
+
+// writeSnapshotConfigMap writes a snapshot to a ConfigMap in an AzureValidator's namespace,
+// creating it, owned by the AzureValidator, if it doesn't exist. The ConfigMap's other keys are
+// kept.
+func (r *AzureValidatorReconciler) writeSnapshotConfigMap(ctx context.Context, validator *v1alpha1.AzureValidator, name string, data []byte) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: validator.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.CreationTimestamp.IsZero() {
+			if err := controllerutil.SetOwnerReference(validator, cm, r.Scheme); err != nil {
+				return err
+			}
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[snapshotDataKey] = string(data)
+		return nil
+	})
+	return err
+}
+
+// uploadSnapshot uploads a snapshot as a blob to the blob container at containerURL, with the
+// AzureValidator's Azure credential.
+func uploadSnapshot(ctx context.Context, azureAPI *azure_utils.AzureAPI, containerURL, name string, data []byte) error {
+	blobs, err := azureAPI.Blobs()
+	if err != nil {
+		return err
+	}
+	return blobs.UploadBlockBlob(ctx, containerURL, name, "application/json", data)
+}
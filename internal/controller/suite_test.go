@@ -17,14 +17,26 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gexec"
@@ -32,13 +44,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	v1alpha1 "github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	"github.com/spectrocloud-labs/validator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
@@ -137,9 +153,10 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred(), "failed to init manager")
 
 	err = (&AzureValidatorReconciler{
-		Client: k8sManager.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AzureValidator"),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("AzureValidator"),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("azurevalidator-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred(), "failed to start AzureValidator controller")
 
@@ -182,3 +199,274 @@ var _ = AfterSuite(func() {
 	})()
 	Expect(err).NotTo(HaveOccurred(), "failed to tear down the test environment")
 })
+
+// newFakeClient returns a fake client, with the status subresource enabled for the plugin's types,
+// for specs that drive a dedicated reconciler instead of the shared manager's reconciler.
+//
+// Unlike the API server, the fake client bumps resourceVersion on no-op patches. The validator
+// library's patch helper sends resourceVersion in both its spec and status patches, so we drop it
+// from patches. Otherwise the status patch would always conflict.
+func newFakeClient(objs ...client.Object) client.Client {
+	withoutResourceVersion := func(obj client.Object, p client.Patch) (client.Patch, error) {
+		data, err := p.Data(obj)
+		if err != nil {
+			return nil, err
+		}
+		patch := map[string]any{}
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return nil, err
+		}
+		if metadata, ok := patch["metadata"].(map[string]any); ok {
+			delete(metadata, "resourceVersion")
+		}
+		if data, err = json.Marshal(patch); err != nil {
+			return nil, err
+		}
+		return client.RawPatch(p.Type(), data), nil
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&v1alpha1.AzureValidator{}, &vapi.ValidationResult{}).
+		WithIndex(&v1alpha1.AzureValidator{}, indexRolePrincipals, indexRolePrincipalIDs).
+		WithIndex(&v1alpha1.AzureValidator{}, indexRoleScopes, indexRoleScopeIDs).
+		WithIndex(&v1alpha1.AzureValidator{}, indexConfigMaps, indexConfigMapNames).
+		WithIndex(&v1alpha1.AzureValidator{}, indexSecrets, indexSecretNames).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+				p, err := withoutResourceVersion(obj, p)
+				if err != nil {
+					return err
+				}
+				return c.Patch(ctx, obj, p, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, p client.Patch, opts ...client.SubResourcePatchOption) error {
+				p, err := withoutResourceVersion(obj, p)
+				if err != nil {
+					return err
+				}
+				return c.SubResource(subResource).Patch(ctx, obj, p, opts...)
+			},
+		}).
+		Build()
+}
+
+// fakeAzure stands in for the Azure APIs used by RBAC rules. It reports a single role assignment
+// for every principal, whose role permits actions, and no deny assignments. The effective permissions
+// of the plugin itself are actions too.
+type fakeAzure struct {
+	mu          sync.Mutex
+	actions     []string
+	requests    int
+	credentials int
+
+	// If set, two deny assignments deny these actions.
+	deniedActions []string
+	// If set, there are three role assignments with the role instead of one.
+	extraRoleAssignments bool
+	// If set, lists are in reverse order. ARM doesn't guarantee any order.
+	reverse bool
+	// The Activity Log events of every subscription.
+	activityLogEvents []any
+	// If set, the credential's access tokens are issued to this principal.
+	principalID string
+	// Requests for these subscriptions fail with AuthorizationFailed.
+	forbiddenSubscriptions []string
+
+	// If set, all requests fail with this status code and ARM error code.
+	failStatusCode int
+	failErrorCode  string
+}
+
+func (f *fakeAzure) fail(statusCode int, errorCode string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failStatusCode, f.failErrorCode = statusCode, errorCode
+}
+
+func (f *fakeAzure) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *fakeAzure) setReverse(reverse bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reverse = reverse
+}
+
+func (f *fakeAzure) setActivityLogEvents(events ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.activityLogEvents = events
+}
+
+// activityLogEvent returns an Activity Log event of an operation on a role assignment at scope for
+// a principal, like ARM lists it. The role assignment is in the request body of writes, and in the
+// response body of deletes.
+func activityLogEvent(id, operation, status, scope, principalID string, at time.Time) any {
+	bodyKey := "requestbody"
+	if strings.HasSuffix(operation, "/delete") {
+		bodyKey = "responseBody"
+	}
+	roleAssignment, err := json.Marshal(map[string]any{
+		"Id":         id,
+		"Properties": map[string]any{"PrincipalId": principalID, "Scope": scope},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return map[string]any{
+		"eventDataId":    id,
+		"eventTimestamp": at.UTC().Format(time.RFC3339),
+		"operationName":  map[string]any{"value": operation},
+		"status":         map[string]any{"value": status},
+		"resourceId":     scope + "/providers/Microsoft.Authorization/roleAssignments/" + id,
+		"properties":     map[string]any{bodyKey: string(roleAssignment)},
+	}
+}
+
+func (f *fakeAzure) setActions(actions ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append([]string{}, actions...)
+}
+
+// Do implements policy.Transporter.
+func (f *fakeAzure) Do(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	statusCode := http.StatusOK
+	var body any
+	switch path := req.URL.Path; {
+	case f.failStatusCode != 0:
+		statusCode = f.failStatusCode
+		body = map[string]any{"error": map[string]any{"code": f.failErrorCode, "message": "fake error"}}
+	case slices.ContainsFunc(f.forbiddenSubscriptions, func(sub string) bool { return strings.HasPrefix(path, "/subscriptions/"+sub+"/") }):
+		statusCode = http.StatusForbidden
+		body = map[string]any{"error": map[string]any{"code": "AuthorizationFailed", "message": "fake error"}}
+	case strings.HasSuffix(path, "/providers/Microsoft.Authorization/permissions"):
+		body = map[string]any{"value": []any{map[string]any{"actions": f.actions}}}
+	case strings.Contains(path, "/eventtypes/management/values"):
+		body = map[string]any{"value": f.order(slices.Clone(f.activityLogEvents))}
+	case strings.Contains(path, "/denyAssignments"):
+		denyAssignments := []any{}
+		if len(f.deniedActions) > 0 {
+			for _, name := range []string{"da-1", "da-2"} {
+				denyAssignments = append(denyAssignments, map[string]any{
+					"id": "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/denyAssignments/" + name,
+					"properties": map[string]any{
+						"permissions": []any{map[string]any{
+							"actions":        f.deniedActions,
+							"notActions":     []string{},
+							"dataActions":    []string{},
+							"notDataActions": []string{},
+						}},
+					},
+				})
+			}
+		}
+		body = map[string]any{"value": f.order(denyAssignments)}
+	case strings.Contains(path, "/roleAssignments"):
+		names := []string{"ra"}
+		if f.extraRoleAssignments {
+			names = []string{"ra-1", "ra-2", "ra-3"}
+		}
+		roleAssignments := []any{}
+		for _, name := range names {
+			roleAssignments = append(roleAssignments, map[string]any{
+				"id": "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleAssignments/" + name,
+				"properties": map[string]any{
+					"roleDefinitionId": "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleDefinitions/rd",
+				},
+			})
+		}
+		body = map[string]any{"value": f.order(roleAssignments)}
+	case strings.Contains(path, "/roleDefinitions"):
+		body = map[string]any{
+			"id": "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleDefinitions/rd",
+			"properties": map[string]any{
+				"permissions": []any{map[string]any{
+					"actions":        f.actions,
+					"notActions":     []string{},
+					"dataActions":    []string{},
+					"notDataActions": []string{},
+				}},
+			},
+		}
+	default:
+		return nil, fmt.Errorf("unexpected request: %s %s", req.Method, req.URL)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+// order returns the items of a list in the order the fake lists them in.
+func (f *fakeAzure) order(items []any) []any {
+	if f.reverse {
+		slices.Reverse(items)
+	}
+	return items
+}
+
+// options returns client factory options whose clients send their requests to the fake. It
+// counts the credentials it creates.
+func (f *fakeAzure) options() azure_utils.ClientFactoryOptions {
+	return azure_utils.ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.credentials++
+			if f.principalID != "" {
+				return principalCredential(f.principalID), nil
+			}
+			return &azfake.TokenCredential{}, nil
+		},
+		Transport: f,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}
+}
+
+// slowTransport sends requests with another transport, advancing a fake clock by a minute for each
+// role assignment list request, so that RBAC rules with one scope each take a minute to evaluate.
+type slowTransport struct {
+	policy.Transporter
+	clk *clocktesting.FakePassiveClock
+}
+
+func (t slowTransport) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/roleAssignments") {
+		t.clk.SetTime(t.clk.Now().Add(time.Minute))
+	}
+	return t.Transporter.Do(req)
+}
+
+// principalCredential is a credential whose access tokens are unsigned JWTs issued to a principal.
+type principalCredential string
+
+func (c principalCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	claims, err := json.Marshal(map[string]string{"oid": string(c)})
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	return azcore.AccessToken{
+		Token:     "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".",
+		ExpiresOn: time.Now().Add(time.Hour),
+	}, nil
+}
+
+func (f *fakeAzure) credentialCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.credentials
+}
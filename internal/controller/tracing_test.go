@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/tracing"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+var _ = Describe("Rule tracing", func() {
+	var recorder *tracetest.SpanRecorder
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		DeferCleanup(func() { tracing.SetTracerProvider(nil) })
+	})
+
+	It("Should evaluate the rule in a span with its name and validation type", func() {
+		var evalSpan trace.SpanContext
+		_, err := tracedEval(context.Background(), "ReconcileNATGatewayRule", constants.ValidationTypeNATGateway, "rule-1", func(ctx context.Context) (*types.ValidationRuleResult, error) {
+			evalSpan = trace.SpanContextFromContext(ctx)
+			return &types.ValidationRuleResult{}, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Ended()).To(HaveLen(1))
+		span := recorder.Ended()[0]
+		Expect(span.Name()).To(Equal("ReconcileNATGatewayRule"))
+		Expect(span.SpanContext().SpanID()).To(Equal(evalSpan.SpanID()))
+		Expect(span.Attributes()).To(ConsistOf(attrRuleName.String("rule-1"), attrValidationType.String(constants.ValidationTypeNATGateway)))
+		Expect(span.Status().Code).To(Equal(codes.Unset))
+	})
+
+	It("Should record the error the rule's evaluation returns", func() {
+		_, err := tracedEval(context.Background(), "ReconcileNATGatewayRule", constants.ValidationTypeNATGateway, "rule-1", func(context.Context) (*types.ValidationRuleResult, error) {
+			return nil, errors.New("subnets unreadable")
+		})
+		Expect(err).To(MatchError("subnets unreadable"))
+
+		Expect(recorder.Ended()).To(HaveLen(1))
+		span := recorder.Ended()[0]
+		Expect(span.Status()).To(Equal(sdktrace.Status{Code: codes.Error, Description: "subnets unreadable"}))
+		Expect(span.Events()).To(HaveLen(1))
+		Expect(span.Events()[0].Name).To(Equal("exception"))
+	})
+})
@@ -0,0 +1,154 @@
+// Package lint checks AzureValidators in YAML files, without a cluster or Azure access, with the
+// validation that the AzureValidator webhook applies.
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+	yamlv3 "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// Finding is a problem with an AzureValidator in a file.
+type Finding struct {
+	File   string
+	Line   int
+	Column int
+	// The path of the field the finding is about (e.g. spec.rbacRules[0].principalId), if any.
+	Field   string
+	Message string
+}
+
+// String returns the finding as file:line:column: field: message.
+func (f Finding) String() string {
+	if f.Field == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", f.File, f.Line, f.Column, f.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", f.File, f.Line, f.Column, f.Field, f.Message)
+}
+
+// Main runs the lint subcommand with the paths of the files to check as args. It prints the
+// findings to stdout, and returns the exit code: 0 if there are none, 1 if there are, and 2 if
+// the files couldn't be checked.
+func Main(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: lint FILE...")
+		return 2
+	}
+	code := 0
+	for _, name := range args {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		findings, err := File(name, data)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			return 2
+		}
+		for _, f := range findings {
+			fmt.Fprintln(stdout, f)
+		}
+		if len(findings) > 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
+// File checks the AzureValidators in the YAML documents of a file. Documents of other kinds are
+// ignored, but a file without any AzureValidators is a finding. Returns an error if the file isn't
+// YAML.
+func File(name string, data []byte) ([]Finding, error) {
+	var findings []Finding
+	validators := 0
+	dec := yamlv3.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yamlv3.Node
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+		kind := lookup(root, "kind")
+		apiVersion := lookup(root, "apiVersion")
+		if kind == nil || kind.Value != "AzureValidator" || apiVersion == nil || apiVersion.Value != v1alpha1.GroupVersion.String() {
+			continue
+		}
+		validators++
+		findings = append(findings, document(name, root)...)
+	}
+	if validators == 0 {
+		findings = append(findings, Finding{File: name, Line: 1, Column: 1, Message: fmt.Sprintf("no %s AzureValidators", v1alpha1.GroupVersion)})
+	}
+	return findings, nil
+}
+
+// document checks an AzureValidator document, whose root node is root.
+func document(name string, root *yamlv3.Node) []Finding {
+	out, err := yamlv3.Marshal(root)
+	if err != nil {
+		return []Finding{{File: name, Line: root.Line, Column: root.Column, Message: err.Error()}}
+	}
+	validator := &v1alpha1.AzureValidator{}
+	if err := yaml.UnmarshalStrict(out, validator); err != nil {
+		return []Finding{{File: name, Line: root.Line, Column: root.Column, Message: err.Error()}}
+	}
+	var findings []Finding
+	for _, e := range validator.Spec.Validate(field.NewPath("spec")) {
+		n := locate(root, e.Field)
+		findings = append(findings, Finding{File: name, Line: n.Line, Column: n.Column, Field: e.Field, Message: e.ErrorBody()})
+	}
+	return findings
+}
+
+// locate returns the node of a field path (e.g. spec.rbacRules[0].scopes[1]) in a document, or
+// the node of the deepest field on the path that's in the document, for fields that are missing.
+func locate(root *yamlv3.Node, path string) *yamlv3.Node {
+	n := root
+	for _, part := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if child := lookup(n, key); child != nil {
+			n = child
+		} else {
+			return n
+		}
+		for rest != "" {
+			index, after, _ := strings.Cut(rest, "]")
+			rest = strings.TrimPrefix(after, "[")
+			i, err := strconv.Atoi(index)
+			if err != nil || n.Kind != yamlv3.SequenceNode || i >= len(n.Content) {
+				return n
+			}
+			n = n.Content[i]
+		}
+	}
+	return n
+}
+
+// lookup returns the value of a key of a mapping node, or nil if it doesn't have the key.
+func lookup(n *yamlv3.Node, key string) *yamlv3.Node {
+	if n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the .golden files in testdata")
+
+// Test_File checks the findings for each YAML file in testdata against the .golden file of the
+// same name. Run with -update to rewrite them.
+func Test_File(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			findings, err := File(path, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			for _, f := range findings {
+				got.WriteString(f.String() + "\n")
+			}
+			golden := strings.TrimSuffix(path, ".yaml") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got.String()), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != string(want) {
+				t.Errorf("got findings:\n%s\nwant:\n%s", got.String(), want)
+			}
+		})
+	}
+}
+
+func Test_Main(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{name: "No findings", args: []string{"testdata/valid.yaml"}, wantCode: 0},
+		{name: "Findings in one of the files", args: []string{"testdata/valid.yaml", "testdata/duplicate-names.yaml"}, wantCode: 1},
+		{name: "No files", wantCode: 2},
+		{name: "Missing file", args: []string{"testdata/missing.yaml"}, wantCode: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := Main(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d (stdout: %q, stderr: %q)", code, tt.wantCode, stdout.String(), stderr.String())
+			}
+		})
+	}
+}
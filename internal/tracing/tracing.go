@@ -0,0 +1,85 @@
+// Package tracing implements optional OpenTelemetry tracing for the plugin. Tracing is disabled
+// unless Setup is called, in which case Start returns a no-op span after a single nil check.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// ServiceName is the service name reported for all spans.
+	ServiceName = "validator-plugin-azure"
+
+	instrumentationName = "github.com/spectrocloud-labs/validator-plugin-azure"
+)
+
+// tracer is nil unless tracing has been enabled.
+var tracer trace.Tracer
+
+// noopSpan is returned by Start when tracing is disabled.
+var noopSpan = noop.Span{}
+
+// Setup enables tracing, exporting spans via OTLP over HTTP to endpoint (host:port). It returns a
+// function that flushes any buffered spans and shuts down the exporter.
+func Setup(ctx context.Context, endpoint string, insecure bool) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// SetTracerProvider enables tracing using spans created by a TracerProvider. Passing nil disables
+// tracing. Used by Setup, and by tests to record spans in memory.
+func SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		tracer = nil
+		return
+	}
+	tracer = tp.Tracer(instrumentationName)
+}
+
+// Enabled returns whether tracing is enabled.
+func Enabled() bool {
+	return tracer != nil
+}
+
+// Start starts a span that is a child of the span in ctx, if any. When tracing is disabled, ctx is
+// returned as is along with a no-op span. Callers computing attributes that are expensive to build
+// should guard them with span.IsRecording().
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	if tracer == nil {
+		return ctx, noopSpan
+	}
+	return tracer.Start(ctx, name)
+}
@@ -0,0 +1,77 @@
+package azure_errors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Category is a category of errors returned by the Azure SDK. It determines whether, and how soon,
+// the request that caused the error is worth retrying.
+type Category string
+
+const (
+	// CategoryNotFound means a resource or scope that was queried doesn't exist (yet).
+	CategoryNotFound Category = "NotFound"
+	// CategoryForbidden means the plugin couldn't authenticate, or isn't authorized to make the
+	// request. Retrying is only worthwhile after someone has fixed the plugin's permissions.
+	CategoryForbidden Category = "Forbidden"
+	// CategoryThrottled means ARM throttled the request.
+	CategoryThrottled Category = "Throttled"
+	// CategoryInvalidRequest means the request was invalid (e.g. a malformed scope). Retrying won't
+	// help until the spec is changed.
+	CategoryInvalidRequest Category = "InvalidRequest"
+	// CategoryTransient means the request failed for a reason that is likely to go away on its own
+	// (e.g. a 503 or a timeout).
+	CategoryTransient Category = "Transient"
+	// CategoryUnknown means the error didn't match any other category.
+	CategoryUnknown Category = "Unknown"
+)
+
+// Classify determines the category of an error returned by the Azure SDK. Errors returned by ARM
+// are classified by their HTTP status code.
+//   - err: An error returned by the Azure SDK, possibly wrapped.
+func Classify(err error) Category {
+	if err == nil {
+		return ""
+	}
+
+	var rerr *azcore.ResponseError
+	if errors.As(err, &rerr) {
+		switch code := rerr.StatusCode; {
+		case code == http.StatusNotFound:
+			return CategoryNotFound
+		case code == http.StatusUnauthorized || code == http.StatusForbidden:
+			return CategoryForbidden
+		case code == http.StatusTooManyRequests:
+			return CategoryThrottled
+		case code == http.StatusRequestTimeout || code == http.StatusConflict || code >= http.StatusInternalServerError:
+			return CategoryTransient
+		case code >= http.StatusBadRequest:
+			return CategoryInvalidRequest
+		}
+		return CategoryUnknown
+	}
+
+	// Requests skipped by the circuit breaker would most likely have failed like the requests that
+	// opened it.
+	if _, ok := CircuitOpen(err); ok {
+		return CategoryTransient
+	}
+
+	var aerr *azidentity.AuthenticationFailedError
+	if errors.As(err, &aerr) || defaultAzureCredential(err) {
+		return CategoryForbidden
+	}
+
+	var nerr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &nerr) {
+		return CategoryTransient
+	}
+
+	return CategoryUnknown
+}
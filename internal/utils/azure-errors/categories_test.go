@@ -0,0 +1,106 @@
+package azure_errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// armError returns the error the Azure SDK returns for an ARM error response.
+func armError(statusCode int, code, message string) error {
+	return runtime.NewResponseError(&http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"error": {"code": %q, "message": %q}}`, code, message))),
+		Request:    &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "management.azure.com", Path: "/"}},
+	})
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Category
+	}{
+		{
+			name: "Classifies a missing subscription as not found.",
+			err:  armError(http.StatusNotFound, "SubscriptionNotFound", "The subscription '00000000-0000-0000-0000-000000000000' could not be found."),
+			want: CategoryNotFound,
+		},
+		{
+			name: "Classifies a missing role assignment as forbidden.",
+			err:  armError(http.StatusForbidden, "AuthorizationFailed", "The client does not have authorization to perform action 'Microsoft.Authorization/roleAssignments/read'."),
+			want: CategoryForbidden,
+		},
+		{
+			name: "Classifies an expired token as forbidden.",
+			err:  armError(http.StatusUnauthorized, "ExpiredAuthenticationToken", "The access token expiry UTC time is earlier than current UTC time."),
+			want: CategoryForbidden,
+		},
+		{
+			name: "Classifies a DefaultAzureCredential failure as forbidden.",
+			err:  errors.New("DefaultAzureCredential: failed to acquire a token."),
+			want: CategoryForbidden,
+		},
+		{
+			name: "Classifies too many requests as throttled.",
+			err:  armError(http.StatusTooManyRequests, "TooManyRequests", "The request is being throttled."),
+			want: CategoryThrottled,
+		},
+		{
+			name: "Classifies an invalid subscription ID as an invalid request.",
+			err:  armError(http.StatusBadRequest, "InvalidSubscriptionId", "The provided subscription identifier 'abc' is malformed or invalid."),
+			want: CategoryInvalidRequest,
+		},
+		{
+			name: "Classifies an invalid filter as an invalid request.",
+			err:  armError(http.StatusBadRequest, "InvalidFilterInQueryString", "Invalid filter in query string."),
+			want: CategoryInvalidRequest,
+		},
+		{
+			name: "Classifies an unavailable service as transient.",
+			err:  armError(http.StatusServiceUnavailable, "ServiceUnavailable", "The service is unavailable."),
+			want: CategoryTransient,
+		},
+		{
+			name: "Classifies an internal server error as transient.",
+			err:  armError(http.StatusInternalServerError, "InternalServerError", "Encountered internal server error."),
+			want: CategoryTransient,
+		},
+		{
+			name: "Classifies a timeout as transient.",
+			err:  fmt.Errorf("context cancelled: %w", context.DeadlineExceeded),
+			want: CategoryTransient,
+		},
+		{
+			name: "Classifies a wrapped ARM error by the ARM error.",
+			err:  fmt.Errorf("failed to get role assignments: %w", AsAugmented(armError(http.StatusForbidden, "AuthorizationFailed", "denied"))),
+			want: CategoryForbidden,
+		},
+		{
+			name: "Classifies a request skipped by the circuit breaker as transient.",
+			err:  fmt.Errorf("failed to get deny assignments: %w", &CircuitOpenError{SubscriptionID: "00000000-0000-0000-0000-000000000000", RetryAt: time.Now()}),
+			want: CategoryTransient,
+		},
+		{
+			name: "Classifies other errors as unknown.",
+			err:  errors.New("role assignment properties nil"),
+			want: CategoryUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 )
@@ -59,3 +60,73 @@ func AsAugmented(err error) error {
 
 	return err
 }
+
+// CorrelationIDHeader is the header ARM uses to return the correlation request ID of a request,
+// which Azure support can use to find the request in Azure's logs.
+const CorrelationIDHeader = "x-ms-correlation-request-id"
+
+// correlatedError is an error returned by the Azure SDK for an API request that ARM assigned a
+// correlation request ID to.
+type correlatedError struct {
+	err           error
+	correlationID string
+}
+
+func (e *correlatedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *correlatedError) Unwrap() error {
+	return e.err
+}
+
+// WithCorrelationID attaches the correlation request ID of the Azure API request that caused err to
+// err. If correlationID is empty, err is returned as is.
+//   - err: An error returned by the Azure SDK.
+//   - correlationID: The correlation request ID ARM returned for the request.
+func WithCorrelationID(err error, correlationID string) error {
+	if err == nil || correlationID == "" {
+		return err
+	}
+	return &correlatedError{err: err, correlationID: correlationID}
+}
+
+// CorrelationID returns the correlation request ID of the Azure API request that caused err, if
+// known. It's known if it was attached to err by WithCorrelationID, or if err wraps an error
+// response from ARM. Otherwise, it returns an empty string.
+//   - err: An error returned by the Azure SDK, possibly wrapped.
+func CorrelationID(err error) string {
+	var cerr *correlatedError
+	if errors.As(err, &cerr) {
+		return cerr.correlationID
+	}
+	var rerr *azcore.ResponseError
+	if errors.As(err, &rerr) && rerr.RawResponse != nil {
+		return rerr.RawResponse.Header.Get(CorrelationIDHeader)
+	}
+	return ""
+}
+
+// CircuitOpenError is returned instead of making an Azure API request for a subscription whose
+// ARM endpoints have failed repeatedly, until the circuit breaker for the subscription allows
+// requests again.
+type CircuitOpenError struct {
+	// SubscriptionID is the ID of the subscription requests were skipped for.
+	SubscriptionID string
+	// RetryAt is when the circuit breaker will next allow a request for the subscription.
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("subscription temporarily skipped due to repeated Azure errors (retry at %s)", e.RetryAt.UTC().Format(time.RFC3339))
+}
+
+// CircuitOpen returns the CircuitOpenError err wraps, if any.
+//   - err: An error returned by an Azure facade, possibly wrapped.
+func CircuitOpen(err error) (*CircuitOpenError, bool) {
+	var cerr *CircuitOpenError
+	if errors.As(err, &cerr) {
+		return cerr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,121 @@
+package azure_errors
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+const (
+	// TransientRequeueAfter is how long to wait before re-evaluating rules that failed with
+	// transient errors.
+	TransientRequeueAfter = 10 * time.Second
+	// ThrottledRequeueAfter is how long to wait before re-evaluating rules that failed because ARM
+	// throttled the plugin, if ARM didn't say when to retry.
+	ThrottledRequeueAfter = 30 * time.Second
+	// maxRetryAfter caps the waits that ARM asks for, so that a bogus Retry-After header can't
+	// postpone re-evaluation indefinitely.
+	maxRetryAfter = 10 * time.Minute
+)
+
+// RequeueHinter is implemented by errors that know how soon re-evaluating the rule that failed with
+// them is worthwhile. The reconciler prefers their hint over the requeue that the category of the
+// error they wrap implies.
+type RequeueHinter interface {
+	// RequeueAfter returns how long to wait before re-evaluating the rule, or zero if it isn't
+	// worth re-evaluating until its spec changes.
+	RequeueAfter() time.Duration
+}
+
+// RequeueError is an error with a requeue hint.
+type RequeueError struct {
+	Err error
+	// After is how long to wait before re-evaluating the rule that failed with Err, or zero if it
+	// isn't worth re-evaluating until its spec changes.
+	After time.Duration
+}
+
+func (e *RequeueError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequeueError) Unwrap() error {
+	return e.Err
+}
+
+// RequeueAfter implements RequeueHinter.
+func (e *RequeueError) RequeueAfter() time.Duration {
+	return e.After
+}
+
+// Terminal returns err with a hint that the rule that failed with it isn't worth re-evaluating
+// until its spec changes, e.g. because its spec can't be evaluated.
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RequeueError{Err: err}
+}
+
+// WithRequeueHint returns err with a requeue hint, if its category implies one regardless of how
+// often the plugin re-validates rules otherwise:
+//   - throttled errors: after the Retry-After that ARM responded with, or ThrottledRequeueAfter.
+//   - transient errors: after TransientRequeueAfter.
+//   - invalid requests: not until the spec changes.
+//
+// Errors that already have a hint, and errors of other categories, are returned as is. So are
+// requests skipped by the circuit breaker, which are worth retrying once it allows them again,
+// which the reconciler determines with its own clock.
+func WithRequeueHint(err error) error {
+	var hinter RequeueHinter
+	if err == nil || errors.As(err, &hinter) {
+		return err
+	}
+	if _, ok := CircuitOpen(err); ok {
+		return err
+	}
+	switch Classify(err) {
+	case CategoryThrottled:
+		after, ok := RetryAfter(err)
+		if !ok {
+			after = ThrottledRequeueAfter
+		}
+		return &RequeueError{Err: err, After: after}
+	case CategoryTransient:
+		return &RequeueError{Err: err, After: TransientRequeueAfter}
+	case CategoryInvalidRequest:
+		return Terminal(err)
+	}
+	return err
+}
+
+// RetryAfter returns how long ARM asked to wait before retrying the request that an error returned
+// by the Azure SDK is the response to, according to the response's Retry-After header, which is a
+// number of seconds or an HTTP date. Returns false if there's no such header, or the wait it asks
+// for isn't positive. Waits are capped at 10 minutes.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) || rerr.RawResponse == nil {
+		return 0, false
+	}
+	header := rerr.RawResponse.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0, false
+		}
+		return min(time.Duration(seconds)*time.Second, maxRetryAfter), true
+	}
+	// Retry-After may also be an HTTP date, though ARM doesn't send one.
+	if at, err := http.ParseTime(header); err == nil {
+		if after := time.Until(at); after > 0 {
+			return min(after, maxRetryAfter), true
+		}
+	}
+	return 0, false
+}
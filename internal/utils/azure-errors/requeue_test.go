@@ -0,0 +1,158 @@
+package azure_errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// throttledError returns the error the Azure SDK returns for a throttled ARM response with the given
+// Retry-After header, if any.
+func throttledError(retryAfter string) error {
+	err := armError(http.StatusTooManyRequests, "TooManyRequests", "The request is being throttled.")
+	var rerr *azcore.ResponseError
+	if retryAfter != "" && errors.As(err, &rerr) {
+		rerr.RawResponse.Header.Set("Retry-After", retryAfter)
+	}
+	return err
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "Parses a number of seconds.",
+			err:    throttledError("17"),
+			want:   17 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "Parses a number of seconds of a wrapped error.",
+			err:    fmt.Errorf("failed to get next page of results: %w", throttledError("17")),
+			want:   17 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "Caps waits at 10 minutes.",
+			err:    throttledError("86400"),
+			want:   maxRetryAfter,
+			wantOK: true,
+		},
+		{
+			name:   "Caps HTTP dates at 10 minutes.",
+			err:    throttledError(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)),
+			want:   maxRetryAfter,
+			wantOK: true,
+		},
+		{
+			name: "Ignores HTTP dates in the past.",
+			err:  throttledError(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)),
+		},
+		{
+			name: "Ignores waits that aren't positive.",
+			err:  throttledError("0"),
+		},
+		{
+			name: "Ignores malformed headers.",
+			err:  throttledError("soon"),
+		},
+		{
+			name: "Ignores responses without a Retry-After header.",
+			err:  throttledError(""),
+		},
+		{
+			name: "Ignores errors that aren't ARM responses.",
+			err:  errors.New("connection reset by peer"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfter(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %s, %t, want %s, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithRequeueHint(t *testing.T) {
+	hinted := &RequeueError{Err: errors.New("hinted"), After: time.Hour}
+	tests := []struct {
+		name     string
+		err      error
+		want     time.Duration
+		wantHint bool
+	}{
+		{
+			name:     "Hints throttled errors with ARM's Retry-After.",
+			err:      throttledError("42"),
+			want:     42 * time.Second,
+			wantHint: true,
+		},
+		{
+			name:     "Hints throttled errors without a Retry-After with the default.",
+			err:      throttledError(""),
+			want:     ThrottledRequeueAfter,
+			wantHint: true,
+		},
+		{
+			name:     "Hints wrapped throttled errors.",
+			err:      fmt.Errorf("failed to get role assignments: %w", throttledError("42")),
+			want:     42 * time.Second,
+			wantHint: true,
+		},
+		{
+			name:     "Hints transient errors with a short requeue.",
+			err:      armError(http.StatusServiceUnavailable, "ServiceUnavailable", "The service is unavailable."),
+			want:     TransientRequeueAfter,
+			wantHint: true,
+		},
+		{
+			name:     "Hints invalid requests as terminal.",
+			err:      fmt.Errorf("failed to get subscription: %w", armError(http.StatusBadRequest, "InvalidSubscriptionId", "The provided subscription identifier 'abc' is malformed or invalid.")),
+			want:     0,
+			wantHint: true,
+		},
+		{
+			name: "Doesn't hint forbidden errors.",
+			err:  armError(http.StatusForbidden, "AuthorizationFailed", "The client does not have authorization to perform action 'Microsoft.Authorization/roleAssignments/read'."),
+		},
+		{
+			name: "Doesn't hint requests skipped by the circuit breaker.",
+			err:  fmt.Errorf("failed to get deny assignments: %w", &CircuitOpenError{RetryAt: time.Now().Add(time.Minute)}),
+		},
+		{
+			name:     "Keeps existing hints.",
+			err:      fmt.Errorf("failed to get role assignments: %w", hinted),
+			want:     time.Hour,
+			wantHint: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WithRequeueHint(tt.err)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, which doesn't wrap %v", err, tt.err)
+			}
+			var hinter RequeueHinter
+			if ok := errors.As(err, &hinter); ok != tt.wantHint {
+				t.Fatalf("got hint %t, want %t", ok, tt.wantHint)
+			}
+			if hinter != nil && hinter.RequeueAfter() != tt.want {
+				t.Errorf("got requeue after %s, want %s", hinter.RequeueAfter(), tt.want)
+			}
+		})
+	}
+
+	if err := WithRequeueHint(nil); err != nil {
+		t.Errorf("got %v for nil, want nil", err)
+	}
+}
@@ -0,0 +1,59 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+)
+
+// AzureManagedClustersClient is a facade over the Azure AKS managed clusters client for a single
+// subscription. Exists to make our code easier to test.
+type AzureManagedClustersClient struct {
+	ctx            context.Context
+	client         *armcontainerservice.ManagedClustersClient
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureManagedClustersClient creates a new AzureManagedClustersClient (our facade client) from a
+// client from the Azure SDK for the subscription with ID subscriptionID.
+func NewAzureManagedClustersClient(ctx context.Context, azClient *armcontainerservice.ManagedClustersClient, subscriptionID string) *AzureManagedClustersClient {
+	return &AzureManagedClustersClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureManagedClustersClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureManagedClustersClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetManagedCluster gets an AKS cluster.
+func (c *AzureManagedClustersClient) GetManagedCluster(resourceGroupName, clusterName string) (_ *armcontainerservice.ManagedCluster, err error) {
+	scope := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", c.subscriptionID, resourceGroupName, clusterName)
+	ctx, span := startScopeSpan(c.ctx, "ManagedClusters.Get", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Get(ctx, resourceGroupName, clusterName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed cluster %s: %w", scope, rec.withCorrelationID(err))
+	}
+	return &resp.ManagedCluster, nil
+}
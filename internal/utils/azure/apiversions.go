@@ -0,0 +1,132 @@
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// apiVersionHeader is the response header in which a service reports the api-version that served a
+// request, if it differs from the requested one or the request had none.
+const apiVersionHeader = "api-version"
+
+// sdkModulePrefix is the module path prefix of the Azure SDK for Go's modules.
+const sdkModulePrefix = "github.com/Azure/azure-sdk-for-go/sdk/"
+
+// apiVersion is the version of an API that served a facade's requests, and the version of the SDK
+// module that made them.
+type apiVersion struct {
+	apiVersion string
+	sdkModule  string
+}
+
+func (v apiVersion) String() string {
+	s := "api-version " + v.apiVersion
+	if v.sdkModule != "" {
+		s += " (" + v.sdkModule + ")"
+	}
+	return s
+}
+
+func (l *correlationIDLog) addAPIVersion(api string, v apiVersion) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.apis == nil {
+		l.apis = map[string]apiVersion{}
+	}
+	l.apis[api] = v
+}
+
+// apiVersions returns the version of each API, sorted by API, e.g.
+// "Microsoft.Authorization/roleAssignments api-version 2022-04-01 (armauthorization v2.2.0)".
+func (l *correlationIDLog) apiVersions() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	versions := make([]string, 0, len(l.apis))
+	for api, v := range l.apis {
+		versions = append(versions, fmt.Sprintf("%s %s", api, v))
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// apiVersionPolicy is an azcore pipeline policy that records the api-version of each response, and
+// the SDK module that made its request, in the request context's correlationIDRecorder's log, if
+// there is one. The api-version is the one in the response's api-version header, or, if it has
+// none, the one that was requested.
+type apiVersionPolicy struct{}
+
+// Do implements policy.Policy.
+func (apiVersionPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if resp == nil {
+		return resp, err
+	}
+	r, ok := req.Raw().Context().Value(correlationIDRecorderKey{}).(*correlationIDRecorder)
+	if !ok || r.log == nil {
+		return resp, err
+	}
+	v := apiVersion{
+		apiVersion: resp.Header.Get(apiVersionHeader),
+		sdkModule:  sdkModuleFromUserAgent(req.Raw().Header.Get("User-Agent")),
+	}
+	if v.apiVersion == "" {
+		v.apiVersion = req.Raw().URL.Query().Get("api-version")
+	}
+	if v.apiVersion != "" {
+		r.log.addAPIVersion(apiName(req.Raw().URL), v)
+	}
+	return resp, err
+}
+
+// apiName names the API of a request URL after its resource provider namespace and resource type
+// (e.g. Microsoft.Network/virtualNetworks/subnets), or, for data plane APIs like Key Vault's, after
+// the first segment of its path (e.g. certificates).
+func apiName(u *url.URL) string {
+	path := strings.Trim(u.Path, "/")
+	lower := strings.ToLower(path)
+	if i := strings.LastIndex(lower, "providers/"); i >= 0 {
+		segments := strings.Split(path[i+len("providers/"):], "/")
+		parts := segments[:1]
+		for j := 1; j < len(segments); j += 2 {
+			parts = append(parts, segments[j])
+		}
+		return strings.Join(parts, "/")
+	}
+	first, _, _ := strings.Cut(path, "/")
+	return first
+}
+
+// sdkModuleFromUserAgent returns the SDK module and version (e.g. armauthorization v2.2.0) that the
+// SDK's telemetry policy added to a User-Agent header, or an empty string if it has none.
+func sdkModuleFromUserAgent(userAgent string) string {
+	for _, token := range strings.Fields(userAgent) {
+		if module, ok := strings.CutPrefix(token, "azsdk-go-"); ok {
+			return strings.Replace(module, "/", " ", 1)
+		}
+	}
+	return ""
+}
+
+// SDKModuleVersions returns the versions of the Azure SDK for Go modules that the plugin was built
+// with, e.g. "resourcemanager/authorization/armauthorization/v2 v2.2.0", sorted by module. Returns
+// nil if the plugin wasn't built with module support.
+func SDKModuleVersions() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	var versions []string
+	for _, dep := range info.Deps {
+		if module, ok := strings.CutPrefix(dep.Path, sdkModulePrefix); ok {
+			versions = append(versions, module+" "+dep.Version)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
@@ -0,0 +1,112 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+)
+
+func Test_APIVersions(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		wantVersionOf func(requested string) string
+	}{
+		{
+			name:          "Records the api-version that the response reports.",
+			header:        "2099-01-01-preview",
+			wantVersionOf: func(string) string { return "2099-01-01-preview" },
+		},
+		{
+			name:          "Records the requested api-version if the response doesn't report one.",
+			wantVersionOf: func(requested string) string { return requested },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested, userAgent string
+			transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+				requested = req.URL.Query().Get("api-version")
+				userAgent = req.Header.Get("User-Agent")
+				header := http.Header{}
+				header.Set("Content-Type", "application/json")
+				if tt.header != "" {
+					header.Set(apiVersionHeader, tt.header)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       io.NopCloser(strings.NewReader(`{"id": "rd"}`)),
+					Request:    req,
+				}, nil
+			})
+			client, err := armauthorization.NewRoleDefinitionsClient(&azfake.TokenCredential{}, &armpolicy.ClientOptions{
+				ClientOptions: policy.ClientOptions{
+					Transport:        transport,
+					Retry:            policy.RetryOptions{MaxRetries: -1},
+					PerRetryPolicies: []policy.Policy{correlationIDPolicy{}, apiVersionPolicy{}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			facade := NewAzureRoleDefinitionsClient(context.Background(), client)
+			if _, err := facade.GetByID("/subscriptions/s/providers/Microsoft.Authorization/roleDefinitions/rd"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			module := sdkModuleFromUserAgent(userAgent)
+			if !strings.HasPrefix(module, "armauthorization v2.") {
+				t.Fatalf("got SDK module %q from User-Agent %q, want armauthorization v2.x", module, userAgent)
+			}
+			want := []string{"Microsoft.Authorization/roleDefinitions api-version " + tt.wantVersionOf(requested) + " (" + module + ")"}
+			if got := facade.APIVersions(); !reflect.DeepEqual(got, want) {
+				t.Errorf("got API versions %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_apiName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://management.azure.com/subscriptions/s/providers/Microsoft.Authorization/roleAssignments",
+			want: "Microsoft.Authorization/roleAssignments",
+		},
+		{
+			url:  "https://management.azure.com/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet",
+			want: "Microsoft.Network/virtualNetworks/subnets",
+		},
+		{
+			url:  "https://management.azure.com/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/providers/Microsoft.Authorization/permissions",
+			want: "Microsoft.Authorization/permissions",
+		},
+		{
+			url:  "https://vault.vault.azure.net/certificates/cert/",
+			want: "certificates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := apiName(u); got != tt.want {
+				t.Errorf("apiName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Types of permissions that an application can request of a resource application, as Microsoft
+// Graph names them in an app registration's requiredResourceAccess.
+const (
+	// GraphResourceAccessRole is an application permission, i.e. an app role of the resource.
+	GraphResourceAccessRole = "Role"
+	// GraphResourceAccessScope is a delegated permission, i.e. an OAuth2 permission scope of the
+	// resource.
+	GraphResourceAccessScope = "Scope"
+)
+
+// GraphConsentTypeAllPrincipals is the consent type of delegated permission grants that an
+// administrator consented to on behalf of all users.
+const GraphConsentTypeAllPrincipals = "AllPrincipals"
+
+// GraphApplication is a Microsoft Graph application, i.e. an app registration, with the
+// properties that rules check.
+type GraphApplication struct {
+	ID          string `json:"id"`
+	AppID       string `json:"appId"`
+	DisplayName string `json:"displayName"`
+	// The permissions the application requests, grouped by resource application.
+	RequiredResourceAccess []GraphRequiredResourceAccess `json:"requiredResourceAccess"`
+}
+
+// GraphRequiredResourceAccess is the set of permissions that an application requests of a
+// resource application.
+type GraphRequiredResourceAccess struct {
+	ResourceAppID  string                `json:"resourceAppId"`
+	ResourceAccess []GraphResourceAccess `json:"resourceAccess"`
+}
+
+// GraphResourceAccess is a permission that an application requests, by the ID of the resource's
+// app role or permission scope.
+type GraphResourceAccess struct {
+	ID string `json:"id"`
+	// Role or Scope.
+	Type string `json:"type"`
+}
+
+// GraphServicePrincipal is a Microsoft Graph service principal, i.e. an application's instance in
+// a tenant, with the permissions it defines as a resource.
+type GraphServicePrincipal struct {
+	ID          string `json:"id"`
+	AppID       string `json:"appId"`
+	DisplayName string `json:"displayName"`
+	// The application permissions the service principal defines.
+	AppRoles []GraphPermission `json:"appRoles"`
+	// The delegated permissions the service principal defines.
+	OAuth2PermissionScopes []GraphPermission `json:"oauth2PermissionScopes"`
+}
+
+// GraphPermission is an app role or OAuth2 permission scope that a service principal defines.
+type GraphPermission struct {
+	ID string `json:"id"`
+	// The name of the permission, e.g. User.Read.All.
+	Value string `json:"value"`
+}
+
+// GraphAppRoleAssignment is the assignment of an app role of a resource service principal, i.e.
+// an application permission with admin consent.
+type GraphAppRoleAssignment struct {
+	AppRoleID string `json:"appRoleId"`
+	// The object ID of the resource service principal.
+	ResourceID string `json:"resourceId"`
+}
+
+// GraphOAuth2PermissionGrant is the grant of delegated permissions of a resource service principal
+// to a client service principal.
+type GraphOAuth2PermissionGrant struct {
+	// AllPrincipals for admin consent, or Principal for a single user's consent.
+	ConsentType string `json:"consentType"`
+	// The object ID of the resource service principal.
+	ResourceID string `json:"resourceId"`
+	// The names of the granted permissions, separated by spaces.
+	Scope string `json:"scope"`
+}
+
+// GraphFederatedIdentityCredential is a federated identity credential of an app registration,
+// which lets tokens issued by an external identity provider, such as a Kubernetes service account
+// token, be exchanged for access tokens of the application.
+type GraphFederatedIdentityCredential struct {
+	Name    string `json:"name"`
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+	// The audiences that the external tokens may have, usually api://AzureADTokenExchange.
+	Audiences []string `json:"audiences"`
+}
+
+// AzureApplicationsClient is a facade over Microsoft Graph's applications and service principals
+// APIs. Exists to make our code easier to test. Applications and service principals are
+// identified by their application (client) IDs, which are the same in every tenant.
+type AzureApplicationsClient struct {
+	ctx            context.Context
+	client         *GraphClient
+	correlationIDs correlationIDLog
+}
+
+// NewAzureApplicationsClient creates a new AzureApplicationsClient (our facade client) from a
+// Graph client.
+func NewAzureApplicationsClient(ctx context.Context, client *GraphClient) *AzureApplicationsClient {
+	return &AzureApplicationsClient{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+// CorrelationIDs returns the request IDs of all Graph responses the client has received.
+func (c *AzureApplicationsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// GetApplication gets the app registration of an application by its application ID, with the
+// permissions it requests.
+func (c *AzureApplicationsClient) GetApplication(appID string) (_ *GraphApplication, err error) {
+	path := fmt.Sprintf("/applications(appId='%s')", url.PathEscape(appID))
+	ctx, span := startScopeSpan(c.ctx, "Applications.Get", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	var app GraphApplication
+	query := url.Values{"$select": {"id,appId,displayName,requiredResourceAccess"}}
+	if err = c.client.get(ctx, path, query, &app); err != nil {
+		return nil, fmt.Errorf("failed to get application %s: %w", appID, rec.withCorrelationID(err))
+	}
+	return &app, nil
+}
+
+// GetServicePrincipal gets the service principal of an application by its application ID, with
+// the permissions it defines.
+func (c *AzureApplicationsClient) GetServicePrincipal(appID string) (_ *GraphServicePrincipal, err error) {
+	path := fmt.Sprintf("/servicePrincipals(appId='%s')", url.PathEscape(appID))
+	ctx, span := startScopeSpan(c.ctx, "ServicePrincipals.Get", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	var sp GraphServicePrincipal
+	query := url.Values{"$select": {"id,appId,displayName,appRoles,oauth2PermissionScopes"}}
+	if err = c.client.get(ctx, path, query, &sp); err != nil {
+		return nil, fmt.Errorf("failed to get service principal of application %s: %w", appID, rec.withCorrelationID(err))
+	}
+	return &sp, nil
+}
+
+// ListAppRoleAssignments gets the app roles assigned to a service principal by its object ID,
+// i.e. its application permissions with admin consent.
+func (c *AzureApplicationsClient) ListAppRoleAssignments(servicePrincipalID string) (_ []*GraphAppRoleAssignment, err error) {
+	path := fmt.Sprintf("/servicePrincipals/%s/appRoleAssignments", url.PathEscape(servicePrincipalID))
+	ctx, span := startScopeSpan(c.ctx, "ServicePrincipals.ListAppRoleAssignments", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	assignments, err := listGraph[GraphAppRoleAssignment](ctx, c.client, path, url.Values{"$select": {"appRoleId,resourceId"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app role assignments of service principal %s: %w", servicePrincipalID, rec.withCorrelationID(err))
+	}
+	return assignments, nil
+}
+
+// ListOAuth2PermissionGrants gets the delegated permission grants of a client service principal by
+// its object ID.
+func (c *AzureApplicationsClient) ListOAuth2PermissionGrants(servicePrincipalID string) (_ []*GraphOAuth2PermissionGrant, err error) {
+	path := fmt.Sprintf("/servicePrincipals/%s/oauth2PermissionGrants", url.PathEscape(servicePrincipalID))
+	ctx, span := startScopeSpan(c.ctx, "ServicePrincipals.ListOAuth2PermissionGrants", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	grants, err := listGraph[GraphOAuth2PermissionGrant](ctx, c.client, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OAuth2 permission grants of service principal %s: %w", servicePrincipalID, rec.withCorrelationID(err))
+	}
+	return grants, nil
+}
+
+// ListFederatedIdentityCredentials gets the federated identity credentials of the app
+// registration of an application by its application ID.
+func (c *AzureApplicationsClient) ListFederatedIdentityCredentials(appID string) (_ []*GraphFederatedIdentityCredential, err error) {
+	path := fmt.Sprintf("/applications(appId='%s')/federatedIdentityCredentials", url.PathEscape(appID))
+	ctx, span := startScopeSpan(c.ctx, "Applications.ListFederatedIdentityCredentials", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	credentials, err := listGraph[GraphFederatedIdentityCredential](ctx, c.client, path, url.Values{"$select": {"name,issuer,subject,audiences"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated identity credentials of application %s: %w", appID, rec.withCorrelationID(err))
+	}
+	return credentials, nil
+}
@@ -0,0 +1,132 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// Fixture responses of Microsoft Graph, trimmed to the properties the applications client selects.
+const (
+	testApplicationFixture = `{
+		"id": "app-object-1",
+		"appId": "app-1",
+		"displayName": "my-app",
+		"requiredResourceAccess": [
+			{
+				"resourceAppId": "00000003-0000-0000-c000-000000000000",
+				"resourceAccess": [
+					{"id": "role-1", "type": "Role"},
+					{"id": "scope-1", "type": "Scope"}
+				]
+			}
+		]
+	}`
+	testServicePrincipalFixture = `{
+		"id": "sp-graph",
+		"appId": "00000003-0000-0000-c000-000000000000",
+		"displayName": "Microsoft Graph",
+		"appRoles": [{"id": "role-1", "value": "User.Read.All"}],
+		"oauth2PermissionScopes": [{"id": "scope-1", "value": "User.Read"}]
+	}`
+	testFederatedCredentialsFixture = `{
+		"value": [{
+			"name": "validator-plugin-azure",
+			"issuer": "https://oidc.prod-aks.azure.com/tenant-1/issuer-1/",
+			"subject": "system:serviceaccount:validator:validator-plugin-azure-controller-manager",
+			"audiences": ["api://AzureADTokenExchange"]
+		}]
+	}`
+	testGrantsFixture = `{
+		"value": [{"clientId": "sp-1", "consentType": "AllPrincipals", "resourceId": "sp-graph", "scope": " User.Read openid "}]
+	}`
+)
+
+func Test_AzureApplicationsClient(t *testing.T) {
+	fixtures := map[string]string{
+		"/v1.0/applications(appId='app-1')":                                     testApplicationFixture,
+		"/v1.0/servicePrincipals(appId='00000003-0000-0000-c000-000000000000')": testServicePrincipalFixture,
+		"/v1.0/servicePrincipals/sp-1/oauth2PermissionGrants":                   testGrantsFixture,
+		"/v1.0/servicePrincipals/sp-1/appRoleAssignments":                       `{"value": [{"appRoleId": "role-1", "resourceId": "sp-graph"}]}`,
+		"/v1.0/applications(appId='app-1')/federatedIdentityCredentials":        testFederatedCredentialsFixture,
+	}
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		body, ok := fixtures[req.URL.Path]
+		if !ok {
+			return graphResponse(req, http.StatusNotFound, `{"error": {"code": "Request_ResourceNotFound"}}`), nil
+		}
+		return graphResponse(req, http.StatusOK, body), nil
+	})
+	c := NewAzureApplicationsClient(context.Background(), newTestGraphClient(t, transport))
+
+	app, err := c.GetApplication("app-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantApp := &GraphApplication{
+		ID:          "app-object-1",
+		AppID:       "app-1",
+		DisplayName: "my-app",
+		RequiredResourceAccess: []GraphRequiredResourceAccess{{
+			ResourceAppID: "00000003-0000-0000-c000-000000000000",
+			ResourceAccess: []GraphResourceAccess{
+				{ID: "role-1", Type: GraphResourceAccessRole},
+				{ID: "scope-1", Type: GraphResourceAccessScope},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(app, wantApp) {
+		t.Errorf("got application %+v, want %+v", app, wantApp)
+	}
+
+	sp, err := c.GetServicePrincipal("00000003-0000-0000-c000-000000000000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []GraphPermission{{ID: "role-1", Value: "User.Read.All"}}; !reflect.DeepEqual(sp.AppRoles, want) {
+		t.Errorf("got app roles %v, want %v", sp.AppRoles, want)
+	}
+	if want := []GraphPermission{{ID: "scope-1", Value: "User.Read"}}; !reflect.DeepEqual(sp.OAuth2PermissionScopes, want) {
+		t.Errorf("got permission scopes %v, want %v", sp.OAuth2PermissionScopes, want)
+	}
+
+	assignments, err := c.ListAppRoleAssignments("sp-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []*GraphAppRoleAssignment{{AppRoleID: "role-1", ResourceID: "sp-graph"}}; !reflect.DeepEqual(assignments, want) {
+		t.Errorf("got app role assignments %v, want %v", assignments, want)
+	}
+
+	grants, err := c.ListOAuth2PermissionGrants("sp-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []*GraphOAuth2PermissionGrant{{ConsentType: GraphConsentTypeAllPrincipals, ResourceID: "sp-graph", Scope: " User.Read openid "}}; !reflect.DeepEqual(grants, want) {
+		t.Errorf("got grants %v, want %v", grants, want)
+	}
+
+	credentials, err := c.ListFederatedIdentityCredentials("app-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantCredentials := []*GraphFederatedIdentityCredential{{
+		Name:      "validator-plugin-azure",
+		Issuer:    "https://oidc.prod-aks.azure.com/tenant-1/issuer-1/",
+		Subject:   "system:serviceaccount:validator:validator-plugin-azure-controller-manager",
+		Audiences: []string{"api://AzureADTokenExchange"},
+	}}
+	if !reflect.DeepEqual(credentials, wantCredentials) {
+		t.Errorf("got federated identity credentials %+v, want %+v", credentials, wantCredentials)
+	}
+
+	_, err = c.GetServicePrincipal("app-1")
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 Azure error, got %v", err)
+	}
+}
@@ -5,78 +5,21 @@ package azure
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"os"
+	"net/url"
 	"strings"
 	"time"
 
-	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 )
 
 const TestClientTimeout = 10 * time.Second
 
-type AzureAPI struct {
-	DenyAssignments *armauthorization.DenyAssignmentsClient
-	RoleAssignments *armauthorization.RoleAssignmentsClient
-	RoleDefinitions *armauthorization.RoleDefinitionsClient
-}
-
-// NewAzureAPI creates an AzureAPI object that aggregates Azure service clients.
-func NewAzureAPI() (*AzureAPI, error) {
-	// Get credentials from the three env vars. For more info on default auth, see:
-	// https://learn.microsoft.com/en-us/azure/developer/go/azure-sdk-authentication
-	var cred *azidentity.DefaultAzureCredential
-	var err error
-	if cred, err = azidentity.NewDefaultAzureCredential(nil); err != nil {
-		return nil, fmt.Errorf("failed to prepare default Azure credential: %w", err)
-	}
-
-	// Minimize retries/timeouts for tests
-	opts := &armpolicy.ClientOptions{
-		ClientOptions: policy.ClientOptions{
-			Retry: policy.RetryOptions{},
-		},
-	}
-	if os.Getenv("IS_TEST") == "true" {
-		httpClient := http.DefaultClient
-		httpClient.Timeout = TestClientTimeout
-
-		opts.ClientOptions.Retry.MaxRetries = -1
-		opts.ClientOptions.Retry.TryTimeout = TestClientTimeout
-		opts.ClientOptions.Transport = policy.Transporter(httpClient)
-	}
-
-	// The subscription ID parameter for deny assignment and role assignment clients isn't relevant
-	// because the plugin only uses methods where scope is specified for each query. Therefore, an
-	// empty string is used for the param.
-	daClient, err := armauthorization.NewDenyAssignmentsClient("", cred, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure deny assignments client: %w", err)
-	}
-	raClient, err := armauthorization.NewRoleAssignmentsClient("", cred, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure role assignments client: %w", err)
-	}
-	rdClient, err := armauthorization.NewRoleDefinitionsClient(cred, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure role assignments client: %w", err)
-	}
-
-	return &AzureAPI{
-		DenyAssignments: daClient,
-		RoleAssignments: raClient,
-		RoleDefinitions: rdClient,
-	}, err
-}
-
 // AzureDenyAssignmentsClient is a facade over the Azure deny assignments client. Exists to make our
 // code easier to test (it handles paging).
 type AzureDenyAssignmentsClient struct {
-	ctx    context.Context
-	client *armauthorization.DenyAssignmentsClient
+	ctx            context.Context
+	client         *armauthorization.DenyAssignmentsClient
+	correlationIDs correlationIDLog
 }
 
 // NewAzureDenyAssignmentsClient creates a new AzureDenyAssignmentsClient (our facade client) from a
@@ -88,20 +31,43 @@ func NewAzureDenyAssignmentsClient(ctx context.Context, azClient *armauthorizati
 	}
 }
 
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureDenyAssignmentsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureDenyAssignmentsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
 // GetDenyAssignmentsForScope gets all the deny assignments matching a scope and an optional filter.
-func (c *AzureDenyAssignmentsClient) GetDenyAssignmentsForScope(scope string, filter *string) ([]*armauthorization.DenyAssignment, error) {
-	var denyAssignments []*armauthorization.DenyAssignment
+func (c *AzureDenyAssignmentsClient) GetDenyAssignmentsForScope(scope string, filter *string) (denyAssignments []*armauthorization.DenyAssignment, err error) {
+	ctx, span := startScopeSpan(c.ctx, "DenyAssignments.ListForScope", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
 	pager := c.client.NewListForScopePager(scope, &armauthorization.DenyAssignmentsClientListForScopeOptions{
 		Filter: filter,
 	})
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 	go func() {
 		defer close(ch)
 		for pager.More() {
-			nextResult, err := pager.NextPage(c.ctx)
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
 			if err != nil {
-				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
 			}
 			if nextResult.Value != nil {
 				denyAssignments = append(denyAssignments, nextResult.Value...)
@@ -111,18 +77,19 @@ func (c *AzureDenyAssignmentsClient) GetDenyAssignmentsForScope(scope string, fi
 	}()
 
 	select {
-	case err := <-ch:
+	case err = <-ch:
 		return denyAssignments, err
 	case <-c.ctx.Done():
-		return denyAssignments, fmt.Errorf("context cancelled")
+		return denyAssignments, fmt.Errorf("context cancelled: %w", c.ctx.Err())
 	}
 }
 
 // AzureRoleAssignmentsClient is a facade over the Azure role assignments client. Exists to make our
 // code easier to test (it handles paging).
 type AzureRoleAssignmentsClient struct {
-	ctx    context.Context
-	client *armauthorization.RoleAssignmentsClient
+	ctx            context.Context
+	client         *armauthorization.RoleAssignmentsClient
+	correlationIDs correlationIDLog
 }
 
 // NewAzureRoleAssignmentsClient creates a new AzureRoleAssignmentsClient (our facade client) from a
@@ -134,20 +101,59 @@ func NewAzureRoleAssignmentsClient(ctx context.Context, azClient *armauthorizati
 	}
 }
 
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureRoleAssignmentsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureRoleAssignmentsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// RoleAssignmentFilter returns the filter for GetRoleAssignmentsForScope that gets the role
+// assignments of a principal. If expandGroups, it uses assignedTo(), for which ARM also returns the
+// role assignments of the groups the principal is a member of (directly or transitively), instead
+// of principalId eq, which only matches role assignments made to the principal itself.
+//
+// Note that Azure's Go SDK has a bug where it doesn't escape the filter string for the role
+// assignments call, so the filter is escaped here.
+// https://github.com/Azure/azure-sdk-for-go/issues/20847
+func RoleAssignmentFilter(principalID string, expandGroups bool) string {
+	filter := fmt.Sprintf("principalId eq '%s'", principalID)
+	if expandGroups {
+		filter = fmt.Sprintf("assignedTo('%s')", principalID)
+	}
+	return url.QueryEscape(filter)
+}
+
 // GetRoleAssignmentsForScope gets all the role assignments matching a scope and an optional filter.
-func (c *AzureRoleAssignmentsClient) GetRoleAssignmentsForScope(scope string, filter *string) ([]*armauthorization.RoleAssignment, error) {
-	var roleAssignments []*armauthorization.RoleAssignment
+func (c *AzureRoleAssignmentsClient) GetRoleAssignmentsForScope(scope string, filter *string) (roleAssignments []*armauthorization.RoleAssignment, err error) {
+	ctx, span := startScopeSpan(c.ctx, "RoleAssignments.ListForScope", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
 	pager := c.client.NewListForScopePager(scope, &armauthorization.RoleAssignmentsClientListForScopeOptions{
 		Filter: filter,
 	})
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 	go func() {
 		defer close(ch)
 		for pager.More() {
-			nextResult, err := pager.NextPage(c.ctx)
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
 			if err != nil {
-				ch <- fmt.Errorf("failed to get next page of results: %w", err)
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
 			}
 			if nextResult.Value != nil {
 				roleAssignments = append(roleAssignments, nextResult.Value...)
@@ -157,10 +163,10 @@ func (c *AzureRoleAssignmentsClient) GetRoleAssignmentsForScope(scope string, fi
 	}()
 
 	select {
-	case err := <-ch:
+	case err = <-ch:
 		return roleAssignments, err
 	case <-c.ctx.Done():
-		return roleAssignments, fmt.Errorf("context cancelled")
+		return roleAssignments, fmt.Errorf("context cancelled: %w", c.ctx.Err())
 	}
 }
 
@@ -168,8 +174,9 @@ func (c *AzureRoleAssignmentsClient) GetRoleAssignmentsForScope(scope string, fi
 // this instead of the actual Azure client is easier to test because it won't need to deal with
 // finding the permissions part of the API response.
 type AzureRoleDefinitionsClient struct {
-	ctx    context.Context
-	client *armauthorization.RoleDefinitionsClient
+	ctx            context.Context
+	client         *armauthorization.RoleDefinitionsClient
+	correlationIDs correlationIDLog
 }
 
 // NewAzureRoleDefinitionsClient creates a new AzureRoleDefinitionsClient (our facade client) from a
@@ -181,12 +188,34 @@ func NewAzureRoleDefinitionsClient(ctx context.Context, azClient *armauthorizati
 	}
 }
 
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureRoleDefinitionsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureRoleDefinitionsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
 // GetByID gets the role definition associated with a role assignment because it uses the
 // fully-qualified role ID contained within the role assignment data to retrieve it from Azure.
-func (c *AzureRoleDefinitionsClient) GetByID(roleID string) (*armauthorization.RoleDefinition, error) {
-	roleDefinitionResp, err := c.client.GetByID(c.ctx, roleID, nil)
+func (c *AzureRoleDefinitionsClient) GetByID(roleID string) (_ *armauthorization.RoleDefinition, err error) {
+	ctx, span := startScopeSpan(c.ctx, "RoleDefinitions.GetByID", roleID)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(roleID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(roleID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	roleDefinitionResp, err := c.client.GetByID(ctx, roleID, nil)
 	if err != nil {
-		return &armauthorization.RoleDefinition{}, fmt.Errorf("failed to get role definition for with ID %s: %w", roleID, err)
+		return &armauthorization.RoleDefinition{}, fmt.Errorf("failed to get role definition for with ID %s: %w", roleID, rec.withCorrelationID(err))
 	}
 	return &roleDefinitionResp.RoleDefinition, nil
 }
@@ -29,3 +29,28 @@ func Test_RoleNameFromRoleDefinitionID(t *testing.T) {
 		})
 	}
 }
+
+func Test_RoleAssignmentFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		expandGroups bool
+		want         string
+	}{
+		{
+			name: "Matches role assignments made to the principal.",
+			want: "principalId+eq+%2700000000-0000-0000-0000-000000000001%27",
+		},
+		{
+			name:         "Matches role assignments made to the principal and its groups.",
+			expandGroups: true,
+			want:         "assignedTo%28%2700000000-0000-0000-0000-000000000001%27%29",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleAssignmentFilter("00000000-0000-0000-0000-000000000001", tt.expandGroups); got != tt.want {
+				t.Errorf("RoleAssignmentFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+)
+
+const (
+	// storageScope is the scope of tokens for Azure Storage's data plane, in every cloud.
+	storageScope = "https://storage.azure.com/.default"
+	// blobServiceVersion is the version of the Blob service REST API that blobs are uploaded with.
+	blobServiceVersion = "2023-11-03"
+)
+
+// BlobClient uploads blobs to Azure Storage. There's no Azure SDK for Go module for blobs among
+// the plugin's dependencies, so, like GraphClient's, its requests go through an azcore pipeline of
+// our own, with the same options as the SDK's clients.
+type BlobClient struct {
+	pipeline runtime.Pipeline
+}
+
+// NewBlobClient creates a BlobClient that authenticates with cred.
+func NewBlobClient(cred azcore.TokenCredential, options *policy.ClientOptions) *BlobClient {
+	if options == nil {
+		options = &policy.ClientOptions{}
+	}
+	auth := runtime.NewBearerTokenPolicy(cred, []string{storageScope}, nil)
+	return &BlobClient{
+		pipeline: runtime.NewPipeline("blob", "v1.0", runtime.PipelineOptions{PerRetry: []policy.Policy{auth}}, options),
+	}
+}
+
+// UploadBlockBlob uploads data as a block blob with a name to the container at containerURL,
+// replacing the blob of that name, if there is one. Slashes in the name are kept, as the virtual
+// directories of the blob.
+func (c *BlobClient) UploadBlockBlob(ctx context.Context, containerURL, name, contentType string, data []byte) error {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	req, err := runtime.NewRequest(ctx, http.MethodPut, strings.TrimSuffix(containerURL, "/")+"/"+strings.Join(segments, "/"))
+	if err != nil {
+		return err
+	}
+	req.Raw().Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Raw().Header.Set("x-ms-version", blobServiceVersion)
+	if err := req.SetBody(streaming.NopCloser(bytes.NewReader(data)), contentType); err != nil {
+		return err
+	}
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusCreated) {
+		return runtime.NewResponseError(resp)
+	}
+	return nil
+}
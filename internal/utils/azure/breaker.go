@@ -0,0 +1,152 @@
+package azure
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"k8s.io/utils/clock"
+
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+)
+
+// breaker short-circuits Azure API calls made by all facades in the process for subscriptions
+// whose ARM endpoints are consistently failing. It is nil unless a circuit breaker has been set.
+var breaker atomic.Pointer[circuitBreaker]
+
+// SetCircuitBreaker stops facades from making Azure API calls for a subscription for cooldown
+// after threshold consecutive calls for it failed with transient errors or throttling within
+// window. A threshold of zero or less removes the circuit breaker.
+func SetCircuitBreaker(threshold int, window, cooldown time.Duration) {
+	if threshold <= 0 {
+		breaker.Store(nil)
+		return
+	}
+	breaker.Store(newCircuitBreaker(clock.RealClock{}, threshold, window, cooldown))
+}
+
+// circuitBreaker tracks a circuit per subscription. A subscription's circuit is closed (calls are
+// made) until it is opened by repeated failures. Once the cooldown has passed, it is half-open: a
+// single call is made to probe whether ARM has recovered, which closes the circuit if it succeeds
+// and re-opens it if it fails.
+type circuitBreaker struct {
+	clock     clock.PassiveClock
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of the circuit for a single subscription.
+type circuit struct {
+	// failures is the number of consecutive failures since firstFailure.
+	failures     int
+	firstFailure time.Time
+	// openUntil is when the circuit becomes half-open. Zero if the circuit is closed.
+	openUntil time.Time
+	// probing is whether the call probing a half-open circuit is in progress.
+	probing bool
+}
+
+func newCircuitBreaker(clock clock.PassiveClock, threshold int, window, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		clock:     clock,
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+		circuits:  map[string]*circuit{},
+	}
+}
+
+// allow returns an error if calls for a subscription are currently being short-circuited.
+func (b *circuitBreaker) allow(subscriptionID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[subscriptionID]
+	if !ok || c.openUntil.IsZero() {
+		return nil
+	}
+	if b.clock.Now().Before(c.openUntil) || c.probing {
+		return &azure_errors.CircuitOpenError{SubscriptionID: subscriptionID, RetryAt: c.openUntil}
+	}
+	c.probing = true
+	return nil
+}
+
+// record records the outcome of a call for a subscription that allow permitted.
+func (b *circuitBreaker) record(subscriptionID string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[subscriptionID]
+	if !ok {
+		c = &circuit{}
+		b.circuits[subscriptionID] = c
+	}
+
+	if !isARMOutage(err) {
+		// Any response from ARM that isn't a server error or throttling means the subscription's
+		// ARM endpoints are working. Other errors (e.g. a cancelled context) say nothing about
+		// ARM, but must still end a probe, or the circuit would never close.
+		var rerr *azcore.ResponseError
+		if err == nil || errors.As(err, &rerr) {
+			delete(b.circuits, subscriptionID)
+		}
+		c.probing = false
+		return
+	}
+
+	now := b.clock.Now()
+	if c.probing {
+		c.probing = false
+		c.failures = 0
+		c.openUntil = now.Add(b.cooldown)
+		return
+	}
+	if c.failures == 0 || now.Sub(c.firstFailure) > b.window {
+		c.failures = 0
+		c.firstFailure = now
+	}
+	c.failures++
+	if c.failures >= b.threshold {
+		c.failures = 0
+		c.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// isARMOutage returns whether an error counts towards opening a circuit.
+func isARMOutage(err error) bool {
+	switch azure_errors.Classify(err) {
+	case azure_errors.CategoryTransient, azure_errors.CategoryThrottled:
+		return true
+	}
+	return false
+}
+
+// allowCall returns an error if calls for the subscription of a scope are currently being
+// short-circuited. Scopes outside of a subscription (e.g. management groups) are never
+// short-circuited.
+func allowCall(scope string) error {
+	b := breaker.Load()
+	sub := SubscriptionIDFromScope(scope)
+	if b == nil || sub == "" {
+		return nil
+	}
+	return b.allow(sub)
+}
+
+// recordCall records the outcome of a call for the subscription of a scope that allowCall
+// permitted.
+func recordCall(scope string, err error) {
+	b := breaker.Load()
+	sub := SubscriptionIDFromScope(scope)
+	if b == nil || sub == "" {
+		return
+	}
+	b.record(sub, err)
+}
@@ -0,0 +1,200 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+)
+
+const (
+	failingSubscription = "00000000-0000-0000-0000-000000000001"
+	healthySubscription = "00000000-0000-0000-0000-000000000002"
+)
+
+// armOutage is a fake ARM that returns 503s for one subscription, until it recovers, and empty
+// results for all other subscriptions. It counts the requests made for each subscription.
+type armOutage struct {
+	mu        sync.Mutex
+	recovered bool
+	requests  map[string]int
+}
+
+func (a *armOutage) Do(req *http.Request) (*http.Response, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sub := SubscriptionIDFromScope(req.URL.Path)
+	a.requests[sub]++
+
+	statusCode, body := http.StatusOK, `{"value": []}`
+	if sub == failingSubscription && !a.recovered {
+		statusCode, body = http.StatusServiceUnavailable, `{"error": {"code": "ServiceUnavailable", "message": "unavailable"}}`
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (a *armOutage) requestCount(sub string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[sub]
+}
+
+func (a *armOutage) recover() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.recovered = true
+}
+
+func Test_CircuitBreaker(t *testing.T) {
+	const (
+		threshold = 3
+		window    = time.Minute
+		cooldown  = 5 * time.Minute
+	)
+	clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker.Store(newCircuitBreaker(clk, threshold, window, cooldown))
+	defer breaker.Store(nil)
+
+	arm := &armOutage{requests: map[string]int{}}
+	azClient, err := armauthorization.NewRoleAssignmentsClient("", &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: arm,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewAzureRoleAssignmentsClient(context.Background(), azClient)
+	get := func(sub string) error {
+		_, err := client.GetRoleAssignmentsForScope(fmt.Sprintf("/subscriptions/%s", sub), nil)
+		return err
+	}
+
+	// Drive the breaker open.
+	for i := 0; i < threshold; i++ {
+		if err := get(failingSubscription); err == nil {
+			t.Fatal("expected an error from ARM")
+		}
+	}
+	if got := arm.requestCount(failingSubscription); got != threshold {
+		t.Fatalf("made %d requests while the circuit was closed, want %d", got, threshold)
+	}
+
+	// Calls for the failing subscription are short-circuited.
+	err = get(failingSubscription)
+	cerr, ok := azure_errors.CircuitOpen(err)
+	if !ok {
+		t.Fatalf("expected a CircuitOpenError, got %v", err)
+	}
+	if want := clk.Now().Add(cooldown); !cerr.RetryAt.Equal(want) {
+		t.Errorf("RetryAt = %s, want %s", cerr.RetryAt, want)
+	}
+	if !strings.Contains(err.Error(), "subscription temporarily skipped due to repeated Azure errors (retry at 2024-01-01T00:05:00Z)") {
+		t.Errorf("unexpected error message: %s", err)
+	}
+	if got := arm.requestCount(failingSubscription); got != threshold {
+		t.Errorf("made %d requests while the circuit was open, want %d", got-threshold, 0)
+	}
+
+	// Healthy subscriptions are unaffected.
+	if err := get(healthySubscription); err != nil {
+		t.Errorf("expected no error for a healthy subscription, got %v", err)
+	}
+
+	// Once the cooldown has passed, a failed probe re-opens the circuit for another cooldown.
+	clk.SetTime(clk.Now().Add(cooldown))
+	if err := get(failingSubscription); err == nil || errors.As(err, &cerr) {
+		t.Fatalf("expected the probe to be made and fail, got %v", err)
+	}
+	if _, ok := azure_errors.CircuitOpen(get(failingSubscription)); !ok {
+		t.Fatal("expected the circuit to re-open after a failed probe")
+	}
+
+	// After ARM recovers, a successful probe closes the circuit.
+	arm.recover()
+	clk.SetTime(clk.Now().Add(cooldown))
+	for i := 0; i < threshold+1; i++ {
+		if err := get(failingSubscription); err != nil {
+			t.Fatalf("expected no error after recovery, got %v", err)
+		}
+	}
+	if got, want := arm.requestCount(failingSubscription), threshold+1+threshold+1; got != want {
+		t.Errorf("made %d requests in total, want %d", got, want)
+	}
+}
+
+func Test_CircuitBreakerHalfOpen(t *testing.T) {
+	clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := newCircuitBreaker(clk, 1, time.Minute, time.Minute)
+	outage := fmt.Errorf("failed to get next page of results: %w", context.DeadlineExceeded)
+
+	b.record(failingSubscription, outage)
+	if err := b.allow(failingSubscription); err == nil {
+		t.Fatal("expected the circuit to be open")
+	}
+
+	// Only one call probes a half-open circuit at a time.
+	clk.SetTime(clk.Now().Add(time.Minute))
+	if err := b.allow(failingSubscription); err != nil {
+		t.Fatalf("expected the probe to be allowed, got %v", err)
+	}
+	if err := b.allow(failingSubscription); err == nil {
+		t.Fatal("expected calls to be short-circuited while probing")
+	}
+
+	// A probe that fails for a reason unrelated to ARM ends the probe without closing the circuit.
+	b.record(failingSubscription, context.Canceled)
+	if err := b.allow(failingSubscription); err != nil {
+		t.Fatalf("expected another probe to be allowed, got %v", err)
+	}
+	b.record(failingSubscription, nil)
+	if err := b.allow(failingSubscription); err != nil {
+		t.Fatalf("expected the circuit to be closed, got %v", err)
+	}
+}
+
+func Test_CircuitBreakerWindow(t *testing.T) {
+	clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := newCircuitBreaker(clk, 2, time.Minute, time.Minute)
+	outage := fmt.Errorf("failed to get next page of results: %w", context.DeadlineExceeded)
+
+	// Failures further apart than the window don't open the circuit.
+	b.record(failingSubscription, outage)
+	clk.SetTime(clk.Now().Add(2 * time.Minute))
+	b.record(failingSubscription, outage)
+	if err := b.allow(failingSubscription); err != nil {
+		t.Fatalf("expected the circuit to be closed, got %v", err)
+	}
+
+	// Neither do failures interrupted by a success.
+	b.record(failingSubscription, nil)
+	b.record(failingSubscription, outage)
+	if err := b.allow(failingSubscription); err != nil {
+		t.Fatalf("expected the circuit to be closed, got %v", err)
+	}
+
+	b.record(failingSubscription, outage)
+	if err := b.allow(failingSubscription); err == nil {
+		t.Fatal("expected the circuit to be open")
+	}
+}
@@ -0,0 +1,399 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// Resource types of the SKUs that are VM sizes and managed disk types.
+const (
+	resourceTypeVirtualMachines = "virtualMachines"
+	resourceTypeDisks           = "disks"
+)
+
+// AzureGalleryImagesClient is a facade over the Azure compute gallery image definitions client.
+// Exists to make our code easier to test. Image definitions are identified by their resource IDs,
+// so they can be in a different subscription than the VMs that use them.
+type AzureGalleryImagesClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armcompute.GalleryImagesClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureGalleryImagesClient creates a new AzureGalleryImagesClient (our facade client) that gets
+// the client from the Azure SDK for each subscription from clients.
+func NewAzureGalleryImagesClient(ctx context.Context, clients func(subscriptionID string) (*armcompute.GalleryImagesClient, error)) *AzureGalleryImagesClient {
+	return &AzureGalleryImagesClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureGalleryImagesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureGalleryImagesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetGalleryImage gets an image definition by its resource ID.
+func (c *AzureGalleryImagesClient) GetGalleryImage(imageDefinitionID string) (_ *armcompute.GalleryImage, err error) {
+	ctx, span := startScopeSpan(c.ctx, "GalleryImages.Get", imageDefinitionID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(imageDefinitionID)
+	if err != nil || id.Parent == nil {
+		return nil, fmt.Errorf("failed to parse image definition ID %s: %w", imageDefinitionID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(imageDefinitionID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(imageDefinitionID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image definition %s: %w", imageDefinitionID, rec.withCorrelationID(err))
+	}
+	return &resp.GalleryImage, nil
+}
+
+// AzureGalleryImageVersionsClient is a facade over the Azure compute gallery image versions
+// client. Exists to make our code easier to test. Like image definitions, image versions are
+// identified by their resource IDs.
+type AzureGalleryImageVersionsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureGalleryImageVersionsClient creates a new AzureGalleryImageVersionsClient (our facade
+// client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureGalleryImageVersionsClient(ctx context.Context, clients func(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error)) *AzureGalleryImageVersionsClient {
+	return &AzureGalleryImageVersionsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureGalleryImageVersionsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureGalleryImageVersionsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetGalleryImageVersion gets an image version by its resource ID, with the status of its
+// replication to each of its target regions.
+func (c *AzureGalleryImageVersionsClient) GetGalleryImageVersion(imageVersionID string) (_ *armcompute.GalleryImageVersion, err error) {
+	ctx, span := startScopeSpan(c.ctx, "GalleryImageVersions.Get", imageVersionID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(imageVersionID)
+	if err != nil || id.Parent == nil || id.Parent.Parent == nil {
+		return nil, fmt.Errorf("failed to parse image version ID %s: %w", imageVersionID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(imageVersionID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(imageVersionID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	expand := armcompute.ReplicationStatusTypesReplicationStatus
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Parent.Name, id.Parent.Name, id.Name, &armcompute.GalleryImageVersionsClientGetOptions{
+		Expand: &expand,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image version %s: %w", imageVersionID, rec.withCorrelationID(err))
+	}
+	return &resp.GalleryImageVersion, nil
+}
+
+// ListGalleryImageVersions lists the versions of an image definition, identified by its resource
+// ID. Listed versions have their publishing profiles, but not their replication status.
+func (c *AzureGalleryImageVersionsClient) ListGalleryImageVersions(imageDefinitionID string) (versions []*armcompute.GalleryImageVersion, err error) {
+	ctx, span := startScopeSpan(c.ctx, "GalleryImageVersions.ListByGalleryImage", imageDefinitionID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(imageDefinitionID)
+	if err != nil || id.Parent == nil {
+		return nil, fmt.Errorf("failed to parse image definition ID %s: %w", imageDefinitionID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(imageDefinitionID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(imageDefinitionID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := client.NewListByGalleryImagePager(id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+	for pager.More() {
+		if err = waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of image definition %s: %w", imageDefinitionID, rec.withCorrelationID(err))
+		}
+		versions = append(versions, page.Value...)
+	}
+	return versions, nil
+}
+
+// AzureProximityPlacementGroupsClient is a facade over the Azure proximity placement groups
+// client. Exists to make our code easier to test. Proximity placement groups are identified by
+// their resource IDs.
+type AzureProximityPlacementGroupsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armcompute.ProximityPlacementGroupsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureProximityPlacementGroupsClient creates a new AzureProximityPlacementGroupsClient (our
+// facade client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureProximityPlacementGroupsClient(ctx context.Context, clients func(subscriptionID string) (*armcompute.ProximityPlacementGroupsClient, error)) *AzureProximityPlacementGroupsClient {
+	return &AzureProximityPlacementGroupsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureProximityPlacementGroupsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureProximityPlacementGroupsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetProximityPlacementGroup gets a proximity placement group by its resource ID, with its
+// colocation status and the colocation status of each of its members.
+func (c *AzureProximityPlacementGroupsClient) GetProximityPlacementGroup(ppgID string) (_ *armcompute.ProximityPlacementGroup, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ProximityPlacementGroups.Get", ppgID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(ppgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proximity placement group ID %s: %w", ppgID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(ppgID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(ppgID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	includeColocationStatus := "true"
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, &armcompute.ProximityPlacementGroupsClientGetOptions{
+		IncludeColocationStatus: &includeColocationStatus,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proximity placement group %s: %w", ppgID, rec.withCorrelationID(err))
+	}
+	return &resp.ProximityPlacementGroup, nil
+}
+
+// AzureResourceSKUsClient is a facade over the Azure resource SKUs client for a single
+// subscription. Exists to make our code easier to test.
+type AzureResourceSKUsClient struct {
+	ctx            context.Context
+	client         *armcompute.ResourceSKUsClient
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureResourceSKUsClient creates a new AzureResourceSKUsClient (our facade client) from a
+// client from the Azure SDK for the subscription with ID subscriptionID.
+func NewAzureResourceSKUsClient(ctx context.Context, azClient *armcompute.ResourceSKUsClient, subscriptionID string) *AzureResourceSKUsClient {
+	return &AzureResourceSKUsClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureResourceSKUsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureResourceSKUsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListVirtualMachineSKUs gets the VM sizes available to the subscription in a location, with their
+// capabilities.
+func (c *AzureResourceSKUsClient) ListVirtualMachineSKUs(location string) ([]*armcompute.ResourceSKU, error) {
+	return c.listSKUs(location, resourceTypeVirtualMachines)
+}
+
+// ListDiskSKUs gets the managed disk types (e.g. UltraSSD_LRS) available to the subscription in a
+// location, with the zones they're offered in.
+func (c *AzureResourceSKUsClient) ListDiskSKUs(location string) ([]*armcompute.ResourceSKU, error) {
+	return c.listSKUs(location, resourceTypeDisks)
+}
+
+// listSKUs gets the SKUs of a compute resource type available to the subscription in a location.
+func (c *AzureResourceSKUsClient) listSKUs(location, resourceType string) (skus []*armcompute.ResourceSKU, err error) {
+	scope := fmt.Sprintf("/subscriptions/%s", c.subscriptionID)
+	ctx, span := startScopeSpan(c.ctx, "ResourceSKUs.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	filter := fmt.Sprintf("location eq '%s'", location)
+	pager := c.client.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: &filter,
+	})
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			// The list includes the SKUs of all compute resource types, which the API can't
+			// filter by.
+			for _, sku := range nextResult.Value {
+				if sku != nil && sku.ResourceType != nil && strings.EqualFold(*sku.ResourceType, resourceType) {
+					skus = append(skus, sku)
+				}
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return skus, err
+	case <-c.ctx.Done():
+		return skus, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
+
+// AzureVirtualMachineImagesClient is a facade over the Azure platform (marketplace) VM images
+// client for a single subscription. Exists to make our code easier to test.
+type AzureVirtualMachineImagesClient struct {
+	ctx            context.Context
+	client         *armcompute.VirtualMachineImagesClient
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureVirtualMachineImagesClient creates a new AzureVirtualMachineImagesClient (our facade
+// client) from a client from the Azure SDK for the subscription with ID subscriptionID.
+func NewAzureVirtualMachineImagesClient(ctx context.Context, azClient *armcompute.VirtualMachineImagesClient, subscriptionID string) *AzureVirtualMachineImagesClient {
+	return &AzureVirtualMachineImagesClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureVirtualMachineImagesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureVirtualMachineImagesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// imageSKUScope returns the resource ID under which the versions of a platform image SKU are
+// listed in a location.
+func (c *AzureVirtualMachineImagesClient) imageSKUScope(location, publisher, offer, sku string) string {
+	return fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/locations/%s/publishers/%s/artifacttypes/vmimage/offers/%s/skus/%s",
+		c.subscriptionID, location, publisher, offer, sku)
+}
+
+// GetVirtualMachineImage gets a version of a platform image in a location, with its deprecation
+// status.
+func (c *AzureVirtualMachineImagesClient) GetVirtualMachineImage(location, publisher, offer, sku, version string) (_ *armcompute.VirtualMachineImage, err error) {
+	scope := c.imageSKUScope(location, publisher, offer, sku) + "/versions/" + version
+	ctx, span := startScopeSpan(c.ctx, "VirtualMachineImages.Get", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Get(ctx, location, publisher, offer, sku, version, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image %s:%s:%s:%s: %w", publisher, offer, sku, version, rec.withCorrelationID(err))
+	}
+	return &resp.VirtualMachineImage, nil
+}
+
+// ListVirtualMachineImageVersions lists the versions of a platform image SKU in a location. Each
+// version's name is its version number.
+func (c *AzureVirtualMachineImagesClient) ListVirtualMachineImageVersions(location, publisher, offer, sku string) (_ []*armcompute.VirtualMachineImageResource, err error) {
+	scope := c.imageSKUScope(location, publisher, offer, sku)
+	ctx, span := startScopeSpan(c.ctx, "VirtualMachineImages.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.List(ctx, location, publisher, offer, sku, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of image %s:%s:%s: %w", publisher, offer, sku, rec.withCorrelationID(err))
+	}
+	return resp.VirtualMachineImageResourceArray, nil
+}
@@ -0,0 +1,205 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func Test_ListSKUs(t *testing.T) {
+	const sub = "00000000-0000-0000-0000-000000000000"
+	var filters []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		filters = append(filters, req.URL.Query().Get("$filter"))
+		body := `{"value": [
+			{"resourceType": "virtualMachines", "name": "Standard_D4s_v5"},
+			{"resourceType": "disks", "name": "UltraSSD_LRS"},
+			{"resourceType": "availabilitySets", "name": "Aligned"},
+			{"resourceType": "disks", "name": "PremiumV2_LRS"}
+		]}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	azClient, err := armcompute.NewResourceSKUsClient(sub, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := NewAzureResourceSKUsClient(context.Background(), azClient, sub)
+
+	names := func(skus []*armcompute.ResourceSKU) []string {
+		var names []string
+		for _, sku := range skus {
+			names = append(names, *sku.Name)
+		}
+		return names
+	}
+	vmSKUs, err := client.ListVirtualMachineSKUs("eastus")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Standard_D4s_v5"}; !reflect.DeepEqual(names(vmSKUs), want) {
+		t.Errorf("got VM sizes %v, want %v", names(vmSKUs), want)
+	}
+	diskSKUs, err := client.ListDiskSKUs("eastus")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"UltraSSD_LRS", "PremiumV2_LRS"}; !reflect.DeepEqual(names(diskSKUs), want) {
+		t.Errorf("got disk types %v, want %v", names(diskSKUs), want)
+	}
+	if want := []string{"location eq 'eastus'", "location eq 'eastus'"}; !reflect.DeepEqual(filters, want) {
+		t.Errorf("got filters %v, want %v", filters, want)
+	}
+}
+
+func Test_GetGalleryImageVersion(t *testing.T) {
+	const id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/galleries/gallery/images/ubuntu-2204/versions/1.0.0"
+	var reqs []*http.Request
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		reqs = append(reqs, req)
+		body := `{"name": "1.0.0", "properties": {"replicationStatus": {"aggregatedState": "Completed"}}}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	client := NewAzureGalleryImageVersionsClient(context.Background(), func(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error) {
+		return armcompute.NewGalleryImageVersionsClient(subscriptionID, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+			ClientOptions: policy.ClientOptions{
+				Transport: transport,
+				Retry:     policy.RetryOptions{MaxRetries: -1},
+			},
+		})
+	})
+
+	version, err := client.GetGalleryImageVersion(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := *version.Properties.ReplicationStatus.AggregatedState; got != armcompute.AggregatedReplicationStateCompleted {
+		t.Errorf("got aggregated state %s, want Completed", got)
+	}
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	if got := reqs[0].URL.Path; got != id {
+		t.Errorf("got path %s, want %s", got, id)
+	}
+	if got := reqs[0].URL.Query().Get("$expand"); got != "ReplicationStatus" {
+		t.Errorf("got $expand %q, want ReplicationStatus", got)
+	}
+}
+
+func Test_GetVirtualMachineImage(t *testing.T) {
+	const sub = "00000000-0000-0000-0000-000000000000"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `[{"name": "22.04.202401010", "location": "eastus"}, {"name": "22.04.202402010", "location": "eastus"}]`
+		if strings.HasSuffix(req.URL.Path, "/versions/22.04.202401010") {
+			body = `{"name": "22.04.202401010", "properties": {"imageDeprecationStatus": {
+				"imageState": "ScheduledForDeprecation",
+				"scheduledDeprecationTime": "2024-06-01T00:00:00Z"
+			}}}`
+		}
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	azClient, err := armcompute.NewVirtualMachineImagesClient(sub, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := NewAzureVirtualMachineImagesClient(context.Background(), azClient, sub)
+
+	versions, err := client.ListVirtualMachineImageVersions("eastus", "Canonical", "0001-com-ubuntu-server-jammy", "22_04-lts-gen2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 2 || *versions[1].Name != "22.04.202402010" {
+		t.Errorf("got versions %v, want 22.04.202401010 and 22.04.202402010", versions)
+	}
+	image, err := client.GetVirtualMachineImage("eastus", "Canonical", "0001-com-ubuntu-server-jammy", "22_04-lts-gen2", "22.04.202401010")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := *image.Properties.ImageDeprecationStatus.ImageState; got != armcompute.ImageStateScheduledForDeprecation {
+		t.Errorf("got image state %s, want ScheduledForDeprecation", got)
+	}
+	prefix := "/subscriptions/" + sub + "/providers/Microsoft.Compute/locations/eastus/publishers/Canonical/artifacttypes/vmimage/offers/0001-com-ubuntu-server-jammy/skus/22_04-lts-gen2/versions"
+	if want := []string{prefix, prefix + "/22.04.202401010"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
+
+func Test_ListGalleryImageVersions(t *testing.T) {
+	const id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/galleries/gallery/images/ubuntu-2204"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"value": [
+			{"name": "1.0.0", "properties": {"publishingProfile": {"excludeFromLatest": false}}},
+			{"name": "1.1.0", "properties": {"publishingProfile": {"excludeFromLatest": true}}}
+		]}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	client := NewAzureGalleryImageVersionsClient(context.Background(), func(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error) {
+		return armcompute.NewGalleryImageVersionsClient(subscriptionID, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+			ClientOptions: policy.ClientOptions{
+				Transport: transport,
+				Retry:     policy.RetryOptions{MaxRetries: -1},
+			},
+		})
+	})
+
+	versions, err := client.ListGalleryImageVersions(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 2 || !*versions[1].Properties.PublishingProfile.ExcludeFromLatest {
+		t.Errorf("got versions %v, want 1.0.0 and 1.1.0, excluded from latest", versions)
+	}
+	if want := []string{id + "/versions"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,77 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+)
+
+// AzureBudgetsClient is a facade over the Azure Cost Management budgets client. Exists to make our
+// code easier to test (it handles paging).
+type AzureBudgetsClient struct {
+	ctx            context.Context
+	client         *armconsumption.BudgetsClient
+	correlationIDs correlationIDLog
+}
+
+// NewAzureBudgetsClient creates a new AzureBudgetsClient (our facade client) from a client from the
+// Azure SDK.
+func NewAzureBudgetsClient(ctx context.Context, azClient *armconsumption.BudgetsClient) *AzureBudgetsClient {
+	return &AzureBudgetsClient{
+		ctx:    ctx,
+		client: azClient,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureBudgetsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureBudgetsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListBudgetsForScope gets all the budgets defined at a scope (e.g. a subscription or a resource
+// group). Budgets of containing scopes aren't included.
+func (c *AzureBudgetsClient) ListBudgetsForScope(scope string) (budgets []*armconsumption.Budget, err error) {
+	ctx, span := startScopeSpan(c.ctx, "Budgets.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := c.client.NewListPager(scope, nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				budgets = append(budgets, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return budgets, err
+	case <-c.ctx.Done():
+		return budgets, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
@@ -0,0 +1,89 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+)
+
+type correlationIDRecorderKey struct{}
+
+// correlationIDRecorder records the correlation request ID of the most recent response ARM sent for
+// requests made with a context. Facades use it to attach correlation request IDs to errors.
+type correlationIDRecorder struct {
+	mu  sync.Mutex
+	id  string
+	log *correlationIDLog
+}
+
+func (r *correlationIDRecorder) set(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.id = id
+	if r.log != nil && id != "" {
+		r.log.add(id)
+	}
+}
+
+func (r *correlationIDRecorder) get() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.id
+}
+
+// withCorrelationIDRecorder returns a context that records the correlation request IDs of requests
+// made with it. All recorded IDs are also added to log.
+func withCorrelationIDRecorder(ctx context.Context, log *correlationIDLog) (context.Context, *correlationIDRecorder) {
+	r := &correlationIDRecorder{log: log}
+	return context.WithValue(ctx, correlationIDRecorderKey{}, r), r
+}
+
+// correlationIDLog records the correlation request IDs of all the ARM responses a facade received,
+// in the order they were received, along with the versions of the APIs that sent them.
+type correlationIDLog struct {
+	mu  sync.Mutex
+	ids []string
+	// keys = APIs (see apiName)
+	apis map[string]apiVersion
+}
+
+func (l *correlationIDLog) add(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ids = append(l.ids, id)
+}
+
+func (l *correlationIDLog) all() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.ids...)
+}
+
+// withCorrelationID attaches the most recently recorded correlation request ID to err.
+func (r *correlationIDRecorder) withCorrelationID(err error) error {
+	return azure_errors.WithCorrelationID(err, r.get())
+}
+
+// correlationIDPolicy is an azcore pipeline policy that records the correlation request ID of each
+// response, or, for Microsoft Graph responses, the request ID, in the request context's
+// correlationIDRecorder, if there is one.
+type correlationIDPolicy struct{}
+
+// Do implements policy.Policy.
+func (correlationIDPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if resp != nil {
+		if r, ok := req.Raw().Context().Value(correlationIDRecorderKey{}).(*correlationIDRecorder); ok {
+			id := resp.Header.Get(azure_errors.CorrelationIDHeader)
+			if id == "" {
+				id = resp.Header.Get(graphRequestIDHeader)
+			}
+			r.set(id)
+		}
+	}
+	return resp, err
+}
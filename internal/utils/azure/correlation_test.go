@@ -0,0 +1,115 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+)
+
+type transportFunc func(req *http.Request) (*http.Response, error)
+
+func (f transportFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_CorrelationID(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "Attaches the correlation request ID to error responses from ARM.",
+			statusCode: http.StatusForbidden,
+			body:       `{"error": {"code": "AuthorizationFailed", "message": "denied"}}`,
+		},
+		{
+			// The SDK doesn't return an azcore.ResponseError in this case, so the correlation
+			// request ID is only known because correlationIDPolicy recorded it.
+			name:       "Attaches the correlation request ID to errors processing successful responses from ARM.",
+			statusCode: http.StatusOK,
+			body:       `not json`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("Content-Type", "application/json")
+				header.Set(azure_errors.CorrelationIDHeader, "correlation-id")
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Header:     header,
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+					Request:    req,
+				}, nil
+			})
+			client, err := armauthorization.NewRoleDefinitionsClient(&azfake.TokenCredential{}, &armpolicy.ClientOptions{
+				ClientOptions: policy.ClientOptions{
+					Transport:        transport,
+					Retry:            policy.RetryOptions{MaxRetries: -1},
+					PerRetryPolicies: []policy.Policy{correlationIDPolicy{}},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = NewAzureRoleDefinitionsClient(context.Background(), client).GetByID("/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleDefinitions/rd")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := azure_errors.CorrelationID(err); got != "correlation-id" {
+				t.Errorf("CorrelationID() = %q, want %q", got, "correlation-id")
+			}
+		})
+	}
+}
+
+func Test_CorrelationIDs(t *testing.T) {
+	calls := 0
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		header.Set(azure_errors.CorrelationIDHeader, fmt.Sprintf("correlation-id-%d", calls))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(`{"value": []}`)),
+			Request:    req,
+		}, nil
+	})
+	azClient, err := armauthorization.NewRoleAssignmentsClient("", &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport:        transport,
+			Retry:            policy.RetryOptions{MaxRetries: -1},
+			PerRetryPolicies: []policy.Policy{correlationIDPolicy{}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewAzureRoleAssignmentsClient(context.Background(), azClient)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetRoleAssignmentsForScope("/subscriptions/00000000-0000-0000-0000-000000000000", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"correlation-id-1", "correlation-id-2"}
+	if got := client.CorrelationIDs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CorrelationIDs() = %v, want %v", got, want)
+	}
+}
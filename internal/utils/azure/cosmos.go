@@ -0,0 +1,65 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
+)
+
+// AzureDatabaseAccountsClient is a facade over the Azure Cosmos DB database accounts client.
+// Exists to make our code easier to test. Accounts are identified by their resource IDs.
+type AzureDatabaseAccountsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armcosmos.DatabaseAccountsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureDatabaseAccountsClient creates a new AzureDatabaseAccountsClient (our facade client) that
+// gets the client from the Azure SDK for each subscription from clients.
+func NewAzureDatabaseAccountsClient(ctx context.Context, clients func(subscriptionID string) (*armcosmos.DatabaseAccountsClient, error)) *AzureDatabaseAccountsClient {
+	return &AzureDatabaseAccountsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureDatabaseAccountsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureDatabaseAccountsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetDatabaseAccount gets a Cosmos DB account by its resource ID.
+func (c *AzureDatabaseAccountsClient) GetDatabaseAccount(accountID string) (_ *armcosmos.DatabaseAccountGetResults, err error) {
+	ctx, span := startScopeSpan(c.ctx, "DatabaseAccounts.Get", accountID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Cosmos DB account ID %s: %w", accountID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(accountID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(accountID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Cosmos DB account %s: %w", accountID, rec.withCorrelationID(err))
+	}
+	return &resp.DatabaseAccountGetResults, nil
+}
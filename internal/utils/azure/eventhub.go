@@ -0,0 +1,145 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
+)
+
+// AzureEventHubsClient is a facade over the Azure Event Hubs namespaces and event hubs clients.
+// Exists to make our code easier to test. Namespaces, event hubs, and authorization rules are
+// identified by their resource IDs.
+type AzureEventHubsClient struct {
+	ctx            context.Context
+	namespaces     func(subscriptionID string) (*armeventhub.NamespacesClient, error)
+	eventHubs      func(subscriptionID string) (*armeventhub.EventHubsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureEventHubsClient creates a new AzureEventHubsClient (our facade client) that gets the
+// clients from the Azure SDK for each subscription from namespaces and eventHubs.
+func NewAzureEventHubsClient(ctx context.Context, namespaces func(subscriptionID string) (*armeventhub.NamespacesClient, error), eventHubs func(subscriptionID string) (*armeventhub.EventHubsClient, error)) *AzureEventHubsClient {
+	return &AzureEventHubsClient{
+		ctx:        ctx,
+		namespaces: namespaces,
+		eventHubs:  eventHubs,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureEventHubsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureEventHubsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetNamespace gets an Event Hubs namespace by its resource ID.
+func (c *AzureEventHubsClient) GetNamespace(namespaceID string) (_ *armeventhub.EHNamespace, err error) {
+	ctx, span := startScopeSpan(c.ctx, "EventHubNamespaces.Get", namespaceID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(namespaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Event Hubs namespace ID %s: %w", namespaceID, err)
+	}
+	client, err := c.namespaces(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(namespaceID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(namespaceID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Event Hubs namespace %s: %w", namespaceID, rec.withCorrelationID(err))
+	}
+	return &resp.EHNamespace, nil
+}
+
+// GetEventHub gets an event hub by its resource ID.
+func (c *AzureEventHubsClient) GetEventHub(eventHubID string) (_ *armeventhub.Eventhub, err error) {
+	ctx, span := startScopeSpan(c.ctx, "EventHubs.Get", eventHubID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(eventHubID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse event hub ID %s: %w", eventHubID, err)
+	}
+	client, err := c.eventHubs(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(eventHubID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(eventHubID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event hub %s: %w", eventHubID, rec.withCorrelationID(err))
+	}
+	return &resp.Eventhub, nil
+}
+
+// GetAuthorizationRule gets an authorization rule of an Event Hubs namespace or of an event hub by
+// its resource ID.
+func (c *AzureEventHubsClient) GetAuthorizationRule(authorizationRuleID string) (_ *armeventhub.AuthorizationRule, err error) {
+	ctx, span := startScopeSpan(c.ctx, "EventHubAuthorizationRules.Get", authorizationRuleID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(authorizationRuleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse authorization rule ID %s: %w", authorizationRuleID, err)
+	}
+	// The rule's parent is either the namespace or an event hub, whose parent is the namespace.
+	onEventHub := strings.EqualFold(id.Parent.ResourceType.Type, "namespaces/eventhubs")
+
+	var get func(context.Context) (armeventhub.AuthorizationRule, error)
+	if onEventHub {
+		client, err := c.eventHubs(id.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		get = func(ctx context.Context) (armeventhub.AuthorizationRule, error) {
+			resp, err := client.GetAuthorizationRule(ctx, id.ResourceGroupName, id.Parent.Parent.Name, id.Parent.Name, id.Name, nil)
+			return resp.AuthorizationRule, err
+		}
+	} else {
+		client, err := c.namespaces(id.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		get = func(ctx context.Context) (armeventhub.AuthorizationRule, error) {
+			resp, err := client.GetAuthorizationRule(ctx, id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+			return resp.AuthorizationRule, err
+		}
+	}
+	if err = allowCall(authorizationRuleID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(authorizationRuleID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	rule, err := get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization rule %s: %w", authorizationRuleID, rec.withCorrelationID(err))
+	}
+	return &rule, nil
+}
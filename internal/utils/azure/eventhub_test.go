@@ -0,0 +1,76 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
+)
+
+func Test_GetEventHubResources(t *testing.T) {
+	const namespaceID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.EventHub/namespaces/ns"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"properties": {"partitionCount": 4, "rights": ["Send"]}}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	opts := &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+	client := NewAzureEventHubsClient(context.Background(),
+		func(subscriptionID string) (*armeventhub.NamespacesClient, error) {
+			return armeventhub.NewNamespacesClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+		func(subscriptionID string) (*armeventhub.EventHubsClient, error) {
+			return armeventhub.NewEventHubsClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+	)
+
+	if _, err := client.GetNamespace(namespaceID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hub, err := client.GetEventHub(namespaceID + "/eventhubs/logs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := *hub.Properties.PartitionCount; got != 4 {
+		t.Errorf("got %d partitions, want 4", got)
+	}
+	for _, ruleID := range []string{namespaceID + "/eventhubs/logs/authorizationRules/send", namespaceID + "/authorizationRules/send"} {
+		rule, err := client.GetAuthorizationRule(ruleID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rights := rule.Properties.Rights; len(rights) != 1 || *rights[0] != armeventhub.AccessRightsSend {
+			t.Errorf("got rights %v, want [Send]", rights)
+		}
+	}
+
+	want := []string{
+		namespaceID,
+		namespaceID + "/eventhubs/logs",
+		namespaceID + "/eventhubs/logs/authorizationRules/send",
+		namespaceID + "/authorizationRules/send",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+}
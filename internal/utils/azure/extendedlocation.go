@@ -0,0 +1,158 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// The resource types of the hosts of Azure Arc custom locations: Arc resource bridges, e.g. of
+// Azure Stack HCI clusters, and Arc-enabled Kubernetes clusters.
+const (
+	ResourceBridgeType   = "Microsoft.ResourceConnector/appliances"
+	ConnectedClusterType = "Microsoft.Kubernetes/connectedClusters"
+)
+
+// arcAPIVersions are the api-versions that Arc resources are read with, by resource type. There
+// are no Azure SDK for Go modules for them among the plugin's dependencies, so they're read with
+// the generic resources client, which needs the api-version of each type.
+var arcAPIVersions = map[string]string{
+	"microsoft.extendedlocation/customlocations":   "2021-08-15",
+	"microsoft.kubernetesconfiguration/extensions": "2023-05-01",
+	strings.ToLower(ResourceBridgeType):            "2022-10-27",
+	strings.ToLower(ConnectedClusterType):          "2024-01-01",
+}
+
+// CustomLocation is an Azure Arc custom location, with the properties that rules check.
+type CustomLocation struct {
+	ID         string                   `json:"id"`
+	Properties CustomLocationProperties `json:"properties"`
+}
+
+// CustomLocationProperties are the properties of an Azure Arc custom location that rules check.
+type CustomLocationProperties struct {
+	ProvisioningState string `json:"provisioningState"`
+	// The resource ID of the custom location's host, an Arc resource bridge or an Arc-enabled
+	// Kubernetes cluster.
+	HostResourceID      string   `json:"hostResourceId"`
+	ClusterExtensionIDs []string `json:"clusterExtensionIds"`
+}
+
+// ClusterExtension is an extension of an Arc resource bridge or Arc-enabled Kubernetes cluster,
+// with the properties that rules check.
+type ClusterExtension struct {
+	ID         string                     `json:"id"`
+	Properties ClusterExtensionProperties `json:"properties"`
+}
+
+// ClusterExtensionProperties are the properties of a cluster extension that rules check.
+type ClusterExtensionProperties struct {
+	// The type of the extension, e.g. microsoft.azstackhci.operator.
+	ExtensionType     string `json:"extensionType"`
+	ProvisioningState string `json:"provisioningState"`
+}
+
+// ArcHost is the host of an Azure Arc custom location, with the properties that rules check.
+type ArcHost struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Properties ArcHostProperties `json:"properties"`
+}
+
+// ArcHostProperties are the properties of the host of a custom location that rules check. Arc
+// resource bridges report their status, and Arc-enabled Kubernetes clusters their connectivity
+// status.
+type ArcHostProperties struct {
+	ProvisioningState  string `json:"provisioningState"`
+	Status             string `json:"status"`
+	ConnectivityStatus string `json:"connectivityStatus"`
+}
+
+// IsArcHost returns whether a resource ID is of an Arc resource bridge or an Arc-enabled
+// Kubernetes cluster, which can host custom locations.
+func IsArcHost(resourceID string) bool {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return false
+	}
+	t := id.ResourceType.String()
+	return strings.EqualFold(t, ResourceBridgeType) || strings.EqualFold(t, ConnectedClusterType)
+}
+
+// AzureArcClient is a facade over the generic Azure resources client, for Azure Arc custom
+// locations and their cluster extensions and hosts. Exists to make our code easier to test.
+// Resources are identified by their resource IDs, like subnets.
+type AzureArcClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armresources.Client, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureArcClient creates a new AzureArcClient (our facade client) that gets the client from the
+// Azure SDK for each subscription from clients.
+func NewAzureArcClient(ctx context.Context, clients func(subscriptionID string) (*armresources.Client, error)) *AzureArcClient {
+	return &AzureArcClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureArcClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureArcClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetCustomLocation gets a custom location by its resource ID.
+func (c *AzureArcClient) GetCustomLocation(customLocationID string) (*CustomLocation, error) {
+	cl := &CustomLocation{}
+	if err := c.get("CustomLocations.Get", "custom location", customLocationID, cl); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// GetClusterExtension gets a cluster extension by its resource ID.
+func (c *AzureArcClient) GetClusterExtension(extensionID string) (*ClusterExtension, error) {
+	ext := &ClusterExtension{}
+	if err := c.get("Extensions.Get", "cluster extension", extensionID, ext); err != nil {
+		return nil, err
+	}
+	return ext, nil
+}
+
+// GetArcHost gets the host of a custom location, an Arc resource bridge or an Arc-enabled
+// Kubernetes cluster, by its resource ID.
+func (c *AzureArcClient) GetArcHost(hostID string) (*ArcHost, error) {
+	host := &ArcHost{}
+	if err := c.get("ArcHosts.Get", "custom location host", hostID, host); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// get gets an Arc resource by its resource ID with the api-version of its type, and unmarshals it
+// into v.
+func (c *AzureArcClient) get(spanName, kind, resourceID string, v any) error {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s ID %s: %w", kind, resourceID, err)
+	}
+	apiVersion, ok := arcAPIVersions[strings.ToLower(id.ResourceType.String())]
+	if !ok {
+		return fmt.Errorf("%s %s has resource type %s, which isn't supported", kind, resourceID, id.ResourceType)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	return getGenericResource(c.ctx, client, &c.correlationIDs, spanName, kind, resourceID, apiVersion, v)
+}
@@ -0,0 +1,106 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+func Test_GetArcResources(t *testing.T) {
+	const (
+		customLocationID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.ExtendedLocation/customLocations/cl"
+		bridgeID         = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.ResourceConnector/appliances/bridge"
+		extensionID      = bridgeID + "/providers/Microsoft.KubernetesConfiguration/extensions/hci"
+	)
+	bodies := map[string]string{
+		customLocationID: `{"id": "` + customLocationID + `", "properties": {"provisioningState": "Succeeded", "hostResourceId": "` + bridgeID + `", "clusterExtensionIds": ["` + extensionID + `"]}}`,
+		extensionID:      `{"properties": {"extensionType": "microsoft.azstackhci.operator", "provisioningState": "Succeeded"}}`,
+		bridgeID:         `{"type": "Microsoft.ResourceConnector/appliances", "properties": {"status": "Running"}}`,
+	}
+	var requests []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.Path+"?api-version="+req.URL.Query().Get("api-version"))
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(bodies[req.URL.Path])),
+			Request:    req,
+		}, nil
+	})
+	opts := &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+	client := NewAzureArcClient(context.Background(), func(subscriptionID string) (*armresources.Client, error) {
+		return armresources.NewClient(subscriptionID, &azfake.TokenCredential{}, opts)
+	})
+
+	cl, err := client.GetCustomLocation(customLocationID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := CustomLocationProperties{ProvisioningState: "Succeeded", HostResourceID: bridgeID, ClusterExtensionIDs: []string{extensionID}}
+	if !reflect.DeepEqual(cl.Properties, want) {
+		t.Errorf("got custom location properties %+v, want %+v", cl.Properties, want)
+	}
+	ext, err := client.GetClusterExtension(extensionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ext.Properties.ExtensionType != "microsoft.azstackhci.operator" {
+		t.Errorf("got extension type %s, want microsoft.azstackhci.operator", ext.Properties.ExtensionType)
+	}
+	host, err := client.GetArcHost(bridgeID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host.Properties.Status != "Running" {
+		t.Errorf("got status %s, want Running", host.Properties.Status)
+	}
+
+	wantRequests := []string{
+		customLocationID + "?api-version=2021-08-15",
+		extensionID + "?api-version=2023-05-01",
+		bridgeID + "?api-version=2022-10-27",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("got requests %v, want %v", requests, wantRequests)
+	}
+
+	// Resources of other types aren't requested.
+	if _, err := client.GetArcHost("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"); err == nil {
+		t.Error("expected an error for a host of an unsupported type")
+	}
+	if len(requests) != len(wantRequests) {
+		t.Errorf("got %d requests, want %d", len(requests), len(wantRequests))
+	}
+}
+
+func Test_IsArcHost(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ResourceConnector/appliances/bridge", true},
+		{"/subscriptions/sub/resourceGroups/rg/providers/microsoft.kubernetes/connectedclusters/cluster", true},
+		{"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks", false},
+		{"not-an-id", false},
+	}
+	for _, tt := range tests {
+		if got := IsArcHost(tt.id); got != tt.want {
+			t.Errorf("IsArcHost(%s) = %t, want %t", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	azpolicy "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/security/armsecurity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azcertificates"
+	"k8s.io/utils/clock"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/tracing"
+)
+
+// Client types, used to cache clients.
+const (
+	clientTypeDenyAssignments  = "DenyAssignments"
+	clientTypeRoleAssignments  = "RoleAssignments"
+	clientTypeRoleDefinitions  = "RoleDefinitions"
+	clientTypeCertificates     = "Certificates"
+	clientTypeManagedClusters  = "ManagedClusters"
+	clientTypeSubnets          = "Subnets"
+	clientTypeNatGateways      = "NatGateways"
+	clientTypeVNetPeerings     = "VirtualNetworkPeerings"
+	clientTypeRouteTables      = "RouteTables"
+	clientTypeFirewallPolicies = "FirewallPolicyRuleCollectionGroups"
+	clientTypeBastionHosts     = "BastionHosts"
+	clientTypeVirtualNetworks  = "VirtualNetworks"
+	clientTypePublicIPPrefixes = "PublicIPPrefixes"
+	clientTypeEHNamespaces     = "EventHubNamespaces"
+	clientTypeEventHubs        = "EventHubs"
+	clientTypeAppGateways      = "ApplicationGateways"
+	clientTypeWAFPolicies      = "WebApplicationFirewallPolicies"
+	clientTypeERCircuits       = "ExpressRouteCircuits"
+	clientTypeGWConnections    = "VirtualNetworkGatewayConnections"
+	clientTypeVNetGateways     = "VirtualNetworkGateways"
+	clientTypeWatchers         = "NetworkWatchers"
+	clientTypeFlowLogs         = "FlowLogs"
+	clientTypeCosmosAccounts   = "DatabaseAccounts"
+	clientTypeSQLServers       = "SQLServers"
+	clientTypeSQLAdmins        = "SQLServerAzureADAdministrators"
+	clientTypeTMProfiles       = "TrafficManagerProfiles"
+	clientTypeGalleryImages    = "GalleryImages"
+	clientTypeImageVersions    = "GalleryImageVersions"
+	clientTypeResourceSKUs     = "ResourceSKUs"
+	clientTypeVMImages         = "VirtualMachineImages"
+	clientTypePPGs             = "ProximityPlacementGroups"
+	clientTypeFeatures         = "Features"
+	clientTypeStorageAccounts  = "StorageAccounts"
+	clientTypeStorageSKUs      = "StorageSKUs"
+	clientTypeBlobContainers   = "BlobContainers"
+	clientTypeFileShares       = "FileShares"
+	clientTypeMgmtPolicies     = "ManagementPolicies"
+	clientTypeGraph            = "Graph"
+	clientTypeBlobs            = "Blobs"
+	clientTypePolicyExemptions = "PolicyExemptions"
+	clientTypePricings         = "Pricings"
+	clientTypeBudgets          = "Budgets"
+	clientTypeManagementLocks  = "ManagementLocks"
+	clientTypeActivityLogs     = "ActivityLogs"
+	clientTypeActionGroups     = "ActionGroups"
+	clientTypeMetricAlerts     = "MetricAlerts"
+	clientTypeDiagSettings     = "DiagnosticSettings"
+	clientTypePermissions      = "Permissions"
+	clientTypeResourceGroups   = "ResourceGroups"
+	clientTypeResources        = "Resources"
+	clientTypeMgmtGroups       = "ManagementGroups"
+)
+
+// ClientFactoryOptions configures a ClientFactory. The zero value authenticates with the
+// DefaultAzureCredential strategy against the Azure public cloud.
+type ClientFactoryOptions struct {
+	// Credential creates the credential used by clients. If nil, a DefaultAzureCredential is
+	// created, which reads its configuration from environment variables when it's created.
+	Credential func() (azcore.TokenCredential, error)
+	// Cloud is the Azure cloud that clients connect to. If zero, the Azure public cloud is used.
+	Cloud cloud.Configuration
+	// Transport sends the clients' HTTP requests. If nil, the Azure SDK's default is used.
+	Transport policy.Transporter
+	// Retry configures how clients retry failed requests. If zero, the Azure SDK's defaults are
+	// used.
+	Retry policy.RetryOptions
+}
+
+// ClientFactory creates Azure service clients lazily, and caches them per credential, so that
+// rules and reconciles using the same credential share clients, and clients that no rule uses are
+// never created. It's the only place where Azure client options are configured.
+type ClientFactory struct {
+	newCredential func() (azcore.TokenCredential, error)
+	opts          *armpolicy.ClientOptions
+	regions       *regionalEndpoints
+
+	mu sync.Mutex
+	// keys = credential identities
+	credentials map[string]*cachedCredential
+	// constructed is the number of clients created, for tests.
+	constructed int
+}
+
+// cachedCredential is a credential and the clients that have been created with it.
+type cachedCredential struct {
+	version    string
+	credential azcore.TokenCredential
+	clients    map[clientKey]any
+}
+
+// clientKey identifies a cached client. Clients bound to a subscription or a data-plane endpoint
+// (e.g. a Key Vault) are cached separately for each.
+type clientKey struct {
+	target     string
+	clientType string
+}
+
+// NewClientFactory creates a ClientFactory.
+func NewClientFactory(o ClientFactoryOptions) *ClientFactory {
+	newCredential := o.Credential
+	if newCredential == nil {
+		// For more info on default auth, see:
+		// https://learn.microsoft.com/en-us/azure/developer/go/azure-sdk-authentication
+		newCredential = func() (azcore.TokenCredential, error) {
+			return azidentity.NewDefaultAzureCredential(nil)
+		}
+	}
+
+	armEndpoint := o.Cloud.Services[cloud.ResourceManager].Endpoint
+	if armEndpoint == "" {
+		armEndpoint = cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	}
+	regions := newRegionalEndpoints(clock.RealClock{}, armEndpoint)
+	opts := &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud:            o.Cloud,
+			Retry:            o.Retry,
+			Transport:        o.Transport,
+			PerRetryPolicies: []policy.Policy{regionalEndpointPolicy{endpoints: regions}, correlationIDPolicy{}, apiVersionPolicy{}},
+		},
+	}
+	// Minimize retries/timeouts for tests
+	if os.Getenv("IS_TEST") == "true" && o.Transport == nil {
+		httpClient := http.DefaultClient
+		httpClient.Timeout = TestClientTimeout
+
+		opts.ClientOptions.Retry.MaxRetries = -1
+		opts.ClientOptions.Retry.TryTimeout = TestClientTimeout
+		opts.ClientOptions.Transport = policy.Transporter(httpClient)
+	}
+	if tracing.Enabled() {
+		opts.ClientOptions.PerRetryPolicies = append(opts.ClientOptions.PerRetryPolicies, tracingPolicy{})
+	}
+
+	return &ClientFactory{
+		newCredential: newCredential,
+		opts:          opts,
+		regions:       regions,
+		credentials:   map[string]*cachedCredential{},
+	}
+}
+
+// API returns an AzureAPI that creates clients with the credential identified by identity (e.g.
+// the secret the credential is configured from). The credential is created if this is the first
+// call for identity, or if version differs from the version of the cached credential (e.g. because
+// the secret changed), in which case the clients created with the previous credential are dropped.
+//
+// A DefaultAzureCredential reads its configuration from environment variables when it's created,
+// so they must be set while API is called.
+func (f *ClientFactory) API(identity, version string) (*AzureAPI, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, ok := f.credentials[identity]; ok && c.version == version {
+		return &AzureAPI{factory: f, credential: c}, nil
+	}
+	cred, err := f.newCredential()
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare Azure credential: %w", err)
+	}
+	c := &cachedCredential{
+		version:    version,
+		credential: cred,
+		clients:    map[clientKey]any{},
+	}
+	f.credentials[identity] = c
+	return &AzureAPI{factory: f, credential: c}, nil
+}
+
+// AzureAPI provides the Azure service clients used to evaluate rules, all using the same
+// credential. Clients are created on first use and cached by the ClientFactory that returned the
+// AzureAPI.
+type AzureAPI struct {
+	factory    *ClientFactory
+	credential *cachedCredential
+}
+
+// DenyAssignments returns a deny assignments client.
+func (a *AzureAPI) DenyAssignments() (*armauthorization.DenyAssignmentsClient, error) {
+	// The subscription ID parameter for deny assignment and role assignment clients isn't relevant
+	// because the plugin only uses methods where scope is specified for each query. Therefore, an
+	// empty string is used for the param.
+	return getClient(a, "", clientTypeDenyAssignments, armauthorization.NewDenyAssignmentsClient)
+}
+
+// RoleAssignments returns a role assignments client.
+func (a *AzureAPI) RoleAssignments() (*armauthorization.RoleAssignmentsClient, error) {
+	return getClient(a, "", clientTypeRoleAssignments, armauthorization.NewRoleAssignmentsClient)
+}
+
+// RoleDefinitions returns a role definitions client.
+func (a *AzureAPI) RoleDefinitions() (*armauthorization.RoleDefinitionsClient, error) {
+	return getClient(a, "", clientTypeRoleDefinitions, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*armauthorization.RoleDefinitionsClient, error) {
+		return armauthorization.NewRoleDefinitionsClient(cred, opts)
+	})
+}
+
+// Permissions returns a client for the effective permissions of the credential's identity in a
+// subscription.
+func (a *AzureAPI) Permissions(subscriptionID string) (*armauthorization.PermissionsClient, error) {
+	return getClient(a, subscriptionID, clientTypePermissions, armauthorization.NewPermissionsClient)
+}
+
+// ManagementGroups returns a management groups client.
+func (a *AzureAPI) ManagementGroups() (*armmanagementgroups.Client, error) {
+	return getClient(a, "", clientTypeMgmtGroups, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*armmanagementgroups.Client, error) {
+		return armmanagementgroups.NewClient(cred, opts)
+	})
+}
+
+// ResourceGroups returns a resource groups client for a subscription.
+func (a *AzureAPI) ResourceGroups(subscriptionID string) (*armresources.ResourceGroupsClient, error) {
+	return getClient(a, subscriptionID, clientTypeResourceGroups, armresources.NewResourceGroupsClient)
+}
+
+// Resources returns a generic resources client for a subscription.
+func (a *AzureAPI) Resources(subscriptionID string) (*armresources.Client, error) {
+	return getClient(a, subscriptionID, clientTypeResources, armresources.NewClient)
+}
+
+// Certificates returns a certificates client for the Key Vault at vaultURI.
+func (a *AzureAPI) Certificates(vaultURI string) (*azcertificates.Client, error) {
+	return getClient(a, vaultURI, clientTypeCertificates, func(vaultURI string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*azcertificates.Client, error) {
+		return azcertificates.NewClient(vaultURI, cred, &azcertificates.ClientOptions{ClientOptions: opts.ClientOptions})
+	})
+}
+
+// Graph returns a Microsoft Graph client for the factory's cloud.
+func (a *AzureAPI) Graph() (*GraphClient, error) {
+	return getClient(a, "", clientTypeGraph, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*GraphClient, error) {
+		return NewGraphClient(cred, &opts.ClientOptions)
+	})
+}
+
+// Blobs returns a client that uploads blobs to Azure Storage.
+func (a *AzureAPI) Blobs() (*BlobClient, error) {
+	return getClient(a, "", clientTypeBlobs, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*BlobClient, error) {
+		return NewBlobClient(cred, &opts.ClientOptions), nil
+	})
+}
+
+// ManagedClusters returns an AKS managed clusters client for a subscription.
+func (a *AzureAPI) ManagedClusters(subscriptionID string) (*armcontainerservice.ManagedClustersClient, error) {
+	return getClient(a, subscriptionID, clientTypeManagedClusters, armcontainerservice.NewManagedClustersClient)
+}
+
+// Subnets returns a subnets client for a subscription.
+func (a *AzureAPI) Subnets(subscriptionID string) (*armnetwork.SubnetsClient, error) {
+	return getClient(a, subscriptionID, clientTypeSubnets, armnetwork.NewSubnetsClient)
+}
+
+// NatGateways returns a NAT gateways client for a subscription.
+func (a *AzureAPI) NatGateways(subscriptionID string) (*armnetwork.NatGatewaysClient, error) {
+	return getClient(a, subscriptionID, clientTypeNatGateways, armnetwork.NewNatGatewaysClient)
+}
+
+// VirtualNetworkPeerings returns a virtual network peerings client for a subscription.
+func (a *AzureAPI) VirtualNetworkPeerings(subscriptionID string) (*armnetwork.VirtualNetworkPeeringsClient, error) {
+	return getClient(a, subscriptionID, clientTypeVNetPeerings, armnetwork.NewVirtualNetworkPeeringsClient)
+}
+
+// RouteTables returns a route tables client for a subscription.
+func (a *AzureAPI) RouteTables(subscriptionID string) (*armnetwork.RouteTablesClient, error) {
+	return getClient(a, subscriptionID, clientTypeRouteTables, armnetwork.NewRouteTablesClient)
+}
+
+// FirewallPolicyRuleCollectionGroups returns a firewall policy rule collection groups client for
+// a subscription.
+func (a *AzureAPI) FirewallPolicyRuleCollectionGroups(subscriptionID string) (*armnetwork.FirewallPolicyRuleCollectionGroupsClient, error) {
+	return getClient(a, subscriptionID, clientTypeFirewallPolicies, armnetwork.NewFirewallPolicyRuleCollectionGroupsClient)
+}
+
+// BastionHosts returns a Bastion hosts client for a subscription.
+func (a *AzureAPI) BastionHosts(subscriptionID string) (*armnetwork.BastionHostsClient, error) {
+	return getClient(a, subscriptionID, clientTypeBastionHosts, armnetwork.NewBastionHostsClient)
+}
+
+// VirtualNetworks returns a virtual networks client for a subscription.
+func (a *AzureAPI) VirtualNetworks(subscriptionID string) (*armnetwork.VirtualNetworksClient, error) {
+	return getClient(a, subscriptionID, clientTypeVirtualNetworks, armnetwork.NewVirtualNetworksClient)
+}
+
+// PublicIPPrefixes returns a public IP prefixes client for a subscription.
+func (a *AzureAPI) PublicIPPrefixes(subscriptionID string) (*armnetwork.PublicIPPrefixesClient, error) {
+	return getClient(a, subscriptionID, clientTypePublicIPPrefixes, armnetwork.NewPublicIPPrefixesClient)
+}
+
+// ApplicationGateways returns an application gateways client for a subscription.
+func (a *AzureAPI) ApplicationGateways(subscriptionID string) (*armnetwork.ApplicationGatewaysClient, error) {
+	return getClient(a, subscriptionID, clientTypeAppGateways, armnetwork.NewApplicationGatewaysClient)
+}
+
+// WebApplicationFirewallPolicies returns a WAF policies client for a subscription.
+func (a *AzureAPI) WebApplicationFirewallPolicies(subscriptionID string) (*armnetwork.WebApplicationFirewallPoliciesClient, error) {
+	return getClient(a, subscriptionID, clientTypeWAFPolicies, armnetwork.NewWebApplicationFirewallPoliciesClient)
+}
+
+// ExpressRouteCircuits returns an ExpressRoute circuits client for a subscription.
+func (a *AzureAPI) ExpressRouteCircuits(subscriptionID string) (*armnetwork.ExpressRouteCircuitsClient, error) {
+	return getClient(a, subscriptionID, clientTypeERCircuits, armnetwork.NewExpressRouteCircuitsClient)
+}
+
+// VirtualNetworkGatewayConnections returns a virtual network gateway connections client for a
+// subscription.
+func (a *AzureAPI) VirtualNetworkGatewayConnections(subscriptionID string) (*armnetwork.VirtualNetworkGatewayConnectionsClient, error) {
+	return getClient(a, subscriptionID, clientTypeGWConnections, armnetwork.NewVirtualNetworkGatewayConnectionsClient)
+}
+
+// VirtualNetworkGateways returns a virtual network gateways client for a subscription.
+func (a *AzureAPI) VirtualNetworkGateways(subscriptionID string) (*armnetwork.VirtualNetworkGatewaysClient, error) {
+	return getClient(a, subscriptionID, clientTypeVNetGateways, armnetwork.NewVirtualNetworkGatewaysClient)
+}
+
+// NetworkWatchers returns a network watchers client for a subscription.
+func (a *AzureAPI) NetworkWatchers(subscriptionID string) (*armnetwork.WatchersClient, error) {
+	return getClient(a, subscriptionID, clientTypeWatchers, armnetwork.NewWatchersClient)
+}
+
+// FlowLogs returns a flow logs client for a subscription.
+func (a *AzureAPI) FlowLogs(subscriptionID string) (*armnetwork.FlowLogsClient, error) {
+	return getClient(a, subscriptionID, clientTypeFlowLogs, armnetwork.NewFlowLogsClient)
+}
+
+// DatabaseAccounts returns a Cosmos DB database accounts client for a subscription.
+func (a *AzureAPI) DatabaseAccounts(subscriptionID string) (*armcosmos.DatabaseAccountsClient, error) {
+	return getClient(a, subscriptionID, clientTypeCosmosAccounts, armcosmos.NewDatabaseAccountsClient)
+}
+
+// SQLServers returns an Azure SQL servers client for a subscription.
+func (a *AzureAPI) SQLServers(subscriptionID string) (*armsql.ServersClient, error) {
+	return getClient(a, subscriptionID, clientTypeSQLServers, armsql.NewServersClient)
+}
+
+// SQLServerAzureADAdministrators returns an Azure SQL server Azure AD administrators client for a
+// subscription.
+func (a *AzureAPI) SQLServerAzureADAdministrators(subscriptionID string) (*armsql.ServerAzureADAdministratorsClient, error) {
+	return getClient(a, subscriptionID, clientTypeSQLAdmins, armsql.NewServerAzureADAdministratorsClient)
+}
+
+// TrafficManagerProfiles returns a Traffic Manager profiles client for a subscription.
+func (a *AzureAPI) TrafficManagerProfiles(subscriptionID string) (*armtrafficmanager.ProfilesClient, error) {
+	return getClient(a, subscriptionID, clientTypeTMProfiles, armtrafficmanager.NewProfilesClient)
+}
+
+// StorageAccounts returns an Azure Storage accounts client for a subscription.
+func (a *AzureAPI) StorageAccounts(subscriptionID string) (*armstorage.AccountsClient, error) {
+	return getClient(a, subscriptionID, clientTypeStorageAccounts, armstorage.NewAccountsClient)
+}
+
+// StorageSKUs returns an Azure Storage SKUs client for a subscription.
+func (a *AzureAPI) StorageSKUs(subscriptionID string) (*armstorage.SKUsClient, error) {
+	return getClient(a, subscriptionID, clientTypeStorageSKUs, armstorage.NewSKUsClient)
+}
+
+// BlobContainers returns an Azure Storage blob containers client for a subscription.
+func (a *AzureAPI) BlobContainers(subscriptionID string) (*armstorage.BlobContainersClient, error) {
+	return getClient(a, subscriptionID, clientTypeBlobContainers, armstorage.NewBlobContainersClient)
+}
+
+// FileShares returns an Azure Files shares client for a subscription.
+func (a *AzureAPI) FileShares(subscriptionID string) (*armstorage.FileSharesClient, error) {
+	return getClient(a, subscriptionID, clientTypeFileShares, armstorage.NewFileSharesClient)
+}
+
+// ManagementPolicies returns an Azure Storage lifecycle management policies client for a
+// subscription.
+func (a *AzureAPI) ManagementPolicies(subscriptionID string) (*armstorage.ManagementPoliciesClient, error) {
+	return getClient(a, subscriptionID, clientTypeMgmtPolicies, armstorage.NewManagementPoliciesClient)
+}
+
+// EventHubNamespaces returns an Event Hubs namespaces client for a subscription.
+func (a *AzureAPI) EventHubNamespaces(subscriptionID string) (*armeventhub.NamespacesClient, error) {
+	return getClient(a, subscriptionID, clientTypeEHNamespaces, armeventhub.NewNamespacesClient)
+}
+
+// EventHubs returns an event hubs client for a subscription.
+func (a *AzureAPI) EventHubs(subscriptionID string) (*armeventhub.EventHubsClient, error) {
+	return getClient(a, subscriptionID, clientTypeEventHubs, armeventhub.NewEventHubsClient)
+}
+
+// GalleryImages returns a compute gallery image definitions client for a subscription.
+func (a *AzureAPI) GalleryImages(subscriptionID string) (*armcompute.GalleryImagesClient, error) {
+	return getClient(a, subscriptionID, clientTypeGalleryImages, armcompute.NewGalleryImagesClient)
+}
+
+// GalleryImageVersions returns a compute gallery image versions client for a subscription.
+func (a *AzureAPI) GalleryImageVersions(subscriptionID string) (*armcompute.GalleryImageVersionsClient, error) {
+	return getClient(a, subscriptionID, clientTypeImageVersions, armcompute.NewGalleryImageVersionsClient)
+}
+
+// ResourceSKUs returns a resource SKUs client for a subscription.
+func (a *AzureAPI) ResourceSKUs(subscriptionID string) (*armcompute.ResourceSKUsClient, error) {
+	return getClient(a, subscriptionID, clientTypeResourceSKUs, armcompute.NewResourceSKUsClient)
+}
+
+// VirtualMachineImages returns a platform VM images client for a subscription.
+func (a *AzureAPI) VirtualMachineImages(subscriptionID string) (*armcompute.VirtualMachineImagesClient, error) {
+	return getClient(a, subscriptionID, clientTypeVMImages, armcompute.NewVirtualMachineImagesClient)
+}
+
+// ProximityPlacementGroups returns a proximity placement groups client for a subscription.
+func (a *AzureAPI) ProximityPlacementGroups(subscriptionID string) (*armcompute.ProximityPlacementGroupsClient, error) {
+	return getClient(a, subscriptionID, clientTypePPGs, armcompute.NewProximityPlacementGroupsClient)
+}
+
+// Features returns a preview features client for a subscription.
+func (a *AzureAPI) Features(subscriptionID string) (*armfeatures.Client, error) {
+	return getClient(a, subscriptionID, clientTypeFeatures, armfeatures.NewClient)
+}
+
+// PolicyExemptions returns a policy exemptions client for a subscription.
+func (a *AzureAPI) PolicyExemptions(subscriptionID string) (*azpolicy.ExemptionsClient, error) {
+	return getClient(a, subscriptionID, clientTypePolicyExemptions, azpolicy.NewExemptionsClient)
+}
+
+// ManagementLocks returns a management locks client for a subscription.
+func (a *AzureAPI) ManagementLocks(subscriptionID string) (*armlocks.ManagementLocksClient, error) {
+	return getClient(a, subscriptionID, clientTypeManagementLocks, armlocks.NewManagementLocksClient)
+}
+
+// Pricings returns a Defender for Cloud pricings client.
+func (a *AzureAPI) Pricings() (*armsecurity.PricingsClient, error) {
+	// Like the role definitions client, the pricings client takes the scope for each query.
+	return getClient(a, "", clientTypePricings, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*armsecurity.PricingsClient, error) {
+		return armsecurity.NewPricingsClient(cred, opts)
+	})
+}
+
+// Budgets returns a Cost Management budgets client.
+func (a *AzureAPI) Budgets() (*armconsumption.BudgetsClient, error) {
+	return getClient(a, "", clientTypeBudgets, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*armconsumption.BudgetsClient, error) {
+		return armconsumption.NewBudgetsClient(cred, opts)
+	})
+}
+
+// ActivityLogs returns an Activity Log client for a subscription.
+func (a *AzureAPI) ActivityLogs(subscriptionID string) (*armmonitor.ActivityLogsClient, error) {
+	return getClient(a, subscriptionID, clientTypeActivityLogs, armmonitor.NewActivityLogsClient)
+}
+
+// ActionGroups returns an Azure Monitor action groups client for a subscription.
+func (a *AzureAPI) ActionGroups(subscriptionID string) (*armmonitor.ActionGroupsClient, error) {
+	return getClient(a, subscriptionID, clientTypeActionGroups, armmonitor.NewActionGroupsClient)
+}
+
+// MetricAlerts returns an Azure Monitor metric alerts client for a subscription.
+func (a *AzureAPI) MetricAlerts(subscriptionID string) (*armmonitor.MetricAlertsClient, error) {
+	return getClient(a, subscriptionID, clientTypeMetricAlerts, armmonitor.NewMetricAlertsClient)
+}
+
+// DiagnosticSettings returns an Azure Monitor diagnostic settings client.
+func (a *AzureAPI) DiagnosticSettings() (*armmonitor.DiagnosticSettingsClient, error) {
+	// Like the budgets client, the diagnostic settings client takes the scope for each query.
+	return getClient(a, "", clientTypeDiagSettings, func(_ string, cred azcore.TokenCredential, opts *armpolicy.ClientOptions) (*armmonitor.DiagnosticSettingsClient, error) {
+		return armmonitor.NewDiagnosticSettingsClient(cred, opts)
+	})
+}
+
+// getClient returns the cached client of a type for a target (a subscription ID or an endpoint),
+// creating it with newClient if it hasn't been created yet.
+func getClient[T any](a *AzureAPI, target, clientType string, newClient func(string, azcore.TokenCredential, *armpolicy.ClientOptions) (T, error)) (T, error) {
+	f := a.factory
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := clientKey{target: target, clientType: clientType}
+	if client, ok := a.credential.clients[key]; ok {
+		return client.(T), nil
+	}
+	opts := *f.opts
+	opts.PerRetryPolicies = append(slices.Clip(f.opts.PerRetryPolicies), requestCountPolicy{clientType: clientType}, snapshotPolicy{})
+	client, err := newClient(target, a.credential.credential, &opts)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to create Azure %s client: %w", clientType, err)
+	}
+	a.credential.clients[key] = client
+	f.constructed++
+	return client, nil
+}
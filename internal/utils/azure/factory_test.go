@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func Test_ClientFactory(t *testing.T) {
+	credentials := 0
+	f := NewClientFactory(ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) {
+			credentials++
+			return &azfake.TokenCredential{}, nil
+		},
+		Transport: &armOutage{requests: map[string]int{}},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+
+	api, err := f.API("secret/validator/creds", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials != 1 || f.constructed != 0 {
+		t.Fatalf("created %d credential(s) and %d client(s) before any client was used, want 1 and 0", credentials, f.constructed)
+	}
+
+	// Clients are created on first use, and shared by all AzureAPIs for the same credential.
+	ra, err := api.RoleAssignments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, err := f.API("secret/validator/creds", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra2, err := same.RoleAssignments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra != ra2 {
+		t.Error("expected the role assignments client to be reused")
+	}
+	if _, err := same.RoleDefinitions(); err != nil {
+		t.Fatal(err)
+	}
+	if credentials != 1 || f.constructed != 2 {
+		t.Errorf("created %d credential(s) and %d client(s), want 1 and 2", credentials, f.constructed)
+	}
+
+	// Other credentials get their own clients.
+	other, err := f.API("implicit", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra3, err := other.RoleAssignments(); err != nil {
+		t.Fatal(err)
+	} else if ra3 == ra {
+		t.Error("expected credentials not to share clients")
+	}
+
+	// A new version of a credential replaces it and its clients.
+	changed, err := f.API("secret/validator/creds", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra4, err := changed.RoleAssignments(); err != nil {
+		t.Fatal(err)
+	} else if ra4 == ra {
+		t.Error("expected the clients of a replaced credential to be dropped")
+	}
+	if credentials != 3 || f.constructed != 4 {
+		t.Errorf("created %d credential(s) and %d client(s), want 3 and 4", credentials, f.constructed)
+	}
+}
+
+func Test_ClientFactoryCredentialError(t *testing.T) {
+	calls := 0
+	f := NewClientFactory(ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) {
+			calls++
+			return nil, errors.New("no credential configured")
+		},
+	})
+
+	// Failures aren't cached, so that a credential can be created once its configuration is fixed.
+	for i := 0; i < 2; i++ {
+		if _, err := f.API("implicit", ""); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("tried to create the credential %d time(s), want 2", calls)
+	}
+}
+
+func Test_ClientFactoryCertificates(t *testing.T) {
+	f := NewClientFactory(ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) {
+			return &azfake.TokenCredential{}, nil
+		},
+	})
+	api, err := f.API("implicit", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Certificates clients are bound to a vault, so each vault gets its own.
+	vault1, err := api.Certificates("https://vault1.vault.azure.net/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault2, err := api.Certificates("https://vault2.vault.azure.net/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := api.Certificates("https://vault1.vault.azure.net/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vault1 == vault2 || vault1 != again {
+		t.Error("expected one certificates client per vault")
+	}
+	if f.constructed != 2 {
+		t.Errorf("created %d client(s), want 2", f.constructed)
+	}
+}
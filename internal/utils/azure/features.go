@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
+)
+
+// AzureFeaturesClient is a facade over the Azure preview features client for a single
+// subscription. Exists to make our code easier to test.
+type AzureFeaturesClient struct {
+	ctx            context.Context
+	client         *armfeatures.Client
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureFeaturesClient creates a new AzureFeaturesClient (our facade client) from a client from
+// the Azure SDK for the subscription with ID subscriptionID.
+func NewAzureFeaturesClient(ctx context.Context, azClient *armfeatures.Client, subscriptionID string) *AzureFeaturesClient {
+	return &AzureFeaturesClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureFeaturesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureFeaturesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetFeature gets a preview feature of a resource provider (e.g. Microsoft.Compute), with its
+// registration state in the subscription.
+func (c *AzureFeaturesClient) GetFeature(namespace, name string) (_ *armfeatures.FeatureResult, err error) {
+	scope := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Features/providers/%s/features/%s", c.subscriptionID, namespace, name)
+	ctx, span := startScopeSpan(c.ctx, "Features.Get", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Get(ctx, namespace, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature %s/%s of subscription %s: %w", namespace, name, c.subscriptionID, rec.withCorrelationID(err))
+	}
+	return &resp.FeatureResult, nil
+}
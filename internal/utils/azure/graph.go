@@ -0,0 +1,214 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// GraphService is the service name of Microsoft Graph in a cloud configuration. The Graph
+// endpoints of the Azure public, US government, and China clouds are known, so only custom clouds
+// need to configure it.
+const GraphService cloud.ServiceName = "microsoftGraph"
+
+// graphRequestIDHeader is the header Microsoft Graph uses to return the ID of a request, which
+// Microsoft support can use to find the request in Graph's logs. Graph doesn't return correlation
+// request IDs.
+const graphRequestIDHeader = "request-id"
+
+// graphEndpoints are the Microsoft Graph endpoints of the well-known clouds, keyed by their
+// Microsoft Entra ID authority hosts.
+var graphEndpoints = map[string]string{
+	cloud.AzurePublic.ActiveDirectoryAuthorityHost:     "https://graph.microsoft.com",
+	cloud.AzureGovernment.ActiveDirectoryAuthorityHost: "https://graph.microsoft.us",
+	cloud.AzureChina.ActiveDirectoryAuthorityHost:      "https://microsoftgraph.chinacloudapi.cn",
+}
+
+// graphEndpoint returns the Microsoft Graph endpoint and token audience of a cloud. The zero
+// configuration is the Azure public cloud.
+func graphEndpoint(cfg cloud.Configuration) (endpoint, audience string, err error) {
+	if svc, ok := cfg.Services[GraphService]; ok && svc.Endpoint != "" {
+		audience = svc.Audience
+		if audience == "" {
+			audience = svc.Endpoint
+		}
+		return strings.TrimSuffix(svc.Endpoint, "/"), audience, nil
+	}
+	host := cfg.ActiveDirectoryAuthorityHost
+	if host == "" {
+		host = cloud.AzurePublic.ActiveDirectoryAuthorityHost
+	}
+	endpoint, ok := graphEndpoints[host]
+	if !ok {
+		return "", "", fmt.Errorf("the Microsoft Graph endpoint of the cloud with authority host %s is unknown; configure service %s", host, GraphService)
+	}
+	return endpoint, endpoint, nil
+}
+
+// GraphClient sends requests to the v1.0 Microsoft Graph API. There's no Azure SDK for Go module
+// for Graph that's built on azcore, so requests go through an azcore pipeline of our own, with the
+// same options as the SDK's clients.
+type GraphClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// GraphGroup is a Microsoft Graph group, with the properties that rules check.
+type GraphGroup struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// GraphDirectoryObject is a Microsoft Graph directory object, e.g. a user, group, or service
+// principal, with the properties that rules check.
+type GraphDirectoryObject struct {
+	ID string `json:"id"`
+	// The kind of object, e.g. #microsoft.graph.servicePrincipal.
+	ODataType   string `json:"@odata.type"`
+	DisplayName string `json:"displayName"`
+}
+
+// NewGraphClient creates a GraphClient that authenticates with cred, for the cloud in options.
+func NewGraphClient(cred azcore.TokenCredential, options *policy.ClientOptions) (*GraphClient, error) {
+	if options == nil {
+		options = &policy.ClientOptions{}
+	}
+	endpoint, audience, err := graphEndpoint(options.Cloud)
+	if err != nil {
+		return nil, err
+	}
+	auth := runtime.NewBearerTokenPolicy(cred, []string{strings.TrimSuffix(audience, "/") + "/.default"}, nil)
+	return &GraphClient{
+		endpoint: endpoint,
+		pipeline: runtime.NewPipeline("graph", "v1.0", runtime.PipelineOptions{PerRetry: []policy.Policy{auth}}, options),
+	}, nil
+}
+
+// get sends a GET request for a path of the v1.0 API, or for an absolute URL (e.g. a next link),
+// and unmarshals the response into v.
+func (c *GraphClient) get(ctx context.Context, pathOrURL string, query url.Values, v any) error {
+	u := pathOrURL
+	if !strings.HasPrefix(u, "https://") {
+		u = c.endpoint + "/v1.0" + pathOrURL
+		if len(query) > 0 {
+			u += "?" + query.Encode()
+		}
+	}
+	req, err := runtime.NewRequest(ctx, http.MethodGet, u)
+	if err != nil {
+		return err
+	}
+	req.Raw().Header.Set("Accept", "application/json")
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, v)
+}
+
+// AzureGroupsClient is a facade over Microsoft Graph's groups API. Exists to make our code easier
+// to test.
+type AzureGroupsClient struct {
+	ctx            context.Context
+	client         *GraphClient
+	correlationIDs correlationIDLog
+}
+
+// NewAzureGroupsClient creates a new AzureGroupsClient (our facade client) from a Graph client.
+func NewAzureGroupsClient(ctx context.Context, client *GraphClient) *AzureGroupsClient {
+	return &AzureGroupsClient{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+// CorrelationIDs returns the request IDs of all Graph responses the client has received.
+func (c *AzureGroupsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// GetGroup gets a group by its object ID.
+func (c *AzureGroupsClient) GetGroup(groupID string) (_ *GraphGroup, err error) {
+	ctx, span := startScopeSpan(c.ctx, "Groups.Get", "/groups/"+groupID)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	var group GraphGroup
+	query := url.Values{"$select": {"id,displayName"}}
+	if err = c.client.get(ctx, "/groups/"+url.PathEscape(groupID), query, &group); err != nil {
+		return nil, fmt.Errorf("failed to get group %s: %w", groupID, rec.withCorrelationID(err))
+	}
+	return &group, nil
+}
+
+// ListGroupsByDisplayName gets the groups with a display name. Display names aren't unique, so
+// there may be several.
+func (c *AzureGroupsClient) ListGroupsByDisplayName(displayName string) (_ []*GraphGroup, err error) {
+	ctx, span := startScopeSpan(c.ctx, "Groups.List", "/groups")
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	// Single quotes in OData string literals are escaped by doubling them.
+	query := url.Values{
+		"$filter": {fmt.Sprintf("displayName eq '%s'", strings.ReplaceAll(displayName, "'", "''"))},
+		"$select": {"id,displayName"},
+	}
+	groups, err := listGraph[GraphGroup](ctx, c.client, "/groups", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups named %s: %w", displayName, rec.withCorrelationID(err))
+	}
+	return groups, nil
+}
+
+// ListMembers gets the members of a group by its object ID. If transitive is true, the members of
+// its nested groups are included too.
+func (c *AzureGroupsClient) ListMembers(groupID string, transitive bool) (_ []*GraphDirectoryObject, err error) {
+	relationship := "members"
+	if transitive {
+		relationship = "transitiveMembers"
+	}
+	path := fmt.Sprintf("/groups/%s/%s", url.PathEscape(groupID), relationship)
+	ctx, span := startScopeSpan(c.ctx, "Groups.ListMembers", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	members, err := listGraph[GraphDirectoryObject](ctx, c.client, path, url.Values{"$select": {"id"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s of group %s: %w", relationship, groupID, rec.withCorrelationID(err))
+	}
+	return members, nil
+}
+
+// listGraph gets all pages of a Graph collection, following each page's next link.
+func listGraph[T any](ctx context.Context, client *GraphClient, path string, query url.Values) ([]*T, error) {
+	var items []*T
+	next := path
+	for next != "" {
+		if err := waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		var page struct {
+			Value    []*T   `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := client.get(ctx, next, query, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Value...)
+		next = page.NextLink
+	}
+	return items, nil
+}
@@ -0,0 +1,144 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func Test_graphEndpoint(t *testing.T) {
+	custom := cloud.Configuration{
+		ActiveDirectoryAuthorityHost: "https://login.example/",
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			GraphService: {Endpoint: "https://graph.example/"},
+		},
+	}
+	tests := []struct {
+		name         string
+		cfg          cloud.Configuration
+		wantEndpoint string
+		wantErr      bool
+	}{
+		{name: "Defaults to the public cloud.", wantEndpoint: "https://graph.microsoft.com"},
+		{name: "Knows the US government cloud.", cfg: cloud.AzureGovernment, wantEndpoint: "https://graph.microsoft.us"},
+		{name: "Knows the China cloud.", cfg: cloud.AzureChina, wantEndpoint: "https://microsoftgraph.chinacloudapi.cn"},
+		{name: "Uses the Graph service of custom clouds.", cfg: custom, wantEndpoint: "https://graph.example"},
+		{name: "Fails for custom clouds without a Graph service.", cfg: cloud.Configuration{ActiveDirectoryAuthorityHost: "https://login.example/"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, _, err := graphEndpoint(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if endpoint != tt.wantEndpoint {
+				t.Errorf("got endpoint %q, want %q", endpoint, tt.wantEndpoint)
+			}
+		})
+	}
+}
+
+// newTestGraphClient returns a Graph client that sends its requests to transport.
+func newTestGraphClient(t *testing.T, transport policy.Transporter) *GraphClient {
+	t.Helper()
+	client, err := NewGraphClient(&azfake.TokenCredential{}, &policy.ClientOptions{
+		Transport:        transport,
+		Retry:            policy.RetryOptions{MaxRetries: -1},
+		PerRetryPolicies: []policy.Policy{correlationIDPolicy{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// graphResponse returns a Graph JSON response with a request ID.
+func graphResponse(req *http.Request, status int, body string) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	page := req.URL.Query().Get("page")
+	if page == "" {
+		page = "1"
+	}
+	header.Set(graphRequestIDHeader, "request-"+page)
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func Test_ListMembers(t *testing.T) {
+	const wantPath = "/v1.0/groups/group-1/transitiveMembers"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"value": [{"id": "member-1", "@odata.type": "#microsoft.graph.servicePrincipal"}], "@odata.nextLink": "https://graph.microsoft.com` + wantPath + `?page=2"}`
+		if req.URL.Query().Get("page") == "2" {
+			body = `{"value": [{"id": "member-2", "@odata.type": "#microsoft.graph.user"}]}`
+		}
+		return graphResponse(req, http.StatusOK, body), nil
+	})
+	c := NewAzureGroupsClient(context.Background(), newTestGraphClient(t, transport))
+
+	members, err := c.ListMembers("group-1", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []*GraphDirectoryObject{
+		{ID: "member-1", ODataType: "#microsoft.graph.servicePrincipal"},
+		{ID: "member-2", ODataType: "#microsoft.graph.user"},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("got members %v, want %v", members, want)
+	}
+	if want := []string{wantPath, wantPath}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+	if want := []string{"request-1", "request-2"}; !reflect.DeepEqual(c.CorrelationIDs(), want) {
+		t.Errorf("got request IDs %v, want %v", c.CorrelationIDs(), want)
+	}
+}
+
+func Test_GetGroupNotFound(t *testing.T) {
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		return graphResponse(req, http.StatusNotFound, `{"error": {"code": "Request_ResourceNotFound", "message": "Resource 'group-1' does not exist."}}`), nil
+	})
+	c := NewAzureGroupsClient(context.Background(), newTestGraphClient(t, transport))
+
+	_, err := c.GetGroup("group-1")
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusNotFound || rerr.ErrorCode != "Request_ResourceNotFound" {
+		t.Errorf("expected a 404 Azure error, got %v", err)
+	}
+}
+
+func Test_ListGroupsByDisplayName(t *testing.T) {
+	var filter string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		filter = req.URL.Query().Get("$filter")
+		return graphResponse(req, http.StatusOK, `{"value": [{"id": "group-1", "displayName": "O'Brien admins"}]}`), nil
+	})
+	c := NewAzureGroupsClient(context.Background(), newTestGraphClient(t, transport))
+
+	groups, err := c.ListGroupsByDisplayName("O'Brien admins")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "displayName eq 'O''Brien admins'"; filter != want {
+		t.Errorf("got filter %q, want %q", filter, want)
+	}
+	if want := []*GraphGroup{{ID: "group-1", DisplayName: "O'Brien admins"}}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("got groups %v, want %v", groups, want)
+	}
+}
@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// PrincipalID returns the object ID of the principal that the AzureAPI's credential authenticates
+// as, from the oid claim of an ARM access token.
+func (a *AzureAPI) PrincipalID(ctx context.Context) (string, error) {
+	cfg := a.factory.opts.Cloud
+	if len(cfg.Services) == 0 {
+		cfg = cloud.AzurePublic
+	}
+	token, err := a.credential.credential.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{cfg.Services[cloud.ResourceManager].Audience + "/.default"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get ARM access token: %w", err)
+	}
+	return principalIDFromToken(token.Token)
+}
+
+// principalIDFromToken returns the oid claim of a JWT access token. The token isn't verified; it
+// was just issued to us.
+func principalIDFromToken(token string) (string, error) {
+	var claims struct {
+		OID string `json:"oid"`
+	}
+	if err := jwtClaims(token, "access token", &claims); err != nil {
+		return "", err
+	}
+	if claims.OID == "" {
+		return "", errors.New("access token has no oid claim")
+	}
+	return claims.OID, nil
+}
+
+// WorkloadIdentity is the Microsoft Entra Workload ID configuration of the plugin's pod, which
+// the workload identity webhook injects as environment variables, and which the implicit
+// credential authenticates with.
+type WorkloadIdentity struct {
+	// ClientID is the client ID of the application or managed identity, from AZURE_CLIENT_ID.
+	ClientID string
+	// TenantID is the tenant of the application or managed identity, from AZURE_TENANT_ID.
+	TenantID string
+	// TokenFile is the path of the projected service account token, from
+	// AZURE_FEDERATED_TOKEN_FILE. Empty if the pod doesn't use workload identity.
+	TokenFile string
+}
+
+// WorkloadIdentityFromEnv returns the plugin's workload identity configuration from its
+// environment. It must be called before the environment variables of any AzureValidator's auth
+// secret are set, as they may override the pod's.
+func WorkloadIdentityFromEnv() WorkloadIdentity {
+	return WorkloadIdentity{
+		ClientID:  os.Getenv("AZURE_CLIENT_ID"),
+		TenantID:  os.Getenv("AZURE_TENANT_ID"),
+		TokenFile: os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+	}
+}
+
+// ServiceAccountTokenClaims are the claims of a Kubernetes service account token that Microsoft
+// Entra ID matches against the federated identity credentials of an application or managed
+// identity when the token is exchanged for an access token.
+type ServiceAccountTokenClaims struct {
+	Issuer  string
+	Subject string
+	// Audiences are the token's audiences, which are a single string or a list of strings in the
+	// token.
+	Audiences []string
+}
+
+// ParseServiceAccountToken returns the issuer, subject, and audience claims of a service account
+// token. The token isn't verified, and isn't part of any error returned, so that it never ends up
+// in logs or conditions.
+func ParseServiceAccountToken(token string) (*ServiceAccountTokenClaims, error) {
+	var claims struct {
+		Issuer   string          `json:"iss"`
+		Subject  string          `json:"sub"`
+		Audience json.RawMessage `json:"aud"`
+	}
+	if err := jwtClaims(strings.TrimSpace(token), "service account token", &claims); err != nil {
+		return nil, err
+	}
+	parsed := &ServiceAccountTokenClaims{Issuer: claims.Issuer, Subject: claims.Subject}
+	if len(claims.Audience) > 0 {
+		var audience string
+		if err := json.Unmarshal(claims.Audience, &audience); err == nil {
+			parsed.Audiences = []string{audience}
+		} else if err := json.Unmarshal(claims.Audience, &parsed.Audiences); err != nil {
+			return nil, errors.New("service account token's aud claim is neither a string nor a list of strings")
+		}
+	}
+	return parsed, nil
+}
+
+// jwtClaims decodes the claims of a JWT, which is described by kind in errors, into v. The token
+// isn't verified.
+func jwtClaims(token, kind string, v any) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%s is not a JWT", kind)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", kind, err)
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("failed to parse %s claims: %w", kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package azure
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// jwt returns an unsigned JWT with claims.
+func jwt(claims string) string {
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func Test_principalIDFromToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Token with oid claim", token: jwt(`{"oid": "p_id", "tid": "t_id"}`), want: "p_id"},
+		{name: "Token without oid claim", token: jwt(`{"tid": "t_id"}`), wantErr: true},
+		{name: "Opaque token", token: "opaque", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := principalIDFromToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got principal ID %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseServiceAccountToken(t *testing.T) {
+	const (
+		issuer  = "https://oidc.prod-aks.azure.com/tenant-1/issuer-1/"
+		subject = "system:serviceaccount:validator:validator-plugin-azure-controller-manager"
+	)
+	tests := []struct {
+		name    string
+		token   string
+		want    *ServiceAccountTokenClaims
+		wantErr bool
+	}{
+		{
+			name:  "Parses a token with a list of audiences, ignoring surrounding whitespace.",
+			token: jwt(`{"iss": "`+issuer+`", "sub": "`+subject+`", "aud": ["api://AzureADTokenExchange"], "exp": 1700000000}`) + "\n",
+			want:  &ServiceAccountTokenClaims{Issuer: issuer, Subject: subject, Audiences: []string{"api://AzureADTokenExchange"}},
+		},
+		{
+			name:  "Parses a token with a single audience.",
+			token: jwt(`{"iss": "` + issuer + `", "sub": "` + subject + `", "aud": "api://AzureADTokenExchange"}`),
+			want:  &ServiceAccountTokenClaims{Issuer: issuer, Subject: subject, Audiences: []string{"api://AzureADTokenExchange"}},
+		},
+		{
+			name:  "Parses a token without an audience.",
+			token: jwt(`{"iss": "` + issuer + `", "sub": "` + subject + `"}`),
+			want:  &ServiceAccountTokenClaims{Issuer: issuer, Subject: subject},
+		},
+		{
+			name:    "Rejects audiences that aren't strings.",
+			token:   jwt(`{"iss": "` + issuer + `", "aud": 42}`),
+			wantErr: true,
+		},
+		{
+			name:    "Rejects opaque tokens.",
+			token:   "opaque-secret",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServiceAccountToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), strings.TrimSpace(tt.token)) {
+				t.Errorf("error %q includes the token", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got claims %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
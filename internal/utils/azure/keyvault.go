@@ -0,0 +1,56 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azcertificates"
+)
+
+// AzureCertificatesClient is a facade over the Azure Key Vault certificates client for a single
+// vault. Exists to make our code easier to test.
+type AzureCertificatesClient struct {
+	ctx            context.Context
+	client         *azcertificates.Client
+	vaultURI       string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureCertificatesClient creates a new AzureCertificatesClient (our facade client) from a
+// client from the Azure SDK for the vault at vaultURI.
+func NewAzureCertificatesClient(ctx context.Context, azClient *azcertificates.Client, vaultURI string) *AzureCertificatesClient {
+	return &AzureCertificatesClient{
+		ctx:      ctx,
+		client:   azClient,
+		vaultURI: vaultURI,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all responses the client has received.
+func (c *AzureCertificatesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureCertificatesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetCertificate gets the latest version of a certificate, including its policy.
+func (c *AzureCertificatesClient) GetCertificate(name string) (_ *azcertificates.Certificate, err error) {
+	ctx, span := startScopeSpan(c.ctx, "Certificates.GetCertificate", fmt.Sprintf("%s/certificates/%s", strings.TrimSuffix(c.vaultURI, "/"), name))
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	// An empty version gets the latest version of the certificate.
+	resp, err := c.client.GetCertificate(ctx, name, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate %s: %w", name, rec.withCorrelationID(err))
+	}
+	return &resp.Certificate, nil
+}
@@ -0,0 +1,109 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+)
+
+// AzureManagementLocksClient is a facade over the Azure management locks client. Exists to make our
+// code easier to test (it handles paging). Scopes are identified by their resource IDs, so the
+// scopes of a rule can be in different subscriptions.
+type AzureManagementLocksClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armlocks.ManagementLocksClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureManagementLocksClient creates a new AzureManagementLocksClient (our facade client) that
+// gets the client from the Azure SDK for each subscription from clients.
+func NewAzureManagementLocksClient(ctx context.Context, clients func(subscriptionID string) (*armlocks.ManagementLocksClient, error)) *AzureManagementLocksClient {
+	return &AzureManagementLocksClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureManagementLocksClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureManagementLocksClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListLocksForScope gets all management locks that apply to a resource group or a resource,
+// including those inherited from containing scopes. Locks of resources within the scope aren't
+// included.
+func (c *AzureManagementLocksClient) ListLocksForScope(scope string) (locks []*armlocks.ManagementLockObject, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ManagementLocks.List", scope)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scope %s: %w", scope, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	// The pagers for resource groups and other scopes have different types, but their pages both
+	// hold a ManagementLockListResult.
+	filter := "atScope()"
+	var more func() bool
+	var nextPage func(context.Context) (armlocks.ManagementLockListResult, error)
+	if id.ResourceType.String() == arm.ResourceGroupResourceType.String() {
+		pager := client.NewListAtResourceGroupLevelPager(id.ResourceGroupName, &armlocks.ManagementLocksClientListAtResourceGroupLevelOptions{Filter: &filter})
+		more = pager.More
+		nextPage = func(ctx context.Context) (armlocks.ManagementLockListResult, error) {
+			page, err := pager.NextPage(ctx)
+			return page.ManagementLockListResult, err
+		}
+	} else {
+		// The SDK adds a slash before the scope.
+		pager := client.NewListByScopePager(strings.TrimPrefix(scope, "/"), &armlocks.ManagementLocksClientListByScopeOptions{Filter: &filter})
+		more = pager.More
+		nextPage = func(ctx context.Context) (armlocks.ManagementLockListResult, error) {
+			page, err := pager.NextPage(ctx)
+			return page.ManagementLockListResult, err
+		}
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for more() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := nextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				locks = append(locks, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return locks, err
+	case <-c.ctx.Done():
+		return locks, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
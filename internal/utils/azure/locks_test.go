@@ -0,0 +1,83 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+)
+
+func Test_ListLocksForScope(t *testing.T) {
+	const sub = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	tests := []struct {
+		name     string
+		scope    string
+		wantPath string
+	}{
+		{
+			name:     "Lists the locks of a resource group, following next links.",
+			scope:    sub + "/resourceGroups/rg",
+			wantPath: sub + "/resourceGroups/rg/providers/Microsoft.Authorization/locks",
+		},
+		{
+			name:     "Lists the locks of a resource, following next links.",
+			scope:    sub + "/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+			wantPath: sub + "/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa/providers/Microsoft.Authorization/locks",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths, filters []string
+			transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+				paths = append(paths, req.URL.Path)
+				filters = append(filters, req.URL.Query().Get("$filter"))
+				body := fmt.Sprintf(`{"value": [{"id": "%s/providers/Microsoft.Authorization/locks/sub-lock", "properties": {"level": "ReadOnly"}}], "nextLink": "https://management.azure.com%s?page=2"}`, sub, tt.wantPath)
+				if req.URL.Query().Get("page") == "2" {
+					body = fmt.Sprintf(`{"value": [{"id": "%s/providers/Microsoft.Authorization/locks/lock", "properties": {"level": "CanNotDelete"}}]}`, tt.scope)
+				}
+				header := http.Header{}
+				header.Set("Content-Type", "application/json")
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Request:    req,
+				}, nil
+			})
+			clients := func(subscriptionID string) (*armlocks.ManagementLocksClient, error) {
+				return armlocks.NewManagementLocksClient(subscriptionID, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+					ClientOptions: policy.ClientOptions{
+						Transport: transport,
+						Retry:     policy.RetryOptions{MaxRetries: -1},
+					},
+				})
+			}
+
+			locks, err := NewAzureManagementLocksClient(context.Background(), clients).ListLocksForScope(tt.scope)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var levels []armlocks.LockLevel
+			for _, l := range locks {
+				levels = append(levels, *l.Properties.Level)
+			}
+			if want := []armlocks.LockLevel{armlocks.LockLevelReadOnly, armlocks.LockLevelCanNotDelete}; !reflect.DeepEqual(levels, want) {
+				t.Errorf("got lock levels %v, want %v", levels, want)
+			}
+			if want := []string{tt.wantPath, tt.wantPath}; !reflect.DeepEqual(paths, want) {
+				t.Errorf("got request paths %v, want %v", paths, want)
+			}
+			if filters[0] != "atScope()" {
+				t.Errorf("got filter %q, want %q", filters[0], "atScope()")
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+)
+
+// ManagementGroupSubscription is a subscription beneath a management group.
+type ManagementGroupSubscription struct {
+	// ID is the subscription's ID, e.g. 00000000-0000-0000-0000-000000000000.
+	ID string
+	// DisplayName is the subscription's display name. Empty if ARM doesn't report it.
+	DisplayName string
+}
+
+// AzureManagementGroupsClient is a facade over the Azure management groups client. Exists to make
+// our code easier to test (it handles paging).
+type AzureManagementGroupsClient struct {
+	ctx            context.Context
+	client         func() (*armmanagementgroups.Client, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureManagementGroupsClient creates a new AzureManagementGroupsClient (our facade client) that
+// gets the client from the Azure SDK from client when it's first needed.
+func NewAzureManagementGroupsClient(ctx context.Context, client func() (*armmanagementgroups.Client, error)) *AzureManagementGroupsClient {
+	return &AzureManagementGroupsClient{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureManagementGroupsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureManagementGroupsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListDescendantSubscriptions gets the subscriptions beneath a management group, including those of
+// its nested management groups, in the order ARM lists them.
+func (c *AzureManagementGroupsClient) ListDescendantSubscriptions(groupID string) (subs []ManagementGroupSubscription, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ManagementGroups.GetDescendants", "/providers/Microsoft.Management/managementGroups/"+groupID)
+	defer func() { endSpan(span, err) }()
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+	pager := client.NewGetDescendantsPager(groupID, nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			for _, d := range nextResult.Value {
+				// Descendants are management groups and subscriptions, whose IDs are
+				// /subscriptions/<id>.
+				if d == nil || d.ID == nil || d.Name == nil || !strings.HasPrefix(strings.ToLower(*d.ID), "/subscriptions/") {
+					continue
+				}
+				sub := ManagementGroupSubscription{ID: *d.Name}
+				if d.Properties != nil && d.Properties.DisplayName != nil {
+					sub.DisplayName = *d.Properties.DisplayName
+				}
+				subs = append(subs, sub)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return subs, err
+	case <-c.ctx.Done():
+		return subs, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
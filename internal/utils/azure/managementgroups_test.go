@@ -0,0 +1,68 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+)
+
+func Test_ListDescendantSubscriptions(t *testing.T) {
+	const wantPath = "/providers/Microsoft.Management/managementGroups/platform/descendants"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		// The platform management group has a subscription and a nested management group, whose
+		// subscriptions are listed on the next page.
+		body := `{"value": [
+			{"id": "/subscriptions/11111111-1111-1111-1111-111111111111", "name": "11111111-1111-1111-1111-111111111111", "type": "/subscriptions",
+			 "properties": {"displayName": "Production", "parent": {"id": "/providers/Microsoft.Management/managementGroups/platform"}}},
+			{"id": "/providers/Microsoft.Management/managementGroups/sandbox", "name": "sandbox", "type": "Microsoft.Management/managementGroups",
+			 "properties": {"displayName": "Sandbox", "parent": {"id": "/providers/Microsoft.Management/managementGroups/platform"}}}
+		], "nextLink": "https://management.azure.com` + wantPath + `?page=2"}`
+		if req.URL.Query().Get("page") == "2" {
+			body = `{"value": [
+				{"id": "/subscriptions/22222222-2222-2222-2222-222222222222", "name": "22222222-2222-2222-2222-222222222222", "type": "/subscriptions",
+				 "properties": {"parent": {"id": "/providers/Microsoft.Management/managementGroups/sandbox"}}}
+			]}`
+		}
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	client := func() (*armmanagementgroups.Client, error) {
+		return armmanagementgroups.NewClient(&azfake.TokenCredential{}, &armpolicy.ClientOptions{
+			ClientOptions: policy.ClientOptions{
+				Transport: transport,
+				Retry:     policy.RetryOptions{MaxRetries: -1},
+			},
+		})
+	}
+
+	subs, err := NewAzureManagementGroupsClient(context.Background(), client).ListDescendantSubscriptions("platform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ManagementGroupSubscription{
+		{ID: "11111111-1111-1111-1111-111111111111", DisplayName: "Production"},
+		{ID: "22222222-2222-2222-2222-222222222222"},
+	}
+	if !reflect.DeepEqual(subs, want) {
+		t.Errorf("got subscriptions %v, want %v", subs, want)
+	}
+	if want := []string{wantPath, wantPath}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+}
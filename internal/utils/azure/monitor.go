@@ -0,0 +1,235 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// activityLogFields are the fields of Activity Log events that ListRoleAssignmentEvents selects.
+// The rest aren't used, and the full events are large.
+const activityLogFields = "eventDataId,eventTimestamp,operationName,properties,resourceId,status"
+
+// AzureActivityLogsClient is a facade over the Azure Activity Log client for a single subscription.
+// Exists to make our code easier to test (it handles paging).
+type AzureActivityLogsClient struct {
+	ctx            context.Context
+	client         *armmonitor.ActivityLogsClient
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureActivityLogsClient creates a new AzureActivityLogsClient (our facade client) from a client
+// from the Azure SDK for the subscription with ID subscriptionID.
+func NewAzureActivityLogsClient(ctx context.Context, azClient *armmonitor.ActivityLogsClient, subscriptionID string) *AzureActivityLogsClient {
+	return &AzureActivityLogsClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureActivityLogsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureActivityLogsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListAuthorizationEvents gets the subscription's Activity Log events of the
+// Microsoft.Authorization resource provider (e.g. role assignment writes and deletes) that
+// occurred between since and until. The Activity Log can only be filtered by resource provider,
+// so callers pick the operations they're interested in.
+func (c *AzureActivityLogsClient) ListAuthorizationEvents(since, until time.Time) (events []*armmonitor.EventData, err error) {
+	scope := "/subscriptions/" + c.subscriptionID
+	ctx, span := startScopeSpan(c.ctx, "ActivityLogs.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	filter := fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceProvider eq 'Microsoft.Authorization'",
+		since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+	selected := activityLogFields
+	pager := c.client.NewListPager(filter, &armmonitor.ActivityLogsClientListOptions{Select: &selected})
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				events = append(events, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return events, err
+	case <-c.ctx.Done():
+		return events, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
+
+// AzureMonitorAlertsClient is a facade over the Azure Monitor action groups and metric alerts
+// clients. Exists to make our code easier to test. Action groups and metric alerts are identified
+// by their resource IDs.
+type AzureMonitorAlertsClient struct {
+	ctx            context.Context
+	actionGroups   func(subscriptionID string) (*armmonitor.ActionGroupsClient, error)
+	metricAlerts   func(subscriptionID string) (*armmonitor.MetricAlertsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureMonitorAlertsClient creates a new AzureMonitorAlertsClient (our facade client) that gets
+// the clients from the Azure SDK for each subscription from actionGroups and metricAlerts.
+func NewAzureMonitorAlertsClient(ctx context.Context, actionGroups func(subscriptionID string) (*armmonitor.ActionGroupsClient, error), metricAlerts func(subscriptionID string) (*armmonitor.MetricAlertsClient, error)) *AzureMonitorAlertsClient {
+	return &AzureMonitorAlertsClient{
+		ctx:          ctx,
+		actionGroups: actionGroups,
+		metricAlerts: metricAlerts,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureMonitorAlertsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureMonitorAlertsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetActionGroup gets an action group, with its receivers, by its resource ID.
+func (c *AzureMonitorAlertsClient) GetActionGroup(actionGroupID string) (_ *armmonitor.ActionGroupResource, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ActionGroups.Get", actionGroupID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(actionGroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse action group ID %s: %w", actionGroupID, err)
+	}
+	client, err := c.actionGroups(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(actionGroupID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(actionGroupID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action group %s: %w", actionGroupID, rec.withCorrelationID(err))
+	}
+	return &resp.ActionGroupResource, nil
+}
+
+// GetMetricAlert gets a metric alert rule by its resource ID.
+func (c *AzureMonitorAlertsClient) GetMetricAlert(alertID string) (_ *armmonitor.MetricAlertResource, err error) {
+	ctx, span := startScopeSpan(c.ctx, "MetricAlerts.Get", alertID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(alertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metric alert ID %s: %w", alertID, err)
+	}
+	client, err := c.metricAlerts(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(alertID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(alertID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metric alert %s: %w", alertID, rec.withCorrelationID(err))
+	}
+	return &resp.MetricAlertResource, nil
+}
+
+// AzureDiagnosticSettingsClient is a facade over the Azure Monitor diagnostic settings client.
+// Exists to make our code easier to test.
+type AzureDiagnosticSettingsClient struct {
+	ctx            context.Context
+	client         *armmonitor.DiagnosticSettingsClient
+	correlationIDs correlationIDLog
+}
+
+// NewAzureDiagnosticSettingsClient creates a new AzureDiagnosticSettingsClient (our facade client)
+// from a client from the Azure SDK.
+func NewAzureDiagnosticSettingsClient(ctx context.Context, azClient *armmonitor.DiagnosticSettingsClient) *AzureDiagnosticSettingsClient {
+	return &AzureDiagnosticSettingsClient{
+		ctx:    ctx,
+		client: azClient,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureDiagnosticSettingsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureDiagnosticSettingsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListSubscriptionDiagnosticSettings gets the diagnostic settings of a subscription, which export
+// its Activity Log.
+func (c *AzureDiagnosticSettingsClient) ListSubscriptionDiagnosticSettings(subscriptionID string) (settings []*armmonitor.DiagnosticSettingsResource, err error) {
+	scope := "/subscriptions/" + subscriptionID
+	ctx, span := startScopeSpan(c.ctx, "DiagnosticSettings.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	// The SDK adds the leading slash of the resource URI itself. Diagnostic settings aren't paged,
+	// so the pager only has one page.
+	pager := c.client.NewListPager(strings.TrimPrefix(scope, "/"), nil)
+	for pager.More() {
+		if err = waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list diagnostic settings of subscription %s: %w", subscriptionID, rec.withCorrelationID(err))
+		}
+		settings = append(settings, page.Value...)
+	}
+	return settings, nil
+}
@@ -0,0 +1,158 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+func Test_ListAuthorizationEvents(t *testing.T) {
+	const (
+		sub      = "00000000-0000-0000-0000-000000000000"
+		wantPath = "/subscriptions/" + sub + "/providers/Microsoft.Insights/eventtypes/management/values"
+	)
+	var paths, filters, selects []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		filters = append(filters, req.URL.Query().Get("$filter"))
+		selects = append(selects, req.URL.Query().Get("$select"))
+		body := `{"value": [{"eventDataId": "e-1"}], "nextLink": "https://management.azure.com` + wantPath + `?page=2"}`
+		if req.URL.Query().Get("page") == "2" {
+			body = `{"value": [{"eventDataId": "e-2"}]}`
+		}
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	azClient, err := armmonitor.NewActivityLogsClient(sub, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events, err := NewAzureActivityLogsClient(context.Background(), azClient, sub).ListAuthorizationEvents(since, since.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, *e.EventDataID)
+	}
+	if want := []string{"e-1", "e-2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got events %v, want %v", ids, want)
+	}
+	if want := []string{wantPath, wantPath}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+	wantFilter := "eventTimestamp ge '2024-01-01T00:00:00Z' and eventTimestamp le '2024-01-01T00:01:00Z' and resourceProvider eq 'Microsoft.Authorization'"
+	if filters[0] != wantFilter {
+		t.Errorf("got filter %q, want %q", filters[0], wantFilter)
+	}
+	if selects[0] != activityLogFields {
+		t.Errorf("got select %q, want %q", selects[0], activityLogFields)
+	}
+}
+
+func Test_GetMonitorAlerts(t *testing.T) {
+	const rgID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Insights"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"location": "Global", "properties": {"enabled": true, "emailReceivers": [{"name": "oncall", "emailAddress": "oncall@example.com"}]}}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	opts := &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+	client := NewAzureMonitorAlertsClient(context.Background(),
+		func(subscriptionID string) (*armmonitor.ActionGroupsClient, error) {
+			return armmonitor.NewActionGroupsClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+		func(subscriptionID string) (*armmonitor.MetricAlertsClient, error) {
+			return armmonitor.NewMetricAlertsClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+	)
+
+	group, err := client.GetActionGroup(rgID + "/actionGroups/oncall")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivers := group.Properties.EmailReceivers; len(receivers) != 1 || *receivers[0].Name != "oncall" {
+		t.Errorf("got email receivers %+v, want the oncall receiver", receivers)
+	}
+	if _, err := client.GetMetricAlert(rgID + "/metricAlerts/node-cpu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{rgID + "/actionGroups/oncall", rgID + "/metricAlerts/node-cpu"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+}
+
+func Test_ListSubscriptionDiagnosticSettings(t *testing.T) {
+	const sub = "00000000-0000-0000-0000-000000000000"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"value": [{"name": "export", "properties": {"logs": [{"category": "Administrative", "enabled": true}]}}]}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	azClient, err := armmonitor.NewDiagnosticSettingsClient(&azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewAzureDiagnosticSettingsClient(context.Background(), azClient)
+
+	settings, err := client.ListSubscriptionDiagnosticSettings(sub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(settings) != 1 || *settings[0].Name != "export" {
+		t.Errorf("got settings %+v, want the export setting", settings)
+	}
+	if want := []string{"/subscriptions/" + sub + "/providers/Microsoft.Insights/diagnosticSettings"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,912 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+)
+
+// AzureSubnetsClient is a facade over the Azure subnets client. Exists to make our code easier to
+// test. Subnets are identified by their resource IDs, so the subnets of a rule can be in different
+// subscriptions.
+type AzureSubnetsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.SubnetsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureSubnetsClient creates a new AzureSubnetsClient (our facade client) that gets the client
+// from the Azure SDK for each subscription from clients.
+func NewAzureSubnetsClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.SubnetsClient, error)) *AzureSubnetsClient {
+	return &AzureSubnetsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureSubnetsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureSubnetsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetSubnet gets a subnet by its resource ID.
+func (c *AzureSubnetsClient) GetSubnet(subnetID string) (_ *armnetwork.Subnet, err error) {
+	ctx, span := startScopeSpan(c.ctx, "Subnets.Get", subnetID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(subnetID)
+	if err != nil || id.Parent == nil {
+		return nil, fmt.Errorf("failed to parse subnet ID %s: %w", subnetID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(subnetID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(subnetID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet %s: %w", subnetID, rec.withCorrelationID(err))
+	}
+	return &resp.Subnet, nil
+}
+
+// AzureNatGatewaysClient is a facade over the Azure NAT gateways client. Exists to make our code
+// easier to test. NAT gateways are identified by their resource IDs, like subnets.
+type AzureNatGatewaysClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.NatGatewaysClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureNatGatewaysClient creates a new AzureNatGatewaysClient (our facade client) that gets the
+// client from the Azure SDK for each subscription from clients.
+func NewAzureNatGatewaysClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.NatGatewaysClient, error)) *AzureNatGatewaysClient {
+	return &AzureNatGatewaysClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureNatGatewaysClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureNatGatewaysClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetNatGateway gets a NAT gateway by its resource ID.
+func (c *AzureNatGatewaysClient) GetNatGateway(natGatewayID string) (_ *armnetwork.NatGateway, err error) {
+	ctx, span := startScopeSpan(c.ctx, "NatGateways.Get", natGatewayID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(natGatewayID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse NAT gateway ID %s: %w", natGatewayID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(natGatewayID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(natGatewayID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAT gateway %s: %w", natGatewayID, rec.withCorrelationID(err))
+	}
+	return &resp.NatGateway, nil
+}
+
+// AzureRouteTablesClient is a facade over the Azure route tables client. Exists to make our code
+// easier to test. Route tables are identified by their resource IDs, like subnets.
+type AzureRouteTablesClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.RouteTablesClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureRouteTablesClient creates a new AzureRouteTablesClient (our facade client) that gets the
+// client from the Azure SDK for each subscription from clients.
+func NewAzureRouteTablesClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.RouteTablesClient, error)) *AzureRouteTablesClient {
+	return &AzureRouteTablesClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureRouteTablesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureRouteTablesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetRouteTable gets a route table, including its routes, by its resource ID.
+func (c *AzureRouteTablesClient) GetRouteTable(routeTableID string) (_ *armnetwork.RouteTable, err error) {
+	ctx, span := startScopeSpan(c.ctx, "RouteTables.Get", routeTableID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(routeTableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse route table ID %s: %w", routeTableID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(routeTableID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(routeTableID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route table %s: %w", routeTableID, rec.withCorrelationID(err))
+	}
+	return &resp.RouteTable, nil
+}
+
+// AzureVirtualNetworkPeeringsClient is a facade over the Azure virtual network peerings client.
+// Exists to make our code easier to test. Virtual networks are identified by their resource IDs,
+// like subnets.
+type AzureVirtualNetworkPeeringsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.VirtualNetworkPeeringsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureVirtualNetworkPeeringsClient creates a new AzureVirtualNetworkPeeringsClient (our facade
+// client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureVirtualNetworkPeeringsClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.VirtualNetworkPeeringsClient, error)) *AzureVirtualNetworkPeeringsClient {
+	return &AzureVirtualNetworkPeeringsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureVirtualNetworkPeeringsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureVirtualNetworkPeeringsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListPeerings gets all the peerings of a virtual network by its resource ID.
+func (c *AzureVirtualNetworkPeeringsClient) ListPeerings(virtualNetworkID string) (peerings []*armnetwork.VirtualNetworkPeering, err error) {
+	ctx, span := startScopeSpan(c.ctx, "VirtualNetworkPeerings.List", virtualNetworkID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(virtualNetworkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse virtual network ID %s: %w", virtualNetworkID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(virtualNetworkID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(virtualNetworkID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := client.NewListPager(id.ResourceGroupName, id.Name, nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				peerings = append(peerings, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return peerings, err
+	case <-c.ctx.Done():
+		return peerings, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
+
+// AzureFirewallPolicyRuleCollectionGroupsClient is a facade over the Azure firewall policy rule
+// collection groups client. Exists to make our code easier to test (it handles paging). Firewall
+// policies are identified by their resource IDs.
+type AzureFirewallPolicyRuleCollectionGroupsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.FirewallPolicyRuleCollectionGroupsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureFirewallPolicyRuleCollectionGroupsClient creates a new
+// AzureFirewallPolicyRuleCollectionGroupsClient (our facade client) that gets the client from the
+// Azure SDK for each subscription from clients.
+func NewAzureFirewallPolicyRuleCollectionGroupsClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.FirewallPolicyRuleCollectionGroupsClient, error)) *AzureFirewallPolicyRuleCollectionGroupsClient {
+	return &AzureFirewallPolicyRuleCollectionGroupsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureFirewallPolicyRuleCollectionGroupsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureFirewallPolicyRuleCollectionGroupsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListRuleCollectionGroups gets all the rule collection groups of a firewall policy, including
+// their rule collections and rules, by the policy's resource ID.
+func (c *AzureFirewallPolicyRuleCollectionGroupsClient) ListRuleCollectionGroups(firewallPolicyID string) (groups []*armnetwork.FirewallPolicyRuleCollectionGroup, err error) {
+	ctx, span := startScopeSpan(c.ctx, "FirewallPolicyRuleCollectionGroups.List", firewallPolicyID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(firewallPolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse firewall policy ID %s: %w", firewallPolicyID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(firewallPolicyID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(firewallPolicyID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := client.NewListPager(id.ResourceGroupName, id.Name, nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				groups = append(groups, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return groups, err
+	case <-c.ctx.Done():
+		return groups, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
+
+// AzureBastionHostsClient is a facade over the Azure Bastion hosts client. Exists to make our code
+// easier to test. Bastion hosts are identified by their resource IDs, like subnets.
+type AzureBastionHostsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.BastionHostsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureBastionHostsClient creates a new AzureBastionHostsClient (our facade client) that gets
+// the client from the Azure SDK for each subscription from clients.
+func NewAzureBastionHostsClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.BastionHostsClient, error)) *AzureBastionHostsClient {
+	return &AzureBastionHostsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureBastionHostsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureBastionHostsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetBastionHost gets a Bastion host by its resource ID.
+func (c *AzureBastionHostsClient) GetBastionHost(bastionHostID string) (_ *armnetwork.BastionHost, err error) {
+	ctx, span := startScopeSpan(c.ctx, "BastionHosts.Get", bastionHostID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(bastionHostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Bastion host ID %s: %w", bastionHostID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(bastionHostID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(bastionHostID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Bastion host %s: %w", bastionHostID, rec.withCorrelationID(err))
+	}
+	return &resp.BastionHost, nil
+}
+
+// AzureVirtualNetworksClient is a facade over the Azure virtual networks client. Exists to make our
+// code easier to test. Virtual networks are identified by their resource IDs, like subnets.
+type AzureVirtualNetworksClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.VirtualNetworksClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureVirtualNetworksClient creates a new AzureVirtualNetworksClient (our facade client) that
+// gets the client from the Azure SDK for each subscription from clients.
+func NewAzureVirtualNetworksClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.VirtualNetworksClient, error)) *AzureVirtualNetworksClient {
+	return &AzureVirtualNetworksClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureVirtualNetworksClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureVirtualNetworksClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetVirtualNetwork gets a virtual network by its resource ID.
+func (c *AzureVirtualNetworksClient) GetVirtualNetwork(virtualNetworkID string) (_ *armnetwork.VirtualNetwork, err error) {
+	ctx, span := startScopeSpan(c.ctx, "VirtualNetworks.Get", virtualNetworkID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(virtualNetworkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse virtual network ID %s: %w", virtualNetworkID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(virtualNetworkID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(virtualNetworkID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual network %s: %w", virtualNetworkID, rec.withCorrelationID(err))
+	}
+	return &resp.VirtualNetwork, nil
+}
+
+// AzurePublicIPPrefixesClient is a facade over the Azure public IP prefixes client. Exists to make
+// our code easier to test. Public IP prefixes are identified by their resource IDs, like subnets.
+type AzurePublicIPPrefixesClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.PublicIPPrefixesClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzurePublicIPPrefixesClient creates a new AzurePublicIPPrefixesClient (our facade client) that
+// gets the client from the Azure SDK for each subscription from clients.
+func NewAzurePublicIPPrefixesClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.PublicIPPrefixesClient, error)) *AzurePublicIPPrefixesClient {
+	return &AzurePublicIPPrefixesClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzurePublicIPPrefixesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzurePublicIPPrefixesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetPublicIPPrefix gets a public IP prefix, including the public IP addresses allocated from it,
+// by its resource ID.
+func (c *AzurePublicIPPrefixesClient) GetPublicIPPrefix(publicIPPrefixID string) (_ *armnetwork.PublicIPPrefix, err error) {
+	ctx, span := startScopeSpan(c.ctx, "PublicIPPrefixes.Get", publicIPPrefixID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(publicIPPrefixID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public IP prefix ID %s: %w", publicIPPrefixID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(publicIPPrefixID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(publicIPPrefixID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public IP prefix %s: %w", publicIPPrefixID, rec.withCorrelationID(err))
+	}
+	return &resp.PublicIPPrefix, nil
+}
+
+// AzureApplicationGatewaysClient is a facade over the Azure application gateways client. Exists to
+// make our code easier to test. Application gateways are identified by their resource IDs, like
+// subnets.
+type AzureApplicationGatewaysClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.ApplicationGatewaysClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureApplicationGatewaysClient creates a new AzureApplicationGatewaysClient (our facade
+// client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureApplicationGatewaysClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.ApplicationGatewaysClient, error)) *AzureApplicationGatewaysClient {
+	return &AzureApplicationGatewaysClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureApplicationGatewaysClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureApplicationGatewaysClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetApplicationGateway gets an application gateway by its resource ID.
+func (c *AzureApplicationGatewaysClient) GetApplicationGateway(applicationGatewayID string) (_ *armnetwork.ApplicationGateway, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ApplicationGateways.Get", applicationGatewayID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(applicationGatewayID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse application gateway ID %s: %w", applicationGatewayID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(applicationGatewayID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(applicationGatewayID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application gateway %s: %w", applicationGatewayID, rec.withCorrelationID(err))
+	}
+	return &resp.ApplicationGateway, nil
+}
+
+// AzureWebApplicationFirewallPoliciesClient is a facade over the Azure WAF policies client. Exists
+// to make our code easier to test. WAF policies are identified by their resource IDs, like subnets.
+type AzureWebApplicationFirewallPoliciesClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.WebApplicationFirewallPoliciesClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureWebApplicationFirewallPoliciesClient creates a new
+// AzureWebApplicationFirewallPoliciesClient (our facade client) that gets the client from the Azure
+// SDK for each subscription from clients.
+func NewAzureWebApplicationFirewallPoliciesClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.WebApplicationFirewallPoliciesClient, error)) *AzureWebApplicationFirewallPoliciesClient {
+	return &AzureWebApplicationFirewallPoliciesClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureWebApplicationFirewallPoliciesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureWebApplicationFirewallPoliciesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetWebApplicationFirewallPolicy gets a WAF policy by its resource ID.
+func (c *AzureWebApplicationFirewallPoliciesClient) GetWebApplicationFirewallPolicy(policyID string) (_ *armnetwork.WebApplicationFirewallPolicy, err error) {
+	ctx, span := startScopeSpan(c.ctx, "WebApplicationFirewallPolicies.Get", policyID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(policyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse WAF policy ID %s: %w", policyID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(policyID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(policyID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get WAF policy %s: %w", policyID, rec.withCorrelationID(err))
+	}
+	return &resp.WebApplicationFirewallPolicy, nil
+}
+
+// AzureExpressRouteCircuitsClient is a facade over the Azure ExpressRoute circuits client. Exists
+// to make our code easier to test. Circuits are identified by their resource IDs, like subnets.
+type AzureExpressRouteCircuitsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.ExpressRouteCircuitsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureExpressRouteCircuitsClient creates a new AzureExpressRouteCircuitsClient (our facade
+// client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureExpressRouteCircuitsClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.ExpressRouteCircuitsClient, error)) *AzureExpressRouteCircuitsClient {
+	return &AzureExpressRouteCircuitsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureExpressRouteCircuitsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureExpressRouteCircuitsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetExpressRouteCircuit gets an ExpressRoute circuit by its resource ID.
+func (c *AzureExpressRouteCircuitsClient) GetExpressRouteCircuit(circuitID string) (_ *armnetwork.ExpressRouteCircuit, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ExpressRouteCircuits.Get", circuitID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(circuitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ExpressRoute circuit ID %s: %w", circuitID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(circuitID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(circuitID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ExpressRoute circuit %s: %w", circuitID, rec.withCorrelationID(err))
+	}
+	return &resp.ExpressRouteCircuit, nil
+}
+
+// AzureVirtualNetworkGatewayConnectionsClient is a facade over the Azure virtual network gateway
+// connections client. Exists to make our code easier to test. Connections are identified by their
+// resource IDs, like subnets.
+type AzureVirtualNetworkGatewayConnectionsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.VirtualNetworkGatewayConnectionsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureVirtualNetworkGatewayConnectionsClient creates a new
+// AzureVirtualNetworkGatewayConnectionsClient (our facade client) that gets the client from the
+// Azure SDK for each subscription from clients.
+func NewAzureVirtualNetworkGatewayConnectionsClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.VirtualNetworkGatewayConnectionsClient, error)) *AzureVirtualNetworkGatewayConnectionsClient {
+	return &AzureVirtualNetworkGatewayConnectionsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureVirtualNetworkGatewayConnectionsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureVirtualNetworkGatewayConnectionsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetVirtualNetworkGatewayConnection gets a virtual network gateway connection by its resource ID.
+func (c *AzureVirtualNetworkGatewayConnectionsClient) GetVirtualNetworkGatewayConnection(connectionID string) (_ *armnetwork.VirtualNetworkGatewayConnection, err error) {
+	ctx, span := startScopeSpan(c.ctx, "VirtualNetworkGatewayConnections.Get", connectionID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse virtual network gateway connection ID %s: %w", connectionID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(connectionID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(connectionID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual network gateway connection %s: %w", connectionID, rec.withCorrelationID(err))
+	}
+	return &resp.VirtualNetworkGatewayConnection, nil
+}
+
+// AzureVirtualNetworkGatewaysClient is a facade over the Azure virtual network gateways client.
+// Exists to make our code easier to test. Gateways are identified by their resource IDs, like
+// subnets.
+type AzureVirtualNetworkGatewaysClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armnetwork.VirtualNetworkGatewaysClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureVirtualNetworkGatewaysClient creates a new AzureVirtualNetworkGatewaysClient (our facade
+// client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureVirtualNetworkGatewaysClient(ctx context.Context, clients func(subscriptionID string) (*armnetwork.VirtualNetworkGatewaysClient, error)) *AzureVirtualNetworkGatewaysClient {
+	return &AzureVirtualNetworkGatewaysClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureVirtualNetworkGatewaysClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureVirtualNetworkGatewaysClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetVirtualNetworkGateway gets a virtual network gateway by its resource ID.
+func (c *AzureVirtualNetworkGatewaysClient) GetVirtualNetworkGateway(gatewayID string) (_ *armnetwork.VirtualNetworkGateway, err error) {
+	ctx, span := startScopeSpan(c.ctx, "VirtualNetworkGateways.Get", gatewayID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(gatewayID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse virtual network gateway ID %s: %w", gatewayID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(gatewayID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(gatewayID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual network gateway %s: %w", gatewayID, rec.withCorrelationID(err))
+	}
+	return &resp.VirtualNetworkGateway, nil
+}
+
+// AzureNetworkWatchersClient is a facade over the Azure network watchers and flow logs clients.
+// Exists to make our code easier to test (it handles paging). Network watchers are identified by
+// their resource IDs.
+type AzureNetworkWatchersClient struct {
+	ctx            context.Context
+	watchers       func(subscriptionID string) (*armnetwork.WatchersClient, error)
+	flowLogs       func(subscriptionID string) (*armnetwork.FlowLogsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureNetworkWatchersClient creates a new AzureNetworkWatchersClient (our facade client) that
+// gets the clients from the Azure SDK for each subscription from watchers and flowLogs.
+func NewAzureNetworkWatchersClient(ctx context.Context, watchers func(subscriptionID string) (*armnetwork.WatchersClient, error), flowLogs func(subscriptionID string) (*armnetwork.FlowLogsClient, error)) *AzureNetworkWatchersClient {
+	return &AzureNetworkWatchersClient{
+		ctx:      ctx,
+		watchers: watchers,
+		flowLogs: flowLogs,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureNetworkWatchersClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureNetworkWatchersClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListWatchers gets all the network watchers in a subscription.
+func (c *AzureNetworkWatchersClient) ListWatchers(subscriptionID string) (watchers []*armnetwork.Watcher, err error) {
+	scope := "/subscriptions/" + subscriptionID
+	ctx, span := startScopeSpan(c.ctx, "NetworkWatchers.ListAll", scope)
+	defer func() { endSpan(span, err) }()
+	client, err := c.watchers(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := client.NewListAllPager(nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				watchers = append(watchers, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return watchers, err
+	case <-c.ctx.Done():
+		return watchers, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
+
+// ListFlowLogs gets all the flow logs of a network watcher by its resource ID.
+func (c *AzureNetworkWatchersClient) ListFlowLogs(watcherID string) (flowLogs []*armnetwork.FlowLog, err error) {
+	ctx, span := startScopeSpan(c.ctx, "FlowLogs.List", watcherID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(watcherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse network watcher ID %s: %w", watcherID, err)
+	}
+	client, err := c.flowLogs(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(watcherID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(watcherID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := client.NewListPager(id.ResourceGroupName, id.Name, nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				flowLogs = append(flowLogs, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return flowLogs, err
+	case <-c.ctx.Done():
+		return flowLogs, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
@@ -0,0 +1,123 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+)
+
+// AzurePermissionsClient is a facade over the Azure permissions client, which reports the
+// effective permissions of the calling identity. Exists to make our code easier to test (it
+// handles paging). Scopes are identified by their resource IDs, so the scopes of a rule can be in
+// different subscriptions.
+type AzurePermissionsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armauthorization.PermissionsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzurePermissionsClient creates a new AzurePermissionsClient (our facade client) that gets the
+// client from the Azure SDK for each subscription from clients.
+func NewAzurePermissionsClient(ctx context.Context, clients func(subscriptionID string) (*armauthorization.PermissionsClient, error)) *AzurePermissionsClient {
+	return &AzurePermissionsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzurePermissionsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzurePermissionsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListPermissionsForScope gets the permissions that the calling identity has at a resource group or
+// a resource, one per role that grants it permissions there. ARM doesn't report permissions at
+// subscriptions or management groups.
+func (c *AzurePermissionsClient) ListPermissionsForScope(scope string) (permissions []*armauthorization.Permission, err error) {
+	ctx, span := startScopeSpan(c.ctx, "Permissions.List", scope)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scope %s: %w", scope, err)
+	}
+	if id.ResourceGroupName == "" {
+		return nil, fmt.Errorf("scope %s is not a resource group or resource", scope)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	// The pagers for resource groups and resources have different types, but their pages both
+	// hold a PermissionGetResult.
+	var more func() bool
+	var nextPage func(context.Context) (armauthorization.PermissionGetResult, error)
+	if id.ResourceType.String() == arm.ResourceGroupResourceType.String() {
+		pager := client.NewListForResourceGroupPager(id.ResourceGroupName, nil)
+		more = pager.More
+		nextPage = func(ctx context.Context) (armauthorization.PermissionGetResult, error) {
+			page, err := pager.NextPage(ctx)
+			return page.PermissionGetResult, err
+		}
+	} else {
+		parentPath, resourceType := resourcePath(id)
+		pager := client.NewListForResourcePager(id.ResourceGroupName, id.ResourceType.Namespace, parentPath, resourceType, id.Name, nil)
+		more = pager.More
+		nextPage = func(ctx context.Context) (armauthorization.PermissionGetResult, error) {
+			page, err := pager.NextPage(ctx)
+			return page.PermissionGetResult, err
+		}
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for more() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := nextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				permissions = append(permissions, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return permissions, err
+	case <-c.ctx.Done():
+		return permissions, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
+
+// resourcePath returns the path of a resource's parents within its resource provider (e.g.
+// virtualNetworks/vnet for a subnet, or empty for a top-level resource) and its own resource type
+// (e.g. subnets), as the permissions API takes them.
+func resourcePath(id *arm.ResourceID) (parentPath, resourceType string) {
+	var parents []string
+	for p := id.Parent; p != nil && p.ResourceType.Namespace == id.ResourceType.Namespace && len(p.ResourceType.Types) > 0; p = p.Parent {
+		parents = append([]string{p.ResourceType.Types[len(p.ResourceType.Types)-1], p.Name}, parents...)
+	}
+	return strings.Join(parents, "/"), id.ResourceType.Types[len(id.ResourceType.Types)-1]
+}
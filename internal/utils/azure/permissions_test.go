@@ -0,0 +1,92 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+)
+
+func Test_ListPermissionsForScope(t *testing.T) {
+	const (
+		sub = "00000000-0000-0000-0000-000000000000"
+		rg  = "/subscriptions/" + sub + "/resourceGroups/rg"
+		// The SDK's request paths spell resourcegroups in lowercase.
+		rgPath = "/subscriptions/" + sub + "/resourcegroups/rg"
+	)
+	tests := []struct {
+		name     string
+		scope    string
+		wantPath string
+		wantErr  bool
+	}{
+		{
+			name:     "Resource group",
+			scope:    rg,
+			wantPath: rgPath + "/providers/Microsoft.Authorization/permissions",
+		},
+		{
+			name:     "Top-level resource",
+			scope:    rg + "/providers/Microsoft.Network/virtualNetworks/vnet",
+			wantPath: rgPath + "/providers/Microsoft.Network/virtualNetworks/vnet/providers/Microsoft.Authorization/permissions",
+		},
+		{
+			name:     "Child resource",
+			scope:    rg + "/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet",
+			wantPath: rgPath + "/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet/providers/Microsoft.Authorization/permissions",
+		},
+		{
+			name:    "Subscription",
+			scope:   "/subscriptions/" + sub,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+				paths = append(paths, req.URL.Path)
+				header := http.Header{}
+				header.Set("Content-Type", "application/json")
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       io.NopCloser(strings.NewReader(`{"value": [{"actions": ["*/read"]}]}`)),
+					Request:    req,
+				}, nil
+			})
+			clients := func(subscriptionID string) (*armauthorization.PermissionsClient, error) {
+				return armauthorization.NewPermissionsClient(subscriptionID, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+					ClientOptions: policy.ClientOptions{
+						Transport: transport,
+						Retry:     policy.RetryOptions{MaxRetries: -1},
+					},
+				})
+			}
+
+			permissions, err := NewAzurePermissionsClient(context.Background(), clients).ListPermissionsForScope(tt.scope)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := []string{tt.wantPath}; !reflect.DeepEqual(paths, want) {
+				t.Errorf("got request paths %v, want %v", paths, want)
+			}
+			if len(permissions) != 1 || *permissions[0].Actions[0] != "*/read" {
+				t.Errorf("got permissions %v, want one with Action */read", permissions)
+			}
+		})
+	}
+}
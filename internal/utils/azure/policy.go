@@ -0,0 +1,104 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
+)
+
+// AzurePolicyExemptionsClient is a facade over the Azure policy exemptions client for a single
+// subscription. Exists to make our code easier to test.
+type AzurePolicyExemptionsClient struct {
+	ctx            context.Context
+	client         *armpolicy.ExemptionsClient
+	correlationIDs correlationIDLog
+}
+
+// NewAzurePolicyExemptionsClient creates a new AzurePolicyExemptionsClient (our facade client) from
+// a client from the Azure SDK.
+func NewAzurePolicyExemptionsClient(ctx context.Context, azClient *armpolicy.ExemptionsClient) *AzurePolicyExemptionsClient {
+	return &AzurePolicyExemptionsClient{
+		ctx:    ctx,
+		client: azClient,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzurePolicyExemptionsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzurePolicyExemptionsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListExemptionsForScope gets all policy exemptions that apply to a subscription or resource group,
+// including those inherited from containing scopes.
+func (c *AzurePolicyExemptionsClient) ListExemptionsForScope(scope string) (exemptions []*armpolicy.Exemption, err error) {
+	ctx, span := startScopeSpan(c.ctx, "PolicyExemptions.List", scope)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scope %s: %w", scope, err)
+	}
+	if id.ResourceType.String() != arm.SubscriptionResourceType.String() && id.ResourceType.String() != arm.ResourceGroupResourceType.String() {
+		return nil, fmt.Errorf("scope %s is neither a subscription nor a resource group", scope)
+	}
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	// The pagers for subscriptions and resource groups have different types, but their pages both
+	// hold an ExemptionListResult.
+	filter := "atScope()"
+	var more func() bool
+	var nextPage func(context.Context) (armpolicy.ExemptionListResult, error)
+	if id.ResourceGroupName == "" {
+		pager := c.client.NewListPager(&armpolicy.ExemptionsClientListOptions{Filter: &filter})
+		more = pager.More
+		nextPage = func(ctx context.Context) (armpolicy.ExemptionListResult, error) {
+			page, err := pager.NextPage(ctx)
+			return page.ExemptionListResult, err
+		}
+	} else {
+		pager := c.client.NewListForResourceGroupPager(id.ResourceGroupName, &armpolicy.ExemptionsClientListForResourceGroupOptions{Filter: &filter})
+		more = pager.More
+		nextPage = func(ctx context.Context) (armpolicy.ExemptionListResult, error) {
+			page, err := pager.NextPage(ctx)
+			return page.ExemptionListResult, err
+		}
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for more() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := nextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			if nextResult.Value != nil {
+				exemptions = append(exemptions, nextResult.Value...)
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return exemptions, err
+	case <-c.ctx.Done():
+		return exemptions, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
@@ -0,0 +1,111 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// principalCollections are the Microsoft Graph collections of the principal types whose objects
+// can be listed by display name, keyed by their OData types.
+var principalCollections = map[string]string{
+	"#microsoft.graph.servicePrincipal": "/servicePrincipals",
+	"#microsoft.graph.user":             "/users",
+	"#microsoft.graph.group":            "/groups",
+}
+
+// AzurePrincipalsClient is a facade over Microsoft Graph's directory objects API, for looking up
+// the principals that role assignments are for. Exists to make our code easier to test.
+type AzurePrincipalsClient struct {
+	ctx            context.Context
+	client         *GraphClient
+	correlationIDs correlationIDLog
+}
+
+// NewAzurePrincipalsClient creates a new AzurePrincipalsClient (our facade client) from a Graph
+// client.
+func NewAzurePrincipalsClient(ctx context.Context, client *GraphClient) *AzurePrincipalsClient {
+	return &AzurePrincipalsClient{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+// CorrelationIDs returns the request IDs of all Graph responses the client has received.
+func (c *AzurePrincipalsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// GetPrincipal gets a principal of any type by its object ID, with its type and display name.
+func (c *AzurePrincipalsClient) GetPrincipal(objectID string) (_ *GraphDirectoryObject, err error) {
+	path := "/directoryObjects/" + url.PathEscape(objectID)
+	ctx, span := startScopeSpan(c.ctx, "DirectoryObjects.Get", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	// Directory objects have the properties of their types, so there's nothing to select.
+	var principal GraphDirectoryObject
+	if err = c.client.get(ctx, path, nil, &principal); err != nil {
+		return nil, fmt.Errorf("failed to get principal %s: %w", objectID, rec.withCorrelationID(err))
+	}
+	return &principal, nil
+}
+
+// ListPrincipalsByDisplayName gets the principals of a type (e.g.
+// #microsoft.graph.servicePrincipal) with a display name. Display names aren't unique, so there
+// may be several. Principals of types other than service principals, users, and groups aren't
+// listed.
+func (c *AzurePrincipalsClient) ListPrincipalsByDisplayName(odataType, displayName string) (_ []*GraphDirectoryObject, err error) {
+	path, ok := principalCollections[odataType]
+	if !ok {
+		return nil, nil
+	}
+	ctx, span := startScopeSpan(c.ctx, "DirectoryObjects.List", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	// Single quotes in OData string literals are escaped by doubling them.
+	query := url.Values{
+		"$filter": {fmt.Sprintf("displayName eq '%s'", strings.ReplaceAll(displayName, "'", "''"))},
+		"$select": {"id,displayName"},
+	}
+	principals, err := listGraph[GraphDirectoryObject](ctx, c.client, path, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list principals named %s: %w", displayName, rec.withCorrelationID(err))
+	}
+	// Objects of a type's collection don't always say what type they are.
+	for _, p := range principals {
+		if p.ODataType == "" {
+			p.ODataType = odataType
+		}
+	}
+	return principals, nil
+}
+
+// GetServicePrincipalByAppID gets the service principal of an application by the application's
+// (client) ID, or nil if the tenant has none.
+func (c *AzurePrincipalsClient) GetServicePrincipalByAppID(appID string) (_ *GraphDirectoryObject, err error) {
+	const path = "/servicePrincipals"
+	ctx, span := startScopeSpan(c.ctx, "ServicePrincipals.List", path)
+	defer func() { endSpan(span, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	query := url.Values{
+		"$filter": {fmt.Sprintf("appId eq '%s'", strings.ReplaceAll(appID, "'", "''"))},
+		"$select": {"id,displayName"},
+	}
+	principals, err := listGraph[GraphDirectoryObject](ctx, c.client, path, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service principals of application %s: %w", appID, rec.withCorrelationID(err))
+	}
+	// An application has at most one service principal per tenant.
+	if len(principals) == 0 || principals[0] == nil {
+		return nil, nil
+	}
+	principals[0].ODataType = "#microsoft.graph.servicePrincipal"
+	return principals[0], nil
+}
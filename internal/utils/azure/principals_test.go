@@ -0,0 +1,74 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func Test_AzurePrincipalsClient(t *testing.T) {
+	var paths, filters []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		filters = append(filters, req.URL.Query().Get("$filter"))
+		body := `{"value": [{"id": "sp-2", "displayName": "ci-deployer"}]}`
+		if req.URL.Path == "/v1.0/directoryObjects/sp-1" {
+			body = `{"@odata.type": "#microsoft.graph.servicePrincipal", "id": "sp-1", "displayName": "ci-deployer", "appId": "app-1"}`
+		}
+		return graphResponse(req, http.StatusOK, body), nil
+	})
+	c := NewAzurePrincipalsClient(context.Background(), newTestGraphClient(t, transport))
+
+	principal, err := c.GetPrincipal("sp-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (&GraphDirectoryObject{ID: "sp-1", ODataType: "#microsoft.graph.servicePrincipal", DisplayName: "ci-deployer"}); !reflect.DeepEqual(principal, want) {
+		t.Errorf("got principal %v, want %v", principal, want)
+	}
+	principals, err := c.ListPrincipalsByDisplayName(principal.ODataType, "ci-deployer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []*GraphDirectoryObject{{ID: "sp-2", ODataType: "#microsoft.graph.servicePrincipal", DisplayName: "ci-deployer"}}; !reflect.DeepEqual(principals, want) {
+		t.Errorf("got principals %v, want %v", principals, want)
+	}
+	if principals, err := c.ListPrincipalsByDisplayName("#microsoft.graph.device", "ci-deployer"); err != nil || principals != nil {
+		t.Errorf("got principals %v and error %v for devices, want neither", principals, err)
+	}
+
+	if want := []string{"/v1.0/directoryObjects/sp-1", "/v1.0/servicePrincipals"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+	if want := []string{"", "displayName eq 'ci-deployer'"}; !reflect.DeepEqual(filters, want) {
+		t.Errorf("got filters %v, want %v", filters, want)
+	}
+}
+
+func Test_AzurePrincipalsClient_GetServicePrincipalByAppID(t *testing.T) {
+	var filters []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		filters = append(filters, req.URL.Query().Get("$filter"))
+		body := `{"value": []}`
+		if req.URL.Query().Get("$filter") == "appId eq 'app-1'" {
+			body = `{"value": [{"id": "sp-1", "displayName": "ci-deployer"}]}`
+		}
+		return graphResponse(req, http.StatusOK, body), nil
+	})
+	c := NewAzurePrincipalsClient(context.Background(), newTestGraphClient(t, transport))
+
+	principal, err := c.GetServicePrincipalByAppID("app-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (&GraphDirectoryObject{ID: "sp-1", ODataType: "#microsoft.graph.servicePrincipal", DisplayName: "ci-deployer"}); !reflect.DeepEqual(principal, want) {
+		t.Errorf("got principal %v, want %v", principal, want)
+	}
+	if principal, err := c.GetServicePrincipalByAppID("app-2"); err != nil || principal != nil {
+		t.Errorf("got principal %v and error %v for an application without a service principal, want neither", principal, err)
+	}
+	if want := []string{"appId eq 'app-1'", "appId eq 'app-2'"}; !reflect.DeepEqual(filters, want) {
+		t.Errorf("got filters %v, want %v", filters, want)
+	}
+}
@@ -0,0 +1,35 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// limiter bounds the rate of Azure API calls made by all facades in the process, regardless of how
+// many AzureValidators are reconciled in parallel. It is nil unless a rate limit has been set.
+var limiter atomic.Pointer[rate.Limiter]
+
+// SetRateLimit limits Azure API calls made by facades to qps calls per second, allowing bursts of
+// up to burst calls. A qps of zero or less removes the limit.
+func SetRateLimit(qps float64, burst int) {
+	if qps <= 0 {
+		limiter.Store(nil)
+		return
+	}
+	limiter.Store(rate.NewLimiter(rate.Limit(qps), burst))
+}
+
+// waitForRateLimit blocks until the rate limit permits another Azure API call, or ctx is done.
+func waitForRateLimit(ctx context.Context) error {
+	l := limiter.Load()
+	if l == nil {
+		return nil
+	}
+	if err := l.Wait(ctx); err != nil {
+		return fmt.Errorf("failed waiting for Azure API rate limit: %w", err)
+	}
+	return nil
+}
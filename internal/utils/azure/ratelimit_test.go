@@ -0,0 +1,94 @@
+package azure
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+)
+
+func newRoleAssignmentsClientMock(t *testing.T) *armauthorization.RoleAssignmentsClient {
+	client, err := armauthorization.NewRoleAssignmentsClient("", &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transporterMock{},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func Test_RateLimit(t *testing.T) {
+	const (
+		qps        = 50
+		goroutines = 10
+		calls      = 3
+	)
+	SetRateLimit(qps, 1)
+	defer SetRateLimit(0, 0)
+
+	client := NewAzureRoleAssignmentsClient(context.Background(), newRoleAssignmentsClientMock(t))
+	scope := "/subscriptions/00000000-0000-0000-0000-000000000000"
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*calls)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				if _, err := client.GetRoleAssignmentsForScope(scope, nil); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// With a burst of 1, the first call is immediate and each subsequent call waits for a token.
+	want := time.Duration(goroutines*calls-1) * time.Second / qps
+	if elapsed < want {
+		t.Errorf("%d calls took %s, want at least %s at %d calls per second", goroutines*calls, elapsed, want, qps)
+	}
+}
+
+func Test_RateLimitContextCancelled(t *testing.T) {
+	SetRateLimit(0.001, 1)
+	defer SetRateLimit(0, 0)
+
+	// Use up the only token so that the next call has to wait.
+	client := NewAzureRoleAssignmentsClient(context.Background(), newRoleAssignmentsClientMock(t))
+	if _, err := client.GetRoleAssignmentsForScope("/subscriptions/00000000-0000-0000-0000-000000000000", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client = NewAzureRoleAssignmentsClient(ctx, newRoleAssignmentsClientMock(t))
+
+	done := make(chan error)
+	go func() {
+		_, err := client.GetRoleAssignmentsForScope("/subscriptions/00000000-0000-0000-0000-000000000000", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error when the context is done while waiting for the rate limit")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("call blocked on the rate limit after its context was done")
+	}
+}
@@ -0,0 +1,210 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// regionalEndpointCooldown is how long requests for a region are sent to the global ARM
+	// endpoint after the region's endpoint rejected one.
+	regionalEndpointCooldown = 10 * time.Minute
+	// globalSampleEvery is how often a request for a region is sent to the global ARM endpoint
+	// instead, to compare the latency of the two.
+	globalSampleEvery = 50
+	// latencyWeight is the weight of each new sample in the average latency of an endpoint.
+	latencyWeight = 0.2
+)
+
+type armRegionKey struct{}
+
+// WithARMRegion returns a context whose ARM requests are sent to the regional ARM endpoint of an
+// Azure region (e.g. australiaeast) instead of the global endpoint, where supported. An empty
+// region leaves the context's region unchanged.
+func WithARMRegion(ctx context.Context, region string) context.Context {
+	if region == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, armRegionKey{}, region)
+}
+
+// regionalHost returns the host of the regional endpoint of a region for the ARM endpoint host of
+// a cloud, e.g. australiaeast.management.azure.com for management.azure.com.
+func regionalHost(armHost, region string) string {
+	return region + "." + armHost
+}
+
+// regionalEndpoints routes the ARM requests of contexts with a region to the region's endpoint,
+// and tracks which regional endpoints reject requests and how fast each endpoint responds.
+type regionalEndpoints struct {
+	clock clock.PassiveClock
+	// armHost is the host of the cloud's global ARM endpoint.
+	armHost string
+
+	mu sync.Mutex
+	// keys = regions
+	regions map[string]*regionalEndpoint
+}
+
+// regionalEndpoint is the state of the endpoint of a single region.
+type regionalEndpoint struct {
+	// unavailableUntil is when requests are sent to the region's endpoint again, after it
+	// rejected one. Zero if it's available.
+	unavailableUntil time.Time
+	// requests is the number of requests for the region.
+	requests int
+	// regional and global are the latencies of the region's requests sent to the regional and
+	// the global endpoint.
+	regional, global latency
+}
+
+// latency is an exponentially weighted moving average of response times.
+type latency struct {
+	avg     time.Duration
+	samples int
+}
+
+func (l *latency) add(d time.Duration) {
+	if l.samples == 0 {
+		l.avg = d
+	} else {
+		l.avg += time.Duration(latencyWeight * float64(d-l.avg))
+	}
+	l.samples++
+}
+
+func newRegionalEndpoints(clock clock.PassiveClock, armEndpoint string) *regionalEndpoints {
+	u, err := url.Parse(armEndpoint)
+	host := ""
+	if err == nil {
+		host = u.Host
+	}
+	return &regionalEndpoints{
+		clock:   clock,
+		armHost: host,
+		regions: map[string]*regionalEndpoint{},
+	}
+}
+
+// route returns whether a request for a region is sent to the region's endpoint, rather than the
+// global endpoint, and whether it's sent to the global endpoint to sample its latency: once the
+// regional endpoint has responded, every globalSampleEvery-th request is.
+func (e *regionalEndpoints) route(region string) (regional, sample bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r, ok := e.regions[region]
+	if !ok {
+		r = &regionalEndpoint{}
+		e.regions[region] = r
+	}
+	r.requests++
+	if e.clock.Now().Before(r.unavailableUntil) {
+		return false, false
+	}
+	sample = r.regional.samples > 0 && (r.global.samples == 0 || r.requests%globalSampleEvery == 0)
+	return !sample, sample
+}
+
+// reject records that a region's endpoint rejected a request, and returns when requests are sent
+// to it again.
+func (e *regionalEndpoints) reject(region string) time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r := e.regions[region]
+	r.unavailableUntil = e.clock.Now().Add(regionalEndpointCooldown)
+	return r.unavailableUntil
+}
+
+// record records how long a region's request took to get a response from the regional or the
+// global endpoint, and returns the average latencies of both, for the region's requests.
+func (e *regionalEndpoints) record(region string, regional bool, d time.Duration) (regionalAvg, globalAvg latency) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r := e.regions[region]
+	if regional {
+		r.regional.add(d)
+	} else {
+		r.global.add(d)
+	}
+	return r.regional, r.global
+}
+
+// rejected returns whether a regional endpoint's response to a request, or its error, means that
+// the endpoint can't serve the request: ARM couldn't be reached (e.g. the region has no endpoint),
+// the endpoint says the request was misdirected, or it failed with a server error. Other error
+// responses are ARM's answer to the request, which the global endpoint would give too.
+func rejected(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusMisdirectedRequest || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// regionalEndpointPolicy is an azcore pipeline policy that sends each ARM read request made with
+// a context with a region to the region's ARM endpoint, and falls back to the global endpoint if
+// the regional endpoint rejects it. Only reads are routed, so falling back never repeats a write.
+// Requests to other endpoints (e.g. Microsoft Graph) aren't affected.
+type regionalEndpointPolicy struct {
+	endpoints *regionalEndpoints
+}
+
+// Do implements policy.Policy.
+func (p regionalEndpointPolicy) Do(req *policy.Request) (*http.Response, error) {
+	e := p.endpoints
+	raw := req.Raw()
+	region, _ := raw.Context().Value(armRegionKey{}).(string)
+	if region == "" || raw.URL.Host != e.armHost || (raw.Method != http.MethodGet && raw.Method != http.MethodHead) {
+		return req.Next()
+	}
+	l := logr.FromContextOrDiscard(raw.Context())
+
+	routed, sample := e.route(region)
+	if routed {
+		host := regionalHost(e.armHost, region)
+		regional := req.Clone(raw.Context())
+		regional.Raw().URL.Host = host
+		regional.Raw().Host = host
+		start := e.clock.Now()
+		resp, err := regional.Next()
+		if !rejected(resp, err) {
+			if err == nil {
+				e.record(region, true, e.clock.Since(start))
+			}
+			return resp, err
+		}
+		retryAt := e.reject(region)
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		l.Info("Regional ARM endpoint rejected a request. Sending the region's requests to the global endpoint for now.",
+			"region", region, "endpoint", host, "statusCode", status, "error", err, "retryAt", retryAt)
+		if err := req.RewindBody(); err != nil {
+			return nil, err
+		}
+		return req.Next()
+	}
+
+	start := e.clock.Now()
+	resp, err := req.Next()
+	if err == nil {
+		regionalAvg, globalAvg := e.record(region, false, e.clock.Since(start))
+		if sample {
+			l.Info("Measured the latency of a regional ARM endpoint against the global endpoint.", "region", region,
+				"regionalLatency", regionalAvg.avg, "globalLatency", globalAvg.avg, "saved", globalAvg.avg-regionalAvg.avg)
+		}
+	}
+	return resp, err
+}
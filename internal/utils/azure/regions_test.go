@@ -0,0 +1,193 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+const (
+	regionalLatency = 20 * time.Millisecond
+	globalLatency   = 200 * time.Millisecond
+)
+
+// regionalARM is a fake ARM whose regional endpoints respond faster than its global endpoint, by
+// advancing a clock. It records the host each request was sent to.
+type regionalARM struct {
+	clk *clocktesting.FakePassiveClock
+
+	mu    sync.Mutex
+	hosts []string
+	// regionalStatus is the status regional endpoints respond with. Zero if they can't be reached.
+	regionalStatus int
+}
+
+func (a *regionalARM) Do(req *http.Request) (*http.Response, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if req.Host != req.URL.Host {
+		return nil, &net.OpError{Op: "dial", Err: net.UnknownNetworkError("host header " + req.Host + " doesn't match " + req.URL.Host)}
+	}
+	a.hosts = append(a.hosts, req.URL.Host)
+	statusCode := http.StatusOK
+	if req.URL.Host == "management.azure.com" {
+		a.clk.SetTime(a.clk.Now().Add(globalLatency))
+	} else {
+		if a.regionalStatus == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: req.URL.Host, IsNotFound: true}
+		}
+		a.clk.SetTime(a.clk.Now().Add(regionalLatency))
+		statusCode = a.regionalStatus
+	}
+	body := `{"value": []}`
+	if statusCode != http.StatusOK {
+		body = `{"error": {"code": "Error", "message": "error"}}`
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// takeHosts returns the hosts that requests were sent to since the last call.
+func (a *regionalARM) takeHosts() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	hosts := a.hosts
+	a.hosts = nil
+	return hosts
+}
+
+func Test_RegionalEndpoints(t *testing.T) {
+	const (
+		scope    = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		regional = "australiaeast.management.azure.com"
+		global   = "management.azure.com"
+	)
+	clk := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	arm := &regionalARM{clk: clk, regionalStatus: http.StatusOK}
+	f := NewClientFactory(ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) { return &azfake.TokenCredential{}, nil },
+		Transport:  arm,
+		Retry:      policy.RetryOptions{MaxRetries: -1},
+	})
+	f.regions.clock = clk
+	api, err := f.API("implicit", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raClient, err := api.RoleAssignments()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs []string
+	ctx := logr.NewContext(context.Background(), funcr.New(func(_, args string) { logs = append(logs, args) }, funcr.Options{}))
+	regionCtx := WithARMRegion(ctx, "australiaeast")
+	get := func(ctx context.Context, wantHosts ...string) error {
+		t.Helper()
+		_, err := NewAzureRoleAssignmentsClient(ctx, raClient).GetRoleAssignmentsForScope(scope, nil)
+		if hosts := arm.takeHosts(); !slices.Equal(hosts, wantHosts) {
+			t.Fatalf("got requests to %v, want %v", hosts, wantHosts)
+		}
+		return err
+	}
+
+	// Requests without a region go to the global endpoint.
+	if err := get(ctx, global); err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests with a region go to its endpoint, except for those that sample the global
+	// endpoint's latency: the first after the regional endpoint responded, then every 50th.
+	for i := 1; i <= globalSampleEvery; i++ {
+		want := regional
+		if i == 2 || i == globalSampleEvery {
+			want = global
+		}
+		if err := get(regionCtx, want); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(logs) != 2 || !strings.Contains(logs[0], `"region"="australiaeast" "regionalLatency"="20ms" "globalLatency"="200ms" "saved"="180ms"`) {
+		t.Errorf("got logs %v, want the latency difference logged for each sample", logs)
+	}
+
+	// Writes aren't routed.
+	if _, err := raClient.Create(regionCtx, scope, "ra", armauthorization.RoleAssignmentCreateParameters{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if hosts := arm.takeHosts(); !slices.Equal(hosts, []string{global}) {
+		t.Errorf("got a write sent to %v, want it sent to the global endpoint", hosts)
+	}
+
+	// ARM's error responses from the regional endpoint are returned as is.
+	arm.regionalStatus = http.StatusNotFound
+	if err := get(regionCtx, regional); err == nil {
+		t.Error("expected the regional endpoint's error to be returned")
+	}
+
+	// Requests that the regional endpoint rejects are sent to the global endpoint, and so are the
+	// region's requests until the cooldown has passed.
+	logs = nil
+	arm.regionalStatus = 0
+	if err := get(regionCtx, regional, global); err != nil {
+		t.Fatalf("expected the request to fall back to the global endpoint, got %v", err)
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], `"endpoint"="australiaeast.management.azure.com"`) {
+		t.Errorf("got logs %v, want the fallback logged", logs)
+	}
+	clk.SetTime(clk.Now().Add(regionalEndpointCooldown - time.Second))
+	if err := get(regionCtx, global); err != nil {
+		t.Fatal(err)
+	}
+	arm.regionalStatus = http.StatusOK
+	clk.SetTime(clk.Now().Add(time.Second))
+	if err := get(regionCtx, regional); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		err        error
+		want       bool
+	}{
+		{name: "Successful responses aren't rejections.", statusCode: http.StatusOK},
+		{name: "ARM's client errors aren't rejections.", statusCode: http.StatusForbidden},
+		{name: "Misdirected requests are rejected.", statusCode: http.StatusMisdirectedRequest, want: true},
+		{name: "Server errors are rejections.", statusCode: http.StatusServiceUnavailable, want: true},
+		{name: "Unreachable endpoints reject requests.", err: &net.DNSError{Err: "no such host", IsNotFound: true}, want: true},
+		{name: "Cancelled requests aren't rejections.", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.statusCode}
+			}
+			if got := rejected(resp, tt.err); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
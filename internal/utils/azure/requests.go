@@ -0,0 +1,90 @@
+package azure
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// RemainingReadsHeader is the ARM response header with the number of read requests that the
+// caller may still make in a subscription before ARM throttles it.
+const RemainingReadsHeader = "x-ms-ratelimit-remaining-subscription-reads"
+
+type requestCountsKey struct{}
+
+// RequestCounts counts the ARM requests sent with a context, per client type, including retries,
+// and records the fewest remaining reads that ARM reported in responses to them.
+type RequestCounts struct {
+	mu sync.Mutex
+	// keys = client types (e.g. RoleAssignments)
+	byClientType   map[string]int
+	remainingReads int
+	reported       bool
+}
+
+// WithRequestCounts returns a context that counts the ARM requests sent with it, and the counts.
+func WithRequestCounts(ctx context.Context) (context.Context, *RequestCounts) {
+	c := &RequestCounts{byClientType: map[string]int{}}
+	return context.WithValue(ctx, requestCountsKey{}, c), c
+}
+
+// ByClientType returns the number of requests sent by each type of client.
+func (c *RequestCounts) ByClientType() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.byClientType)
+}
+
+// Total returns the number of requests sent.
+func (c *RequestCounts) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := 0
+	for _, n := range c.byClientType {
+		total += n
+	}
+	return total
+}
+
+// RemainingReads returns the fewest remaining reads that ARM reported, and whether it reported any.
+func (c *RequestCounts) RemainingReads() (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remainingReads, c.reported
+}
+
+func (c *RequestCounts) record(clientType string, resp *http.Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byClientType[clientType]++
+	if resp == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get(RemainingReadsHeader))
+	if err != nil {
+		return
+	}
+	if !c.reported || remaining < c.remainingReads {
+		c.remainingReads = remaining
+		c.reported = true
+	}
+}
+
+// requestCountPolicy is an azcore pipeline policy that counts each request sent by a type of
+// client in the request context's RequestCounts, if there is one.
+type requestCountPolicy struct {
+	clientType string
+}
+
+// Do implements policy.Policy.
+func (p requestCountPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if c, ok := req.Raw().Context().Value(requestCountsKey{}).(*RequestCounts); ok {
+		c.record(p.clientType, resp)
+	}
+	return resp, err
+}
@@ -0,0 +1,90 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func Test_RequestCounts(t *testing.T) {
+	// remaining are the remaining reads that ARM reports in its responses, in order. Empty values
+	// aren't reported, and invalid ones are ignored.
+	remaining := []string{"11999", "", "11500", "not-a-number", "11800"}
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		if len(remaining) > 0 {
+			if remaining[0] != "" {
+				header.Set(RemainingReadsHeader, remaining[0])
+			}
+			remaining = remaining[1:]
+		}
+		body := `{"value": []}`
+		if strings.Contains(req.URL.Path, "/roleDefinitions/") {
+			body = `{"id": "rd"}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	f := NewClientFactory(ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) { return &azfake.TokenCredential{}, nil },
+		Transport:  transport,
+		Retry:      policy.RetryOptions{MaxRetries: -1},
+	})
+	api, err := f.API("implicit", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raClient, err := api.RoleAssignments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdClient, err := api.RoleDefinitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests made without counts, e.g. outside of a reconcile, aren't counted.
+	ctx, counts := WithRequestCounts(context.Background())
+	if _, err := NewAzureRoleAssignmentsClient(context.Background(), raClient).GetRoleAssignmentsForScope("/subscriptions/sub", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := counts.RemainingReads(); ok {
+		t.Error("expected no remaining reads before any counted request")
+	}
+
+	ra := NewAzureRoleAssignmentsClient(ctx, raClient)
+	for i := 0; i < 2; i++ {
+		if _, err := ra.GetRoleAssignmentsForScope("/subscriptions/sub", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rd := NewAzureRoleDefinitionsClient(ctx, rdClient)
+	for i := 0; i < 2; i++ {
+		if _, err := rd.GetByID("/subscriptions/sub/providers/Microsoft.Authorization/roleDefinitions/rd"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]int{clientTypeRoleAssignments: 2, clientTypeRoleDefinitions: 2}
+	if got := counts.ByClientType(); !maps.Equal(got, want) {
+		t.Errorf("got counts %v, want %v", got, want)
+	}
+	if got := counts.Total(); got != 4 {
+		t.Errorf("got total %d, want 4", got)
+	}
+	if got, ok := counts.RemainingReads(); !ok || got != 11500 {
+		t.Errorf("got remaining reads %d (reported: %t), want the fewest reported, 11500", got, ok)
+	}
+}
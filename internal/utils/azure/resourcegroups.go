@@ -0,0 +1,82 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// AzureResourceGroupsClient is a facade over the Azure resource groups client. Exists to make our
+// code easier to test (it handles paging).
+type AzureResourceGroupsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armresources.ResourceGroupsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureResourceGroupsClient creates a new AzureResourceGroupsClient (our facade client) that
+// gets the client from the Azure SDK for each subscription from clients.
+func NewAzureResourceGroupsClient(ctx context.Context, clients func(subscriptionID string) (*armresources.ResourceGroupsClient, error)) *AzureResourceGroupsClient {
+	return &AzureResourceGroupsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureResourceGroupsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureResourceGroupsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListResourceGroups gets the names of all resource groups in a subscription.
+func (c *AzureResourceGroupsClient) ListResourceGroups(subscriptionID string) (names []string, err error) {
+	scope := "/subscriptions/" + subscriptionID
+	ctx, span := startScopeSpan(c.ctx, "ResourceGroups.List", scope)
+	defer func() { endSpan(span, err) }()
+	client, err := c.clients(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+	pager := client.NewListPager(nil)
+
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for pager.More() {
+			if err := waitForRateLimit(ctx); err != nil {
+				ch <- err
+				return
+			}
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				ch <- fmt.Errorf("failed to get next page of results: %w", rec.withCorrelationID(err))
+				return
+			}
+			for _, rg := range nextResult.Value {
+				if rg != nil && rg.Name != nil {
+					names = append(names, *rg.Name)
+				}
+			}
+		}
+		ch <- nil
+	}()
+
+	select {
+	case err = <-ch:
+		return names, err
+	case <-c.ctx.Done():
+		return names, fmt.Errorf("context cancelled: %w", c.ctx.Err())
+	}
+}
@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+func Test_ListResourceGroups(t *testing.T) {
+	const (
+		sub      = "00000000-0000-0000-0000-000000000000"
+		wantPath = "/subscriptions/" + sub + "/resourcegroups"
+	)
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"value": [{"name": "rg-1"}, {}], "nextLink": "https://management.azure.com` + wantPath + `?page=2"}`
+		if req.URL.Query().Get("page") == "2" {
+			body = `{"value": [{"name": "rg-2"}]}`
+		}
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	clients := func(subscriptionID string) (*armresources.ResourceGroupsClient, error) {
+		return armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+			ClientOptions: policy.ClientOptions{
+				Transport: transport,
+				Retry:     policy.RetryOptions{MaxRetries: -1},
+			},
+		})
+	}
+
+	names, err := NewAzureResourceGroupsClient(context.Background(), clients).ListResourceGroups(sub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"rg-1", "rg-2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got names %v, want %v", names, want)
+	}
+	if want := []string{wantPath, wantPath}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,39 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// getGenericResource gets a resource by its resource ID with the generic resources client, at an
+// api-version, and unmarshals it into v. It's for resource types that there are no Azure SDK for
+// Go modules for among the plugin's dependencies. Their properties are untyped in the generic
+// resource, so they're read by re-encoding it.
+func getGenericResource(ctx context.Context, client *armresources.Client, correlationIDs *correlationIDLog, spanName, kind, resourceID, apiVersion string, v any) (err error) {
+	ctx, span := startScopeSpan(ctx, spanName, resourceID)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(resourceID); err != nil {
+		return err
+	}
+	defer func() { recordCall(resourceID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return err
+	}
+	resp, err := client.GetByID(ctx, resourceID, apiVersion, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s: %w", kind, resourceID, rec.withCorrelationID(err))
+	}
+	data, err := json.Marshal(resp.GenericResource)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to read %s %s: %w", kind, resourceID, err)
+	}
+	return nil
+}
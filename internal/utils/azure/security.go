@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/security/armsecurity"
+)
+
+// AzurePricingsClient is a facade over the Azure Defender for Cloud pricings client for a single
+// subscription. Exists to make our code easier to test.
+type AzurePricingsClient struct {
+	ctx            context.Context
+	client         *armsecurity.PricingsClient
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzurePricingsClient creates a new AzurePricingsClient (our facade client) from a client from
+// the Azure SDK for the subscription with ID subscriptionID.
+func NewAzurePricingsClient(ctx context.Context, azClient *armsecurity.PricingsClient, subscriptionID string) *AzurePricingsClient {
+	return &AzurePricingsClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzurePricingsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzurePricingsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListPricings gets the pricing configurations of all Defender for Cloud plans in the subscription.
+func (c *AzurePricingsClient) ListPricings() (_ []*armsecurity.Pricing, err error) {
+	scope := fmt.Sprintf("/subscriptions/%s", c.subscriptionID)
+	ctx, span := startScopeSpan(c.ctx, "Pricings.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	// The scope ID of the pricings API has no leading slash.
+	resp, err := c.client.List(ctx, scope[1:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pricings of subscription %s: %w", c.subscriptionID, rec.withCorrelationID(err))
+	}
+	return resp.Value, nil
+}
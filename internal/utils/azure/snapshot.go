@@ -0,0 +1,133 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+type snapshotKey struct{}
+
+// Snapshot records the JSON of the responses to the GET requests sent with a context, i.e. what
+// the rules that sent them observed in Azure, up to a number of bytes. It never records the
+// requests' headers, which carry the credential's tokens, or the responses to other requests,
+// e.g. those that list storage account keys.
+type Snapshot struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	rule     snapshotRule
+	// Responses are recorded in the order they were received.
+	responses []SnapshotResponse
+	omitted   int
+}
+
+// snapshotRule is the rule that the responses recorded by a Snapshot are for.
+type snapshotRule struct {
+	name           string
+	validationType string
+}
+
+// SnapshotResponse is a response recorded by a Snapshot.
+type SnapshotResponse struct {
+	// The rule whose request the response is to.
+	RuleName       string `json:"ruleName,omitempty"`
+	ValidationType string `json:"validationType,omitempty"`
+	URL            string `json:"url"`
+	StatusCode     int    `json:"statusCode"`
+	// The body of the response, if it's JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// WithSnapshot returns a context that records the responses to the GET requests sent with it in a
+// Snapshot, and the Snapshot. The Snapshot holds at most maxBytes of responses, as JSON.
+func WithSnapshot(ctx context.Context, maxBytes int) (context.Context, *Snapshot) {
+	s := &Snapshot{maxBytes: maxBytes}
+	return context.WithValue(ctx, snapshotKey{}, s), s
+}
+
+// StartRule attributes the responses recorded from now on to a rule. Rules are evaluated one at a
+// time, so those responses are to the rule's requests.
+func (s *Snapshot) StartRule(name, validationType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rule = snapshotRule{name: name, validationType: validationType}
+}
+
+// Responses returns the recorded responses.
+func (s *Snapshot) Responses() []SnapshotResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.responses)
+}
+
+// Omitted returns the number of responses that weren't recorded, because the Snapshot would have
+// held more than its maximum number of bytes with them.
+func (s *Snapshot) Omitted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.omitted
+}
+
+func (s *Snapshot) record(req *http.Request, resp *http.Response, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := SnapshotResponse{
+		RuleName:       s.rule.name,
+		ValidationType: s.rule.validationType,
+		URL:            req.URL.String(),
+		StatusCode:     resp.StatusCode,
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, body) == nil {
+		r.Body = compact.Bytes()
+	}
+	// The size of a response is that of its JSON in the Snapshot's responses.
+	b, err := json.Marshal(r)
+	if err != nil || s.size+len(b)+1 > s.maxBytes {
+		s.omitted++
+		return
+	}
+	s.size += len(b) + 1
+	s.responses = append(s.responses, r)
+}
+
+// snapshotted returns whether a response is recorded in snapshots. Responses to requests other
+// than GETs aren't, nor are those that are retried, since they're of throttled requests or server
+// errors rather than of what the requests observed.
+func snapshotted(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet || resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// snapshotPolicy is an azcore pipeline policy that records the responses to GET requests in the
+// request context's Snapshot, if there is one.
+type snapshotPolicy struct{}
+
+// Do implements policy.Policy.
+func (snapshotPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	s, ok := req.Raw().Context().Value(snapshotKey{}).(*Snapshot)
+	if !ok || err != nil || !snapshotted(req.Raw(), resp) {
+		return resp, err
+	}
+	body, err := runtime.Payload(resp)
+	if err != nil {
+		// The response is returned as is, for the client to fail to read it as it would have.
+		return resp, nil
+	}
+	s.record(req.Raw(), resp, body)
+	return resp, nil
+}
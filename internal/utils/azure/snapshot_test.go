@@ -0,0 +1,127 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func Test_Snapshot(t *testing.T) {
+	// Role definitions are found, role assignments are listed, and the blob is uploaded.
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		status, body := http.StatusOK, `{"value": [{"id": "ra"}]}`
+		switch {
+		case req.Method == http.MethodPut:
+			status, body = http.StatusCreated, ""
+		case strings.Contains(req.URL.Path, "/roleDefinitions/"):
+			body = `{"id": "rd", "properties": {"roleName": "` + strings.Repeat("r", 200) + `"}}`
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	f := NewClientFactory(ClientFactoryOptions{
+		Credential: func() (azcore.TokenCredential, error) { return &azfake.TokenCredential{}, nil },
+		Transport:  transport,
+		Retry:      policy.RetryOptions{MaxRetries: -1},
+	})
+	api, err := f.API("implicit", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raClient, err := api.RoleAssignments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdClient, err := api.RoleDefinitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := api.Blobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first response fits, the role definition, which is larger, doesn't, and the second
+	// role assignments response does again.
+	ctx, snapshot := WithSnapshot(context.Background(), 500)
+	snapshot.StartRule("rule-1", "azure-rbac")
+	if _, err := NewAzureRoleAssignmentsClient(ctx, raClient).GetRoleAssignmentsForScope("/subscriptions/sub", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAzureRoleDefinitionsClient(ctx, rdClient).GetByID("/subscriptions/sub/providers/Microsoft.Authorization/roleDefinitions/rd"); err != nil {
+		t.Fatal(err)
+	}
+	snapshot.StartRule("rule-2", "azure-rbac")
+	if _, err := NewAzureRoleAssignmentsClient(ctx, raClient).GetRoleAssignmentsForScope("/subscriptions/sub2", nil); err != nil {
+		t.Fatal(err)
+	}
+	// Uploads aren't GETs, so they aren't recorded.
+	if err := blobs.UploadBlockBlob(ctx, "https://account.blob.core.windows.net/snapshots", "snapshot.json", "application/json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	// Requests made without a snapshot aren't recorded.
+	if _, err := NewAzureRoleAssignmentsClient(context.Background(), raClient).GetRoleAssignmentsForScope("/subscriptions/sub3", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := snapshot.Responses()
+	if len(responses) != 2 {
+		t.Fatalf("got %d responses, want 2: %+v", len(responses), responses)
+	}
+	for i, want := range []struct{ rule, path string }{{"rule-1", "/subscriptions/sub/"}, {"rule-2", "/subscriptions/sub2/"}} {
+		r := responses[i]
+		if r.RuleName != want.rule || r.ValidationType != "azure-rbac" || !strings.Contains(r.URL, want.path) || r.StatusCode != http.StatusOK {
+			t.Errorf("got response %d %+v, want one of rule %s for %s", i, r, want.rule, want.path)
+		}
+		if string(r.Body) != `{"value":[{"id":"ra"}]}` {
+			t.Errorf("got body %s of response %d, want the role assignments", r.Body, i)
+		}
+	}
+	if got := snapshot.Omitted(); got != 1 {
+		t.Errorf("got %d omitted responses, want 1", got)
+	}
+	b, err := json.Marshal(responses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) > 500 {
+		t.Errorf("got %d bytes of responses, want at most 500", len(b))
+	}
+	if strings.Contains(strings.ToLower(string(b)), "bearer") {
+		t.Errorf("snapshot includes a token: %s", b)
+	}
+}
+
+func Test_snapshotted(t *testing.T) {
+	tests := []struct {
+		method string
+		status int
+		want   bool
+	}{
+		{http.MethodGet, http.StatusOK, true},
+		{http.MethodGet, http.StatusNotFound, true},
+		{http.MethodGet, http.StatusForbidden, true},
+		{http.MethodGet, http.StatusTooManyRequests, false},
+		{http.MethodGet, http.StatusServiceUnavailable, false},
+		{http.MethodPost, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "https://management.azure.com/subscriptions/sub", nil)
+		if got := snapshotted(req, &http.Response{StatusCode: tt.status}); got != tt.want {
+			t.Errorf("snapshotted(%s, %d) = %t, want %t", tt.method, tt.status, got, tt.want)
+		}
+	}
+}
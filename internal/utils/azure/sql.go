@@ -0,0 +1,96 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+)
+
+// AzureSQLServersClient is a facade over the Azure SQL servers and server Azure AD administrators
+// clients. Exists to make our code easier to test. Servers are identified by their resource IDs.
+type AzureSQLServersClient struct {
+	ctx            context.Context
+	servers        func(subscriptionID string) (*armsql.ServersClient, error)
+	administrators func(subscriptionID string) (*armsql.ServerAzureADAdministratorsClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureSQLServersClient creates a new AzureSQLServersClient (our facade client) that gets the
+// clients from the Azure SDK for each subscription from servers and administrators.
+func NewAzureSQLServersClient(ctx context.Context, servers func(subscriptionID string) (*armsql.ServersClient, error), administrators func(subscriptionID string) (*armsql.ServerAzureADAdministratorsClient, error)) *AzureSQLServersClient {
+	return &AzureSQLServersClient{
+		ctx:            ctx,
+		servers:        servers,
+		administrators: administrators,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureSQLServersClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureSQLServersClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetServer gets an Azure SQL logical server by its resource ID.
+func (c *AzureSQLServersClient) GetServer(serverID string) (_ *armsql.Server, err error) {
+	ctx, span := startScopeSpan(c.ctx, "SQLServers.Get", serverID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SQL server ID %s: %w", serverID, err)
+	}
+	client, err := c.servers(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(serverID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(serverID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQL server %s: %w", serverID, rec.withCorrelationID(err))
+	}
+	return &resp.Server, nil
+}
+
+// GetAzureADAdministrator gets the Azure AD administrator of an Azure SQL logical server by the
+// server's resource ID. Getting it fails with a 404 if the server has none.
+func (c *AzureSQLServersClient) GetAzureADAdministrator(serverID string) (_ *armsql.ServerAzureADAdministrator, err error) {
+	ctx, span := startScopeSpan(c.ctx, "SQLServerAzureADAdministrators.Get", serverID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SQL server ID %s: %w", serverID, err)
+	}
+	client, err := c.administrators(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(serverID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(serverID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, armsql.AdministratorNameActiveDirectory, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure AD administrator of SQL server %s: %w", serverID, rec.withCorrelationID(err))
+	}
+	return &resp.ServerAzureADAdministrator, nil
+}
@@ -0,0 +1,218 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+// AzureStorageClient is a facade over the Azure Storage accounts, blob containers, file shares,
+// and management policies clients. Exists to make our code easier to test. Storage accounts, blob
+// containers, and file shares are identified by their resource IDs.
+type AzureStorageClient struct {
+	ctx                context.Context
+	accounts           func(subscriptionID string) (*armstorage.AccountsClient, error)
+	containers         func(subscriptionID string) (*armstorage.BlobContainersClient, error)
+	fileShares         func(subscriptionID string) (*armstorage.FileSharesClient, error)
+	managementPolicies func(subscriptionID string) (*armstorage.ManagementPoliciesClient, error)
+	correlationIDs     correlationIDLog
+}
+
+// NewAzureStorageClient creates a new AzureStorageClient (our facade client) that gets the clients
+// from the Azure SDK for each subscription from accounts, containers, fileShares, and
+// managementPolicies.
+func NewAzureStorageClient(ctx context.Context, accounts func(subscriptionID string) (*armstorage.AccountsClient, error), containers func(subscriptionID string) (*armstorage.BlobContainersClient, error), fileShares func(subscriptionID string) (*armstorage.FileSharesClient, error), managementPolicies func(subscriptionID string) (*armstorage.ManagementPoliciesClient, error)) *AzureStorageClient {
+	return &AzureStorageClient{
+		ctx:                ctx,
+		accounts:           accounts,
+		containers:         containers,
+		fileShares:         fileShares,
+		managementPolicies: managementPolicies,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureStorageClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureStorageClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetStorageAccount gets a storage account, with its network rules, by its resource ID.
+func (c *AzureStorageClient) GetStorageAccount(storageAccountID string) (_ *armstorage.Account, err error) {
+	ctx, span := startScopeSpan(c.ctx, "StorageAccounts.GetProperties", storageAccountID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(storageAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse storage account ID %s: %w", storageAccountID, err)
+	}
+	client, err := c.accounts(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(storageAccountID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(storageAccountID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.GetProperties(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage account %s: %w", storageAccountID, rec.withCorrelationID(err))
+	}
+	return &resp.Account, nil
+}
+
+// GetBlobContainer gets a blob container, with its immutability policy, by its resource ID (e.g.
+// {storage account ID}/blobServices/default/containers/{name}).
+func (c *AzureStorageClient) GetBlobContainer(containerID string) (_ *armstorage.BlobContainer, err error) {
+	ctx, span := startScopeSpan(c.ctx, "BlobContainers.Get", containerID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blob container ID %s: %w", containerID, err)
+	}
+	client, err := c.containers(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(containerID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(containerID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	// The container's parent is the account's blob service, whose parent is the account.
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Parent.Name, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob container %s: %w", containerID, rec.withCorrelationID(err))
+	}
+	return &resp.BlobContainer, nil
+}
+
+// GetFileShare gets a file share by its resource ID (e.g.
+// {storage account ID}/fileServices/default/shares/{name}).
+func (c *AzureStorageClient) GetFileShare(shareID string) (_ *armstorage.FileShare, err error) {
+	ctx, span := startScopeSpan(c.ctx, "FileShares.Get", shareID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(shareID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file share ID %s: %w", shareID, err)
+	}
+	client, err := c.fileShares(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(shareID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(shareID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	// The share's parent is the account's file service, whose parent is the account.
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Parent.Name, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file share %s: %w", shareID, rec.withCorrelationID(err))
+	}
+	return &resp.FileShare, nil
+}
+
+// GetManagementPolicy gets the lifecycle management policy of a storage account by the account's
+// resource ID. Getting it fails with a 404 if the account has none.
+func (c *AzureStorageClient) GetManagementPolicy(storageAccountID string) (_ *armstorage.ManagementPolicy, err error) {
+	ctx, span := startScopeSpan(c.ctx, "ManagementPolicies.Get", storageAccountID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(storageAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse storage account ID %s: %w", storageAccountID, err)
+	}
+	client, err := c.managementPolicies(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(storageAccountID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(storageAccountID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, armstorage.ManagementPolicyNameDefault, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle management policy of storage account %s: %w", storageAccountID, rec.withCorrelationID(err))
+	}
+	return &resp.ManagementPolicy, nil
+}
+
+// AzureStorageSKUsClient is a facade over the Azure Storage SKUs client for a single
+// subscription. Exists to make our code easier to test.
+type AzureStorageSKUsClient struct {
+	ctx            context.Context
+	client         *armstorage.SKUsClient
+	subscriptionID string
+	correlationIDs correlationIDLog
+}
+
+// NewAzureStorageSKUsClient creates a new AzureStorageSKUsClient (our facade client) from a client
+// from the Azure SDK for the subscription with ID subscriptionID.
+func NewAzureStorageSKUsClient(ctx context.Context, azClient *armstorage.SKUsClient, subscriptionID string) *AzureStorageSKUsClient {
+	return &AzureStorageSKUsClient{
+		ctx:            ctx,
+		client:         azClient,
+		subscriptionID: subscriptionID,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureStorageSKUsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureStorageSKUsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// ListStorageSKUs gets the storage account SKUs (e.g. Standard_ZRS) available to the subscription,
+// with the locations they're available in.
+func (c *AzureStorageSKUsClient) ListStorageSKUs() (skus []*armstorage.SKUInformation, err error) {
+	scope := fmt.Sprintf("/subscriptions/%s", c.subscriptionID)
+	ctx, span := startScopeSpan(c.ctx, "StorageSKUs.List", scope)
+	defer func() { endSpan(span, err) }()
+	if err = allowCall(scope); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(scope, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	pager := c.client.NewListPager(nil)
+	for pager.More() {
+		if err = waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list storage SKUs: %w", rec.withCorrelationID(err))
+		}
+		skus = append(skus, page.Value...)
+	}
+	return skus, nil
+}
@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+func Test_GetStorageResources(t *testing.T) {
+	const accountID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/backups"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"properties": {"immutabilityPolicy": {"properties": {"immutabilityPeriodSinceCreationInDays": 30, "state": "Locked"}}}}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	opts := &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+	client := NewAzureStorageClient(context.Background(),
+		func(subscriptionID string) (*armstorage.AccountsClient, error) {
+			return armstorage.NewAccountsClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+		func(subscriptionID string) (*armstorage.BlobContainersClient, error) {
+			return armstorage.NewBlobContainersClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+		func(subscriptionID string) (*armstorage.FileSharesClient, error) {
+			return armstorage.NewFileSharesClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+		func(subscriptionID string) (*armstorage.ManagementPoliciesClient, error) {
+			return armstorage.NewManagementPoliciesClient(subscriptionID, &azfake.TokenCredential{}, opts)
+		},
+	)
+
+	if _, err := client.GetStorageAccount(accountID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	container, err := client.GetBlobContainer(accountID + "/blobServices/default/containers/velero")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := container.ContainerProperties.ImmutabilityPolicy.Properties
+	if *policy.ImmutabilityPeriodSinceCreationInDays != 30 || *policy.State != armstorage.ImmutabilityPolicyStateLocked {
+		t.Errorf("got immutability policy %+v, want a locked 30-day policy", policy)
+	}
+	if _, err := client.GetFileShare(accountID + "/fileServices/default/shares/data"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetManagementPolicy(accountID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		accountID,
+		accountID + "/blobServices/default/containers/velero",
+		accountID + "/fileServices/default/shares/data",
+		accountID + "/managementPolicies/default",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got request paths %v, want %v", paths, want)
+	}
+}
+
+func Test_ListStorageSKUs(t *testing.T) {
+	const sub = "00000000-0000-0000-0000-000000000000"
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		body := `{"value": [
+			{"name": "Standard_LRS", "resourceType": "storageAccounts", "locations": ["eastus"]},
+			{"name": "Standard_ZRS", "resourceType": "storageAccounts", "locations": ["eastus"], "restrictions": []}
+		]}`
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	azClient, err := armstorage.NewSKUsClient(sub, &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := NewAzureStorageSKUsClient(context.Background(), azClient, sub)
+
+	skus, err := client.ListStorageSKUs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(skus) != 2 || *skus[1].Name != armstorage.SKUNameStandardZRS {
+		t.Errorf("got SKUs %v, want Standard_LRS and Standard_ZRS", skus)
+	}
+	if want := []string{"/subscriptions/" + sub + "/providers/Microsoft.Storage/skus"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
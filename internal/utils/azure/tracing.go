@@ -0,0 +1,92 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/tracing"
+)
+
+// Span attribute keys used for Azure API calls.
+const (
+	AttrScope          = attribute.Key("azure.scope")
+	AttrSubscriptionID = attribute.Key("azure.subscription_id")
+	AttrHTTPMethod     = attribute.Key("http.request.method")
+	AttrHTTPStatusCode = attribute.Key("http.response.status_code")
+	AttrURLPath        = attribute.Key("url.path")
+)
+
+// tracingPolicy is an azcore pipeline policy that records a span for each HTTP request (including
+// each retry) made by an Azure SDK client. The span is a child of the span in the request's
+// context, which is the span of the facade call that made the request.
+type tracingPolicy struct{}
+
+// Do implements policy.Policy.
+func (tracingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if !tracing.Enabled() {
+		return req.Next()
+	}
+
+	raw := req.Raw()
+	ctx, span := tracing.Start(raw.Context(), "HTTP "+raw.Method)
+	defer span.End()
+	span.SetAttributes(
+		AttrHTTPMethod.String(raw.Method),
+		AttrURLPath.String(raw.URL.Path),
+	)
+	if sub := SubscriptionIDFromScope(raw.URL.Path); sub != "" {
+		span.SetAttributes(AttrSubscriptionID.String(sub))
+	}
+
+	resp, err := req.WithContext(ctx).Next()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(AttrHTTPStatusCode.Int(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// startScopeSpan starts the span for a facade call that queries Azure at a scope.
+func startScopeSpan(ctx context.Context, name, scope string) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(ctx, name)
+	if span.IsRecording() {
+		span.SetAttributes(
+			AttrScope.String(scope),
+			AttrSubscriptionID.String(SubscriptionIDFromScope(scope)),
+		)
+	}
+	return ctx, span
+}
+
+// endSpan ends a facade call's span, recording err if it isn't nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SubscriptionIDFromScope extracts the subscription ID from an Azure scope or resource ID (e.g.
+// "/subscriptions/{id}/resourceGroups/{rg}"). Returns an empty string if the scope doesn't
+// contain a subscription.
+func SubscriptionIDFromScope(scope string) string {
+	split := strings.Split(scope, "/")
+	for i := 0; i < len(split)-1; i++ {
+		if strings.EqualFold(split[i], "subscriptions") {
+			return split[i+1]
+		}
+	}
+	return ""
+}
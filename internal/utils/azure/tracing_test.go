@@ -0,0 +1,137 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/tracing"
+)
+
+const roleAssignmentsResp = `{"value": [{
+	"id": "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleAssignments/ra",
+	"properties": {
+		"principalId": "p",
+		"roleDefinitionId": "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/roleDefinitions/rd",
+		"scope": "/subscriptions/00000000-0000-0000-0000-000000000000"
+	}
+}]}`
+
+type transporterMock struct{}
+
+func (transporterMock) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(roleAssignmentsResp)),
+		Request:    req,
+	}, nil
+}
+
+func Test_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer tracing.SetTracerProvider(nil)
+
+	client, err := armauthorization.NewRoleAssignmentsClient("", &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport:        transporterMock{},
+			PerRetryPolicies: []policy.Policy{tracingPolicy{}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scope := "/subscriptions/00000000-0000-0000-0000-000000000000"
+	ctx, ruleSpan := tracing.Start(context.Background(), "rule")
+	roleAssignments, err := NewAzureRoleAssignmentsClient(ctx, client).GetRoleAssignmentsForScope(scope, nil)
+	ruleSpan.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roleAssignments) != 1 {
+		t.Fatalf("got %d role assignments, want 1", len(roleAssignments))
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	rule, facade, httpSpan := spans["rule"], spans["RoleAssignments.ListForScope"], spans["HTTP GET"]
+	if rule == nil || facade == nil || httpSpan == nil {
+		t.Fatalf("missing spans, got %v", spans)
+	}
+	if facade.Parent().SpanID() != rule.SpanContext().SpanID() {
+		t.Errorf("facade span isn't a child of the rule span")
+	}
+	if httpSpan.Parent().SpanID() != facade.SpanContext().SpanID() {
+		t.Errorf("HTTP span isn't a child of the facade span")
+	}
+
+	wantAttrs := []struct {
+		span sdktrace.ReadOnlySpan
+		kv   attribute.KeyValue
+	}{
+		{facade, AttrScope.String(scope)},
+		{facade, AttrSubscriptionID.String("00000000-0000-0000-0000-000000000000")},
+		{httpSpan, AttrSubscriptionID.String("00000000-0000-0000-0000-000000000000")},
+		{httpSpan, AttrHTTPMethod.String(http.MethodGet)},
+		{httpSpan, AttrHTTPStatusCode.Int(http.StatusOK)},
+	}
+	for _, w := range wantAttrs {
+		if !hasAttr(w.span, w.kv) {
+			t.Errorf("span %s: missing attribute %s=%s, got %v", w.span.Name(), w.kv.Key, w.kv.Value.Emit(), w.span.Attributes())
+		}
+	}
+}
+
+func hasAttr(span sdktrace.ReadOnlySpan, kv attribute.KeyValue) bool {
+	for _, a := range span.Attributes() {
+		if a == kv {
+			return true
+		}
+	}
+	return false
+}
+
+func Test_SubscriptionIDFromScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope string
+		want  string
+	}{
+		{
+			name:  "Extracts the subscription ID from a subscription scope.",
+			scope: "/subscriptions/00000000-0000-0000-0000-000000000000",
+			want:  "00000000-0000-0000-0000-000000000000",
+		},
+		{
+			name:  "Extracts the subscription ID from a resource group scope.",
+			scope: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg",
+			want:  "00000000-0000-0000-0000-000000000000",
+		},
+		{
+			name:  "Returns an empty string for a management group scope.",
+			scope: "/providers/Microsoft.Management/managementGroups/mg",
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SubscriptionIDFromScope(tt.scope); got != tt.want {
+				t.Errorf("SubscriptionIDFromScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
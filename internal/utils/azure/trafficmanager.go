@@ -0,0 +1,66 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+)
+
+// AzureTrafficManagerProfilesClient is a facade over the Azure Traffic Manager profiles client.
+// Exists to make our code easier to test. Profiles are identified by their resource IDs.
+type AzureTrafficManagerProfilesClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armtrafficmanager.ProfilesClient, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureTrafficManagerProfilesClient creates a new AzureTrafficManagerProfilesClient (our facade
+// client) that gets the client from the Azure SDK for each subscription from clients.
+func NewAzureTrafficManagerProfilesClient(ctx context.Context, clients func(subscriptionID string) (*armtrafficmanager.ProfilesClient, error)) *AzureTrafficManagerProfilesClient {
+	return &AzureTrafficManagerProfilesClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureTrafficManagerProfilesClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureTrafficManagerProfilesClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetProfile gets a Traffic Manager profile, with its endpoints and their monitor statuses, by
+// its resource ID.
+func (c *AzureTrafficManagerProfilesClient) GetProfile(profileID string) (_ *armtrafficmanager.Profile, err error) {
+	ctx, span := startScopeSpan(c.ctx, "TrafficManagerProfiles.Get", profileID)
+	defer func() { endSpan(span, err) }()
+	id, err := arm.ParseResourceID(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Traffic Manager profile ID %s: %w", profileID, err)
+	}
+	client, err := c.clients(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = allowCall(profileID); err != nil {
+		return nil, err
+	}
+	defer func() { recordCall(profileID, err) }()
+	ctx, rec := withCorrelationIDRecorder(ctx, &c.correlationIDs)
+
+	if err = waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Traffic Manager profile %s: %w", profileID, rec.withCorrelationID(err))
+	}
+	return &resp.Profile, nil
+}
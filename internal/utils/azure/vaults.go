@@ -0,0 +1,101 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// keyVaultAPIVersion is the api-version that Key Vaults and soft-deleted Key Vaults are read with.
+// There's no Azure SDK for Go module for them among the plugin's dependencies, so they're read
+// with the generic resources client.
+const keyVaultAPIVersion = "2023-07-01"
+
+// Vault is a Key Vault, with the properties that rules check.
+type Vault struct {
+	ID         string          `json:"id"`
+	Location   string          `json:"location"`
+	Properties VaultProperties `json:"properties"`
+}
+
+// VaultProperties are the properties of a Key Vault that rules check. Vaults whose properties
+// aren't set have soft delete and purge protection disabled, and retain soft-deleted vaults and
+// objects for 90 days.
+type VaultProperties struct {
+	EnableSoftDelete          *bool  `json:"enableSoftDelete"`
+	EnablePurgeProtection     *bool  `json:"enablePurgeProtection"`
+	SoftDeleteRetentionInDays *int32 `json:"softDeleteRetentionInDays"`
+}
+
+// DeletedVault is a soft-deleted Key Vault, which holds its name until it's recovered or purged.
+type DeletedVault struct {
+	ID         string                 `json:"id"`
+	Properties DeletedVaultProperties `json:"properties"`
+}
+
+// DeletedVaultProperties are the properties of a soft-deleted Key Vault that rules report.
+type DeletedVaultProperties struct {
+	// The resource ID of the vault before it was deleted.
+	VaultID                string     `json:"vaultId"`
+	DeletionDate           *time.Time `json:"deletionDate"`
+	ScheduledPurgeDate     *time.Time `json:"scheduledPurgeDate"`
+	PurgeProtectionEnabled *bool      `json:"purgeProtectionEnabled"`
+}
+
+// AzureKeyVaultsClient is a facade over the generic Azure resources client, for Key Vaults and
+// soft-deleted Key Vaults. Exists to make our code easier to test.
+type AzureKeyVaultsClient struct {
+	ctx            context.Context
+	clients        func(subscriptionID string) (*armresources.Client, error)
+	correlationIDs correlationIDLog
+}
+
+// NewAzureKeyVaultsClient creates a new AzureKeyVaultsClient (our facade client) that gets the
+// client from the Azure SDK for each subscription from clients.
+func NewAzureKeyVaultsClient(ctx context.Context, clients func(subscriptionID string) (*armresources.Client, error)) *AzureKeyVaultsClient {
+	return &AzureKeyVaultsClient{
+		ctx:     ctx,
+		clients: clients,
+	}
+}
+
+// CorrelationIDs returns the correlation request IDs of all ARM responses the client has received.
+func (c *AzureKeyVaultsClient) CorrelationIDs() []string {
+	return c.correlationIDs.all()
+}
+
+// APIVersions returns the versions of the APIs that served the client's requests, and of the SDK
+// modules that made them.
+func (c *AzureKeyVaultsClient) APIVersions() []string {
+	return c.correlationIDs.apiVersions()
+}
+
+// GetVault gets a Key Vault by its subscription, resource group, and name.
+func (c *AzureKeyVaultsClient) GetVault(subscriptionID, resourceGroup, vaultName string) (*Vault, error) {
+	client, err := c.clients(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.KeyVault/vaults/%s", subscriptionID, resourceGroup, vaultName)
+	vault := &Vault{}
+	if err := getGenericResource(c.ctx, client, &c.correlationIDs, "Vaults.Get", "Key Vault", id, keyVaultAPIVersion, vault); err != nil {
+		return nil, err
+	}
+	return vault, nil
+}
+
+// GetDeletedVault gets the soft-deleted Key Vault with a name in a subscription and location.
+func (c *AzureKeyVaultsClient) GetDeletedVault(subscriptionID, location, vaultName string) (*DeletedVault, error) {
+	client, err := c.clients(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.KeyVault/locations/%s/deletedVaults/%s", subscriptionID, location, vaultName)
+	vault := &DeletedVault{}
+	if err := getGenericResource(c.ctx, client, &c.correlationIDs, "Vaults.GetDeleted", "soft-deleted Key Vault", id, keyVaultAPIVersion, vault); err != nil {
+		return nil, err
+	}
+	return vault, nil
+}
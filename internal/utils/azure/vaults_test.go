@@ -0,0 +1,74 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+func Test_GetKeyVaults(t *testing.T) {
+	const (
+		vaultPath   = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"
+		deletedPath = "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.KeyVault/locations/eastus/deletedVaults/kv"
+	)
+	bodies := map[string]string{
+		vaultPath:   `{"id": "` + vaultPath + `", "location": "eastus", "properties": {"enableSoftDelete": true, "enablePurgeProtection": true, "softDeleteRetentionInDays": 30}}`,
+		deletedPath: `{"id": "` + deletedPath + `", "properties": {"vaultId": "` + vaultPath + `", "deletionDate": "2024-03-01T12:00:00Z", "scheduledPurgeDate": "2024-05-30T12:00:00Z", "purgeProtectionEnabled": false}}`,
+	}
+	var requests []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.Path+"?api-version="+req.URL.Query().Get("api-version"))
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(bodies[req.URL.Path])),
+			Request:    req,
+		}, nil
+	})
+	opts := &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: transport,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	}
+	client := NewAzureKeyVaultsClient(context.Background(), func(subscriptionID string) (*armresources.Client, error) {
+		return armresources.NewClient(subscriptionID, &azfake.TokenCredential{}, opts)
+	})
+
+	vault, err := client.GetVault("00000000-0000-0000-0000-000000000000", "rg", "kv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := vault.Properties; !*p.EnableSoftDelete || !*p.EnablePurgeProtection || *p.SoftDeleteRetentionInDays != 30 {
+		t.Errorf("got vault properties %+v, want soft delete, purge protection, and 30 days of retention", p)
+	}
+	deleted, err := client.GetDeletedVault("00000000-0000-0000-0000-000000000000", "eastus", "kv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted.Properties.VaultID != vaultPath {
+		t.Errorf("got vault ID %s, want %s", deleted.Properties.VaultID, vaultPath)
+	}
+	if want := time.Date(2024, 5, 30, 12, 0, 0, 0, time.UTC); deleted.Properties.ScheduledPurgeDate == nil || !deleted.Properties.ScheduledPurgeDate.Equal(want) {
+		t.Errorf("got scheduled purge date %v, want %v", deleted.Properties.ScheduledPurgeDate, want)
+	}
+
+	wantRequests := []string{
+		vaultPath + "?api-version=2023-07-01",
+		deletedPath + "?api-version=2023-07-01",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("got requests %v, want %v", requests, wantRequests)
+	}
+}
@@ -0,0 +1,1006 @@
+package messages
+
+// seeFailures ends the condition message of a rule that failed, whose failures say why.
+const seeFailures = " See failures for details."
+
+// The condition messages of each type of rule, for when it succeeded and when it failed. Dashboards
+// and alerts match on them, so they only change with the rule's behavior, and rules of the same
+// type always use the same ones. Messages with verbs are rendered with fmt.Sprintf.
+const (
+	RBACSucceeded = "Principal has all required permissions."
+	RBACFailed    = "Principal lacks required permissions." + seeFailures
+
+	KeyVaultCertificateSucceeded = "All certificates are valid."
+	KeyVaultCertificateFailed    = "One or more certificates are missing, disabled, expiring soon, or not valid for the required DNS names." + seeFailures
+
+	AKSClusterSucceeded = "Cluster has all expected properties."
+	AKSClusterFailed    = "Cluster lacks expected properties." + seeFailures
+
+	NATGatewaySucceeded = "All subnets have outbound connectivity through a NAT gateway."
+	NATGatewayFailed    = "One or more subnets lack a provisioned NAT gateway with outbound IPs." + seeFailures
+
+	VNetPeeringSucceeded = "Virtual network has all expected peerings."
+	VNetPeeringFailed    = "Virtual network lacks expected peerings, or they're disconnected or misconfigured." + seeFailures
+
+	RouteTableSucceeded = "Route table has all required routes."
+	RouteTableFailed    = "Route table is missing, lacks required routes, or has forbidden routes." + seeFailures
+
+	ImageCompatibilitySucceeded = "All VM sizes can run the image."
+	ImageCompatibilityFailed    = "One or more VM sizes can't run the image." + seeFailures
+
+	ImageReplicationSucceeded = "Image version is replicated to all regions."
+	ImageReplicationFailed    = "Image version isn't replicated to one or more regions." + seeFailures
+
+	VMSecuritySucceeded = "All VM sizes support the %s security type."
+	VMSecurityFailed    = "One or more VM sizes or the image don't support the %s security type." + seeFailures
+
+	VMSizeSucceeded = "All VM sizes have the required capabilities."
+	VMSizeFailed    = "One or more VM sizes lack required capabilities." + seeFailures
+
+	DiskZoneSucceeded = "VM size %s can attach %s disks in all zones."
+	DiskZoneFailed    = "VM size %s can't attach %s disks in one or more zones." + seeFailures
+
+	TemplatePermissionSucceeded = "Principal has all permissions required to deploy the template."
+	TemplatePermissionFailed    = "Principal lacks permissions required to deploy the template." + seeFailures
+
+	PolicyExemptionSucceeded = "All expected policy exemptions apply to the scope."
+	PolicyExemptionFailed    = "One or more policy exemptions are missing, have the wrong category, or expire soon." + seeFailures
+
+	DefenderPlanSucceeded = "All Defender plans have the expected pricing tier."
+	DefenderPlanFailed    = "One or more Defender plans are missing or lack the expected pricing tier." + seeFailures
+
+	BudgetSucceeded = "A budget that meets all expectations is defined at the scope."
+	BudgetFailed    = "No budget that meets all expectations is defined at the scope." + seeFailures
+
+	ResourceLockSucceeded = "All scopes have the expected locks."
+	ResourceLockFailed    = "One or more scopes lack a required lock or have a forbidden lock." + seeFailures
+
+	FirewallPolicySucceeded = "Firewall policy allows all expected traffic."
+	FirewallPolicyFailed    = "Firewall policy is missing, doesn't allow expected traffic, or denies it." + seeFailures
+
+	BastionSucceeded = "Virtual network has a provisioned Bastion host in a large enough AzureBastionSubnet."
+	BastionFailed    = "Virtual network lacks an AzureBastionSubnet of /26 or larger with a provisioned Bastion host." + seeFailures
+
+	DDoSProtectionSucceeded = "All virtual networks are protected by the expected DDoS protection plan."
+	DDoSProtectionFailed    = "One or more virtual networks lack DDoS protection or use another DDoS protection plan." + seeFailures
+
+	PublicIPPrefixSucceeded = "Public IP prefix has enough free addresses."
+	PublicIPPrefixFailed    = "Public IP prefix is missing, has too few free addresses, or isn't configured as expected." + seeFailures
+
+	EventHubSucceeded = "Event Hubs namespace has the expected event hub and authorization rule."
+	EventHubFailed    = "Event Hubs namespace, event hub, or authorization rule is missing or isn't configured as expected." + seeFailures
+
+	ApplicationGatewaySucceeded = "Application gateway is provisioned and configured as expected."
+	ApplicationGatewayFailed    = "Application gateway is missing, not provisioned, or not configured as expected." + seeFailures
+
+	CosmosDBSucceeded = "Cosmos DB account is configured as expected."
+	CosmosDBFailed    = "Cosmos DB account is missing or isn't configured as expected." + seeFailures
+
+	SQLServerSucceeded = "SQL server is configured as expected."
+	SQLServerFailed    = "SQL server is missing or isn't configured as expected." + seeFailures
+
+	GlobalEndpointSucceeded = "Global entry point is enabled and all expected endpoints are healthy."
+	GlobalEndpointFailed    = "Global entry point is missing, disabled, or has endpoints that aren't healthy." + seeFailures
+
+	ExpressRouteSucceeded = "ExpressRoute circuit is provisioned and connected as expected."
+	ExpressRouteFailed    = "ExpressRoute circuit or its connection is missing, not provisioned, or not connected." + seeFailures
+
+	VPNGatewaySucceeded = "VPN gateway is configured as expected and all expected connections are connected."
+	VPNGatewayFailed    = "VPN gateway is missing or misconfigured, or has connections that aren't connected." + seeFailures
+
+	NetworkWatcherSucceeded = "Network watcher is enabled and all expected flow logs are configured."
+	NetworkWatcherFailed    = "Network watcher is missing, or flow logs are missing or misconfigured." + seeFailures
+
+	ProximityPlacementGroupSucceeded = "Proximity placement group is colocated and allows all expected VM sizes."
+	ProximityPlacementGroupFailed    = "Proximity placement group is missing, not colocated, or doesn't allow expected VM sizes." + seeFailures
+
+	EncryptionAtHostSucceeded = "Encryption at host is registered and all VM sizes support it."
+	EncryptionAtHostFailed    = "Encryption at host isn't registered, or one or more VM sizes don't support it." + seeFailures
+
+	GroupMembershipSucceeded = "Group exists and has all expected members."
+	GroupMembershipFailed    = "Group is missing or lacks expected members." + seeFailures
+
+	AppPermissionSucceeded = "App registration requests all expected permissions, with admin consent."
+	AppPermissionFailed    = "App registration is missing, or doesn't request expected permissions or lacks admin consent for them." + seeFailures
+
+	BlobContainerSucceeded = "Blob container has the expected immutability policy and lifecycle management rule."
+	BlobContainerFailed    = "Blob container or lifecycle management rule is missing, or isn't configured as expected." + seeFailures
+
+	StorageNetworkSucceeded = "Storage account denies network access by default, and allows it from the expected subnets and IP ranges."
+	StorageNetworkFailed    = "Storage account is missing, allows network access by default, or doesn't allow it from expected subnets or IP ranges." + seeFailures
+
+	FileShareSucceeded = "Storage account has all expected file shares, with enough quota and the expected access tier."
+	FileShareFailed    = "One or more file shares are missing, or lack quota or the expected access tier." + seeFailures
+
+	SubnetDelegationSucceeded = "All subnets are free of conflicting delegations and service association links, and have the expected network policies."
+	SubnetDelegationFailed    = "One or more subnets are missing, delegated or linked to other services, or have unexpected network policies." + seeFailures
+
+	MonitorAlertSucceeded = "Action group is enabled with the expected receivers, and all metric alert rules exist and are enabled."
+	MonitorAlertFailed    = "Action group is missing, disabled, or lacks receivers, or one or more metric alert rules are missing or disabled." + seeFailures
+
+	ActivityLogExportSucceeded = "Subscription's Activity Log is exported to each expected destination with the expected categories."
+	ActivityLogExportFailed    = "Subscription's Activity Log isn't exported to one or more destinations, or is exported without one or more categories." + seeFailures
+
+	ImageDeprecationSucceeded = "No image is deprecated or scheduled for deprecation within the horizon."
+	ImageDeprecationFailed    = "One or more images are missing, deprecated, or scheduled for deprecation within the horizon." + seeFailures
+
+	ZoneRedundancySucceeded = "Location %s has enough availability zones, and the services can be zone-redundant there."
+	ZoneRedundancyFailed    = "Location %s lacks availability zones for compute, VM sizes, or services." + seeFailures
+
+	CustomLocationSucceeded = "Custom location is provisioned, with its cluster extensions provisioned and its host connected."
+	CustomLocationFailed    = "Custom location, its cluster extensions, or its host are missing, not provisioned, or not connected." + seeFailures
+
+	KeyVaultRecoverySucceeded = "Key Vault name is held by a soft-deleted vault only as required, and the vault has the required soft delete and purge protection."
+	KeyVaultRecoveryFailed    = "Key Vault name is held by an unexpected soft-deleted vault, or by none to recover, or the vault lacks soft delete or purge protection." + seeFailures
+
+	PreflightSucceeded = "Plugin can read role assignments and role definitions at all scopes."
+	PreflightFailed    = "Plugin lacks access needed to validate permissions at one or more scopes." + seeFailures
+
+	WorkloadIdentitySucceeded  = "Plugin's service account token matches a federated identity credential of its workload identity."
+	WorkloadIdentityUnverified = "Plugin's service account token couldn't be compared with the federated identity credentials of its workload identity. See details."
+	WorkloadIdentityFailed     = "Plugin's service account token doesn't match any federated identity credential of its workload identity." + seeFailures
+
+	SpecSucceeded = "No rule's spec has problems that keep it from being evaluated."
+	SpecFailed    = "One or more rules weren't evaluated, because their specs have problems." + seeFailures
+)
+
+// RuleSpecInvalid is the condition message of a rule that wasn't evaluated, because its spec has
+// problems.
+const RuleSpecInvalid = "Rule wasn't evaluated, because its spec has problems." + seeFailures
+
+// The failures of RBAC, template permission, and preflight rules, besides the permission failures
+// that Templates render. Failures with verbs are rendered with fmt.Sprintf.
+const (
+	// RuleSpecFailure is rendered with the number of problems with a rule's spec, and the rule
+	// name of the spec condition, which lists them.
+	RuleSpecFailure = "Rule's spec has %d problem(s), which the %s condition lists."
+	// SpecProblemFailure is rendered with a rule's name and a problem with its spec.
+	SpecProblemFailure = "Rule %s: %s"
+	// PrincipalFailure is rendered with a principal and one of its failures, for rules with more
+	// than one principal.
+	PrincipalFailure = "Principal %s: %s"
+	// PrincipalClientIDFailure is rendered with the client ID of a rule's principal that no
+	// service principal has.
+	PrincipalClientIDFailure = "No service principal found for client ID %s."
+	// PrincipalClientIDUnresolvedFailure is rendered with the client ID of a rule's principal,
+	// when Microsoft Graph can't be queried to resolve it.
+	PrincipalClientIDUnresolvedFailure = "Client ID %s couldn't be resolved to a service principal, because Microsoft Graph couldn't be queried. Set principalId to the service principal's object ID instead."
+	// ScopePatternFailure is rendered with a scope pattern.
+	ScopePatternFailure = "Scope pattern %s matched no resource groups."
+	// ManagementGroupFailure is rendered with a management group ID.
+	ManagementGroupFailure = "Management group %s has no subscriptions."
+	// RoleAssignmentQuotaFailure is rendered with a subscription ID, the number of role
+	// assignments it has room for, and the number required.
+	RoleAssignmentQuotaFailure = "Subscription %s has room for %d more role assignment(s), fewer than the required %d, so creating the role assignments needed to fix this rule may fail."
+	// CreationWindowFailure is rendered with Action or DataAction, the Action, its scope, the
+	// creation window, and the role assignments that permit the Action outside of it.
+	CreationWindowFailure = "%s %s at scope %s is only permitted by role assignments not created %s: %s."
+	// AssignmentAssertionFailure is rendered with Action or DataAction, the Action, its scope, the
+	// permission set's assertions about role assignments, and the role assignments that permit
+	// the Action without satisfying all of them.
+	AssignmentAssertionFailure = "%s %s at scope %s is only permitted by role assignments that don't satisfy all of the permission set's assertions (%s): %s."
+	// TemplateExpressionTypeFailure is rendered with a resource's type and name.
+	TemplateExpressionTypeFailure = "Type %s of resource %s is a template expression, so the Actions needed to deploy it can't be derived."
+	// LinkedTemplateFailure is rendered with the name of a nested deployment.
+	LinkedTemplateFailure = "Nested deployment %s links to its template, so the Actions needed to deploy it can't be derived."
+	// PreflightFailure is rendered with the Actions the plugin lacks, and the scope.
+	PreflightFailure = "Plugin lacks %s at scope %s."
+)
+
+// The failures of rules of more than one type.
+const (
+	// ScopeNotFoundFailure is rendered with a scope.
+	ScopeNotFoundFailure = "Scope %s not found."
+	// VirtualNetworkNotFoundFailure is rendered with a virtual network's ID.
+	VirtualNetworkNotFoundFailure = "Virtual network %s not found."
+	// VMSizeUnavailableFailure is rendered with a VM size and a location.
+	VMSizeUnavailableFailure = "VM size %s is not available in location %s."
+	// VMSizeCheckFailure is rendered with a VM size and the error that kept its capabilities from
+	// being checked.
+	VMSizeCheckFailure = "Failed to check VM size %s: %v."
+	// GatewayConnectionNotFoundFailure is rendered with a virtual network gateway connection's ID.
+	GatewayConnectionNotFoundFailure = "Virtual network gateway connection %s not found."
+	// GatewayConnectionStatusFailure is rendered with a virtual network gateway connection's ID and
+	// connection status.
+	GatewayConnectionStatusFailure = "Virtual network gateway connection %s has connection status %s, not Connected."
+	// ImageDefinitionNotFoundFailure is rendered with an image definition's ID.
+	ImageDefinitionNotFoundFailure = "Image definition %s not found."
+	// SubnetNotFoundFailure is rendered with a subnet's ID.
+	SubnetNotFoundFailure = "Subnet %s not found."
+)
+
+// The failures of Key Vault certificate rules.
+const (
+	// CertificateNotFoundFailure is rendered with a certificate's name and its vault's URI.
+	CertificateNotFoundFailure = "Certificate %s not found in vault %s."
+	// CertificateDisabledFailure is rendered with a certificate's name.
+	CertificateDisabledFailure = "Certificate %s is disabled."
+	// CertificateExpiredFailure is rendered with a certificate's name and when it expired, in RFC
+	// 3339.
+	CertificateExpiredFailure = "Certificate %s expired at %s."
+	// CertificateExpiringFailure is rendered with a certificate's name, when it expires, in RFC
+	// 3339, and the minimum remaining validity.
+	CertificateExpiringFailure = "Certificate %s expires at %s, which is sooner than the required minimum remaining validity of %s."
+	// CertificateDNSNameFailure is rendered with a certificate's name and a DNS name.
+	CertificateDNSNameFailure = "Certificate %s is not valid for DNS name %s: neither its subject nor its subject alternative names contain it."
+)
+
+// The failures of AKS cluster rules.
+const (
+	// AKSClusterNotFoundFailure is rendered with a cluster's ID.
+	AKSClusterNotFoundFailure = "Cluster %s not found."
+	// AKSClusterPropertyFailure is rendered with a cluster property, its expected value, and its
+	// value.
+	AKSClusterPropertyFailure = "Expected %s to be %s, but it is %s."
+	// AKSClusterVersionFailure is rendered with the minimum Kubernetes version and the cluster's.
+	AKSClusterVersionFailure = "Expected kubernetesVersion to be at least %s, but it is %s."
+)
+
+// The failures of NAT gateway rules.
+const (
+	// NATGatewayMissingFailure is rendered with a subnet's ID.
+	NATGatewayMissingFailure = "Subnet %s has no NAT gateway."
+	// NATGatewayNotFoundFailure is rendered with a NAT gateway's ID and its subnet's.
+	NATGatewayNotFoundFailure = "NAT gateway %s of subnet %s not found."
+	// NATGatewayProvisioningFailure is rendered with a NAT gateway's ID, its subnet's, and its
+	// provisioning state.
+	NATGatewayProvisioningFailure = "NAT gateway %s of subnet %s is not provisioned: its provisioning state is %s."
+	// NATGatewayNoOutboundIPsFailure is rendered with a NAT gateway's ID and its subnet's.
+	NATGatewayNoOutboundIPsFailure = "NAT gateway %s of subnet %s has no outbound IPs: it has no public IP addresses or public IP prefixes."
+	// NATGatewayOutboundIPsFailure is rendered with a NAT gateway's ID, its subnet's, its number of
+	// public IP addresses and prefixes, and the required number.
+	NATGatewayOutboundIPsFailure = "NAT gateway %s of subnet %s has %d public IP address(es) and prefix(es), fewer than the required %d."
+)
+
+// The failures of VNet peering rules.
+const (
+	// PeeringMissingFailure is rendered with a virtual network's ID and the ID of its expected
+	// remote virtual network.
+	PeeringMissingFailure = "Virtual network %s has no peering with %s."
+	// PeeringSettingFailure is rendered with a peering setting, the peering's name, its remote
+	// virtual network's ID, the setting's expected value, and its value.
+	PeeringSettingFailure = "Expected %s of peering %s with %s to be %s, but it is %s."
+)
+
+// The failures of route table rules.
+const (
+	// RouteTableMissingFailure is rendered with a subnet's ID.
+	RouteTableMissingFailure = "Subnet %s has no route table."
+	// RouteTableNotFoundFailure is rendered with a route table's ID.
+	RouteTableNotFoundFailure = "Route table %s not found."
+	// RouteMissingFailure is rendered with a route table's ID and an address prefix.
+	RouteMissingFailure = "Route table %s has no route for %s."
+	// RouteNextHopFailure is rendered with a route's name, its address prefix, its route table's
+	// ID, the expected next hop, and its next hop.
+	RouteNextHopFailure = "Expected route %s for %s in route table %s to have next hop %s, but it has next hop %s."
+	// DefaultRouteFailure is rendered with a route table's ID, and a default route's name, address
+	// prefix, and next hop.
+	DefaultRouteFailure = "Route table %s has a default route %s for %s with next hop %s, but default routes are forbidden."
+)
+
+// The failures of image compatibility rules.
+const (
+	// ImageIncompatibleFailure is rendered with an image definition's ID, a VM size, and one of the
+	// incompatibilities below.
+	ImageIncompatibleFailure = "Image %s is incompatible with VM size %s: %s"
+	// HyperVGenerationIncompatibility is rendered with an image's Hyper-V generation and those a VM
+	// size supports.
+	HyperVGenerationIncompatibility = "the image's Hyper-V generation is %s, but the size only supports %s."
+	// ArchitectureIncompatibility is rendered with an image's architecture and a VM size's CPU
+	// architecture.
+	ArchitectureIncompatibility = "the image's architecture is %s, but the size's CPU architecture is %s."
+	// TrustedLaunchIncompatibility is the incompatibility of a VM size without trusted launch with
+	// an image that requires it.
+	TrustedLaunchIncompatibility = "the image requires trusted launch, but the size doesn't support it."
+	// ConfidentialVMIncompatibility is the incompatibility of a VM size without confidential
+	// computing with an image that requires it.
+	ConfidentialVMIncompatibility = "the image requires a confidential VM, but the size doesn't support confidential computing."
+)
+
+// The failures of image replication rules.
+const (
+	// ImageVersionNotFoundFailure is rendered with an image version's ID.
+	ImageVersionNotFoundFailure = "Image version %s not found."
+	// ImageTargetRegionFailure is rendered with a region and an image version's ID.
+	ImageTargetRegionFailure = "Region %s isn't a target region of image version %s."
+	// ImageReplicaCountFailure is rendered with an image version's ID, its number of replicas in a
+	// region, the region, and the required number.
+	ImageReplicaCountFailure = "Image version %s has %d replicas in region %s, fewer than %d."
+	// ImageReplicationUnknownFailure is rendered with an image version's ID and a region.
+	ImageReplicationUnknownFailure = "Replication status of image version %s in region %s is unknown."
+	// ImageDefinitionVersionConstraintFailure is rendered with an image version, its image
+	// definition's ID, and the error parsing the version.
+	ImageDefinitionVersionConstraintFailure = "Version %q of image definition %s isn't a version, a version range, or latest: %v."
+	// ImageDefinitionVersionUnsatisfiedFailure is rendered with an image definition's ID, a version
+	// constraint, its number of versions, the number of them that aren't excluded from latest, and
+	// those versions.
+	ImageDefinitionVersionUnsatisfiedFailure = "No version of image definition %s satisfies %s: of its %d versions, %d aren't excluded from latest: %s."
+	// ImageNotReplicatedFailure is rendered with an image version's ID, a region, and its
+	// ReplicationState, ReplicationProgress, and ReplicationDetails, joined with commas.
+	ImageNotReplicatedFailure = "Image version %s isn't replicated to region %s: %s."
+	// ReplicationState is rendered with the replication state of an image version in a region.
+	ReplicationState = "replication state %s"
+	// ReplicationProgress is rendered with the percentage of an image version's replication to a
+	// region that's done.
+	ReplicationProgress = "progress %d%%"
+	// ReplicationDetails is rendered with the details ARM reports about an image version's
+	// replication to a region, without a trailing period.
+	ReplicationDetails = "details: %s"
+)
+
+// The failures of VM security rules.
+const (
+	// ImageSecurityTypeFailure is rendered with an image definition's ID, its security type, and
+	// the required one.
+	ImageSecurityTypeFailure = "Image definition %s has security type %s, which doesn't support %s."
+	// VMSizeGenerationFailure is rendered with a VM size, the name of the Hyper-V generations
+	// capability, a security type, and the size's Hyper-V generations.
+	VMSizeGenerationFailure = "VM size %s lacks capability %s=V2, which %s requires; it has %s."
+	// VMSizeTrustedLaunchFailure is rendered with a VM size, and the name and value of its
+	// capability.
+	VMSizeTrustedLaunchFailure = "VM size %s doesn't support trusted launch: its capability %s is %s."
+	// VMSizeConfidentialMissingFailure is rendered with a VM size and the name of the capability it
+	// lacks.
+	VMSizeConfidentialMissingFailure = "VM size %s doesn't support confidential VMs: it lacks capability %s."
+	// VMSizeConfidentialTypeFailure is rendered with a VM size, the name and value of its
+	// capability, and the expected value.
+	VMSizeConfidentialTypeFailure = "VM size %s has capability %s=%s, not %s."
+)
+
+// The failures of VM size rules.
+const (
+	// AcceleratedNetworkingMissingFailure is rendered with a VM size and the name of the capability
+	// it lacks.
+	AcceleratedNetworkingMissingFailure = "VM size %s doesn't support accelerated networking: it lacks capability %s."
+	// AcceleratedNetworkingUnsupportedFailure is rendered with a VM size, and the name and value of
+	// its capability.
+	AcceleratedNetworkingUnsupportedFailure = "VM size %s doesn't support accelerated networking: its capability %s is %s."
+	// VMSizeCapabilityMissingFailure is rendered with a VM size and the name of the capability it
+	// lacks.
+	VMSizeCapabilityMissingFailure = "VM size %s lacks capability %s."
+	// VMSizeCapabilityFailure is rendered with a VM size, the name and value of its capability, and
+	// the required minimum.
+	VMSizeCapabilityFailure = "VM size %s has capability %s=%d, but at least %d is required."
+)
+
+// The failures of disk zone rules.
+const (
+	// DiskTypeUnavailableFailure is rendered with a disk type and a location.
+	DiskTypeUnavailableFailure = "Disk type %s is not available in location %s."
+	// DiskZoneFailure is rendered with a VM size, a disk type, a zone, and the reasons it can't
+	// attach the disks there, from DiskZoneReasons, joined with semicolons.
+	DiskZoneFailure = "VM size %s can't attach %s disks in zone %s: %s."
+	// DiskZonesFailure is rendered with a VM size, a disk type, the zones where the size can attach
+	// the disks, and the zones where it can't.
+	DiskZonesFailure = "Zones where VM size %s can attach %s disks: %s. Zones where it can't: %s."
+	// VMSizeNotInZone is rendered with a VM size.
+	VMSizeNotInZone = "VM size %s isn't offered there"
+	// DiskTypeNotInZone is rendered with a disk type.
+	DiskTypeNotInZone = "disk type %s isn't offered there"
+	// VMSizeZoneCapabilityMissing is rendered with a VM size and the name of the capability it
+	// lacks in a zone.
+	VMSizeZoneCapabilityMissing = "VM size %s lacks capability %s=True there"
+	// VMSizeCapabilityNotTrue is rendered with a VM size and the name of the capability it lacks in
+	// all zones.
+	VMSizeCapabilityNotTrue = "VM size %s lacks capability %s=True"
+)
+
+// The failures of policy exemption rules.
+const (
+	// PolicyExemptionMissingFailure is rendered with a policy assignment's ID and a scope.
+	PolicyExemptionMissingFailure = "No policy exemption for policy assignment %s applies to scope %s."
+	// PolicyExemptionCategoryFailure is rendered with a policy exemption's ID, its policy
+	// assignment's, its category, and the required one.
+	PolicyExemptionCategoryFailure = "Policy exemption %s for policy assignment %s has category %s, but category %s is required."
+	// PolicyExemptionExpiredFailure is rendered with a policy exemption's ID, its policy
+	// assignment's, and when it expired, in RFC 3339.
+	PolicyExemptionExpiredFailure = "Policy exemption %s for policy assignment %s expired at %s."
+	// PolicyExemptionExpiringFailure is rendered with a policy exemption's ID, its policy
+	// assignment's, when it expires, in RFC 3339, and the minimum remaining validity.
+	PolicyExemptionExpiringFailure = "Policy exemption %s for policy assignment %s expires at %s, which is sooner than the required minimum remaining validity of %s."
+)
+
+// The failures of Defender plan rules.
+const (
+	// SubscriptionNotFoundFailure is rendered with a subscription ID.
+	SubscriptionNotFoundFailure = "Subscription %s not found."
+	// DefenderPlanNotFoundFailure is rendered with a Defender plan's name and a subscription ID.
+	DefenderPlanNotFoundFailure = "Plan %s not found in subscription %s."
+	// DefenderPlanTierFailure is rendered with a Defender plan's name, the expected pricing tier,
+	// and its pricing tier.
+	DefenderPlanTierFailure = "Expected plan %s to have pricing tier %s, but it has pricing tier %s."
+	// DefenderPlanSubPlanFailure is rendered with a Defender plan's name, the expected sub-plan,
+	// and its sub-plan.
+	DefenderPlanSubPlanFailure = "Expected plan %s to have sub-plan %s, but it has sub-plan %s."
+)
+
+// The failures of budget rules.
+const (
+	// BudgetMissingFailure is rendered with a scope.
+	BudgetMissingFailure = "No budget is defined at scope %s."
+	// BudgetMinAmountFailure is rendered with a budget's name, its amount, and the minimum amount.
+	BudgetMinAmountFailure = "Budget %s has amount %s, but an amount of at least %d is required."
+	// BudgetMaxAmountFailure is rendered with a budget's name, its amount, and the maximum amount.
+	BudgetMaxAmountFailure = "Budget %s has amount %s, but an amount of at most %d is required."
+	// BudgetTimeGrainFailure is rendered with a budget's name, its time grain, and the required
+	// one.
+	BudgetTimeGrainFailure = "Budget %s has time grain %s, but time grain %s is required."
+	// BudgetNotificationFailure is rendered with a budget's name.
+	BudgetNotificationFailure = "Budget %s has no enabled notification with a contact email, group, or role."
+)
+
+// The failures of resource lock rules.
+const (
+	// LockMissingFailure is rendered with a scope, the required lock level, and the levels of the
+	// locks that apply to it.
+	LockMissingFailure = "Scope %s requires a %s lock, but the locks that apply to it have levels: %s."
+	// ReadOnlyLockFailure is rendered with a scope and the ReadOnly locks that apply to it.
+	ReadOnlyLockFailure = "Scope %s must not have a ReadOnly lock, but ReadOnly locks %s apply to it."
+)
+
+// The failures of firewall policy rules.
+const (
+	// FirewallPolicyNotFoundFailure is rendered with a firewall policy's ID.
+	FirewallPolicyNotFoundFailure = "Firewall policy %s not found."
+	// RuleCollectionGroupNotFoundFailure is rendered with a firewall policy's ID and a rule
+	// collection group's name.
+	RuleCollectionGroupNotFoundFailure = "Firewall policy %s has no rule collection group %s."
+	// FirewallTrafficNotAllowedFailure is rendered with a firewall policy's ID and a
+	// FirewallTraffic.
+	FirewallTrafficNotAllowedFailure = "Firewall policy %s doesn't allow %s."
+	// FirewallTrafficDeniedFirstFailure is rendered with a firewall policy's ID, a FirewallTraffic,
+	// the FirewallRuleMatch that denies it, and the one that allows it later.
+	FirewallTrafficDeniedFirstFailure = "Firewall policy %s denies %s with %s, before %s allows it."
+	// FirewallTrafficDeniedFailure is rendered with a firewall policy's ID, a FirewallTraffic, and
+	// the FirewallRuleMatch that denies it.
+	FirewallTrafficDeniedFailure = "Firewall policy %s denies %s with %s."
+)
+
+// The failures of Bastion rules.
+const (
+	// BastionSubnetNotFoundFailure is rendered with the name of the Bastion subnet and a virtual
+	// network's ID.
+	BastionSubnetNotFoundFailure = "Subnet %s not found in virtual network %s."
+	// BastionSubnetNoPrefixFailure is rendered with a subnet's ID and the number of addresses Azure
+	// Bastion requires.
+	BastionSubnetNoPrefixFailure = "Subnet %s has no IPv4 address prefix, but Azure Bastion requires a /26 (%d addresses) or larger."
+	// BastionSubnetPrefixFailure is rendered with a subnet's ID, its largest address prefix and the
+	// number of addresses in it, and the number Azure Bastion requires.
+	BastionSubnetPrefixFailure = "Subnet %s has address prefix %s (%d addresses), but Azure Bastion requires a /26 (%d addresses) or larger."
+	// BastionHostMissingFailure is rendered with a subnet's ID.
+	BastionHostMissingFailure = "No Bastion host is associated with subnet %s."
+	// BastionHostNotFoundFailure is rendered with a Bastion host's ID and its subnet's.
+	BastionHostNotFoundFailure = "Bastion host %s of subnet %s not found."
+	// BastionHostProvisioningFailure is rendered with a Bastion host's ID, its subnet's, and its
+	// provisioning state.
+	BastionHostProvisioningFailure = "Bastion host %s of subnet %s is not provisioned: its provisioning state is %s."
+	// BastionHostSKUFailure is rendered with a Bastion host's ID, its subnet's, its SKU, and the
+	// expected one.
+	BastionHostSKUFailure = "Bastion host %s of subnet %s has SKU %s, not %s."
+)
+
+// The failures of DDoS protection rules.
+const (
+	// DDoSProtectionDisabledFailure is rendered with a virtual network's ID and its
+	// DDoSPlanAssociation or DDoSPlanNotAssociated.
+	DDoSProtectionDisabledFailure = "Virtual network %s doesn't have DDoS protection enabled; %s."
+	// DDoSProtectionNoPlanFailure is rendered with a virtual network's ID and
+	// DDoSPlanNotAssociated.
+	DDoSProtectionNoPlanFailure = "Virtual network %s has DDoS protection enabled, but %s."
+	// DDoSProtectionPlanFailure is rendered with a virtual network's ID, the ID of its DDoS
+	// protection plan, and the ID of the expected one.
+	DDoSProtectionPlanFailure = "Virtual network %s is associated with DDoS protection plan %s, not %s."
+	// DDoSPlanAssociation is rendered with the ID of a virtual network's DDoS protection plan.
+	DDoSPlanAssociation = "it's associated with DDoS protection plan %s"
+	// DDoSPlanNotAssociated is the DDoS protection plan association of a virtual network without
+	// one.
+	DDoSPlanNotAssociated = "it isn't associated with a DDoS protection plan"
+)
+
+// The failures of public IP prefix rules.
+const (
+	// PublicIPPrefixNotFoundFailure is rendered with a public IP prefix's ID.
+	PublicIPPrefixNotFoundFailure = "Public IP prefix %s not found."
+	// PublicIPPrefixFreeAddressesFailure is rendered with a public IP prefix's ID, its number of
+	// free addresses, its number of addresses, and the required number of free addresses.
+	PublicIPPrefixFreeAddressesFailure = "Public IP prefix %s has %d free address(es) of %d, fewer than the required %d."
+	// PublicIPPrefixNoPrefixFailure is rendered with a public IP prefix's ID.
+	PublicIPPrefixNoPrefixFailure = "Public IP prefix %s has no IP prefix or prefix length."
+	// PublicIPPrefixZonesFailure is rendered with a public IP prefix's ID, the zones it isn't in,
+	// and the zones it's in.
+	PublicIPPrefixZonesFailure = "Public IP prefix %s isn't in zone(s) %s: it's in %s."
+	// PublicIPPrefixSKUTierFailure is rendered with a public IP prefix's ID, its SKU tier, and the
+	// expected one.
+	PublicIPPrefixSKUTierFailure = "Public IP prefix %s has SKU tier %s, not %s."
+)
+
+// The failures of Event Hub rules.
+const (
+	// EventHubsNamespaceNotFoundFailure is rendered with an Event Hubs namespace's ID.
+	EventHubsNamespaceNotFoundFailure = "Event Hubs namespace %s not found."
+	// EventHubsNamespaceProvisioningFailure is rendered with an Event Hubs namespace's ID and
+	// provisioning state.
+	EventHubsNamespaceProvisioningFailure = "Event Hubs namespace %s is not provisioned: its provisioning state is %s."
+	// EventHubNotFoundFailure is rendered with an event hub's name and its namespace's ID.
+	EventHubNotFoundFailure = "Event hub %s not found in Event Hubs namespace %s."
+	// EventHubInactiveFailure is rendered with an event hub's ID and status.
+	EventHubInactiveFailure = "Event hub %s is not active: its status is %s."
+	// EventHubPartitionsFailure is rendered with an event hub's ID, its number of partitions, and
+	// the required number.
+	EventHubPartitionsFailure = "Event hub %s has %d partition(s), fewer than the required %d."
+	// EventHubsLocalAuthFailure is rendered with an Event Hubs namespace's ID and an authorization
+	// rule's name.
+	EventHubsLocalAuthFailure = "Event Hubs namespace %s has local authentication disabled, so authorization rule %s can't be used."
+	// AuthorizationRuleMissingFailure is rendered with an event hub's ID and an authorization
+	// rule's name.
+	AuthorizationRuleMissingFailure = "Neither event hub %s nor its namespace has authorization rule %s."
+	// AuthorizationRuleRightsFailure is rendered with an authorization rule's ID and the rights it
+	// lacks.
+	AuthorizationRuleRightsFailure = "Authorization rule %s lacks right(s) %s."
+)
+
+// The failures of application gateway rules.
+const (
+	// ApplicationGatewayNotFoundFailure is rendered with an application gateway's ID.
+	ApplicationGatewayNotFoundFailure = "Application gateway %s not found."
+	// ApplicationGatewayProvisioningFailure is rendered with an application gateway's ID and
+	// provisioning state.
+	ApplicationGatewayProvisioningFailure = "Application gateway %s is not provisioned: its provisioning state is %s."
+	// ApplicationGatewaySKUTierFailure is rendered with an application gateway's ID, its SKU tier,
+	// and the expected one.
+	ApplicationGatewaySKUTierFailure = "Application gateway %s has SKU tier %s, not %s."
+	// ApplicationGatewayListenerFailure is rendered with an application gateway's ID, the protocol
+	// and port of an expected listener, and the listeners it has.
+	ApplicationGatewayListenerFailure = "Application gateway %s has no %s listener on port %d; its listeners are %s."
+	// ApplicationGatewayNoWAFPolicyFailure is rendered with an application gateway's ID.
+	ApplicationGatewayNoWAFPolicyFailure = "Application gateway %s has no WAF policy attached."
+	// ApplicationGatewayWAFPolicyFailure is rendered with an application gateway's ID, the ID of
+	// its WAF policy, and the ID of the expected one.
+	ApplicationGatewayWAFPolicyFailure = "Application gateway %s has WAF policy %s attached, not %s."
+	// WAFPolicyNotFoundFailure is rendered with a WAF policy's ID and its application gateway's.
+	WAFPolicyNotFoundFailure = "WAF policy %s of application gateway %s not found."
+	// WAFPolicyDisabledFailure is rendered with a WAF policy's ID, its application gateway's, and
+	// its state.
+	WAFPolicyDisabledFailure = "WAF policy %s of application gateway %s is not enabled: its state is %s."
+	// WAFPolicyModeFailure is rendered with a WAF policy's ID, its application gateway's, its mode,
+	// and the expected one.
+	WAFPolicyModeFailure = "WAF policy %s of application gateway %s is in %s mode, not %s."
+)
+
+// The failures of Cosmos DB rules.
+const (
+	// CosmosDBAccountNotFoundFailure is rendered with a Cosmos DB account's ID.
+	CosmosDBAccountNotFoundFailure = "Cosmos DB account %s not found."
+	// CosmosDBCapabilitiesFailure is rendered with a Cosmos DB account's ID, the capabilities it
+	// lacks, and those it has.
+	CosmosDBCapabilitiesFailure = "Cosmos DB account %s lacks capabilities %s; it has %s."
+	// CosmosDBConsistencyFailure is rendered with a Cosmos DB account's ID, its default consistency
+	// level, and the expected one.
+	CosmosDBConsistencyFailure = "Cosmos DB account %s has default consistency level %s, not %s."
+	// CosmosDBPublicNetworkAccessFailure is rendered with a Cosmos DB account's ID, its public
+	// network access, and the expected one.
+	CosmosDBPublicNetworkAccessFailure = "Cosmos DB account %s has public network access %s, not %s."
+	// CosmosDBLocationsFailure is rendered with a Cosmos DB account's ID, the locations it isn't
+	// enabled in, and those it is.
+	CosmosDBLocationsFailure = "Cosmos DB account %s isn't enabled in location(s) %s; it's enabled in %s."
+	// CosmosDBPrivateEndpointFailure is rendered with a Cosmos DB account's ID and its number of
+	// private endpoint connections.
+	CosmosDBPrivateEndpointFailure = "Cosmos DB account %s has no approved private endpoint connection; it has %d private endpoint connection(s) in all."
+)
+
+// The failures of SQL server rules.
+const (
+	// SQLServerNotFoundFailure is rendered with a SQL server's ID.
+	SQLServerNotFoundFailure = "SQL server %s not found."
+	// SQLServerPublicNetworkAccessFailure is rendered with a SQL server's ID, its public network
+	// access, and the expected one.
+	SQLServerPublicNetworkAccessFailure = "SQL server %s has public network access %s, not %s."
+	// SQLServerTLSVersionFailure is rendered with a SQL server's ID, its minimal TLS version, and
+	// the required one.
+	SQLServerTLSVersionFailure = "SQL server %s has minimal TLS version %s, lower than %s."
+	// SQLServerNoAdministratorFailure is rendered with a SQL server's ID and the expected Azure AD
+	// administrator's ID.
+	SQLServerNoAdministratorFailure = "SQL server %s has no Azure AD administrator, not %s."
+	// SQLServerNoAdministratorAuthFailure is rendered with a SQL server's ID.
+	SQLServerNoAdministratorAuthFailure = "SQL server %s doesn't have Azure AD-only authentication enabled: it has no Azure AD administrator."
+	// SQLServerAdministratorFailure is rendered with a SQL server's ID, the login and ID of its
+	// Azure AD administrator, and the expected administrator's ID.
+	SQLServerAdministratorFailure = "SQL server %s has Azure AD administrator %s (%s), not %s."
+	// SQLServerADOnlyAuthFailure is rendered with a SQL server's ID.
+	SQLServerADOnlyAuthFailure = "SQL server %s doesn't have Azure AD-only authentication enabled."
+)
+
+// The failures of global endpoint rules.
+const (
+	// GlobalEndpointNotFoundFailure is rendered with the kind and ID of a Front Door profile or
+	// Traffic Manager profile.
+	GlobalEndpointNotFoundFailure = "%s not found."
+	// GlobalEndpointDisabledFailure is rendered with the kind and ID of a profile, and its status.
+	GlobalEndpointDisabledFailure = "%s is %s, not Enabled."
+	// GlobalEndpointMissingFailure is rendered with the kind and ID of a profile, and an endpoint's
+	// name.
+	GlobalEndpointMissingFailure = "%s has no endpoint %s."
+	// EndpointDisabledFailure is rendered with an endpoint's name, the kind and ID of its profile,
+	// and its status.
+	EndpointDisabledFailure = "Endpoint %s of %s is %s, not Enabled."
+	// EndpointMonitorStatusFailure is rendered with an endpoint's name, the kind and ID of its
+	// profile, its monitor status, and the healthy statuses.
+	EndpointMonitorStatusFailure = "Endpoint %s of %s has monitor status %s, not %s."
+)
+
+// The failures of ExpressRoute rules.
+const (
+	// ExpressRouteCircuitNotFoundFailure is rendered with an ExpressRoute circuit's ID.
+	ExpressRouteCircuitNotFoundFailure = "ExpressRoute circuit %s not found."
+	// ExpressRouteProviderStateFailure is rendered with an ExpressRoute circuit's ID and service
+	// provider provisioning state.
+	ExpressRouteProviderStateFailure = "ExpressRoute circuit %s has service provider provisioning state %s, not Provisioned."
+	// ExpressRouteCircuitStateFailure is rendered with an ExpressRoute circuit's ID and circuit
+	// provisioning state.
+	ExpressRouteCircuitStateFailure = "ExpressRoute circuit %s has circuit provisioning state %s, not Enabled."
+	// ExpressRouteConnectionPeerFailure is rendered with a virtual network gateway connection's ID,
+	// the ID of its peer, and an ExpressRoute circuit's ID.
+	ExpressRouteConnectionPeerFailure = "Virtual network gateway connection %s is to %s, not ExpressRoute circuit %s."
+)
+
+// The failures of VPN gateway rules.
+const (
+	// VPNGatewayNotFoundFailure is rendered with a virtual network gateway's ID.
+	VPNGatewayNotFoundFailure = "Virtual network gateway %s not found."
+	// VPNGatewaySKUFailure is rendered with a virtual network gateway's ID, its SKU, and the
+	// expected one.
+	VPNGatewaySKUFailure = "Virtual network gateway %s has SKU %s, not %s."
+	// VPNGatewayGenerationFailure is rendered with a virtual network gateway's ID, its generation,
+	// and the expected one.
+	VPNGatewayGenerationFailure = "Virtual network gateway %s is %s, not %s."
+	// VPNGatewayActiveActiveFailure is rendered with a virtual network gateway's ID.
+	VPNGatewayActiveActiveFailure = "Virtual network gateway %s isn't active-active."
+	// VPNConnectionGatewayFailure is rendered with a virtual network gateway connection's ID, the
+	// ID of its gateway, and the ID of the expected one.
+	VPNConnectionGatewayFailure = "Virtual network gateway connection %s is of %s, not virtual network gateway %s."
+	// VPNConnectionTrafficFailure is rendered with a virtual network gateway connection's ID, and
+	// the number of ingress and egress bytes it has transferred.
+	VPNConnectionTrafficFailure = "Virtual network gateway connection %s has transferred %d ingress and %d egress bytes; both must be above zero."
+)
+
+// The failures of network watcher rules.
+const (
+	// NetworkWatcherMissingFailure is rendered with a subscription ID and a location.
+	NetworkWatcherMissingFailure = "Subscription %s has no network watcher in location %s."
+	// FlowLogMissingFailure is rendered with a network security group's ID and a network watcher's.
+	FlowLogMissingFailure = "Network security group %s has no flow log in network watcher %s."
+	// FlowLogDisabledFailure is rendered with a flow log's name and its network security group's
+	// ID.
+	FlowLogDisabledFailure = "Flow log %s of network security group %s is disabled."
+	// FlowLogStorageFailure is rendered with a flow log's name, its network security group's ID,
+	// the ID of its storage account, and the ID of the expected one.
+	FlowLogStorageFailure = "Flow log %s of network security group %s stores logs in %s, not %s."
+	// FlowLogRetentionFailure is rendered with a flow log's name, its network security group's ID,
+	// its retention in days, and the required number of days.
+	FlowLogRetentionFailure = "Flow log %s of network security group %s keeps logs for %d day(s), fewer than %d."
+	// FlowLogTrafficAnalyticsFailure is rendered with a flow log's name and its network security
+	// group's ID.
+	FlowLogTrafficAnalyticsFailure = "Flow log %s of network security group %s doesn't have Traffic Analytics enabled."
+)
+
+// The failures of proximity placement group rules.
+const (
+	// ProximityPlacementGroupNotFoundFailure is rendered with a proximity placement group's ID.
+	ProximityPlacementGroupNotFoundFailure = "Proximity placement group %s not found."
+	// ProximityPlacementGroupZoneFailure is rendered with a proximity placement group's ID, its
+	// zones, and the expected zone.
+	ProximityPlacementGroupZoneFailure = "Proximity placement group %s is in zone(s) %s, not %s."
+	// ProximityPlacementGroupVMSizeFailure is rendered with a proximity placement group's ID, a VM
+	// size, and its intended VM sizes.
+	ProximityPlacementGroupVMSizeFailure = "Proximity placement group %s doesn't allow VM size %s. Its intended VM sizes are %s."
+	// ProximityPlacementGroupColocationFailure is rendered with a proximity placement group's ID,
+	// its colocation status, and the aligned status. It's followed by the colocation status
+	// message, if ARM reports one.
+	ProximityPlacementGroupColocationFailure = "Proximity placement group %s has colocation status %s, not %s."
+)
+
+// The failures of encryption at host rules.
+const (
+	// FeatureNotFoundFailure is rendered with a preview feature's namespace and name.
+	FeatureNotFoundFailure = "Feature %s/%s not found."
+	// FeatureNotRegisteredFailure is rendered with a preview feature's namespace and name, a
+	// subscription ID, and the feature's state.
+	FeatureNotRegisteredFailure = "Feature %s/%s is not registered in subscription %s: its state is %s."
+	// EncryptionAtHostMissingFailure is rendered with a VM size and the name of the capability it
+	// lacks.
+	EncryptionAtHostMissingFailure = "VM size %s doesn't support encryption at host: it lacks capability %s."
+	// EncryptionAtHostUnsupportedFailure is rendered with a VM size, and the name and value of its
+	// capability.
+	EncryptionAtHostUnsupportedFailure = "VM size %s doesn't support encryption at host: its capability %s is %s."
+)
+
+// The failures of group membership rules.
+const (
+	// GroupMemberMissingFailure is rendered with a principal's ID, member or direct member, and a
+	// group's ID.
+	GroupMemberMissingFailure = "Principal %s is not a %s of group %s."
+	// GroupNotFoundFailure is rendered with a group's ID.
+	GroupNotFoundFailure = "Group %s not found."
+	// GroupNameNotFoundFailure is rendered with a group's display name.
+	GroupNameNotFoundFailure = "No group is named %s."
+	// GroupNameAmbiguousFailure is rendered with the number of groups with a display name, and the
+	// display name.
+	GroupNameAmbiguousFailure = "%d groups are named %s. Set groupId to choose one."
+)
+
+// The failures of app permission rules.
+const (
+	// AppRegistrationNotFoundFailure is rendered with an application ID.
+	AppRegistrationNotFoundFailure = "No app registration has application ID %s."
+	// AppRegistrationNoServicePrincipalFailure is rendered with an application ID.
+	AppRegistrationNoServicePrincipalFailure = "App registration %s has no service principal in the tenant, so none of its permissions have admin consent."
+	// PermissionResourceNotFoundFailure is rendered with a resource's application ID, and the type
+	// and name of a permission it should define.
+	PermissionResourceNotFoundFailure = "No service principal has application ID %s, so %s permission %s can't be checked."
+	// PermissionUndefinedFailure is rendered with a resource's display name and application ID, and
+	// the type and name of a permission it doesn't define.
+	PermissionUndefinedFailure = "%s (%s) defines no %s permission %s."
+	// PermissionNotRequestedFailure is rendered with an application ID and an AppPermissionLabel.
+	PermissionNotRequestedFailure = "App registration %s doesn't request %s."
+	// PermissionRequestedWithoutConsentFailure is rendered with an application ID and an
+	// AppPermissionLabel.
+	PermissionRequestedWithoutConsentFailure = "App registration %s requests %s, but it lacks admin consent."
+	// PermissionWithoutConsentFailure is rendered with an application ID and an AppPermissionLabel.
+	PermissionWithoutConsentFailure = "App registration %s lacks admin consent for %s."
+	// AppPermissionLabel is rendered with a permission's type and name, and its resource's display
+	// name and application ID.
+	AppPermissionLabel = "%s permission %s of %s (%s)"
+)
+
+// The failures of blob container rules.
+const (
+	// BlobContainerNotFoundFailure is rendered with a blob container's name and its storage
+	// account's ID.
+	BlobContainerNotFoundFailure = "Blob container %s not found in storage account %s."
+	// ImmutabilityPolicyMissingFailure is rendered with a blob container's ID.
+	ImmutabilityPolicyMissingFailure = "Blob container %s has no time-based immutability policy."
+	// ImmutabilityPolicyUnlockedFailure is rendered with a blob container's ID and the state of its
+	// immutability policy.
+	ImmutabilityPolicyUnlockedFailure = "Immutability policy of blob container %s is not locked: its state is %s."
+	// ImmutabilityPolicyRetentionFailure is rendered with a blob container's ID, the number of days
+	// its immutability policy retains blobs, and the required number.
+	ImmutabilityPolicyRetentionFailure = "Immutability policy of blob container %s retains blobs for %d day(s), fewer than the required %d."
+	// LifecyclePolicyMissingFailure is rendered with a storage account's ID.
+	LifecyclePolicyMissingFailure = "Storage account %s has no lifecycle management policy."
+	// LifecycleRuleMissingFailure is rendered with a storage account's ID and a blob prefix.
+	LifecycleRuleMissingFailure = "Storage account %s has no enabled lifecycle management rule for blobs with prefix %s."
+	// LifecycleActionMissingFailure is rendered with a storage account's ID, a blob prefix, and the
+	// name of a lifecycle management action.
+	LifecycleActionMissingFailure = "No lifecycle management rule of storage account %s for blobs with prefix %s has a %s action based on their last modification."
+	// LifecycleActionLateFailure is rendered with a lifecycle management rule's name, its storage
+	// account's ID, the name of an action, the number of days after which the rule takes it, and
+	// the maximum number.
+	LifecycleActionLateFailure = "Lifecycle management rule %s of storage account %s has a %s action %g day(s) after the last modification of blobs, later than the required %d."
+)
+
+// The failures of storage network rules.
+const (
+	// StorageAccountNotFoundFailure is rendered with a storage account's ID.
+	StorageAccountNotFoundFailure = "Storage account %s not found."
+	// StorageDefaultActionFailure is rendered with a storage account's ID and its default network
+	// action.
+	StorageDefaultActionFailure = "Storage account %s allows network access by default: its default network action is %s, not Deny."
+	// StoragePublicNetworkAccessFailure is rendered with a storage account's ID.
+	StoragePublicNetworkAccessFailure = "Storage account %s has public network access disabled, so its virtual network and IP rules don't apply, and it's only reachable via private endpoints."
+	// StorageVirtualNetworkRuleMissingFailure is rendered with a storage account's ID and a
+	// subnet's.
+	StorageVirtualNetworkRuleMissingFailure = "Storage account %s has no virtual network rule for subnet %s."
+	// StorageVirtualNetworkRuleStateFailure is rendered with a storage account's ID, a subnet's,
+	// and the state of the virtual network rule.
+	StorageVirtualNetworkRuleStateFailure = "Virtual network rule of storage account %s for subnet %s is not in effect: its state is %s."
+	// StorageIPRuleMissingFailure is rendered with a storage account's ID and an IP range.
+	StorageIPRuleMissingFailure = "Storage account %s has no IP rule for %s."
+)
+
+// The failures of file share rules.
+const (
+	// FileShareNotFoundFailure is rendered with a file share's name and its storage account's ID.
+	FileShareNotFoundFailure = "File share %s not found in storage account %s."
+	// FileShareQuotaFailure is rendered with a file share's ID, its quota, and the required quota.
+	FileShareQuotaFailure = "File share %s has a quota of %d GiB, less than the required %d GiB."
+	// FileShareAccessTierFailure is rendered with a file share's ID, its access tier, and the
+	// expected one.
+	FileShareAccessTierFailure = "File share %s has access tier %s, not %s."
+)
+
+// The failures of subnet delegation rules.
+const (
+	// SubnetDelegationConflictFailure is rendered with a subnet's ID, a delegation's name, and the
+	// service it delegates to.
+	SubnetDelegationConflictFailure = "Subnet %s has delegation %s to %s, which conflicts with the rule."
+	// SubnetServiceLinkConflictFailure is rendered with a subnet's ID, a service association link's
+	// name, and its linked resource type.
+	SubnetServiceLinkConflictFailure = "Subnet %s has service association link %s from %s, which conflicts with the rule."
+	// SubnetPrivateEndpointPoliciesFailure is rendered with a subnet's ID, its private endpoint
+	// network policies, and the expected ones.
+	SubnetPrivateEndpointPoliciesFailure = "Subnet %s has private endpoint network policies %s, not %s."
+	// SubnetPrivateLinkServicePoliciesFailure is rendered with a subnet's ID, its private link
+	// service network policies, and the expected ones.
+	SubnetPrivateLinkServicePoliciesFailure = "Subnet %s has private link service network policies %s, not %s."
+)
+
+// The failures of monitor alert rules.
+const (
+	// ActionGroupNotFoundFailure is rendered with an action group's ID.
+	ActionGroupNotFoundFailure = "Action group %s not found."
+	// ActionGroupDisabledFailure is rendered with an action group's ID.
+	ActionGroupDisabledFailure = "Action group %s is disabled, so none of its receivers are notified."
+	// ActionGroupReceiversFailure is rendered with an action group's ID and a receiver type.
+	ActionGroupReceiversFailure = "Action group %s has no %s receivers."
+	// MetricAlertNotFoundFailure is rendered with a metric alert rule's ID.
+	MetricAlertNotFoundFailure = "Metric alert rule %s not found."
+	// MetricAlertDisabledFailure is rendered with a metric alert rule's ID.
+	MetricAlertDisabledFailure = "Metric alert rule %s is disabled."
+)
+
+// The failures of Activity Log export rules.
+const (
+	// ActivityLogNotExportedFailure is rendered with a subscription ID, and the kind and ID of a
+	// destination.
+	ActivityLogNotExportedFailure = "No diagnostic setting of subscription %s exports the Activity Log to %s %s."
+	// ActivityLogCategoriesFailure is rendered with a diagnostic setting's name, the kind and ID of
+	// its destination, and the categories it doesn't export.
+	ActivityLogCategoriesFailure = "Diagnostic setting %s exports the Activity Log to %s %s without categories: %s."
+)
+
+// The failures of image deprecation rules.
+const (
+	// ImageURNFailure is rendered with an image URN.
+	ImageURNFailure = "Image URN %s is not of the form publisher:offer:sku:version."
+	// ImageVersionConstraintFailure is rendered with an image version, the image's URN, and the
+	// error parsing the version.
+	ImageVersionConstraintFailure = "Version %q of image %s isn't a version, a version range, or latest: %v."
+	// ImageNoVersionsFailure is rendered with an image URN and a location.
+	ImageNoVersionsFailure = "Image %s has no versions in location %s."
+	// ImageVersionUnsatisfiedFailure is rendered with an image URN, a location, and a version
+	// constraint.
+	ImageVersionUnsatisfiedFailure = "No version of image %s in location %s satisfies %s."
+	// ImageNotFoundFailure is rendered with an image URN and a location.
+	ImageNotFoundFailure = "Image %s not found in location %s."
+	// ImageDeprecatedFailure is rendered with an image URN, when it was deprecated or
+	// DeprecationDateUnknown, and ImageAlternative, if its publisher recommends one.
+	ImageDeprecatedFailure = "Image %s is deprecated since %s.%s"
+	// ImageDeprecationScheduledFailure is rendered with an image URN, when it's scheduled for
+	// deprecation or DeprecationDateUnknown, and ImageAlternative, if its publisher recommends one.
+	ImageDeprecationScheduledFailure = "Image %s is scheduled for deprecation at %s.%s"
+	// ImageAlternative is rendered with the type and value of the alternative an image's publisher
+	// recommends, e.g. offer, and follows ImageDeprecatedFailure and
+	// ImageDeprecationScheduledFailure after a space.
+	ImageAlternative = "Its publisher recommends %s %s instead."
+	// DeprecationDateUnknown is when an image is deprecated, if ARM doesn't report it.
+	DeprecationDateUnknown = "an unknown date"
+)
+
+// The failures of zone redundancy rules.
+const (
+	// ComputeZonesFailure is rendered with a location, its number of availability zones for
+	// compute, the zones, and the required number.
+	ComputeZonesFailure = "Location %s offers compute in %d availability zones (%s), fewer than %d."
+	// VMSizeZonesFailure is rendered with a VM size, its number of availability zones in a
+	// location, the location, the zones, and the required number.
+	VMSizeZonesFailure = "VM size %s is offered in %d availability zones of location %s (%s), fewer than %d."
+	// LoadBalancerZonesFailure is rendered with a location and its number of availability zones.
+	LoadBalancerZonesFailure = "Standard load balancers can't have zone-redundant frontends in location %s, which has %d availability zones."
+	// StorageSKURestrictedFailure is rendered with a storage SKU, a location, and the reason for
+	// its restriction.
+	StorageSKURestrictedFailure = "Storage SKU %s is restricted for the subscription in location %s: reason %s."
+	// StorageSKUUnavailableFailure is rendered with a storage SKU and a location.
+	StorageSKUUnavailableFailure = "Storage SKU %s is not available in location %s, so storage accounts can't be zone-redundant there."
+)
+
+// The failures of custom location rules.
+const (
+	// CustomLocationNotFoundFailure is rendered with a custom location's ID.
+	CustomLocationNotFoundFailure = "Custom location %s not found."
+	// CustomLocationProvisioningFailure is rendered with a custom location's ID and provisioning
+	// state.
+	CustomLocationProvisioningFailure = "Custom location %s has provisioning state %s, not Succeeded."
+	// ClusterExtensionProvisioningFailure is rendered with a cluster extension's ID, type, and
+	// provisioning state.
+	ClusterExtensionProvisioningFailure = "Cluster extension %s of type %s has provisioning state %s, not Succeeded."
+	// ClusterExtensionMissingFailure is rendered with a custom location's ID, an extension type,
+	// and the types of its cluster extensions.
+	ClusterExtensionMissingFailure = "Custom location %s has no cluster extension of type %s; its cluster extensions are of types: %s."
+	// CustomLocationNoHostFailure is rendered with a custom location's ID.
+	CustomLocationNoHostFailure = "Custom location %s has no host resource."
+	// CustomLocationHostTypeFailure is rendered with a custom location's ID, its host's ID, and the
+	// resource types of Arc resource bridges and Arc-enabled Kubernetes clusters.
+	CustomLocationHostTypeFailure = "Custom location %s has host %s, which isn't an Arc resource bridge (%s) or an Arc-enabled Kubernetes cluster (%s)."
+	// CustomLocationHostNotFoundFailure is rendered with a host's ID and its custom location's.
+	CustomLocationHostNotFoundFailure = "Host %s of custom location %s not found."
+	// ResourceBridgeStatusFailure is rendered with an Arc resource bridge's ID and status.
+	ResourceBridgeStatusFailure = "Arc resource bridge %s has status %s, not Running or Connected."
+	// ConnectedClusterStatusFailure is rendered with an Arc-enabled Kubernetes cluster's ID and
+	// connectivity status.
+	ConnectedClusterStatusFailure = "Arc-enabled Kubernetes cluster %s has connectivity status %s, not Connected."
+)
+
+// The failures of Key Vault recovery rules.
+const (
+	// DeletedVaultMissingFailure is rendered with a Key Vault's name and a location.
+	DeletedVaultMissingFailure = "No soft-deleted Key Vault named %s in %s to recover."
+	// DeletedVaultHoldsNameFailure is rendered with a Key Vault's name, a location, the ID of the
+	// soft-deleted vault that holds the name, when it was deleted and is scheduled to be purged,
+	// and Enabled or Disabled.
+	DeletedVaultHoldsNameFailure = "Key Vault name %s is held in %s by soft-deleted vault %s, deleted %s and scheduled to be purged %s, with purge protection %s, so a vault of that name can't be created until it's recovered or purged."
+	// KeyVaultNotFoundFailure is rendered with a Key Vault's name and a resource group.
+	KeyVaultNotFoundFailure = "Key Vault %s not found in resource group %s."
+	// KeyVaultSoftDeleteFailure is rendered with a Key Vault's name.
+	KeyVaultSoftDeleteFailure = "Key Vault %s has soft delete disabled."
+	// KeyVaultPurgeProtectionFailure is rendered with a Key Vault's name.
+	KeyVaultPurgeProtectionFailure = "Key Vault %s has purge protection disabled, so it and its keys can be purged before their retention period ends."
+	// KeyVaultRetentionFailure is rendered with a Key Vault's name, its retention period in days,
+	// and the required number of days.
+	KeyVaultRetentionFailure = "Key Vault %s has a retention period of %d days, fewer than the required %d."
+)
+
+// The failures of workload identity rules.
+const (
+	// FederatedTokenFileUnsetFailure is the failure of a plugin whose pod doesn't use workload
+	// identity.
+	FederatedTokenFileUnsetFailure = "AZURE_FEDERATED_TOKEN_FILE isn't set in the plugin's pod, so it doesn't use workload identity."
+	// ClientIDUnsetFailure is the failure of a plugin whose pod has no workload identity client ID.
+	ClientIDUnsetFailure = "AZURE_CLIENT_ID isn't set in the plugin's pod, so its service account token can't be exchanged for an access token."
+	// FederatedCredentialNoMatchFailure is rendered with a client ID, the error code Microsoft
+	// Entra ID returns, and a FederatedCredentialClaims.
+	FederatedCredentialNoMatchFailure = "Microsoft Entra ID found no federated identity credential of client ID %s that matches the plugin's service account token (%s). Add one with %s."
+	// FederatedCredentialsMissingFailure is rendered with a client ID and a
+	// FederatedCredentialClaims.
+	FederatedCredentialsMissingFailure = "Client ID %s has no federated identity credentials. Add one with %s."
+	// FederatedCredentialIssuerFailure is rendered with a federated identity credential's name, its
+	// issuer, and the service account token's.
+	FederatedCredentialIssuerFailure = "Federated identity credential %s expects issuer %q, but the service account token's issuer is %q."
+	// FederatedCredentialSubjectFailure is rendered with a federated identity credential's name,
+	// its subject, and the service account token's.
+	FederatedCredentialSubjectFailure = "Federated identity credential %s expects subject %q, but the service account token's subject is %q."
+	// FederatedCredentialAudienceFailure is rendered with a federated identity credential's name,
+	// its quoted audiences, and the service account token's.
+	FederatedCredentialAudienceFailure = "Federated identity credential %s expects audience %s, but the service account token's audience is %s."
+	// FederatedCredentialClaims is rendered with the issuer, subject, and quoted audiences of the
+	// plugin's service account token.
+	FederatedCredentialClaims = "issuer %q, subject %q, and audience %s"
+)
+
+// RBACPending is the condition message of an RBAC rule whose only failures are missing permissions,
+// within the rule's grace period for role assignments to propagate.
+const RBACPending = "Required roles are not yet visible; within the propagation grace period." + seeFailures
+
+// NotEvaluated is the condition message of a rule that wasn't evaluated, because the
+// AzureValidator's run deadline elapsed before it was, whatever its type.
+const NotEvaluated = "Rule wasn't evaluated before the run deadline." + seeFailures
+
+// RunDeadlineFailure is the failure of a rule that wasn't evaluated, rendered with the run
+// deadline.
+const RunDeadlineFailure = "Not evaluated (run deadline exceeded): spec.runDeadline of %s elapsed before the rule was evaluated. It's evaluated first by the next reconcile."
+
+// The parts of CreationWindowFailure and AssignmentAssertionFailure, which aren't sentences
+// themselves.
+const (
+	// CreatedAtOrAfter, CreatedBefore, and CreatedBetween are rendered with the bounds of a
+	// creation window, in RFC 3339.
+	CreatedAtOrAfter = "at or after %s"
+	CreatedBefore    = "before %s"
+	CreatedBetween   = "between %s and %s"
+	// RoleAssignmentProperties is rendered with a role assignment's ID and the properties that a
+	// permission set asserts, as the role assignment has them.
+	RoleAssignmentProperties = "role assignment %s (%s)"
+	// Created is rendered with a creation window, or with when a role assignment was created.
+	Created = "created %s"
+	// CreationUnknown is the creation time of a role assignment that ARM doesn't report it of.
+	CreationUnknown = "creation time unknown"
+	// DescriptionMatching is rendered with a permission set's description pattern.
+	DescriptionMatching = "description matching %q"
+	// Description is rendered with a role assignment's description.
+	Description = "description %q"
+	// NoDescription is the description of a role assignment that has none.
+	NoDescription = "no description"
+	// Condition is rendered with a permission set's or a role assignment's ABAC condition.
+	Condition = "condition %q"
+	// NoCondition is the condition of an unrestricted role assignment.
+	NoCondition = "no condition"
+)
@@ -0,0 +1,101 @@
+package messages
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// catalog type-checks catalog.go, returning the value of each of its exported constants.
+func catalog(t *testing.T) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "catalog.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse catalog: %v", err)
+	}
+	pkg, err := new(types.Config).Check("messages", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("failed to type-check catalog: %v", err)
+	}
+	msgs := map[string]string{}
+	for _, name := range pkg.Scope().Names() {
+		if c, ok := pkg.Scope().Lookup(name).(*types.Const); ok && c.Exported() {
+			msgs[name] = constant.StringVal(c.Val())
+		}
+	}
+	return msgs
+}
+
+// validationTypes returns the rule types of the constants package, e.g. RBAC for
+// ValidationTypeRBAC.
+func validationTypes(t *testing.T) []string {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "../../constants/constants.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse constants: %v", err)
+	}
+	var ruleTypes []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Obj != nil && id.Obj.Kind == ast.Con {
+			if ruleType, ok := strings.CutPrefix(id.Name, "ValidationType"); ok {
+				ruleTypes = append(ruleTypes, ruleType)
+			}
+		}
+		return true
+	})
+	return ruleTypes
+}
+
+func TestCatalog(t *testing.T) {
+	msgs := catalog(t)
+
+	seen := map[string]string{}
+	for name, msg := range msgs {
+		if other, ok := seen[msg]; ok {
+			t.Errorf("%s and %s are both %q", name, other, msg)
+		}
+		seen[msg] = name
+		if strings.TrimSpace(msg) != msg || msg == "" {
+			t.Errorf("%s is empty or has surrounding spaces: %q", name, msg)
+		}
+	}
+
+	// Every type of rule has a condition message for when it succeeded and when it failed,
+	// except for the condition of loading rules from rulesFrom, which isn't a rule service's.
+	for _, ruleType := range validationTypes(t) {
+		if ruleType == "SpecLoad" {
+			continue
+		}
+		succeeded, ok := msgs[ruleType+"Succeeded"]
+		if !ok {
+			t.Errorf("%sSucceeded is missing", ruleType)
+		} else if !strings.HasSuffix(succeeded, ".") || strings.HasSuffix(succeeded, seeFailures) {
+			t.Errorf("%sSucceeded = %q, want a sentence that doesn't refer to failures", ruleType, succeeded)
+		}
+		failed, ok := msgs[ruleType+"Failed"]
+		if !ok {
+			t.Errorf("%sFailed is missing", ruleType)
+		} else if !strings.HasSuffix(failed, "."+seeFailures) {
+			t.Errorf("%sFailed = %q, want a sentence followed by %q", ruleType, failed, seeFailures)
+		}
+		delete(msgs, ruleType+"Succeeded")
+		delete(msgs, ruleType+"Failed")
+	}
+	for name := range msgs {
+		if strings.HasSuffix(name, "Succeeded") || strings.HasSuffix(name, "Failed") {
+			t.Errorf("%s is a condition message of no validation type", name)
+		}
+	}
+
+	// The failures are sentences, or end with one.
+	for name, msg := range msgs {
+		if strings.HasSuffix(name, "Failure") && !strings.HasSuffix(msg, ".") && !strings.HasSuffix(msg, "%s") {
+			t.Errorf("%s = %q, want a sentence", name, msg)
+		}
+	}
+}
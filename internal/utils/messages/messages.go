@@ -0,0 +1,115 @@
+// Package messages holds the catalog of the condition messages and failures that rules report, and
+// renders permission failures from Go templates, so that rules can override how they read.
+package messages
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PermissionFailure is the data that permission failure templates are rendered with. It describes
+// an Action or DataAction that a principal lacks at a scope.
+type PermissionFailure struct {
+	// Kind is Action or DataAction.
+	Kind string
+	// Action is the Action or DataAction the principal lacks.
+	Action string
+	// Scope is the scope at which the principal lacks it. Subscriptions beneath a management
+	// group are followed by their display name, e.g. /subscriptions/<id> (Production).
+	Scope string
+	// MultiScope is whether the failure's permission set has more than one scope.
+	MultiScope bool
+	// Scopes are the scopes of identical failures of the rule's permission sets that were merged
+	// into this one, in the order of the sets, starting with Scope.
+	Scopes []string
+	// PrincipalID is the principal of the rule.
+	PrincipalID string
+	// Roles are the names of the roles assigned to the principal at the scope. Empty for
+	// SelfCheck failures.
+	Roles []string
+	// DenyAssignment is the ID of the deny assignment that denies the Action, or empty if no
+	// role assignment permits it.
+	DenyAssignment string
+	// SelfCheck is whether the Action was checked against the effective permissions of the
+	// plugin's identity, rather than against the principal's role assignments.
+	SelfCheck bool
+}
+
+// The built-in permission failure templates.
+const (
+	defaultPermissionTemplate = `{{if gt (len .Scopes) 1}}At scopes {{join .Scopes ", "}}: {{else if .MultiScope}}At scope {{.Scope}}: {{end}}{{.Kind}} {{.Action}} ` +
+		`{{if .DenyAssignment}}denied by deny assignment {{.DenyAssignment}}{{else}}unpermitted because no role assignment permits it{{end}}.`
+	defaultSelfCheckTemplate = `{{.Kind}} {{.Action}} at {{if gt (len .Scopes) 1}}scopes {{join .Scopes ", "}}{{else}}scope {{.Scope}}{{end}} ` +
+		`not covered by the plugin's effective permissions.`
+)
+
+// funcs are the functions that failure templates may call, besides the predefined ones.
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// samples are the data that custom templates are checked against when they're parsed, so that
+// references to fields that don't exist are reported then rather than when they're rendered.
+var samples = []PermissionFailure{
+	{Kind: "Action", Action: "Microsoft.Compute/virtualMachines/write", Scope: "/subscriptions/00000000-0000-0000-0000-000000000000", Scopes: []string{"/subscriptions/00000000-0000-0000-0000-000000000000"}, PrincipalID: "00000000-0000-0000-0000-000000000000", Roles: []string{"Reader"}},
+	{Kind: "DataAction", Action: "Microsoft.KeyVault/vaults/secrets/getSecret/action", Scope: "/subscriptions/00000000-0000-0000-0000-000000000000", MultiScope: true, Scopes: []string{"/subscriptions/00000000-0000-0000-0000-000000000000", "/subscriptions/11111111-1111-1111-1111-111111111111"}, PrincipalID: "00000000-0000-0000-0000-000000000000", DenyAssignment: "deny-1"},
+	{Kind: "Action", Action: "Microsoft.Compute/virtualMachines/write", Scope: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg", PrincipalID: "00000000-0000-0000-0000-000000000000", SelfCheck: true},
+}
+
+// Templates render the permission failures of a rule.
+type Templates struct {
+	// nil for the built-in templates
+	custom *template.Template
+}
+
+var (
+	permissionTemplate = template.Must(template.New("permission").Funcs(funcs).Parse(defaultPermissionTemplate))
+	selfCheckTemplate  = template.Must(template.New("selfCheck").Funcs(funcs).Parse(defaultSelfCheckTemplate))
+)
+
+// NewTemplates returns the Templates of a rule with a failure message template, which renders all
+// of its permission failures. If text is empty, the built-in templates are used.
+func NewTemplates(text string) (Templates, error) {
+	if text == "" {
+		return Templates{}, nil
+	}
+	t, err := template.New("failureMessageTemplate").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return Templates{}, err
+	}
+	for _, sample := range samples {
+		if _, err := render(t, sample); err != nil {
+			return Templates{}, err
+		}
+	}
+	return Templates{custom: t}, nil
+}
+
+// Permission renders a permission failure. If a custom template fails to render, the failure is
+// rendered with the built-in template instead, followed by why.
+func (t Templates) Permission(f PermissionFailure) string {
+	builtIn := permissionTemplate
+	if f.SelfCheck {
+		builtIn = selfCheckTemplate
+	}
+	if t.custom != nil {
+		msg, err := render(t.custom, f)
+		if err == nil {
+			return msg
+		}
+		fallback, _ := render(builtIn, f)
+		return fmt.Sprintf("%s (failureMessageTemplate couldn't be rendered: %v)", fallback, err)
+	}
+	msg, _ := render(builtIn, f)
+	return msg
+}
+
+func render(t *template.Template, f PermissionFailure) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, f); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,97 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplates_Permission(t *testing.T) {
+	const scope = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	tests := []struct {
+		name     string
+		template string
+		failure  PermissionFailure
+		want     string
+	}{
+		{
+			name:    "The built-in template renders unpermitted Actions.",
+			failure: PermissionFailure{Kind: "Action", Action: "a", Scope: scope},
+			want:    "Action a unpermitted because no role assignment permits it.",
+		},
+		{
+			name:    "The built-in template renders denied DataActions, and their scope if there are many.",
+			failure: PermissionFailure{Kind: "DataAction", Action: "da", Scope: scope, MultiScope: true, DenyAssignment: "deny-1"},
+			want:    "At scope " + scope + ": DataAction da denied by deny assignment deny-1.",
+		},
+		{
+			name:    "The built-in template renders self-check failures.",
+			failure: PermissionFailure{Kind: "Action", Action: "a", Scope: scope, SelfCheck: true},
+			want:    "Action a at scope " + scope + " not covered by the plugin's effective permissions.",
+		},
+		{
+			name:    "The built-in template renders the scopes of merged failures.",
+			failure: PermissionFailure{Kind: "Action", Action: "a", Scope: scope, Scopes: []string{scope, scope + "/resourceGroups/rg"}},
+			want:    "At scopes " + scope + ", " + scope + "/resourceGroups/rg: Action a unpermitted because no role assignment permits it.",
+		},
+		{
+			name:    "The built-in template renders the scopes of merged self-check failures.",
+			failure: PermissionFailure{Kind: "Action", Action: "a", Scope: scope, Scopes: []string{scope, scope + "/resourceGroups/rg"}, SelfCheck: true},
+			want:    "Action a at scopes " + scope + ", " + scope + "/resourceGroups/rg not covered by the plugin's effective permissions.",
+		},
+		{
+			name:     "A custom template renders all failures.",
+			template: `{{.PrincipalID}} can't {{.Action}}{{if .Roles}} with roles {{join .Roles ", "}}{{end}}.`,
+			failure:  PermissionFailure{Kind: "Action", Action: "a", Scope: scope, PrincipalID: "p_id", Roles: []string{"Reader", "Contributor"}},
+			want:     "p_id can't a with roles Reader, Contributor.",
+		},
+		{
+			name:     "A custom template that fails to render falls back to the built-in template.",
+			template: `{{if eq .Action "x"}}{{index .Roles 0}}{{end}} lacks {{.Action}}.`,
+			failure:  PermissionFailure{Kind: "Action", Action: "x", Scope: scope},
+			want:     `Action x unpermitted because no role assignment permits it. (failureMessageTemplate couldn't be rendered: template: failureMessageTemplate:1:23: executing "failureMessageTemplate" at <index .Roles 0>: error calling index: reflect: slice index out of range)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates, err := NewTemplates(tt.template)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := templates.Permission(tt.failure); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTemplates_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{
+			name:     "Templates that don't parse are invalid.",
+			template: `{{.Action`,
+			wantErr:  "unclosed action",
+		},
+		{
+			name:     "Templates that refer to fields that failures don't have are invalid.",
+			template: `{{.Role}} lacks {{.Action}}.`,
+			wantErr:  "can't evaluate field Role",
+		},
+		{
+			name:     "Templates that call unknown functions are invalid.",
+			template: `{{upper .Action}}`,
+			wantErr:  `function "upper" not defined`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTemplates(tt.template)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+// Package versions resolves the versions of gallery and platform images against constraints: an
+// exact version, latest, or a range such as ">=1.27.0 <1.29.0".
+package versions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Latest is the constraint that resolves to the highest version that isn't a prerelease.
+const Latest = "latest"
+
+// prereleasePattern matches the dot-separated identifiers of a version's prerelease.
+var prereleasePattern = regexp.MustCompile(`^[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*$`)
+
+// Version is an image version: dot-separated numbers (e.g. 1.27.0, or 22.04.202312010 for
+// platform images), optionally followed by a prerelease (e.g. 1.27.0-rc.1) and build metadata
+// (e.g. 1.27.0+20240101), which is ignored.
+type Version struct {
+	raw        string
+	parts      []int
+	prerelease []string
+}
+
+// Parse parses an image version.
+func Parse(s string) (Version, error) {
+	v := Version{raw: s}
+	core, _, _ := strings.Cut(s, "+")
+	core, prerelease, hasPrerelease := strings.Cut(core, "-")
+	if hasPrerelease {
+		if !prereleasePattern.MatchString(prerelease) {
+			return Version{}, fmt.Errorf("invalid prerelease %q in version %q", prerelease, s)
+		}
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+	for _, part := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || strings.Trim(part, "0123456789") != "" {
+			return Version{}, fmt.Errorf("invalid version %q: parts must be non-negative numbers", s)
+		}
+		v.parts = append(v.parts, n)
+	}
+	return v, nil
+}
+
+// String returns the version as it was parsed.
+func (v Version) String() string {
+	return v.raw
+}
+
+// Prerelease reports whether the version is a prerelease.
+func (v Version) Prerelease() bool {
+	return len(v.prerelease) > 0
+}
+
+// Compare returns a negative number if version a is lower than b, a positive number if it's
+// higher, and zero if they're equal. Missing parts count as zero, so 1.27 equals 1.27.0, and a
+// prerelease is lower than its release, as in semantic versioning.
+func Compare(a, b Version) int {
+	if c := compareParts(a.parts, b.parts); c != 0 {
+		return c
+	}
+	switch {
+	case !a.Prerelease() && !b.Prerelease():
+		return 0
+	case !a.Prerelease():
+		return 1
+	case !b.Prerelease():
+		return -1
+	}
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		if c := compareIdentifiers(a.prerelease[i], b.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a.prerelease) - len(b.prerelease)
+}
+
+// compareParts compares the numeric parts of two versions, padding the shorter with zeros.
+func compareParts(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// compareIdentifiers compares two prerelease identifiers: numbers numerically, numbers lower than
+// other identifiers, and other identifiers lexically.
+func compareIdentifiers(a, b string) int {
+	x, xerr := strconv.Atoi(a)
+	y, yerr := strconv.Atoi(b)
+	switch {
+	case xerr == nil && yerr == nil:
+		return x - y
+	case xerr == nil:
+		return -1
+	case yerr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// comparator is a single condition of a range, e.g. >=1.27.0.
+type comparator struct {
+	op      string
+	version Version
+}
+
+// operators are the operators of comparators, longest first so that >= isn't read as >.
+var operators = []string{">=", "<=", "!=", ">", "<", "="}
+
+func (c comparator) satisfiedBy(v Version) bool {
+	cmp := Compare(v, c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// Constraint is what a version field of a rule requires: an exact version, latest, or a range.
+// Ranges are space-separated comparators that must all be satisfied (e.g. ">=1.27.0 <1.29.0"),
+// and ranges separated by || of which at least one must be.
+type Constraint struct {
+	raw    string
+	latest bool
+	exact  bool
+	ranges [][]comparator
+}
+
+// ParseConstraint parses a version constraint.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return Constraint{}, errors.New("version constraint is empty")
+	}
+	if strings.EqualFold(c.raw, Latest) {
+		c.latest = true
+		return c, nil
+	}
+	if !strings.ContainsAny(c.raw, " <>=!|") {
+		if _, err := Parse(c.raw); err != nil {
+			return Constraint{}, err
+		}
+		c.exact = true
+		return c, nil
+	}
+
+	for _, alternative := range strings.Split(c.raw, "||") {
+		tokens := strings.Fields(alternative)
+		if len(tokens) == 0 {
+			return Constraint{}, fmt.Errorf("version constraint %q has an empty range", s)
+		}
+		var comparators []comparator
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			// Allow a space between an operator and its version, e.g. ">= 1.27.0".
+			if isOperator(token) && i+1 < len(tokens) {
+				i++
+				token += tokens[i]
+			}
+			comp := comparator{op: "="}
+			for _, op := range operators {
+				if strings.HasPrefix(token, op) {
+					comp.op, token = op, strings.TrimPrefix(token, op)
+					break
+				}
+			}
+			v, err := Parse(token)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			comp.version = v
+			comparators = append(comparators, comp)
+		}
+		c.ranges = append(c.ranges, comparators)
+	}
+	return c, nil
+}
+
+// isOperator reports whether a token is an operator without a version.
+func isOperator(token string) bool {
+	for _, op := range operators {
+		if token == op {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraint as it was parsed, without surrounding spaces.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Exact returns the version that the constraint is, and true, if it's an exact version.
+func (c Constraint) Exact() (string, bool) {
+	return c.raw, c.exact
+}
+
+// Matches reports whether a version satisfies the constraint. Latest matches every version that
+// isn't a prerelease. As in semantic versioning, a prerelease only satisfies a range if one of the
+// range's comparators is a prerelease of the same version, so that ">=1.27.0-rc.1" matches
+// 1.27.0-rc.2, but ">=1.26.0" doesn't.
+func (c Constraint) Matches(v Version) bool {
+	switch {
+	case c.latest:
+		return !v.Prerelease()
+	case c.exact:
+		exact, _ := Parse(c.raw)
+		return Compare(v, exact) == 0
+	}
+	for _, comparators := range c.ranges {
+		allowPrerelease := !v.Prerelease()
+		satisfied := true
+		for _, comp := range comparators {
+			if !comp.satisfiedBy(v) {
+				satisfied = false
+				break
+			}
+			if comp.version.Prerelease() && compareParts(comp.version.parts, v.parts) == 0 {
+				allowPrerelease = true
+			}
+		}
+		if satisfied && allowPrerelease {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the highest of the candidate versions that satisfies the constraint, and true,
+// or false if none do. Candidates that aren't versions are ignored.
+func (c Constraint) Resolve(candidates []string) (string, bool) {
+	var best *Version
+	for _, candidate := range candidates {
+		v, err := Parse(candidate)
+		if err != nil || !c.Matches(v) {
+			continue
+		}
+		if best == nil || Compare(v, *best) > 0 {
+			best = &v
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	return best.raw, true
+}
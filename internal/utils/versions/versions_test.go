@@ -0,0 +1,268 @@
+package versions
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{version: "1.27.0"},
+		{version: "22.04.202312010"},
+		{version: "1"},
+		{version: "1.27.0-rc.1"},
+		{version: "1.27.0-beta-2"},
+		{version: "1.27.0+20240101"},
+		{version: "1.27.0-rc.1+20240101"},
+		{version: "", wantErr: true},
+		{version: "1..0", wantErr: true},
+		{version: "v1.27.0", wantErr: true},
+		{version: "1.27.x", wantErr: true},
+		{version: "1.27.0-", wantErr: true},
+		{version: "1.27.0-rc..1", wantErr: true},
+		{version: "1.+27.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := Parse(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if err == nil && v.String() != tt.version {
+				t.Errorf("got %s, want %s", v, tt.version)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.27.0", "1.27.0", 0},
+		{"1.27", "1.27.0", 0},
+		{"1.27.0+build1", "1.27.0+build2", 0},
+		{"1.28.0", "1.27.9", 1},
+		{"1.10.0", "1.9.0", 1},
+		{"22.04.202312010", "22.04.202309010", 1},
+		{"1.27.0", "1.27.0.1", -1},
+		{"1.27.0-rc.1", "1.27.0", -1},
+		{"1.27.0-rc.1", "1.26.9", 1},
+		{"1.27.0-rc.2", "1.27.0-rc.10", -1},
+		{"1.27.0-alpha", "1.27.0-beta", -1},
+		{"1.27.0-rc.1", "1.27.0-rc", 1},
+		{"1.27.0-1", "1.27.0-alpha", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, err := Parse(tt.a)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := Parse(tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := Compare(a, b); sign(got) != tt.want {
+				t.Errorf("got %d, want sign %d", got, tt.want)
+			}
+			if got := Compare(b, a); sign(got) != -tt.want {
+				t.Errorf("got %d comparing the other way around, want sign %d", got, -tt.want)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, constraint := range []string{
+		"",
+		"  ",
+		">=",
+		">=1.27.0 <",
+		">=1.27.x",
+		"1.27.0 ||",
+		"|| 1.27.0",
+		"~1.27.0",
+		"latest-1",
+	} {
+		t.Run(constraint, func(t *testing.T) {
+			if _, err := ParseConstraint(constraint); err == nil {
+				t.Errorf("expected an error for %q", constraint)
+			}
+		})
+	}
+}
+
+func TestConstraint_Resolve(t *testing.T) {
+	published := []string{"1.26.3", "1.27.0", "1.27.1", "1.28.0-rc.1", "1.28.0", "1.28.2", "1.29.0-rc.1", "not-a-version"}
+	tests := []struct {
+		name       string
+		constraint string
+		candidates []string
+		want       string
+		wantExact  bool
+		wantOK     bool
+	}{
+		{
+			name:       "An exact version resolves to itself.",
+			constraint: "1.27.0",
+			candidates: published,
+			want:       "1.27.0",
+			wantExact:  true,
+			wantOK:     true,
+		},
+		{
+			name:       "An exact prerelease version resolves to itself.",
+			constraint: "1.29.0-rc.1",
+			candidates: published,
+			want:       "1.29.0-rc.1",
+			wantExact:  true,
+			wantOK:     true,
+		},
+		{
+			name:       "An exact version that isn't published doesn't resolve.",
+			constraint: "1.27.5",
+			candidates: published,
+			wantExact:  true,
+		},
+		{
+			name:       "Latest resolves to the highest version that isn't a prerelease.",
+			constraint: "latest",
+			candidates: published,
+			want:       "1.28.2",
+			wantOK:     true,
+		},
+		{
+			name:       "Latest is case-insensitive.",
+			constraint: " Latest ",
+			candidates: published,
+			want:       "1.28.2",
+			wantOK:     true,
+		},
+		{
+			name:       "Latest doesn't resolve to prereleases only.",
+			constraint: "latest",
+			candidates: []string{"1.29.0-rc.1"},
+		},
+		{
+			name:       "A lower bound resolves to the highest version.",
+			constraint: ">=1.27.0",
+			candidates: published,
+			want:       "1.28.2",
+			wantOK:     true,
+		},
+		{
+			name:       "A range resolves to the highest version below its upper bound.",
+			constraint: ">=1.27.0 <1.28.0",
+			candidates: published,
+			want:       "1.27.1",
+			wantOK:     true,
+		},
+		{
+			name:       "A range may have spaces between operators and versions.",
+			constraint: ">= 1.27.0 <= 1.27.1",
+			candidates: published,
+			want:       "1.27.1",
+			wantOK:     true,
+		},
+		{
+			name:       "A range excludes prereleases of its upper bound.",
+			constraint: ">=1.27.0 <1.29.0",
+			candidates: published,
+			want:       "1.28.2",
+			wantOK:     true,
+		},
+		{
+			name:       "A range only includes prereleases of versions that its comparators are prereleases of.",
+			constraint: ">=1.29.0-rc.0",
+			candidates: published,
+			want:       "1.29.0-rc.1",
+			wantOK:     true,
+		},
+		{
+			name:       "A range with a prerelease lower bound includes later prereleases of its version.",
+			constraint: ">1.28.0-rc.0 <1.28.0",
+			candidates: published,
+			want:       "1.28.0-rc.1",
+			wantOK:     true,
+		},
+		{
+			name:       "A range excludes prereleases when none of its comparators are prereleases.",
+			constraint: ">1.28.2",
+			candidates: published,
+		},
+		{
+			name:       "A range with != excludes the version.",
+			constraint: ">=1.28.0 !=1.28.2",
+			candidates: published,
+			want:       "1.28.0",
+			wantOK:     true,
+		},
+		{
+			name:       "A version without an operator in a range must be equal.",
+			constraint: "=1.26.3 || 1.27.0",
+			candidates: published,
+			want:       "1.27.0",
+			wantOK:     true,
+		},
+		{
+			name:       "Alternative ranges resolve to the highest version of either.",
+			constraint: "<1.27.0 || >=1.28.0 <1.28.1",
+			candidates: published,
+			want:       "1.28.0",
+			wantOK:     true,
+		},
+		{
+			name:       "Missing parts count as zero.",
+			constraint: ">=1.27 <1.28",
+			candidates: published,
+			want:       "1.27.1",
+			wantOK:     true,
+		},
+		{
+			name:       "Platform image versions resolve.",
+			constraint: ">=22.04.202310010",
+			candidates: []string{"22.04.202309010", "22.04.202311010", "22.04.202312010"},
+			want:       "22.04.202312010",
+			wantOK:     true,
+		},
+		{
+			name:       "A range that no version satisfies doesn't resolve.",
+			constraint: ">=1.30.0",
+			candidates: published,
+		},
+		{
+			name:       "Nothing resolves without candidates.",
+			constraint: "latest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, exact := c.Exact(); exact != tt.wantExact {
+				t.Errorf("got exact %t, want %t", exact, tt.wantExact)
+			}
+			got, ok := c.Resolve(tt.candidates)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
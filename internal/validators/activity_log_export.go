@@ -0,0 +1,183 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// defaultActivityLogCategories are the Activity Log categories that must be exported when a rule
+// doesn't list any.
+var defaultActivityLogCategories = []v1alpha1.ActivityLogCategory{"Administrative", "Security"}
+
+// diagnosticSettingsAPI contains methods that allow listing the diagnostic settings of a
+// subscription.
+type diagnosticSettingsAPI interface {
+	ListSubscriptionDiagnosticSettings(subscriptionID string) ([]*armmonitor.DiagnosticSettingsResource, error)
+}
+
+type ActivityLogExportRuleService struct {
+	log logr.Logger
+	api diagnosticSettingsAPI
+}
+
+func NewActivityLogExportRuleService(log logr.Logger, api diagnosticSettingsAPI) *ActivityLogExportRuleService {
+	return &ActivityLogExportRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileActivityLogExportRule reconciles an Activity Log export rule from a validation config.
+func (s *ActivityLogExportRuleService) ReconcileActivityLogExportRule(rule v1alpha1.ActivityLogExportRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this Activity Log export rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ActivityLogExportSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeActivityLogExport
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeActivityLogExport, "subscriptionId", rule.SubscriptionID)
+	l.V(1).Info("Validating Activity Log export")
+	settings, err := s.api.ListSubscriptionDiagnosticSettings(rule.SubscriptionID)
+	if err != nil {
+		err = fmt.Errorf("failed to list diagnostic settings: %w", azure_errors.AsAugmented(err))
+		return validationResult, recordError(l, "failed to list diagnostic settings", err, &latestCondition)
+	}
+
+	categories := rule.Categories
+	if len(categories) == 0 {
+		categories = defaultActivityLogCategories
+	}
+	ev := &evidence{}
+	for _, setting := range settings {
+		if setting == nil || setting.Name == nil {
+			continue
+		}
+		ev.add("Diagnostic setting %s exports categories %s to: %s.", *setting.Name, joinOrNone(enabledLogCategories(setting)), joinOrNone(settingDestinations(setting)))
+	}
+	destinations := []struct{ kind, id string }{
+		{"Log Analytics workspace", rule.WorkspaceID},
+		{"storage account", rule.StorageAccountID},
+	}
+	for _, d := range destinations {
+		if d.id != "" {
+			validateActivityLogDestination(rule.SubscriptionID, d.kind, d.id, categories, settings, &latestCondition.Failures)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ActivityLogExportFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateActivityLogDestination appends a failure if no diagnostic setting exports the Activity
+// Log to a destination, or if none exports all of the categories to it. In that case, the failure
+// lists the categories missing from the setting that exports most of them.
+func validateActivityLogDestination(subscriptionID, kind, destinationID string, categories []v1alpha1.ActivityLogCategory, settings []*armmonitor.DiagnosticSettingsResource, failures *[]string) {
+	var best *armmonitor.DiagnosticSettingsResource
+	var bestMissing []string
+	for _, setting := range settings {
+		if setting == nil || !containsFold(settingDestinations(setting), destinationID) {
+			continue
+		}
+		missing := []string{}
+		for _, c := range categories {
+			if !logCategoryEnabled(setting, string(c)) {
+				missing = append(missing, string(c))
+			}
+		}
+		if best == nil || len(missing) < len(bestMissing) {
+			best, bestMissing = setting, missing
+		}
+	}
+
+	if best == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.ActivityLogNotExportedFailure, subscriptionID, kind, destinationID))
+		return
+	}
+	if len(bestMissing) > 0 {
+		name := ""
+		if best.Name != nil {
+			name = *best.Name
+		}
+		*failures = append(*failures, fmt.Sprintf(messages.ActivityLogCategoriesFailure, name, kind, destinationID, strings.Join(bestMissing, ", ")))
+	}
+}
+
+// settingDestinations returns the resource IDs of the workspace, storage account, and event hub
+// authorization rule that a diagnostic setting exports to.
+func settingDestinations(setting *armmonitor.DiagnosticSettingsResource) []string {
+	destinations := []string{}
+	if setting.Properties == nil {
+		return destinations
+	}
+	for _, id := range []*string{setting.Properties.WorkspaceID, setting.Properties.StorageAccountID, setting.Properties.EventHubAuthorizationRuleID} {
+		if id != nil && *id != "" {
+			destinations = append(destinations, *id)
+		}
+	}
+	return destinations
+}
+
+// enabledLogCategories returns the log categories and category groups that a diagnostic setting
+// has enabled.
+func enabledLogCategories(setting *armmonitor.DiagnosticSettingsResource) []string {
+	enabled := []string{}
+	if setting.Properties == nil {
+		return enabled
+	}
+	for _, log := range setting.Properties.Logs {
+		if log == nil || log.Enabled == nil || !*log.Enabled {
+			continue
+		}
+		if log.Category != nil {
+			enabled = append(enabled, *log.Category)
+		} else if log.CategoryGroup != nil {
+			enabled = append(enabled, *log.CategoryGroup)
+		}
+	}
+	return enabled
+}
+
+// logCategoryEnabled reports whether a diagnostic setting exports a log category, either by
+// itself or as part of the allLogs category group.
+func logCategoryEnabled(setting *armmonitor.DiagnosticSettingsResource, category string) bool {
+	for _, c := range enabledLogCategories(setting) {
+		if strings.EqualFold(c, category) || strings.EqualFold(c, "allLogs") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether items contains s, ignoring case.
+func containsFold(items []string, s string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testWorkspaceID      = "/subscriptions/" + testSubscriptionID + "/resourceGroups/monitoring/providers/Microsoft.OperationalInsights/workspaces/audit"
+	testAuditStorageID   = "/subscriptions/" + testSubscriptionID + "/resourceGroups/monitoring/providers/Microsoft.Storage/storageAccounts/auditlogs"
+	testArchiveStorageID = "/subscriptions/" + testSubscriptionID + "/resourceGroups/monitoring/providers/Microsoft.Storage/storageAccounts/archive"
+)
+
+// diagnosticSettingsAPIMock is a fake ARM with the diagnostic settings in its list, unless err is
+// set.
+type diagnosticSettingsAPIMock struct {
+	settings []*armmonitor.DiagnosticSettingsResource
+	err      error
+}
+
+func (m diagnosticSettingsAPIMock) ListSubscriptionDiagnosticSettings(_ string) ([]*armmonitor.DiagnosticSettingsResource, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.settings, nil
+}
+
+func newDiagnosticSetting(name string, props armmonitor.DiagnosticSettings, categories ...string) *armmonitor.DiagnosticSettingsResource {
+	for _, c := range categories {
+		props.Logs = append(props.Logs, &armmonitor.LogSettings{Category: util.Ptr(c), Enabled: util.Ptr(true)})
+	}
+	return &armmonitor.DiagnosticSettingsResource{Name: util.Ptr(name), Properties: &props}
+}
+
+func TestActivityLogExportRuleService_ReconcileActivityLogExportRule(t *testing.T) {
+	api := diagnosticSettingsAPIMock{settings: []*armmonitor.DiagnosticSettingsResource{
+		newDiagnosticSetting("to-workspace", armmonitor.DiagnosticSettings{WorkspaceID: util.Ptr(testWorkspaceID)}, "Administrative", "Security", "Policy"),
+		newDiagnosticSetting("to-storage", armmonitor.DiagnosticSettings{
+			StorageAccountID: util.Ptr(testAuditStorageID),
+			Logs:             []*armmonitor.LogSettings{{Category: util.Ptr("Security"), Enabled: util.Ptr(false)}},
+		}, "Administrative"),
+		newDiagnosticSetting("to-archive", armmonitor.DiagnosticSettings{
+			StorageAccountID: util.Ptr(testArchiveStorageID),
+			Logs:             []*armmonitor.LogSettings{{CategoryGroup: util.Ptr("allLogs"), Enabled: util.Ptr(true)}},
+		}),
+	}}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.ActivityLogExportRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when a setting exports the default categories to the workspace.",
+			rule:         v1alpha1.ActivityLogExportRule{WorkspaceID: testWorkspaceID},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when a setting exports all logs to the storage account.",
+			rule: v1alpha1.ActivityLogExportRule{
+				StorageAccountID: testArchiveStorageID,
+				Categories:       []v1alpha1.ActivityLogCategory{"Administrative", "Security", "ServiceHealth"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when the setting exporting to the storage account lacks a category.",
+			rule: v1alpha1.ActivityLogExportRule{WorkspaceID: testWorkspaceID, StorageAccountID: testAuditStorageID},
+			wantFailures: []string{
+				"Diagnostic setting to-storage exports the Activity Log to storage account " + testAuditStorageID + " without categories: Security.",
+			},
+		},
+		{
+			name: "Fails when no setting exports to the destination.",
+			rule: v1alpha1.ActivityLogExportRule{
+				WorkspaceID: "/subscriptions/" + testSubscriptionID + "/resourceGroups/monitoring/providers/Microsoft.OperationalInsights/workspaces/other",
+			},
+			wantFailures: []string{
+				"No diagnostic setting of subscription " + testSubscriptionID + " exports the Activity Log to Log Analytics workspace /subscriptions/" + testSubscriptionID + "/resourceGroups/monitoring/providers/Microsoft.OperationalInsights/workspaces/other.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewActivityLogExportRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.SubscriptionID = testSubscriptionID
+			result, err := svc.ReconcileActivityLogExportRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestActivityLogExportRuleService_ReconcileActivityLogExportRule_Error(t *testing.T) {
+	api := diagnosticSettingsAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewActivityLogExportRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileActivityLogExportRule(v1alpha1.ActivityLogExportRule{Name: "rule-1", SubscriptionID: testSubscriptionID, WorkspaceID: testWorkspaceID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
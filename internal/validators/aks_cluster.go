@@ -0,0 +1,224 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// notSet is how properties that the cluster doesn't have are shown in failures.
+const notSet = "(not set)"
+
+// managedClusterAPI contains methods that allow getting an AKS cluster in a subscription.
+type managedClusterAPI interface {
+	GetManagedCluster(resourceGroupName, clusterName string) (*armcontainerservice.ManagedCluster, error)
+}
+
+type AKSClusterRuleService struct {
+	log logr.Logger
+	api managedClusterAPI
+}
+
+func NewAKSClusterRuleService(log logr.Logger, api managedClusterAPI) *AKSClusterRuleService {
+	return &AKSClusterRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileAKSClusterRule reconciles an AKS cluster rule from a validation config.
+func (s *AKSClusterRuleService) ReconcileAKSClusterRule(rule v1alpha1.AKSClusterRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this AKS cluster rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.AKSClusterSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeAKSCluster
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeAKSCluster, "clusterID", rule.ClusterID)
+	l.V(1).Info("Validating AKS cluster")
+	ev := &evidence{}
+	if err := s.validateCluster(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate AKS cluster", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.AKSClusterFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateCluster appends a failure for each expected property that the cluster doesn't have, with
+// the cluster's actual value. A cluster that doesn't exist is a failure, not an error.
+func (s *AKSClusterRuleService) validateCluster(rule v1alpha1.AKSClusterRule, failures *[]string, ev *evidence) error {
+	id, err := arm.ParseResourceID(rule.ClusterID)
+	if err != nil {
+		return fmt.Errorf("failed to parse cluster ID: %w", err)
+	}
+	cluster, err := s.api.GetManagedCluster(id.ResourceGroupName, id.Name)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.AKSClusterNotFoundFailure, rule.ClusterID))
+			return nil
+		}
+		return fmt.Errorf("failed to get cluster: %w", azure_errors.AsAugmented(err))
+	}
+
+	props := cluster.Properties
+	if props == nil {
+		props = &armcontainerservice.ManagedClusterProperties{}
+	}
+	ev.add("Examined cluster %s, whose provisioning state is %s.", rule.ClusterID, strPtrValue(props.ProvisioningState))
+	expected := rule.ExpectedProperties
+	mismatch := func(property, want, got string) {
+		*failures = append(*failures, fmt.Sprintf(messages.AKSClusterPropertyFailure, property, want, got))
+	}
+
+	if want := expected.ProvisioningState; want != nil {
+		if got := strPtrValue(props.ProvisioningState); !strings.EqualFold(got, *want) {
+			mismatch("provisioningState", *want, got)
+		}
+	}
+	if want := expected.OIDCIssuerEnabled; want != nil {
+		got := props.OidcIssuerProfile != nil && props.OidcIssuerProfile.Enabled != nil && *props.OidcIssuerProfile.Enabled
+		if got != *want {
+			mismatch("oidcIssuerEnabled", strconv.FormatBool(*want), strconv.FormatBool(got))
+		}
+	}
+	if want := expected.WorkloadIdentityEnabled; want != nil {
+		sp := props.SecurityProfile
+		got := sp != nil && sp.WorkloadIdentity != nil && sp.WorkloadIdentity.Enabled != nil && *sp.WorkloadIdentity.Enabled
+		if got != *want {
+			mismatch("workloadIdentityEnabled", strconv.FormatBool(*want), strconv.FormatBool(got))
+		}
+	}
+	if want := expected.PrivateCluster; want != nil {
+		ap := props.APIServerAccessProfile
+		got := ap != nil && ap.EnablePrivateCluster != nil && *ap.EnablePrivateCluster
+		if got != *want {
+			mismatch("privateCluster", strconv.FormatBool(*want), strconv.FormatBool(got))
+		}
+	}
+	if want := expected.NetworkPlugin; want != nil {
+		got := notSet
+		if props.NetworkProfile != nil && props.NetworkProfile.NetworkPlugin != nil {
+			got = string(*props.NetworkProfile.NetworkPlugin)
+		}
+		if !strings.EqualFold(got, *want) {
+			mismatch("networkPlugin", *want, got)
+		}
+	}
+	if want := expected.MinKubernetesVersion; want != nil {
+		if err := validateKubernetesVersion(*want, props, failures); err != nil {
+			return err
+		}
+	}
+
+	// Sort the addons so that failures are in a stable order.
+	addons := make([]string, 0, len(expected.AddonProfiles))
+	for name := range expected.AddonProfiles {
+		addons = append(addons, name)
+	}
+	sort.Strings(addons)
+	for _, name := range addons {
+		want := expected.AddonProfiles[name]
+		got := findAddonProfile(props.AddonProfiles, name)
+		enabled := got != nil && got.Enabled != nil && *got.Enabled
+		if enabled != want.Enabled {
+			mismatch(fmt.Sprintf("addonProfiles.%s.enabled", name), strconv.FormatBool(want.Enabled), strconv.FormatBool(enabled))
+		}
+
+		keys := make([]string, 0, len(want.Config))
+		for k := range want.Config {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			value := notSet
+			if got != nil && got.Config[k] != nil {
+				value = *got.Config[k]
+			}
+			if value != want.Config[k] {
+				mismatch(fmt.Sprintf("addonProfiles.%s.config.%s", name, k), want.Config[k], value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateKubernetesVersion appends a failure if the cluster runs a Kubernetes version older than
+// minVersion. The version the cluster currently runs is used if known, because the version it's
+// configured with may be a partial version (e.g. 1.28) or still being upgraded to.
+func validateKubernetesVersion(minVersion string, props *armcontainerservice.ManagedClusterProperties, failures *[]string) error {
+	want, err := version.ParseGeneric(minVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse minimum Kubernetes version: %w", err)
+	}
+	current := props.CurrentKubernetesVersion
+	if current == nil {
+		current = props.KubernetesVersion
+	}
+	if current == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.AKSClusterVersionFailure, minVersion, notSet))
+		return nil
+	}
+	got, err := version.ParseGeneric(*current)
+	if err != nil {
+		return fmt.Errorf("failed to parse cluster's Kubernetes version: %w", err)
+	}
+	if !got.AtLeast(want) {
+		*failures = append(*failures, fmt.Sprintf(messages.AKSClusterVersionFailure, minVersion, *current))
+	}
+	return nil
+}
+
+// findAddonProfile returns a cluster's addon profile by name. Azure doesn't consistently preserve
+// the case of addon names (e.g. azureKeyvaultSecretsProvider vs. azurekeyvaultsecretsprovider), so
+// they're compared case-insensitively.
+func findAddonProfile(profiles map[string]*armcontainerservice.ManagedClusterAddonProfile, name string) *armcontainerservice.ManagedClusterAddonProfile {
+	if p, ok := profiles[name]; ok {
+		return p
+	}
+	for k, p := range profiles {
+		if strings.EqualFold(k, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+func strPtrValue(s *string) string {
+	if s == nil {
+		return notSet
+	}
+	return *s
+}
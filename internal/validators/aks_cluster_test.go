@@ -0,0 +1,204 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testClusterID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/cluster"
+
+// managedClusterAPIMock is a fake ARM with the AKS cluster "cluster" in the resource group "rg", if
+// data is set. Getting any other cluster fails with a 404, unless err is set.
+type managedClusterAPIMock struct {
+	data *armcontainerservice.ManagedCluster
+	err  error
+}
+
+func (m managedClusterAPIMock) GetManagedCluster(resourceGroupName, clusterName string) (*armcontainerservice.ManagedCluster, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.data == nil || resourceGroupName != "rg" || clusterName != "cluster" {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+// newManagedCluster returns a cluster with Succeeded provisioning state, OIDC issuer and workload
+// identity enabled, a private API server, the azure network plugin, Kubernetes 1.28.5, and the
+// Key Vault secrets provider addon enabled with secret rotation.
+func newManagedCluster() *armcontainerservice.ManagedCluster {
+	return &armcontainerservice.ManagedCluster{
+		ID: util.Ptr(testClusterID),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			ProvisioningState:        util.Ptr("Succeeded"),
+			OidcIssuerProfile:        &armcontainerservice.ManagedClusterOIDCIssuerProfile{Enabled: util.Ptr(true)},
+			SecurityProfile:          &armcontainerservice.ManagedClusterSecurityProfile{WorkloadIdentity: &armcontainerservice.ManagedClusterSecurityProfileWorkloadIdentity{Enabled: util.Ptr(true)}},
+			APIServerAccessProfile:   &armcontainerservice.ManagedClusterAPIServerAccessProfile{EnablePrivateCluster: util.Ptr(true)},
+			NetworkProfile:           &armcontainerservice.NetworkProfile{NetworkPlugin: util.Ptr(armcontainerservice.NetworkPluginAzure)},
+			KubernetesVersion:        util.Ptr("1.28"),
+			CurrentKubernetesVersion: util.Ptr("1.28.5"),
+			AddonProfiles: map[string]*armcontainerservice.ManagedClusterAddonProfile{
+				"azureKeyvaultSecretsProvider": {
+					Enabled: util.Ptr(true),
+					Config:  map[string]*string{"enableSecretRotation": util.Ptr("true")},
+				},
+				"azurepolicy": {
+					Enabled: util.Ptr(false),
+				},
+			},
+		},
+	}
+}
+
+func TestAKSClusterRuleService_ReconcileAKSClusterRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		cluster      func(c *armcontainerservice.ManagedCluster)
+		missing      bool
+		expected     v1alpha1.AKSClusterProperties
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the cluster has all expected properties.",
+			expected: v1alpha1.AKSClusterProperties{
+				ProvisioningState:       util.Ptr("succeeded"),
+				OIDCIssuerEnabled:       util.Ptr(true),
+				WorkloadIdentityEnabled: util.Ptr(true),
+				PrivateCluster:          util.Ptr(true),
+				NetworkPlugin:           util.Ptr("azure"),
+				MinKubernetesVersion:    util.Ptr("1.28.5"),
+				AddonProfiles: map[string]v1alpha1.AKSAddonProfile{
+					"azureKeyvaultSecretsProvider": {Enabled: true, Config: map[string]string{"enableSecretRotation": "true"}},
+					"azurePolicy":                  {Enabled: false},
+					"ingressApplicationGateway":    {Enabled: false},
+				},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the provisioning state differs.",
+			cluster:      func(c *armcontainerservice.ManagedCluster) { c.Properties.ProvisioningState = util.Ptr("Failed") },
+			expected:     v1alpha1.AKSClusterProperties{ProvisioningState: util.Ptr("Succeeded")},
+			wantFailures: []string{"Expected provisioningState to be Succeeded, but it is Failed."},
+		},
+		{
+			name:         "Fails when the OIDC issuer isn't enabled.",
+			cluster:      func(c *armcontainerservice.ManagedCluster) { c.Properties.OidcIssuerProfile = nil },
+			expected:     v1alpha1.AKSClusterProperties{OIDCIssuerEnabled: util.Ptr(true)},
+			wantFailures: []string{"Expected oidcIssuerEnabled to be true, but it is false."},
+		},
+		{
+			name: "Fails when workload identity isn't enabled.",
+			cluster: func(c *armcontainerservice.ManagedCluster) {
+				c.Properties.SecurityProfile.WorkloadIdentity.Enabled = util.Ptr(false)
+			},
+			expected:     v1alpha1.AKSClusterProperties{WorkloadIdentityEnabled: util.Ptr(true)},
+			wantFailures: []string{"Expected workloadIdentityEnabled to be true, but it is false."},
+		},
+		{
+			name:         "Fails when the cluster is private but mustn't be.",
+			expected:     v1alpha1.AKSClusterProperties{PrivateCluster: util.Ptr(false)},
+			wantFailures: []string{"Expected privateCluster to be false, but it is true."},
+		},
+		{
+			name:         "Fails when the network plugin differs.",
+			expected:     v1alpha1.AKSClusterProperties{NetworkPlugin: util.Ptr("kubenet")},
+			wantFailures: []string{"Expected networkPlugin to be kubenet, but it is azure."},
+		},
+		{
+			name:         "Fails when the network plugin isn't set.",
+			cluster:      func(c *armcontainerservice.ManagedCluster) { c.Properties.NetworkProfile = nil },
+			expected:     v1alpha1.AKSClusterProperties{NetworkPlugin: util.Ptr("azure")},
+			wantFailures: []string{"Expected networkPlugin to be azure, but it is (not set)."},
+		},
+		{
+			name:         "Fails when the cluster runs an older Kubernetes version.",
+			expected:     v1alpha1.AKSClusterProperties{MinKubernetesVersion: util.Ptr("1.29")},
+			wantFailures: []string{"Expected kubernetesVersion to be at least 1.29, but it is 1.28.5."},
+		},
+		{
+			name:         "Uses the configured Kubernetes version when the current version isn't known.",
+			cluster:      func(c *armcontainerservice.ManagedCluster) { c.Properties.CurrentKubernetesVersion = nil },
+			expected:     v1alpha1.AKSClusterProperties{MinKubernetesVersion: util.Ptr("v1.28.1")},
+			wantFailures: []string{"Expected kubernetesVersion to be at least v1.28.1, but it is 1.28."},
+		},
+		{
+			name: "Fails for each addon profile that differs, in order of addon name.",
+			expected: v1alpha1.AKSClusterProperties{
+				AddonProfiles: map[string]v1alpha1.AKSAddonProfile{
+					"omsagent":                     {Enabled: true},
+					"azureKeyvaultSecretsProvider": {Enabled: true, Config: map[string]string{"enableSecretRotation": "false", "rotationPollInterval": "2m"}},
+					"azurepolicy":                  {Enabled: true},
+				},
+			},
+			wantFailures: []string{
+				"Expected addonProfiles.azureKeyvaultSecretsProvider.config.enableSecretRotation to be false, but it is true.",
+				"Expected addonProfiles.azureKeyvaultSecretsProvider.config.rotationPollInterval to be 2m, but it is (not set).",
+				"Expected addonProfiles.azurepolicy.enabled to be true, but it is false.",
+				"Expected addonProfiles.omsagent.enabled to be true, but it is false.",
+			},
+		},
+		{
+			name:         "Fails when the cluster doesn't exist.",
+			missing:      true,
+			expected:     v1alpha1.AKSClusterProperties{ProvisioningState: util.Ptr("Succeeded")},
+			wantFailures: []string{"Cluster " + testClusterID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := managedClusterAPIMock{}
+			if !tt.missing {
+				api.data = newManagedCluster()
+				if tt.cluster != nil {
+					tt.cluster(api.data)
+				}
+			}
+			svc := NewAKSClusterRuleService(logr.Discard(), api)
+
+			result, err := svc.ReconcileAKSClusterRule(v1alpha1.AKSClusterRule{
+				Name:               "rule-1",
+				ClusterID:          testClusterID,
+				ExpectedProperties: tt.expected,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestAKSClusterRuleService_ReconcileAKSClusterRule_Error(t *testing.T) {
+	api := managedClusterAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewAKSClusterRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileAKSClusterRule(v1alpha1.AKSClusterRule{Name: "rule-1", ClusterID: testClusterID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,192 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// appPermissionTypeApplication is the type of app permissions that the application has itself,
+// rather than on behalf of signed-in users.
+const appPermissionTypeApplication = "Application"
+
+// applicationAPI contains methods that allow getting app registrations and service principals by
+// their application IDs, and the admin consent granted to service principals, from Microsoft
+// Graph.
+type applicationAPI interface {
+	GetApplication(appID string) (*azure_utils.GraphApplication, error)
+	GetServicePrincipal(appID string) (*azure_utils.GraphServicePrincipal, error)
+	ListAppRoleAssignments(servicePrincipalID string) ([]*azure_utils.GraphAppRoleAssignment, error)
+	ListOAuth2PermissionGrants(servicePrincipalID string) ([]*azure_utils.GraphOAuth2PermissionGrant, error)
+}
+
+type AppPermissionRuleService struct {
+	log logr.Logger
+	api applicationAPI
+}
+
+func NewAppPermissionRuleService(log logr.Logger, api applicationAPI) *AppPermissionRuleService {
+	return &AppPermissionRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileAppPermissionRule reconciles an app permission rule from a validation config.
+func (s *AppPermissionRuleService) ReconcileAppPermissionRule(rule v1alpha1.AppPermissionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this app permission rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.AppPermissionSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeAppPermission
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeAppPermission, "applicationID", rule.ApplicationID)
+	l.V(1).Info("Validating app registration permissions")
+	ev := &evidence{}
+	if err := s.validateAppPermissions(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate app registration permissions", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.AppPermissionFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// appConsent is the admin consent that an application's service principal has been granted.
+type appConsent struct {
+	assignments []*azure_utils.GraphAppRoleAssignment
+	grants      []*azure_utils.GraphOAuth2PermissionGrant
+}
+
+// validateAppPermissions appends a failure if the rule's app registration doesn't exist, and, for
+// each of the rule's permissions, if its resource application doesn't define it, if the app
+// registration doesn't request it, or if it lacks admin consent. An application without a service
+// principal in the tenant has no consent at all, which is one failure.
+func (s *AppPermissionRuleService) validateAppPermissions(rule v1alpha1.AppPermissionRule, failures *[]string, ev *evidence) error {
+	var rerr *azcore.ResponseError
+	app, err := s.api.GetApplication(rule.ApplicationID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.AppRegistrationNotFoundFailure, rule.ApplicationID))
+			return nil
+		}
+		return fmt.Errorf("failed to get app registration: %w", azure_errors.AsAugmented(err))
+	}
+	ev.add("App registration %s has application ID %s.", app.DisplayName, rule.ApplicationID)
+
+	var consent *appConsent
+	sp, err := s.api.GetServicePrincipal(rule.ApplicationID)
+	switch {
+	case errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound:
+		*failures = append(*failures, fmt.Sprintf(messages.AppRegistrationNoServicePrincipalFailure, rule.ApplicationID))
+	case err != nil:
+		return fmt.Errorf("failed to get service principal: %w", azure_errors.AsAugmented(err))
+	default:
+		consent = &appConsent{}
+		if consent.assignments, err = s.api.ListAppRoleAssignments(sp.ID); err != nil {
+			return fmt.Errorf("failed to list app role assignments: %w", azure_errors.AsAugmented(err))
+		}
+		if consent.grants, err = s.api.ListOAuth2PermissionGrants(sp.ID); err != nil {
+			return fmt.Errorf("failed to list OAuth2 permission grants: %w", azure_errors.AsAugmented(err))
+		}
+	}
+
+	// keys = resource application IDs; nil values for resources without a service principal
+	resources := map[string]*azure_utils.GraphServicePrincipal{}
+	for _, p := range rule.Permissions {
+		resource, ok := resources[p.ResourceAppID]
+		if !ok {
+			resource, err = s.api.GetServicePrincipal(p.ResourceAppID)
+			if err != nil && !(errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound) {
+				return fmt.Errorf("failed to get service principal of resource application: %w", azure_errors.AsAugmented(err))
+			}
+			resources[p.ResourceAppID] = resource
+		}
+		if resource == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.PermissionResourceNotFoundFailure, p.ResourceAppID, p.Type, p.Name))
+			continue
+		}
+		validateAppPermission(rule.ApplicationID, app, consent, resource, p, failures, ev)
+	}
+	return nil
+}
+
+// validateAppPermission appends a failure if a resource doesn't define a permission, if an app
+// registration doesn't request it, or if the app's service principal lacks admin consent for it.
+// consent is nil if the app has no service principal.
+func validateAppPermission(appID string, app *azure_utils.GraphApplication, consent *appConsent, resource *azure_utils.GraphServicePrincipal, p v1alpha1.AppPermission, failures *[]string, ev *evidence) {
+	label := fmt.Sprintf(messages.AppPermissionLabel, p.Type, p.Name, resource.DisplayName, p.ResourceAppID)
+
+	defined, accessType := resource.OAuth2PermissionScopes, azure_utils.GraphResourceAccessScope
+	if p.Type == appPermissionTypeApplication {
+		defined, accessType = resource.AppRoles, azure_utils.GraphResourceAccessRole
+	}
+	i := slices.IndexFunc(defined, func(d azure_utils.GraphPermission) bool { return strings.EqualFold(d.Value, p.Name) })
+	if i < 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.PermissionUndefinedFailure, resource.DisplayName, p.ResourceAppID, p.Type, p.Name))
+		return
+	}
+	permissionID := defined[i].ID
+
+	requested := slices.ContainsFunc(app.RequiredResourceAccess, func(r azure_utils.GraphRequiredResourceAccess) bool {
+		return strings.EqualFold(r.ResourceAppID, p.ResourceAppID) && slices.ContainsFunc(r.ResourceAccess, func(a azure_utils.GraphResourceAccess) bool {
+			return strings.EqualFold(a.ID, permissionID) && a.Type == accessType
+		})
+	})
+	if !requested {
+		*failures = append(*failures, fmt.Sprintf(messages.PermissionNotRequestedFailure, appID, label))
+	}
+	if consent == nil {
+		return
+	}
+	if !consent.has(resource.ID, permissionID, p) {
+		if requested {
+			*failures = append(*failures, fmt.Sprintf(messages.PermissionRequestedWithoutConsentFailure, appID, label))
+		} else {
+			*failures = append(*failures, fmt.Sprintf(messages.PermissionWithoutConsentFailure, appID, label))
+		}
+		return
+	}
+	ev.add("App registration %s has admin consent for %s.", appID, label)
+}
+
+// has returns whether a permission of a resource service principal has admin consent. Application
+// permissions have it if they're assigned, and delegated ones if they're granted on behalf of all
+// users.
+func (c *appConsent) has(resourceID, permissionID string, p v1alpha1.AppPermission) bool {
+	if p.Type == appPermissionTypeApplication {
+		return slices.ContainsFunc(c.assignments, func(a *azure_utils.GraphAppRoleAssignment) bool {
+			return a != nil && strings.EqualFold(a.ResourceID, resourceID) && strings.EqualFold(a.AppRoleID, permissionID)
+		})
+	}
+	return slices.ContainsFunc(c.grants, func(g *azure_utils.GraphOAuth2PermissionGrant) bool {
+		return g != nil && g.ConsentType == azure_utils.GraphConsentTypeAllPrincipals && strings.EqualFold(g.ResourceID, resourceID) &&
+			slices.ContainsFunc(strings.Fields(g.Scope), func(scope string) bool { return strings.EqualFold(scope, p.Name) })
+	})
+}
@@ -0,0 +1,217 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+const (
+	testClientAppID = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	testGraphAppID  = "00000003-0000-0000-c000-000000000000"
+	testMissingApp  = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+)
+
+// applicationAPIMock is a fake Microsoft Graph with app registrations and service principals,
+// keyed by application ID, and the admin consent of service principals, keyed by object ID.
+// Getting any other app registration or service principal fails with a 404, unless err is set.
+type applicationAPIMock struct {
+	apps        map[string]*azure_utils.GraphApplication
+	sps         map[string]*azure_utils.GraphServicePrincipal
+	assignments map[string][]*azure_utils.GraphAppRoleAssignment
+	grants      map[string][]*azure_utils.GraphOAuth2PermissionGrant
+	err         error
+}
+
+func (m applicationAPIMock) GetApplication(appID string) (*azure_utils.GraphApplication, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.apps, appID)
+}
+
+func (m applicationAPIMock) GetServicePrincipal(appID string) (*azure_utils.GraphServicePrincipal, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.sps, appID)
+}
+
+func (m applicationAPIMock) ListAppRoleAssignments(servicePrincipalID string) ([]*azure_utils.GraphAppRoleAssignment, error) {
+	return m.assignments[servicePrincipalID], m.err
+}
+
+func (m applicationAPIMock) ListOAuth2PermissionGrants(servicePrincipalID string) ([]*azure_utils.GraphOAuth2PermissionGrant, error) {
+	return m.grants[servicePrincipalID], m.err
+}
+
+func TestAppPermissionRuleService_ReconcileAppPermissionRule(t *testing.T) {
+	// The client app requests User.Read.All and Group.Read.All application permissions and the
+	// User.Read and Mail.Read delegated ones, but only User.Read.All and User.Read have admin
+	// consent. Directory.Read.All has consent without being requested.
+	graph := &azure_utils.GraphServicePrincipal{
+		ID:          "sp-graph",
+		AppID:       testGraphAppID,
+		DisplayName: "Microsoft Graph",
+		AppRoles: []azure_utils.GraphPermission{
+			{ID: "role-users", Value: "User.Read.All"},
+			{ID: "role-groups", Value: "Group.Read.All"},
+			{ID: "role-directory", Value: "Directory.Read.All"},
+		},
+		OAuth2PermissionScopes: []azure_utils.GraphPermission{
+			{ID: "scope-user", Value: "User.Read"},
+			{ID: "scope-mail", Value: "Mail.Read"},
+		},
+	}
+	app := &azure_utils.GraphApplication{
+		ID:          "app-object",
+		AppID:       testClientAppID,
+		DisplayName: "spectro-cloud",
+		RequiredResourceAccess: []azure_utils.GraphRequiredResourceAccess{{
+			ResourceAppID: testGraphAppID,
+			ResourceAccess: []azure_utils.GraphResourceAccess{
+				{ID: "role-users", Type: azure_utils.GraphResourceAccessRole},
+				{ID: "role-groups", Type: azure_utils.GraphResourceAccessRole},
+				{ID: "scope-user", Type: azure_utils.GraphResourceAccessScope},
+				{ID: "scope-mail", Type: azure_utils.GraphResourceAccessScope},
+			},
+		}},
+	}
+	api := applicationAPIMock{
+		apps: map[string]*azure_utils.GraphApplication{
+			testClientAppID: app,
+			testMissingApp:  {ID: "unconsented-object", AppID: testMissingApp, DisplayName: "unconsented"},
+		},
+		sps: map[string]*azure_utils.GraphServicePrincipal{
+			testClientAppID: {ID: "sp-client", AppID: testClientAppID, DisplayName: "spectro-cloud"},
+			testGraphAppID:  graph,
+		},
+		assignments: map[string][]*azure_utils.GraphAppRoleAssignment{
+			"sp-client": {
+				{AppRoleID: "role-users", ResourceID: "sp-graph"},
+				{AppRoleID: "role-directory", ResourceID: "sp-graph"},
+			},
+		},
+		grants: map[string][]*azure_utils.GraphOAuth2PermissionGrant{
+			"sp-client": {
+				{ConsentType: azure_utils.GraphConsentTypeAllPrincipals, ResourceID: "sp-graph", Scope: "openid User.Read"},
+				{ConsentType: "Principal", ResourceID: "sp-graph", Scope: "Mail.Read"},
+			},
+		},
+	}
+	permission := func(typ, name string) v1alpha1.AppPermission {
+		return v1alpha1.AppPermission{ResourceAppID: testGraphAppID, Name: name, Type: typ}
+	}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.AppPermissionRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the permissions are requested and have admin consent.",
+			rule: v1alpha1.AppPermissionRule{
+				ApplicationID: testClientAppID,
+				Permissions:   []v1alpha1.AppPermission{permission("Application", "User.Read.All"), permission("Delegated", "user.read")},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for permissions that are requested but lack admin consent.",
+			rule: v1alpha1.AppPermissionRule{
+				ApplicationID: testClientAppID,
+				Permissions:   []v1alpha1.AppPermission{permission("Application", "Group.Read.All"), permission("Delegated", "Mail.Read")},
+			},
+			wantFailures: []string{
+				"App registration " + testClientAppID + " requests Application permission Group.Read.All of Microsoft Graph (" + testGraphAppID + "), but it lacks admin consent.",
+				"App registration " + testClientAppID + " requests Delegated permission Mail.Read of Microsoft Graph (" + testGraphAppID + "), but it lacks admin consent.",
+			},
+		},
+		{
+			name: "Fails for permissions that aren't requested, whether or not they have admin consent.",
+			rule: v1alpha1.AppPermissionRule{
+				ApplicationID: testClientAppID,
+				Permissions:   []v1alpha1.AppPermission{permission("Application", "Directory.Read.All"), permission("Application", "User.Read")},
+			},
+			wantFailures: []string{
+				"App registration " + testClientAppID + " doesn't request Application permission Directory.Read.All of Microsoft Graph (" + testGraphAppID + ").",
+				"Microsoft Graph (" + testGraphAppID + ") defines no Application permission User.Read.",
+			},
+		},
+		{
+			name: "Fails once when the app registration has no service principal.",
+			rule: v1alpha1.AppPermissionRule{
+				ApplicationID: testMissingApp,
+				Permissions:   []v1alpha1.AppPermission{permission("Delegated", "User.Read")},
+			},
+			wantFailures: []string{
+				"App registration " + testMissingApp + " has no service principal in the tenant, so none of its permissions have admin consent.",
+				"App registration " + testMissingApp + " doesn't request Delegated permission User.Read of Microsoft Graph (" + testGraphAppID + ").",
+			},
+		},
+		{
+			name: "Fails when the resource application has no service principal.",
+			rule: v1alpha1.AppPermissionRule{
+				ApplicationID: testClientAppID,
+				Permissions:   []v1alpha1.AppPermission{{ResourceAppID: testMissingApp, Name: "Read", Type: "Delegated"}},
+			},
+			wantFailures: []string{"No service principal has application ID " + testMissingApp + ", so Delegated permission Read can't be checked."},
+		},
+		{
+			name: "Fails when the app registration doesn't exist.",
+			rule: v1alpha1.AppPermissionRule{
+				ApplicationID: "cccccccc-cccc-cccc-cccc-cccccccccccc",
+				Permissions:   []v1alpha1.AppPermission{permission("Delegated", "User.Read")},
+			},
+			wantFailures: []string{"No app registration has application ID cccccccc-cccc-cccc-cccc-cccccccccccc."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAppPermissionRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileAppPermissionRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestAppPermissionRuleService_ReconcileAppPermissionRule_Error(t *testing.T) {
+	api := applicationAPIMock{err: &azcore.ResponseError{ErrorCode: "Authorization_RequestDenied", StatusCode: http.StatusForbidden}}
+	svc := NewAppPermissionRuleService(logr.Discard(), api)
+
+	rule := v1alpha1.AppPermissionRule{
+		Name:          "rule-1",
+		ApplicationID: testClientAppID,
+		Permissions:   []v1alpha1.AppPermission{{ResourceAppID: testGraphAppID, Name: "User.Read", Type: "Delegated"}},
+	}
+	result, err := svc.ReconcileAppPermissionRule(rule)
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,231 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// applicationGatewayAPI contains methods that allow getting an application gateway by its resource
+// ID.
+type applicationGatewayAPI interface {
+	GetApplicationGateway(applicationGatewayID string) (*armnetwork.ApplicationGateway, error)
+}
+
+// wafPolicyAPI contains methods that allow getting a WAF policy by its resource ID.
+type wafPolicyAPI interface {
+	GetWebApplicationFirewallPolicy(policyID string) (*armnetwork.WebApplicationFirewallPolicy, error)
+}
+
+type ApplicationGatewayRuleService struct {
+	log          logr.Logger
+	gatewayAPI   applicationGatewayAPI
+	wafPolicyAPI wafPolicyAPI
+}
+
+func NewApplicationGatewayRuleService(log logr.Logger, gatewayAPI applicationGatewayAPI, wafPolicyAPI wafPolicyAPI) *ApplicationGatewayRuleService {
+	return &ApplicationGatewayRuleService{
+		log:          log,
+		gatewayAPI:   gatewayAPI,
+		wafPolicyAPI: wafPolicyAPI,
+	}
+}
+
+// ReconcileApplicationGatewayRule reconciles an application gateway rule from a validation config.
+func (s *ApplicationGatewayRuleService) ReconcileApplicationGatewayRule(rule v1alpha1.ApplicationGatewayRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this application gateway rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ApplicationGatewaySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeApplicationGateway
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeApplicationGateway, "applicationGatewayID", rule.ApplicationGatewayID)
+	l.V(1).Info("Validating application gateway")
+	ev := &evidence{}
+	if err := s.validateApplicationGateway(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate application gateway", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.gatewayAPI, s.wafPolicyAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ApplicationGatewayFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateApplicationGateway appends a failure for each of the rule's assertions that the
+// application gateway doesn't meet. A gateway or WAF policy that doesn't exist is a failure, not
+// an error.
+func (s *ApplicationGatewayRuleService) validateApplicationGateway(rule v1alpha1.ApplicationGatewayRule, failures *[]string, ev *evidence) error {
+	gateway, err := s.gatewayAPI.GetApplicationGateway(rule.ApplicationGatewayID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ApplicationGatewayNotFoundFailure, rule.ApplicationGatewayID))
+			return nil
+		}
+		return fmt.Errorf("failed to get application gateway: %w", azure_errors.AsAugmented(err))
+	}
+	props := gateway.Properties
+	if props == nil {
+		props = &armnetwork.ApplicationGatewayPropertiesFormat{}
+	}
+
+	provisioningState := notSet
+	if props.ProvisioningState != nil {
+		provisioningState = string(*props.ProvisioningState)
+	}
+	tier := notSet
+	if props.SKU != nil && props.SKU.Tier != nil {
+		tier = string(*props.SKU.Tier)
+	}
+	ev.add("Application gateway %s has provisioning state %s and SKU tier %s.", rule.ApplicationGatewayID, provisioningState, tier)
+	if !strings.EqualFold(provisioningState, string(armnetwork.ProvisioningStateSucceeded)) {
+		*failures = append(*failures, fmt.Sprintf(messages.ApplicationGatewayProvisioningFailure, rule.ApplicationGatewayID, provisioningState))
+	}
+	if rule.SKUTier != "" && !strings.EqualFold(tier, rule.SKUTier) {
+		*failures = append(*failures, fmt.Sprintf(messages.ApplicationGatewaySKUTierFailure, rule.ApplicationGatewayID, tier, rule.SKUTier))
+	}
+
+	if err := s.validateWAFPolicy(rule, props, failures, ev); err != nil {
+		return err
+	}
+
+	listeners := gatewayListeners(props)
+	for _, want := range rule.Listeners {
+		l := gatewayListener{port: want.Port, protocol: want.Protocol}
+		if !slices.ContainsFunc(listeners, l.matches) {
+			*failures = append(*failures, fmt.Sprintf(messages.ApplicationGatewayListenerFailure, rule.ApplicationGatewayID, want.Protocol, want.Port, listenersString(listeners)))
+		}
+	}
+	return nil
+}
+
+// validateWAFPolicy appends a failure if the rule requires a WAF policy that the application
+// gateway doesn't have attached, or if the attached policy isn't enabled in the rule's mode.
+func (s *ApplicationGatewayRuleService) validateWAFPolicy(rule v1alpha1.ApplicationGatewayRule, props *armnetwork.ApplicationGatewayPropertiesFormat, failures *[]string, ev *evidence) error {
+	if rule.FirewallPolicyID == "" && rule.FirewallPolicyMode == "" {
+		return nil
+	}
+	if props.FirewallPolicy == nil || props.FirewallPolicy.ID == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.ApplicationGatewayNoWAFPolicyFailure, rule.ApplicationGatewayID))
+		return nil
+	}
+	policyID := *props.FirewallPolicy.ID
+	if rule.FirewallPolicyID != "" && !strings.EqualFold(policyID, rule.FirewallPolicyID) {
+		*failures = append(*failures, fmt.Sprintf(messages.ApplicationGatewayWAFPolicyFailure, rule.ApplicationGatewayID, policyID, rule.FirewallPolicyID))
+	}
+	if rule.FirewallPolicyMode == "" {
+		return nil
+	}
+
+	policy, err := s.wafPolicyAPI.GetWebApplicationFirewallPolicy(policyID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.WAFPolicyNotFoundFailure, policyID, rule.ApplicationGatewayID))
+			return nil
+		}
+		return fmt.Errorf("failed to get WAF policy: %w", azure_errors.AsAugmented(err))
+	}
+	policyState, mode := notSet, notSet
+	if policy.Properties != nil && policy.Properties.PolicySettings != nil {
+		settings := policy.Properties.PolicySettings
+		if settings.State != nil {
+			policyState = string(*settings.State)
+		}
+		if settings.Mode != nil {
+			mode = string(*settings.Mode)
+		}
+	}
+	ev.add("WAF policy %s is %s in %s mode.", policyID, policyState, mode)
+	if !strings.EqualFold(policyState, string(armnetwork.WebApplicationFirewallEnabledStateEnabled)) {
+		*failures = append(*failures, fmt.Sprintf(messages.WAFPolicyDisabledFailure, policyID, rule.ApplicationGatewayID, policyState))
+	}
+	if !strings.EqualFold(mode, rule.FirewallPolicyMode) {
+		*failures = append(*failures, fmt.Sprintf(messages.WAFPolicyModeFailure, policyID, rule.ApplicationGatewayID, mode, rule.FirewallPolicyMode))
+	}
+	return nil
+}
+
+// gatewayListener is an application gateway listener's protocol and frontend port.
+type gatewayListener struct {
+	port     int32
+	protocol string
+}
+
+func (l gatewayListener) String() string {
+	return fmt.Sprintf("%s:%d", l.protocol, l.port)
+}
+
+func (l gatewayListener) matches(other gatewayListener) bool {
+	return l.port == other.port && strings.EqualFold(l.protocol, other.protocol)
+}
+
+// gatewayListeners returns the protocol and port of each of an application gateway's HTTP and
+// layer 4 listeners. Listeners refer to their frontend ports by ID, so a listener whose port
+// isn't among the gateway's frontend ports is skipped.
+func gatewayListeners(props *armnetwork.ApplicationGatewayPropertiesFormat) []gatewayListener {
+	ports := map[string]int32{}
+	for _, p := range props.FrontendPorts {
+		if p != nil && p.ID != nil && p.Properties != nil && p.Properties.Port != nil {
+			// ARM doesn't preserve the case of resource IDs consistently across references.
+			ports[strings.ToLower(*p.ID)] = *p.Properties.Port
+		}
+	}
+	var listeners []gatewayListener
+	add := func(port *armnetwork.SubResource, protocol *armnetwork.ApplicationGatewayProtocol) {
+		if port == nil || port.ID == nil || protocol == nil {
+			return
+		}
+		if n, ok := ports[strings.ToLower(*port.ID)]; ok {
+			listeners = append(listeners, gatewayListener{port: n, protocol: string(*protocol)})
+		}
+	}
+	for _, l := range props.HTTPListeners {
+		if l != nil && l.Properties != nil {
+			add(l.Properties.FrontendPort, l.Properties.Protocol)
+		}
+	}
+	for _, l := range props.Listeners {
+		if l != nil && l.Properties != nil {
+			add(l.Properties.FrontendPort, l.Properties.Protocol)
+		}
+	}
+	return listeners
+}
+
+func listenersString(listeners []gatewayListener) string {
+	if len(listeners) == 0 {
+		return "none"
+	}
+	s := make([]string, len(listeners))
+	for i, l := range listeners {
+		s[i] = l.String()
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,208 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testAppGatewayID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/agw"
+	testWAFPolicyID  = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/ApplicationGatewayWebApplicationFirewallPolicies/waf"
+)
+
+// applicationGatewayAPIMock is a fake ARM with the application gateways and WAF policies in its
+// maps, keyed by resource ID. Getting any other resource fails with a 404, unless err is set.
+type applicationGatewayAPIMock struct {
+	gateways map[string]*armnetwork.ApplicationGateway
+	policies map[string]*armnetwork.WebApplicationFirewallPolicy
+	err      error
+}
+
+func (m applicationGatewayAPIMock) GetApplicationGateway(applicationGatewayID string) (*armnetwork.ApplicationGateway, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.gateways, applicationGatewayID)
+}
+
+func (m applicationGatewayAPIMock) GetWebApplicationFirewallPolicy(policyID string) (*armnetwork.WebApplicationFirewallPolicy, error) {
+	return getOrNotFound(m.policies, policyID)
+}
+
+// newApplicationGateway returns a provisioned WAF_v2 application gateway with the WAF policy
+// attached, and an Http listener on port 80 and an Https listener on port 443.
+func newApplicationGateway() *armnetwork.ApplicationGateway {
+	port := func(name string, n int32) *armnetwork.ApplicationGatewayFrontendPort {
+		return &armnetwork.ApplicationGatewayFrontendPort{
+			ID:         util.Ptr(testAppGatewayID + "/frontendPorts/" + name),
+			Properties: &armnetwork.ApplicationGatewayFrontendPortPropertiesFormat{Port: util.Ptr(n)},
+		}
+	}
+	httpListener := func(portName string, protocol armnetwork.ApplicationGatewayProtocol) *armnetwork.ApplicationGatewayHTTPListener {
+		return &armnetwork.ApplicationGatewayHTTPListener{
+			Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
+				// ARM references don't always have the case of the IDs they refer to.
+				FrontendPort: &armnetwork.SubResource{ID: util.Ptr(testAppGatewayID + "/frontendports/" + portName)},
+				Protocol:     util.Ptr(protocol),
+			},
+		}
+	}
+	return &armnetwork.ApplicationGateway{
+		Properties: &armnetwork.ApplicationGatewayPropertiesFormat{
+			ProvisioningState: util.Ptr(armnetwork.ProvisioningStateSucceeded),
+			SKU:               &armnetwork.ApplicationGatewaySKU{Tier: util.Ptr(armnetwork.ApplicationGatewayTierWAFV2)},
+			FirewallPolicy:    &armnetwork.SubResource{ID: util.Ptr(testWAFPolicyID)},
+			FrontendPorts:     []*armnetwork.ApplicationGatewayFrontendPort{port("http", 80), port("https", 443)},
+			HTTPListeners: []*armnetwork.ApplicationGatewayHTTPListener{
+				httpListener("http", armnetwork.ApplicationGatewayProtocolHTTP),
+				httpListener("https", armnetwork.ApplicationGatewayProtocolHTTPS),
+			},
+		},
+	}
+}
+
+func newWAFPolicy(state armnetwork.WebApplicationFirewallEnabledState, mode armnetwork.WebApplicationFirewallMode) *armnetwork.WebApplicationFirewallPolicy {
+	return &armnetwork.WebApplicationFirewallPolicy{
+		Properties: &armnetwork.WebApplicationFirewallPolicyPropertiesFormat{
+			PolicySettings: &armnetwork.PolicySettings{State: util.Ptr(state), Mode: util.Ptr(mode)},
+		},
+	}
+}
+
+func TestApplicationGatewayRuleService_ReconcileApplicationGatewayRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		gateway      func(*armnetwork.ApplicationGateway)
+		policy       *armnetwork.WebApplicationFirewallPolicy
+		rule         v1alpha1.ApplicationGatewayRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the application gateway meets all assertions.",
+			rule: v1alpha1.ApplicationGatewayRule{
+				SKUTier:            "WAF_v2",
+				FirewallPolicyID:   testWAFPolicyID,
+				FirewallPolicyMode: "Prevention",
+				Listeners:          []v1alpha1.ApplicationGatewayListener{{Port: 443, Protocol: "Https"}, {Port: 80, Protocol: "Http"}},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes with only a provisioned application gateway.",
+			gateway: func(g *armnetwork.ApplicationGateway) {
+				g.Properties.FirewallPolicy = nil
+			},
+			rule:         v1alpha1.ApplicationGatewayRule{},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails when the WAF policy is in Detection mode.",
+			policy: newWAFPolicy(armnetwork.WebApplicationFirewallEnabledStateEnabled, armnetwork.WebApplicationFirewallModeDetection),
+			rule:   v1alpha1.ApplicationGatewayRule{FirewallPolicyMode: "Prevention"},
+			wantFailures: []string{
+				"WAF policy " + testWAFPolicyID + " of application gateway " + testAppGatewayID + " is in Detection mode, not Prevention.",
+			},
+		},
+		{
+			name:   "Fails when the WAF policy is disabled.",
+			policy: newWAFPolicy(armnetwork.WebApplicationFirewallEnabledStateDisabled, armnetwork.WebApplicationFirewallModePrevention),
+			rule:   v1alpha1.ApplicationGatewayRule{FirewallPolicyMode: "Prevention"},
+			wantFailures: []string{
+				"WAF policy " + testWAFPolicyID + " of application gateway " + testAppGatewayID + " is not enabled: its state is Disabled.",
+			},
+		},
+		{
+			name: "Fails when a listener is missing, reporting the listeners the gateway has.",
+			rule: v1alpha1.ApplicationGatewayRule{
+				Listeners: []v1alpha1.ApplicationGatewayListener{{Port: 443, Protocol: "Https"}, {Port: 8443, Protocol: "Https"}, {Port: 443, Protocol: "Tls"}},
+			},
+			wantFailures: []string{
+				"Application gateway " + testAppGatewayID + " has no Https listener on port 8443; its listeners are Http:80, Https:443.",
+				"Application gateway " + testAppGatewayID + " has no Tls listener on port 443; its listeners are Http:80, Https:443.",
+			},
+		},
+		{
+			name: "Fails when the application gateway isn't provisioned, has another tier, and has no WAF policy.",
+			gateway: func(g *armnetwork.ApplicationGateway) {
+				g.Properties.ProvisioningState = util.Ptr(armnetwork.ProvisioningStateUpdating)
+				g.Properties.SKU.Tier = util.Ptr(armnetwork.ApplicationGatewayTierStandardV2)
+				g.Properties.FirewallPolicy = nil
+			},
+			rule: v1alpha1.ApplicationGatewayRule{SKUTier: "WAF_v2", FirewallPolicyMode: "Prevention"},
+			wantFailures: []string{
+				"Application gateway " + testAppGatewayID + " is not provisioned: its provisioning state is Updating.",
+				"Application gateway " + testAppGatewayID + " has SKU tier Standard_v2, not WAF_v2.",
+				"Application gateway " + testAppGatewayID + " has no WAF policy attached.",
+			},
+		},
+		{
+			name: "Fails when another WAF policy is attached.",
+			gateway: func(g *armnetwork.ApplicationGateway) {
+				g.Properties.FirewallPolicy.ID = util.Ptr(testWAFPolicyID + "-other")
+			},
+			rule: v1alpha1.ApplicationGatewayRule{FirewallPolicyID: testWAFPolicyID, FirewallPolicyMode: "Prevention"},
+			wantFailures: []string{
+				"Application gateway " + testAppGatewayID + " has WAF policy " + testWAFPolicyID + "-other attached, not " + testWAFPolicyID + ".",
+				"WAF policy " + testWAFPolicyID + "-other of application gateway " + testAppGatewayID + " not found.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := newApplicationGateway()
+			if tt.gateway != nil {
+				tt.gateway(gateway)
+			}
+			policy := tt.policy
+			if policy == nil {
+				policy = newWAFPolicy(armnetwork.WebApplicationFirewallEnabledStateEnabled, armnetwork.WebApplicationFirewallModePrevention)
+			}
+			api := applicationGatewayAPIMock{
+				gateways: map[string]*armnetwork.ApplicationGateway{testAppGatewayID: gateway},
+				policies: map[string]*armnetwork.WebApplicationFirewallPolicy{testWAFPolicyID: policy},
+			}
+			svc := NewApplicationGatewayRuleService(logr.Discard(), api, api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.ApplicationGatewayID = testAppGatewayID
+			result, err := svc.ReconcileApplicationGatewayRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestApplicationGatewayRuleService_ReconcileApplicationGatewayRule_Error(t *testing.T) {
+	api := applicationGatewayAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewApplicationGatewayRuleService(logr.Discard(), api, api)
+
+	result, err := svc.ReconcileApplicationGatewayRule(v1alpha1.ApplicationGatewayRule{Name: "rule-1", ApplicationGatewayID: testAppGatewayID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
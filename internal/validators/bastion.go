@@ -0,0 +1,199 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+const (
+	// bastionSubnetName is the name Azure requires the subnet of a Bastion host to have.
+	bastionSubnetName = "AzureBastionSubnet"
+	// bastionMinAddresses is the number of addresses in a /26, the smallest AzureBastionSubnet
+	// that Azure Bastion supports.
+	bastionMinAddresses = 64
+)
+
+// bastionHostAPI contains methods that allow getting a Bastion host by its resource ID.
+type bastionHostAPI interface {
+	GetBastionHost(bastionHostID string) (*armnetwork.BastionHost, error)
+}
+
+type BastionRuleService struct {
+	log            logr.Logger
+	subnetAPI      subnetAPI
+	bastionHostAPI bastionHostAPI
+}
+
+func NewBastionRuleService(log logr.Logger, subnetAPI subnetAPI, bastionHostAPI bastionHostAPI) *BastionRuleService {
+	return &BastionRuleService{
+		log:            log,
+		subnetAPI:      subnetAPI,
+		bastionHostAPI: bastionHostAPI,
+	}
+}
+
+// ReconcileBastionRule reconciles a Bastion rule from a validation config.
+func (s *BastionRuleService) ReconcileBastionRule(rule v1alpha1.BastionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this Bastion rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.BastionSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeBastion
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeBastion, "virtualNetworkID", rule.VirtualNetworkID)
+	l.V(1).Info("Validating virtual network's Bastion host")
+	ev := &evidence{}
+	if err := s.validateBastion(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate virtual network's Bastion host", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.subnetAPI, s.bastionHostAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.BastionFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateBastion appends a failure if the virtual network has no AzureBastionSubnet, if the
+// subnet is smaller than a /26, or if it has no Bastion host that's provisioned and has the rule's
+// SKU. Subnets and hosts that don't exist are failures, not errors.
+func (s *BastionRuleService) validateBastion(rule v1alpha1.BastionRule, failures *[]string, ev *evidence) error {
+	var rerr *azcore.ResponseError
+	subnetID := fmt.Sprintf("%s/subnets/%s", rule.VirtualNetworkID, bastionSubnetName)
+	subnet, err := s.subnetAPI.GetSubnet(subnetID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.BastionSubnetNotFoundFailure, bastionSubnetName, rule.VirtualNetworkID))
+			return nil
+		}
+		return fmt.Errorf("failed to get subnet: %w", azure_errors.AsAugmented(err))
+	}
+	props := subnet.Properties
+	if props == nil {
+		props = &armnetwork.SubnetPropertiesFormat{}
+	}
+
+	prefixes := derefAll(props.AddressPrefixes)
+	if props.AddressPrefix != nil {
+		prefixes = append([]string{*props.AddressPrefix}, prefixes...)
+	}
+	largest, addresses := largestIPv4Prefix(prefixes)
+	switch {
+	case largest == "":
+		*failures = append(*failures, fmt.Sprintf(messages.BastionSubnetNoPrefixFailure, subnetID, bastionMinAddresses))
+	case addresses < bastionMinAddresses:
+		*failures = append(*failures, fmt.Sprintf(messages.BastionSubnetPrefixFailure, subnetID, largest, addresses, bastionMinAddresses))
+	default:
+		ev.add("Subnet %s has address prefix %s (%d addresses).", subnetID, largest, addresses)
+	}
+
+	hostID := subnetBastionHostID(props.IPConfigurations)
+	if hostID == "" {
+		*failures = append(*failures, fmt.Sprintf(messages.BastionHostMissingFailure, subnetID))
+		return nil
+	}
+	host, err := s.bastionHostAPI.GetBastionHost(hostID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.BastionHostNotFoundFailure, hostID, subnetID))
+			return nil
+		}
+		return fmt.Errorf("failed to get Bastion host: %w", azure_errors.AsAugmented(err))
+	}
+
+	provisioningState := notSet
+	if host.Properties != nil && host.Properties.ProvisioningState != nil {
+		provisioningState = string(*host.Properties.ProvisioningState)
+	}
+	sku := notSet
+	if host.SKU != nil && host.SKU.Name != nil {
+		sku = string(*host.SKU.Name)
+	}
+	ev.add("Subnet %s has Bastion host %s, whose provisioning state is %s and whose SKU is %s.", subnetID, hostID, provisioningState, sku)
+
+	if !strings.EqualFold(provisioningState, string(armnetwork.ProvisioningStateSucceeded)) {
+		*failures = append(*failures, fmt.Sprintf(messages.BastionHostProvisioningFailure, hostID, subnetID, provisioningState))
+	}
+	if rule.SKU != "" && !strings.EqualFold(sku, rule.SKU) {
+		*failures = append(*failures, fmt.Sprintf(messages.BastionHostSKUFailure, hostID, subnetID, sku, rule.SKU))
+	}
+	return nil
+}
+
+// subnetBastionHostID returns the resource ID of the Bastion host that one of a subnet's IP
+// configurations belongs to, or "" if none do. Azure allows one Bastion host per
+// AzureBastionSubnet, so the first one found is the only one.
+func subnetBastionHostID(ipConfigs []*armnetwork.IPConfiguration) string {
+	for _, ipConfig := range ipConfigs {
+		if ipConfig == nil || ipConfig.ID == nil {
+			continue
+		}
+		id, err := arm.ParseResourceID(*ipConfig.ID)
+		if err != nil || id.Parent == nil {
+			continue
+		}
+		if strings.EqualFold(id.Parent.ResourceType.String(), "Microsoft.Network/bastionHosts") {
+			return id.Parent.String()
+		}
+	}
+	return ""
+}
+
+// largestIPv4Prefix returns the IPv4 CIDR prefix with the most addresses, and how many it has.
+// Azure Bastion doesn't use IPv6, so IPv6 prefixes, and prefixes that don't parse, are skipped.
+func largestIPv4Prefix(prefixes []string) (string, uint64) {
+	largest, most := "", uint64(0)
+	for _, prefix := range prefixes {
+		n, ok := ipv4PrefixSize(prefix)
+		if ok && n > most {
+			largest, most = prefix, n
+		}
+	}
+	return largest, most
+}
+
+// ipv4PrefixSize returns the number of addresses in an IPv4 CIDR prefix, e.g. 64 for a /26.
+func ipv4PrefixSize(prefix string) (uint64, bool) {
+	p, err := netip.ParsePrefix(strings.TrimSpace(prefix))
+	if err != nil || !p.Addr().Is4() {
+		return 0, false
+	}
+	return prefixSize(p), true
+}
+
+// prefixSize returns the number of addresses in a CIDR prefix of either IP version, capped at the
+// largest uint64 for IPv6 prefixes that have more.
+func prefixSize(p netip.Prefix) uint64 {
+	hostBits := p.Addr().BitLen() - p.Bits()
+	if hostBits >= 64 {
+		return math.MaxUint64
+	}
+	return 1 << hostBits
+}
@@ -0,0 +1,199 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testBastionSubnetID = testVNetID + "/subnets/AzureBastionSubnet"
+	testBastionHostID   = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/bastionHosts/bastion"
+)
+
+// bastionHostAPIMock is a fake ARM with the Bastion hosts in its map, keyed by resource ID.
+type bastionHostAPIMock struct {
+	hosts map[string]*armnetwork.BastionHost
+}
+
+func (m bastionHostAPIMock) GetBastionHost(bastionHostID string) (*armnetwork.BastionHost, error) {
+	host, ok := m.hosts[bastionHostID]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return host, nil
+}
+
+// newBastionSubnet returns an AzureBastionSubnet with an address prefix, and an IP configuration
+// of the Bastion host if bastionHostID isn't empty.
+func newBastionSubnet(prefix, bastionHostID string) *armnetwork.Subnet {
+	subnet := &armnetwork.Subnet{Properties: &armnetwork.SubnetPropertiesFormat{AddressPrefix: util.Ptr(prefix)}}
+	if bastionHostID != "" {
+		subnet.Properties.IPConfigurations = []*armnetwork.IPConfiguration{
+			{ID: util.Ptr(bastionHostID + "/bastionHostIpConfigurations/IpConf")},
+		}
+	}
+	return subnet
+}
+
+// newBastionHost returns a Standard Bastion host with Succeeded provisioning state.
+func newBastionHost() *armnetwork.BastionHost {
+	return &armnetwork.BastionHost{
+		ID: util.Ptr(testBastionHostID),
+		Properties: &armnetwork.BastionHostPropertiesFormat{
+			ProvisioningState: util.Ptr(armnetwork.ProvisioningStateSucceeded),
+		},
+		SKU: &armnetwork.SKU{Name: util.Ptr(armnetwork.BastionHostSKUNameStandard)},
+	}
+}
+
+func TestBastionRuleService_ReconcileBastionRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		subnet       *armnetwork.Subnet
+		host         func(*armnetwork.BastionHost)
+		rule         v1alpha1.BastionRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the subnet is a /26 with a provisioned Bastion host.",
+			subnet:       newBastionSubnet("10.0.1.0/26", testBastionHostID),
+			rule:         v1alpha1.BastionRule{VirtualNetworkID: testVNetID},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the Bastion host has the rule's SKU, ignoring case.",
+			subnet:       newBastionSubnet("10.0.1.0/24", testBastionHostID),
+			rule:         v1alpha1.BastionRule{VirtualNetworkID: testVNetID, SKU: "standard"},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when one of the subnet's address prefixes is large enough.",
+			subnet: func() *armnetwork.Subnet {
+				s := newBastionSubnet("", testBastionHostID)
+				s.Properties.AddressPrefix = nil
+				s.Properties.AddressPrefixes = []*string{util.Ptr("10.0.1.0/27"), util.Ptr("fd00::/64"), util.Ptr("10.0.2.0/26")}
+				return s
+			}(),
+			rule:         v1alpha1.BastionRule{VirtualNetworkID: testVNetID},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the virtual network has no AzureBastionSubnet.",
+			rule:         v1alpha1.BastionRule{VirtualNetworkID: testVNetID},
+			wantFailures: []string{"Subnet AzureBastionSubnet not found in virtual network " + testVNetID + "."},
+		},
+		{
+			name:         "Fails when the subnet is smaller than a /26.",
+			subnet:       newBastionSubnet("10.0.1.0/27", testBastionHostID),
+			rule:         v1alpha1.BastionRule{VirtualNetworkID: testVNetID},
+			wantFailures: []string{"Subnet " + testBastionSubnetID + " has address prefix 10.0.1.0/27 (32 addresses), but Azure Bastion requires a /26 (64 addresses) or larger."},
+		},
+		{
+			name:         "Fails when the subnet has no Bastion host.",
+			subnet:       newBastionSubnet("10.0.1.0/26", ""),
+			rule:         v1alpha1.BastionRule{VirtualNetworkID: testVNetID},
+			wantFailures: []string{"No Bastion host is associated with subnet " + testBastionSubnetID + "."},
+		},
+		{
+			name:   "Fails when the Bastion host is missing.",
+			subnet: newBastionSubnet("10.0.1.0/26", testBastionHostID+"-deleted"),
+			rule:   v1alpha1.BastionRule{VirtualNetworkID: testVNetID},
+			wantFailures: []string{
+				"Bastion host " + testBastionHostID + "-deleted of subnet " + testBastionSubnetID + " not found.",
+			},
+		},
+		{
+			name:   "Fails when the Bastion host isn't provisioned and has another SKU.",
+			subnet: newBastionSubnet("10.0.1.0/26", testBastionHostID),
+			host: func(h *armnetwork.BastionHost) {
+				h.Properties.ProvisioningState = util.Ptr(armnetwork.ProvisioningStateFailed)
+				h.SKU.Name = util.Ptr(armnetwork.BastionHostSKUNameBasic)
+			},
+			rule: v1alpha1.BastionRule{VirtualNetworkID: testVNetID, SKU: "Standard"},
+			wantFailures: []string{
+				"Bastion host " + testBastionHostID + " of subnet " + testBastionSubnetID + " is not provisioned: its provisioning state is Failed.",
+				"Bastion host " + testBastionHostID + " of subnet " + testBastionSubnetID + " has SKU Basic, not Standard.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := newBastionHost()
+			if tt.host != nil {
+				tt.host(host)
+			}
+			subnets := map[string]*armnetwork.Subnet{}
+			if tt.subnet != nil {
+				subnets[testBastionSubnetID] = tt.subnet
+			}
+			svc := NewBastionRuleService(logr.Discard(),
+				networkAPIMock{subnets: subnets},
+				bastionHostAPIMock{hosts: map[string]*armnetwork.BastionHost{testBastionHostID: host}},
+			)
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileBastionRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestBastionRuleService_ReconcileBastionRule_Error(t *testing.T) {
+	api := networkAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewBastionRuleService(logr.Discard(), api, bastionHostAPIMock{})
+
+	result, err := svc.ReconcileBastionRule(v1alpha1.BastionRule{Name: "rule-1", VirtualNetworkID: testVNetID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
+
+func Test_ipv4PrefixSize(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   uint64
+		wantOK bool
+	}{
+		{prefix: "10.0.0.0/26", want: 64, wantOK: true},
+		{prefix: "10.0.0.0/27", want: 32, wantOK: true},
+		{prefix: "10.0.0.0/16", want: 65536, wantOK: true},
+		{prefix: "0.0.0.0/0", want: 1 << 32, wantOK: true},
+		{prefix: "10.0.0.1/32", want: 1, wantOK: true},
+		{prefix: " 10.0.0.0/24 ", want: 256, wantOK: true},
+		{prefix: "fd00::/64", wantOK: false},
+		{prefix: "10.0.0.0", wantOK: false},
+		{prefix: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := ipv4PrefixSize(tt.prefix)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ipv4PrefixSize(%q) = %d, %t, want %d, %t", tt.prefix, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// blobContainerAPI contains methods that allow getting blob containers, and the lifecycle
+// management policies of storage accounts, by their resource IDs.
+type blobContainerAPI interface {
+	GetBlobContainer(containerID string) (*armstorage.BlobContainer, error)
+	GetManagementPolicy(storageAccountID string) (*armstorage.ManagementPolicy, error)
+}
+
+type BlobContainerRuleService struct {
+	log logr.Logger
+	api blobContainerAPI
+}
+
+func NewBlobContainerRuleService(log logr.Logger, api blobContainerAPI) *BlobContainerRuleService {
+	return &BlobContainerRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileBlobContainerRule reconciles a blob container rule from a validation config.
+func (s *BlobContainerRuleService) ReconcileBlobContainerRule(rule v1alpha1.BlobContainerRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this blob container rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.BlobContainerSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeBlobContainer
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeBlobContainer, "storageAccountID", rule.StorageAccountID, "containerName", rule.ContainerName)
+	l.V(1).Info("Validating blob container")
+	ev := &evidence{}
+	if err := s.validateBlobContainer(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate blob container", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.BlobContainerFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateBlobContainer validates the container's immutability policy, then, if the rule has one,
+// its storage account's lifecycle management rule. A missing container is a failure, not an
+// error, and nothing else is checked.
+func (s *BlobContainerRuleService) validateBlobContainer(rule v1alpha1.BlobContainerRule, failures *[]string, ev *evidence) error {
+	containerID := fmt.Sprintf("%s/blobServices/default/containers/%s", rule.StorageAccountID, rule.ContainerName)
+	container, err := s.api.GetBlobContainer(containerID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.BlobContainerNotFoundFailure, rule.ContainerName, rule.StorageAccountID))
+			return nil
+		}
+		return fmt.Errorf("failed to get blob container: %w", azure_errors.AsAugmented(err))
+	}
+	validateImmutabilityPolicy(rule, containerID, container, failures, ev)
+
+	if rule.LifecycleRule == nil {
+		return nil
+	}
+	return s.validateLifecycleRule(rule, failures, ev)
+}
+
+// validateImmutabilityPolicy appends a failure if a container has no time-based immutability
+// policy, if the policy isn't locked, or if it retains blobs for fewer than the rule's days.
+func validateImmutabilityPolicy(rule v1alpha1.BlobContainerRule, containerID string, container *armstorage.BlobContainer, failures *[]string, ev *evidence) {
+	var policy *armstorage.ImmutabilityPolicyProperty
+	if props := container.ContainerProperties; props != nil && props.ImmutabilityPolicy != nil {
+		if props.HasImmutabilityPolicy == nil || *props.HasImmutabilityPolicy {
+			policy = props.ImmutabilityPolicy.Properties
+		}
+	}
+	if policy == nil || policy.ImmutabilityPeriodSinceCreationInDays == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.ImmutabilityPolicyMissingFailure, containerID))
+		return
+	}
+
+	days := *policy.ImmutabilityPeriodSinceCreationInDays
+	state := notSet
+	if policy.State != nil {
+		state = string(*policy.State)
+	}
+	ev.add("Blob container %s has an immutability policy in state %s that retains blobs for %d day(s).", containerID, state, days)
+	if !strings.EqualFold(state, string(armstorage.ImmutabilityPolicyStateLocked)) {
+		*failures = append(*failures, fmt.Sprintf(messages.ImmutabilityPolicyUnlockedFailure, containerID, state))
+	}
+	if days < rule.MinImmutabilityDays {
+		*failures = append(*failures, fmt.Sprintf(messages.ImmutabilityPolicyRetentionFailure, containerID, days, rule.MinImmutabilityDays))
+	}
+}
+
+// validateLifecycleRule appends a failure if the storage account has no lifecycle management
+// policy, or no enabled rule in it that applies to the rule's blobs. Otherwise, it appends one for
+// each of the rule's actions that none of those rules take soon enough.
+func (s *BlobContainerRuleService) validateLifecycleRule(rule v1alpha1.BlobContainerRule, failures *[]string, ev *evidence) error {
+	want := rule.LifecycleRule
+	prefix := rule.ContainerName + "/" + want.Prefix
+
+	policy, err := s.api.GetManagementPolicy(rule.StorageAccountID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.LifecyclePolicyMissingFailure, rule.StorageAccountID))
+			return nil
+		}
+		return fmt.Errorf("failed to get lifecycle management policy: %w", azure_errors.AsAugmented(err))
+	}
+
+	var matching []*armstorage.ManagementPolicyRule
+	if policy.Properties != nil && policy.Properties.Policy != nil {
+		for _, r := range policy.Properties.Policy.Rules {
+			if lifecycleRuleApplies(r, prefix) {
+				matching = append(matching, r)
+			}
+		}
+	}
+	if len(matching) == 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.LifecycleRuleMissingFailure, rule.StorageAccountID, prefix))
+		return nil
+	}
+	names := make([]string, 0, len(matching))
+	for _, r := range matching {
+		names = append(names, strPtrValue(r.Name))
+	}
+	ev.add("Lifecycle management rule(s) %s of storage account %s apply to blobs with prefix %s.", strings.Join(names, ", "), rule.StorageAccountID, prefix)
+
+	if want.TierToCoolAfterDays != nil {
+		validateLifecycleAction(rule.StorageAccountID, prefix, matching, "tierToCool", *want.TierToCoolAfterDays, func(b *armstorage.ManagementPolicyBaseBlob) *armstorage.DateAfterModification { return b.TierToCool }, failures)
+	}
+	if want.DeleteAfterDays != nil {
+		validateLifecycleAction(rule.StorageAccountID, prefix, matching, "delete", *want.DeleteAfterDays, func(b *armstorage.ManagementPolicyBaseBlob) *armstorage.DateAfterModification { return b.Delete }, failures)
+	}
+	return nil
+}
+
+// validateLifecycleAction appends a failure if none of a storage account's lifecycle management
+// rules has a base blob action, e.g. tierToCool, that acts by maxDays after the last modification
+// of blobs. action gets the action's condition from a rule's base blob actions. Conditions on anything other than the last
+// modification, e.g. the last access, don't count.
+func validateLifecycleAction(storageAccountID, prefix string, rules []*armstorage.ManagementPolicyRule, actionName string, maxDays int32, action func(*armstorage.ManagementPolicyBaseBlob) *armstorage.DateAfterModification, failures *[]string) {
+	var soonest *armstorage.ManagementPolicyRule
+	var soonestDays float32
+	for _, r := range rules {
+		base := r.Definition.Actions.BaseBlob
+		if base == nil {
+			continue
+		}
+		cond := action(base)
+		if cond == nil || cond.DaysAfterModificationGreaterThan == nil {
+			continue
+		}
+		if days := *cond.DaysAfterModificationGreaterThan; soonest == nil || days < soonestDays {
+			soonest, soonestDays = r, days
+		}
+	}
+
+	switch {
+	case soonest == nil:
+		*failures = append(*failures, fmt.Sprintf(messages.LifecycleActionMissingFailure, storageAccountID, prefix, actionName))
+	case soonestDays > float32(maxDays):
+		*failures = append(*failures, fmt.Sprintf(messages.LifecycleActionLateFailure, strPtrValue(soonest.Name), storageAccountID, actionName, soonestDays, maxDays))
+	}
+}
+
+// lifecycleRuleApplies returns whether a lifecycle management rule is enabled and applies to the
+// block blobs with a prefix, which includes the container name.
+func lifecycleRuleApplies(r *armstorage.ManagementPolicyRule, prefix string) bool {
+	if r == nil || (r.Enabled != nil && !*r.Enabled) || r.Definition == nil || r.Definition.Actions == nil {
+		return false
+	}
+	filters := r.Definition.Filters
+	if filters == nil {
+		return true
+	}
+	blobTypes := derefAll(filters.BlobTypes)
+	if len(blobTypes) > 0 && !slices.ContainsFunc(blobTypes, func(t string) bool { return strings.EqualFold(t, "blockBlob") }) {
+		return false
+	}
+	prefixes := derefAll(filters.PrefixMatch)
+	return len(prefixes) == 0 || slices.ContainsFunc(prefixes, func(p string) bool { return strings.HasPrefix(prefix, p) })
+}
@@ -0,0 +1,210 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testBackupAccountID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/backups"
+	testBlobContainerID = testBackupAccountID + "/blobServices/default/containers/velero"
+)
+
+// blobContainerAPIMock is a fake ARM with the blob containers and management policies in its
+// maps, keyed by container and storage account resource ID. Getting any other resource fails with
+// a 404, unless err is set.
+type blobContainerAPIMock struct {
+	containers map[string]*armstorage.BlobContainer
+	policies   map[string]*armstorage.ManagementPolicy
+	err        error
+}
+
+func (m blobContainerAPIMock) GetBlobContainer(containerID string) (*armstorage.BlobContainer, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.containers, containerID)
+}
+
+func (m blobContainerAPIMock) GetManagementPolicy(storageAccountID string) (*armstorage.ManagementPolicy, error) {
+	return getOrNotFound(m.policies, storageAccountID)
+}
+
+func newBlobContainer(state armstorage.ImmutabilityPolicyState, days int32) *armstorage.BlobContainer {
+	return &armstorage.BlobContainer{ContainerProperties: &armstorage.ContainerProperties{
+		HasImmutabilityPolicy: util.Ptr(true),
+		ImmutabilityPolicy: &armstorage.ImmutabilityPolicyProperties{Properties: &armstorage.ImmutabilityPolicyProperty{
+			ImmutabilityPeriodSinceCreationInDays: util.Ptr(days),
+			State:                                 util.Ptr(state),
+		}},
+	}}
+}
+
+func newManagementPolicy(rules ...*armstorage.ManagementPolicyRule) *armstorage.ManagementPolicy {
+	return &armstorage.ManagementPolicy{Properties: &armstorage.ManagementPolicyProperties{
+		Policy: &armstorage.ManagementPolicySchema{Rules: rules},
+	}}
+}
+
+// newLifecycleRule returns a lifecycle management rule for block blobs with the prefixes that
+// moves them to cool storage and deletes them the days after their last modification. Zero days
+// mean no action.
+func newLifecycleRule(name string, coolDays, deleteDays float32, prefixes ...string) *armstorage.ManagementPolicyRule {
+	base := &armstorage.ManagementPolicyBaseBlob{}
+	if coolDays > 0 {
+		base.TierToCool = &armstorage.DateAfterModification{DaysAfterModificationGreaterThan: util.Ptr(coolDays)}
+	}
+	if deleteDays > 0 {
+		base.Delete = &armstorage.DateAfterModification{DaysAfterModificationGreaterThan: util.Ptr(deleteDays)}
+	}
+	return &armstorage.ManagementPolicyRule{
+		Name:    util.Ptr(name),
+		Enabled: util.Ptr(true),
+		Type:    util.Ptr(armstorage.RuleTypeLifecycle),
+		Definition: &armstorage.ManagementPolicyDefinition{
+			Actions: &armstorage.ManagementPolicyAction{BaseBlob: base},
+			Filters: &armstorage.ManagementPolicyFilter{
+				BlobTypes:   to.SliceOfPtrs("blockBlob"),
+				PrefixMatch: to.SliceOfPtrs(prefixes...),
+			},
+		},
+	}
+}
+
+func TestBlobContainerRuleService_ReconcileBlobContainerRule(t *testing.T) {
+	locked := map[string]*armstorage.BlobContainer{testBlobContainerID: newBlobContainer(armstorage.ImmutabilityPolicyStateLocked, 30)}
+	lifecycle := &v1alpha1.BlobLifecycleRule{Prefix: "daily/", TierToCoolAfterDays: util.Ptr(int32(30)), DeleteAfterDays: util.Ptr(int32(365))}
+
+	tests := []struct {
+		name         string
+		api          blobContainerAPIMock
+		rule         v1alpha1.BlobContainerRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the immutability policy is locked and long enough, and a lifecycle rule acts soon enough.",
+			api: blobContainerAPIMock{
+				containers: locked,
+				policies: map[string]*armstorage.ManagementPolicy{
+					testBackupAccountID: newManagementPolicy(newLifecycleRule("cool", 7, 0, "velero/daily"), newLifecycleRule("expire", 0, 365, "velero")),
+				},
+			},
+			rule:         v1alpha1.BlobContainerRule{MinImmutabilityDays: 30, LifecycleRule: lifecycle},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes without a lifecycle rule.",
+			api:          blobContainerAPIMock{containers: locked},
+			rule:         v1alpha1.BlobContainerRule{MinImmutabilityDays: 7},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when the immutability policy is unlocked and too short.",
+			api: blobContainerAPIMock{
+				containers: map[string]*armstorage.BlobContainer{testBlobContainerID: newBlobContainer(armstorage.ImmutabilityPolicyStateUnlocked, 7)},
+			},
+			rule: v1alpha1.BlobContainerRule{MinImmutabilityDays: 30},
+			wantFailures: []string{
+				"Immutability policy of blob container " + testBlobContainerID + " is not locked: its state is Unlocked.",
+				"Immutability policy of blob container " + testBlobContainerID + " retains blobs for 7 day(s), fewer than the required 30.",
+			},
+		},
+		{
+			name: "Fails when the container has no immutability policy.",
+			api: blobContainerAPIMock{
+				containers: map[string]*armstorage.BlobContainer{testBlobContainerID: {ContainerProperties: &armstorage.ContainerProperties{HasImmutabilityPolicy: util.Ptr(false)}}},
+			},
+			rule:         v1alpha1.BlobContainerRule{MinImmutabilityDays: 30},
+			wantFailures: []string{"Blob container " + testBlobContainerID + " has no time-based immutability policy."},
+		},
+		{
+			name:         "Fails when the container is missing.",
+			api:          blobContainerAPIMock{},
+			rule:         v1alpha1.BlobContainerRule{MinImmutabilityDays: 30, LifecycleRule: lifecycle},
+			wantFailures: []string{"Blob container velero not found in storage account " + testBackupAccountID + "."},
+		},
+		{
+			name:         "Fails when the storage account has no lifecycle management policy.",
+			api:          blobContainerAPIMock{containers: locked},
+			rule:         v1alpha1.BlobContainerRule{MinImmutabilityDays: 30, LifecycleRule: lifecycle},
+			wantFailures: []string{"Storage account " + testBackupAccountID + " has no lifecycle management policy."},
+		},
+		{
+			name: "Fails when no enabled lifecycle rule applies to the blobs.",
+			api: blobContainerAPIMock{
+				containers: locked,
+				policies: map[string]*armstorage.ManagementPolicy{
+					testBackupAccountID: newManagementPolicy(
+						newLifecycleRule("other-container", 7, 365, "logs"),
+						newLifecycleRule("other-prefix", 7, 365, "velero/weekly"),
+						&armstorage.ManagementPolicyRule{Name: util.Ptr("disabled"), Enabled: util.Ptr(false), Definition: newLifecycleRule("", 7, 365).Definition},
+					),
+				},
+			},
+			rule:         v1alpha1.BlobContainerRule{MinImmutabilityDays: 30, LifecycleRule: lifecycle},
+			wantFailures: []string{"Storage account " + testBackupAccountID + " has no enabled lifecycle management rule for blobs with prefix velero/daily/."},
+		},
+		{
+			name: "Fails for each action that's missing or too late.",
+			api: blobContainerAPIMock{
+				containers: locked,
+				policies: map[string]*armstorage.ManagementPolicy{
+					testBackupAccountID: newManagementPolicy(newLifecycleRule("cool", 90, 0), newLifecycleRule("cooler", 60, 0, "velero/")),
+				},
+			},
+			rule: v1alpha1.BlobContainerRule{MinImmutabilityDays: 30, LifecycleRule: lifecycle},
+			wantFailures: []string{
+				"Lifecycle management rule cooler of storage account " + testBackupAccountID + " has a tierToCool action 60 day(s) after the last modification of blobs, later than the required 30.",
+				"No lifecycle management rule of storage account " + testBackupAccountID + " for blobs with prefix velero/daily/ has a delete action based on their last modification.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewBlobContainerRuleService(logr.Discard(), tt.api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.StorageAccountID = testBackupAccountID
+			tt.rule.ContainerName = "velero"
+			result, err := svc.ReconcileBlobContainerRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestBlobContainerRuleService_ReconcileBlobContainerRule_Error(t *testing.T) {
+	api := blobContainerAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewBlobContainerRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileBlobContainerRule(v1alpha1.BlobContainerRule{Name: "rule-1", StorageAccountID: testBackupAccountID, ContainerName: "velero", MinImmutabilityDays: 30})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,159 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// budgetAPI contains methods that allow getting the budgets defined at a scope.
+type budgetAPI interface {
+	ListBudgetsForScope(scope string) ([]*armconsumption.Budget, error)
+}
+
+type BudgetRuleService struct {
+	log logr.Logger
+	api budgetAPI
+}
+
+func NewBudgetRuleService(log logr.Logger, api budgetAPI) *BudgetRuleService {
+	return &BudgetRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileBudgetRule reconciles a budget rule from a validation config.
+func (s *BudgetRuleService) ReconcileBudgetRule(rule v1alpha1.BudgetRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this budget rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.BudgetSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeBudget
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeBudget, "scope", rule.Scope)
+	l.V(1).Info("Validating budgets")
+	ev := &evidence{}
+	if err := s.validateBudgets(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate budgets", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.BudgetFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateBudgets passes if any budget defined at the scope meets all of the rule's expectations.
+// Otherwise, it appends a failure for each expectation that each budget doesn't meet, or a single
+// failure if there are no budgets. A scope that doesn't exist is a failure, not an error.
+func (s *BudgetRuleService) validateBudgets(rule v1alpha1.BudgetRule, failures *[]string, ev *evidence) error {
+	budgets, err := s.api.ListBudgetsForScope(rule.Scope)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ScopeNotFoundFailure, rule.Scope))
+			return nil
+		}
+		return fmt.Errorf("failed to list budgets: %w", azure_errors.AsAugmented(err))
+	}
+	sortByID(budgets, func(b *armconsumption.Budget) *string { return b.ID })
+
+	ev.add("Examined %d budget(s) defined at scope %s.", len(budgets), rule.Scope)
+	if len(budgets) == 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.BudgetMissingFailure, rule.Scope))
+		return nil
+	}
+
+	var unmet []string
+	for _, b := range budgets {
+		problems := budgetProblems(rule, b)
+		if len(problems) == 0 {
+			ev.add("Budget %s meets all expectations.", strPtrValue(b.Name))
+			return nil
+		}
+		unmet = append(unmet, problems...)
+	}
+	*failures = append(*failures, unmet...)
+	return nil
+}
+
+// budgetProblems returns a description of each of the rule's expectations that a budget doesn't
+// meet.
+func budgetProblems(rule v1alpha1.BudgetRule, b *armconsumption.Budget) []string {
+	name := strPtrValue(b.Name)
+	props := b.Properties
+	if props == nil {
+		props = &armconsumption.BudgetProperties{}
+	}
+	var problems []string
+
+	amount := notSet
+	if props.Amount != nil {
+		amount = strconv.FormatFloat(*props.Amount, 'f', -1, 64)
+	}
+	if rule.MinAmount != nil && (props.Amount == nil || *props.Amount < float64(*rule.MinAmount)) {
+		problems = append(problems, fmt.Sprintf(messages.BudgetMinAmountFailure, name, amount, *rule.MinAmount))
+	}
+	if rule.MaxAmount != nil && (props.Amount == nil || *props.Amount > float64(*rule.MaxAmount)) {
+		problems = append(problems, fmt.Sprintf(messages.BudgetMaxAmountFailure, name, amount, *rule.MaxAmount))
+	}
+
+	// The CRD defaults the time grain and requireNotification, but rules built in code may leave
+	// them unset.
+	wantGrain := rule.TimeGrain
+	if wantGrain == "" {
+		wantGrain = string(armconsumption.TimeGrainTypeMonthly)
+	}
+	grain := notSet
+	if props.TimeGrain != nil {
+		grain = string(*props.TimeGrain)
+	}
+	if !strings.EqualFold(grain, wantGrain) {
+		problems = append(problems, fmt.Sprintf(messages.BudgetTimeGrainFailure, name, grain, wantGrain))
+	}
+
+	if (rule.RequireNotification == nil || *rule.RequireNotification) && !hasNotificationWithContact(props.Notifications) {
+		problems = append(problems, fmt.Sprintf(messages.BudgetNotificationFailure, name))
+	}
+	return problems
+}
+
+// hasNotificationWithContact reports whether any of a budget's notifications is enabled and is
+// sent to someone.
+func hasNotificationWithContact(notifications map[string]*armconsumption.Notification) bool {
+	for _, n := range notifications {
+		if n == nil || n.Enabled == nil || !*n.Enabled {
+			continue
+		}
+		if len(n.ContactEmails) > 0 || len(n.ContactGroups) > 0 || len(n.ContactRoles) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testBudgetScope = "/subscriptions/00000000-0000-0000-0000-000000000000"
+
+// budgetAPIMock is a fake Cost Management API with the budgets in data defined at testBudgetScope.
+// Listing the budgets of any other scope fails with a 404, unless err is set.
+type budgetAPIMock struct {
+	data []*armconsumption.Budget
+	err  error
+}
+
+func (m budgetAPIMock) ListBudgetsForScope(scope string) ([]*armconsumption.Budget, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if scope != testBudgetScope {
+		return nil, &azcore.ResponseError{ErrorCode: "SubscriptionNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+// newBudget returns a monthly budget with an amount and an enabled notification that emails
+// finance@example.com at 80% of the amount.
+func newBudget(name string, amount float64) *armconsumption.Budget {
+	return &armconsumption.Budget{
+		ID:   util.Ptr(testBudgetScope + "/providers/Microsoft.Consumption/budgets/" + name),
+		Name: util.Ptr(name),
+		Properties: &armconsumption.BudgetProperties{
+			Amount:    util.Ptr(amount),
+			Category:  util.Ptr(armconsumption.CategoryTypeCost),
+			TimeGrain: util.Ptr(armconsumption.TimeGrainTypeMonthly),
+			Notifications: map[string]*armconsumption.Notification{
+				"actual-80": {
+					Enabled:       util.Ptr(true),
+					Operator:      util.Ptr(armconsumption.OperatorTypeGreaterThan),
+					Threshold:     util.Ptr(80.0),
+					ContactEmails: []*string{util.Ptr("finance@example.com")},
+				},
+			},
+		},
+	}
+}
+
+func TestBudgetRuleService_ReconcileBudgetRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		budgets      []*armconsumption.Budget
+		scope        string
+		rule         v1alpha1.BudgetRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when a budget meets all expectations.",
+			budgets:      []*armconsumption.Budget{newBudget("monthly", 1000)},
+			rule:         v1alpha1.BudgetRule{MinAmount: util.Ptr[int64](500), MaxAmount: util.Ptr[int64](1000), TimeGrain: "Monthly"},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when any budget meets all expectations.",
+			budgets: []*armconsumption.Budget{
+				newBudget("a-too-small", 100),
+				newBudget("b-big-enough", 5000),
+			},
+			rule:         v1alpha1.BudgetRule{MinAmount: util.Ptr[int64](1000)},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when no budget is defined.",
+			rule:         v1alpha1.BudgetRule{},
+			wantFailures: []string{"No budget is defined at scope " + testBudgetScope + "."},
+		},
+		{
+			name:    "Fails when no budget has an amount in range.",
+			budgets: []*armconsumption.Budget{newBudget("small", 100), newBudget("large", 100000)},
+			rule:    v1alpha1.BudgetRule{MinAmount: util.Ptr[int64](500), MaxAmount: util.Ptr[int64](10000)},
+			wantFailures: []string{
+				"Budget large has amount 100000, but an amount of at most 10000 is required.",
+				"Budget small has amount 100, but an amount of at least 500 is required.",
+			},
+		},
+		{
+			name: "Fails when the budget has another time grain.",
+			budgets: []*armconsumption.Budget{func() *armconsumption.Budget {
+				b := newBudget("quarterly", 3000)
+				b.Properties.TimeGrain = util.Ptr(armconsumption.TimeGrainTypeQuarterly)
+				return b
+			}()},
+			rule:         v1alpha1.BudgetRule{},
+			wantFailures: []string{"Budget quarterly has time grain Quarterly, but time grain Monthly is required."},
+		},
+		{
+			name: "Fails when the budget has no enabled notification with a contact.",
+			budgets: []*armconsumption.Budget{func() *armconsumption.Budget {
+				b := newBudget("silent", 1000)
+				b.Properties.Notifications["actual-80"].Enabled = util.Ptr(false)
+				b.Properties.Notifications["forecast-100"] = &armconsumption.Notification{Enabled: util.Ptr(true), Threshold: util.Ptr(100.0)}
+				return b
+			}()},
+			rule:         v1alpha1.BudgetRule{},
+			wantFailures: []string{"Budget silent has no enabled notification with a contact email, group, or role."},
+		},
+		{
+			name: "Passes without notifications when they aren't required.",
+			budgets: []*armconsumption.Budget{func() *armconsumption.Budget {
+				b := newBudget("silent", 1000)
+				b.Properties.Notifications = nil
+				return b
+			}()},
+			rule:         v1alpha1.BudgetRule{RequireNotification: util.Ptr(false)},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when a notification is sent to a role.",
+			budgets: []*armconsumption.Budget{func() *armconsumption.Budget {
+				b := newBudget("roles", 1000)
+				b.Properties.Notifications["actual-80"].ContactEmails = nil
+				b.Properties.Notifications["actual-80"].ContactRoles = []*string{util.Ptr("Owner")}
+				return b
+			}()},
+			rule:         v1alpha1.BudgetRule{},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the scope doesn't exist.",
+			scope:        "/subscriptions/11111111-1111-1111-1111-111111111111",
+			rule:         v1alpha1.BudgetRule{},
+			wantFailures: []string{"Scope /subscriptions/11111111-1111-1111-1111-111111111111 not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewBudgetRuleService(logr.Discard(), budgetAPIMock{data: tt.budgets})
+			rule := tt.rule
+			rule.Name = "rule-1"
+			rule.Scope = tt.scope
+			if rule.Scope == "" {
+				rule.Scope = testBudgetScope
+			}
+
+			result, err := svc.ReconcileBudgetRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestBudgetRuleService_ReconcileBudgetRule_Error(t *testing.T) {
+	api := budgetAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewBudgetRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileBudgetRule(v1alpha1.BudgetRule{Name: "rule-1", Scope: testBudgetScope})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,97 @@
+package validators
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failureSlices are the expressions that failures are appended to, directly or before they're
+// appended to a condition's failures.
+var failureSlices = map[string]bool{
+	"latestCondition.Failures": true,
+	"*failures":                true,
+	"failures":                 true,
+	"outside":                  true,
+	"problems":                 true,
+	"mismatches":               true,
+}
+
+// fromCatalog reports whether an expression is a message of the catalog, or is rendered from one
+// with fmt.Sprintf.
+func fromCatalog(expr ast.Expr) bool {
+	if call, ok := expr.(*ast.CallExpr); ok && exprString(call.Fun) == "fmt.Sprintf" && len(call.Args) > 0 {
+		expr = call.Args[0]
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "messages"
+}
+
+// builtHere reports whether an expression builds a string from a literal, rather than the catalog.
+func builtHere(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING
+	case *ast.BinaryExpr:
+		return builtHere(e.X) || builtHere(e.Y)
+	case *ast.CallExpr:
+		return exprString(e.Fun) == "fmt.Sprintf" && len(e.Args) > 0 && builtHere(e.Args[0])
+	}
+	return false
+}
+
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	}
+	return ""
+}
+
+func TestRuleServices_UseMessageCatalog(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", file, err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == "Message" && i < len(n.Rhs) && !fromCatalog(n.Rhs[i]) {
+						t.Errorf("%s: condition message isn't from the message catalog", fset.Position(n.Pos()))
+					}
+				}
+			case *ast.CallExpr:
+				if exprString(n.Fun) != "append" || len(n.Args) < 2 || !failureSlices[exprString(n.Args[0])] {
+					return true
+				}
+				for _, arg := range n.Args[1:] {
+					if builtHere(arg) {
+						t.Errorf("%s: failure isn't from the message catalog", fset.Position(arg.Pos()))
+					}
+				}
+			}
+			return true
+		})
+	}
+}
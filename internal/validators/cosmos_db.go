@@ -0,0 +1,169 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// databaseAccountAPI contains methods that allow getting a Cosmos DB account by its resource ID.
+type databaseAccountAPI interface {
+	GetDatabaseAccount(accountID string) (*armcosmos.DatabaseAccountGetResults, error)
+}
+
+type CosmosDBRuleService struct {
+	log logr.Logger
+	api databaseAccountAPI
+}
+
+func NewCosmosDBRuleService(log logr.Logger, api databaseAccountAPI) *CosmosDBRuleService {
+	return &CosmosDBRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileCosmosDBRule reconciles a Cosmos DB rule from a validation config.
+func (s *CosmosDBRuleService) ReconcileCosmosDBRule(rule v1alpha1.CosmosDBRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this Cosmos DB rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.CosmosDBSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeCosmosDB
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeCosmosDB, "databaseAccountID", rule.DatabaseAccountID)
+	l.V(1).Info("Validating Cosmos DB account")
+	ev := &evidence{}
+	if err := s.validateDatabaseAccount(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate Cosmos DB account", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.CosmosDBFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateDatabaseAccount appends a failure for each of the rule's settings that the account
+// doesn't have, with the account's actual value. An account that doesn't exist is a failure, not
+// an error.
+func (s *CosmosDBRuleService) validateDatabaseAccount(rule v1alpha1.CosmosDBRule, failures *[]string, ev *evidence) error {
+	account, err := s.api.GetDatabaseAccount(rule.DatabaseAccountID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.CosmosDBAccountNotFoundFailure, rule.DatabaseAccountID))
+			return nil
+		}
+		return fmt.Errorf("failed to get Cosmos DB account: %w", azure_errors.AsAugmented(err))
+	}
+	props := account.Properties
+	if props == nil {
+		props = &armcosmos.DatabaseAccountGetProperties{}
+	}
+
+	var capabilities []string
+	for _, c := range props.Capabilities {
+		if c != nil && c.Name != nil {
+			capabilities = append(capabilities, *c.Name)
+		}
+	}
+	if missing := missingItems(rule.Capabilities, capabilities, strings.ToLower); len(missing) > 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.CosmosDBCapabilitiesFailure, rule.DatabaseAccountID, strings.Join(missing, ", "), joinOrNone(capabilities)))
+	}
+
+	consistency := notSet
+	if props.ConsistencyPolicy != nil && props.ConsistencyPolicy.DefaultConsistencyLevel != nil {
+		consistency = string(*props.ConsistencyPolicy.DefaultConsistencyLevel)
+	}
+	if rule.ConsistencyLevel != "" && !strings.EqualFold(consistency, rule.ConsistencyLevel) {
+		*failures = append(*failures, fmt.Sprintf(messages.CosmosDBConsistencyFailure, rule.DatabaseAccountID, consistency, rule.ConsistencyLevel))
+	}
+
+	publicAccess := notSet
+	if props.PublicNetworkAccess != nil {
+		publicAccess = string(*props.PublicNetworkAccess)
+	}
+	if rule.PublicNetworkAccess != "" && !strings.EqualFold(publicAccess, rule.PublicNetworkAccess) {
+		*failures = append(*failures, fmt.Sprintf(messages.CosmosDBPublicNetworkAccessFailure, rule.DatabaseAccountID, publicAccess, rule.PublicNetworkAccess))
+	}
+
+	var locations []string
+	for _, loc := range props.Locations {
+		if loc != nil && loc.LocationName != nil {
+			locations = append(locations, *loc.LocationName)
+		}
+	}
+	if missing := missingItems(rule.Locations, locations, normalizeLocation); len(missing) > 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.CosmosDBLocationsFailure, rule.DatabaseAccountID, strings.Join(missing, ", "), joinOrNone(locations)))
+	}
+
+	approved := 0
+	for _, conn := range props.PrivateEndpointConnections {
+		if conn != nil && conn.Properties != nil && conn.Properties.PrivateLinkServiceConnectionState != nil &&
+			strings.EqualFold(strPtrValue(conn.Properties.PrivateLinkServiceConnectionState.Status), "Approved") {
+			approved++
+		}
+	}
+	if rule.RequirePrivateEndpoint && approved == 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.CosmosDBPrivateEndpointFailure, rule.DatabaseAccountID, len(props.PrivateEndpointConnections)))
+	}
+
+	ev.add("Cosmos DB account %s has capabilities %s, default consistency level %s, public network access %s, locations %s, and %d approved private endpoint connection(s).",
+		rule.DatabaseAccountID, joinOrNone(capabilities), consistency, publicAccess, joinOrNone(locations), approved)
+	return nil
+}
+
+// missingItems returns the items of want that aren't in have, in the order of want, comparing them
+// after normalizing them with normalize.
+func missingItems(want, have []string, normalize func(string) string) []string {
+	normalized := make([]string, len(have))
+	for i, h := range have {
+		normalized[i] = normalize(h)
+	}
+	var missing []string
+	for _, w := range want {
+		if !slices.Contains(normalized, normalize(w)) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
+
+// normalizeLocation turns a region's display name (e.g. East US) into its name (e.g. eastus), and
+// leaves names as they are, apart from their case.
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
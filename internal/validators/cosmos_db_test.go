@@ -0,0 +1,223 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testCosmosDBAccountID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.DocumentDB/databaseAccounts/account-1"
+
+// databaseAccountAPIMock is a fake ARM with the Cosmos DB accounts in its map, keyed by resource
+// ID. Getting any other account fails with a 404, unless err is set.
+type databaseAccountAPIMock struct {
+	accounts map[string]*armcosmos.DatabaseAccountGetResults
+	err      error
+}
+
+func (m databaseAccountAPIMock) GetDatabaseAccount(accountID string) (*armcosmos.DatabaseAccountGetResults, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	account, ok := m.accounts[accountID]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return account, nil
+}
+
+// newDatabaseAccount returns a Cosmos DB account with Session consistency, public network access
+// disabled, the EnableServerless and EnableMongo capabilities, locations East US and West US, and
+// an approved private endpoint connection.
+func newDatabaseAccount() *armcosmos.DatabaseAccountGetResults {
+	return &armcosmos.DatabaseAccountGetResults{
+		Properties: &armcosmos.DatabaseAccountGetProperties{
+			Capabilities: []*armcosmos.Capability{
+				{Name: util.Ptr("EnableServerless")},
+				{Name: util.Ptr("EnableMongo")},
+			},
+			ConsistencyPolicy: &armcosmos.ConsistencyPolicy{
+				DefaultConsistencyLevel: util.Ptr(armcosmos.DefaultConsistencyLevelSession),
+			},
+			PublicNetworkAccess: util.Ptr(armcosmos.PublicNetworkAccessDisabled),
+			Locations: []*armcosmos.Location{
+				{LocationName: util.Ptr("East US")},
+				{LocationName: util.Ptr("West US")},
+			},
+			PrivateEndpointConnections: []*armcosmos.PrivateEndpointConnection{
+				newCosmosDBPrivateEndpointConnection("Approved"),
+			},
+		},
+	}
+}
+
+func newCosmosDBPrivateEndpointConnection(status string) *armcosmos.PrivateEndpointConnection {
+	return &armcosmos.PrivateEndpointConnection{
+		Properties: &armcosmos.PrivateEndpointConnectionProperties{
+			PrivateLinkServiceConnectionState: &armcosmos.PrivateLinkServiceConnectionStateProperty{Status: util.Ptr(status)},
+		},
+	}
+}
+
+func TestCosmosDBRuleService_ReconcileCosmosDBRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		account      *armcosmos.DatabaseAccountGetResults
+		rule         v1alpha1.CosmosDBRule
+		wantFailures []string
+	}{
+		{
+			name:    "Passes when the account is configured as expected.",
+			account: newDatabaseAccount(),
+			rule: v1alpha1.CosmosDBRule{
+				Capabilities:           []string{"EnableServerless", "EnableMongo"},
+				ConsistencyLevel:       "Session",
+				PublicNetworkAccess:    "Disabled",
+				Locations:              []string{"eastus", "West US"},
+				RequirePrivateEndpoint: true,
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the rule's capabilities are in another order and case.",
+			account:      newDatabaseAccount(),
+			rule:         v1alpha1.CosmosDBRule{Capabilities: []string{"enablemongo", "EnableServerless"}},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the rule doesn't check anything.",
+			account:      &armcosmos.DatabaseAccountGetResults{},
+			rule:         v1alpha1.CosmosDBRule{},
+			wantFailures: []string{},
+		},
+		{
+			name:    "Fails when the account lacks some of the rule's capabilities.",
+			account: newDatabaseAccount(),
+			rule:    v1alpha1.CosmosDBRule{Capabilities: []string{"EnableCassandra", "EnableMongo", "EnableTable"}},
+			wantFailures: []string{
+				"Cosmos DB account " + testCosmosDBAccountID + " lacks capabilities EnableCassandra, EnableTable; it has EnableServerless, EnableMongo.",
+			},
+		},
+		{
+			name:    "Fails when the account has another consistency level and public network access.",
+			account: newDatabaseAccount(),
+			rule:    v1alpha1.CosmosDBRule{ConsistencyLevel: "Strong", PublicNetworkAccess: "Enabled"},
+			wantFailures: []string{
+				"Cosmos DB account " + testCosmosDBAccountID + " has default consistency level Session, not Strong.",
+				"Cosmos DB account " + testCosmosDBAccountID + " has public network access Disabled, not Enabled.",
+			},
+		},
+		{
+			name:    "Fails when the account has no capabilities, consistency policy, or public network access.",
+			account: &armcosmos.DatabaseAccountGetResults{Properties: &armcosmos.DatabaseAccountGetProperties{}},
+			rule:    v1alpha1.CosmosDBRule{Capabilities: []string{"EnableServerless"}, ConsistencyLevel: "Session", PublicNetworkAccess: "Disabled"},
+			wantFailures: []string{
+				"Cosmos DB account " + testCosmosDBAccountID + " lacks capabilities EnableServerless; it has none.",
+				"Cosmos DB account " + testCosmosDBAccountID + " has default consistency level (not set), not Session.",
+				"Cosmos DB account " + testCosmosDBAccountID + " has public network access (not set), not Disabled.",
+			},
+		},
+		{
+			name:    "Fails when the account isn't enabled in some of the rule's locations.",
+			account: newDatabaseAccount(),
+			rule:    v1alpha1.CosmosDBRule{Locations: []string{"westus", "North Europe"}},
+			wantFailures: []string{
+				"Cosmos DB account " + testCosmosDBAccountID + " isn't enabled in location(s) North Europe; it's enabled in East US, West US.",
+			},
+		},
+		{
+			name: "Fails when the account's private endpoint connections aren't approved.",
+			account: func() *armcosmos.DatabaseAccountGetResults {
+				a := newDatabaseAccount()
+				a.Properties.PrivateEndpointConnections = []*armcosmos.PrivateEndpointConnection{
+					newCosmosDBPrivateEndpointConnection("Pending"),
+					newCosmosDBPrivateEndpointConnection("Rejected"),
+				}
+				return a
+			}(),
+			rule: v1alpha1.CosmosDBRule{RequirePrivateEndpoint: true},
+			wantFailures: []string{
+				"Cosmos DB account " + testCosmosDBAccountID + " has no approved private endpoint connection; it has 2 private endpoint connection(s) in all.",
+			},
+		},
+		{
+			name:         "Fails when the account is missing.",
+			rule:         v1alpha1.CosmosDBRule{ConsistencyLevel: "Session"},
+			wantFailures: []string{"Cosmos DB account " + testCosmosDBAccountID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]*armcosmos.DatabaseAccountGetResults{}
+			if tt.account != nil {
+				accounts[testCosmosDBAccountID] = tt.account
+			}
+			svc := NewCosmosDBRuleService(logr.Discard(), databaseAccountAPIMock{accounts: accounts})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.DatabaseAccountID = testCosmosDBAccountID
+			result, err := svc.ReconcileCosmosDBRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestCosmosDBRuleService_ReconcileCosmosDBRule_Error(t *testing.T) {
+	api := databaseAccountAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewCosmosDBRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileCosmosDBRule(v1alpha1.CosmosDBRule{Name: "rule-1", DatabaseAccountID: testCosmosDBAccountID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
+
+func Test_missingItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      []string
+		have      []string
+		normalize func(string) string
+		missing   []string
+	}{
+		{name: "same order", want: []string{"a", "b"}, have: []string{"a", "b"}, normalize: strings.ToLower},
+		{name: "other order", want: []string{"b", "a"}, have: []string{"a", "c", "b"}, normalize: strings.ToLower},
+		{name: "other case", want: []string{"EnableMongo"}, have: []string{"enablemongo"}, normalize: strings.ToLower},
+		{name: "some missing, in the order wanted", want: []string{"c", "a", "d"}, have: []string{"a", "b"}, normalize: strings.ToLower, missing: []string{"c", "d"}},
+		{name: "none had", want: []string{"a"}, normalize: strings.ToLower, missing: []string{"a"}},
+		{name: "location display names", want: []string{"eastus", "West Europe"}, have: []string{"East US", "westeurope"}, normalize: normalizeLocation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingItems(tt.want, tt.have, tt.normalize); !reflect.DeepEqual(got, tt.missing) {
+				t.Errorf("missingItems() = %v, want %v", got, tt.missing)
+			}
+		})
+	}
+}
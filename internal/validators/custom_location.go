@@ -0,0 +1,203 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// The states of Arc resources that are ready to be deployed to. Arc resource bridges report
+// Running once they're connected and have started their cluster.
+const (
+	arcSucceeded = "Succeeded"
+	arcConnected = "Connected"
+	arcRunning   = "Running"
+)
+
+// arcAPI contains methods that allow getting Azure Arc custom locations, and their cluster
+// extensions and hosts, by their resource IDs.
+type arcAPI interface {
+	GetCustomLocation(customLocationID string) (*azure_utils.CustomLocation, error)
+	GetClusterExtension(extensionID string) (*azure_utils.ClusterExtension, error)
+	GetArcHost(hostID string) (*azure_utils.ArcHost, error)
+}
+
+type CustomLocationRuleService struct {
+	log    logr.Logger
+	arcAPI arcAPI
+}
+
+func NewCustomLocationRuleService(log logr.Logger, arcAPI arcAPI) *CustomLocationRuleService {
+	return &CustomLocationRuleService{
+		log:    log,
+		arcAPI: arcAPI,
+	}
+}
+
+// ReconcileCustomLocationRule reconciles a custom location rule from a validation config.
+func (s *CustomLocationRuleService) ReconcileCustomLocationRule(rule v1alpha1.CustomLocationRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this custom location rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.CustomLocationSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeCustomLocation
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeCustomLocation, "customLocationID", rule.CustomLocationID)
+	l.V(1).Info("Validating custom location")
+	ev := &evidence{}
+	if err := s.validateCustomLocation(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate custom location", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.arcAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.CustomLocationFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateCustomLocation appends a failure if the custom location isn't provisioned, and then
+// validates its cluster extensions and its host, each of which has failures of its own. A custom
+// location that doesn't exist is a failure, not an error, and leaves nothing else to validate.
+func (s *CustomLocationRuleService) validateCustomLocation(rule v1alpha1.CustomLocationRule, failures *[]string, ev *evidence) error {
+	cl, err := s.arcAPI.GetCustomLocation(rule.CustomLocationID)
+	if err != nil {
+		if isNotFound(err) {
+			*failures = append(*failures, fmt.Sprintf(messages.CustomLocationNotFoundFailure, rule.CustomLocationID))
+			return nil
+		}
+		return fmt.Errorf("failed to get custom location: %w", azure_errors.AsAugmented(err))
+	}
+
+	provisioningState := orNotSet(cl.Properties.ProvisioningState)
+	if provisioningState != arcSucceeded {
+		*failures = append(*failures, fmt.Sprintf(messages.CustomLocationProvisioningFailure, rule.CustomLocationID, provisioningState))
+	}
+	ev.add("Custom location %s has provisioning state %s, host %s, and %d cluster extensions.", rule.CustomLocationID, provisioningState, orNotSet(cl.Properties.HostResourceID), len(cl.Properties.ClusterExtensionIDs))
+
+	if err := s.validateExtensions(rule, cl, failures, ev); err != nil {
+		return err
+	}
+	return s.validateHost(rule, cl.Properties.HostResourceID, failures, ev)
+}
+
+// validateExtensions appends a failure for each of the rule's extension types that none of the
+// custom location's cluster extensions is of, and one for each extension of those types that
+// isn't provisioned. If the rule has no extension types, each of the custom location's cluster
+// extensions must be provisioned. Extensions that the custom location lists but that don't exist
+// aren't of any type.
+func (s *CustomLocationRuleService) validateExtensions(rule v1alpha1.CustomLocationRule, cl *azure_utils.CustomLocation, failures *[]string, ev *evidence) error {
+	var types []string
+	found := map[string]bool{}
+	for _, id := range cl.Properties.ClusterExtensionIDs {
+		ext, err := s.arcAPI.GetClusterExtension(id)
+		if err != nil {
+			if isNotFound(err) {
+				ev.add("Cluster extension %s of custom location %s not found.", id, rule.CustomLocationID)
+				continue
+			}
+			return fmt.Errorf("failed to get cluster extension: %w", azure_errors.AsAugmented(err))
+		}
+		extType := orNotSet(ext.Properties.ExtensionType)
+		types = append(types, extType)
+		if len(rule.ExtensionTypes) > 0 && !slices.ContainsFunc(rule.ExtensionTypes, func(t string) bool { return strings.EqualFold(t, extType) }) {
+			continue
+		}
+		found[strings.ToLower(extType)] = true
+		provisioningState := orNotSet(ext.Properties.ProvisioningState)
+		if provisioningState != arcSucceeded {
+			*failures = append(*failures, fmt.Sprintf(messages.ClusterExtensionProvisioningFailure, id, extType, provisioningState))
+			continue
+		}
+		ev.add("Cluster extension %s of type %s has provisioning state %s.", id, extType, provisioningState)
+	}
+
+	for _, t := range rule.ExtensionTypes {
+		if found[strings.ToLower(t)] {
+			continue
+		}
+		has := "none"
+		if len(types) > 0 {
+			has = strings.Join(types, ", ")
+		}
+		*failures = append(*failures, fmt.Sprintf(messages.ClusterExtensionMissingFailure, rule.CustomLocationID, t, has))
+	}
+	return nil
+}
+
+// validateHost appends a failure if the custom location's host, an Arc resource bridge or an
+// Arc-enabled Kubernetes cluster, isn't connected, with its actual status. A host that doesn't
+// exist, or that's of another type, is a failure, not an error.
+func (s *CustomLocationRuleService) validateHost(rule v1alpha1.CustomLocationRule, hostID string, failures *[]string, ev *evidence) error {
+	if hostID == "" {
+		*failures = append(*failures, fmt.Sprintf(messages.CustomLocationNoHostFailure, rule.CustomLocationID))
+		return nil
+	}
+	if !azure_utils.IsArcHost(hostID) {
+		*failures = append(*failures, fmt.Sprintf(messages.CustomLocationHostTypeFailure, rule.CustomLocationID, hostID, azure_utils.ResourceBridgeType, azure_utils.ConnectedClusterType))
+		return nil
+	}
+	host, err := s.arcAPI.GetArcHost(hostID)
+	if err != nil {
+		if isNotFound(err) {
+			*failures = append(*failures, fmt.Sprintf(messages.CustomLocationHostNotFoundFailure, hostID, rule.CustomLocationID))
+			return nil
+		}
+		return fmt.Errorf("failed to get custom location host: %w", azure_errors.AsAugmented(err))
+	}
+
+	if strings.Contains(strings.ToLower(hostID), "/providers/"+strings.ToLower(azure_utils.ResourceBridgeType)+"/") {
+		status := orNotSet(host.Properties.Status)
+		if status != arcRunning && status != arcConnected {
+			*failures = append(*failures, fmt.Sprintf(messages.ResourceBridgeStatusFailure, hostID, status))
+			return nil
+		}
+		ev.add("Arc resource bridge %s has status %s.", hostID, status)
+		return nil
+	}
+	status := orNotSet(host.Properties.ConnectivityStatus)
+	if status != arcConnected {
+		*failures = append(*failures, fmt.Sprintf(messages.ConnectedClusterStatusFailure, hostID, status))
+		return nil
+	}
+	ev.add("Arc-enabled Kubernetes cluster %s has connectivity status %s.", hostID, status)
+	return nil
+}
+
+// isNotFound returns whether err is ARM's response that a resource doesn't exist.
+func isNotFound(err error) bool {
+	var rerr *azcore.ResponseError
+	return errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound
+}
+
+// orNotSet returns s, or notSet if it's empty.
+func orNotSet(s string) string {
+	if s == "" {
+		return notSet
+	}
+	return s
+}
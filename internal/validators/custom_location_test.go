@@ -0,0 +1,250 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+const (
+	testCustomLocationID   = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.ExtendedLocation/customLocations/cl-1"
+	testResourceBridgeID   = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.ResourceConnector/appliances/bridge-1"
+	testConnectedClusterID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Kubernetes/connectedClusters/cluster-1"
+	testHCIExtensionID     = testResourceBridgeID + "/providers/Microsoft.KubernetesConfiguration/extensions/hci-operator"
+	testVMExtensionID      = testResourceBridgeID + "/providers/Microsoft.KubernetesConfiguration/extensions/vm-operator"
+	testHCIExtensionType   = "microsoft.azstackhci.operator"
+	testVMExtensionType    = "microsoft.vmware.vmoperator"
+)
+
+// arcAPIMock is a fake ARM with the custom locations, cluster extensions, and hosts in its maps,
+// keyed by resource ID. Getting any other resource fails with a 404, unless err is set.
+type arcAPIMock struct {
+	customLocations map[string]*azure_utils.CustomLocation
+	extensions      map[string]*azure_utils.ClusterExtension
+	hosts           map[string]*azure_utils.ArcHost
+	err             error
+}
+
+func (m arcAPIMock) GetCustomLocation(customLocationID string) (*azure_utils.CustomLocation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.customLocations, customLocationID)
+}
+
+func (m arcAPIMock) GetClusterExtension(extensionID string) (*azure_utils.ClusterExtension, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.extensions, extensionID)
+}
+
+func (m arcAPIMock) GetArcHost(hostID string) (*azure_utils.ArcHost, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.hosts, hostID)
+}
+
+func newCustomLocation(provisioningState, hostID string, extensionIDs ...string) *azure_utils.CustomLocation {
+	return &azure_utils.CustomLocation{
+		ID: testCustomLocationID,
+		Properties: azure_utils.CustomLocationProperties{
+			ProvisioningState:   provisioningState,
+			HostResourceID:      hostID,
+			ClusterExtensionIDs: extensionIDs,
+		},
+	}
+}
+
+func newClusterExtension(extensionType, provisioningState string) *azure_utils.ClusterExtension {
+	return &azure_utils.ClusterExtension{
+		Properties: azure_utils.ClusterExtensionProperties{ExtensionType: extensionType, ProvisioningState: provisioningState},
+	}
+}
+
+func TestCustomLocationRuleService_ReconcileCustomLocationRule(t *testing.T) {
+	bridge := func(status string) *azure_utils.ArcHost {
+		return &azure_utils.ArcHost{Properties: azure_utils.ArcHostProperties{ProvisioningState: "Succeeded", Status: status}}
+	}
+	cluster := func(status string) *azure_utils.ArcHost {
+		return &azure_utils.ArcHost{Properties: azure_utils.ArcHostProperties{ProvisioningState: "Succeeded", ConnectivityStatus: status}}
+	}
+	extensions := map[string]*azure_utils.ClusterExtension{
+		testHCIExtensionID: newClusterExtension(testHCIExtensionType, "Succeeded"),
+		testVMExtensionID:  newClusterExtension(testVMExtensionType, "Succeeded"),
+	}
+
+	tests := []struct {
+		name           string
+		customLocation *azure_utils.CustomLocation
+		extensions     map[string]*azure_utils.ClusterExtension
+		hosts          map[string]*azure_utils.ArcHost
+		extensionTypes []string
+		wantFailures   []string
+	}{
+		{
+			name:           "Passes when the custom location, its extension, and its resource bridge are ready.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID, testHCIExtensionID),
+			extensions:     extensions,
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Running")},
+			extensionTypes: []string{"Microsoft.AzStackHCI.Operator"},
+			wantFailures:   []string{},
+		},
+		{
+			name:           "Passes when every extension is provisioned and the connected cluster is connected, without extension types.",
+			customLocation: newCustomLocation("Succeeded", testConnectedClusterID, testHCIExtensionID, testVMExtensionID),
+			extensions:     extensions,
+			hosts:          map[string]*azure_utils.ArcHost{testConnectedClusterID: cluster("Connected")},
+			wantFailures:   []string{},
+		},
+		{
+			name:           "Passes when an extension of another type isn't provisioned.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID, testHCIExtensionID, testVMExtensionID),
+			extensions: map[string]*azure_utils.ClusterExtension{
+				testHCIExtensionID: newClusterExtension(testHCIExtensionType, "Succeeded"),
+				testVMExtensionID:  newClusterExtension(testVMExtensionType, "Failed"),
+			},
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Connected")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures:   []string{},
+		},
+		{
+			name:         "Fails when the custom location is missing.",
+			wantFailures: []string{"Custom location " + testCustomLocationID + " not found."},
+		},
+		{
+			name:           "Fails when the custom location isn't provisioned.",
+			customLocation: newCustomLocation("Failed", testResourceBridgeID),
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Running")},
+			wantFailures:   []string{"Custom location " + testCustomLocationID + " has provisioning state Failed, not Succeeded."},
+		},
+		{
+			name:           "Fails when the extension is missing, with the types the custom location has.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID, testVMExtensionID),
+			extensions:     extensions,
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Running")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures: []string{
+				"Custom location " + testCustomLocationID + " has no cluster extension of type " + testHCIExtensionType + "; its cluster extensions are of types: " + testVMExtensionType + ".",
+			},
+		},
+		{
+			name:           "Fails when the custom location has no extensions.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID),
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Running")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures: []string{
+				"Custom location " + testCustomLocationID + " has no cluster extension of type " + testHCIExtensionType + "; its cluster extensions are of types: none.",
+			},
+		},
+		{
+			name:           "Fails when an extension the custom location lists doesn't exist.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID, testHCIExtensionID),
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Running")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures: []string{
+				"Custom location " + testCustomLocationID + " has no cluster extension of type " + testHCIExtensionType + "; its cluster extensions are of types: none.",
+			},
+		},
+		{
+			name:           "Fails when the extension isn't provisioned.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID, testHCIExtensionID),
+			extensions:     map[string]*azure_utils.ClusterExtension{testHCIExtensionID: newClusterExtension(testHCIExtensionType, "Failed")},
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Running")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures: []string{
+				"Cluster extension " + testHCIExtensionID + " of type " + testHCIExtensionType + " has provisioning state Failed, not Succeeded.",
+			},
+		},
+		{
+			name:           "Fails when the resource bridge is disconnected.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID, testHCIExtensionID),
+			extensions:     extensions,
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("Offline")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures:   []string{"Arc resource bridge " + testResourceBridgeID + " has status Offline, not Running or Connected."},
+		},
+		{
+			name:           "Fails when the connected cluster is disconnected.",
+			customLocation: newCustomLocation("Succeeded", testConnectedClusterID),
+			hosts:          map[string]*azure_utils.ArcHost{testConnectedClusterID: cluster("Offline")},
+			wantFailures:   []string{"Arc-enabled Kubernetes cluster " + testConnectedClusterID + " has connectivity status Offline, not Connected."},
+		},
+		{
+			name:           "Fails when the resource bridge is missing.",
+			customLocation: newCustomLocation("Succeeded", testResourceBridgeID),
+			wantFailures:   []string{"Host " + testResourceBridgeID + " of custom location " + testCustomLocationID + " not found."},
+		},
+		{
+			name:           "Fails when the host isn't an Arc host.",
+			customLocation: newCustomLocation("Succeeded", "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1"),
+			wantFailures: []string{
+				"Custom location " + testCustomLocationID + " has host /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1, which isn't an Arc resource bridge (Microsoft.ResourceConnector/appliances) or an Arc-enabled Kubernetes cluster (Microsoft.Kubernetes/connectedClusters).",
+			},
+		},
+		{
+			name:           "Fails with each layer that isn't ready.",
+			customLocation: newCustomLocation("Provisioning", testResourceBridgeID, testHCIExtensionID),
+			extensions:     map[string]*azure_utils.ClusterExtension{testHCIExtensionID: newClusterExtension(testHCIExtensionType, "Creating")},
+			hosts:          map[string]*azure_utils.ArcHost{testResourceBridgeID: bridge("")},
+			extensionTypes: []string{testHCIExtensionType},
+			wantFailures: []string{
+				"Custom location " + testCustomLocationID + " has provisioning state Provisioning, not Succeeded.",
+				"Cluster extension " + testHCIExtensionID + " of type " + testHCIExtensionType + " has provisioning state Creating, not Succeeded.",
+				"Arc resource bridge " + testResourceBridgeID + " has status (not set), not Running or Connected.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := arcAPIMock{
+				customLocations: map[string]*azure_utils.CustomLocation{},
+				extensions:      tt.extensions,
+				hosts:           tt.hosts,
+			}
+			if tt.customLocation != nil {
+				api.customLocations[testCustomLocationID] = tt.customLocation
+			}
+			svc := NewCustomLocationRuleService(logr.Discard(), api)
+
+			rule := v1alpha1.CustomLocationRule{Name: "rule-1", CustomLocationID: testCustomLocationID, ExtensionTypes: tt.extensionTypes}
+			result, err := svc.ReconcileCustomLocationRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestCustomLocationRuleService_ReconcileCustomLocationRule_Error(t *testing.T) {
+	api := arcAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewCustomLocationRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileCustomLocationRule(v1alpha1.CustomLocationRule{Name: "rule-1", CustomLocationID: testCustomLocationID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,112 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// virtualNetworkAPI contains methods that allow getting a virtual network by its resource ID.
+type virtualNetworkAPI interface {
+	GetVirtualNetwork(virtualNetworkID string) (*armnetwork.VirtualNetwork, error)
+}
+
+type DDoSProtectionRuleService struct {
+	log logr.Logger
+	api virtualNetworkAPI
+}
+
+func NewDDoSProtectionRuleService(log logr.Logger, api virtualNetworkAPI) *DDoSProtectionRuleService {
+	return &DDoSProtectionRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileDDoSProtectionRule reconciles a DDoS protection rule from a validation config.
+func (s *DDoSProtectionRuleService) ReconcileDDoSProtectionRule(rule v1alpha1.DDoSProtectionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this DDoS protection rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.DDoSProtectionSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeDDoSProtection
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeDDoSProtection)
+	ev := &evidence{}
+	for _, vnetID := range rule.VirtualNetworkIDs {
+		vl := l.WithValues("virtualNetworkID", vnetID)
+		vl.V(1).Info("Validating virtual network's DDoS protection")
+		if err := s.validateVirtualNetwork(rule, string(vnetID), &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(vl, "failed to validate virtual network's DDoS protection", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.DDoSProtectionFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateVirtualNetwork appends a failure if a virtual network doesn't have DDoS protection
+// enabled, or isn't associated with the rule's DDoS protection plan. Virtual networks that don't
+// exist are failures, not errors.
+func (s *DDoSProtectionRuleService) validateVirtualNetwork(rule v1alpha1.DDoSProtectionRule, vnetID string, failures *[]string, ev *evidence) error {
+	vnet, err := s.api.GetVirtualNetwork(vnetID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.VirtualNetworkNotFoundFailure, vnetID))
+			return nil
+		}
+		return fmt.Errorf("failed to get virtual network: %w", azure_errors.AsAugmented(err))
+	}
+
+	enabled := false
+	planID := ""
+	if props := vnet.Properties; props != nil {
+		enabled = props.EnableDdosProtection != nil && *props.EnableDdosProtection
+		if props.DdosProtectionPlan != nil && props.DdosProtectionPlan.ID != nil {
+			planID = *props.DdosProtectionPlan.ID
+		}
+	}
+	association := messages.DDoSPlanNotAssociated
+	if planID != "" {
+		association = fmt.Sprintf(messages.DDoSPlanAssociation, planID)
+	}
+
+	switch {
+	case !enabled:
+		*failures = append(*failures, fmt.Sprintf(messages.DDoSProtectionDisabledFailure, vnetID, association))
+	case planID == "":
+		*failures = append(*failures, fmt.Sprintf(messages.DDoSProtectionNoPlanFailure, vnetID, association))
+	case rule.DDoSProtectionPlanID != "" && !strings.EqualFold(planID, rule.DDoSProtectionPlanID):
+		*failures = append(*failures, fmt.Sprintf(messages.DDoSProtectionPlanFailure, vnetID, planID, rule.DDoSProtectionPlanID))
+	default:
+		ev.add("Virtual network %s has DDoS protection enabled with DDoS protection plan %s.", vnetID, planID)
+	}
+	return nil
+}
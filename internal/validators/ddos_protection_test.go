@@ -0,0 +1,146 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testVNet2ID         = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet2"
+	testDDoSPlanID      = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/ddosProtectionPlans/plan"
+	testOtherDDoSPlanID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/ddosProtectionPlans/other"
+)
+
+// virtualNetworkAPIMock is a fake ARM with the virtual networks in its map, keyed by resource ID.
+// Getting any other virtual network fails with a 404, unless err is set.
+type virtualNetworkAPIMock struct {
+	vnets map[string]*armnetwork.VirtualNetwork
+	err   error
+}
+
+func (m virtualNetworkAPIMock) GetVirtualNetwork(virtualNetworkID string) (*armnetwork.VirtualNetwork, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	vnet, ok := m.vnets[virtualNetworkID]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return vnet, nil
+}
+
+// newDDoSVirtualNetwork returns a virtual network with DDoS protection enabled or disabled, and
+// associated with the DDoS protection plan if planID isn't empty.
+func newDDoSVirtualNetwork(enabled bool, planID string) *armnetwork.VirtualNetwork {
+	vnet := &armnetwork.VirtualNetwork{Properties: &armnetwork.VirtualNetworkPropertiesFormat{EnableDdosProtection: util.Ptr(enabled)}}
+	if planID != "" {
+		vnet.Properties.DdosProtectionPlan = &armnetwork.SubResource{ID: util.Ptr(planID)}
+	}
+	return vnet
+}
+
+func TestDDoSProtectionRuleService_ReconcileDDoSProtectionRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		vnets        map[string]*armnetwork.VirtualNetwork
+		rule         v1alpha1.DDoSProtectionRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when all virtual networks have DDoS protection enabled, with any plan.",
+			vnets: map[string]*armnetwork.VirtualNetwork{
+				testVNetID:  newDDoSVirtualNetwork(true, testDDoSPlanID),
+				testVNet2ID: newDDoSVirtualNetwork(true, testOtherDDoSPlanID),
+			},
+			rule:         v1alpha1.DDoSProtectionRule{VirtualNetworkIDs: []v1alpha1.VirtualNetworkID{testVNetID, testVNet2ID}},
+			wantFailures: []string{},
+		},
+		{
+			name:  "Passes when the plan matches the rule's, ignoring case.",
+			vnets: map[string]*armnetwork.VirtualNetwork{testVNetID: newDDoSVirtualNetwork(true, strings.ToLower(testDDoSPlanID))},
+			rule: v1alpha1.DDoSProtectionRule{
+				VirtualNetworkIDs:    []v1alpha1.VirtualNetworkID{testVNetID},
+				DDoSProtectionPlanID: strings.Replace(testDDoSPlanID, "resourceGroups", "resourcegroups", 1),
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:  "Fails when DDoS protection is enabled with another plan.",
+			vnets: map[string]*armnetwork.VirtualNetwork{testVNetID: newDDoSVirtualNetwork(true, testOtherDDoSPlanID)},
+			rule: v1alpha1.DDoSProtectionRule{
+				VirtualNetworkIDs:    []v1alpha1.VirtualNetworkID{testVNetID},
+				DDoSProtectionPlanID: testDDoSPlanID,
+			},
+			wantFailures: []string{"Virtual network " + testVNetID + " is associated with DDoS protection plan " + testOtherDDoSPlanID + ", not " + testDDoSPlanID + "."},
+		},
+		{
+			name: "Fails when DDoS protection is disabled, reporting the plan it's associated with, if any.",
+			vnets: map[string]*armnetwork.VirtualNetwork{
+				testVNetID:  newDDoSVirtualNetwork(false, testDDoSPlanID),
+				testVNet2ID: {},
+			},
+			rule: v1alpha1.DDoSProtectionRule{VirtualNetworkIDs: []v1alpha1.VirtualNetworkID{testVNetID, testVNet2ID}},
+			wantFailures: []string{
+				"Virtual network " + testVNetID + " doesn't have DDoS protection enabled; it's associated with DDoS protection plan " + testDDoSPlanID + ".",
+				"Virtual network " + testVNet2ID + " doesn't have DDoS protection enabled; it isn't associated with a DDoS protection plan.",
+			},
+		},
+		{
+			name:         "Fails when DDoS protection is enabled without a plan.",
+			vnets:        map[string]*armnetwork.VirtualNetwork{testVNetID: newDDoSVirtualNetwork(true, "")},
+			rule:         v1alpha1.DDoSProtectionRule{VirtualNetworkIDs: []v1alpha1.VirtualNetworkID{testVNetID}},
+			wantFailures: []string{"Virtual network " + testVNetID + " has DDoS protection enabled, but it isn't associated with a DDoS protection plan."},
+		},
+		{
+			name:         "Fails for missing virtual networks.",
+			rule:         v1alpha1.DDoSProtectionRule{VirtualNetworkIDs: []v1alpha1.VirtualNetworkID{testVNetID}},
+			wantFailures: []string{"Virtual network " + testVNetID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewDDoSProtectionRuleService(logr.Discard(), virtualNetworkAPIMock{vnets: tt.vnets})
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileDDoSProtectionRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestDDoSProtectionRuleService_ReconcileDDoSProtectionRule_Error(t *testing.T) {
+	api := virtualNetworkAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewDDoSProtectionRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileDDoSProtectionRule(v1alpha1.DDoSProtectionRule{Name: "rule-1", VirtualNetworkIDs: []v1alpha1.VirtualNetworkID{testVNetID}})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,130 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/security/armsecurity"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// pricingAPI contains methods that allow getting the Defender for Cloud pricing configurations of
+// a subscription.
+type pricingAPI interface {
+	ListPricings() ([]*armsecurity.Pricing, error)
+}
+
+type DefenderPlanRuleService struct {
+	log logr.Logger
+	api pricingAPI
+}
+
+func NewDefenderPlanRuleService(log logr.Logger, api pricingAPI) *DefenderPlanRuleService {
+	return &DefenderPlanRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileDefenderPlanRule reconciles a Defender for Cloud plan rule from a validation config.
+func (s *DefenderPlanRuleService) ReconcileDefenderPlanRule(rule v1alpha1.DefenderPlanRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this Defender plan rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.DefenderPlanSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeDefenderPlan
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeDefenderPlan, "subscriptionID", rule.SubscriptionID)
+	l.V(1).Info("Validating Defender plans")
+	ev := &evidence{}
+	if err := s.validatePlans(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate Defender plans", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.DefenderPlanFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validatePlans appends a failure for each expected plan that the subscription doesn't have, or
+// whose pricing tier or sub-plan differs, with the plan's actual configuration. A subscription that
+// doesn't exist is a failure, not an error.
+func (s *DefenderPlanRuleService) validatePlans(rule v1alpha1.DefenderPlanRule, failures *[]string, ev *evidence) error {
+	pricings, err := s.api.ListPricings()
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.SubscriptionNotFoundFailure, rule.SubscriptionID))
+			return nil
+		}
+		return fmt.Errorf("failed to list pricings: %w", azure_errors.AsAugmented(err))
+	}
+
+	for _, want := range rule.Plans {
+		pricing := findPricing(pricings, want.Name)
+		if pricing == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.DefenderPlanNotFoundFailure, want.Name, rule.SubscriptionID))
+			continue
+		}
+		props := pricing.Properties
+		if props == nil {
+			props = &armsecurity.PricingProperties{}
+		}
+		tier, subPlan := notSet, notSet
+		if props.PricingTier != nil {
+			tier = string(*props.PricingTier)
+		}
+		if props.SubPlan != nil {
+			subPlan = *props.SubPlan
+		}
+		ev.add("Plan %s has pricing tier %s and sub-plan %s.", want.Name, tier, subPlan)
+
+		// The CRD defaults the pricing tier, but rules built in code may leave it empty.
+		wantTier := want.PricingTier
+		if wantTier == "" {
+			wantTier = string(armsecurity.PricingTierStandard)
+		}
+		if !strings.EqualFold(tier, wantTier) {
+			*failures = append(*failures, fmt.Sprintf(messages.DefenderPlanTierFailure, want.Name, wantTier, tier))
+			continue
+		}
+		if want.SubPlan != "" && !strings.EqualFold(subPlan, want.SubPlan) {
+			*failures = append(*failures, fmt.Sprintf(messages.DefenderPlanSubPlanFailure, want.Name, want.SubPlan, subPlan))
+		}
+	}
+	return nil
+}
+
+// findPricing returns the pricing configuration of a plan, or nil if there isn't one. Plan names
+// are case-insensitive.
+func findPricing(pricings []*armsecurity.Pricing, name string) *armsecurity.Pricing {
+	for _, p := range pricings {
+		if p != nil && p.Name != nil && strings.EqualFold(*p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/security/armsecurity"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testSubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// pricingAPIMock is a fake Defender for Cloud API with the pricing configurations in data. Listing
+// them fails with err, if set.
+type pricingAPIMock struct {
+	data []*armsecurity.Pricing
+	err  error
+}
+
+func (m pricingAPIMock) ListPricings() ([]*armsecurity.Pricing, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.data, nil
+}
+
+// newPricing returns the pricing configuration of a plan. It has no sub-plan if subPlan is empty.
+func newPricing(name string, tier armsecurity.PricingTier, subPlan string) *armsecurity.Pricing {
+	p := &armsecurity.Pricing{
+		ID:         util.Ptr("/subscriptions/" + testSubscriptionID + "/providers/Microsoft.Security/pricings/" + name),
+		Name:       util.Ptr(name),
+		Properties: &armsecurity.PricingProperties{PricingTier: util.Ptr(tier)},
+	}
+	if subPlan != "" {
+		p.Properties.SubPlan = util.Ptr(subPlan)
+	}
+	return p
+}
+
+func TestDefenderPlanRuleService_ReconcileDefenderPlanRule(t *testing.T) {
+	pricings := []*armsecurity.Pricing{
+		newPricing("VirtualMachines", armsecurity.PricingTierStandard, "P2"),
+		newPricing("Containers", armsecurity.PricingTierStandard, ""),
+		newPricing("KeyVaults", armsecurity.PricingTierFree, ""),
+	}
+
+	tests := []struct {
+		name         string
+		api          pricingAPIMock
+		plans        []v1alpha1.ExpectedDefenderPlan
+		wantFailures []string
+	}{
+		{
+			name: "Passes when all plans have the expected pricing tier and sub-plan.",
+			api:  pricingAPIMock{data: pricings},
+			plans: []v1alpha1.ExpectedDefenderPlan{
+				{Name: "VirtualMachines", PricingTier: "Standard", SubPlan: "P2"},
+				{Name: "containers"},
+				{Name: "KeyVaults", PricingTier: "Free"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when a plan is on the Free tier.",
+			api:          pricingAPIMock{data: pricings},
+			plans:        []v1alpha1.ExpectedDefenderPlan{{Name: "KeyVaults", PricingTier: "Standard"}},
+			wantFailures: []string{"Expected plan KeyVaults to have pricing tier Standard, but it has pricing tier Free."},
+		},
+		{
+			name:         "Fails when the subscription has no pricing configuration for a plan.",
+			api:          pricingAPIMock{data: pricings},
+			plans:        []v1alpha1.ExpectedDefenderPlan{{Name: "StorageAccounts", PricingTier: "Standard"}},
+			wantFailures: []string{"Plan StorageAccounts not found in subscription " + testSubscriptionID + "."},
+		},
+		{
+			name: "Fails when the sub-plan differs.",
+			api:  pricingAPIMock{data: pricings},
+			plans: []v1alpha1.ExpectedDefenderPlan{
+				{Name: "VirtualMachines", PricingTier: "Standard", SubPlan: "P1"},
+				{Name: "Containers", PricingTier: "Standard", SubPlan: "P1"},
+			},
+			wantFailures: []string{
+				"Expected plan VirtualMachines to have sub-plan P1, but it has sub-plan P2.",
+				"Expected plan Containers to have sub-plan P1, but it has sub-plan (not set).",
+			},
+		},
+		{
+			name:         "Fails when the subscription doesn't exist.",
+			api:          pricingAPIMock{err: &azcore.ResponseError{ErrorCode: "SubscriptionNotFound", StatusCode: http.StatusNotFound}},
+			plans:        []v1alpha1.ExpectedDefenderPlan{{Name: "VirtualMachines", PricingTier: "Standard"}},
+			wantFailures: []string{"Subscription " + testSubscriptionID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewDefenderPlanRuleService(logr.Discard(), tt.api)
+
+			result, err := svc.ReconcileDefenderPlanRule(v1alpha1.DefenderPlanRule{
+				Name:           "rule-1",
+				SubscriptionID: testSubscriptionID,
+				Plans:          tt.plans,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestDefenderPlanRuleService_ReconcileDefenderPlanRule_Error(t *testing.T) {
+	api := pricingAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewDefenderPlanRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileDefenderPlanRule(v1alpha1.DefenderPlanRule{
+		Name:           "rule-1",
+		SubscriptionID: testSubscriptionID,
+		Plans:          []v1alpha1.ExpectedDefenderPlan{{Name: "VirtualMachines", PricingTier: "Standard"}},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
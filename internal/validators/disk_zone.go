@@ -0,0 +1,226 @@
+package validators
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// Disk types that disk zone rules check, and the VM size capabilities they need.
+const (
+	diskSKUUltraSSD             = "UltraSSD_LRS"
+	diskSKUPremiumV2            = "PremiumV2_LRS"
+	capabilityUltraSSDAvailable = "UltraSSDAvailable"
+	capabilityPremiumIO         = "PremiumIO"
+)
+
+// diskSKUAPI contains methods that allow getting the VM sizes and managed disk types available in
+// a location.
+type diskSKUAPI interface {
+	resourceSKUAPI
+	ListDiskSKUs(location string) ([]*armcompute.ResourceSKU, error)
+}
+
+type DiskZoneRuleService struct {
+	log    logr.Logger
+	skuAPI diskSKUAPI
+}
+
+func NewDiskZoneRuleService(log logr.Logger, skuAPI diskSKUAPI) *DiskZoneRuleService {
+	return &DiskZoneRuleService{
+		log:    log,
+		skuAPI: skuAPI,
+	}
+}
+
+// ReconcileDiskZoneRule reconciles a disk zone rule from a validation config.
+func (s *DiskZoneRuleService) ReconcileDiskZoneRule(rule v1alpha1.DiskZoneRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this disk zone rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = fmt.Sprintf(messages.DiskZoneSucceeded, rule.VMSize, rule.DiskSKU)
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeDiskZone
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeDiskZone, "vmSize", rule.VMSize, "diskSku", rule.DiskSKU, "location", rule.Location)
+	l.V(1).Info("Validating disk type's zone support for VM size")
+	ev := &evidence{}
+	if err := s.validateZones(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate disk zone support", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.skuAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = fmt.Sprintf(messages.DiskZoneFailed, rule.VMSize, rule.DiskSKU)
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateZones appends a failure for each of a rule's zones where the VM size can't attach disks
+// of the disk type, saying why, followed by one saying which zones can and can't. VM sizes and disk
+// types that aren't available in the location are failures, not errors.
+func (s *DiskZoneRuleService) validateZones(rule v1alpha1.DiskZoneRule, failures *[]string, ev *evidence) error {
+	vmSKUs, err := s.skuAPI.ListVirtualMachineSKUs(rule.Location)
+	if err != nil {
+		return fmt.Errorf("failed to list VM sizes: %w", azure_errors.AsAugmented(err))
+	}
+	vm := findSKU(vmSKUs, rule.VMSize)
+	if vm == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.VMSizeUnavailableFailure, rule.VMSize, rule.Location))
+		return nil
+	}
+	diskSKUs, err := s.skuAPI.ListDiskSKUs(rule.Location)
+	if err != nil {
+		return fmt.Errorf("failed to list disk types: %w", azure_errors.AsAugmented(err))
+	}
+	disk := findSKU(diskSKUs, rule.DiskSKU)
+	if disk == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.DiskTypeUnavailableFailure, rule.DiskSKU, rule.Location))
+		return nil
+	}
+
+	vmZones := offeredZones(vm, rule.Location)
+	diskZones := offeredZones(disk, rule.Location)
+	ev.add("VM size %s is offered in zones %s of %s, and disk type %s in zones %s.", rule.VMSize, joinZones(vmZones), rule.Location, rule.DiskSKU, joinZones(diskZones))
+
+	// Ultra disks need support from the VM size in each zone, but premium SSD v2 disks only need
+	// premium storage support.
+	var ultraZones []string
+	premiumIO := true
+	switch rule.DiskSKU {
+	case diskSKUUltraSSD:
+		ultraZones = zonesWithCapability(vm, rule.Location, capabilityUltraSSDAvailable)
+	case diskSKUPremiumV2:
+		premiumIO, _, _ = boolCapability(skuCapabilities(vm), capabilityPremiumIO)
+	}
+
+	var satisfied, unsatisfied []string
+	for _, zone := range rule.Zones {
+		var reasons []string
+		if !slices.Contains(vmZones, zone) {
+			reasons = append(reasons, fmt.Sprintf(messages.VMSizeNotInZone, rule.VMSize))
+		}
+		if !slices.Contains(diskZones, zone) {
+			reasons = append(reasons, fmt.Sprintf(messages.DiskTypeNotInZone, rule.DiskSKU))
+		}
+		if rule.DiskSKU == diskSKUUltraSSD && slices.Contains(vmZones, zone) && !slices.Contains(ultraZones, zone) {
+			reasons = append(reasons, fmt.Sprintf(messages.VMSizeZoneCapabilityMissing, rule.VMSize, capabilityUltraSSDAvailable))
+		}
+		if !premiumIO {
+			reasons = append(reasons, fmt.Sprintf(messages.VMSizeCapabilityNotTrue, rule.VMSize, capabilityPremiumIO))
+		}
+		if len(reasons) == 0 {
+			satisfied = append(satisfied, zone)
+			continue
+		}
+		unsatisfied = append(unsatisfied, zone)
+		*failures = append(*failures, fmt.Sprintf(messages.DiskZoneFailure, rule.VMSize, rule.DiskSKU, zone, strings.Join(reasons, "; ")))
+	}
+
+	summary := fmt.Sprintf(messages.DiskZonesFailure, rule.VMSize, rule.DiskSKU, joinZones(satisfied), joinZones(unsatisfied))
+	if len(unsatisfied) > 0 {
+		*failures = append(*failures, summary)
+	} else {
+		ev.add(summary)
+	}
+	return nil
+}
+
+// findSKU returns the SKU with a name, regardless of case, or nil if there's none.
+func findSKU(skus []*armcompute.ResourceSKU, name string) *armcompute.ResourceSKU {
+	i := slices.IndexFunc(skus, func(sku *armcompute.ResourceSKU) bool {
+		return sku.Name != nil && strings.EqualFold(*sku.Name, name)
+	})
+	if i < 0 {
+		return nil
+	}
+	return skus[i]
+}
+
+// offeredZones returns the zones of a location that a SKU is offered to the subscription in, in
+// ascending order. Zones that the SKU is restricted in don't count, nor do any if it's restricted
+// in the whole location.
+func offeredZones(sku *armcompute.ResourceSKU, location string) []string {
+	var zones []string
+	for _, info := range sku.LocationInfo {
+		if info != nil && info.Location != nil && strings.EqualFold(*info.Location, location) {
+			zones = append(zones, derefAll(info.Zones)...)
+		}
+	}
+
+	restricted := map[string]bool{}
+	for _, r := range sku.Restrictions {
+		if r == nil || r.Type == nil || r.RestrictionInfo == nil {
+			continue
+		}
+		if !slices.ContainsFunc(derefAll(r.RestrictionInfo.Locations), func(l string) bool { return strings.EqualFold(l, location) }) {
+			continue
+		}
+		switch *r.Type {
+		case armcompute.ResourceSKURestrictionsTypeLocation:
+			return nil
+		case armcompute.ResourceSKURestrictionsTypeZone:
+			for _, zone := range derefAll(r.RestrictionInfo.Zones) {
+				restricted[zone] = true
+			}
+		}
+	}
+	zones = slices.DeleteFunc(zones, func(zone string) bool { return restricted[zone] })
+	slices.Sort(zones)
+	return slices.Compact(zones)
+}
+
+// zonesWithCapability returns the zones of a location where a SKU has a boolean zonal capability
+// set to true, in ascending order.
+func zonesWithCapability(sku *armcompute.ResourceSKU, location, name string) []string {
+	var zones []string
+	for _, info := range sku.LocationInfo {
+		if info == nil || info.Location == nil || !strings.EqualFold(*info.Location, location) {
+			continue
+		}
+		for _, details := range info.ZoneDetails {
+			if details == nil {
+				continue
+			}
+			capabilities := map[string]string{}
+			for _, c := range details.Capabilities {
+				if c != nil && c.Name != nil && c.Value != nil {
+					capabilities[strings.ToLower(*c.Name)] = *c.Value
+				}
+			}
+			if enabled, _, _ := boolCapability(capabilities, name); enabled {
+				zones = append(zones, derefAll(details.Name)...)
+			}
+		}
+	}
+	slices.Sort(zones)
+	return slices.Compact(zones)
+}
+
+// joinZones formats a list of zones for a condition.
+func joinZones(zones []string) string {
+	if len(zones) == 0 {
+		return "none"
+	}
+	return strings.Join(zones, ", ")
+}
@@ -0,0 +1,218 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// diskZoneVMSKUs are VM sizes as the Resource SKUs API returns them. Standard_E4s_v5 is offered in
+// all zones of eastus but only supports ultra disks in zones 1 and 3, and Standard_D2_v2 doesn't
+// support premium storage and is restricted in zone 3 for the subscription.
+const diskZoneVMSKUs = `[
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_E4s_v5",
+		"locations": ["eastus"],
+		"locationInfo": [{
+			"location": "eastus",
+			"zones": ["2", "3", "1"],
+			"zoneDetails": [{
+				"name": ["3", "1"],
+				"capabilities": [{"name": "UltraSSDAvailable", "value": "True"}]
+			}]
+		}],
+		"capabilities": [
+			{"name": "vCPUs", "value": "4"},
+			{"name": "PremiumIO", "value": "True"}
+		],
+		"restrictions": []
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D2_v2",
+		"locations": ["eastus"],
+		"locationInfo": [{"location": "eastus", "zones": ["1", "2", "3"], "zoneDetails": []}],
+		"capabilities": [
+			{"name": "vCPUs", "value": "2"},
+			{"name": "PremiumIO", "value": "False"}
+		],
+		"restrictions": [{
+			"type": "Zone",
+			"values": ["eastus"],
+			"restrictionInfo": {"locations": ["eastus"], "zones": ["3"]},
+			"reasonCode": "NotAvailableForSubscription"
+		}]
+	}
+]`
+
+// diskZoneDiskSKUs are disk types as the Resource SKUs API returns them. UltraSSD_LRS is only
+// offered in zones 1 and 2 of eastus.
+const diskZoneDiskSKUs = `[
+	{
+		"resourceType": "disks",
+		"name": "UltraSSD_LRS",
+		"tier": "Ultra",
+		"locations": ["eastus"],
+		"locationInfo": [{"location": "eastus", "zones": ["1", "2"]}],
+		"restrictions": []
+	},
+	{
+		"resourceType": "disks",
+		"name": "PremiumV2_LRS",
+		"tier": "Premium",
+		"locations": ["eastus"],
+		"locationInfo": [{"location": "eastus", "zones": ["1", "2", "3"]}],
+		"restrictions": []
+	}
+]`
+
+func Test_offeredZones(t *testing.T) {
+	skus := parseSKUs(t, diskZoneVMSKUs)
+
+	tests := []struct {
+		name     string
+		sku      int
+		location string
+		want     []string
+	}{
+		{name: "Sorts the zones of the location.", sku: 0, location: "eastus", want: []string{"1", "2", "3"}},
+		{name: "Matches locations regardless of case.", sku: 0, location: "EastUS", want: []string{"1", "2", "3"}},
+		{name: "Leaves out restricted zones.", sku: 1, location: "eastus", want: []string{"1", "2"}},
+		{name: "Has no zones in other locations.", sku: 0, location: "westus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := offeredZones(skus[tt.sku], tt.location); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got zones %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Has no zones if restricted in the whole location.", func(t *testing.T) {
+		skus := parseSKUs(t, `[{
+			"name": "Standard_D2_v2",
+			"locationInfo": [{"location": "eastus", "zones": ["1", "2", "3"]}],
+			"restrictions": [{"type": "Location", "values": ["eastus"], "restrictionInfo": {"locations": ["eastus"]}}]
+		}]`)
+		if got := offeredZones(skus[0], "eastus"); len(got) != 0 {
+			t.Errorf("got zones %v, want none", got)
+		}
+	})
+}
+
+func Test_zonesWithCapability(t *testing.T) {
+	skus := parseSKUs(t, diskZoneVMSKUs)
+
+	if got, want := zonesWithCapability(skus[0], "eastus", capabilityUltraSSDAvailable), []string{"1", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got zones %v, want %v", got, want)
+	}
+	if got := zonesWithCapability(skus[1], "eastus", capabilityUltraSSDAvailable); len(got) != 0 {
+		t.Errorf("got zones %v, want none", got)
+	}
+}
+
+func TestDiskZoneRuleService_ReconcileDiskZoneRule(t *testing.T) {
+	vmSKUs := parseSKUs(t, diskZoneVMSKUs)
+	diskSKUs := parseSKUs(t, diskZoneDiskSKUs)
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.DiskZoneRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the VM size supports ultra disks in all zones that offer them.",
+			rule:         v1alpha1.DiskZoneRule{VMSize: "standard_e4s_v5", DiskSKU: "UltraSSD_LRS", Zones: []string{"1"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for zones that lack the disk type or the VM size's ultra disk support.",
+			rule: v1alpha1.DiskZoneRule{VMSize: "Standard_E4s_v5", DiskSKU: "UltraSSD_LRS", Zones: []string{"1", "2", "3"}},
+			wantFailures: []string{
+				"VM size Standard_E4s_v5 can't attach UltraSSD_LRS disks in zone 2: VM size Standard_E4s_v5 lacks capability UltraSSDAvailable=True there.",
+				"VM size Standard_E4s_v5 can't attach UltraSSD_LRS disks in zone 3: disk type UltraSSD_LRS isn't offered there.",
+				"Zones where VM size Standard_E4s_v5 can attach UltraSSD_LRS disks: 1. Zones where it can't: 2, 3.",
+			},
+		},
+		{
+			name:         "Passes for premium SSD v2 disks and a VM size with premium storage.",
+			rule:         v1alpha1.DiskZoneRule{VMSize: "Standard_E4s_v5", DiskSKU: "PremiumV2_LRS", Zones: []string{"1", "2", "3"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for premium SSD v2 disks and a VM size without premium storage, restricted in a zone.",
+			rule: v1alpha1.DiskZoneRule{VMSize: "Standard_D2_v2", DiskSKU: "PremiumV2_LRS", Zones: []string{"1", "3"}},
+			wantFailures: []string{
+				"VM size Standard_D2_v2 can't attach PremiumV2_LRS disks in zone 1: VM size Standard_D2_v2 lacks capability PremiumIO=True.",
+				"VM size Standard_D2_v2 can't attach PremiumV2_LRS disks in zone 3: VM size Standard_D2_v2 isn't offered there; VM size Standard_D2_v2 lacks capability PremiumIO=True.",
+				"Zones where VM size Standard_D2_v2 can attach PremiumV2_LRS disks: none. Zones where it can't: 1, 3.",
+			},
+		},
+		{
+			name:         "Fails for a VM size that isn't available in the location.",
+			rule:         v1alpha1.DiskZoneRule{VMSize: "Standard_L8s_v3", DiskSKU: "UltraSSD_LRS", Zones: []string{"1"}},
+			wantFailures: []string{"VM size Standard_L8s_v3 is not available in location eastus."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewDiskZoneRuleService(logr.Discard(), resourceSKUAPIMock{data: vmSKUs, disks: diskSKUs})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.Location = "eastus"
+			result, err := svc.ReconcileDiskZoneRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+
+	t.Run("Fails for a disk type that isn't available in the location.", func(t *testing.T) {
+		svc := NewDiskZoneRuleService(logr.Discard(), resourceSKUAPIMock{data: vmSKUs, disks: diskSKUs[:1]})
+		result, err := svc.ReconcileDiskZoneRule(v1alpha1.DiskZoneRule{Name: "rule-1", Location: "eastus", VMSize: "Standard_E4s_v5", DiskSKU: "PremiumV2_LRS", Zones: []string{"1"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"Disk type PremiumV2_LRS is not available in location eastus."}; !reflect.DeepEqual(result.Condition.Failures, want) {
+			t.Errorf("got failures %v, want %v", result.Condition.Failures, want)
+		}
+	})
+}
+
+func TestDiskZoneRuleService_ReconcileDiskZoneRule_Error(t *testing.T) {
+	skuAPI := resourceSKUAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewDiskZoneRuleService(logr.Discard(), skuAPI)
+
+	result, err := svc.ReconcileDiskZoneRule(v1alpha1.DiskZoneRule{
+		Name:     "rule-1",
+		Location: "eastus",
+		VMSize:   "Standard_E4s_v5",
+		DiskSKU:  "UltraSSD_LRS",
+		Zones:    []string{"1"},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,150 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+const (
+	// capabilityEncryptionAtHostSupported is the name of the VM size capability that says whether
+	// VMs of the size can be created with encryption at host.
+	capabilityEncryptionAtHostSupported = "EncryptionAtHostSupported"
+
+	// The feature that must be registered in a subscription before VMs in it can be created with
+	// encryption at host.
+	encryptionAtHostFeatureNamespace = "Microsoft.Compute"
+	encryptionAtHostFeatureName      = "EncryptionAtHost"
+
+	// featureStateRegistered is the registration state of a feature that's enabled in a
+	// subscription. Other states include Pending, Registering, and NotRegistered.
+	featureStateRegistered = "Registered"
+)
+
+// featureAPI contains methods that allow getting a preview feature of a resource provider, with
+// its registration state in a subscription.
+type featureAPI interface {
+	GetFeature(namespace, name string) (*armfeatures.FeatureResult, error)
+}
+
+type EncryptionAtHostRuleService struct {
+	log        logr.Logger
+	featureAPI featureAPI
+	skuAPI     resourceSKUAPI
+}
+
+func NewEncryptionAtHostRuleService(log logr.Logger, featureAPI featureAPI, skuAPI resourceSKUAPI) *EncryptionAtHostRuleService {
+	return &EncryptionAtHostRuleService{
+		log:        log,
+		featureAPI: featureAPI,
+		skuAPI:     skuAPI,
+	}
+}
+
+// ReconcileEncryptionAtHostRule reconciles an encryption at host rule from a validation config.
+func (s *EncryptionAtHostRuleService) ReconcileEncryptionAtHostRule(rule v1alpha1.EncryptionAtHostRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this encryption at host rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.EncryptionAtHostSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeEncryptionAtHost
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeEncryptionAtHost, "subscriptionID", rule.SubscriptionID, "location", rule.Location)
+	l.V(1).Info("Validating encryption at host")
+	ev := &evidence{}
+	if err := s.validateEncryptionAtHost(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate encryption at host", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.featureAPI, s.skuAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.EncryptionAtHostFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateEncryptionAtHost appends a failure if the rule's feature isn't registered in the
+// subscription, and one for each of the rule's VM sizes that isn't available in the location or
+// doesn't support encryption at host there. A feature that doesn't exist is a failure, not an
+// error.
+func (s *EncryptionAtHostRuleService) validateEncryptionAtHost(rule v1alpha1.EncryptionAtHostRule, failures *[]string, ev *evidence) error {
+	// The CRD defaults the feature, but rules built in code may leave it unset.
+	namespace, name := rule.FeatureNamespace, rule.FeatureName
+	if namespace == "" {
+		namespace = encryptionAtHostFeatureNamespace
+	}
+	if name == "" {
+		name = encryptionAtHostFeatureName
+	}
+
+	feature, err := s.featureAPI.GetFeature(namespace, name)
+	var rerr *azcore.ResponseError
+	switch {
+	case errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound:
+		*failures = append(*failures, fmt.Sprintf(messages.FeatureNotFoundFailure, namespace, name))
+	case err != nil:
+		return fmt.Errorf("failed to get feature: %w", azure_errors.AsAugmented(err))
+	default:
+		featureState := notSet
+		if feature.Properties != nil && feature.Properties.State != nil {
+			featureState = *feature.Properties.State
+		}
+		if strings.EqualFold(featureState, featureStateRegistered) {
+			ev.add("Feature %s/%s is registered in subscription %s.", namespace, name, rule.SubscriptionID)
+		} else {
+			*failures = append(*failures, fmt.Sprintf(messages.FeatureNotRegisteredFailure, namespace, name, rule.SubscriptionID, featureState))
+		}
+	}
+
+	skus, err := s.skuAPI.ListVirtualMachineSKUs(rule.Location)
+	if err != nil {
+		return fmt.Errorf("failed to list VM sizes: %w", azure_errors.AsAugmented(err))
+	}
+	for _, size := range rule.VMSizes {
+		i := slices.IndexFunc(skus, func(sku *armcompute.ResourceSKU) bool {
+			return sku.Name != nil && strings.EqualFold(*sku.Name, size)
+		})
+		if i < 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.VMSizeUnavailableFailure, size, rule.Location))
+			continue
+		}
+		capabilities := skuCapabilities(skus[i])
+		supported, ok, err := boolCapability(capabilities, capabilityEncryptionAtHostSupported)
+		switch {
+		case err != nil:
+			*failures = append(*failures, fmt.Sprintf(messages.VMSizeCheckFailure, size, err))
+		case !ok:
+			*failures = append(*failures, fmt.Sprintf(messages.EncryptionAtHostMissingFailure, size, capabilityEncryptionAtHostSupported))
+		case !supported:
+			*failures = append(*failures, fmt.Sprintf(messages.EncryptionAtHostUnsupportedFailure, size, capabilityEncryptionAtHostSupported, capabilities[strings.ToLower(capabilityEncryptionAtHostSupported)]))
+		default:
+			ev.add("VM size %s supports encryption at host in location %s.", size, rule.Location)
+		}
+	}
+	return nil
+}
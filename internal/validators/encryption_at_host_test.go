@@ -0,0 +1,168 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// encryptionAtHostSKUs are VM sizes as the Resource SKUs API returns them, trimmed to the
+// capability that matters. Standard_A1_v2 doesn't support encryption at host, Standard_B1s lacks
+// the capability, and Standard_F2 has a malformed one.
+const encryptionAtHostSKUs = `[
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_A1_v2",
+		"locations": ["eastus"],
+		"capabilities": [{"name": "EncryptionAtHostSupported", "value": "False"}]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D4s_v5",
+		"locations": ["eastus"],
+		"capabilities": [{"name": "EncryptionAtHostSupported", "value": "True"}]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_B1s",
+		"locations": ["eastus"],
+		"capabilities": [{"name": "vCPUs", "value": "1"}]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_F2",
+		"locations": ["eastus"],
+		"capabilities": [{"name": "EncryptionAtHostSupported", "value": "Maybe"}]
+	}
+]`
+
+// featureAPIMock is a fake ARM with the registration states of features in its map, keyed by
+// namespace/name. Getting any other feature fails with a 404, unless err is set.
+type featureAPIMock struct {
+	states map[string]string
+	err    error
+}
+
+func (m featureAPIMock) GetFeature(namespace, name string) (*armfeatures.FeatureResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	state, ok := m.states[namespace+"/"+name]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "FeatureNotFound", StatusCode: http.StatusNotFound}
+	}
+	return &armfeatures.FeatureResult{
+		Name:       util.Ptr(namespace + "/" + name),
+		Properties: &armfeatures.FeatureProperties{State: util.Ptr(state)},
+	}, nil
+}
+
+func TestEncryptionAtHostRuleService_ReconcileEncryptionAtHostRule(t *testing.T) {
+	skus := parseSKUs(t, encryptionAtHostSKUs)
+
+	tests := []struct {
+		name         string
+		states       map[string]string
+		rule         v1alpha1.EncryptionAtHostRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the feature is registered and the sizes support encryption at host.",
+			states:       map[string]string{"Microsoft.Compute/EncryptionAtHost": "Registered"},
+			rule:         v1alpha1.EncryptionAtHostRule{VMSizes: []string{"Standard_D4s_v5", "standard_d4s_v5"}},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails when the feature is pending.",
+			states: map[string]string{"Microsoft.Compute/EncryptionAtHost": "Pending"},
+			rule:   v1alpha1.EncryptionAtHostRule{VMSizes: []string{"Standard_D4s_v5"}},
+			wantFailures: []string{
+				"Feature Microsoft.Compute/EncryptionAtHost is not registered in subscription sub: its state is Pending.",
+			},
+		},
+		{
+			name:   "Fails when the feature isn't registered, and still validates the sizes.",
+			states: map[string]string{"Microsoft.Compute/EncryptionAtHost": "NotRegistered"},
+			rule:   v1alpha1.EncryptionAtHostRule{VMSizes: []string{"Standard_A1_v2"}},
+			wantFailures: []string{
+				"Feature Microsoft.Compute/EncryptionAtHost is not registered in subscription sub: its state is NotRegistered.",
+				"VM size Standard_A1_v2 doesn't support encryption at host: its capability EncryptionAtHostSupported is False.",
+			},
+		},
+		{
+			name: "Fails when the feature doesn't exist.",
+			rule: v1alpha1.EncryptionAtHostRule{VMSizes: []string{"Standard_D4s_v5"}},
+			wantFailures: []string{
+				"Feature Microsoft.Compute/EncryptionAtHost not found.",
+			},
+		},
+		{
+			name:   "Checks the rule's feature instead of the default one.",
+			states: map[string]string{"Microsoft.Compute/EncryptionAtHost": "Registered", "Microsoft.Compute/Other": "Registering"},
+			rule: v1alpha1.EncryptionAtHostRule{
+				VMSizes:          []string{"Standard_D4s_v5"},
+				FeatureNamespace: "Microsoft.Compute",
+				FeatureName:      "Other",
+			},
+			wantFailures: []string{
+				"Feature Microsoft.Compute/Other is not registered in subscription sub: its state is Registering.",
+			},
+		},
+		{
+			name:   "Fails for absent and malformed capabilities, and sizes that aren't available.",
+			states: map[string]string{"Microsoft.Compute/EncryptionAtHost": "Registered"},
+			rule:   v1alpha1.EncryptionAtHostRule{VMSizes: []string{"Standard_B1s", "Standard_F2", "Standard_E2s_v5"}},
+			wantFailures: []string{
+				"VM size Standard_B1s doesn't support encryption at host: it lacks capability EncryptionAtHostSupported.",
+				`Failed to check VM size Standard_F2: capability EncryptionAtHostSupported has value "Maybe", which isn't a boolean.`,
+				"VM size Standard_E2s_v5 is not available in location eastus.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewEncryptionAtHostRuleService(logr.Discard(), featureAPIMock{states: tt.states}, resourceSKUAPIMock{data: skus})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.SubscriptionID = "sub"
+			tt.rule.Location = "eastus"
+			result, err := svc.ReconcileEncryptionAtHostRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestEncryptionAtHostRuleService_ReconcileEncryptionAtHostRule_Error(t *testing.T) {
+	api := featureAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewEncryptionAtHostRuleService(logr.Discard(), api, resourceSKUAPIMock{})
+
+	result, err := svc.ReconcileEncryptionAtHostRule(v1alpha1.EncryptionAtHostRule{Name: "rule-1", SubscriptionID: "sub", Location: "eastus"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,182 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// eventHubAPI contains methods that allow getting Event Hubs namespaces, event hubs, and their
+// authorization rules by their resource IDs.
+type eventHubAPI interface {
+	GetNamespace(namespaceID string) (*armeventhub.EHNamespace, error)
+	GetEventHub(eventHubID string) (*armeventhub.Eventhub, error)
+	GetAuthorizationRule(authorizationRuleID string) (*armeventhub.AuthorizationRule, error)
+}
+
+type EventHubRuleService struct {
+	log logr.Logger
+	api eventHubAPI
+}
+
+func NewEventHubRuleService(log logr.Logger, api eventHubAPI) *EventHubRuleService {
+	return &EventHubRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileEventHubRule reconciles an event hub rule from a validation config.
+func (s *EventHubRuleService) ReconcileEventHubRule(rule v1alpha1.EventHubRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this event hub rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.EventHubSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeEventHub
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeEventHub, "namespaceID", rule.NamespaceID, "eventHubName", rule.EventHubName)
+	l.V(1).Info("Validating event hub")
+	ev := &evidence{}
+	if err := s.validateEventHub(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate event hub", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.EventHubFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateEventHub validates the namespace, then its event hub, then the authorization rule,
+// stopping at the first of them that doesn't exist, which is a failure, not an error.
+func (s *EventHubRuleService) validateEventHub(rule v1alpha1.EventHubRule, failures *[]string, ev *evidence) error {
+	var rerr *azcore.ResponseError
+	namespace, err := s.api.GetNamespace(rule.NamespaceID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.EventHubsNamespaceNotFoundFailure, rule.NamespaceID))
+			return nil
+		}
+		return fmt.Errorf("failed to get Event Hubs namespace: %w", azure_errors.AsAugmented(err))
+	}
+	nsProps := namespace.Properties
+	if nsProps == nil {
+		nsProps = &armeventhub.EHNamespaceProperties{}
+	}
+	provisioningState := strPtrValue(nsProps.ProvisioningState)
+	ev.add("Event Hubs namespace %s has provisioning state %s.", rule.NamespaceID, provisioningState)
+	if !strings.EqualFold(provisioningState, "Succeeded") {
+		*failures = append(*failures, fmt.Sprintf(messages.EventHubsNamespaceProvisioningFailure, rule.NamespaceID, provisioningState))
+	}
+
+	eventHubID := fmt.Sprintf("%s/eventhubs/%s", rule.NamespaceID, rule.EventHubName)
+	hub, err := s.api.GetEventHub(eventHubID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.EventHubNotFoundFailure, rule.EventHubName, rule.NamespaceID))
+			return nil
+		}
+		return fmt.Errorf("failed to get event hub: %w", azure_errors.AsAugmented(err))
+	}
+	hubProps := hub.Properties
+	if hubProps == nil {
+		hubProps = &armeventhub.Properties{}
+	}
+	status := notSet
+	if hubProps.Status != nil {
+		status = string(*hubProps.Status)
+	}
+	partitions := int64(0)
+	if hubProps.PartitionCount != nil {
+		partitions = *hubProps.PartitionCount
+	}
+	ev.add("Event hub %s has status %s and %d partition(s).", eventHubID, status, partitions)
+	if !strings.EqualFold(status, string(armeventhub.EntityStatusActive)) {
+		*failures = append(*failures, fmt.Sprintf(messages.EventHubInactiveFailure, eventHubID, status))
+	}
+	if partitions < rule.MinPartitionCount {
+		*failures = append(*failures, fmt.Sprintf(messages.EventHubPartitionsFailure, eventHubID, partitions, rule.MinPartitionCount))
+	}
+
+	if rule.AuthorizationRule == nil {
+		return nil
+	}
+	return s.validateAuthorizationRule(rule, eventHubID, nsProps, failures, ev)
+}
+
+// validateAuthorizationRule appends a failure if neither the event hub nor its namespace has the
+// rule's authorization rule, if the authorization rule lacks any of the rule's rights, or if the
+// namespace doesn't allow shared access signatures at all.
+func (s *EventHubRuleService) validateAuthorizationRule(rule v1alpha1.EventHubRule, eventHubID string, nsProps *armeventhub.EHNamespaceProperties, failures *[]string, ev *evidence) error {
+	var rerr *azcore.ResponseError
+	want := rule.AuthorizationRule
+	if nsProps.DisableLocalAuth != nil && *nsProps.DisableLocalAuth {
+		*failures = append(*failures, fmt.Sprintf(messages.EventHubsLocalAuthFailure, rule.NamespaceID, want.Name))
+	}
+
+	// Authorization rules of the namespace apply to all of its event hubs, so the namespace's is
+	// only looked up if the event hub doesn't have one.
+	var authRule *armeventhub.AuthorizationRule
+	var ruleID string
+	for _, parentID := range []string{eventHubID, rule.NamespaceID} {
+		ruleID = fmt.Sprintf("%s/authorizationRules/%s", parentID, want.Name)
+		r, err := s.api.GetAuthorizationRule(ruleID)
+		if err != nil {
+			if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return fmt.Errorf("failed to get authorization rule: %w", azure_errors.AsAugmented(err))
+		}
+		authRule = r
+		break
+	}
+	if authRule == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.AuthorizationRuleMissingFailure, eventHubID, want.Name))
+		return nil
+	}
+
+	var rights []string
+	if authRule.Properties != nil {
+		for _, r := range authRule.Properties.Rights {
+			if r != nil {
+				rights = append(rights, string(*r))
+			}
+		}
+	}
+	ev.add("Authorization rule %s grants rights %s.", ruleID, strings.Join(rights, ", "))
+	var missing []string
+	for _, r := range want.Rights {
+		if !slices.ContainsFunc(rights, func(have string) bool { return strings.EqualFold(have, string(r)) }) {
+			missing = append(missing, string(r))
+		}
+	}
+	if len(missing) > 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.AuthorizationRuleRightsFailure, ruleID, strings.Join(missing, ", ")))
+	}
+	return nil
+}
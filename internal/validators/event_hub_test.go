@@ -0,0 +1,213 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testEventHubNamespaceID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.EventHub/namespaces/ns"
+	testEventHubID          = testEventHubNamespaceID + "/eventhubs/audit-logs"
+)
+
+// eventHubAPIMock is a fake ARM with the namespaces, event hubs, and authorization rules in its
+// maps, keyed by resource ID. Getting any other resource fails with a 404, unless err is set.
+type eventHubAPIMock struct {
+	namespaces map[string]*armeventhub.EHNamespace
+	eventHubs  map[string]*armeventhub.Eventhub
+	authRules  map[string]*armeventhub.AuthorizationRule
+	err        error
+}
+
+func (m eventHubAPIMock) GetNamespace(namespaceID string) (*armeventhub.EHNamespace, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.namespaces, namespaceID)
+}
+
+func (m eventHubAPIMock) GetEventHub(eventHubID string) (*armeventhub.Eventhub, error) {
+	return getOrNotFound(m.eventHubs, eventHubID)
+}
+
+func (m eventHubAPIMock) GetAuthorizationRule(authorizationRuleID string) (*armeventhub.AuthorizationRule, error) {
+	return getOrNotFound(m.authRules, authorizationRuleID)
+}
+
+func getOrNotFound[T any](m map[string]*T, id string) (*T, error) {
+	v, ok := m[id]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "NotFound", StatusCode: http.StatusNotFound}
+	}
+	return v, nil
+}
+
+func newEventHubAuthorizationRule(rights ...armeventhub.AccessRights) *armeventhub.AuthorizationRule {
+	return &armeventhub.AuthorizationRule{Properties: &armeventhub.AuthorizationRuleProperties{Rights: to.SliceOfPtrs(rights...)}}
+}
+
+func TestEventHubRuleService_ReconcileEventHubRule(t *testing.T) {
+	sendRule := &v1alpha1.EventHubAuthorizationRule{Name: "send", Rights: []v1alpha1.EventHubAccessRight{"Send"}}
+	tests := []struct {
+		name         string
+		api          eventHubAPIMock
+		rule         v1alpha1.EventHubRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the event hub has enough partitions and an authorization rule with the rights.",
+			api: eventHubAPIMock{
+				authRules: map[string]*armeventhub.AuthorizationRule{
+					testEventHubID + "/authorizationRules/send": newEventHubAuthorizationRule(armeventhub.AccessRightsSend, armeventhub.AccessRightsListen),
+				},
+			},
+			rule:         v1alpha1.EventHubRule{MinPartitionCount: 4, AuthorizationRule: sendRule},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when the namespace has the authorization rule.",
+			api: eventHubAPIMock{
+				authRules: map[string]*armeventhub.AuthorizationRule{
+					testEventHubNamespaceID + "/authorizationRules/send": newEventHubAuthorizationRule(armeventhub.AccessRightsSend),
+				},
+			},
+			rule:         v1alpha1.EventHubRule{AuthorizationRule: sendRule},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the namespace is missing.",
+			api:          eventHubAPIMock{namespaces: map[string]*armeventhub.EHNamespace{}},
+			rule:         v1alpha1.EventHubRule{AuthorizationRule: sendRule},
+			wantFailures: []string{"Event Hubs namespace " + testEventHubNamespaceID + " not found."},
+		},
+		{
+			name: "Fails when the namespace isn't provisioned.",
+			api: eventHubAPIMock{
+				namespaces: map[string]*armeventhub.EHNamespace{
+					testEventHubNamespaceID: {Properties: &armeventhub.EHNamespaceProperties{ProvisioningState: util.Ptr("Creating")}},
+				},
+			},
+			rule:         v1alpha1.EventHubRule{},
+			wantFailures: []string{"Event Hubs namespace " + testEventHubNamespaceID + " is not provisioned: its provisioning state is Creating."},
+		},
+		{
+			name:         "Fails when the event hub is missing.",
+			api:          eventHubAPIMock{eventHubs: map[string]*armeventhub.Eventhub{}},
+			rule:         v1alpha1.EventHubRule{AuthorizationRule: sendRule},
+			wantFailures: []string{"Event hub audit-logs not found in Event Hubs namespace " + testEventHubNamespaceID + "."},
+		},
+		{
+			name: "Fails when the event hub isn't active and has too few partitions.",
+			api: eventHubAPIMock{
+				eventHubs: map[string]*armeventhub.Eventhub{
+					testEventHubID: {Properties: &armeventhub.Properties{
+						Status:         util.Ptr(armeventhub.EntityStatusSendDisabled),
+						PartitionCount: util.Ptr(int64(2)),
+					}},
+				},
+			},
+			rule: v1alpha1.EventHubRule{MinPartitionCount: 4},
+			wantFailures: []string{
+				"Event hub " + testEventHubID + " is not active: its status is SendDisabled.",
+				"Event hub " + testEventHubID + " has 2 partition(s), fewer than the required 4.",
+			},
+		},
+		{
+			name:         "Fails when neither the event hub nor the namespace has the authorization rule.",
+			api:          eventHubAPIMock{},
+			rule:         v1alpha1.EventHubRule{AuthorizationRule: sendRule},
+			wantFailures: []string{"Neither event hub " + testEventHubID + " nor its namespace has authorization rule send."},
+		},
+		{
+			name: "Fails when the authorization rule lacks rights.",
+			api: eventHubAPIMock{
+				authRules: map[string]*armeventhub.AuthorizationRule{
+					testEventHubID + "/authorizationRules/send": newEventHubAuthorizationRule(armeventhub.AccessRightsListen),
+				},
+			},
+			rule: v1alpha1.EventHubRule{AuthorizationRule: &v1alpha1.EventHubAuthorizationRule{
+				Name:   "send",
+				Rights: []v1alpha1.EventHubAccessRight{"Send", "Listen", "Manage"},
+			}},
+			wantFailures: []string{"Authorization rule " + testEventHubID + "/authorizationRules/send lacks right(s) Send, Manage."},
+		},
+		{
+			name: "Fails when the namespace has local authentication disabled.",
+			api: eventHubAPIMock{
+				namespaces: map[string]*armeventhub.EHNamespace{
+					testEventHubNamespaceID: {Properties: &armeventhub.EHNamespaceProperties{
+						ProvisioningState: util.Ptr("Succeeded"),
+						DisableLocalAuth:  util.Ptr(true),
+					}},
+				},
+				authRules: map[string]*armeventhub.AuthorizationRule{
+					testEventHubID + "/authorizationRules/send": newEventHubAuthorizationRule(armeventhub.AccessRightsSend),
+				},
+			},
+			rule:         v1alpha1.EventHubRule{AuthorizationRule: sendRule},
+			wantFailures: []string{"Event Hubs namespace " + testEventHubNamespaceID + " has local authentication disabled, so authorization rule send can't be used."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each layer that a test case doesn't set up exists and is as expected.
+			if tt.api.namespaces == nil {
+				tt.api.namespaces = map[string]*armeventhub.EHNamespace{
+					testEventHubNamespaceID: {Properties: &armeventhub.EHNamespaceProperties{ProvisioningState: util.Ptr("Succeeded")}},
+				}
+			}
+			if tt.api.eventHubs == nil {
+				tt.api.eventHubs = map[string]*armeventhub.Eventhub{
+					testEventHubID: {Properties: &armeventhub.Properties{
+						Status:         util.Ptr(armeventhub.EntityStatusActive),
+						PartitionCount: util.Ptr(int64(4)),
+					}},
+				}
+			}
+			svc := NewEventHubRuleService(logr.Discard(), tt.api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.NamespaceID = testEventHubNamespaceID
+			tt.rule.EventHubName = "audit-logs"
+			result, err := svc.ReconcileEventHubRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestEventHubRuleService_ReconcileEventHubRule_Error(t *testing.T) {
+	api := eventHubAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewEventHubRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileEventHubRule(v1alpha1.EventHubRule{Name: "rule-1", NamespaceID: testEventHubNamespaceID, EventHubName: "audit-logs"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
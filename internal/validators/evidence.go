@@ -0,0 +1,92 @@
+package validators
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	str_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/strings"
+)
+
+const (
+	// maxEvidenceRequestIDs is the maximum number of ARM request IDs listed in a condition's
+	// details.
+	maxEvidenceRequestIDs = 10
+	// maxEvidenceDetails is the maximum number of other evidence details added to a condition.
+	// Together with maxEvidenceRequestIDs, it keeps ValidationResults small for rules with many
+	// permission sets or requirements.
+	maxEvidenceDetails = 20
+)
+
+// correlationIDSource is implemented by Azure facades that record the correlation request IDs of
+// the ARM responses they received.
+type correlationIDSource interface {
+	CorrelationIDs() []string
+}
+
+// apiVersionSource is implemented by Azure facades that record the versions of the APIs that
+// served their requests.
+type apiVersionSource interface {
+	APIVersions() []string
+}
+
+// evidence collects what a rule's evaluation was based on (the ARM requests made, the Azure
+// resources examined, and which of them satisfied each requirement), so that a rule's condition
+// can show auditors what the plugin actually saw in Azure, not just whether the rule passed.
+type evidence struct {
+	requestIDs  []string
+	apiVersions []string
+	details     []string
+	omitted     int
+}
+
+// addRequestIDs records the ARM correlation request IDs of the requests made to evaluate the rule,
+// ignoring duplicates, along with the versions of the APIs that served them. Both are taken from
+// each API that records them.
+func (e *evidence) addRequestIDs(apis ...any) {
+	for _, api := range apis {
+		if src, ok := api.(correlationIDSource); ok {
+			e.requestIDs = append(e.requestIDs, src.CorrelationIDs()...)
+		}
+		if src, ok := api.(apiVersionSource); ok {
+			e.apiVersions = append(e.apiVersions, src.APIVersions()...)
+		}
+	}
+	e.requestIDs = str_utils.DeDupeStrSlice(e.requestIDs)
+	e.apiVersions = str_utils.DeDupeStrSlice(e.apiVersions)
+	sort.Strings(e.apiVersions)
+}
+
+// add records a piece of evidence. Once maxEvidenceDetails pieces have been recorded, further
+// pieces are only counted.
+func (e *evidence) add(format string, args ...any) {
+	if len(e.details) >= maxEvidenceDetails {
+		e.omitted++
+		return
+	}
+	e.details = append(e.details, fmt.Sprintf(format, args...))
+}
+
+// conditionDetails returns the evidence as condition details.
+func (e *evidence) conditionDetails() []string {
+	details := []string{}
+	if len(e.requestIDs) > 0 {
+		ids := e.requestIDs
+		if len(ids) > maxEvidenceRequestIDs {
+			ids = ids[:maxEvidenceRequestIDs]
+		}
+		detail := fmt.Sprintf("ARM request IDs: %s", strings.Join(ids, ", "))
+		if omitted := len(e.requestIDs) - len(ids); omitted > 0 {
+			detail += fmt.Sprintf(" (and %d more)", omitted)
+		}
+		details = append(details, detail)
+	}
+	if len(e.apiVersions) > 0 {
+		details = append(details, fmt.Sprintf("Azure API versions: %s", strings.Join(e.apiVersions, ", ")))
+	}
+	details = append(details, e.details...)
+	if e.omitted > 0 {
+		details = append(details, fmt.Sprintf("%d more evidence details omitted.", e.omitted))
+	}
+	return details
+}
@@ -0,0 +1,85 @@
+package validators
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type correlationIDSourceMock []string
+
+func (m correlationIDSourceMock) CorrelationIDs() []string {
+	return m
+}
+
+type apiVersionSourceMock []string
+
+func (m apiVersionSourceMock) APIVersions() []string {
+	return m
+}
+
+func Test_evidence_conditionDetails(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(e *evidence)
+		want  []string
+	}{
+		{
+			name:  "Returns no details when there is no evidence.",
+			build: func(e *evidence) {},
+			want:  []string{},
+		},
+		{
+			name: "Lists request IDs first, without duplicates, followed by the other evidence in order.",
+			build: func(e *evidence) {
+				e.add("Examined %d role assignment(s).", 1)
+				e.addRequestIDs(correlationIDSourceMock{"a", "b"}, "not a source", correlationIDSourceMock{"b", "c"})
+				e.add("Action %s permitted by role assignment %s.", "x", "ra")
+			},
+			want: []string{
+				"ARM request IDs: a, b, c",
+				"Examined 1 role assignment(s).",
+				"Action x permitted by role assignment ra.",
+			},
+		},
+		{
+			name: "Lists API versions after request IDs, sorted and without duplicates.",
+			build: func(e *evidence) {
+				e.addRequestIDs(correlationIDSourceMock{"a"}, apiVersionSourceMock{"b api-version 2", "a api-version 1"}, apiVersionSourceMock{"a api-version 1"})
+			},
+			want: []string{
+				"ARM request IDs: a",
+				"Azure API versions: a api-version 1, b api-version 2",
+			},
+		},
+		{
+			name: "Caps the number of request IDs and other details.",
+			build: func(e *evidence) {
+				ids := correlationIDSourceMock{}
+				for i := 0; i < maxEvidenceRequestIDs+2; i++ {
+					ids = append(ids, fmt.Sprint(i))
+				}
+				e.addRequestIDs(ids)
+				for i := 0; i < maxEvidenceDetails+5; i++ {
+					e.add("detail %d", i)
+				}
+			},
+			want: func() []string {
+				want := []string{"ARM request IDs: 0, 1, 2, 3, 4, 5, 6, 7, 8, 9 (and 2 more)"}
+				for i := 0; i < maxEvidenceDetails; i++ {
+					want = append(want, fmt.Sprintf("detail %d", i))
+				}
+				return append(want, "5 more evidence details omitted.")
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &evidence{}
+			tt.build(e)
+			if got := e.conditionDetails(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditionDetails() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// expressRouteCircuitAPI contains methods that allow getting an ExpressRoute circuit by its
+// resource ID.
+type expressRouteCircuitAPI interface {
+	GetExpressRouteCircuit(circuitID string) (*armnetwork.ExpressRouteCircuit, error)
+}
+
+// gatewayConnectionAPI contains methods that allow getting a virtual network gateway connection by
+// its resource ID.
+type gatewayConnectionAPI interface {
+	GetVirtualNetworkGatewayConnection(connectionID string) (*armnetwork.VirtualNetworkGatewayConnection, error)
+}
+
+type ExpressRouteRuleService struct {
+	log           logr.Logger
+	circuitAPI    expressRouteCircuitAPI
+	connectionAPI gatewayConnectionAPI
+}
+
+func NewExpressRouteRuleService(log logr.Logger, circuitAPI expressRouteCircuitAPI, connectionAPI gatewayConnectionAPI) *ExpressRouteRuleService {
+	return &ExpressRouteRuleService{
+		log:           log,
+		circuitAPI:    circuitAPI,
+		connectionAPI: connectionAPI,
+	}
+}
+
+// ReconcileExpressRouteRule reconciles an ExpressRoute rule from a validation config.
+func (s *ExpressRouteRuleService) ReconcileExpressRouteRule(rule v1alpha1.ExpressRouteRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this ExpressRoute rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ExpressRouteSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeExpressRoute
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeExpressRoute, "circuitID", rule.CircuitID)
+	l.V(1).Info("Validating ExpressRoute circuit")
+	ev := &evidence{}
+	if err := s.validateCircuit(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate ExpressRoute circuit", err, &latestCondition)
+	}
+	if rule.ConnectionID != "" {
+		if err := s.validateConnection(rule, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l, "failed to validate virtual network gateway connection", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.circuitAPI, s.connectionAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ExpressRouteFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateCircuit appends a failure if the circuit isn't Provisioned by its service provider, and
+// one if it isn't Enabled. A circuit that doesn't exist is a failure, not an error.
+func (s *ExpressRouteRuleService) validateCircuit(rule v1alpha1.ExpressRouteRule, failures *[]string, ev *evidence) error {
+	circuit, err := s.circuitAPI.GetExpressRouteCircuit(rule.CircuitID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ExpressRouteCircuitNotFoundFailure, rule.CircuitID))
+			return nil
+		}
+		return fmt.Errorf("failed to get ExpressRoute circuit: %w", azure_errors.AsAugmented(err))
+	}
+
+	providerState, circuitState := notSet, notSet
+	if props := circuit.Properties; props != nil {
+		if props.ServiceProviderProvisioningState != nil {
+			providerState = string(*props.ServiceProviderProvisioningState)
+		}
+		circuitState = strPtrValue(props.CircuitProvisioningState)
+	}
+	if providerState != string(armnetwork.ServiceProviderProvisioningStateProvisioned) {
+		*failures = append(*failures, fmt.Sprintf(messages.ExpressRouteProviderStateFailure, rule.CircuitID, providerState))
+	}
+	if circuitState != "Enabled" {
+		*failures = append(*failures, fmt.Sprintf(messages.ExpressRouteCircuitStateFailure, rule.CircuitID, circuitState))
+	}
+	ev.add("ExpressRoute circuit %s has service provider provisioning state %s and circuit provisioning state %s.", rule.CircuitID, providerState, circuitState)
+	return nil
+}
+
+// validateConnection appends a failure if the rule's connection isn't to its circuit, and one if
+// it isn't Connected. A connection that doesn't exist is a failure, not an error.
+func (s *ExpressRouteRuleService) validateConnection(rule v1alpha1.ExpressRouteRule, failures *[]string, ev *evidence) error {
+	connection, err := s.connectionAPI.GetVirtualNetworkGatewayConnection(rule.ConnectionID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.GatewayConnectionNotFoundFailure, rule.ConnectionID))
+			return nil
+		}
+		return fmt.Errorf("failed to get virtual network gateway connection: %w", azure_errors.AsAugmented(err))
+	}
+
+	peer, status := notSet, notSet
+	if props := connection.Properties; props != nil {
+		if props.Peer != nil {
+			peer = strPtrValue(props.Peer.ID)
+		}
+		if props.ConnectionStatus != nil {
+			status = string(*props.ConnectionStatus)
+		}
+	}
+	if !strings.EqualFold(peer, rule.CircuitID) {
+		*failures = append(*failures, fmt.Sprintf(messages.ExpressRouteConnectionPeerFailure, rule.ConnectionID, peer, rule.CircuitID))
+	}
+	if status != string(armnetwork.VirtualNetworkGatewayConnectionStatusConnected) {
+		*failures = append(*failures, fmt.Sprintf(messages.GatewayConnectionStatusFailure, rule.ConnectionID, status))
+	}
+	ev.add("Virtual network gateway connection %s is to %s and has connection status %s.", rule.ConnectionID, peer, status)
+	return nil
+}
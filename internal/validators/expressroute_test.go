@@ -0,0 +1,203 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testCircuitID    = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/expressRouteCircuits/circuit-1"
+	testConnectionID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/connections/connection-1"
+)
+
+// expressRouteAPIMock is a fake ARM with the ExpressRoute circuits and virtual network gateway
+// connections in its maps, keyed by resource ID. Getting any other circuit or connection fails with
+// a 404, unless err is set.
+type expressRouteAPIMock struct {
+	circuits    map[string]*armnetwork.ExpressRouteCircuit
+	connections map[string]*armnetwork.VirtualNetworkGatewayConnection
+	err         error
+}
+
+func (m expressRouteAPIMock) GetExpressRouteCircuit(circuitID string) (*armnetwork.ExpressRouteCircuit, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.circuits, circuitID)
+}
+
+func (m expressRouteAPIMock) GetVirtualNetworkGatewayConnection(connectionID string) (*armnetwork.VirtualNetworkGatewayConnection, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.connections, connectionID)
+}
+
+func newExpressRouteCircuit(providerState armnetwork.ServiceProviderProvisioningState, circuitState string) *armnetwork.ExpressRouteCircuit {
+	return &armnetwork.ExpressRouteCircuit{
+		Properties: &armnetwork.ExpressRouteCircuitPropertiesFormat{
+			ServiceProviderProvisioningState: util.Ptr(providerState),
+			CircuitProvisioningState:         util.Ptr(circuitState),
+		},
+	}
+}
+
+// newGatewayConnection returns an ExpressRoute connection to a peer with a connection status.
+func newGatewayConnection(peerID string, status armnetwork.VirtualNetworkGatewayConnectionStatus) *armnetwork.VirtualNetworkGatewayConnection {
+	return &armnetwork.VirtualNetworkGatewayConnection{
+		Properties: &armnetwork.VirtualNetworkGatewayConnectionPropertiesFormat{
+			ConnectionType:   util.Ptr(armnetwork.VirtualNetworkGatewayConnectionTypeExpressRoute),
+			Peer:             &armnetwork.SubResource{ID: util.Ptr(peerID)},
+			ConnectionStatus: util.Ptr(status),
+		},
+	}
+}
+
+func TestExpressRouteRuleService_ReconcileExpressRouteRule(t *testing.T) {
+	type testCase struct {
+		name         string
+		circuit      *armnetwork.ExpressRouteCircuit
+		connection   *armnetwork.VirtualNetworkGatewayConnection
+		connectionID string
+		wantFailures []string
+	}
+	tests := []testCase{
+		{
+			name:         "Passes when the circuit is provisioned and enabled, without a connection.",
+			circuit:      newExpressRouteCircuit(armnetwork.ServiceProviderProvisioningStateProvisioned, "Enabled"),
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the circuit's connection is connected.",
+			circuit:      newExpressRouteCircuit(armnetwork.ServiceProviderProvisioningStateProvisioned, "Enabled"),
+			connection:   newGatewayConnection(testCircuitID, armnetwork.VirtualNetworkGatewayConnectionStatusConnected),
+			connectionID: testConnectionID,
+			wantFailures: []string{},
+		},
+		{
+			name:    "Fails when the circuit's state isn't set.",
+			circuit: &armnetwork.ExpressRouteCircuit{},
+			wantFailures: []string{
+				"ExpressRoute circuit " + testCircuitID + " has service provider provisioning state (not set), not Provisioned.",
+				"ExpressRoute circuit " + testCircuitID + " has circuit provisioning state (not set), not Enabled.",
+			},
+		},
+		{
+			name:         "Fails when the connection is to another circuit.",
+			circuit:      newExpressRouteCircuit(armnetwork.ServiceProviderProvisioningStateProvisioned, "Enabled"),
+			connection:   newGatewayConnection("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/expressRouteCircuits/circuit-2", armnetwork.VirtualNetworkGatewayConnectionStatusConnected),
+			connectionID: testConnectionID,
+			wantFailures: []string{
+				"Virtual network gateway connection " + testConnectionID + " is to /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/expressRouteCircuits/circuit-2, not ExpressRoute circuit " + testCircuitID + ".",
+			},
+		},
+		{
+			name:         "Fails when the connection is missing.",
+			circuit:      newExpressRouteCircuit(armnetwork.ServiceProviderProvisioningStateProvisioned, "Enabled"),
+			connectionID: testConnectionID,
+			wantFailures: []string{"Virtual network gateway connection " + testConnectionID + " not found."},
+		},
+		{
+			name:         "Fails when the circuit is missing, and still validates the connection.",
+			connection:   newGatewayConnection(testCircuitID, armnetwork.VirtualNetworkGatewayConnectionStatusNotConnected),
+			connectionID: testConnectionID,
+			wantFailures: []string{
+				"ExpressRoute circuit " + testCircuitID + " not found.",
+				"Virtual network gateway connection " + testConnectionID + " has connection status NotConnected, not Connected.",
+			},
+		},
+	}
+	providerStates := []armnetwork.ServiceProviderProvisioningState{
+		armnetwork.ServiceProviderProvisioningStateNotProvisioned,
+		armnetwork.ServiceProviderProvisioningStateProvisioning,
+		armnetwork.ServiceProviderProvisioningStateProvisioned,
+		armnetwork.ServiceProviderProvisioningStateDeprovisioning,
+	}
+	for _, providerState := range providerStates {
+		for _, circuitState := range []string{"Enabled", "Disabled"} {
+			want := []string{}
+			if providerState != armnetwork.ServiceProviderProvisioningStateProvisioned {
+				want = append(want, fmt.Sprintf("ExpressRoute circuit %s has service provider provisioning state %s, not Provisioned.", testCircuitID, providerState))
+			}
+			if circuitState != "Enabled" {
+				want = append(want, fmt.Sprintf("ExpressRoute circuit %s has circuit provisioning state %s, not Enabled.", testCircuitID, circuitState))
+			}
+			tests = append(tests, testCase{
+				name:         fmt.Sprintf("Circuit %s and %s.", providerState, circuitState),
+				circuit:      newExpressRouteCircuit(providerState, circuitState),
+				wantFailures: want,
+			})
+		}
+	}
+	for _, status := range []armnetwork.VirtualNetworkGatewayConnectionStatus{
+		armnetwork.VirtualNetworkGatewayConnectionStatusConnecting,
+		armnetwork.VirtualNetworkGatewayConnectionStatusNotConnected,
+		armnetwork.VirtualNetworkGatewayConnectionStatusUnknown,
+	} {
+		tests = append(tests, testCase{
+			name:         fmt.Sprintf("Connection %s.", status),
+			circuit:      newExpressRouteCircuit(armnetwork.ServiceProviderProvisioningStateProvisioned, "Enabled"),
+			connection:   newGatewayConnection(testCircuitID, status),
+			connectionID: testConnectionID,
+			wantFailures: []string{
+				fmt.Sprintf("Virtual network gateway connection %s has connection status %s, not Connected.", testConnectionID, status),
+			},
+		})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := expressRouteAPIMock{
+				circuits:    map[string]*armnetwork.ExpressRouteCircuit{},
+				connections: map[string]*armnetwork.VirtualNetworkGatewayConnection{},
+			}
+			if tt.circuit != nil {
+				api.circuits[testCircuitID] = tt.circuit
+			}
+			if tt.connection != nil {
+				api.connections[testConnectionID] = tt.connection
+			}
+			svc := NewExpressRouteRuleService(logr.Discard(), api, api)
+
+			rule := v1alpha1.ExpressRouteRule{Name: "rule-1", CircuitID: testCircuitID, ConnectionID: tt.connectionID}
+			result, err := svc.ReconcileExpressRouteRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestExpressRouteRuleService_ReconcileExpressRouteRule_Error(t *testing.T) {
+	api := expressRouteAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewExpressRouteRuleService(logr.Discard(), api, api)
+
+	result, err := svc.ReconcileExpressRouteRule(v1alpha1.ExpressRouteRule{Name: "rule-1", CircuitID: testCircuitID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,104 @@
+package validators
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// FinalizeFailures post-processes the failures of a rule's condition before the condition is
+// recorded: identical failures, e.g. of requirements that a spec lists twice, are reported once,
+// where they first occurred. Failures are otherwise kept in the order the rule reported them,
+// which is the order of what the rule specifies, so that it's the same on every evaluation.
+func FinalizeFailures(condition *vapi.ValidationCondition) {
+	if len(condition.Failures) < 2 {
+		return
+	}
+	seen := make(map[string]bool, len(condition.Failures))
+	condition.Failures = slices.DeleteFunc(condition.Failures, func(failure string) bool {
+		if seen[failure] {
+			return true
+		}
+		seen[failure] = true
+		return false
+	})
+}
+
+// setFailures collects the failures of a principal's permission sets. Permission failures are kept
+// unrendered until all of the sets have been processed, so that identical ones at several scopes,
+// e.g. of sets at overlapping scopes that require the same Action, are rendered as one failure
+// that lists the scopes.
+type setFailures struct {
+	items []setFailure
+}
+
+// setFailure is either a permission failure, or another failure of a permission set, e.g. of its
+// creation window.
+type setFailure struct {
+	permission   messages.PermissionFailure
+	isPermission bool
+	text         string
+}
+
+// permissionFailureKey identifies identical permission failures, regardless of their scope.
+type permissionFailureKey struct {
+	kind, action, principalID, denyAssignment, roles string
+	selfCheck                                        bool
+}
+
+func (f *setFailures) addPermission(failure messages.PermissionFailure) {
+	f.items = append(f.items, setFailure{permission: failure, isPermission: true})
+}
+
+func (f *setFailures) add(text string) {
+	f.items = append(f.items, setFailure{text: text})
+}
+
+func (f *setFailures) len() int {
+	return len(f.items)
+}
+
+// render renders the failures with msgs, in the order they were added. Permission failures that
+// are identical but for their scope are merged into the first of them, which lists all of their
+// scopes.
+func (f *setFailures) render(msgs messages.Templates) []string {
+	merged := make([]setFailure, 0, len(f.items))
+	// values = indices into merged
+	byKey := make(map[permissionFailureKey]int, len(f.items))
+	for _, item := range f.items {
+		if !item.isPermission {
+			merged = append(merged, item)
+			continue
+		}
+		p := item.permission
+		key := permissionFailureKey{
+			kind:           p.Kind,
+			action:         p.Action,
+			principalID:    p.PrincipalID,
+			denyAssignment: p.DenyAssignment,
+			roles:          strings.Join(p.Roles, "\x00"),
+			selfCheck:      p.SelfCheck,
+		}
+		if i, ok := byKey[key]; ok {
+			if m := &merged[i].permission; !slices.ContainsFunc(m.Scopes, func(scope string) bool { return strings.EqualFold(scope, p.Scope) }) {
+				m.Scopes = append(m.Scopes, p.Scope)
+			}
+			continue
+		}
+		byKey[key] = len(merged)
+		item.permission.Scopes = []string{p.Scope}
+		merged = append(merged, item)
+	}
+
+	failures := make([]string, 0, len(merged))
+	for _, item := range merged {
+		if item.isPermission {
+			failures = append(failures, msgs.Permission(item.permission))
+		} else {
+			failures = append(failures, item.text)
+		}
+	}
+	return failures
+}
@@ -0,0 +1,124 @@
+package validators
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+func TestFinalizeFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures []string
+		want     []string
+	}{
+		{
+			name:     "Rules without failures are left alone.",
+			failures: []string{},
+			want:     []string{},
+		},
+		{
+			name:     "Identical failures are reported once, where they first occurred.",
+			failures: []string{"b", "a", "b", "c", "a"},
+			want:     []string{"b", "a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := &vapi.ValidationCondition{Failures: tt.failures}
+			FinalizeFailures(condition)
+			if !slices.Equal(condition.Failures, tt.want) {
+				t.Errorf("got failures %v, want %v", condition.Failures, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFailures_render(t *testing.T) {
+	const (
+		sub = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		rg  = sub + "/resourceGroups/rg"
+	)
+	failures := &setFailures{}
+	failures.addPermission(messages.PermissionFailure{Kind: "Action", Action: "a", Scope: sub, Roles: []string{"Reader"}})
+	failures.add("Other failure.")
+	failures.addPermission(messages.PermissionFailure{Kind: "Action", Action: "a", Scope: rg, Roles: []string{"Reader"}})
+	failures.addPermission(messages.PermissionFailure{Kind: "Action", Action: "a", Scope: rg, Roles: []string{"Reader"}})
+	// Failures that differ by more than their scope are kept apart.
+	failures.addPermission(messages.PermissionFailure{Kind: "Action", Action: "a", Scope: rg, Roles: []string{"Reader", "Contributor"}})
+	failures.addPermission(messages.PermissionFailure{Kind: "Action", Action: "a", Scope: rg, DenyAssignment: "deny-1"})
+	failures.addPermission(messages.PermissionFailure{Kind: "DataAction", Action: "a", Scope: rg})
+
+	want := []string{
+		"At scopes " + sub + ", " + rg + ": Action a unpermitted because no role assignment permits it.",
+		"Other failure.",
+		"Action a unpermitted because no role assignment permits it.",
+		"Action a denied by deny assignment deny-1.",
+		"DataAction a unpermitted because no role assignment permits it.",
+	}
+	if got := failures.render(messages.Templates{}); !slices.Equal(got, want) {
+		t.Errorf("got failures %q, want %q", got, want)
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_OverlappingScopes(t *testing.T) {
+	const (
+		sub = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		rg1 = sub + "/resourceGroups/rg-1"
+		rg2 = sub + "/resourceGroups/rg-2"
+	)
+	raAPI := roleAssignmentAPIMock{
+		data: []*armauthorization.RoleAssignment{{
+			ID:         util.Ptr("ra-1"),
+			Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("role-1")},
+		}},
+	}
+	rdAPI := roleDefinitionAPIMock{
+		data: map[string]*armauthorization.RoleDefinition{
+			"role-1": {
+				Properties: &armauthorization.RoleDefinitionProperties{
+					RoleName: util.Ptr("Reader"),
+					Permissions: []*armauthorization.Permission{{
+						Actions:        []*string{util.Ptr("a")},
+						NotActions:     []*string{},
+						DataActions:    []*string{},
+						NotDataActions: []*string{},
+					}},
+				},
+			},
+		},
+	}
+	rule := v1alpha1.RBACRule{
+		Name:         "rule-1",
+		PrincipalIDs: []string{"p_id_1", "p_id_2"},
+		Permissions: []v1alpha1.PermissionSet{
+			{Scope: sub, Actions: []v1alpha1.ActionStr{"a", "b"}},
+			{Scope: rg1, Actions: []v1alpha1.ActionStr{"b", "c"}},
+			{Scopes: []string{rg1, rg2}, Actions: []v1alpha1.ActionStr{"b"}},
+		},
+	}
+
+	result, err := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil).ReconcileRBACRule(rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"Principal p_id_1: At scopes " + sub + ", " + rg1 + ", " + rg2 + ": Action b unpermitted because no role assignment permits it.",
+		"Principal p_id_1: Action c unpermitted because no role assignment permits it.",
+		"Principal p_id_2: At scopes " + sub + ", " + rg1 + ", " + rg2 + ": Action b unpermitted because no role assignment permits it.",
+		"Principal p_id_2: Action c unpermitted because no role assignment permits it.",
+	}
+	if !slices.Equal(result.Condition.Failures, want) {
+		t.Errorf("got failures %q, want %q", result.Condition.Failures, want)
+	}
+	if *result.State != vapi.ValidationFailed {
+		t.Errorf("got state %s, want %s", *result.State, vapi.ValidationFailed)
+	}
+}
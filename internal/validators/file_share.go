@@ -0,0 +1,108 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// fileShareAPI contains methods that allow getting file shares by their resource IDs.
+type fileShareAPI interface {
+	GetFileShare(shareID string) (*armstorage.FileShare, error)
+}
+
+type FileShareRuleService struct {
+	log logr.Logger
+	api fileShareAPI
+}
+
+func NewFileShareRuleService(log logr.Logger, api fileShareAPI) *FileShareRuleService {
+	return &FileShareRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileFileShareRule reconciles a file share rule from a validation config.
+func (s *FileShareRuleService) ReconcileFileShareRule(rule v1alpha1.FileShareRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this file share rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.FileShareSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeFileShare
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeFileShare, "storageAccountID", rule.StorageAccountID)
+	l.V(1).Info("Validating file shares")
+	ev := &evidence{}
+	for _, name := range rule.ShareNames {
+		if err := s.validateFileShare(rule, name, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l, "failed to validate file share", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.FileShareFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateFileShare appends a failure if a file share of the rule's storage account doesn't exist,
+// if its quota is less than the rule's minimum, or if it isn't in the rule's access tier.
+func (s *FileShareRuleService) validateFileShare(rule v1alpha1.FileShareRule, name string, failures *[]string, ev *evidence) error {
+	shareID := fmt.Sprintf("%s/fileServices/default/shares/%s", rule.StorageAccountID, name)
+	share, err := s.api.GetFileShare(shareID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.FileShareNotFoundFailure, name, rule.StorageAccountID))
+			return nil
+		}
+		return fmt.Errorf("failed to get file share: %w", azure_errors.AsAugmented(err))
+	}
+	props := share.FileShareProperties
+	if props == nil {
+		props = &armstorage.FileShareProperties{}
+	}
+
+	quota := int32(0)
+	if props.ShareQuota != nil {
+		quota = *props.ShareQuota
+	}
+	tier := notSet
+	if props.AccessTier != nil {
+		tier = string(*props.AccessTier)
+	}
+	ev.add("File share %s has a quota of %d GiB and access tier %s.", shareID, quota, tier)
+
+	if quota < rule.MinQuotaGiB {
+		*failures = append(*failures, fmt.Sprintf(messages.FileShareQuotaFailure, shareID, quota, rule.MinQuotaGiB))
+	}
+	if rule.AccessTier != "" && !strings.EqualFold(tier, rule.AccessTier) {
+		*failures = append(*failures, fmt.Sprintf(messages.FileShareAccessTierFailure, shareID, tier, rule.AccessTier))
+	}
+	return nil
+}
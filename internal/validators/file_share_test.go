@@ -0,0 +1,112 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testFileSharePrefix = testBackupAccountID + "/fileServices/default/shares/"
+
+// fileShareAPIMock is a fake ARM with the file shares in its map, keyed by resource ID. Getting
+// any other file share fails with a 404, unless err is set.
+type fileShareAPIMock struct {
+	shares map[string]*armstorage.FileShare
+	err    error
+}
+
+func (m fileShareAPIMock) GetFileShare(shareID string) (*armstorage.FileShare, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.shares, shareID)
+}
+
+func newFileShare(quota int32, tier armstorage.ShareAccessTier) *armstorage.FileShare {
+	return &armstorage.FileShare{FileShareProperties: &armstorage.FileShareProperties{
+		ShareQuota: util.Ptr(quota),
+		AccessTier: util.Ptr(tier),
+	}}
+}
+
+func TestFileShareRuleService_ReconcileFileShareRule(t *testing.T) {
+	api := fileShareAPIMock{shares: map[string]*armstorage.FileShare{
+		testFileSharePrefix + "data":    newFileShare(100, armstorage.ShareAccessTierTransactionOptimized),
+		testFileSharePrefix + "archive": newFileShare(1024, armstorage.ShareAccessTierCool),
+	}}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.FileShareRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the file shares exist.",
+			rule:         v1alpha1.FileShareRule{ShareNames: []string{"data", "archive"}},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the quota is exactly the minimum.",
+			rule:         v1alpha1.FileShareRule{ShareNames: []string{"data"}, MinQuotaGiB: 100, AccessTier: "TransactionOptimized"},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for each file share with too little quota or another access tier.",
+			rule: v1alpha1.FileShareRule{ShareNames: []string{"data", "archive"}, MinQuotaGiB: 101, AccessTier: "Cool"},
+			wantFailures: []string{
+				"File share " + testFileSharePrefix + "data has a quota of 100 GiB, less than the required 101 GiB.",
+				"File share " + testFileSharePrefix + "data has access tier TransactionOptimized, not Cool.",
+			},
+		},
+		{
+			name:         "Fails for each file share that's missing.",
+			rule:         v1alpha1.FileShareRule{ShareNames: []string{"data", "logs"}, MinQuotaGiB: 10},
+			wantFailures: []string{"File share logs not found in storage account " + testBackupAccountID + "."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewFileShareRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.StorageAccountID = testBackupAccountID
+			result, err := svc.ReconcileFileShareRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestFileShareRuleService_ReconcileFileShareRule_Error(t *testing.T) {
+	api := fileShareAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewFileShareRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileFileShareRule(v1alpha1.FileShareRule{Name: "rule-1", StorageAccountID: testBackupAccountID, ShareNames: []string{"data"}})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,345 @@
+package validators
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+// firewallFlow is traffic that an expected firewall rule describes, to one destination, on one
+// port, with one protocol, and from one source.
+type firewallFlow struct {
+	// Whether an application rule must allow the flow, rather than a network rule.
+	application bool
+	// An FQDN, or, for network flows, an FQDN or an address.
+	destination string
+	fqdn        bool
+	port        int32
+	protocol    string
+	// Empty if the flow is from any source.
+	source string
+}
+
+// String describes the flow in failures, e.g. "Https:443 traffic to mcr.microsoft.com".
+func (f firewallFlow) String() string {
+	s := fmt.Sprintf("%s:%d traffic to %s", f.protocol, f.port, f.destination)
+	if f.source != "" {
+		s += " from " + f.source
+	}
+	return s
+}
+
+// firewallFlows returns the flows of an expected firewall rule, by destination, then port, then
+// protocol, then source.
+func firewallFlows(want v1alpha1.ExpectedFirewallRule) []firewallFlow {
+	type destination struct {
+		name string
+		fqdn bool
+	}
+	var destinations []destination
+	for _, fqdn := range want.DestinationFQDNs {
+		destinations = append(destinations, destination{fqdn, true})
+	}
+	for _, address := range want.DestinationAddresses {
+		destinations = append(destinations, destination{address, false})
+	}
+	sources := want.SourceAddresses
+	if len(sources) == 0 {
+		sources = []string{""}
+	}
+	var flows []firewallFlow
+	for _, d := range destinations {
+		for _, port := range want.Ports {
+			for _, protocol := range want.Protocols {
+				for _, source := range sources {
+					flows = append(flows, firewallFlow{
+						application: want.Type == "Application",
+						destination: d.name,
+						fqdn:        d.fqdn,
+						port:        port,
+						protocol:    protocol,
+						source:      source,
+					})
+				}
+			}
+		}
+	}
+	return flows
+}
+
+// firewallCollection is a filter rule collection of a firewall policy, with the priority of its
+// rule collection group.
+type firewallCollection struct {
+	group         string
+	groupPriority int32
+	name          string
+	priority      int32
+	deny          bool
+	application   []*armnetwork.ApplicationRule
+	network       []*armnetwork.Rule
+}
+
+// firewallCollections returns the filter rule collections of a firewall policy's rule collection
+// groups, in the order the firewall processes them. If groups isn't empty, only the rule
+// collection groups with those names count, and the names that no group has are returned too.
+func firewallCollections(ruleCollectionGroups []*armnetwork.FirewallPolicyRuleCollectionGroup, groups []string) (collections []*firewallCollection, missing []string) {
+	found := map[string]bool{}
+	for _, g := range ruleCollectionGroups {
+		if g == nil || g.Properties == nil {
+			continue
+		}
+		name := strPtrValue(g.Name)
+		if len(groups) > 0 && !slices.ContainsFunc(groups, func(n string) bool { return strings.EqualFold(n, name) }) {
+			continue
+		}
+		found[strings.ToLower(name)] = true
+		for _, rc := range g.Properties.RuleCollections {
+			fc, ok := rc.(*armnetwork.FirewallPolicyFilterRuleCollection)
+			if !ok {
+				continue
+			}
+			c := &firewallCollection{
+				group:         name,
+				groupPriority: int32PtrValue(g.Properties.Priority),
+				name:          strPtrValue(fc.Name),
+				priority:      int32PtrValue(fc.Priority),
+				deny:          fc.Action != nil && fc.Action.Type != nil && *fc.Action.Type == armnetwork.FirewallPolicyFilterRuleCollectionActionTypeDeny,
+			}
+			for _, r := range fc.Rules {
+				switch r := r.(type) {
+				case *armnetwork.ApplicationRule:
+					c.application = append(c.application, r)
+				case *armnetwork.Rule:
+					c.network = append(c.network, r)
+				}
+			}
+			collections = append(collections, c)
+		}
+	}
+	for _, name := range groups {
+		if !found[strings.ToLower(name)] {
+			missing = append(missing, name)
+		}
+	}
+	sort.SliceStable(collections, func(i, j int) bool {
+		if collections[i].groupPriority != collections[j].groupPriority {
+			return collections[i].groupPriority < collections[j].groupPriority
+		}
+		return collections[i].priority < collections[j].priority
+	})
+	return collections, missing
+}
+
+// firewallMatch is a firewall rule that matches a flow.
+type firewallMatch struct {
+	collection *firewallCollection
+	rule       string
+}
+
+// String describes the rule in failures and evidence.
+func (m firewallMatch) String() string {
+	return fmt.Sprintf("rule %s of rule collection %s in rule collection group %s", m.rule, m.collection.name, m.collection.group)
+}
+
+// evaluateFlow returns the rule that decides whether a firewall policy allows a flow: the first
+// one that matches it, with network rules processed before application rules, or nil if none
+// matches. If that rule denies the flow, the first rule after it that allows the flow is returned
+// too, if there is one.
+func evaluateFlow(collections []*firewallCollection, f firewallFlow) (decided, allowedLater *firewallMatch) {
+	var matches []firewallMatch
+	for _, c := range collections {
+		for _, r := range c.network {
+			if networkRuleMatches(r, f, c.deny) {
+				matches = append(matches, firewallMatch{c, strPtrValue(r.Name)})
+				break
+			}
+		}
+	}
+	if f.application {
+		for _, c := range collections {
+			for _, r := range c.application {
+				if applicationRuleMatches(r, f, c.deny) {
+					matches = append(matches, firewallMatch{c, strPtrValue(r.Name)})
+					break
+				}
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	if !matches[0].collection.deny {
+		return &matches[0], nil
+	}
+	for _, m := range matches[1:] {
+		if !m.collection.deny {
+			return &matches[0], &m
+		}
+	}
+	return &matches[0], nil
+}
+
+// networkRuleMatches returns whether a network rule matches a flow. Network rules see HTTP and
+// HTTPS traffic as TCP, and only match the FQDNs of application flows with their destination FQDNs
+// or a wildcard destination address, since the addresses that the FQDNs resolve to are unknown.
+func networkRuleMatches(r *armnetwork.Rule, f firewallFlow, deny bool) bool {
+	protocol := f.protocol
+	if f.application {
+		protocol = string(armnetwork.FirewallPolicyRuleNetworkProtocolTCP)
+	}
+	if !anyOf(r.IPProtocols, func(p armnetwork.FirewallPolicyRuleNetworkProtocol) bool {
+		return p == armnetwork.FirewallPolicyRuleNetworkProtocolAny || strings.EqualFold(string(p), protocol)
+	}) {
+		return false
+	}
+	if !anyOf(r.DestinationPorts, func(p string) bool { return portCovers(p, f.port) }) {
+		return false
+	}
+	if f.fqdn {
+		if !anyOf(r.DestinationFqdns, func(d string) bool { return fqdnCovers(d, f.destination) }) &&
+			!anyOf(r.DestinationAddresses, func(a string) bool { return strings.TrimSpace(a) == "*" }) {
+			return false
+		}
+	} else if !anyOf(r.DestinationAddresses, func(a string) bool { return addressCovers(a, f.destination) }) {
+		return false
+	}
+	return sourcesCover(r.SourceAddresses, f.source, deny)
+}
+
+// applicationRuleMatches returns whether an application rule matches a flow, by its target FQDNs.
+func applicationRuleMatches(r *armnetwork.ApplicationRule, f firewallFlow, deny bool) bool {
+	if !anyOf(r.Protocols, func(p armnetwork.FirewallPolicyRuleApplicationProtocol) bool {
+		return p.ProtocolType != nil && strings.EqualFold(string(*p.ProtocolType), f.protocol) && p.Port != nil && *p.Port == f.port
+	}) {
+		return false
+	}
+	if !anyOf(r.TargetFqdns, func(d string) bool { return fqdnCovers(d, f.destination) }) {
+		return false
+	}
+	return sourcesCover(r.SourceAddresses, f.source, deny)
+}
+
+// sourcesCover returns whether the source addresses of a firewall rule cover the source of a
+// flow. Flows from any source are covered by the rules that allow them from some source, but only
+// by the rules that deny them from all sources.
+func sourcesCover(sources []*string, source string, deny bool) bool {
+	if source == "" {
+		return !deny || anyOf(sources, func(s string) bool { return strings.TrimSpace(s) == "*" })
+	}
+	return anyOf(sources, func(s string) bool { return addressCovers(s, source) })
+}
+
+// fqdnCovers returns whether a firewall rule's FQDN, which may start with a wildcard, matches all
+// traffic to an FQDN. The wildcard matches any characters, including dots, so *.ubuntu.com matches
+// archive.ubuntu.com and security.archive.ubuntu.com, but not ubuntu.com. If the FQDN starts with a
+// wildcard too, the rule must match everything that the FQDN does: *.ubuntu.com and * cover
+// *.archive.ubuntu.com, but archive.ubuntu.com doesn't. FQDNs are compared case-insensitively,
+// without trailing dots.
+func fqdnCovers(pattern, fqdn string) bool {
+	pattern, fqdn = normalizeFQDN(pattern), normalizeFQDN(fqdn)
+	suffix, wildcard := strings.CutPrefix(pattern, "*")
+	if !wildcard {
+		return pattern == fqdn
+	}
+	return strings.HasSuffix(strings.TrimPrefix(fqdn, "*"), suffix)
+}
+
+func normalizeFQDN(fqdn string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(fqdn)), ".")
+}
+
+// addressCovers returns whether a firewall rule's address (an IP address, a CIDR, a range like
+// 10.0.0.1-10.0.0.9, a service tag, or *) covers all of an address, CIDR, or service tag. Service
+// tags only cover themselves, compared case-insensitively.
+func addressCovers(entry, address string) bool {
+	entry, address = strings.TrimSpace(entry), strings.TrimSpace(address)
+	if entry == "*" {
+		return true
+	}
+	want, ok := parseIPRange(address)
+	if !ok {
+		return strings.EqualFold(entry, address)
+	}
+	got, ok := parseIPRange(entry)
+	return ok && got.first.Compare(want.first) <= 0 && want.last.Compare(got.last) <= 0
+}
+
+// ipRange is a range of IP addresses, including its first and last address.
+type ipRange struct {
+	first, last netip.Addr
+}
+
+// parseIPRange parses an IP address, a CIDR, or a range like 10.0.0.1-10.0.0.9.
+func parseIPRange(s string) (ipRange, bool) {
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		first, err1 := netip.ParseAddr(strings.TrimSpace(from))
+		last, err2 := netip.ParseAddr(strings.TrimSpace(to))
+		if err1 != nil || err2 != nil || first.Is4() != last.Is4() || last.Less(first) {
+			return ipRange{}, false
+		}
+		return ipRange{first, last}, true
+	}
+	if p, err := netip.ParsePrefix(s); err == nil {
+		p = p.Masked()
+		return ipRange{p.Addr(), lastAddr(p)}, true
+	}
+	if a, err := netip.ParseAddr(s); err == nil {
+		return ipRange{a, a}, true
+	}
+	return ipRange{}, false
+}
+
+// lastAddr returns the last address of a masked prefix.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := range b {
+		switch start := i * 8; {
+		case start >= p.Bits():
+			b[i] = 0xff
+		case start+8 > p.Bits():
+			b[i] |= 0xff >> (p.Bits() - start)
+		}
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// portCovers returns whether a firewall rule's destination port (a port, a range like 8000-8999,
+// or *) covers a port.
+func portCovers(entry string, port int32) bool {
+	entry = strings.TrimSpace(entry)
+	if entry == "*" {
+		return true
+	}
+	if from, to, ok := strings.Cut(entry, "-"); ok {
+		first, err1 := strconv.ParseInt(strings.TrimSpace(from), 10, 32)
+		last, err2 := strconv.ParseInt(strings.TrimSpace(to), 10, 32)
+		return err1 == nil && err2 == nil && first <= int64(port) && int64(port) <= last
+	}
+	n, err := strconv.ParseInt(entry, 10, 32)
+	return err == nil && n == int64(port)
+}
+
+// anyOf returns whether any of the non-nil values that a slice of pointers points to satisfies f.
+func anyOf[T any](values []*T, f func(T) bool) bool {
+	for _, v := range values {
+		if v != nil && f(*v) {
+			return true
+		}
+	}
+	return false
+}
+
+func int32PtrValue(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
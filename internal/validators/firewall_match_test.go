@@ -0,0 +1,85 @@
+package validators
+
+import "testing"
+
+func Test_fqdnCovers(t *testing.T) {
+	tests := []struct {
+		pattern string
+		fqdn    string
+		want    bool
+	}{
+		{pattern: "mcr.microsoft.com", fqdn: "mcr.microsoft.com", want: true},
+		{pattern: "MCR.Microsoft.com.", fqdn: "mcr.microsoft.com", want: true},
+		{pattern: "mcr.microsoft.com", fqdn: "data.mcr.microsoft.com", want: false},
+		{pattern: "*.ubuntu.com", fqdn: "archive.ubuntu.com", want: true},
+		{pattern: "*.ubuntu.com", fqdn: "security.archive.ubuntu.com", want: true},
+		{pattern: "*.ubuntu.com", fqdn: "ubuntu.com", want: false},
+		{pattern: "*.ubuntu.com", fqdn: "notubuntu.com", want: false},
+		{pattern: "*.ubuntu.com", fqdn: "archive.ubuntu.com.evil.example", want: false},
+		{pattern: "*ubuntu.com", fqdn: "ubuntu.com", want: true},
+		{pattern: "*ubuntu.com", fqdn: "notubuntu.com", want: true},
+		{pattern: "*", fqdn: "anything.example", want: true},
+		{pattern: "*.ubuntu.com", fqdn: "*.ubuntu.com", want: true},
+		{pattern: "*.ubuntu.com", fqdn: "*.archive.ubuntu.com", want: true},
+		{pattern: "*", fqdn: "*.ubuntu.com", want: true},
+		{pattern: "*.archive.ubuntu.com", fqdn: "*.ubuntu.com", want: false},
+		{pattern: "archive.ubuntu.com", fqdn: "*.ubuntu.com", want: false},
+		{pattern: "*.ubuntu.com", fqdn: "*ubuntu.com", want: false},
+		{pattern: "*.ubuntu.com", fqdn: "*", want: false},
+	}
+	for _, tt := range tests {
+		if got := fqdnCovers(tt.pattern, tt.fqdn); got != tt.want {
+			t.Errorf("fqdnCovers(%q, %q) = %t, want %t", tt.pattern, tt.fqdn, got, tt.want)
+		}
+	}
+}
+
+func Test_addressCovers(t *testing.T) {
+	tests := []struct {
+		entry   string
+		address string
+		want    bool
+	}{
+		{entry: "*", address: "10.0.0.1", want: true},
+		{entry: "*", address: "AzureCloud", want: true},
+		{entry: "10.0.0.1", address: "10.0.0.1", want: true},
+		{entry: "10.0.0.0/16", address: "10.0.3.4", want: true},
+		{entry: "10.0.0.0/16", address: "10.0.128.0/17", want: true},
+		{entry: "10.0.0.0/16", address: "10.0.0.0/8", want: false},
+		{entry: "10.0.1.2/16", address: "10.0.255.255", want: true},
+		{entry: "10.0.0.1-10.0.0.9", address: "10.0.0.8/30", want: false},
+		{entry: "10.0.0.1-10.0.0.9", address: "10.0.0.4/30", want: true},
+		{entry: "10.0.0.9-10.0.0.1", address: "10.0.0.4", want: false},
+		{entry: "fd00::/8", address: "fd12::1", want: true},
+		{entry: "0.0.0.0/0", address: "fd12::1", want: false},
+		{entry: "AzureContainerRegistry", address: "azurecontainerregistry", want: true},
+		{entry: "AzureContainerRegistry", address: "10.0.0.1", want: false},
+		{entry: "10.0.0.0/8", address: "*", want: false},
+	}
+	for _, tt := range tests {
+		if got := addressCovers(tt.entry, tt.address); got != tt.want {
+			t.Errorf("addressCovers(%q, %q) = %t, want %t", tt.entry, tt.address, got, tt.want)
+		}
+	}
+}
+
+func Test_portCovers(t *testing.T) {
+	tests := []struct {
+		entry string
+		port  int32
+		want  bool
+	}{
+		{entry: "*", port: 443, want: true},
+		{entry: "443", port: 443, want: true},
+		{entry: " 443 ", port: 443, want: true},
+		{entry: "80", port: 443, want: false},
+		{entry: "400-500", port: 443, want: true},
+		{entry: "400-442", port: 443, want: false},
+		{entry: "https", port: 443, want: false},
+	}
+	for _, tt := range tests {
+		if got := portCovers(tt.entry, tt.port); got != tt.want {
+			t.Errorf("portCovers(%q, %d) = %t, want %t", tt.entry, tt.port, got, tt.want)
+		}
+	}
+}
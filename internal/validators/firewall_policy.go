@@ -0,0 +1,106 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// firewallPolicyAPI contains methods that allow getting the rule collection groups of a firewall
+// policy by its resource ID.
+type firewallPolicyAPI interface {
+	ListRuleCollectionGroups(firewallPolicyID string) ([]*armnetwork.FirewallPolicyRuleCollectionGroup, error)
+}
+
+type FirewallPolicyRuleService struct {
+	log logr.Logger
+	api firewallPolicyAPI
+}
+
+func NewFirewallPolicyRuleService(log logr.Logger, api firewallPolicyAPI) *FirewallPolicyRuleService {
+	return &FirewallPolicyRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileFirewallPolicyRule reconciles a firewall policy rule from a validation config.
+func (s *FirewallPolicyRuleService) ReconcileFirewallPolicyRule(rule v1alpha1.FirewallPolicyRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this firewall policy rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.FirewallPolicySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeFirewallPolicy
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeFirewallPolicy, "firewallPolicyID", rule.FirewallPolicyID)
+	l.V(1).Info("Validating firewall policy")
+	ev := &evidence{}
+	if err := s.validateFirewallPolicy(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate firewall policy", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.FirewallPolicyFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateFirewallPolicy appends a failure for each flow of the expected rules that the policy
+// doesn't allow, or denies before allowing it, and for each of the rule's rule collection groups
+// that the policy doesn't have. A policy that doesn't exist is a failure, not an error.
+func (s *FirewallPolicyRuleService) validateFirewallPolicy(rule v1alpha1.FirewallPolicyRule, failures *[]string, ev *evidence) error {
+	groups, err := s.api.ListRuleCollectionGroups(rule.FirewallPolicyID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.FirewallPolicyNotFoundFailure, rule.FirewallPolicyID))
+			return nil
+		}
+		return fmt.Errorf("failed to list rule collection groups: %w", azure_errors.AsAugmented(err))
+	}
+	collections, missing := firewallCollections(groups, rule.RuleCollectionGroups)
+	for _, name := range missing {
+		*failures = append(*failures, fmt.Sprintf(messages.RuleCollectionGroupNotFoundFailure, rule.FirewallPolicyID, name))
+	}
+	ev.add("Firewall policy %s has %d filter rule collection(s) that count.", rule.FirewallPolicyID, len(collections))
+
+	for _, want := range rule.ExpectedRules {
+		for _, f := range firewallFlows(want) {
+			decided, allowedLater := evaluateFlow(collections, f)
+			switch {
+			case decided == nil:
+				*failures = append(*failures, fmt.Sprintf(messages.FirewallTrafficNotAllowedFailure, rule.FirewallPolicyID, f))
+			case decided.collection.deny && allowedLater != nil:
+				*failures = append(*failures, fmt.Sprintf(messages.FirewallTrafficDeniedFirstFailure, rule.FirewallPolicyID, f, decided, allowedLater))
+			case decided.collection.deny:
+				*failures = append(*failures, fmt.Sprintf(messages.FirewallTrafficDeniedFailure, rule.FirewallPolicyID, f, decided))
+			default:
+				ev.add("Firewall policy %s allows %s with %s.", rule.FirewallPolicyID, f, decided)
+			}
+		}
+	}
+	return nil
+}
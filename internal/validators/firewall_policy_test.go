@@ -0,0 +1,262 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testFirewallPolicyID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/firewallPolicies/egress"
+
+// firewallPolicyAPIMock is a fake ARM with the firewall policy testFirewallPolicyID, whose rule
+// collection groups are data. Listing the rule collection groups of any other policy fails with a
+// 404, unless err is set.
+type firewallPolicyAPIMock struct {
+	data []*armnetwork.FirewallPolicyRuleCollectionGroup
+	err  error
+}
+
+func (m firewallPolicyAPIMock) ListRuleCollectionGroups(firewallPolicyID string) ([]*armnetwork.FirewallPolicyRuleCollectionGroup, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if firewallPolicyID != testFirewallPolicyID {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+func newRuleCollectionGroup(name string, priority int32, collections ...armnetwork.FirewallPolicyRuleCollectionClassification) *armnetwork.FirewallPolicyRuleCollectionGroup {
+	return &armnetwork.FirewallPolicyRuleCollectionGroup{
+		Name: util.Ptr(name),
+		Properties: &armnetwork.FirewallPolicyRuleCollectionGroupProperties{
+			Priority:        util.Ptr(priority),
+			RuleCollections: collections,
+		},
+	}
+}
+
+func newFilterRuleCollection(name string, priority int32, action armnetwork.FirewallPolicyFilterRuleCollectionActionType, rules ...armnetwork.FirewallPolicyRuleClassification) *armnetwork.FirewallPolicyFilterRuleCollection {
+	return &armnetwork.FirewallPolicyFilterRuleCollection{
+		Name:               util.Ptr(name),
+		Priority:           util.Ptr(priority),
+		RuleCollectionType: util.Ptr(armnetwork.FirewallPolicyRuleCollectionTypeFirewallPolicyFilterRuleCollection),
+		Action:             &armnetwork.FirewallPolicyFilterRuleCollectionAction{Type: util.Ptr(action)},
+		Rules:              rules,
+	}
+}
+
+func newApplicationRule(name string, sources, fqdns []string, protocol armnetwork.FirewallPolicyRuleApplicationProtocolType, port int32) *armnetwork.ApplicationRule {
+	return &armnetwork.ApplicationRule{
+		Name:            util.Ptr(name),
+		RuleType:        util.Ptr(armnetwork.FirewallPolicyRuleTypeApplicationRule),
+		SourceAddresses: to.SliceOfPtrs(sources...),
+		TargetFqdns:     to.SliceOfPtrs(fqdns...),
+		Protocols:       []*armnetwork.FirewallPolicyRuleApplicationProtocol{{ProtocolType: util.Ptr(protocol), Port: util.Ptr(port)}},
+	}
+}
+
+func newNetworkRule(name string, sources, destinations, ports []string, protocol armnetwork.FirewallPolicyRuleNetworkProtocol) *armnetwork.Rule {
+	return &armnetwork.Rule{
+		Name:                 util.Ptr(name),
+		RuleType:             util.Ptr(armnetwork.FirewallPolicyRuleTypeNetworkRule),
+		SourceAddresses:      to.SliceOfPtrs(sources...),
+		DestinationAddresses: to.SliceOfPtrs(destinations...),
+		DestinationPorts:     to.SliceOfPtrs(ports...),
+		IPProtocols:          []*armnetwork.FirewallPolicyRuleNetworkProtocol{util.Ptr(protocol)},
+	}
+}
+
+func TestFirewallPolicyRuleService_ReconcileFirewallPolicyRule(t *testing.T) {
+	const (
+		allow = armnetwork.FirewallPolicyFilterRuleCollectionActionTypeAllow
+		deny  = armnetwork.FirewallPolicyFilterRuleCollectionActionTypeDeny
+		https = armnetwork.FirewallPolicyRuleApplicationProtocolTypeHTTPS
+		tcp   = armnetwork.FirewallPolicyRuleNetworkProtocolTCP
+		udp   = armnetwork.FirewallPolicyRuleNetworkProtocolUDP
+	)
+	aksSubnet := "10.240.0.0/16"
+	egress := newRuleCollectionGroup("egress", 200,
+		newFilterRuleCollection("aks-fqdns", 100, allow,
+			newApplicationRule("mcr", []string{aksSubnet}, []string{"mcr.microsoft.com", "*.data.mcr.microsoft.com"}, https, 443),
+			newApplicationRule("ubuntu", []string{"*"}, []string{"*.ubuntu.com"}, armnetwork.FirewallPolicyRuleApplicationProtocolTypeHTTP, 80),
+		),
+		newFilterRuleCollection("aks-network", 200, allow,
+			newNetworkRule("ntp", []string{aksSubnet}, []string{"*"}, []string{"123"}, udp),
+			newNetworkRule("api-server", []string{aksSubnet}, []string{"AzureCloud.westus"}, []string{"1194", "9000-9001"}, tcp),
+		),
+	)
+	mcrRule := v1alpha1.ExpectedFirewallRule{Type: "Application", DestinationFQDNs: []string{"mcr.microsoft.com", "eastus.data.mcr.microsoft.com"}, Ports: []int32{443}, Protocols: []string{"Https"}}
+
+	tests := []struct {
+		name         string
+		groups       []*armnetwork.FirewallPolicyRuleCollectionGroup
+		rule         v1alpha1.FirewallPolicyRule
+		wantFailures []string
+	}{
+		{
+			name:   "Passes when application and network rules allow all expected traffic.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{egress},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID,
+				ExpectedRules: []v1alpha1.ExpectedFirewallRule{
+					mcrRule,
+					{Type: "Application", DestinationFQDNs: []string{"archive.ubuntu.com", "*.security.ubuntu.com"}, Ports: []int32{80}, Protocols: []string{"Http"}, SourceAddresses: []string{"10.240.1.0/24"}},
+					{Type: "Network", DestinationAddresses: []string{"AzureCloud.westus"}, Ports: []int32{1194, 9000}, Protocols: []string{"TCP"}, SourceAddresses: []string{aksSubnet}},
+					{Type: "Network", DestinationFQDNs: []string{"ntp.ubuntu.com"}, Ports: []int32{123}, Protocols: []string{"UDP"}},
+				},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails for each flow that no rule allows.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{egress},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID,
+				ExpectedRules: []v1alpha1.ExpectedFirewallRule{
+					{Type: "Application", DestinationFQDNs: []string{"ubuntu.com", "packages.microsoft.com"}, Ports: []int32{80}, Protocols: []string{"Http"}},
+					{Type: "Application", DestinationFQDNs: []string{"mcr.microsoft.com"}, Ports: []int32{443}, Protocols: []string{"Https"}, SourceAddresses: []string{"10.0.0.0/8"}},
+					{Type: "Network", DestinationAddresses: []string{"AzureCloud.westus"}, Ports: []int32{443}, Protocols: []string{"TCP"}},
+				},
+			},
+			wantFailures: []string{
+				"Firewall policy " + testFirewallPolicyID + " doesn't allow Http:80 traffic to ubuntu.com.",
+				"Firewall policy " + testFirewallPolicyID + " doesn't allow Http:80 traffic to packages.microsoft.com.",
+				"Firewall policy " + testFirewallPolicyID + " doesn't allow Https:443 traffic to mcr.microsoft.com from 10.0.0.0/8.",
+				"Firewall policy " + testFirewallPolicyID + " doesn't allow TCP:443 traffic to AzureCloud.westus.",
+			},
+		},
+		{
+			name: "Fails when a deny rule with a higher priority matches first.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{
+				egress,
+				newRuleCollectionGroup("baseline", 100,
+					newFilterRuleCollection("deny-microsoft", 100, deny,
+						newApplicationRule("deny-all-microsoft", []string{"*"}, []string{"*.microsoft.com"}, https, 443),
+					),
+				),
+			},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID,
+				ExpectedRules:    []v1alpha1.ExpectedFirewallRule{mcrRule},
+			},
+			wantFailures: []string{
+				"Firewall policy " + testFirewallPolicyID + " denies Https:443 traffic to mcr.microsoft.com with rule deny-all-microsoft of rule collection deny-microsoft in rule collection group baseline, before rule mcr of rule collection aks-fqdns in rule collection group egress allows it.",
+				"Firewall policy " + testFirewallPolicyID + " denies Https:443 traffic to eastus.data.mcr.microsoft.com with rule deny-all-microsoft of rule collection deny-microsoft in rule collection group baseline, before rule mcr of rule collection aks-fqdns in rule collection group egress allows it.",
+			},
+		},
+		{
+			name: "Processes network rules before application rules, whatever their priorities.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{
+				egress,
+				newRuleCollectionGroup("catch-all", 60000,
+					newFilterRuleCollection("deny-tcp", 60000, deny,
+						newNetworkRule("deny-all-tcp", []string{"*"}, []string{"*"}, []string{"*"}, armnetwork.FirewallPolicyRuleNetworkProtocolAny),
+					),
+				),
+			},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID,
+				ExpectedRules:    []v1alpha1.ExpectedFirewallRule{{Type: "Application", DestinationFQDNs: []string{"mcr.microsoft.com"}, Ports: []int32{443}, Protocols: []string{"Https"}}},
+			},
+			wantFailures: []string{
+				"Firewall policy " + testFirewallPolicyID + " denies Https:443 traffic to mcr.microsoft.com with rule deny-all-tcp of rule collection deny-tcp in rule collection group catch-all, before rule mcr of rule collection aks-fqdns in rule collection group egress allows it.",
+			},
+		},
+		{
+			name: "Ignores deny rules that don't deny all sources when the expected rule has none.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{
+				egress,
+				newRuleCollectionGroup("baseline", 100,
+					newFilterRuleCollection("deny-dmz", 100, deny,
+						newApplicationRule("deny-dmz-microsoft", []string{"192.168.0.0/16"}, []string{"*.microsoft.com"}, https, 443),
+					),
+				),
+			},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID,
+				ExpectedRules:    []v1alpha1.ExpectedFirewallRule{mcrRule},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails when only a narrower wildcard is allowed.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{egress},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID,
+				ExpectedRules:    []v1alpha1.ExpectedFirewallRule{{Type: "Application", DestinationFQDNs: []string{"*.mcr.microsoft.com"}, Ports: []int32{443}, Protocols: []string{"Https"}}},
+			},
+			wantFailures: []string{"Firewall policy " + testFirewallPolicyID + " doesn't allow Https:443 traffic to *.mcr.microsoft.com."},
+		},
+		{
+			name:   "Only counts the rules of the rule collection groups of the rule, and fails for missing ones.",
+			groups: []*armnetwork.FirewallPolicyRuleCollectionGroup{egress},
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID:     testFirewallPolicyID,
+				RuleCollectionGroups: []string{"aks-egress"},
+				ExpectedRules:        []v1alpha1.ExpectedFirewallRule{{Type: "Application", DestinationFQDNs: []string{"mcr.microsoft.com"}, Ports: []int32{443}, Protocols: []string{"Https"}}},
+			},
+			wantFailures: []string{
+				"Firewall policy " + testFirewallPolicyID + " has no rule collection group aks-egress.",
+				"Firewall policy " + testFirewallPolicyID + " doesn't allow Https:443 traffic to mcr.microsoft.com.",
+			},
+		},
+		{
+			name: "Fails when the firewall policy doesn't exist.",
+			rule: v1alpha1.FirewallPolicyRule{
+				FirewallPolicyID: testFirewallPolicyID + "-deleted",
+				ExpectedRules:    []v1alpha1.ExpectedFirewallRule{mcrRule},
+			},
+			wantFailures: []string{"Firewall policy " + testFirewallPolicyID + "-deleted not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewFirewallPolicyRuleService(logr.Discard(), firewallPolicyAPIMock{data: tt.groups})
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileFirewallPolicyRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestFirewallPolicyRuleService_ReconcileFirewallPolicyRule_Error(t *testing.T) {
+	api := firewallPolicyAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewFirewallPolicyRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileFirewallPolicyRule(v1alpha1.FirewallPolicyRule{
+		Name:             "rule-1",
+		FirewallPolicyID: testFirewallPolicyID,
+		ExpectedRules:    []v1alpha1.ExpectedFirewallRule{{Type: "Network", DestinationAddresses: []string{"*"}, Ports: []int32{443}, Protocols: []string{"TCP"}}},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
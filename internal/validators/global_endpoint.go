@@ -0,0 +1,178 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// trafficManagerAPI contains methods that allow getting a Traffic Manager profile by its resource
+// ID.
+type trafficManagerAPI interface {
+	GetProfile(profileID string) (*armtrafficmanager.Profile, error)
+}
+
+// entryPoint is a global entry point of any kind, such as a Traffic Manager profile, with what
+// global endpoint rules validate about it.
+type entryPoint struct {
+	// kind is what the entry point is called in failures, e.g. Traffic Manager profile.
+	kind      string
+	id        string
+	status    string
+	endpoints []entryPointEndpoint
+}
+
+type entryPointEndpoint struct {
+	name          string
+	status        string
+	monitorStatus string
+}
+
+func (p entryPoint) String() string {
+	return fmt.Sprintf("%s %s", p.kind, p.id)
+}
+
+type GlobalEndpointRuleService struct {
+	log            logr.Logger
+	trafficManager trafficManagerAPI
+}
+
+func NewGlobalEndpointRuleService(log logr.Logger, trafficManager trafficManagerAPI) *GlobalEndpointRuleService {
+	return &GlobalEndpointRuleService{
+		log:            log,
+		trafficManager: trafficManager,
+	}
+}
+
+// ReconcileGlobalEndpointRule reconciles a global endpoint rule from a validation config.
+func (s *GlobalEndpointRuleService) ReconcileGlobalEndpointRule(rule v1alpha1.GlobalEndpointRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this global endpoint rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.GlobalEndpointSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeGlobalEndpoint
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeGlobalEndpoint, "trafficManagerProfileID", rule.TrafficManagerProfileID)
+	l.V(1).Info("Validating global entry point")
+	ev := &evidence{}
+	if err := s.validateEntryPoint(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate global entry point", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.trafficManager)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.GlobalEndpointFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateEntryPoint appends a failure if the rule's entry point is disabled, and one for each of
+// the rule's endpoints that it doesn't have, that's disabled, or whose monitor status isn't
+// healthy. An entry point that doesn't exist is a failure, not an error.
+func (s *GlobalEndpointRuleService) validateEntryPoint(rule v1alpha1.GlobalEndpointRule, failures *[]string, ev *evidence) error {
+	point, err := s.getEntryPoint(rule)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.GlobalEndpointNotFoundFailure, point))
+			return nil
+		}
+		return err
+	}
+
+	if !strings.EqualFold(point.status, "Enabled") {
+		*failures = append(*failures, fmt.Sprintf(messages.GlobalEndpointDisabledFailure, point, point.status))
+	}
+
+	healthy := []string{string(armtrafficmanager.EndpointMonitorStatusOnline)}
+	if len(rule.HealthyMonitorStatuses) > 0 {
+		healthy = make([]string, len(rule.HealthyMonitorStatuses))
+		for i, status := range rule.HealthyMonitorStatuses {
+			healthy[i] = string(status)
+		}
+	}
+	for _, name := range rule.Endpoints {
+		i := slices.IndexFunc(point.endpoints, func(e entryPointEndpoint) bool { return strings.EqualFold(e.name, name) })
+		if i < 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.GlobalEndpointMissingFailure, point, name))
+			continue
+		}
+		endpoint := point.endpoints[i]
+		switch {
+		case !strings.EqualFold(endpoint.status, "Enabled"):
+			*failures = append(*failures, fmt.Sprintf(messages.EndpointDisabledFailure, name, point, endpoint.status))
+		case !slices.ContainsFunc(healthy, func(status string) bool { return strings.EqualFold(status, endpoint.monitorStatus) }):
+			*failures = append(*failures, fmt.Sprintf(messages.EndpointMonitorStatusFailure, name, point, endpoint.monitorStatus, strings.Join(healthy, " or ")))
+		default:
+			ev.add("Endpoint %s of %s is Enabled with monitor status %s.", name, point, endpoint.monitorStatus)
+		}
+	}
+	return nil
+}
+
+// getEntryPoint gets the rule's entry point. Its kind and ID are set even if getting it fails.
+func (s *GlobalEndpointRuleService) getEntryPoint(rule v1alpha1.GlobalEndpointRule) (entryPoint, error) {
+	switch {
+	case rule.TrafficManagerProfileID != "":
+		point := entryPoint{kind: "Traffic Manager profile", id: rule.TrafficManagerProfileID}
+		profile, err := s.trafficManager.GetProfile(rule.TrafficManagerProfileID)
+		if err != nil {
+			return point, fmt.Errorf("failed to get Traffic Manager profile: %w", azure_errors.AsAugmented(err))
+		}
+		trafficManagerEntryPoint(&point, profile)
+		return point, nil
+	default:
+		return entryPoint{}, errors.New("global endpoint rule has no profile")
+	}
+}
+
+// trafficManagerEntryPoint sets an entry point's status and endpoints from a Traffic Manager
+// profile's.
+func trafficManagerEntryPoint(point *entryPoint, profile *armtrafficmanager.Profile) {
+	point.status = notSet
+	if profile.Properties == nil {
+		return
+	}
+	if profile.Properties.ProfileStatus != nil {
+		point.status = string(*profile.Properties.ProfileStatus)
+	}
+	for _, e := range profile.Properties.Endpoints {
+		if e == nil || e.Name == nil {
+			continue
+		}
+		endpoint := entryPointEndpoint{name: *e.Name, status: notSet, monitorStatus: notSet}
+		if e.Properties != nil {
+			if e.Properties.EndpointStatus != nil {
+				endpoint.status = string(*e.Properties.EndpointStatus)
+			}
+			if e.Properties.EndpointMonitorStatus != nil {
+				endpoint.monitorStatus = string(*e.Properties.EndpointMonitorStatus)
+			}
+		}
+		point.endpoints = append(point.endpoints, endpoint)
+	}
+}
@@ -0,0 +1,174 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testTrafficManagerProfileID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile-1"
+
+// trafficManagerAPIMock is a fake ARM with the Traffic Manager profiles in its map, keyed by
+// resource ID. Getting any other profile fails with a 404, unless err is set.
+type trafficManagerAPIMock struct {
+	profiles map[string]*armtrafficmanager.Profile
+	err      error
+}
+
+func (m trafficManagerAPIMock) GetProfile(profileID string) (*armtrafficmanager.Profile, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.profiles, profileID)
+}
+
+// newTrafficManagerEndpoint returns a Traffic Manager endpoint with a status and monitor status.
+func newTrafficManagerEndpoint(name string, status armtrafficmanager.EndpointStatus, monitorStatus armtrafficmanager.EndpointMonitorStatus) *armtrafficmanager.Endpoint {
+	return &armtrafficmanager.Endpoint{
+		Name: util.Ptr(name),
+		Properties: &armtrafficmanager.EndpointProperties{
+			EndpointStatus:        util.Ptr(status),
+			EndpointMonitorStatus: util.Ptr(monitorStatus),
+		},
+	}
+}
+
+// newTrafficManagerProfile returns an enabled Traffic Manager profile with endpoints.
+func newTrafficManagerProfile(endpoints ...*armtrafficmanager.Endpoint) *armtrafficmanager.Profile {
+	return &armtrafficmanager.Profile{
+		Properties: &armtrafficmanager.ProfileProperties{
+			ProfileStatus: util.Ptr(armtrafficmanager.ProfileStatusEnabled),
+			Endpoints:     endpoints,
+		},
+	}
+}
+
+func TestGlobalEndpointRuleService_ReconcileGlobalEndpointRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      *armtrafficmanager.Profile
+		rule         v1alpha1.GlobalEndpointRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the profile is enabled and its endpoints are online.",
+			profile: newTrafficManagerProfile(
+				newTrafficManagerEndpoint("eastus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusOnline),
+				newTrafficManagerEndpoint("westus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusOnline),
+				newTrafficManagerEndpoint("standby", armtrafficmanager.EndpointStatusDisabled, armtrafficmanager.EndpointMonitorStatusDisabled),
+			),
+			rule:         v1alpha1.GlobalEndpointRule{Endpoints: []string{"EastUS", "westus"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when an endpoint is degraded and Degraded is healthy.",
+			profile: newTrafficManagerProfile(
+				newTrafficManagerEndpoint("eastus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusDegraded),
+			),
+			rule:         v1alpha1.GlobalEndpointRule{Endpoints: []string{"eastus"}, HealthyMonitorStatuses: []v1alpha1.EndpointMonitorStatus{"Online", "Degraded"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when an endpoint is degraded and only Online is healthy.",
+			profile: newTrafficManagerProfile(
+				newTrafficManagerEndpoint("eastus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusDegraded),
+				newTrafficManagerEndpoint("westus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusCheckingEndpoint),
+			),
+			rule: v1alpha1.GlobalEndpointRule{Endpoints: []string{"eastus", "westus"}},
+			wantFailures: []string{
+				"Endpoint eastus of Traffic Manager profile " + testTrafficManagerProfileID + " has monitor status Degraded, not Online.",
+				"Endpoint westus of Traffic Manager profile " + testTrafficManagerProfileID + " has monitor status CheckingEndpoint, not Online.",
+			},
+		},
+		{
+			name: "Fails when an endpoint is stopped, even if Degraded is healthy.",
+			profile: newTrafficManagerProfile(
+				newTrafficManagerEndpoint("eastus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusStopped),
+			),
+			rule: v1alpha1.GlobalEndpointRule{Endpoints: []string{"eastus"}, HealthyMonitorStatuses: []v1alpha1.EndpointMonitorStatus{"Online", "Degraded"}},
+			wantFailures: []string{
+				"Endpoint eastus of Traffic Manager profile " + testTrafficManagerProfileID + " has monitor status Stopped, not Online or Degraded.",
+			},
+		},
+		{
+			name: "Fails when an endpoint is disabled or missing.",
+			profile: newTrafficManagerProfile(
+				newTrafficManagerEndpoint("eastus", armtrafficmanager.EndpointStatusDisabled, armtrafficmanager.EndpointMonitorStatusDisabled),
+			),
+			rule: v1alpha1.GlobalEndpointRule{Endpoints: []string{"eastus", "westus"}},
+			wantFailures: []string{
+				"Endpoint eastus of Traffic Manager profile " + testTrafficManagerProfileID + " is Disabled, not Enabled.",
+				"Traffic Manager profile " + testTrafficManagerProfileID + " has no endpoint westus.",
+			},
+		},
+		{
+			name: "Fails when the profile is disabled.",
+			profile: func() *armtrafficmanager.Profile {
+				p := newTrafficManagerProfile(
+					newTrafficManagerEndpoint("eastus", armtrafficmanager.EndpointStatusEnabled, armtrafficmanager.EndpointMonitorStatusInactive),
+				)
+				p.Properties.ProfileStatus = util.Ptr(armtrafficmanager.ProfileStatusDisabled)
+				return p
+			}(),
+			rule: v1alpha1.GlobalEndpointRule{Endpoints: []string{"eastus"}},
+			wantFailures: []string{
+				"Traffic Manager profile " + testTrafficManagerProfileID + " is Disabled, not Enabled.",
+				"Endpoint eastus of Traffic Manager profile " + testTrafficManagerProfileID + " has monitor status Inactive, not Online.",
+			},
+		},
+		{
+			name:         "Fails when the profile is missing.",
+			rule:         v1alpha1.GlobalEndpointRule{Endpoints: []string{"eastus"}},
+			wantFailures: []string{"Traffic Manager profile " + testTrafficManagerProfileID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles := map[string]*armtrafficmanager.Profile{}
+			if tt.profile != nil {
+				profiles[testTrafficManagerProfileID] = tt.profile
+			}
+			svc := NewGlobalEndpointRuleService(logr.Discard(), trafficManagerAPIMock{profiles: profiles})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.TrafficManagerProfileID = testTrafficManagerProfileID
+			result, err := svc.ReconcileGlobalEndpointRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestGlobalEndpointRuleService_ReconcileGlobalEndpointRule_Error(t *testing.T) {
+	api := trafficManagerAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewGlobalEndpointRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileGlobalEndpointRule(v1alpha1.GlobalEndpointRule{Name: "rule-1", TrafficManagerProfileID: testTrafficManagerProfileID, Endpoints: []string{"eastus"}})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,134 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// groupAPI contains methods that allow getting Microsoft Entra ID groups, by object ID or display
+// name, and their members from Microsoft Graph.
+type groupAPI interface {
+	GetGroup(groupID string) (*azure_utils.GraphGroup, error)
+	ListGroupsByDisplayName(displayName string) ([]*azure_utils.GraphGroup, error)
+	ListMembers(groupID string, transitive bool) ([]*azure_utils.GraphDirectoryObject, error)
+}
+
+type GroupMembershipRuleService struct {
+	log logr.Logger
+	api groupAPI
+}
+
+func NewGroupMembershipRuleService(log logr.Logger, api groupAPI) *GroupMembershipRuleService {
+	return &GroupMembershipRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileGroupMembershipRule reconciles a group membership rule from a validation config.
+func (s *GroupMembershipRuleService) ReconcileGroupMembershipRule(rule v1alpha1.GroupMembershipRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this group membership rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.GroupMembershipSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeGroupMembership
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeGroupMembership, "groupID", rule.GroupID, "groupDisplayName", rule.GroupDisplayName)
+	l.V(1).Info("Validating group membership")
+	ev := &evidence{}
+	if err := s.validateGroupMembership(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate group membership", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.GroupMembershipFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateGroupMembership appends a failure if the rule's group doesn't exist, or, for rules that
+// name the group, if no group or several groups have the name. Otherwise, it appends one for each
+// of the rule's principals that isn't a member of the group.
+func (s *GroupMembershipRuleService) validateGroupMembership(rule v1alpha1.GroupMembershipRule, failures *[]string, ev *evidence) error {
+	group, err := s.findGroup(rule, failures)
+	if err != nil || group == nil {
+		return err
+	}
+
+	members, err := s.api.ListMembers(group.ID, !rule.DirectOnly)
+	if err != nil {
+		return fmt.Errorf("failed to list group members: %w", azure_errors.AsAugmented(err))
+	}
+	memberIDs := make([]string, 0, len(members))
+	for _, m := range members {
+		if m != nil {
+			memberIDs = append(memberIDs, m.ID)
+		}
+	}
+	kind := "member"
+	if rule.DirectOnly {
+		kind = "direct member"
+	}
+	for _, id := range missingItems(rule.MemberIDs, memberIDs, strings.ToLower) {
+		*failures = append(*failures, fmt.Sprintf(messages.GroupMemberMissingFailure, id, kind, group.ID))
+	}
+
+	ev.add("Group %s (%s) has %d %s(s).", group.ID, group.DisplayName, len(memberIDs), kind)
+	return nil
+}
+
+// findGroup returns the rule's group, or nil, after appending a failure, if it can't be found.
+func (s *GroupMembershipRuleService) findGroup(rule v1alpha1.GroupMembershipRule, failures *[]string) (*azure_utils.GraphGroup, error) {
+	if rule.GroupID != "" {
+		group, err := s.api.GetGroup(rule.GroupID)
+		if err != nil {
+			var rerr *azcore.ResponseError
+			if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+				*failures = append(*failures, fmt.Sprintf(messages.GroupNotFoundFailure, rule.GroupID))
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get group: %w", azure_errors.AsAugmented(err))
+		}
+		return group, nil
+	}
+
+	groups, err := s.api.ListGroupsByDisplayName(rule.GroupDisplayName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", azure_errors.AsAugmented(err))
+	}
+	switch len(groups) {
+	case 0:
+		*failures = append(*failures, fmt.Sprintf(messages.GroupNameNotFoundFailure, rule.GroupDisplayName))
+		return nil, nil
+	case 1:
+		return groups[0], nil
+	default:
+		*failures = append(*failures, fmt.Sprintf(messages.GroupNameAmbiguousFailure, len(groups), rule.GroupDisplayName))
+		return nil, nil
+	}
+}
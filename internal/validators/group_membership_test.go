@@ -0,0 +1,192 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+const (
+	testGroupID       = "11111111-1111-1111-1111-111111111111"
+	testNestedGroupID = "22222222-2222-2222-2222-222222222222"
+	testKubeletID     = "33333333-3333-3333-3333-333333333333"
+	testControlID     = "44444444-4444-4444-4444-444444444444"
+)
+
+// groupAPIMock is a fake Microsoft Graph with groups and their direct members, keyed by group ID.
+// Transitive members are the members of the group and, recursively, of its member groups. Getting
+// any other group fails with a 404, unless err is set.
+type groupAPIMock struct {
+	groups  []*azure_utils.GraphGroup
+	members map[string][]string
+	err     error
+}
+
+func (m groupAPIMock) GetGroup(groupID string) (*azure_utils.GraphGroup, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, g := range m.groups {
+		if g.ID == groupID {
+			return g, nil
+		}
+	}
+	return nil, &azcore.ResponseError{ErrorCode: "Request_ResourceNotFound", StatusCode: http.StatusNotFound}
+}
+
+func (m groupAPIMock) ListGroupsByDisplayName(displayName string) ([]*azure_utils.GraphGroup, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var groups []*azure_utils.GraphGroup
+	for _, g := range m.groups {
+		if g.DisplayName == displayName {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+func (m groupAPIMock) ListMembers(groupID string, transitive bool) ([]*azure_utils.GraphDirectoryObject, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var members []*azure_utils.GraphDirectoryObject
+	for _, id := range m.members[groupID] {
+		members = append(members, &azure_utils.GraphDirectoryObject{ID: id})
+		if transitive {
+			nested, _ := m.ListMembers(id, true)
+			members = append(members, nested...)
+		}
+	}
+	return members, nil
+}
+
+func TestGroupMembershipRuleService_ReconcileGroupMembershipRule(t *testing.T) {
+	// The control plane identity is a direct member of the group, and the kubelet identity is a
+	// member of a group nested in it.
+	api := groupAPIMock{
+		groups: []*azure_utils.GraphGroup{
+			{ID: testGroupID, DisplayName: "aks-admins"},
+			{ID: testNestedGroupID, DisplayName: "aks-nodes"},
+			{ID: "55555555-5555-5555-5555-555555555555", DisplayName: "duplicate"},
+			{ID: "66666666-6666-6666-6666-666666666666", DisplayName: "duplicate"},
+		},
+		members: map[string][]string{
+			testGroupID:       {testControlID, testNestedGroupID},
+			testNestedGroupID: {testKubeletID},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.GroupMembershipRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the principals are transitive members of the group.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupID:   testGroupID,
+				MemberIDs: []string{testControlID, testKubeletID},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when the principals are members of the group with the display name.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupDisplayName: "aks-admins",
+				MemberIDs:        []string{testKubeletID},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for principals that are only transitive members, when direct members are required.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupID:    testGroupID,
+				MemberIDs:  []string{testControlID, testKubeletID},
+				DirectOnly: true,
+			},
+			wantFailures: []string{
+				"Principal " + testKubeletID + " is not a direct member of group " + testGroupID + ".",
+			},
+		},
+		{
+			name: "Fails for each principal that isn't a member.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupID:   testNestedGroupID,
+				MemberIDs: []string{testControlID, testKubeletID, testGroupID},
+			},
+			wantFailures: []string{
+				"Principal " + testControlID + " is not a member of group " + testNestedGroupID + ".",
+				"Principal " + testGroupID + " is not a member of group " + testNestedGroupID + ".",
+			},
+		},
+		{
+			name: "Fails when the group doesn't exist.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupID:   "77777777-7777-7777-7777-777777777777",
+				MemberIDs: []string{testControlID},
+			},
+			wantFailures: []string{"Group 77777777-7777-7777-7777-777777777777 not found."},
+		},
+		{
+			name: "Fails when no group has the display name.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupDisplayName: "missing",
+				MemberIDs:        []string{testControlID},
+			},
+			wantFailures: []string{"No group is named missing."},
+		},
+		{
+			name: "Fails when several groups have the display name.",
+			rule: v1alpha1.GroupMembershipRule{
+				GroupDisplayName: "duplicate",
+				MemberIDs:        []string{testControlID},
+			},
+			wantFailures: []string{"2 groups are named duplicate. Set groupId to choose one."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewGroupMembershipRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileGroupMembershipRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestGroupMembershipRuleService_ReconcileGroupMembershipRule_Error(t *testing.T) {
+	api := groupAPIMock{err: &azcore.ResponseError{ErrorCode: "Authorization_RequestDenied", StatusCode: http.StatusForbidden}}
+	svc := NewGroupMembershipRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileGroupMembershipRule(v1alpha1.GroupMembershipRule{Name: "rule-1", GroupID: testGroupID, MemberIDs: []string{testControlID}})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
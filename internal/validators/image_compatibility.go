@@ -0,0 +1,223 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// Names of the image definition feature and VM size capabilities that determine whether a VM size
+// can run an image, and the values they're compared with.
+const (
+	featureSecurityType                 = "SecurityType"
+	securityTypeTrustedLaunch           = "TrustedLaunch"
+	securityTypeConfidentialVM          = "ConfidentialVM"
+	capabilityHyperVGenerations         = "HyperVGenerations"
+	capabilityCPUArchitectureType       = "CpuArchitectureType"
+	capabilityTrustedLaunchDisabled     = "TrustedLaunchDisabled"
+	capabilityConfidentialComputingType = "ConfidentialComputingType"
+)
+
+// galleryImageAPI contains methods that allow getting a compute gallery image definition by its
+// resource ID.
+type galleryImageAPI interface {
+	GetGalleryImage(imageDefinitionID string) (*armcompute.GalleryImage, error)
+}
+
+// resourceSKUAPI contains methods that allow getting the VM sizes available in a location.
+type resourceSKUAPI interface {
+	ListVirtualMachineSKUs(location string) ([]*armcompute.ResourceSKU, error)
+}
+
+type ImageCompatibilityRuleService struct {
+	log      logr.Logger
+	imageAPI galleryImageAPI
+	skuAPI   resourceSKUAPI
+}
+
+func NewImageCompatibilityRuleService(log logr.Logger, imageAPI galleryImageAPI, skuAPI resourceSKUAPI) *ImageCompatibilityRuleService {
+	return &ImageCompatibilityRuleService{
+		log:      log,
+		imageAPI: imageAPI,
+		skuAPI:   skuAPI,
+	}
+}
+
+// imageRequirements are the capabilities that a VM size needs to run an image.
+type imageRequirements struct {
+	name             string
+	hyperVGeneration string
+	architecture     string
+	// empty unless the image requires trusted launch or a confidential VM
+	securityType string
+}
+
+// ReconcileImageCompatibilityRule reconciles an image compatibility rule from a validation config.
+func (s *ImageCompatibilityRuleService) ReconcileImageCompatibilityRule(rule v1alpha1.ImageCompatibilityRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this image compatibility rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ImageCompatibilitySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeImageCompatibility
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeImageCompatibility, "imageDefinitionID", rule.ImageDefinitionID, "location", rule.Location)
+	l.V(1).Info("Validating image compatibility with VM sizes")
+	ev := &evidence{}
+	if err := s.validateCompatibility(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate image compatibility", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.imageAPI, s.skuAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ImageCompatibilityFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateCompatibility appends a failure for each capability that a VM size lacks to run the
+// image. Image definitions that don't exist and VM sizes that aren't available in the location
+// are failures, not errors.
+func (s *ImageCompatibilityRuleService) validateCompatibility(rule v1alpha1.ImageCompatibilityRule, failures *[]string, ev *evidence) error {
+	id, err := arm.ParseResourceID(rule.ImageDefinitionID)
+	if err != nil {
+		return fmt.Errorf("failed to parse image definition ID: %w", err)
+	}
+	image, err := s.imageAPI.GetGalleryImage(rule.ImageDefinitionID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ImageDefinitionNotFoundFailure, rule.ImageDefinitionID))
+			return nil
+		}
+		return fmt.Errorf("failed to get image definition: %w", azure_errors.AsAugmented(err))
+	}
+	reqs := newImageRequirements(id.Name, image)
+	securityType := reqs.securityType
+	if securityType == "" {
+		securityType = "Standard"
+	}
+	ev.add("Image definition %s has Hyper-V generation %s, architecture %s, and security type %s.", rule.ImageDefinitionID, reqs.hyperVGeneration, reqs.architecture, securityType)
+
+	skus, err := s.skuAPI.ListVirtualMachineSKUs(rule.Location)
+	if err != nil {
+		return fmt.Errorf("failed to list VM sizes: %w", azure_errors.AsAugmented(err))
+	}
+	for _, size := range rule.VMSizes {
+		i := slices.IndexFunc(skus, func(sku *armcompute.ResourceSKU) bool {
+			return sku.Name != nil && strings.EqualFold(*sku.Name, size)
+		})
+		if i < 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.VMSizeUnavailableFailure, size, rule.Location))
+			continue
+		}
+		*failures = append(*failures, reqs.incompatibilities(size, skuCapabilities(skus[i]))...)
+	}
+
+	return nil
+}
+
+// newImageRequirements returns what a VM size needs to run an image definition. Images that don't
+// specify a Hyper-V generation or architecture are Gen1 and x64, like Azure assumes.
+func newImageRequirements(name string, image *armcompute.GalleryImage) imageRequirements {
+	reqs := imageRequirements{
+		name:             name,
+		hyperVGeneration: string(armcompute.HyperVGenerationV1),
+		architecture:     string(armcompute.ArchitectureX64),
+	}
+	props := image.Properties
+	if props == nil {
+		return reqs
+	}
+	if props.HyperVGeneration != nil {
+		reqs.hyperVGeneration = string(*props.HyperVGeneration)
+	}
+	if props.Architecture != nil {
+		reqs.architecture = string(*props.Architecture)
+	}
+	for _, f := range props.Features {
+		if f == nil || f.Name == nil || f.Value == nil || !strings.EqualFold(*f.Name, featureSecurityType) {
+			continue
+		}
+		// Other security types (e.g. TrustedLaunchSupported) mean that the image can run with or
+		// without the security feature, so they don't require anything of the VM size.
+		if strings.EqualFold(*f.Value, securityTypeTrustedLaunch) || strings.EqualFold(*f.Value, securityTypeConfidentialVM) {
+			reqs.securityType = *f.Value
+		}
+	}
+	return reqs
+}
+
+// incompatibilities returns a failure for each capability of a VM size that doesn't meet the
+// image's requirements.
+func (r imageRequirements) incompatibilities(size string, capabilities map[string]string) []string {
+	var failures []string
+	incompatible := func(format string, args ...any) {
+		failures = append(failures, fmt.Sprintf(messages.ImageIncompatibleFailure, r.name, size, fmt.Sprintf(format, args...)))
+	}
+
+	// Sizes without the capability only support Gen1.
+	generations := []string{string(armcompute.HyperVGenerationV1)}
+	if v, ok := capabilities[strings.ToLower(capabilityHyperVGenerations)]; ok {
+		generations = strings.Split(v, ",")
+	}
+	if !slices.ContainsFunc(generations, func(g string) bool { return strings.EqualFold(strings.TrimSpace(g), r.hyperVGeneration) }) {
+		incompatible(messages.HyperVGenerationIncompatibility, r.hyperVGeneration, strings.Join(generations, ", "))
+	}
+
+	architecture := string(armcompute.ArchitectureX64)
+	if v, ok := capabilities[strings.ToLower(capabilityCPUArchitectureType)]; ok {
+		architecture = v
+	}
+	if !strings.EqualFold(architecture, r.architecture) {
+		incompatible(messages.ArchitectureIncompatibility, r.architecture, architecture)
+	}
+
+	switch {
+	case strings.EqualFold(r.securityType, securityTypeTrustedLaunch):
+		if strings.EqualFold(capabilities[strings.ToLower(capabilityTrustedLaunchDisabled)], "true") {
+			incompatible(messages.TrustedLaunchIncompatibility)
+		}
+	case strings.EqualFold(r.securityType, securityTypeConfidentialVM):
+		if capabilities[strings.ToLower(capabilityConfidentialComputingType)] == "" {
+			incompatible(messages.ConfidentialVMIncompatibility)
+		}
+	}
+
+	return failures
+}
+
+// skuCapabilities returns the capabilities of a VM size, keyed by lowercase name.
+func skuCapabilities(sku *armcompute.ResourceSKU) map[string]string {
+	capabilities := map[string]string{}
+	for _, c := range sku.Capabilities {
+		if c != nil && c.Name != nil && c.Value != nil {
+			capabilities[strings.ToLower(*c.Name)] = *c.Value
+		}
+	}
+	return capabilities
+}
@@ -0,0 +1,225 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testImageDefinitionID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/galleries/gallery/images/ubuntu"
+
+// galleryImageAPIMock is a fake ARM with the image definition testImageDefinitionID, if data is
+// set. Getting any other image definition fails with a 404.
+type galleryImageAPIMock struct {
+	data *armcompute.GalleryImage
+}
+
+func (m galleryImageAPIMock) GetGalleryImage(imageDefinitionID string) (*armcompute.GalleryImage, error) {
+	if m.data == nil || imageDefinitionID != testImageDefinitionID {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+// resourceSKUAPIMock is a fake ARM with the VM sizes in data and the disk types in disks, all in
+// eastus, unless err is set.
+type resourceSKUAPIMock struct {
+	data  []*armcompute.ResourceSKU
+	disks []*armcompute.ResourceSKU
+	err   error
+}
+
+func (m resourceSKUAPIMock) ListVirtualMachineSKUs(location string) ([]*armcompute.ResourceSKU, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if location != "eastus" {
+		return nil, nil
+	}
+	return m.data, nil
+}
+
+func (m resourceSKUAPIMock) ListDiskSKUs(location string) ([]*armcompute.ResourceSKU, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if location != "eastus" {
+		return nil, nil
+	}
+	return m.disks, nil
+}
+
+func newGalleryImage(generation armcompute.HyperVGeneration, architecture armcompute.Architecture, securityType string) *armcompute.GalleryImage {
+	image := &armcompute.GalleryImage{
+		Properties: &armcompute.GalleryImageProperties{
+			HyperVGeneration: util.Ptr(generation),
+			Architecture:     util.Ptr(architecture),
+		},
+	}
+	if securityType != "" {
+		image.Properties.Features = []*armcompute.GalleryImageFeature{
+			{Name: util.Ptr("IsAcceleratedNetworkSupported"), Value: util.Ptr("True")},
+			{Name: util.Ptr("SecurityType"), Value: util.Ptr(securityType)},
+		}
+	}
+	return image
+}
+
+func newVMSize(name string, capabilities map[string]string) *armcompute.ResourceSKU {
+	sku := &armcompute.ResourceSKU{Name: util.Ptr(name), ResourceType: util.Ptr("virtualMachines")}
+	for k, v := range capabilities {
+		sku.Capabilities = append(sku.Capabilities, &armcompute.ResourceSKUCapabilities{Name: util.Ptr(k), Value: util.Ptr(v)})
+	}
+	return sku
+}
+
+func TestImageCompatibilityRuleService_ReconcileImageCompatibilityRule(t *testing.T) {
+	// Standard_D2_v2 only supports Gen1 and doesn't support trusted launch, Standard_D4s_v5
+	// supports both generations and trusted launch, Standard_D4ps_v5 is an Arm64 size, and
+	// Standard_DC4as_v5 is a confidential VM size.
+	sizes := []*armcompute.ResourceSKU{
+		newVMSize("Standard_D2_v2", map[string]string{"HyperVGenerations": "V1", "CpuArchitectureType": "x64", "TrustedLaunchDisabled": "True"}),
+		newVMSize("Standard_D4s_v5", map[string]string{"HyperVGenerations": "V1,V2", "CpuArchitectureType": "x64"}),
+		newVMSize("Standard_D4ps_v5", map[string]string{"HyperVGenerations": "V2", "CpuArchitectureType": "Arm64"}),
+		newVMSize("Standard_DC4as_v5", map[string]string{"HyperVGenerations": "V2", "CpuArchitectureType": "x64", "ConfidentialComputingType": "SNP"}),
+		newVMSize("Standard_A1", map[string]string{}),
+	}
+
+	tests := []struct {
+		name         string
+		image        *armcompute.GalleryImage
+		vmSizes      []string
+		location     string
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when all sizes support the image's generation and architecture.",
+			image:        newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, ""),
+			vmSizes:      []string{"Standard_D4s_v5", "standard_dc4as_v5"},
+			wantFailures: []string{},
+		},
+		{
+			name:    "Fails for a Gen2 image and a size that only supports Gen1.",
+			image:   newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, ""),
+			vmSizes: []string{"Standard_D2_v2", "Standard_D4s_v5"},
+			wantFailures: []string{
+				"Image ubuntu is incompatible with VM size Standard_D2_v2: the image's Hyper-V generation is V2, but the size only supports V1.",
+			},
+		},
+		{
+			name:    "Treats sizes without Hyper-V generations or CPU architecture as Gen1 and x64.",
+			image:   newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureArm64, ""),
+			vmSizes: []string{"Standard_A1"},
+			wantFailures: []string{
+				"Image ubuntu is incompatible with VM size Standard_A1: the image's Hyper-V generation is V2, but the size only supports V1.",
+				"Image ubuntu is incompatible with VM size Standard_A1: the image's architecture is Arm64, but the size's CPU architecture is x64.",
+			},
+		},
+		{
+			name:    "Fails for each size whose CPU architecture differs.",
+			image:   newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureArm64, ""),
+			vmSizes: []string{"Standard_D4ps_v5", "Standard_D4s_v5"},
+			wantFailures: []string{
+				"Image ubuntu is incompatible with VM size Standard_D4s_v5: the image's architecture is Arm64, but the size's CPU architecture is x64.",
+			},
+		},
+		{
+			name:    "Fails for a trusted launch image and a size that doesn't support trusted launch.",
+			image:   newGalleryImage(armcompute.HyperVGenerationV1, armcompute.ArchitectureX64, "TrustedLaunch"),
+			vmSizes: []string{"Standard_D2_v2", "Standard_D4s_v5"},
+			wantFailures: []string{
+				"Image ubuntu is incompatible with VM size Standard_D2_v2: the image requires trusted launch, but the size doesn't support it.",
+			},
+		},
+		{
+			name:         "Doesn't require trusted launch of images that merely support it.",
+			image:        newGalleryImage(armcompute.HyperVGenerationV1, armcompute.ArchitectureX64, "TrustedLaunchSupported"),
+			vmSizes:      []string{"Standard_D2_v2"},
+			wantFailures: []string{},
+		},
+		{
+			name:    "Fails for a confidential VM image and a size without confidential computing.",
+			image:   newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, "ConfidentialVM"),
+			vmSizes: []string{"Standard_DC4as_v5", "Standard_D4s_v5"},
+			wantFailures: []string{
+				"Image ubuntu is incompatible with VM size Standard_D4s_v5: the image requires a confidential VM, but the size doesn't support confidential computing.",
+			},
+		},
+		{
+			name:         "Treats images without a generation or architecture as Gen1 and x64.",
+			image:        &armcompute.GalleryImage{Properties: &armcompute.GalleryImageProperties{}},
+			vmSizes:      []string{"Standard_D2_v2", "Standard_A1"},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails for a size that isn't available in the location.",
+			image:        newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, ""),
+			vmSizes:      []string{"Standard_D4s_v5"},
+			location:     "westus",
+			wantFailures: []string{"VM size Standard_D4s_v5 is not available in location westus."},
+		},
+		{
+			name:         "Fails when the image definition doesn't exist.",
+			vmSizes:      []string{"Standard_D4s_v5"},
+			wantFailures: []string{"Image definition " + testImageDefinitionID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewImageCompatibilityRuleService(logr.Discard(), galleryImageAPIMock{data: tt.image}, resourceSKUAPIMock{data: sizes})
+
+			location := "eastus"
+			if tt.location != "" {
+				location = tt.location
+			}
+			result, err := svc.ReconcileImageCompatibilityRule(v1alpha1.ImageCompatibilityRule{
+				Name:              "rule-1",
+				ImageDefinitionID: testImageDefinitionID,
+				Location:          location,
+				VMSizes:           tt.vmSizes,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestImageCompatibilityRuleService_ReconcileImageCompatibilityRule_Error(t *testing.T) {
+	imageAPI := galleryImageAPIMock{data: newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, "")}
+	skuAPI := resourceSKUAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewImageCompatibilityRuleService(logr.Discard(), imageAPI, skuAPI)
+
+	result, err := svc.ReconcileImageCompatibilityRule(v1alpha1.ImageCompatibilityRule{
+		Name:              "rule-1",
+		ImageDefinitionID: testImageDefinitionID,
+		Location:          "eastus",
+		VMSizes:           []string{"Standard_D4s_v5"},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
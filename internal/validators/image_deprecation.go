@@ -0,0 +1,171 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/versions"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// vmImageAPI contains methods that allow getting a version of a platform image, and listing the
+// versions of a platform image SKU.
+type vmImageAPI interface {
+	GetVirtualMachineImage(location, publisher, offer, sku, version string) (*armcompute.VirtualMachineImage, error)
+	ListVirtualMachineImageVersions(location, publisher, offer, sku string) ([]*armcompute.VirtualMachineImageResource, error)
+}
+
+type ImageDeprecationRuleService struct {
+	log   logr.Logger
+	api   vmImageAPI
+	clock clock.PassiveClock
+}
+
+// NewImageDeprecationRuleService creates an ImageDeprecationRuleService that gets images with
+// api. How soon images are scheduled for deprecation is measured from the current time of clock.
+func NewImageDeprecationRuleService(log logr.Logger, api vmImageAPI, clock clock.PassiveClock) *ImageDeprecationRuleService {
+	return &ImageDeprecationRuleService{
+		log:   log,
+		api:   api,
+		clock: clock,
+	}
+}
+
+// ReconcileImageDeprecationRule reconciles an image deprecation rule from a validation config.
+func (s *ImageDeprecationRuleService) ReconcileImageDeprecationRule(rule v1alpha1.ImageDeprecationRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this image deprecation rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ImageDeprecationSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeImageDeprecation
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeImageDeprecation, "location", rule.Location)
+	l.V(1).Info("Validating image deprecation status")
+	now := s.clock.Now()
+	ev := &evidence{}
+	for _, urn := range rule.ImageURNs {
+		if err := s.validateImage(rule, string(urn), now, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l.WithValues("imageUrn", urn), "failed to validate image", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ImageDeprecationFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateImage appends a failure if an image doesn't exist in the rule's location, is
+// deprecated, or is scheduled for deprecation within the rule's horizon.
+func (s *ImageDeprecationRuleService) validateImage(rule v1alpha1.ImageDeprecationRule, urn string, now time.Time, failures *[]string, ev *evidence) error {
+	parts := strings.Split(urn, ":")
+	if len(parts) != 4 {
+		*failures = append(*failures, fmt.Sprintf(messages.ImageURNFailure, urn))
+		return nil
+	}
+	publisher, offer, sku, version := parts[0], parts[1], parts[2], parts[3]
+
+	constraint, err := versions.ParseConstraint(version)
+	if err != nil {
+		*failures = append(*failures, fmt.Sprintf(messages.ImageVersionConstraintFailure, version, urn, err))
+		return nil
+	}
+	if _, exact := constraint.Exact(); !exact {
+		published, err := s.api.ListVirtualMachineImageVersions(rule.Location, publisher, offer, sku)
+		if err != nil {
+			var rerr *azcore.ResponseError
+			if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusNotFound {
+				return fmt.Errorf("failed to list image versions: %w", azure_errors.AsAugmented(err))
+			}
+		}
+		candidates := []string{}
+		for _, v := range published {
+			if v != nil && v.Name != nil {
+				candidates = append(candidates, *v.Name)
+			}
+		}
+		resolved, ok := constraint.Resolve(candidates)
+		if !ok {
+			if len(candidates) == 0 {
+				*failures = append(*failures, fmt.Sprintf(messages.ImageNoVersionsFailure, urn, rule.Location))
+			} else {
+				*failures = append(*failures, fmt.Sprintf(messages.ImageVersionUnsatisfiedFailure, urn, rule.Location, constraint))
+			}
+			return nil
+		}
+		version = resolved
+		ev.add("Image %s resolved to version %s in location %s.", urn, version, rule.Location)
+		urn = strings.Join([]string{publisher, offer, sku, version}, ":")
+	}
+
+	image, err := s.api.GetVirtualMachineImage(rule.Location, publisher, offer, sku, version)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ImageNotFoundFailure, urn, rule.Location))
+			return nil
+		}
+		return fmt.Errorf("failed to get image: %w", azure_errors.AsAugmented(err))
+	}
+	var status *armcompute.ImageDeprecationStatus
+	if image.Properties != nil {
+		status = image.Properties.ImageDeprecationStatus
+	}
+	if status == nil || status.ImageState == nil || *status.ImageState == armcompute.ImageStateActive {
+		ev.add("Image %s is active.", urn)
+		return nil
+	}
+
+	scheduled := messages.DeprecationDateUnknown
+	if status.ScheduledDeprecationTime != nil {
+		scheduled = status.ScheduledDeprecationTime.Format(time.RFC3339)
+	}
+	switch *status.ImageState {
+	case armcompute.ImageStateDeprecated:
+		ev.add("Image %s was deprecated at %s.", urn, scheduled)
+		*failures = append(*failures, fmt.Sprintf(messages.ImageDeprecatedFailure, urn, scheduled, alternativeImage(status)))
+	case armcompute.ImageStateScheduledForDeprecation:
+		ev.add("Image %s is scheduled for deprecation at %s.", urn, scheduled)
+		if rule.DeprecationHorizon != nil && status.ScheduledDeprecationTime != nil &&
+			status.ScheduledDeprecationTime.Sub(now) > rule.DeprecationHorizon.Duration {
+			return nil
+		}
+		*failures = append(*failures, fmt.Sprintf(messages.ImageDeprecationScheduledFailure, urn, scheduled, alternativeImage(status)))
+	}
+	return nil
+}
+
+// alternativeImage returns a sentence naming the alternative that an image's publisher
+// recommends, or an empty string if there's none.
+func alternativeImage(status *armcompute.ImageDeprecationStatus) string {
+	alt := status.AlternativeOption
+	if alt == nil || alt.Type == nil || alt.Value == nil || *alt.Type == armcompute.AlternativeTypeNone {
+		return ""
+	}
+	return " " + fmt.Sprintf(messages.ImageAlternative, strings.ToLower(string(*alt.Type)), *alt.Value)
+}
@@ -0,0 +1,191 @@
+package validators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// vmImageAPIMock is a fake ARM with the platform images in its map, keyed by URN. Getting any
+// other image fails with a 404, unless err is set. Each image SKU's versions are those of the
+// images in the map.
+type vmImageAPIMock struct {
+	images map[string]*armcompute.VirtualMachineImage
+	err    error
+}
+
+func (m vmImageAPIMock) GetVirtualMachineImage(_, publisher, offer, sku, version string) (*armcompute.VirtualMachineImage, error) {
+	return getOrNotFound(m.images, strings.Join([]string{publisher, offer, sku, version}, ":"))
+}
+
+func (m vmImageAPIMock) ListVirtualMachineImageVersions(_, publisher, offer, sku string) ([]*armcompute.VirtualMachineImageResource, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	prefix := strings.Join([]string{publisher, offer, sku}, ":") + ":"
+	var versions []*armcompute.VirtualMachineImageResource
+	for urn := range m.images {
+		if strings.HasPrefix(urn, prefix) {
+			versions = append(versions, &armcompute.VirtualMachineImageResource{Name: util.Ptr(strings.TrimPrefix(urn, prefix))})
+		}
+	}
+	return versions, nil
+}
+
+// imagePayload unmarshals a platform image as ARM returns it.
+func imagePayload(t *testing.T, payload string) *armcompute.VirtualMachineImage {
+	t.Helper()
+	image := &armcompute.VirtualMachineImage{}
+	if err := json.Unmarshal([]byte(payload), image); err != nil {
+		t.Fatalf("failed to unmarshal image: %v", err)
+	}
+	return image
+}
+
+func TestImageDeprecationRuleService_ReconcileImageDeprecationRule(t *testing.T) {
+	const jammy = "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2"
+	const focal = "Canonical:0001-com-ubuntu-server-focal:20_04-lts-gen2"
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	api := vmImageAPIMock{images: map[string]*armcompute.VirtualMachineImage{
+		jammy + ":22.04.202309010": imagePayload(t, `{"name": "22.04.202309010", "properties": {"imageDeprecationStatus": {
+			"imageState": "Deprecated",
+			"scheduledDeprecationTime": "2023-12-01T00:00:00Z"
+		}}}`),
+		jammy + ":22.04.202311010": imagePayload(t, `{"name": "22.04.202311010", "properties": {"imageDeprecationStatus": {
+			"imageState": "ScheduledForDeprecation",
+			"scheduledDeprecationTime": "2024-02-01T00:00:00Z"
+		}}}`),
+		jammy + ":22.04.202312010": imagePayload(t, `{"name": "22.04.202312010", "properties": {"imageDeprecationStatus": {
+			"imageState": "Active"
+		}}}`),
+		focal + ":20.04.202312010": imagePayload(t, `{"name": "20.04.202312010", "properties": {"imageDeprecationStatus": {
+			"imageState": "ScheduledForDeprecation",
+			"scheduledDeprecationTime": "2024-06-01T00:00:00Z",
+			"alternativeOption": {"type": "Offer", "value": "0001-com-ubuntu-server-jammy"}
+		}}}`),
+		focal + ":20.04.202309010": imagePayload(t, `{"name": "20.04.202309010", "properties": {}}`),
+	}}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.ImageDeprecationRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the latest version of the image is active.",
+			rule:         v1alpha1.ImageDeprecationRule{ImageURNs: []v1alpha1.PlatformImageURN{jammy + ":latest"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for deprecated images and images scheduled for deprecation.",
+			rule: v1alpha1.ImageDeprecationRule{ImageURNs: []v1alpha1.PlatformImageURN{jammy + ":22.04.202309010", jammy + ":22.04.202311010"}},
+			wantFailures: []string{
+				"Image " + jammy + ":22.04.202309010 is deprecated since 2023-12-01T00:00:00Z.",
+				"Image " + jammy + ":22.04.202311010 is scheduled for deprecation at 2024-02-01T00:00:00Z.",
+			},
+		},
+		{
+			name: "Fails only for images scheduled for deprecation within the horizon.",
+			rule: v1alpha1.ImageDeprecationRule{
+				ImageURNs:          []v1alpha1.PlatformImageURN{jammy + ":22.04.202311010", focal + ":latest"},
+				DeprecationHorizon: &metav1.Duration{Duration: 90 * 24 * time.Hour},
+			},
+			wantFailures: []string{
+				"Image " + jammy + ":22.04.202311010 is scheduled for deprecation at 2024-02-01T00:00:00Z.",
+			},
+		},
+		{
+			name: "Names the publisher's alternative to the latest version of an image scheduled for deprecation within the horizon.",
+			rule: v1alpha1.ImageDeprecationRule{
+				ImageURNs:          []v1alpha1.PlatformImageURN{focal + ":latest"},
+				DeprecationHorizon: &metav1.Duration{Duration: 180 * 24 * time.Hour},
+			},
+			wantFailures: []string{
+				"Image " + focal + ":20.04.202312010 is scheduled for deprecation at 2024-06-01T00:00:00Z. Its publisher recommends offer 0001-com-ubuntu-server-jammy instead.",
+			},
+		},
+		{
+			name:         "Passes when the highest version in the range is active.",
+			rule:         v1alpha1.ImageDeprecationRule{ImageURNs: []v1alpha1.PlatformImageURN{jammy + ":>=22.04.202311010 <22.04.202401010"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when the highest version in the range is scheduled for deprecation.",
+			rule: v1alpha1.ImageDeprecationRule{ImageURNs: []v1alpha1.PlatformImageURN{jammy + ":>=22.04.202309010 <22.04.202312010"}},
+			wantFailures: []string{
+				"Image " + jammy + ":22.04.202311010 is scheduled for deprecation at 2024-02-01T00:00:00Z.",
+			},
+		},
+		{
+			name: "Fails when no version satisfies the range.",
+			rule: v1alpha1.ImageDeprecationRule{ImageURNs: []v1alpha1.PlatformImageURN{jammy + ":>=22.04.202401010"}},
+			wantFailures: []string{
+				"No version of image " + jammy + ":>=22.04.202401010 in location eastus satisfies >=22.04.202401010.",
+			},
+		},
+		{
+			name: "Fails when the image or its SKU is missing.",
+			rule: v1alpha1.ImageDeprecationRule{ImageURNs: []v1alpha1.PlatformImageURN{jammy + ":22.04.202201010", "Canonical:UbuntuServer:18.04-LTS:latest"}},
+			wantFailures: []string{
+				"Image " + jammy + ":22.04.202201010 not found in location eastus.",
+				"Image Canonical:UbuntuServer:18.04-LTS:latest has no versions in location eastus.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewImageDeprecationRuleService(logr.Discard(), api, clocktesting.NewFakePassiveClock(now))
+
+			tt.rule.Name = "rule-1"
+			tt.rule.SubscriptionID = testSubscriptionID
+			tt.rule.Location = "eastus"
+			result, err := svc.ReconcileImageDeprecationRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestImageDeprecationRuleService_ReconcileImageDeprecationRule_Error(t *testing.T) {
+	api := vmImageAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewImageDeprecationRuleService(logr.Discard(), api, clocktesting.NewFakePassiveClock(time.Now()))
+
+	result, err := svc.ReconcileImageDeprecationRule(v1alpha1.ImageDeprecationRule{
+		Name:           "rule-1",
+		SubscriptionID: testSubscriptionID,
+		Location:       "eastus",
+		ImageURNs:      []v1alpha1.PlatformImageURN{"Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest"},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,228 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/versions"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// galleryImageVersionAPI contains methods that allow getting a compute gallery image version, with
+// its replication status, by its resource ID, and listing the versions of an image definition.
+type galleryImageVersionAPI interface {
+	GetGalleryImageVersion(imageVersionID string) (*armcompute.GalleryImageVersion, error)
+	ListGalleryImageVersions(imageDefinitionID string) ([]*armcompute.GalleryImageVersion, error)
+}
+
+type ImageReplicationRuleService struct {
+	log        logr.Logger
+	versionAPI galleryImageVersionAPI
+}
+
+func NewImageReplicationRuleService(log logr.Logger, versionAPI galleryImageVersionAPI) *ImageReplicationRuleService {
+	return &ImageReplicationRuleService{
+		log:        log,
+		versionAPI: versionAPI,
+	}
+}
+
+// ReconcileImageReplicationRule reconciles an image replication rule from a validation config.
+func (s *ImageReplicationRuleService) ReconcileImageReplicationRule(rule v1alpha1.ImageReplicationRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this image replication rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ImageReplicationSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeImageReplication
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeImageReplication, "imageVersionID", rule.ImageVersionID)
+	l.V(1).Info("Validating image version replication")
+	ev := &evidence{}
+	if err := s.validateReplication(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate image version replication", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.versionAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ImageReplicationFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateReplication appends a failure for each region of a rule that isn't a target region of
+// the image version, has too few replicas, or hasn't completed replication. Image versions that
+// don't exist are failures, not errors.
+func (s *ImageReplicationRuleService) validateReplication(rule v1alpha1.ImageReplicationRule, failures *[]string, ev *evidence) error {
+	imageVersionID, err := s.resolveImageVersion(rule.ImageVersionID, failures, ev)
+	if err != nil || imageVersionID == "" {
+		return err
+	}
+	version, err := s.versionAPI.GetGalleryImageVersion(imageVersionID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ImageVersionNotFoundFailure, imageVersionID))
+			return nil
+		}
+		return fmt.Errorf("failed to get image version: %w", azure_errors.AsAugmented(err))
+	}
+
+	var profile *armcompute.GalleryImageVersionPublishingProfile
+	var statuses []*armcompute.RegionalReplicationStatus
+	if version.Properties != nil {
+		profile = version.Properties.PublishingProfile
+		if version.Properties.ReplicationStatus != nil {
+			statuses = version.Properties.ReplicationStatus.Summary
+		}
+	}
+	targets := map[string]*armcompute.TargetRegion{}
+	var targetNames []string
+	if profile != nil {
+		for _, target := range profile.TargetRegions {
+			if target != nil && target.Name != nil {
+				targets[normalizeRegion(*target.Name)] = target
+				targetNames = append(targetNames, *target.Name)
+			}
+		}
+	}
+	replication := map[string]*armcompute.RegionalReplicationStatus{}
+	for _, status := range statuses {
+		if status != nil && status.Region != nil {
+			replication[normalizeRegion(*status.Region)] = status
+		}
+	}
+	ev.add("Image version %s has target regions %s.", imageVersionID, joinRegions(targetNames))
+
+	for _, region := range rule.Regions {
+		target, ok := targets[normalizeRegion(region)]
+		if !ok {
+			*failures = append(*failures, fmt.Sprintf(messages.ImageTargetRegionFailure, region, imageVersionID))
+			continue
+		}
+		replicas := replicaCount(profile, target)
+		if rule.MinReplicas > 0 && replicas < rule.MinReplicas {
+			*failures = append(*failures, fmt.Sprintf(messages.ImageReplicaCountFailure, imageVersionID, replicas, region, rule.MinReplicas))
+		}
+
+		status := replication[normalizeRegion(region)]
+		switch {
+		case status == nil || status.State == nil:
+			*failures = append(*failures, fmt.Sprintf(messages.ImageReplicationUnknownFailure, imageVersionID, region))
+		case *status.State != armcompute.ReplicationStateCompleted:
+			*failures = append(*failures, replicationFailure(imageVersionID, region, status))
+		default:
+			ev.add("Image version %s is replicated to region %s, with %d replicas.", imageVersionID, region, replicas)
+		}
+	}
+	return nil
+}
+
+// resolveImageVersion returns the resource ID of the image version whose version satisfies the
+// version of imageVersionID, which is an exact version, latest, or a version range. Latest and
+// ranges resolve to the highest version of the image definition that satisfies them and isn't
+// excluded from latest. If none does, it appends a failure and returns an empty string.
+func (s *ImageReplicationRuleService) resolveImageVersion(imageVersionID string, failures *[]string, ev *evidence) (string, error) {
+	i := strings.LastIndex(imageVersionID, "/versions/")
+	if i < 0 {
+		return imageVersionID, nil
+	}
+	imageDefinitionID, version := imageVersionID[:i], imageVersionID[i+len("/versions/"):]
+	constraint, err := versions.ParseConstraint(version)
+	if err != nil {
+		*failures = append(*failures, fmt.Sprintf(messages.ImageDefinitionVersionConstraintFailure, version, imageDefinitionID, err))
+		return "", nil
+	}
+	if _, exact := constraint.Exact(); exact {
+		return imageVersionID, nil
+	}
+
+	published, err := s.versionAPI.ListGalleryImageVersions(imageDefinitionID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ImageDefinitionNotFoundFailure, imageDefinitionID))
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to list image versions: %w", azure_errors.AsAugmented(err))
+	}
+	candidates := []string{}
+	for _, v := range published {
+		if v == nil || v.Name == nil {
+			continue
+		}
+		if v.Properties != nil && v.Properties.PublishingProfile != nil && v.Properties.PublishingProfile.ExcludeFromLatest != nil && *v.Properties.PublishingProfile.ExcludeFromLatest {
+			continue
+		}
+		candidates = append(candidates, *v.Name)
+	}
+	resolved, ok := constraint.Resolve(candidates)
+	if !ok {
+		*failures = append(*failures, fmt.Sprintf(messages.ImageDefinitionVersionUnsatisfiedFailure,
+			imageDefinitionID, constraint, len(published), len(candidates), joinOrNone(candidates)))
+		return "", nil
+	}
+	ev.add("Version %s of image definition %s resolved to version %s.", constraint, imageDefinitionID, resolved)
+	return imageDefinitionID + "/versions/" + resolved, nil
+}
+
+// replicaCount returns the number of replicas of an image version in a target region, which
+// defaults to the version's replica count, or 1 if that isn't set either.
+func replicaCount(profile *armcompute.GalleryImageVersionPublishingProfile, target *armcompute.TargetRegion) int {
+	switch {
+	case target.RegionalReplicaCount != nil:
+		return int(*target.RegionalReplicaCount)
+	case profile.ReplicaCount != nil:
+		return int(*profile.ReplicaCount)
+	default:
+		return 1
+	}
+}
+
+// replicationFailure describes the replication of an image version to a region that hasn't
+// completed, with its progress and details if ARM reports them.
+func replicationFailure(imageVersionID, region string, status *armcompute.RegionalReplicationStatus) string {
+	parts := []string{fmt.Sprintf(messages.ReplicationState, *status.State)}
+	if status.Progress != nil {
+		parts = append(parts, fmt.Sprintf(messages.ReplicationProgress, *status.Progress))
+	}
+	if status.Details != nil && *status.Details != "" {
+		parts = append(parts, fmt.Sprintf(messages.ReplicationDetails, strings.TrimSuffix(*status.Details, ".")))
+	}
+	return fmt.Sprintf(messages.ImageNotReplicatedFailure, imageVersionID, region, strings.Join(parts, ", "))
+}
+
+// normalizeRegion returns the name of a region (e.g. eastus) given either its name or its display
+// name (e.g. East US), which ARM uses for the target regions of image versions.
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
+
+// joinRegions formats a list of regions for a condition.
+func joinRegions(regions []string) string {
+	if len(regions) == 0 {
+		return "none"
+	}
+	return strings.Join(regions, ", ")
+}
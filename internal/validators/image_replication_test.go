@@ -0,0 +1,289 @@
+package validators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+const (
+	testReplicatedImageID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/galleries/gallery/images/ubuntu-2204"
+	testImageVersionID    = testReplicatedImageID + "/versions/1.0.0"
+)
+
+// testImageVersion is an image version as ARM returns it with $expand=ReplicationStatus. It's
+// replicated to East US with 2 replicas and to West Europe with the default of 1, and is still
+// being replicated to West US 2.
+const testImageVersion = `{
+	"id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/galleries/gallery/images/ubuntu-2204/versions/1.0.0",
+	"name": "1.0.0",
+	"location": "eastus",
+	"properties": {
+		"publishingProfile": {
+			"replicaCount": 1,
+			"targetRegions": [
+				{"name": "East US", "regionalReplicaCount": 2, "storageAccountType": "Standard_LRS"},
+				{"name": "West Europe", "storageAccountType": "Standard_LRS"},
+				{"name": "West US 2", "regionalReplicaCount": 1, "storageAccountType": "Standard_ZRS"}
+			]
+		},
+		"provisioningState": "Updating",
+		"replicationStatus": {
+			"aggregatedState": "InProgress",
+			"summary": [
+				{"region": "East US", "state": "Completed", "details": "", "progress": 100},
+				{"region": "West Europe", "state": "Completed", "details": "", "progress": 100},
+				{"region": "West US 2", "state": "Replicating", "details": "", "progress": 42}
+			]
+		}
+	}
+}`
+
+// galleryImageVersionAPIMock is a fake ARM with the image version testImageVersionID, if data is
+// set, unless err is set. Getting any other image version fails with a 404. The versions of the
+// image definition of testImageVersionID are listed from published, if it's set.
+type galleryImageVersionAPIMock struct {
+	data      *armcompute.GalleryImageVersion
+	published []*armcompute.GalleryImageVersion
+	err       error
+}
+
+func (m galleryImageVersionAPIMock) GetGalleryImageVersion(imageVersionID string) (*armcompute.GalleryImageVersion, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.data == nil || imageVersionID != testImageVersionID {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+func (m galleryImageVersionAPIMock) ListGalleryImageVersions(imageDefinitionID string) ([]*armcompute.GalleryImageVersion, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.published == nil || imageDefinitionID != testReplicatedImageID {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.published, nil
+}
+
+func parseImageVersion(t *testing.T, payload string) *armcompute.GalleryImageVersion {
+	t.Helper()
+	version := &armcompute.GalleryImageVersion{}
+	if err := json.Unmarshal([]byte(payload), version); err != nil {
+		t.Fatalf("failed to parse image version: %v", err)
+	}
+	return version
+}
+
+func TestImageReplicationRuleService_ReconcileImageReplicationRule(t *testing.T) {
+	version := parseImageVersion(t, testImageVersion)
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.ImageReplicationRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes for regions that replication has completed in, by name or display name.",
+			rule:         v1alpha1.ImageReplicationRule{Regions: []string{"eastus", "West Europe"}},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes for regions with enough replicas.",
+			rule:         v1alpha1.ImageReplicationRule{Regions: []string{"eastus"}, MinReplicas: 2},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for a region that replication is in progress in.",
+			rule: v1alpha1.ImageReplicationRule{Regions: []string{"eastus", "westus2"}},
+			wantFailures: []string{
+				"Image version " + testImageVersionID + " isn't replicated to region westus2: replication state Replicating, progress 42%.",
+			},
+		},
+		{
+			name: "Fails for regions with too few replicas, including the default replica count.",
+			rule: v1alpha1.ImageReplicationRule{Regions: []string{"eastus", "westeurope"}, MinReplicas: 2},
+			wantFailures: []string{
+				"Image version " + testImageVersionID + " has 1 replicas in region westeurope, fewer than 2.",
+			},
+		},
+		{
+			name: "Fails for a region that isn't a target region.",
+			rule: v1alpha1.ImageReplicationRule{Regions: []string{"centralus"}},
+			wantFailures: []string{
+				"Region centralus isn't a target region of image version " + testImageVersionID + ".",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewImageReplicationRuleService(logr.Discard(), galleryImageVersionAPIMock{data: version})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.ImageVersionID = testImageVersionID
+			result, err := svc.ReconcileImageReplicationRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+
+	t.Run("Fails with the details of a failed replication, and for a region without replication status.", func(t *testing.T) {
+		version := parseImageVersion(t, `{
+			"properties": {
+				"publishingProfile": {
+					"targetRegions": [{"name": "East US"}, {"name": "West US 2"}]
+				},
+				"replicationStatus": {
+					"aggregatedState": "Failed",
+					"summary": [{"region": "East US", "state": "Failed", "details": "The storage account quota was exceeded.", "progress": 0}]
+				}
+			}
+		}`)
+		svc := NewImageReplicationRuleService(logr.Discard(), galleryImageVersionAPIMock{data: version})
+		result, err := svc.ReconcileImageReplicationRule(v1alpha1.ImageReplicationRule{Name: "rule-1", ImageVersionID: testImageVersionID, Regions: []string{"eastus", "westus2"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{
+			"Image version " + testImageVersionID + " isn't replicated to region eastus: replication state Failed, progress 0%, details: The storage account quota was exceeded.",
+			"Replication status of image version " + testImageVersionID + " in region westus2 is unknown.",
+		}
+		if !reflect.DeepEqual(result.Condition.Failures, want) {
+			t.Errorf("got failures %v, want %v", result.Condition.Failures, want)
+		}
+	})
+
+	t.Run("Fails for an image version that doesn't exist.", func(t *testing.T) {
+		svc := NewImageReplicationRuleService(logr.Discard(), galleryImageVersionAPIMock{})
+		result, err := svc.ReconcileImageReplicationRule(v1alpha1.ImageReplicationRule{Name: "rule-1", ImageVersionID: testImageVersionID, Regions: []string{"eastus"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"Image version " + testImageVersionID + " not found."}; !reflect.DeepEqual(result.Condition.Failures, want) {
+			t.Errorf("got failures %v, want %v", result.Condition.Failures, want)
+		}
+	})
+}
+
+func TestImageReplicationRuleService_ReconcileImageReplicationRule_VersionConstraint(t *testing.T) {
+	version := parseImageVersion(t, testImageVersion)
+	published := []*armcompute.GalleryImageVersion{
+		parseImageVersion(t, `{"name": "0.9.0", "properties": {"publishingProfile": {}}}`),
+		parseImageVersion(t, `{"name": "1.0.0", "properties": {"publishingProfile": {"excludeFromLatest": false}}}`),
+		parseImageVersion(t, `{"name": "1.1.0", "properties": {"publishingProfile": {"excludeFromLatest": true}}}`),
+		parseImageVersion(t, `{"name": "2.0.0-rc.1", "properties": {"publishingProfile": {}}}`),
+	}
+
+	tests := []struct {
+		name         string
+		version      string
+		published    []*armcompute.GalleryImageVersion
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name:         "Latest resolves to the highest version that isn't excluded from latest.",
+			version:      "latest",
+			published:    published,
+			wantFailures: []string{},
+			wantDetails: []string{
+				"Version latest of image definition " + testReplicatedImageID + " resolved to version 1.0.0.",
+				"Image version " + testImageVersionID + " has target regions East US, West Europe, West US 2.",
+				"Image version " + testImageVersionID + " is replicated to region eastus, with 2 replicas.",
+			},
+		},
+		{
+			name:         "A range resolves to the highest version that satisfies it and isn't excluded from latest.",
+			version:      ">=0.9.0 <2.0.0",
+			published:    published,
+			wantFailures: []string{},
+			wantDetails: []string{
+				"Version >=0.9.0 <2.0.0 of image definition " + testReplicatedImageID + " resolved to version 1.0.0.",
+				"Image version " + testImageVersionID + " has target regions East US, West Europe, West US 2.",
+				"Image version " + testImageVersionID + " is replicated to region eastus, with 2 replicas.",
+			},
+		},
+		{
+			name:      "Fails when no version that isn't excluded from latest satisfies the range.",
+			version:   ">=1.1.0",
+			published: published,
+			wantFailures: []string{
+				"No version of image definition " + testReplicatedImageID + " satisfies >=1.1.0: of its 4 versions, 3 aren't excluded from latest: 0.9.0, 1.0.0, 2.0.0-rc.1.",
+			},
+		},
+		{
+			name:    "Fails when the image definition is missing.",
+			version: "latest",
+			wantFailures: []string{
+				"Image definition " + testReplicatedImageID + " not found.",
+			},
+		},
+		{
+			name:    "Fails when the version isn't a version, a range, or latest.",
+			version: "~1.0",
+			wantFailures: []string{
+				`Version "~1.0" of image definition ` + testReplicatedImageID + ` isn't a version, a version range, or latest: invalid version "~1.0": parts must be non-negative numbers.`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewImageReplicationRuleService(logr.Discard(), galleryImageVersionAPIMock{data: version, published: tt.published})
+
+			result, err := svc.ReconcileImageReplicationRule(v1alpha1.ImageReplicationRule{
+				Name:           "rule-1",
+				ImageVersionID: testReplicatedImageID + "/versions/" + tt.version,
+				Regions:        []string{"eastus"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if tt.wantDetails != nil && !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestImageReplicationRuleService_ReconcileImageReplicationRule_Error(t *testing.T) {
+	versionAPI := galleryImageVersionAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewImageReplicationRuleService(logr.Discard(), versionAPI)
+
+	result, err := svc.ReconcileImageReplicationRule(v1alpha1.ImageReplicationRule{
+		Name:           "rule-1",
+		ImageVersionID: testImageVersionID,
+		Regions:        []string{"eastus"},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
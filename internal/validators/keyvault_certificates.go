@@ -0,0 +1,161 @@
+package validators
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azcertificates"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// certificateAPI contains methods that allow getting the latest version of a certificate in a Key
+// Vault.
+type certificateAPI interface {
+	GetCertificate(name string) (*azcertificates.Certificate, error)
+}
+
+type KeyVaultCertificateRuleService struct {
+	log   logr.Logger
+	api   certificateAPI
+	clock clock.PassiveClock
+}
+
+// NewKeyVaultCertificateRuleService creates a KeyVaultCertificateRuleService that gets certificates
+// with api. Certificates' remaining validity is measured from the current time of clock.
+func NewKeyVaultCertificateRuleService(log logr.Logger, api certificateAPI, clock clock.PassiveClock) *KeyVaultCertificateRuleService {
+	return &KeyVaultCertificateRuleService{
+		log:   log,
+		api:   api,
+		clock: clock,
+	}
+}
+
+// ReconcileKeyVaultCertificateRule reconciles a Key Vault certificate rule from a validation config.
+func (s *KeyVaultCertificateRuleService) ReconcileKeyVaultCertificateRule(rule v1alpha1.KeyVaultCertificateRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this certificate rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.KeyVaultCertificateSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeKeyVaultCertificate
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeKeyVaultCertificate, "vaultURI", rule.VaultURI)
+	ev := &evidence{}
+	now := s.clock.Now()
+	for _, name := range rule.Certificates {
+		cl := l.WithValues("certificate", name)
+		cl.V(1).Info("Validating certificate")
+		if err := s.validateCertificate(rule, name, now, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(cl, "failed to validate certificate", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.KeyVaultCertificateFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateCertificate validates a single certificate of a rule, appending a failure for each
+// requirement it doesn't meet. A certificate that doesn't exist is a failure, not an error.
+func (s *KeyVaultCertificateRuleService) validateCertificate(rule v1alpha1.KeyVaultCertificateRule, name string, now time.Time, failures *[]string, ev *evidence) error {
+	cert, err := s.api.GetCertificate(name)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.CertificateNotFoundFailure, name, rule.VaultURI))
+			return nil
+		}
+		return fmt.Errorf("failed to get certificate %s: %w", name, azure_errors.AsAugmented(err))
+	}
+
+	version := "(unknown version)"
+	if cert.ID != nil {
+		version = cert.ID.Version()
+	}
+
+	if cert.Attributes != nil && cert.Attributes.Enabled != nil && !*cert.Attributes.Enabled {
+		*failures = append(*failures, fmt.Sprintf(messages.CertificateDisabledFailure, name))
+	}
+
+	if cert.Attributes == nil || cert.Attributes.Expires == nil {
+		ev.add("Certificate %s (version %s) has no expiry.", name, version)
+	} else {
+		expires := cert.Attributes.Expires.UTC()
+		ev.add("Certificate %s (version %s) expires at %s.", name, version, expires.Format(time.RFC3339))
+		switch remaining := expires.Sub(now); {
+		case remaining <= 0:
+			*failures = append(*failures, fmt.Sprintf(messages.CertificateExpiredFailure, name, expires.Format(time.RFC3339)))
+		case remaining < rule.MinRemainingValidity.Duration:
+			*failures = append(*failures, fmt.Sprintf(messages.CertificateExpiringFailure, name, expires.Format(time.RFC3339), rule.MinRemainingValidity.Duration))
+		}
+	}
+
+	if len(rule.DNSNames) > 0 {
+		names := certificateDNSNames(cert)
+		for _, dnsName := range rule.DNSNames {
+			if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, dnsName) }) {
+				*failures = append(*failures, fmt.Sprintf(messages.CertificateDNSNameFailure, name, dnsName))
+			}
+		}
+	}
+
+	return nil
+}
+
+// certificateDNSNames returns the common name of a certificate's subject and its DNS subject
+// alternative names. They're taken from the certificate itself when it can be parsed, because its
+// policy only describes what future versions of the certificate will be issued with.
+func certificateDNSNames(cert *azcertificates.Certificate) []string {
+	if len(cert.CER) > 0 {
+		if parsed, err := x509.ParseCertificate(cert.CER); err == nil {
+			return append([]string{parsed.Subject.CommonName}, parsed.DNSNames...)
+		}
+	}
+
+	names := []string{}
+	if cert.Policy == nil || cert.Policy.X509CertificateProperties == nil {
+		return names
+	}
+	props := cert.Policy.X509CertificateProperties
+	if props.Subject != nil {
+		for _, rdn := range strings.Split(*props.Subject, ",") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(rdn), "="); ok && strings.EqualFold(k, "CN") {
+				names = append(names, v)
+			}
+		}
+	}
+	if props.SubjectAlternativeNames != nil {
+		for _, n := range props.SubjectAlternativeNames.DNSNames {
+			if n != nil {
+				names = append(names, *n)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,264 @@
+package validators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azcertificates"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testVaultURI = "https://myvault.vault.azure.net/"
+
+// certificateAPIMock is a fake Key Vault with the certificates in data. Getting any other
+// certificate fails with a 404, unless err is set.
+type certificateAPIMock struct {
+	// keys = certificate names
+	data map[string]*azcertificates.Certificate
+	err  error
+}
+
+func (m certificateAPIMock) GetCertificate(name string) (*azcertificates.Certificate, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	cert, ok := m.data[name]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "CertificateNotFound", StatusCode: http.StatusNotFound}
+	}
+	return cert, nil
+}
+
+// newCertificate returns a certificate as Key Vault returns it, with an X.509 certificate for
+// commonName and dnsNames.
+func newCertificate(t *testing.T, name string, enabled bool, expires time.Time, commonName string, dnsNames ...string) *azcertificates.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    expires.AddDate(-1, 0, 0),
+		NotAfter:     expires,
+	}
+	cer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := azcertificates.ID(testVaultURI + "certificates/" + name + "/v1")
+	return &azcertificates.Certificate{
+		ID:  &id,
+		CER: cer,
+		Attributes: &azcertificates.CertificateAttributes{
+			Enabled: util.Ptr(enabled),
+			Expires: util.Ptr(expires),
+		},
+	}
+}
+
+func TestKeyVaultCertificateRuleService_ReconcileKeyVaultCertificateRule(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	minValidity := metav1.Duration{Duration: 30 * 24 * time.Hour}
+
+	tests := []struct {
+		name          string
+		certificates  []*azcertificates.Certificate
+		rule          v1alpha1.KeyVaultCertificateRule
+		wantState     vapi.ValidationState
+		wantFailures  []string
+		wantErr       bool
+		wantEvidences int
+	}{
+		{
+			name: "Passes when all certificates are enabled and remain valid for long enough.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "ingress", true, now.AddDate(0, 3, 0), "example.com", "example.com", "www.example.com"),
+				newCertificate(t, "apiserver", true, now.AddDate(1, 0, 0), "api.example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress", "apiserver"},
+				MinRemainingValidity: minValidity,
+			},
+			wantState:     vapi.ValidationSucceeded,
+			wantFailures:  []string{},
+			wantEvidences: 2,
+		},
+		{
+			name: "Fails for an expired certificate.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "ingress", true, now.Add(-time.Hour), "example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress"},
+				MinRemainingValidity: minValidity,
+			},
+			wantState:     vapi.ValidationFailed,
+			wantFailures:  []string{"Certificate ingress expired at 2023-12-31T23:00:00Z."},
+			wantEvidences: 1,
+		},
+		{
+			name: "Fails for a certificate that expires sooner than the minimum remaining validity.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "ingress", true, now.AddDate(0, 0, 10), "example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress"},
+				MinRemainingValidity: minValidity,
+			},
+			wantState:     vapi.ValidationFailed,
+			wantFailures:  []string{"Certificate ingress expires at 2024-01-11T00:00:00Z, which is sooner than the required minimum remaining validity of 720h0m0s."},
+			wantEvidences: 1,
+		},
+		{
+			name: "Fails for a disabled certificate.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "ingress", false, now.AddDate(1, 0, 0), "example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress"},
+				MinRemainingValidity: minValidity,
+			},
+			wantState:     vapi.ValidationFailed,
+			wantFailures:  []string{"Certificate ingress is disabled."},
+			wantEvidences: 1,
+		},
+		{
+			name: "Fails for a missing certificate, and still validates the other certificates.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "apiserver", true, now.AddDate(0, 0, 1), "api.example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress", "apiserver"},
+				MinRemainingValidity: minValidity,
+			},
+			wantState: vapi.ValidationFailed,
+			wantFailures: []string{
+				"Certificate ingress not found in vault https://myvault.vault.azure.net/.",
+				"Certificate apiserver expires at 2024-01-02T00:00:00Z, which is sooner than the required minimum remaining validity of 720h0m0s.",
+			},
+			wantEvidences: 1,
+		},
+		{
+			name: "Passes when the subject or SANs contain each required DNS name.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "ingress", true, now.AddDate(1, 0, 0), "example.com", "www.example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress"},
+				MinRemainingValidity: minValidity,
+				DNSNames:             []string{"example.com", "WWW.example.com"},
+			},
+			wantState:     vapi.ValidationSucceeded,
+			wantFailures:  []string{},
+			wantEvidences: 1,
+		},
+		{
+			name: "Fails when neither the subject nor the SANs contain a required DNS name.",
+			certificates: []*azcertificates.Certificate{
+				newCertificate(t, "ingress", true, now.AddDate(1, 0, 0), "example.com", "www.example.com"),
+			},
+			rule: v1alpha1.KeyVaultCertificateRule{
+				Name:                 "rule-1",
+				VaultURI:             testVaultURI,
+				Certificates:         []string{"ingress"},
+				MinRemainingValidity: minValidity,
+				DNSNames:             []string{"example.com", "api.example.com"},
+			},
+			wantState:     vapi.ValidationFailed,
+			wantFailures:  []string{"Certificate ingress is not valid for DNS name api.example.com: neither its subject nor its subject alternative names contain it."},
+			wantEvidences: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := certificateAPIMock{data: map[string]*azcertificates.Certificate{}}
+			for _, cert := range tt.certificates {
+				api.data[cert.ID.Name()] = cert
+			}
+			svc := NewKeyVaultCertificateRuleService(logr.Discard(), api, clocktesting.NewFakePassiveClock(now))
+
+			result, err := svc.ReconcileKeyVaultCertificateRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if *result.State != tt.wantState {
+				t.Errorf("got state %s, want %s", *result.State, tt.wantState)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if got := len(result.Condition.Details); got != tt.wantEvidences {
+				t.Errorf("got %d detail(s), want %d: %v", got, tt.wantEvidences, result.Condition.Details)
+			}
+		})
+	}
+}
+
+func TestKeyVaultCertificateRuleService_ReconcileKeyVaultCertificateRule_Error(t *testing.T) {
+	api := certificateAPIMock{err: &azcore.ResponseError{ErrorCode: "Forbidden", StatusCode: http.StatusForbidden}}
+	svc := NewKeyVaultCertificateRuleService(logr.Discard(), api, clocktesting.NewFakePassiveClock(time.Now()))
+
+	result, err := svc.ReconcileKeyVaultCertificateRule(v1alpha1.KeyVaultCertificateRule{
+		Name:         "rule-1",
+		VaultURI:     testVaultURI,
+		Certificates: []string{"ingress"},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
+
+func Test_certificateDNSNames(t *testing.T) {
+	// Certificates without an X.509 certificate (e.g. while it's being issued) fall back to
+	// their policy.
+	cert := &azcertificates.Certificate{
+		Policy: &azcertificates.CertificatePolicy{
+			X509CertificateProperties: &azcertificates.X509CertificateProperties{
+				Subject: util.Ptr("O=Example, CN=example.com"),
+				SubjectAlternativeNames: &azcertificates.SubjectAlternativeNames{
+					DNSNames: []*string{util.Ptr("www.example.com")},
+				},
+			},
+		},
+	}
+	if got, want := certificateDNSNames(cert), []string{"example.com", "www.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := certificateDNSNames(&azcertificates.Certificate{}); len(got) != 0 {
+		t.Errorf("got %v, want no names", got)
+	}
+}
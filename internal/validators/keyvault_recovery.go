@@ -0,0 +1,156 @@
+package validators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// defaultSoftDeleteRetentionDays is how many days Key Vaults without softDeleteRetentionInDays
+// retain themselves and their objects once they're soft-deleted.
+const defaultSoftDeleteRetentionDays = 90
+
+// keyVaultRecoveryAPI contains methods that allow getting Key Vaults, and soft-deleted Key Vaults
+// by their names and locations.
+type keyVaultRecoveryAPI interface {
+	GetVault(subscriptionID, resourceGroup, vaultName string) (*azure_utils.Vault, error)
+	GetDeletedVault(subscriptionID, location, vaultName string) (*azure_utils.DeletedVault, error)
+}
+
+type KeyVaultRecoveryRuleService struct {
+	log      logr.Logger
+	vaultAPI keyVaultRecoveryAPI
+}
+
+func NewKeyVaultRecoveryRuleService(log logr.Logger, vaultAPI keyVaultRecoveryAPI) *KeyVaultRecoveryRuleService {
+	return &KeyVaultRecoveryRuleService{
+		log:      log,
+		vaultAPI: vaultAPI,
+	}
+}
+
+// ReconcileKeyVaultRecoveryRule reconciles a Key Vault recovery rule from a validation config.
+func (s *KeyVaultRecoveryRuleService) ReconcileKeyVaultRecoveryRule(rule v1alpha1.KeyVaultRecoveryRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this Key Vault recovery rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.KeyVaultRecoverySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeKeyVaultRecovery
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeKeyVaultRecovery, "vaultName", rule.VaultName, "location", rule.Location)
+	l.V(1).Info("Validating Key Vault recovery")
+	ev := &evidence{}
+	if err := s.validateDeletedVault(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate soft-deleted Key Vault", err, &latestCondition)
+	}
+	if rule.Vault != nil {
+		if err := s.validateVault(rule, *rule.Vault, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l, "failed to validate Key Vault", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.vaultAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.KeyVaultRecoveryFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateDeletedVault appends a failure if a soft-deleted vault holds the rule's vault name in its
+// location and the rule's deletedVault is MustNotExist, the default, or if none does and it's
+// MustExist.
+func (s *KeyVaultRecoveryRuleService) validateDeletedVault(rule v1alpha1.KeyVaultRecoveryRule, failures *[]string, ev *evidence) error {
+	deleted, err := s.vaultAPI.GetDeletedVault(rule.SubscriptionID, rule.Location, rule.VaultName)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to get soft-deleted Key Vault: %w", azure_errors.AsAugmented(err))
+	}
+
+	if deleted == nil {
+		if rule.DeletedVault == v1alpha1.DeletedVaultMustExist {
+			*failures = append(*failures, fmt.Sprintf(messages.DeletedVaultMissingFailure, rule.VaultName, rule.Location))
+			return nil
+		}
+		ev.add("No soft-deleted Key Vault holds the name %s in %s.", rule.VaultName, rule.Location)
+		return nil
+	}
+
+	p := deleted.Properties
+	purgeProtection := enabledOrDisabled(p.PurgeProtectionEnabled != nil && *p.PurgeProtectionEnabled)
+	if rule.DeletedVault == v1alpha1.DeletedVaultMustExist {
+		ev.add("Soft-deleted Key Vault %s holds the name %s in %s, deleted %s, scheduled to be purged %s, with purge protection %s.",
+			orNotSet(p.VaultID), rule.VaultName, rule.Location, dateOrNotSet(p.DeletionDate), dateOrNotSet(p.ScheduledPurgeDate), purgeProtection)
+		return nil
+	}
+	*failures = append(*failures, fmt.Sprintf(messages.DeletedVaultHoldsNameFailure,
+		rule.VaultName, rule.Location, orNotSet(p.VaultID), dateOrNotSet(p.DeletionDate), dateOrNotSet(p.ScheduledPurgeDate), purgeProtection))
+	return nil
+}
+
+// validateVault appends a failure if the rule's vault doesn't exist, and one for each of the
+// settings it lacks. A vault that doesn't exist is a failure, not an error.
+func (s *KeyVaultRecoveryRuleService) validateVault(rule v1alpha1.KeyVaultRecoveryRule, settings v1alpha1.KeyVaultSettings, failures *[]string, ev *evidence) error {
+	vault, err := s.vaultAPI.GetVault(rule.SubscriptionID, settings.ResourceGroup, rule.VaultName)
+	if err != nil {
+		if isNotFound(err) {
+			*failures = append(*failures, fmt.Sprintf(messages.KeyVaultNotFoundFailure, rule.VaultName, settings.ResourceGroup))
+			return nil
+		}
+		return fmt.Errorf("failed to get Key Vault: %w", azure_errors.AsAugmented(err))
+	}
+
+	p := vault.Properties
+	softDelete := p.EnableSoftDelete != nil && *p.EnableSoftDelete
+	purgeProtection := p.EnablePurgeProtection != nil && *p.EnablePurgeProtection
+	retentionDays := int32(defaultSoftDeleteRetentionDays)
+	if p.SoftDeleteRetentionInDays != nil {
+		retentionDays = *p.SoftDeleteRetentionInDays
+	}
+	ev.add("Key Vault %s has soft delete %s, purge protection %s, and a retention period of %d days.", rule.VaultName, enabledOrDisabled(softDelete), enabledOrDisabled(purgeProtection), retentionDays)
+
+	if settings.RequireSoftDelete && !softDelete {
+		*failures = append(*failures, fmt.Sprintf(messages.KeyVaultSoftDeleteFailure, rule.VaultName))
+	}
+	if settings.RequirePurgeProtection && !purgeProtection {
+		*failures = append(*failures, fmt.Sprintf(messages.KeyVaultPurgeProtectionFailure, rule.VaultName))
+	}
+	if settings.MinSoftDeleteRetentionDays > 0 && retentionDays < settings.MinSoftDeleteRetentionDays {
+		*failures = append(*failures, fmt.Sprintf(messages.KeyVaultRetentionFailure, rule.VaultName, retentionDays, settings.MinSoftDeleteRetentionDays))
+	}
+	return nil
+}
+
+// dateOrNotSet returns t as an RFC 3339 date, or notSet if it's nil.
+func dateOrNotSet(t *time.Time) string {
+	if t == nil {
+		return notSet
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// enabledOrDisabled returns "enabled" if b is true, and "disabled" otherwise.
+func enabledOrDisabled(b bool) string {
+	if b {
+		return "enabled"
+	}
+	return "disabled"
+}
@@ -0,0 +1,198 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testVaultSubscriptionID = "00000000-0000-0000-0000-000000000000"
+	testVaultName           = "cmk-vault"
+	testVaultID             = "/subscriptions/" + testVaultSubscriptionID + "/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/" + testVaultName
+)
+
+// keyVaultRecoveryAPIMock is a fake ARM with the vaults and soft-deleted vaults in its maps, keyed
+// by resource group and location. Getting any other vault fails with a 404, unless err is set.
+type keyVaultRecoveryAPIMock struct {
+	vaults        map[string]*azure_utils.Vault
+	deletedVaults map[string]*azure_utils.DeletedVault
+	err           error
+}
+
+func (m keyVaultRecoveryAPIMock) GetVault(_, resourceGroup, _ string) (*azure_utils.Vault, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.vaults, resourceGroup)
+}
+
+func (m keyVaultRecoveryAPIMock) GetDeletedVault(_, location, _ string) (*azure_utils.DeletedVault, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.deletedVaults, location)
+}
+
+func newVault(softDelete, purgeProtection *bool, retentionDays *int32) *azure_utils.Vault {
+	return &azure_utils.Vault{
+		ID: testVaultID,
+		Properties: azure_utils.VaultProperties{
+			EnableSoftDelete:          softDelete,
+			EnablePurgeProtection:     purgeProtection,
+			SoftDeleteRetentionInDays: retentionDays,
+		},
+	}
+}
+
+func TestKeyVaultRecoveryRuleService_ReconcileKeyVaultRecoveryRule(t *testing.T) {
+	deletionDate := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	purgeDate := deletionDate.AddDate(0, 0, 90)
+	deletedVault := &azure_utils.DeletedVault{
+		Properties: azure_utils.DeletedVaultProperties{
+			VaultID:                testVaultID,
+			DeletionDate:           &deletionDate,
+			ScheduledPurgeDate:     &purgeDate,
+			PurgeProtectionEnabled: util.Ptr(true),
+		},
+	}
+	cmkSettings := &v1alpha1.KeyVaultSettings{ResourceGroup: "rg", RequireSoftDelete: true, RequirePurgeProtection: true, MinSoftDeleteRetentionDays: 30}
+
+	tests := []struct {
+		name          string
+		deletedVault  v1alpha1.DeletedVaultExpectation
+		settings      *v1alpha1.KeyVaultSettings
+		vaults        map[string]*azure_utils.Vault
+		deletedVaults map[string]*azure_utils.DeletedVault
+		wantFailures  []string
+	}{
+		{
+			name:         "Passes when no soft-deleted vault holds the name.",
+			wantFailures: []string{},
+		},
+		{
+			name:          "Passes when a soft-deleted vault holds the name in another location.",
+			deletedVaults: map[string]*azure_utils.DeletedVault{"westus": deletedVault},
+			wantFailures:  []string{},
+		},
+		{
+			name:          "Fails when a soft-deleted vault occupies the name.",
+			deletedVaults: map[string]*azure_utils.DeletedVault{"eastus": deletedVault},
+			wantFailures: []string{
+				"Key Vault name " + testVaultName + " is held in eastus by soft-deleted vault " + testVaultID + ", deleted 2024-03-01T12:00:00Z and scheduled to be purged 2024-05-30T12:00:00Z, with purge protection enabled, so a vault of that name can't be created until it's recovered or purged.",
+			},
+		},
+		{
+			name:          "Fails when a soft-deleted vault occupies the name, whose dates aren't set.",
+			deletedVaults: map[string]*azure_utils.DeletedVault{"eastus": {}},
+			wantFailures: []string{
+				"Key Vault name " + testVaultName + " is held in eastus by soft-deleted vault (not set), deleted (not set) and scheduled to be purged (not set), with purge protection disabled, so a vault of that name can't be created until it's recovered or purged.",
+			},
+		},
+		{
+			name:          "Passes when a soft-deleted vault to recover holds the name.",
+			deletedVault:  v1alpha1.DeletedVaultMustExist,
+			deletedVaults: map[string]*azure_utils.DeletedVault{"eastus": deletedVault},
+			wantFailures:  []string{},
+		},
+		{
+			name:         "Fails when no soft-deleted vault to recover holds the name.",
+			deletedVault: v1alpha1.DeletedVaultMustExist,
+			wantFailures: []string{"No soft-deleted Key Vault named " + testVaultName + " in eastus to recover."},
+		},
+		{
+			name:         "Passes when the vault has soft delete, purge protection, and a long enough retention period.",
+			settings:     cmkSettings,
+			vaults:       map[string]*azure_utils.Vault{"rg": newVault(util.Ptr(true), util.Ptr(true), util.Ptr(int32(30)))},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Counts 90 days of retention when the vault's retention period isn't set.",
+			settings:     cmkSettings,
+			vaults:       map[string]*azure_utils.Vault{"rg": newVault(util.Ptr(true), util.Ptr(true), nil)},
+			wantFailures: []string{},
+		},
+		{
+			name:     "Fails when purge protection is disabled.",
+			settings: cmkSettings,
+			vaults:   map[string]*azure_utils.Vault{"rg": newVault(util.Ptr(true), util.Ptr(false), util.Ptr(int32(90)))},
+			wantFailures: []string{
+				"Key Vault " + testVaultName + " has purge protection disabled, so it and its keys can be purged before their retention period ends.",
+			},
+		},
+		{
+			name:     "Fails with each setting the vault lacks, when its properties aren't set.",
+			settings: cmkSettings,
+			vaults:   map[string]*azure_utils.Vault{"rg": newVault(nil, nil, util.Ptr(int32(7)))},
+			wantFailures: []string{
+				"Key Vault " + testVaultName + " has soft delete disabled.",
+				"Key Vault " + testVaultName + " has purge protection disabled, so it and its keys can be purged before their retention period ends.",
+				"Key Vault " + testVaultName + " has a retention period of 7 days, fewer than the required 30.",
+			},
+		},
+		{
+			name:         "Passes without required settings when the vault lacks them.",
+			settings:     &v1alpha1.KeyVaultSettings{ResourceGroup: "rg"},
+			vaults:       map[string]*azure_utils.Vault{"rg": newVault(nil, nil, nil)},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the vault is missing.",
+			settings:     cmkSettings,
+			wantFailures: []string{"Key Vault " + testVaultName + " not found in resource group rg."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := keyVaultRecoveryAPIMock{vaults: tt.vaults, deletedVaults: tt.deletedVaults}
+			svc := NewKeyVaultRecoveryRuleService(logr.Discard(), api)
+
+			rule := v1alpha1.KeyVaultRecoveryRule{
+				Name:           "rule-1",
+				SubscriptionID: testVaultSubscriptionID,
+				Location:       "eastus",
+				VaultName:      testVaultName,
+				DeletedVault:   tt.deletedVault,
+				Vault:          tt.settings,
+			}
+			result, err := svc.ReconcileKeyVaultRecoveryRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestKeyVaultRecoveryRuleService_ReconcileKeyVaultRecoveryRule_Error(t *testing.T) {
+	api := keyVaultRecoveryAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewKeyVaultRecoveryRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileKeyVaultRecoveryRule(v1alpha1.KeyVaultRecoveryRule{Name: "rule-1", SubscriptionID: testVaultSubscriptionID, Location: "eastus", VaultName: testVaultName})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
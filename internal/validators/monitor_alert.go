@@ -0,0 +1,162 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// monitorAlertAPI contains methods that allow getting action groups and metric alert rules by
+// their resource IDs.
+type monitorAlertAPI interface {
+	GetActionGroup(actionGroupID string) (*armmonitor.ActionGroupResource, error)
+	GetMetricAlert(alertID string) (*armmonitor.MetricAlertResource, error)
+}
+
+type MonitorAlertRuleService struct {
+	log logr.Logger
+	api monitorAlertAPI
+}
+
+func NewMonitorAlertRuleService(log logr.Logger, api monitorAlertAPI) *MonitorAlertRuleService {
+	return &MonitorAlertRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileMonitorAlertRule reconciles a monitor alert rule from a validation config.
+func (s *MonitorAlertRuleService) ReconcileMonitorAlertRule(rule v1alpha1.MonitorAlertRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this monitor alert rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.MonitorAlertSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeMonitorAlert
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	prefix := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights", rule.SubscriptionID, rule.ResourceGroup)
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeMonitorAlert, "resourceGroup", rule.ResourceGroup)
+	l.V(1).Info("Validating action group and metric alert rules")
+	ev := &evidence{}
+	if err := s.validateActionGroup(rule, prefix+"/actionGroups/"+rule.ActionGroupName, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate action group", err, &latestCondition)
+	}
+	for _, name := range rule.MetricAlertNames {
+		if err := s.validateMetricAlert(prefix+"/metricAlerts/"+name, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l.WithValues("metricAlert", name), "failed to validate metric alert rule", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.MonitorAlertFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateActionGroup appends a failure if the action group doesn't exist or is disabled, and for
+// each of the rule's receiver types that it has no receivers of.
+func (s *MonitorAlertRuleService) validateActionGroup(rule v1alpha1.MonitorAlertRule, actionGroupID string, failures *[]string, ev *evidence) error {
+	group, err := s.api.GetActionGroup(actionGroupID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ActionGroupNotFoundFailure, actionGroupID))
+			return nil
+		}
+		return fmt.Errorf("failed to get action group: %w", azure_errors.AsAugmented(err))
+	}
+	props := group.Properties
+	if props == nil {
+		props = &armmonitor.ActionGroup{}
+	}
+
+	counts := receiverCounts(props)
+	receivers := []string{}
+	for _, t := range receiverTypes {
+		if counts[t] > 0 {
+			receivers = append(receivers, fmt.Sprintf("%d %s", counts[t], t))
+		}
+	}
+	status := "disabled"
+	if props.Enabled != nil && *props.Enabled {
+		status = "enabled"
+	}
+	ev.add("Action group %s is %s and has receivers: %s.", actionGroupID, status, joinOrNone(receivers))
+
+	// A disabled action group notifies none of its receivers.
+	if status == "disabled" {
+		*failures = append(*failures, fmt.Sprintf(messages.ActionGroupDisabledFailure, actionGroupID))
+	}
+	for _, t := range rule.ReceiverTypes {
+		if counts[string(t)] == 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.ActionGroupReceiversFailure, actionGroupID, t))
+		}
+	}
+	return nil
+}
+
+// validateMetricAlert appends a failure if a metric alert rule doesn't exist or is disabled.
+func (s *MonitorAlertRuleService) validateMetricAlert(alertID string, failures *[]string, ev *evidence) error {
+	alert, err := s.api.GetMetricAlert(alertID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.MetricAlertNotFoundFailure, alertID))
+			return nil
+		}
+		return fmt.Errorf("failed to get metric alert rule: %w", azure_errors.AsAugmented(err))
+	}
+	status := "disabled"
+	if alert.Properties != nil && alert.Properties.Enabled != nil && *alert.Properties.Enabled {
+		status = "enabled"
+	}
+	ev.add("Metric alert rule %s is %s.", alertID, status)
+	if status == "disabled" {
+		*failures = append(*failures, fmt.Sprintf(messages.MetricAlertDisabledFailure, alertID))
+	}
+	return nil
+}
+
+// receiverTypes are the receiver types of monitor alert rules, in the order that evidence lists
+// them.
+var receiverTypes = []string{"Email", "Sms", "Webhook", "Voice", "AzureAppPush", "ArmRole", "AzureFunction", "LogicApp", "AutomationRunbook", "EventHub", "Itsm"}
+
+// receiverCounts returns the number of an action group's receivers of each type, keyed by the
+// receiver types of monitor alert rules.
+func receiverCounts(group *armmonitor.ActionGroup) map[string]int {
+	return map[string]int{
+		"Email":             len(group.EmailReceivers),
+		"Sms":               len(group.SmsReceivers),
+		"Webhook":           len(group.WebhookReceivers),
+		"Voice":             len(group.VoiceReceivers),
+		"AzureAppPush":      len(group.AzureAppPushReceivers),
+		"ArmRole":           len(group.ArmRoleReceivers),
+		"AzureFunction":     len(group.AzureFunctionReceivers),
+		"LogicApp":          len(group.LogicAppReceivers),
+		"AutomationRunbook": len(group.AutomationRunbookReceivers),
+		"EventHub":          len(group.EventHubReceivers),
+		"Itsm":              len(group.ItsmReceivers),
+	}
+}
@@ -0,0 +1,140 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testInsightsPrefix = "/subscriptions/" + testSubscriptionID + "/resourceGroups/monitoring/providers/Microsoft.Insights"
+
+// monitorAlertAPIMock is a fake ARM with the action groups and metric alert rules in its maps,
+// keyed by resource ID. Getting any other resource fails with a 404, unless err is set.
+type monitorAlertAPIMock struct {
+	actionGroups map[string]*armmonitor.ActionGroupResource
+	metricAlerts map[string]*armmonitor.MetricAlertResource
+	err          error
+}
+
+func (m monitorAlertAPIMock) GetActionGroup(actionGroupID string) (*armmonitor.ActionGroupResource, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.actionGroups, actionGroupID)
+}
+
+func (m monitorAlertAPIMock) GetMetricAlert(alertID string) (*armmonitor.MetricAlertResource, error) {
+	return getOrNotFound(m.metricAlerts, alertID)
+}
+
+func newMetricAlert(enabled bool) *armmonitor.MetricAlertResource {
+	return &armmonitor.MetricAlertResource{Properties: &armmonitor.MetricAlertProperties{Enabled: util.Ptr(enabled)}}
+}
+
+func TestMonitorAlertRuleService_ReconcileMonitorAlertRule(t *testing.T) {
+	api := monitorAlertAPIMock{
+		actionGroups: map[string]*armmonitor.ActionGroupResource{
+			testInsightsPrefix + "/actionGroups/oncall": {Properties: &armmonitor.ActionGroup{
+				Enabled:          util.Ptr(true),
+				EmailReceivers:   []*armmonitor.EmailReceiver{{Name: util.Ptr("sre"), EmailAddress: util.Ptr("sre@example.com")}},
+				WebhookReceivers: []*armmonitor.WebhookReceiver{{Name: util.Ptr("pager"), ServiceURI: util.Ptr("https://example.com/hook")}},
+			}},
+			testInsightsPrefix + "/actionGroups/muted": {Properties: &armmonitor.ActionGroup{
+				Enabled:        util.Ptr(false),
+				EmailReceivers: []*armmonitor.EmailReceiver{{Name: util.Ptr("sre"), EmailAddress: util.Ptr("sre@example.com")}},
+			}},
+		},
+		metricAlerts: map[string]*armmonitor.MetricAlertResource{
+			testInsightsPrefix + "/metricAlerts/node-cpu":    newMetricAlert(true),
+			testInsightsPrefix + "/metricAlerts/node-memory": newMetricAlert(false),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.MonitorAlertRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the action group has the receiver types and the metric alert rules are enabled.",
+			rule: v1alpha1.MonitorAlertRule{
+				ActionGroupName:  "oncall",
+				ReceiverTypes:    []v1alpha1.ActionGroupReceiverType{"Email", "Webhook"},
+				MetricAlertNames: []string{"node-cpu"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for each missing receiver type and each missing or disabled metric alert rule.",
+			rule: v1alpha1.MonitorAlertRule{
+				ActionGroupName:  "oncall",
+				ReceiverTypes:    []v1alpha1.ActionGroupReceiverType{"Email", "Sms", "Itsm"},
+				MetricAlertNames: []string{"node-cpu", "node-memory", "node-disk"},
+			},
+			wantFailures: []string{
+				"Action group " + testInsightsPrefix + "/actionGroups/oncall has no Sms receivers.",
+				"Action group " + testInsightsPrefix + "/actionGroups/oncall has no Itsm receivers.",
+				"Metric alert rule " + testInsightsPrefix + "/metricAlerts/node-memory is disabled.",
+				"Metric alert rule " + testInsightsPrefix + "/metricAlerts/node-disk not found.",
+			},
+		},
+		{
+			name: "Fails when the action group is disabled.",
+			rule: v1alpha1.MonitorAlertRule{ActionGroupName: "muted", ReceiverTypes: []v1alpha1.ActionGroupReceiverType{"Email"}},
+			wantFailures: []string{
+				"Action group " + testInsightsPrefix + "/actionGroups/muted is disabled, so none of its receivers are notified.",
+			},
+		},
+		{
+			name:         "Fails when the action group is missing.",
+			rule:         v1alpha1.MonitorAlertRule{ActionGroupName: "deleted", ReceiverTypes: []v1alpha1.ActionGroupReceiverType{"Email"}},
+			wantFailures: []string{"Action group " + testInsightsPrefix + "/actionGroups/deleted not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMonitorAlertRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.SubscriptionID = testSubscriptionID
+			tt.rule.ResourceGroup = "monitoring"
+			result, err := svc.ReconcileMonitorAlertRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestMonitorAlertRuleService_ReconcileMonitorAlertRule_Error(t *testing.T) {
+	api := monitorAlertAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewMonitorAlertRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileMonitorAlertRule(v1alpha1.MonitorAlertRule{Name: "rule-1", SubscriptionID: testSubscriptionID, ResourceGroup: "monitoring", ActionGroupName: "oncall"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
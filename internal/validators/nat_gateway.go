@@ -0,0 +1,143 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// subnetAPI contains methods that allow getting a subnet by its resource ID.
+type subnetAPI interface {
+	GetSubnet(subnetID string) (*armnetwork.Subnet, error)
+}
+
+// natGatewayAPI contains methods that allow getting a NAT gateway by its resource ID.
+type natGatewayAPI interface {
+	GetNatGateway(natGatewayID string) (*armnetwork.NatGateway, error)
+}
+
+type NATGatewayRuleService struct {
+	log           logr.Logger
+	subnetAPI     subnetAPI
+	natGatewayAPI natGatewayAPI
+}
+
+func NewNATGatewayRuleService(log logr.Logger, subnetAPI subnetAPI, natGatewayAPI natGatewayAPI) *NATGatewayRuleService {
+	return &NATGatewayRuleService{
+		log:           log,
+		subnetAPI:     subnetAPI,
+		natGatewayAPI: natGatewayAPI,
+	}
+}
+
+// ReconcileNATGatewayRule reconciles a NAT gateway rule from a validation config.
+func (s *NATGatewayRuleService) ReconcileNATGatewayRule(rule v1alpha1.NATGatewayRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this NAT gateway rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.NATGatewaySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeNATGateway
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeNATGateway)
+	ev := &evidence{}
+	// Subnets often share a NAT gateway, so each gateway is only fetched once.
+	gateways := map[string]*armnetwork.NatGateway{}
+	for _, subnetID := range rule.SubnetIDs {
+		sl := l.WithValues("subnetID", subnetID)
+		sl.V(1).Info("Validating subnet's NAT gateway")
+		if err := s.validateSubnet(rule, string(subnetID), gateways, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(sl, "failed to validate subnet's NAT gateway", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.subnetAPI, s.natGatewayAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.NATGatewayFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateSubnet appends a failure if a subnet doesn't have a NAT gateway attached, or if its NAT
+// gateway doesn't meet the rule's requirements. Subnets and gateways that don't exist are failures,
+// not errors.
+func (s *NATGatewayRuleService) validateSubnet(rule v1alpha1.NATGatewayRule, subnetID string, gateways map[string]*armnetwork.NatGateway, failures *[]string, ev *evidence) error {
+	var rerr *azcore.ResponseError
+	subnet, err := s.subnetAPI.GetSubnet(subnetID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.SubnetNotFoundFailure, subnetID))
+			return nil
+		}
+		return fmt.Errorf("failed to get subnet: %w", azure_errors.AsAugmented(err))
+	}
+	if subnet.Properties == nil || subnet.Properties.NatGateway == nil || subnet.Properties.NatGateway.ID == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.NATGatewayMissingFailure, subnetID))
+		return nil
+	}
+
+	gatewayID := *subnet.Properties.NatGateway.ID
+	// ARM doesn't preserve the case of resource IDs consistently across references.
+	key := strings.ToLower(gatewayID)
+	gateway, ok := gateways[key]
+	if !ok {
+		gateway, err = s.natGatewayAPI.GetNatGateway(gatewayID)
+		if err != nil {
+			if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+				*failures = append(*failures, fmt.Sprintf(messages.NATGatewayNotFoundFailure, gatewayID, subnetID))
+				return nil
+			}
+			return fmt.Errorf("failed to get NAT gateway: %w", azure_errors.AsAugmented(err))
+		}
+		gateways[key] = gateway
+	}
+
+	props := gateway.Properties
+	if props == nil {
+		props = &armnetwork.NatGatewayPropertiesFormat{}
+	}
+	provisioningState := notSet
+	if props.ProvisioningState != nil {
+		provisioningState = string(*props.ProvisioningState)
+	}
+	ev.add("Subnet %s uses NAT gateway %s, whose provisioning state is %s and which has %d public IP address(es) and %d public IP prefix(es).",
+		subnetID, gatewayID, provisioningState, len(props.PublicIPAddresses), len(props.PublicIPPrefixes))
+
+	if rule.RequireProvisioned && !strings.EqualFold(provisioningState, string(armnetwork.ProvisioningStateSucceeded)) {
+		*failures = append(*failures, fmt.Sprintf(messages.NATGatewayProvisioningFailure, gatewayID, subnetID, provisioningState))
+	}
+
+	// A public IP prefix provides several outbound IPs, but the rule counts what's associated with
+	// the gateway, not the addresses themselves.
+	outbound := len(props.PublicIPAddresses) + len(props.PublicIPPrefixes)
+	switch {
+	case outbound == 0:
+		*failures = append(*failures, fmt.Sprintf(messages.NATGatewayNoOutboundIPsFailure, gatewayID, subnetID))
+	case outbound < rule.MinOutboundIPs:
+		*failures = append(*failures, fmt.Sprintf(messages.NATGatewayOutboundIPsFailure, gatewayID, subnetID, outbound, rule.MinOutboundIPs))
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testVNetID              = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet"
+	testSubnet1ID           = testVNetID + "/subnets/nodes"
+	testSubnet2ID           = testVNetID + "/subnets/pods"
+	testNATGatewayID        = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/natGateways/natgw"
+	testPublicIPID          = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/natgw-ip"
+	testIPPrefixID          = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/publicIPPrefixes/natgw-prefix"
+	testMissingNATGatewayID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/natGateways/deleted"
+)
+
+// networkAPIMock is a fake ARM with the subnets and NAT gateways in its maps, keyed by resource ID.
+// Getting any other resource fails with a 404, unless err is set. It counts the NAT gateways it's
+// asked for.
+type networkAPIMock struct {
+	subnets     map[string]*armnetwork.Subnet
+	natGateways map[string]*armnetwork.NatGateway
+	err         error
+	gets        *int
+}
+
+func (m networkAPIMock) GetSubnet(subnetID string) (*armnetwork.Subnet, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	subnet, ok := m.subnets[subnetID]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "NotFound", StatusCode: http.StatusNotFound}
+	}
+	return subnet, nil
+}
+
+func (m networkAPIMock) GetNatGateway(natGatewayID string) (*armnetwork.NatGateway, error) {
+	*m.gets++
+	gateway, ok := m.natGateways[natGatewayID]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return gateway, nil
+}
+
+func newSubnet(natGatewayID string) *armnetwork.Subnet {
+	subnet := &armnetwork.Subnet{Properties: &armnetwork.SubnetPropertiesFormat{}}
+	if natGatewayID != "" {
+		subnet.Properties.NatGateway = &armnetwork.SubResource{ID: util.Ptr(natGatewayID)}
+	}
+	return subnet
+}
+
+// newNATGateway returns a NAT gateway with Succeeded provisioning state, one public IP address,
+// and one public IP prefix.
+func newNATGateway() *armnetwork.NatGateway {
+	return &armnetwork.NatGateway{
+		ID: util.Ptr(testNATGatewayID),
+		Properties: &armnetwork.NatGatewayPropertiesFormat{
+			ProvisioningState: util.Ptr(armnetwork.ProvisioningStateSucceeded),
+			PublicIPAddresses: []*armnetwork.SubResource{{ID: util.Ptr(testPublicIPID)}},
+			PublicIPPrefixes:  []*armnetwork.SubResource{{ID: util.Ptr(testIPPrefixID)}},
+		},
+	}
+}
+
+func TestNATGatewayRuleService_ReconcileNATGatewayRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		subnets      map[string]*armnetwork.Subnet
+		gateway      func(g *armnetwork.NatGateway)
+		rule         v1alpha1.NATGatewayRule
+		wantFailures []string
+		wantGets     int
+	}{
+		{
+			name: "Passes when all subnets share a provisioned NAT gateway with enough outbound IPs, which is only fetched once.",
+			subnets: map[string]*armnetwork.Subnet{
+				testSubnet1ID: newSubnet(testNATGatewayID),
+				testSubnet2ID: newSubnet(testNATGatewayID),
+			},
+			rule: v1alpha1.NATGatewayRule{
+				SubnetIDs:          []v1alpha1.SubnetID{testSubnet1ID, testSubnet2ID},
+				MinOutboundIPs:     2,
+				RequireProvisioned: true,
+			},
+			wantFailures: []string{},
+			wantGets:     1,
+		},
+		{
+			name: "Matches the NAT gateway's ID case-insensitively when caching it.",
+			subnets: map[string]*armnetwork.Subnet{
+				testSubnet1ID: newSubnet(testNATGatewayID),
+				testSubnet2ID: newSubnet(strings.ToLower(testNATGatewayID)),
+			},
+			rule:         v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID, testSubnet2ID}},
+			wantFailures: []string{},
+			wantGets:     1,
+		},
+		{
+			name:         "Fails when a subnet has no NAT gateway.",
+			subnets:      map[string]*armnetwork.Subnet{testSubnet1ID: newSubnet("")},
+			rule:         v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}},
+			wantFailures: []string{"Subnet " + testSubnet1ID + " has no NAT gateway."},
+		},
+		{
+			name:    "Fails when the NAT gateway isn't provisioned and that's required.",
+			subnets: map[string]*armnetwork.Subnet{testSubnet1ID: newSubnet(testNATGatewayID)},
+			gateway: func(g *armnetwork.NatGateway) {
+				g.Properties.ProvisioningState = util.Ptr(armnetwork.ProvisioningStateUpdating)
+			},
+			rule:         v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}, RequireProvisioned: true},
+			wantFailures: []string{"NAT gateway " + testNATGatewayID + " of subnet " + testSubnet1ID + " is not provisioned: its provisioning state is Updating."},
+			wantGets:     1,
+		},
+		{
+			name:    "Ignores the provisioning state unless required.",
+			subnets: map[string]*armnetwork.Subnet{testSubnet1ID: newSubnet(testNATGatewayID)},
+			gateway: func(g *armnetwork.NatGateway) {
+				g.Properties.ProvisioningState = util.Ptr(armnetwork.ProvisioningStateFailed)
+			},
+			rule:         v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}},
+			wantFailures: []string{},
+			wantGets:     1,
+		},
+		{
+			name:    "Fails when the NAT gateway has no outbound IPs, even without a minimum.",
+			subnets: map[string]*armnetwork.Subnet{testSubnet1ID: newSubnet(testNATGatewayID)},
+			gateway: func(g *armnetwork.NatGateway) {
+				g.Properties.PublicIPAddresses = nil
+				g.Properties.PublicIPPrefixes = nil
+			},
+			rule:         v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}},
+			wantFailures: []string{"NAT gateway " + testNATGatewayID + " of subnet " + testSubnet1ID + " has no outbound IPs: it has no public IP addresses or public IP prefixes."},
+			wantGets:     1,
+		},
+		{
+			name:         "Fails when the NAT gateway has fewer outbound IPs than the minimum.",
+			subnets:      map[string]*armnetwork.Subnet{testSubnet1ID: newSubnet(testNATGatewayID)},
+			rule:         v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}, MinOutboundIPs: 3},
+			wantFailures: []string{"NAT gateway " + testNATGatewayID + " of subnet " + testSubnet1ID + " has 2 public IP address(es) and prefix(es), fewer than the required 3."},
+			wantGets:     1,
+		},
+		{
+			name: "Fails for missing subnets and NAT gateways, and still validates the other subnets.",
+			subnets: map[string]*armnetwork.Subnet{
+				testSubnet1ID: newSubnet(testMissingNATGatewayID),
+			},
+			rule: v1alpha1.NATGatewayRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID, testSubnet2ID}},
+			wantFailures: []string{
+				"NAT gateway " + testMissingNATGatewayID + " of subnet " + testSubnet1ID + " not found.",
+				"Subnet " + testSubnet2ID + " not found.",
+			},
+			wantGets: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := newNATGateway()
+			if tt.gateway != nil {
+				tt.gateway(gateway)
+			}
+			gets := 0
+			api := networkAPIMock{
+				subnets:     tt.subnets,
+				natGateways: map[string]*armnetwork.NatGateway{testNATGatewayID: gateway},
+				gets:        &gets,
+			}
+			svc := NewNATGatewayRuleService(logr.Discard(), api, api)
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileNATGatewayRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+			if gets != tt.wantGets {
+				t.Errorf("got %d NAT gateway request(s), want %d", gets, tt.wantGets)
+			}
+		})
+	}
+}
+
+func TestNATGatewayRuleService_ReconcileNATGatewayRule_Error(t *testing.T) {
+	api := networkAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewNATGatewayRuleService(logr.Discard(), api, api)
+
+	result, err := svc.ReconcileNATGatewayRule(v1alpha1.NATGatewayRule{Name: "rule-1", SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
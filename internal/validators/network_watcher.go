@@ -0,0 +1,152 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// networkWatcherAPI contains methods that allow listing the network watchers of a subscription,
+// and the flow logs of a network watcher by its resource ID.
+type networkWatcherAPI interface {
+	ListWatchers(subscriptionID string) ([]*armnetwork.Watcher, error)
+	ListFlowLogs(watcherID string) ([]*armnetwork.FlowLog, error)
+}
+
+type NetworkWatcherRuleService struct {
+	log logr.Logger
+	api networkWatcherAPI
+}
+
+func NewNetworkWatcherRuleService(log logr.Logger, api networkWatcherAPI) *NetworkWatcherRuleService {
+	return &NetworkWatcherRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileNetworkWatcherRule reconciles a network watcher rule from a validation config.
+func (s *NetworkWatcherRuleService) ReconcileNetworkWatcherRule(rule v1alpha1.NetworkWatcherRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this network watcher rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.NetworkWatcherSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeNetworkWatcher
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeNetworkWatcher, "subscriptionID", rule.SubscriptionID, "location", rule.Location)
+	l.V(1).Info("Validating network watcher")
+	ev := &evidence{}
+	if err := s.validateNetworkWatcher(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate network watcher", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.NetworkWatcherFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateNetworkWatcher appends a failure if the subscription has no network watcher in the
+// rule's location, and otherwise one for each way that each of the rule's network security groups'
+// flow logs isn't as expected.
+func (s *NetworkWatcherRuleService) validateNetworkWatcher(rule v1alpha1.NetworkWatcherRule, failures *[]string, ev *evidence) error {
+	watchers, err := s.api.ListWatchers(rule.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to list network watchers: %w", azure_errors.AsAugmented(err))
+	}
+	var watcher *armnetwork.Watcher
+	for _, w := range watchers {
+		if w != nil && w.ID != nil && w.Location != nil && normalizeLocation(*w.Location) == normalizeLocation(rule.Location) {
+			watcher = w
+			break
+		}
+	}
+	if watcher == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.NetworkWatcherMissingFailure, rule.SubscriptionID, rule.Location))
+		return nil
+	}
+	ev.add("Subscription %s has network watcher %s in location %s.", rule.SubscriptionID, *watcher.ID, rule.Location)
+	if len(rule.NetworkSecurityGroupIDs) == 0 {
+		return nil
+	}
+
+	flowLogs, err := s.api.ListFlowLogs(*watcher.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list flow logs: %w", azure_errors.AsAugmented(err))
+	}
+	for _, nsgID := range rule.NetworkSecurityGroupIDs {
+		flowLog := nsgFlowLog(flowLogs, nsgID)
+		if flowLog == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.FlowLogMissingFailure, nsgID, *watcher.ID))
+			continue
+		}
+		validateFlowLog(rule, nsgID, flowLog, failures, ev)
+	}
+	return nil
+}
+
+// nsgFlowLog returns the flow log of a network security group, or nil if it has none.
+func nsgFlowLog(flowLogs []*armnetwork.FlowLog, nsgID string) *armnetwork.FlowLog {
+	for _, f := range flowLogs {
+		if f != nil && f.Properties != nil && strings.EqualFold(strPtrValue(f.Properties.TargetResourceID), nsgID) {
+			return f
+		}
+	}
+	return nil
+}
+
+// validateFlowLog appends a failure for each of the rule's settings that a network security
+// group's flow log doesn't have, with the flow log's actual value.
+func validateFlowLog(rule v1alpha1.NetworkWatcherRule, nsgID string, flowLog *armnetwork.FlowLog, failures *[]string, ev *evidence) {
+	props := flowLog.Properties
+	name := strPtrValue(flowLog.Name)
+
+	if props.Enabled == nil || !*props.Enabled {
+		*failures = append(*failures, fmt.Sprintf(messages.FlowLogDisabledFailure, name, nsgID))
+	}
+
+	storageID := strPtrValue(props.StorageID)
+	if rule.StorageAccountID != "" && !strings.EqualFold(storageID, rule.StorageAccountID) {
+		*failures = append(*failures, fmt.Sprintf(messages.FlowLogStorageFailure, name, nsgID, storageID, rule.StorageAccountID))
+	}
+
+	// Flow logs keep logs forever unless their retention policy is enabled with a number of days.
+	retention := "forever"
+	if p := props.RetentionPolicy; p != nil && p.Enabled != nil && *p.Enabled && p.Days != nil && *p.Days > 0 {
+		retention = fmt.Sprintf("for %d day(s)", *p.Days)
+		if rule.MinRetentionDays > 0 && *p.Days < rule.MinRetentionDays {
+			*failures = append(*failures, fmt.Sprintf(messages.FlowLogRetentionFailure, name, nsgID, *p.Days, rule.MinRetentionDays))
+		}
+	}
+
+	trafficAnalytics := false
+	if a := props.FlowAnalyticsConfiguration; a != nil && a.NetworkWatcherFlowAnalyticsConfiguration != nil {
+		trafficAnalytics = a.NetworkWatcherFlowAnalyticsConfiguration.Enabled != nil && *a.NetworkWatcherFlowAnalyticsConfiguration.Enabled
+	}
+	if rule.RequireTrafficAnalytics && !trafficAnalytics {
+		*failures = append(*failures, fmt.Sprintf(messages.FlowLogTrafficAnalyticsFailure, name, nsgID))
+	}
+
+	ev.add("Flow log %s of network security group %s stores logs in %s and keeps them %s. Traffic Analytics enabled: %t.", name, nsgID, storageID, retention, trafficAnalytics)
+}
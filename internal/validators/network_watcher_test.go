@@ -0,0 +1,198 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testWatcherID        = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/NetworkWatcherRG/providers/Microsoft.Network/networkWatchers/NetworkWatcher_eastus"
+	testNSGID1           = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg-1"
+	testNSGID2           = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg-2"
+	testStorageAccountID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/flowlogs"
+)
+
+// networkWatcherAPIMock is a fake ARM with the network watchers of a subscription, and the flow
+// logs of its watcher.
+type networkWatcherAPIMock struct {
+	watchers []*armnetwork.Watcher
+	flowLogs []*armnetwork.FlowLog
+	err      error
+}
+
+func (m networkWatcherAPIMock) ListWatchers(subscriptionID string) ([]*armnetwork.Watcher, error) {
+	return m.watchers, m.err
+}
+
+func (m networkWatcherAPIMock) ListFlowLogs(watcherID string) ([]*armnetwork.FlowLog, error) {
+	return m.flowLogs, m.err
+}
+
+// newFlowLog returns an enabled flow log of a network security group that stores logs in the test
+// storage account for 30 days, with Traffic Analytics enabled.
+func newFlowLog(name, nsgID string) *armnetwork.FlowLog {
+	return &armnetwork.FlowLog{
+		Name: util.Ptr(name),
+		Properties: &armnetwork.FlowLogPropertiesFormat{
+			TargetResourceID: util.Ptr(nsgID),
+			StorageID:        util.Ptr(testStorageAccountID),
+			Enabled:          util.Ptr(true),
+			RetentionPolicy:  &armnetwork.RetentionPolicyParameters{Enabled: util.Ptr(true), Days: util.Ptr(int32(30))},
+			FlowAnalyticsConfiguration: &armnetwork.TrafficAnalyticsProperties{
+				NetworkWatcherFlowAnalyticsConfiguration: &armnetwork.TrafficAnalyticsConfigurationProperties{Enabled: util.Ptr(true)},
+			},
+		},
+	}
+}
+
+func TestNetworkWatcherRuleService_ReconcileNetworkWatcherRule(t *testing.T) {
+	watchers := []*armnetwork.Watcher{
+		{ID: util.Ptr("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/NetworkWatcherRG/providers/Microsoft.Network/networkWatchers/NetworkWatcher_westus"), Location: util.Ptr("westus")},
+		{ID: util.Ptr(testWatcherID), Location: util.Ptr("eastus")},
+	}
+	allChecks := v1alpha1.NetworkWatcherRule{
+		NetworkSecurityGroupIDs: []string{testNSGID1, testNSGID2},
+		StorageAccountID:        testStorageAccountID,
+		MinRetentionDays:        30,
+		RequireTrafficAnalytics: true,
+	}
+	tests := []struct {
+		name         string
+		watchers     []*armnetwork.Watcher
+		flowLogs     []*armnetwork.FlowLog
+		rule         v1alpha1.NetworkWatcherRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the subscription has a network watcher in the location.",
+			watchers:     watchers,
+			rule:         v1alpha1.NetworkWatcherRule{Location: "East US"},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the network security groups' flow logs are configured as expected.",
+			watchers:     watchers,
+			flowLogs:     []*armnetwork.FlowLog{newFlowLog("fl-1", testNSGID1), newFlowLog("fl-2", testNSGID2)},
+			rule:         allChecks,
+			wantFailures: []string{},
+		},
+		{
+			name:     "Passes when a flow log has no retention policy, and so keeps logs forever.",
+			watchers: watchers,
+			flowLogs: func() []*armnetwork.FlowLog {
+				f := newFlowLog("fl-1", testNSGID1)
+				f.Properties.RetentionPolicy = &armnetwork.RetentionPolicyParameters{Enabled: util.Ptr(false), Days: util.Ptr(int32(7))}
+				return []*armnetwork.FlowLog{f}
+			}(),
+			rule:         v1alpha1.NetworkWatcherRule{NetworkSecurityGroupIDs: []string{testNSGID1}, MinRetentionDays: 90},
+			wantFailures: []string{},
+		},
+		{
+			name:     "Fails when a flow log is disabled.",
+			watchers: watchers,
+			flowLogs: func() []*armnetwork.FlowLog {
+				f := newFlowLog("fl-1", testNSGID1)
+				f.Properties.Enabled = util.Ptr(false)
+				return []*armnetwork.FlowLog{f, newFlowLog("fl-2", testNSGID2)}
+			}(),
+			rule: allChecks,
+			wantFailures: []string{
+				"Flow log fl-1 of network security group " + testNSGID1 + " is disabled.",
+			},
+		},
+		{
+			name:     "Fails when a flow log keeps logs for too few days.",
+			watchers: watchers,
+			flowLogs: func() []*armnetwork.FlowLog {
+				f := newFlowLog("fl-2", testNSGID2)
+				f.Properties.RetentionPolicy.Days = util.Ptr(int32(7))
+				return []*armnetwork.FlowLog{newFlowLog("fl-1", testNSGID1), f}
+			}(),
+			rule: allChecks,
+			wantFailures: []string{
+				"Flow log fl-2 of network security group " + testNSGID2 + " keeps logs for 7 day(s), fewer than 30.",
+			},
+		},
+		{
+			name:     "Fails when a flow log stores logs elsewhere, without Traffic Analytics.",
+			watchers: watchers,
+			flowLogs: func() []*armnetwork.FlowLog {
+				f := newFlowLog("fl-1", testNSGID1)
+				f.Properties.StorageID = util.Ptr("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/other")
+				f.Properties.FlowAnalyticsConfiguration = nil
+				return []*armnetwork.FlowLog{f, newFlowLog("fl-2", testNSGID2)}
+			}(),
+			rule: allChecks,
+			wantFailures: []string{
+				"Flow log fl-1 of network security group " + testNSGID1 + " stores logs in /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/other, not " + testStorageAccountID + ".",
+				"Flow log fl-1 of network security group " + testNSGID1 + " doesn't have Traffic Analytics enabled.",
+			},
+		},
+		{
+			name:     "Fails when a network security group has no flow log.",
+			watchers: watchers,
+			flowLogs: []*armnetwork.FlowLog{newFlowLog("fl-1", testNSGID1)},
+			rule:     allChecks,
+			wantFailures: []string{
+				"Network security group " + testNSGID2 + " has no flow log in network watcher " + testWatcherID + ".",
+			},
+		},
+		{
+			name:     "Fails once when the subscription has no network watcher in the location.",
+			watchers: watchers[:1],
+			rule:     allChecks,
+			wantFailures: []string{
+				"Subscription 00000000-0000-0000-0000-000000000000 has no network watcher in location eastus.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewNetworkWatcherRuleService(logr.Discard(), networkWatcherAPIMock{watchers: tt.watchers, flowLogs: tt.flowLogs})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.SubscriptionID = "00000000-0000-0000-0000-000000000000"
+			if tt.rule.Location == "" {
+				tt.rule.Location = "eastus"
+			}
+			result, err := svc.ReconcileNetworkWatcherRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestNetworkWatcherRuleService_ReconcileNetworkWatcherRule_Error(t *testing.T) {
+	api := networkWatcherAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewNetworkWatcherRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileNetworkWatcherRule(v1alpha1.NetworkWatcherRule{Name: "rule-1", SubscriptionID: "00000000-0000-0000-0000-000000000000", Location: "eastus"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,157 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// policyExemptionAPI contains methods that allow getting the policy exemptions that apply to a
+// scope.
+type policyExemptionAPI interface {
+	ListExemptionsForScope(scope string) ([]*armpolicy.Exemption, error)
+}
+
+type PolicyExemptionRuleService struct {
+	log   logr.Logger
+	api   policyExemptionAPI
+	clock clock.PassiveClock
+}
+
+// NewPolicyExemptionRuleService creates a PolicyExemptionRuleService that lists exemptions with
+// api. Exemptions' remaining validity is measured from the current time of clock.
+func NewPolicyExemptionRuleService(log logr.Logger, api policyExemptionAPI, clock clock.PassiveClock) *PolicyExemptionRuleService {
+	return &PolicyExemptionRuleService{
+		log:   log,
+		api:   api,
+		clock: clock,
+	}
+}
+
+// ReconcilePolicyExemptionRule reconciles a policy exemption rule from a validation config.
+func (s *PolicyExemptionRuleService) ReconcilePolicyExemptionRule(rule v1alpha1.PolicyExemptionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this policy exemption rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.PolicyExemptionSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypePolicyExemption
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypePolicyExemption, "scope", rule.Scope)
+	l.V(1).Info("Validating policy exemptions")
+	ev := &evidence{}
+	if err := s.validateExemptions(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate policy exemptions", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.PolicyExemptionFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateExemptions appends a failure for each expected exemption that doesn't apply to the scope
+// as required. A scope that doesn't exist is a failure, not an error.
+func (s *PolicyExemptionRuleService) validateExemptions(rule v1alpha1.PolicyExemptionRule, failures *[]string, ev *evidence) error {
+	exemptions, err := s.api.ListExemptionsForScope(rule.Scope)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ScopeNotFoundFailure, rule.Scope))
+			return nil
+		}
+		return fmt.Errorf("failed to list policy exemptions: %w", azure_errors.AsAugmented(err))
+	}
+	sortByID(exemptions, func(e *armpolicy.Exemption) *string { return e.ID })
+
+	ev.add("Examined %d policy exemption(s) that apply to scope %s.", len(exemptions), rule.Scope)
+	now := s.clock.Now()
+	for _, expected := range rule.Exemptions {
+		validateExemption(rule, expected, exemptions, now, failures, ev)
+	}
+	return nil
+}
+
+// validateExemption appends a failure if no exemption for the expected policy assignment applies,
+// if none of those that do has the expected category, or if the one of them that remains valid the
+// longest doesn't remain valid for long enough.
+func validateExemption(rule v1alpha1.PolicyExemptionRule, expected v1alpha1.ExpectedPolicyExemption, exemptions []*armpolicy.Exemption, now time.Time, failures *[]string, ev *evidence) {
+	var matching, categorized []*armpolicy.Exemption
+	for _, e := range exemptions {
+		if e == nil || e.Properties == nil || e.Properties.PolicyAssignmentID == nil {
+			continue
+		}
+		if !strings.EqualFold(*e.Properties.PolicyAssignmentID, expected.PolicyAssignmentID) {
+			continue
+		}
+		matching = append(matching, e)
+		if e.Properties.ExemptionCategory != nil && strings.EqualFold(string(*e.Properties.ExemptionCategory), expected.Category) {
+			categorized = append(categorized, e)
+		}
+	}
+	if len(matching) == 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.PolicyExemptionMissingFailure, expected.PolicyAssignmentID, rule.Scope))
+		return
+	}
+	if len(categorized) == 0 {
+		e := matching[0]
+		category := notSet
+		if e.Properties.ExemptionCategory != nil {
+			category = string(*e.Properties.ExemptionCategory)
+		}
+		*failures = append(*failures, fmt.Sprintf(messages.PolicyExemptionCategoryFailure, exemptionID(e), expected.PolicyAssignmentID, category, expected.Category))
+		return
+	}
+
+	// Exemptions without an expiry remain valid the longest.
+	sort.SliceStable(categorized, func(i, j int) bool {
+		a, b := categorized[i].Properties.ExpiresOn, categorized[j].Properties.ExpiresOn
+		return a == nil && b != nil || a != nil && b != nil && a.After(*b)
+	})
+	e := categorized[0]
+	if e.Properties.ExpiresOn == nil {
+		ev.add("Policy exemption %s for policy assignment %s has category %s and doesn't expire.", exemptionID(e), expected.PolicyAssignmentID, expected.Category)
+		return
+	}
+	expires := e.Properties.ExpiresOn.UTC()
+	ev.add("Policy exemption %s for policy assignment %s has category %s and expires at %s.", exemptionID(e), expected.PolicyAssignmentID, expected.Category, expires.Format(time.RFC3339))
+	switch remaining := expires.Sub(now); {
+	case remaining <= 0:
+		*failures = append(*failures, fmt.Sprintf(messages.PolicyExemptionExpiredFailure, exemptionID(e), expected.PolicyAssignmentID, expires.Format(time.RFC3339)))
+	case rule.MinRemainingValidity != nil && remaining < rule.MinRemainingValidity.Duration:
+		*failures = append(*failures, fmt.Sprintf(messages.PolicyExemptionExpiringFailure, exemptionID(e), expected.PolicyAssignmentID, expires.Format(time.RFC3339), rule.MinRemainingValidity.Duration))
+	}
+}
+
+func exemptionID(e *armpolicy.Exemption) string {
+	if e.ID == nil {
+		return "(unknown ID)"
+	}
+	return *e.ID
+}
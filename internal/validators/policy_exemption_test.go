@@ -0,0 +1,203 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testPolicyScope            = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+	testDenyPublicIPAssignment = "/subscriptions/00000000-0000-0000-0000-000000000000/providers/Microsoft.Authorization/policyAssignments/deny-public-ip"
+	testAllowedSKUsAssignment  = "/providers/Microsoft.Management/managementGroups/mg/providers/Microsoft.Authorization/policyAssignments/allowed-skus"
+)
+
+// policyExemptionAPIMock is a fake policy exemptions client with the exemptions in data that apply
+// to testPolicyScope. Listing the exemptions of any other scope fails with a 404, unless err is
+// set.
+type policyExemptionAPIMock struct {
+	data []*armpolicy.Exemption
+	err  error
+}
+
+func (m policyExemptionAPIMock) ListExemptionsForScope(scope string) ([]*armpolicy.Exemption, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if scope != testPolicyScope {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceGroupNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+// newExemption returns an exemption named name from a policy assignment. It doesn't expire if
+// expires is nil.
+func newExemption(name, policyAssignmentID string, category armpolicy.ExemptionCategory, expires *time.Time) *armpolicy.Exemption {
+	return &armpolicy.Exemption{
+		ID:   util.Ptr(testPolicyScope + "/providers/Microsoft.Authorization/policyExemptions/" + name),
+		Name: util.Ptr(name),
+		Properties: &armpolicy.ExemptionProperties{
+			PolicyAssignmentID: util.Ptr(policyAssignmentID),
+			ExemptionCategory:  util.Ptr(category),
+			ExpiresOn:          expires,
+		},
+	}
+}
+
+func TestPolicyExemptionRuleService_ReconcilePolicyExemptionRule(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	minValidity := &metav1.Duration{Duration: 30 * 24 * time.Hour}
+	exemptionID := func(name string) string {
+		return testPolicyScope + "/providers/Microsoft.Authorization/policyExemptions/" + name
+	}
+
+	tests := []struct {
+		name         string
+		exemptions   []*armpolicy.Exemption
+		scope        string
+		expected     []v1alpha1.ExpectedPolicyExemption
+		wantFailures []string
+	}{
+		{
+			name: "Passes when all exemptions apply with the expected category, including one that doesn't expire.",
+			exemptions: []*armpolicy.Exemption{
+				newExemption("public-ip", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryWaiver, nil),
+				newExemption("skus", testAllowedSKUsAssignment, armpolicy.ExemptionCategoryMitigated, util.Ptr(now.AddDate(1, 0, 0))),
+			},
+			expected: []v1alpha1.ExpectedPolicyExemption{
+				{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"},
+				{PolicyAssignmentID: strings.ToUpper(testAllowedSKUsAssignment), Category: "Mitigated"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when no exemption for the policy assignment applies.",
+			exemptions: []*armpolicy.Exemption{
+				newExemption("skus", testAllowedSKUsAssignment, armpolicy.ExemptionCategoryWaiver, nil),
+			},
+			expected:     []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+			wantFailures: []string{"No policy exemption for policy assignment " + testDenyPublicIPAssignment + " applies to scope " + testPolicyScope + "."},
+		},
+		{
+			name: "Fails when the exemption has the wrong category.",
+			exemptions: []*armpolicy.Exemption{
+				newExemption("public-ip", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryMitigated, nil),
+			},
+			expected:     []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+			wantFailures: []string{"Policy exemption " + exemptionID("public-ip") + " for policy assignment " + testDenyPublicIPAssignment + " has category Mitigated, but category Waiver is required."},
+		},
+		{
+			name: "Fails when the exemption has expired.",
+			exemptions: []*armpolicy.Exemption{
+				newExemption("public-ip", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryWaiver, util.Ptr(now.Add(-time.Hour))),
+			},
+			expected:     []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+			wantFailures: []string{"Policy exemption " + exemptionID("public-ip") + " for policy assignment " + testDenyPublicIPAssignment + " expired at 2023-12-31T23:00:00Z."},
+		},
+		{
+			name: "Fails when the exemption expires sooner than the minimum remaining validity.",
+			exemptions: []*armpolicy.Exemption{
+				newExemption("public-ip", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryWaiver, util.Ptr(now.AddDate(0, 0, 10))),
+			},
+			expected:     []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+			wantFailures: []string{"Policy exemption " + exemptionID("public-ip") + " for policy assignment " + testDenyPublicIPAssignment + " expires at 2024-01-11T00:00:00Z, which is sooner than the required minimum remaining validity of 720h0m0s."},
+		},
+		{
+			name: "Passes when another exemption with the expected category remains valid for long enough.",
+			exemptions: []*armpolicy.Exemption{
+				newExemption("public-ip-1", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryWaiver, util.Ptr(now.AddDate(0, 0, 10))),
+				newExemption("public-ip-2", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryMitigated, nil),
+				newExemption("public-ip-3", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryWaiver, util.Ptr(now.AddDate(0, 6, 0))),
+			},
+			expected:     []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the scope doesn't exist.",
+			scope:        "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/missing",
+			expected:     []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+			wantFailures: []string{"Scope /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/missing not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewPolicyExemptionRuleService(logr.Discard(), policyExemptionAPIMock{data: tt.exemptions}, clocktesting.NewFakePassiveClock(now))
+			scope := tt.scope
+			if scope == "" {
+				scope = testPolicyScope
+			}
+
+			result, err := svc.ReconcilePolicyExemptionRule(v1alpha1.PolicyExemptionRule{
+				Name:                 "rule-1",
+				Scope:                scope,
+				Exemptions:           tt.expected,
+				MinRemainingValidity: minValidity,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestPolicyExemptionRuleService_ReconcilePolicyExemptionRule_NoMinRemainingValidity(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	api := policyExemptionAPIMock{data: []*armpolicy.Exemption{
+		newExemption("public-ip", testDenyPublicIPAssignment, armpolicy.ExemptionCategoryWaiver, util.Ptr(now.Add(time.Hour))),
+	}}
+	svc := NewPolicyExemptionRuleService(logr.Discard(), api, clocktesting.NewFakePassiveClock(now))
+
+	// Without a minimum remaining validity, exemptions pass until they expire.
+	result, err := svc.ReconcilePolicyExemptionRule(v1alpha1.PolicyExemptionRule{
+		Name:       "rule-1",
+		Scope:      testPolicyScope,
+		Exemptions: []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *result.State != vapi.ValidationSucceeded {
+		t.Errorf("got state %s with failures %v, want %s", *result.State, result.Condition.Failures, vapi.ValidationSucceeded)
+	}
+}
+
+func TestPolicyExemptionRuleService_ReconcilePolicyExemptionRule_Error(t *testing.T) {
+	api := policyExemptionAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewPolicyExemptionRuleService(logr.Discard(), api, clocktesting.NewFakePassiveClock(time.Now()))
+
+	result, err := svc.ReconcilePolicyExemptionRule(v1alpha1.PolicyExemptionRule{
+		Name:       "rule-1",
+		Scope:      testPolicyScope,
+		Exemptions: []v1alpha1.ExpectedPolicyExemption{{PolicyAssignmentID: testDenyPublicIPAssignment, Category: "Waiver"}},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,132 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	actionReadRoleAssignments = "Microsoft.Authorization/roleAssignments/read"
+	actionReadRoleDefinitions = "Microsoft.Authorization/roleDefinitions/read"
+
+	// readerRoleID is the ID of the built-in Reader role, which exists at every scope. Reading it
+	// proves that role definitions can be read there.
+	readerRoleID = "acdd72a7-3385-48ef-bd42-f606fba81ae7"
+)
+
+// errUnknownIdentity is returned when the plugin's identity is needed but couldn't be determined.
+var errUnknownIdentity = errors.New("the plugin's identity couldn't be determined")
+
+type PreflightService struct {
+	log             logr.Logger
+	permAPI         permissionAPI
+	raAPI           roleAssignmentAPI
+	rdAPI           roleDefinitionAPI
+	selfPrincipalID string
+}
+
+// NewPreflightService creates a PreflightService for the plugin's own identity, selfPrincipalID,
+// which is empty if it couldn't be determined.
+func NewPreflightService(log logr.Logger, permAPI permissionAPI, raAPI roleAssignmentAPI, rdAPI roleDefinitionAPI, selfPrincipalID string) *PreflightService {
+	return &PreflightService{
+		log:             log,
+		permAPI:         permAPI,
+		raAPI:           raAPI,
+		rdAPI:           rdAPI,
+		selfPrincipalID: selfPrincipalID,
+	}
+}
+
+// ReconcilePreflight checks that the plugin's identity can read role assignments and role
+// definitions at each scope, so that RBAC and template permission rules at the scopes fail with a
+// clear reason if it can't, rather than with authorization errors. All scopes are reported in a
+// single condition.
+func (s *PreflightService) ReconcilePreflight(scopes []string) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for the preflight check.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.PreflightSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, constants.PreflightRuleName)
+	latestCondition.ValidationType = constants.ValidationTypePreflight
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("validationType", constants.ValidationTypePreflight)
+	ev := &evidence{}
+	for _, scope := range scopes {
+		sl := l.WithValues("scope", scope)
+		sl.V(1).Info("Checking plugin's access")
+		lacking, err := s.lackingActions(scope, ev)
+		if err != nil {
+			return validationResult, recordError(sl, "failed to check plugin's access", err, &latestCondition)
+		}
+		if len(lacking) > 0 {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf(messages.PreflightFailure, strings.Join(lacking, ", "), scope))
+		}
+	}
+
+	ev.addRequestIDs(s.permAPI, s.raAPI, s.rdAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.PreflightFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// lackingActions returns which of the Actions needed to read role assignments and role
+// definitions the plugin lacks at a scope. At resource groups and resources, they're checked
+// against the plugin's effective permissions. ARM doesn't report those at subscriptions and
+// management groups, so there, the plugin's role assignments and the Reader role are read instead,
+// and the Actions whose reads are forbidden are lacking.
+func (s *PreflightService) lackingActions(scope string, ev *evidence) ([]string, error) {
+	if hasEffectivePermissions(scope) {
+		permissions, err := s.permAPI.ListPermissionsForScope(scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get effective permissions: %w", azure_errors.AsAugmented(err))
+		}
+		ev.add("Examined %d effective permission(s) of the plugin at scope %s.", len(permissions), scope)
+		actions, _, err := uncoveredActions(permissions, []string{actionReadRoleAssignments, actionReadRoleDefinitions}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return actions.unpermitted, nil
+	}
+
+	if s.selfPrincipalID == "" {
+		return nil, errUnknownIdentity
+	}
+	var lacking []string
+	filter := util.Ptr(azure_utils.RoleAssignmentFilter(s.selfPrincipalID, false))
+	if _, err := s.raAPI.GetRoleAssignmentsForScope(scope, filter); err != nil {
+		if azure_errors.Classify(err) != azure_errors.CategoryForbidden {
+			return nil, fmt.Errorf("failed to get role assignments: %w", azure_errors.AsAugmented(err))
+		}
+		lacking = append(lacking, actionReadRoleAssignments)
+	}
+	if _, err := s.rdAPI.GetByID(fmt.Sprintf("%s/providers/Microsoft.Authorization/roleDefinitions/%s", scope, readerRoleID)); err != nil {
+		if azure_errors.Classify(err) != azure_errors.CategoryForbidden {
+			return nil, fmt.Errorf("failed to get role definition: %w", azure_errors.AsAugmented(err))
+		}
+		lacking = append(lacking, actionReadRoleDefinitions)
+	}
+	ev.add("Checked the plugin's access at scope %s by reading its role assignments and the Reader role definition there.", scope)
+	return lacking, nil
+}
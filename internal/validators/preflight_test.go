@@ -0,0 +1,139 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// forbiddenAPI is a raAPI and rdAPI implementation for testing that forbids reading role
+// assignments or role definitions at scopes with some prefixes, and records the scopes and role IDs
+// that it's called with.
+type forbiddenAPI struct {
+	// keys = scope prefixes
+	forbidRoleAssignments map[string]bool
+	forbidRoleDefinitions map[string]bool
+	err                   error
+
+	calls []string
+}
+
+func (api *forbiddenAPI) forbidden(scope string, prefixes map[string]bool) error {
+	if api.err != nil {
+		return api.err
+	}
+	for prefix := range prefixes {
+		if strings.HasPrefix(scope, prefix) {
+			return &azcore.ResponseError{StatusCode: http.StatusForbidden, ErrorCode: "AuthorizationFailed"}
+		}
+	}
+	return nil
+}
+
+func (api *forbiddenAPI) GetRoleAssignmentsForScope(scope string, _ *string) ([]*armauthorization.RoleAssignment, error) {
+	api.calls = append(api.calls, scope)
+	return nil, api.forbidden(scope, api.forbidRoleAssignments)
+}
+
+func (api *forbiddenAPI) GetByID(roleID string) (*armauthorization.RoleDefinition, error) {
+	api.calls = append(api.calls, roleID)
+	return &armauthorization.RoleDefinition{}, api.forbidden(roleID, api.forbidRoleDefinitions)
+}
+
+func TestPreflightService_ReconcilePreflight(t *testing.T) {
+	const (
+		subA = "/subscriptions/00000000-0000-0000-0000-00000000000a"
+		subB = "/subscriptions/00000000-0000-0000-0000-00000000000b"
+		subC = "/subscriptions/00000000-0000-0000-0000-00000000000c"
+		rgC  = subC + "/resourceGroups/rg"
+		mg   = "/providers/Microsoft.Management/managementGroups/mg"
+	)
+	api := &forbiddenAPI{
+		forbidRoleAssignments: map[string]bool{subB: true},
+		forbidRoleDefinitions: map[string]bool{subB: true, mg: true},
+	}
+	permAPI := permissionAPIMock{
+		data: map[string][]*armauthorization.Permission{
+			rgC: {{
+				Actions:    []*string{util.Ptr("Microsoft.Authorization/*/read")},
+				NotActions: []*string{util.Ptr("Microsoft.Authorization/roleDefinitions/*")},
+			}},
+		},
+		correlationIDs: []string{"c-1"},
+	}
+
+	svc := NewPreflightService(logr.Discard(), permAPI, api, api, "self_id")
+	result, err := svc.ReconcilePreflight([]string{subA, subB, rgC, mg})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantFailures := []string{
+		"Plugin lacks Microsoft.Authorization/roleAssignments/read, Microsoft.Authorization/roleDefinitions/read at scope " + subB + ".",
+		"Plugin lacks Microsoft.Authorization/roleDefinitions/read at scope " + rgC + ".",
+		"Plugin lacks Microsoft.Authorization/roleDefinitions/read at scope " + mg + ".",
+	}
+	if !reflect.DeepEqual(result.Condition.Failures, wantFailures) {
+		t.Errorf("got failures %v, want %v", result.Condition.Failures, wantFailures)
+	}
+	wantDetails := []string{
+		"ARM request IDs: c-1",
+		"Checked the plugin's access at scope " + subA + " by reading its role assignments and the Reader role definition there.",
+		"Checked the plugin's access at scope " + subB + " by reading its role assignments and the Reader role definition there.",
+		"Examined 1 effective permission(s) of the plugin at scope " + rgC + ".",
+		"Checked the plugin's access at scope " + mg + " by reading its role assignments and the Reader role definition there.",
+	}
+	if !reflect.DeepEqual(result.Condition.Details, wantDetails) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, wantDetails)
+	}
+	wantCalls := []string{
+		subA, subA + "/providers/Microsoft.Authorization/roleDefinitions/" + readerRoleID,
+		subB, subB + "/providers/Microsoft.Authorization/roleDefinitions/" + readerRoleID,
+		mg, mg + "/providers/Microsoft.Authorization/roleDefinitions/" + readerRoleID,
+	}
+	if !reflect.DeepEqual(api.calls, wantCalls) {
+		t.Errorf("got calls %v, want %v", api.calls, wantCalls)
+	}
+	if want := messages.PreflightFailed; result.Condition.Message != want {
+		t.Errorf("got message %q, want %q", result.Condition.Message, want)
+	}
+}
+
+func TestPreflightService_ReconcilePreflight_Error(t *testing.T) {
+	tests := []struct {
+		name        string
+		principalID string
+		api         *forbiddenAPI
+	}{
+		{
+			name:        "Reads at subscriptions fail with errors other than authorization errors.",
+			principalID: "self_id",
+			api:         &forbiddenAPI{err: &azcore.ResponseError{StatusCode: http.StatusInternalServerError}},
+		},
+		{
+			name: "The plugin's identity is unknown.",
+			api:  &forbiddenAPI{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewPreflightService(logr.Discard(), permissionAPIMock{err: errors.New("unexpected call")}, tt.api, tt.api, tt.principalID)
+			result, err := svc.ReconcilePreflight([]string{"/subscriptions/00000000-0000-0000-0000-000000000000"})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(result.Condition.Failures) != 0 {
+				t.Errorf("got failures %v, want none", result.Condition.Failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// colocationStatusAligned is the colocation status of a proximity placement group whose members
+// are all in the same data center.
+const colocationStatusAligned = "Aligned"
+
+// proximityPlacementGroupAPI contains methods that allow getting a proximity placement group, with
+// its colocation status, by its resource ID.
+type proximityPlacementGroupAPI interface {
+	GetProximityPlacementGroup(ppgID string) (*armcompute.ProximityPlacementGroup, error)
+}
+
+type ProximityPlacementGroupRuleService struct {
+	log logr.Logger
+	api proximityPlacementGroupAPI
+}
+
+func NewProximityPlacementGroupRuleService(log logr.Logger, api proximityPlacementGroupAPI) *ProximityPlacementGroupRuleService {
+	return &ProximityPlacementGroupRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileProximityPlacementGroupRule reconciles a proximity placement group rule from a
+// validation config.
+func (s *ProximityPlacementGroupRuleService) ReconcileProximityPlacementGroupRule(rule v1alpha1.ProximityPlacementGroupRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this proximity placement group rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ProximityPlacementGroupSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeProximityPlacementGroup
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeProximityPlacementGroup, "proximityPlacementGroupID", rule.ProximityPlacementGroupID)
+	l.V(1).Info("Validating proximity placement group")
+	ev := &evidence{}
+	if err := s.validateProximityPlacementGroup(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate proximity placement group", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ProximityPlacementGroupFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateProximityPlacementGroup appends a failure if the proximity placement group isn't in the
+// rule's zone, for each of the rule's VM sizes that the group's intent doesn't have, and if its
+// colocation status isn't Aligned. Proximity placement groups have no provisioning state, so a
+// group that exists is provisioned. A group that doesn't exist is a failure, not an error.
+func (s *ProximityPlacementGroupRuleService) validateProximityPlacementGroup(rule v1alpha1.ProximityPlacementGroupRule, failures *[]string, ev *evidence) error {
+	ppg, err := s.api.GetProximityPlacementGroup(rule.ProximityPlacementGroupID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ProximityPlacementGroupNotFoundFailure, rule.ProximityPlacementGroupID))
+			return nil
+		}
+		return fmt.Errorf("failed to get proximity placement group: %w", azure_errors.AsAugmented(err))
+	}
+
+	zones := derefAll(ppg.Zones)
+	if rule.Zone != "" && !slices.Contains(zones, rule.Zone) {
+		*failures = append(*failures, fmt.Sprintf(messages.ProximityPlacementGroupZoneFailure, rule.ProximityPlacementGroupID, joinOrNone(zones), rule.Zone))
+	}
+
+	props := ppg.Properties
+	if props == nil {
+		props = &armcompute.ProximityPlacementGroupProperties{}
+	}
+
+	// A group without an intent doesn't constrain the VM sizes deployed into it.
+	var intentSizes []string
+	if props.Intent != nil {
+		intentSizes = derefAll(props.Intent.VMSizes)
+	}
+	if len(intentSizes) > 0 {
+		for _, size := range missingItems(rule.VMSizes, intentSizes, strings.ToLower) {
+			*failures = append(*failures, fmt.Sprintf(messages.ProximityPlacementGroupVMSizeFailure, rule.ProximityPlacementGroupID, size, strings.Join(intentSizes, ", ")))
+		}
+	}
+
+	status, message := colocationStatus(props.ColocationStatus)
+	if status != colocationStatusAligned {
+		failure := fmt.Sprintf(messages.ProximityPlacementGroupColocationFailure, rule.ProximityPlacementGroupID, status, colocationStatusAligned)
+		if message != "" {
+			failure += " " + message
+		}
+		*failures = append(*failures, failure)
+	}
+
+	ev.add("Proximity placement group %s is in zone(s) %s, has intended VM sizes %s, and has colocation status %s.", rule.ProximityPlacementGroupID, joinOrNone(zones), joinOrNone(intentSizes), status)
+	return nil
+}
+
+// colocationStatus returns the status, e.g. Aligned, from the code of a colocation status, e.g.
+// ColocationStatus/Aligned, and its message.
+func colocationStatus(s *armcompute.InstanceViewStatus) (status, message string) {
+	if s == nil || s.Code == nil {
+		return notSet, ""
+	}
+	_, status, _ = strings.Cut(*s.Code, "/")
+	if status == "" {
+		status = *s.Code
+	}
+	if s.Message != nil {
+		message = *s.Message
+	}
+	return status, message
+}
@@ -0,0 +1,150 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testPPGID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Compute/proximityPlacementGroups/ppg-1"
+
+// proximityPlacementGroupAPIMock is a fake ARM with the proximity placement groups in its map,
+// keyed by resource ID. Getting any other group fails with a 404, unless err is set.
+type proximityPlacementGroupAPIMock struct {
+	groups map[string]*armcompute.ProximityPlacementGroup
+	err    error
+}
+
+func (m proximityPlacementGroupAPIMock) GetProximityPlacementGroup(ppgID string) (*armcompute.ProximityPlacementGroup, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.groups, ppgID)
+}
+
+// newProximityPlacementGroup returns a proximity placement group in zone 1 that intends to have
+// D-series VMs, with a colocation status.
+func newProximityPlacementGroup(code, message string) *armcompute.ProximityPlacementGroup {
+	return &armcompute.ProximityPlacementGroup{
+		Zones: to.SliceOfPtrs("1"),
+		Properties: &armcompute.ProximityPlacementGroupProperties{
+			Intent: &armcompute.ProximityPlacementGroupPropertiesIntent{
+				VMSizes: to.SliceOfPtrs("Standard_D4s_v3", "Standard_D8s_v3"),
+			},
+			ColocationStatus: &armcompute.InstanceViewStatus{
+				Code:    util.Ptr(code),
+				Message: util.Ptr(message),
+			},
+		},
+	}
+}
+
+func TestProximityPlacementGroupRuleService_ReconcileProximityPlacementGroupRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		group        *armcompute.ProximityPlacementGroup
+		rule         v1alpha1.ProximityPlacementGroupRule
+		wantFailures []string
+	}{
+		{
+			name:  "Passes when the group is aligned, in the zone, and intends to have the VM sizes.",
+			group: newProximityPlacementGroup("ColocationStatus/Aligned", "All resources in the proximity placement group are aligned."),
+			rule: v1alpha1.ProximityPlacementGroupRule{
+				Zone:    "1",
+				VMSizes: []string{"standard_d4s_v3", "Standard_D8s_v3"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when the group is aligned and has no intent.",
+			group: &armcompute.ProximityPlacementGroup{
+				Properties: &armcompute.ProximityPlacementGroupProperties{
+					ColocationStatus: &armcompute.InstanceViewStatus{Code: util.Ptr("ColocationStatus/Aligned")},
+				},
+			},
+			rule:         v1alpha1.ProximityPlacementGroupRule{VMSizes: []string{"Standard_E4s_v5"}},
+			wantFailures: []string{},
+		},
+		{
+			name:  "Fails with Azure's message when the group isn't aligned.",
+			group: newProximityPlacementGroup("ColocationStatus/NotAligned", "Virtual machine vm-2 is not aligned."),
+			wantFailures: []string{
+				"Proximity placement group " + testPPGID + " has colocation status NotAligned, not Aligned. Virtual machine vm-2 is not aligned.",
+			},
+		},
+		{
+			name:  "Fails when the group reports no colocation status.",
+			group: &armcompute.ProximityPlacementGroup{},
+			wantFailures: []string{
+				"Proximity placement group " + testPPGID + " has colocation status (not set), not Aligned.",
+			},
+		},
+		{
+			name:  "Fails when the group is in another zone and doesn't intend to have a VM size.",
+			group: newProximityPlacementGroup("ColocationStatus/Aligned", ""),
+			rule: v1alpha1.ProximityPlacementGroupRule{
+				Zone:    "2",
+				VMSizes: []string{"Standard_D4s_v3", "Standard_E4s_v5"},
+			},
+			wantFailures: []string{
+				"Proximity placement group " + testPPGID + " is in zone(s) 1, not 2.",
+				"Proximity placement group " + testPPGID + " doesn't allow VM size Standard_E4s_v5. Its intended VM sizes are Standard_D4s_v3, Standard_D8s_v3.",
+			},
+		},
+		{
+			name:         "Fails when the group is missing.",
+			wantFailures: []string{"Proximity placement group " + testPPGID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := proximityPlacementGroupAPIMock{groups: map[string]*armcompute.ProximityPlacementGroup{}}
+			if tt.group != nil {
+				api.groups[testPPGID] = tt.group
+			}
+			svc := NewProximityPlacementGroupRuleService(logr.Discard(), api)
+
+			rule := tt.rule
+			rule.Name = "rule-1"
+			rule.ProximityPlacementGroupID = testPPGID
+			result, err := svc.ReconcileProximityPlacementGroupRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestProximityPlacementGroupRuleService_ReconcileProximityPlacementGroupRule_Error(t *testing.T) {
+	api := proximityPlacementGroupAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewProximityPlacementGroupRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileProximityPlacementGroupRule(v1alpha1.ProximityPlacementGroupRule{Name: "rule-1", ProximityPlacementGroupID: testPPGID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
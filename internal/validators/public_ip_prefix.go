@@ -0,0 +1,154 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// publicIPPrefixAPI contains methods that allow getting a public IP prefix by its resource ID.
+type publicIPPrefixAPI interface {
+	GetPublicIPPrefix(publicIPPrefixID string) (*armnetwork.PublicIPPrefix, error)
+}
+
+type PublicIPPrefixRuleService struct {
+	log logr.Logger
+	api publicIPPrefixAPI
+}
+
+func NewPublicIPPrefixRuleService(log logr.Logger, api publicIPPrefixAPI) *PublicIPPrefixRuleService {
+	return &PublicIPPrefixRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcilePublicIPPrefixRule reconciles a public IP prefix rule from a validation config.
+func (s *PublicIPPrefixRuleService) ReconcilePublicIPPrefixRule(rule v1alpha1.PublicIPPrefixRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this public IP prefix rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.PublicIPPrefixSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypePublicIPPrefix
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypePublicIPPrefix, "publicIPPrefixID", rule.PublicIPPrefixID)
+	l.V(1).Info("Validating public IP prefix")
+	ev := &evidence{}
+	if err := s.validatePublicIPPrefix(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate public IP prefix", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.PublicIPPrefixFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validatePublicIPPrefix appends a failure if the prefix has fewer free addresses than the rule
+// requires, or isn't in the rule's zones or doesn't have its SKU tier. A prefix that doesn't exist
+// is a failure, not an error.
+func (s *PublicIPPrefixRuleService) validatePublicIPPrefix(rule v1alpha1.PublicIPPrefixRule, failures *[]string, ev *evidence) error {
+	prefix, err := s.api.GetPublicIPPrefix(rule.PublicIPPrefixID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.PublicIPPrefixNotFoundFailure, rule.PublicIPPrefixID))
+			return nil
+		}
+		return fmt.Errorf("failed to get public IP prefix: %w", azure_errors.AsAugmented(err))
+	}
+	props := prefix.Properties
+	if props == nil {
+		props = &armnetwork.PublicIPPrefixPropertiesFormat{}
+	}
+
+	if total, ok := publicIPPrefixSize(props); ok {
+		// Unlike subnets, public IP prefixes don't reserve any of their addresses, so each one that
+		// isn't allocated to a public IP address is free.
+		allocated := uint64(len(props.PublicIPAddresses))
+		free := uint64(0)
+		if total > allocated {
+			free = total - allocated
+		}
+		ev.add("Public IP prefix %s has %d free address(es) of %d.", rule.PublicIPPrefixID, free, total)
+		if free < uint64(rule.MinFreeAddresses) {
+			*failures = append(*failures, fmt.Sprintf(messages.PublicIPPrefixFreeAddressesFailure, rule.PublicIPPrefixID, free, total, rule.MinFreeAddresses))
+		}
+	} else {
+		*failures = append(*failures, fmt.Sprintf(messages.PublicIPPrefixNoPrefixFailure, rule.PublicIPPrefixID))
+	}
+
+	zones := derefAll(prefix.Zones)
+	var missing []string
+	for _, zone := range rule.Zones {
+		if !slices.Contains(zones, zone) {
+			missing = append(missing, zone)
+		}
+	}
+	if len(missing) > 0 {
+		actual := "no zones"
+		if len(zones) > 0 {
+			actual = "zone(s) " + strings.Join(zones, ", ")
+		}
+		*failures = append(*failures, fmt.Sprintf(messages.PublicIPPrefixZonesFailure, rule.PublicIPPrefixID, strings.Join(missing, ", "), actual))
+	}
+
+	if rule.SKUTier != "" {
+		tier := notSet
+		if prefix.SKU != nil && prefix.SKU.Tier != nil {
+			tier = string(*prefix.SKU.Tier)
+		}
+		if !strings.EqualFold(tier, rule.SKUTier) {
+			*failures = append(*failures, fmt.Sprintf(messages.PublicIPPrefixSKUTierFailure, rule.PublicIPPrefixID, tier, rule.SKUTier))
+		}
+	}
+	return nil
+}
+
+// publicIPPrefixSize returns the number of addresses in a public IP prefix, from its CIDR, or from
+// its prefix length and IP version if ARM hasn't assigned it a CIDR yet.
+func publicIPPrefixSize(props *armnetwork.PublicIPPrefixPropertiesFormat) (uint64, bool) {
+	if props.IPPrefix != nil {
+		if p, err := netip.ParsePrefix(strings.TrimSpace(*props.IPPrefix)); err == nil {
+			return prefixSize(p), true
+		}
+	}
+	if props.PrefixLength == nil {
+		return 0, false
+	}
+	addr := netip.IPv4Unspecified()
+	if props.PublicIPAddressVersion != nil && *props.PublicIPAddressVersion == armnetwork.IPVersionIPv6 {
+		addr = netip.IPv6Unspecified()
+	}
+	p, err := addr.Prefix(int(*props.PrefixLength))
+	if err != nil {
+		return 0, false
+	}
+	return prefixSize(p), true
+}
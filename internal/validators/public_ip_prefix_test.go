@@ -0,0 +1,202 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// publicIPPrefixAPIMock is a fake ARM with the public IP prefixes in its map, keyed by resource ID.
+// Getting any other prefix fails with a 404, unless err is set.
+type publicIPPrefixAPIMock struct {
+	prefixes map[string]*armnetwork.PublicIPPrefix
+	err      error
+}
+
+func (m publicIPPrefixAPIMock) GetPublicIPPrefix(publicIPPrefixID string) (*armnetwork.PublicIPPrefix, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	prefix, ok := m.prefixes[publicIPPrefixID]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return prefix, nil
+}
+
+// newPublicIPPrefix returns a Regional public IP prefix in zones 1, 2, and 3, with a CIDR and
+// allocated public IP addresses.
+func newPublicIPPrefix(cidr string, allocated int) *armnetwork.PublicIPPrefix {
+	prefix := &armnetwork.PublicIPPrefix{
+		Properties: &armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr(cidr)},
+		SKU: &armnetwork.PublicIPPrefixSKU{
+			Name: util.Ptr(armnetwork.PublicIPPrefixSKUNameStandard),
+			Tier: util.Ptr(armnetwork.PublicIPPrefixSKUTierRegional),
+		},
+		Zones: []*string{util.Ptr("1"), util.Ptr("2"), util.Ptr("3")},
+	}
+	for i := 0; i < allocated; i++ {
+		prefix.Properties.PublicIPAddresses = append(prefix.Properties.PublicIPAddresses, &armnetwork.ReferencedPublicIPAddress{
+			ID: util.Ptr(fmt.Sprintf("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/ip-%d", i)),
+		})
+	}
+	return prefix
+}
+
+func TestPublicIPPrefixRuleService_ReconcilePublicIPPrefixRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefix       *armnetwork.PublicIPPrefix
+		rule         v1alpha1.PublicIPPrefixRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the prefix has exactly enough free addresses.",
+			prefix:       newPublicIPPrefix("20.0.0.0/28", 12),
+			rule:         v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 4},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the prefix is in the rule's zones and has its SKU tier, ignoring case.",
+			prefix:       newPublicIPPrefix("20.0.0.0/31", 0),
+			rule:         v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 2, Zones: []string{"1", "3"}, SKUTier: "regional"},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when ARM hasn't assigned a CIDR, using the prefix length.",
+			prefix: func() *armnetwork.PublicIPPrefix {
+				p := newPublicIPPrefix("", 1)
+				p.Properties.IPPrefix = nil
+				p.Properties.PrefixLength = util.Ptr(int32(124))
+				p.Properties.PublicIPAddressVersion = util.Ptr(armnetwork.IPVersionIPv6)
+				return p
+			}(),
+			rule:         v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 15},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails when the prefix has too few free addresses.",
+			prefix: newPublicIPPrefix("20.0.0.0/29", 6),
+			rule:   v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 3},
+			wantFailures: []string{
+				"Public IP prefix " + testIPPrefixID + " has 2 free address(es) of 8, fewer than the required 3.",
+			},
+		},
+		{
+			name:   "Fails when the prefix is exhausted.",
+			prefix: newPublicIPPrefix("20.0.0.0/30", 4),
+			rule:   v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 1},
+			wantFailures: []string{
+				"Public IP prefix " + testIPPrefixID + " has 0 free address(es) of 4, fewer than the required 1.",
+			},
+		},
+		{
+			name: "Fails when the prefix isn't in the rule's zones and has another SKU tier.",
+			prefix: func() *armnetwork.PublicIPPrefix {
+				p := newPublicIPPrefix("20.0.0.0/28", 0)
+				p.Zones = nil
+				p.SKU.Tier = util.Ptr(armnetwork.PublicIPPrefixSKUTierGlobal)
+				return p
+			}(),
+			rule: v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 1, Zones: []string{"1", "2"}, SKUTier: "Regional"},
+			wantFailures: []string{
+				"Public IP prefix " + testIPPrefixID + " isn't in zone(s) 1, 2: it's in no zones.",
+				"Public IP prefix " + testIPPrefixID + " has SKU tier Global, not Regional.",
+			},
+		},
+		{
+			name: "Fails when the prefix is in only some of the rule's zones.",
+			prefix: func() *armnetwork.PublicIPPrefix {
+				p := newPublicIPPrefix("20.0.0.0/28", 0)
+				p.Zones = []*string{util.Ptr("1")}
+				return p
+			}(),
+			rule: v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 1, Zones: []string{"1", "2"}},
+			wantFailures: []string{
+				"Public IP prefix " + testIPPrefixID + " isn't in zone(s) 2: it's in zone(s) 1.",
+			},
+		},
+		{
+			name:         "Fails when the prefix is missing.",
+			rule:         v1alpha1.PublicIPPrefixRule{MinFreeAddresses: 1},
+			wantFailures: []string{"Public IP prefix " + testIPPrefixID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes := map[string]*armnetwork.PublicIPPrefix{}
+			if tt.prefix != nil {
+				prefixes[testIPPrefixID] = tt.prefix
+			}
+			svc := NewPublicIPPrefixRuleService(logr.Discard(), publicIPPrefixAPIMock{prefixes: prefixes})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.PublicIPPrefixID = testIPPrefixID
+			result, err := svc.ReconcilePublicIPPrefixRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestPublicIPPrefixRuleService_ReconcilePublicIPPrefixRule_Error(t *testing.T) {
+	api := publicIPPrefixAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewPublicIPPrefixRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcilePublicIPPrefixRule(v1alpha1.PublicIPPrefixRule{Name: "rule-1", PublicIPPrefixID: testIPPrefixID, MinFreeAddresses: 1})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
+
+func Test_publicIPPrefixSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		props  armnetwork.PublicIPPrefixPropertiesFormat
+		want   uint64
+		wantOK bool
+	}{
+		{name: "/28", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("20.0.0.0/28")}, want: 16, wantOK: true},
+		{name: "/29", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("20.0.0.16/29")}, want: 8, wantOK: true},
+		{name: "/30", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("20.0.0.24/30")}, want: 4, wantOK: true},
+		{name: "/31", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("20.0.0.30/31")}, want: 2, wantOK: true},
+		{name: "IPv6 /124", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("2603:1030::/124")}, want: 16, wantOK: true},
+		{name: "IPv6 /127", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("2603:1030::/127")}, want: 2, wantOK: true},
+		{name: "prefix length without a CIDR", props: armnetwork.PublicIPPrefixPropertiesFormat{PrefixLength: util.Ptr(int32(30))}, want: 4, wantOK: true},
+		{name: "CIDR wins over prefix length", props: armnetwork.PublicIPPrefixPropertiesFormat{IPPrefix: util.Ptr("20.0.0.0/31"), PrefixLength: util.Ptr(int32(28))}, want: 2, wantOK: true},
+		{name: "neither", props: armnetwork.PublicIPPrefixPropertiesFormat{}, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := publicIPPrefixSize(&tt.props)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("publicIPPrefixSize() = %d, %t, want %d, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
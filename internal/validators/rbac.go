@@ -1,15 +1,21 @@
 package validators
 
 import (
+	"cmp"
 	"fmt"
-	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
 	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
 	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
 	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
@@ -35,16 +41,38 @@ type roleDefinitionAPI interface {
 }
 
 type RBACRuleService struct {
-	daAPI denyAssignmentAPI
-	raAPI roleAssignmentAPI
-	rdAPI roleDefinitionAPI
+	log      logr.Logger
+	daAPI    denyAssignmentAPI
+	raAPI    roleAssignmentAPI
+	rdAPI    roleDefinitionAPI
+	raCounts *RoleAssignmentCounts
+
+	// permAPI and selfPrincipalID are set by WithSelfCheck.
+	permAPI         permissionAPI
+	selfPrincipalID string
+	// rgAPI is set by WithResourceGroups.
+	rgAPI resourceGroupAPI
+	// mgAPI is set by WithManagementGroups.
+	mgAPI managementGroupAPI
+	// principalAPI is set by WithPrincipals.
+	principalAPI principalAPI
+	// now and graceEnd are set by WithGracePeriod.
+	now, graceEnd time.Time
 }
 
-func NewRBACRuleService(daAPI denyAssignmentAPI, raAPI roleAssignmentAPI, rdAPI roleDefinitionAPI) *RBACRuleService {
+// NewRBACRuleService creates an RBACRuleService. Subscriptions' role assignments are counted at most
+// once per raCounts, which may be shared with other RBACRuleServices. If raCounts is nil, the
+// RBACRuleService counts them itself.
+func NewRBACRuleService(log logr.Logger, daAPI denyAssignmentAPI, raAPI roleAssignmentAPI, rdAPI roleDefinitionAPI, raCounts *RoleAssignmentCounts) *RBACRuleService {
+	if raCounts == nil {
+		raCounts = NewRoleAssignmentCounts()
+	}
 	return &RBACRuleService{
-		daAPI: daAPI,
-		raAPI: raAPI,
-		rdAPI: rdAPI,
+		log:      log,
+		daAPI:    daAPI,
+		raAPI:    raAPI,
+		rdAPI:    rdAPI,
+		raCounts: raCounts,
 	}
 }
 
@@ -55,51 +83,243 @@ func (s *RBACRuleService) ReconcileRBACRule(rule v1alpha1.RBACRule) (*vapitypes.
 	state := vapi.ValidationSucceeded
 	latestCondition := vapi.DefaultValidationCondition()
 	latestCondition.Failures = []string{}
-	latestCondition.Message = "Principal has all required permissions."
+	latestCondition.Message = messages.RBACSucceeded
 	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
 	latestCondition.ValidationType = constants.ValidationTypeRBAC
 	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
 
-	for _, set := range rule.Permissions {
-		if err := s.processPermissionSet(set, rule.PrincipalID, &latestCondition.Failures); err != nil {
-			// Code this is returning to will take care of changing the validation result to a
-			// failed validation, using the error returned.
-			return validationResult, err
+	// Rules whose specs have problems fail without being evaluated. Only the number of problems
+	// is reported here; the spec condition lists them.
+	if errs := RBACRuleSpecErrors(rule, nil); len(errs) > 0 {
+		setSpecInvalid(&state, &latestCondition, errs)
+		return validationResult, nil
+	}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeRBAC)
+	ev := &evidence{}
+	msgs, err := messages.NewTemplates(rule.FailureMessageTemplate)
+	if err != nil {
+		return validationResult, azure_errors.Terminal(err)
+	}
+	sets, err := s.expandScopes(rule.Permissions, &latestCondition.Failures, ev)
+	if err != nil {
+		return validationResult, recordError(l, "failed to expand scopes of permission sets", err, &latestCondition)
+	}
+
+	// Each principal is validated against all of the permission sets. When there are several,
+	// their failures are told apart by starting with the principal. Only the failures of the
+	// permission sets are graced, as the others aren't about role assignments being created. A
+	// principal given by its client ID is validated by its object ID, if it can be resolved.
+	principals := rule.Principals()
+	if rule.PrincipalClientID != "" {
+		principalID, err := s.resolvePrincipalClientID(rule.PrincipalClientID, &latestCondition.Failures, ev)
+		if err != nil {
+			return validationResult, recordError(l.WithValues("principalClientID", rule.PrincipalClientID), "failed to resolve principal client ID", err, &latestCondition)
+		}
+		if principalID != "" {
+			principals = []string{principalID}
+		}
+	}
+	otherFailures := len(latestCondition.Failures)
+	for _, principalID := range principals {
+		q := newRBACQueries(principalID, rule.ExpandPrincipalGroups, rule.RawFilter)
+		selfChecks := s.selfChecks(rule, principalID, ev)
+		var principal *azure_utils.GraphDirectoryObject
+		if rule.VerifyPrincipal {
+			if principal, err = s.verifyPrincipal(principalID, ev); err != nil {
+				return validationResult, recordError(l.WithValues("principalID", principalID), "failed to verify principal", err, &latestCondition)
+			}
+		}
+		failures := &setFailures{}
+		for _, set := range sets {
+			sl := l.WithValues("principalID", principalID, "scope", set.Scope)
+			sl.V(1).Info("Processing permission set")
+			switch {
+			case selfChecks && set.HasAssignmentAssertions():
+				ev.add("Permission set at scope %s was validated against role assignments, because it has createdAfter, createdBefore, descriptionPattern, or condition.", set.Scope)
+				err = s.processPermissionSet(set, q, failures, ev)
+			case selfChecks && hasEffectivePermissions(set.Scope):
+				err = s.processSelfCheckPermissionSet(set.PermissionSet, q.principalID, failures, ev)
+			case selfChecks:
+				ev.add("Permission set at scope %s was validated against role assignments, because ARM only reports effective permissions at resource groups and resources.", set.Scope)
+				fallthrough
+			default:
+				err = s.processPermissionSet(set, q, failures, ev)
+			}
+			if err != nil {
+				// Code this is returning to will take care of changing the validation result to a
+				// failed validation, using the error returned.
+				return validationResult, recordError(sl, "failed to process permission set", err, &latestCondition)
+			}
+		}
+		if principal != nil && q.foundNoRoleAssignments() {
+			if err := s.checkRecreatedPrincipal(principal, q, ev); err != nil {
+				return validationResult, recordError(l.WithValues("principalID", principalID), "failed to look for re-created principal", err, &latestCondition)
+			}
+		}
+		for _, failure := range failures.render(msgs) {
+			if len(principals) > 1 {
+				failure = fmt.Sprintf(messages.PrincipalFailure, principalID, failure)
+			}
+			latestCondition.Failures = append(latestCondition.Failures, failure)
+		}
+	}
+
+	permissionFailures := len(latestCondition.Failures) - otherFailures
+
+	if rule.RoleAssignmentQuota != nil {
+		l.V(1).Info("Checking role assignment quota")
+		if err := s.checkRoleAssignmentQuota(rule, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l, "failed to check role assignment quota", err, &latestCondition)
 		}
 	}
 
+	ev.addRequestIDs(s.daAPI, s.raAPI, s.rdAPI, s.permAPI, s.rgAPI, s.mgAPI, s.principalAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
 	if len(latestCondition.Failures) > 0 {
-		state = vapi.ValidationFailed
-		latestCondition.Message = "Principal lacks required permissions. See failures for details."
-		latestCondition.Status = corev1.ConditionFalse
+		if len(latestCondition.Failures) == permissionFailures && s.withinGracePeriod() {
+			s.setPending(&state, &latestCondition)
+		} else {
+			state = vapi.ValidationFailed
+			latestCondition.Message = messages.RBACFailed
+			latestCondition.Status = corev1.ConditionFalse
+		}
 	}
 
 	return validationResult, nil
 }
 
-// processPermissionSet processes a permission set from the rule.
-func (s *RBACRuleService) processPermissionSet(set v1alpha1.PermissionSet, principalID string, failures *[]string) error {
+// recordError logs an error that stopped a rule's evaluation, and adds its category to the rule's
+// condition, along with the correlation request ID of the failed Azure request, if any, so that the
+// request can be found in Azure's logs. It returns the error with the requeue hint its category
+// implies, if any, for the rule service to return.
+func recordError(l logr.Logger, msg string, err error, condition *vapi.ValidationCondition) error {
+	correlationID := azure_errors.CorrelationID(err)
+	category := azure_errors.Classify(err)
+	if cerr, ok := azure_errors.CircuitOpen(err); ok {
+		// Every rule for the subscription fails like this until the circuit breaker allows
+		// requests again, and the errors that opened it have already been logged.
+		l.V(1).Info("Skipped Azure queries", "subscriptionID", cerr.SubscriptionID, "retryAt", cerr.RetryAt)
+	} else {
+		l.V(0).Error(err, msg, "correlationID", correlationID, "errorCategory", category)
+	}
+	condition.Details = append(condition.Details, fmt.Sprintf("Error category: %s", category))
+	if correlationID != "" {
+		condition.Details = append(condition.Details, fmt.Sprintf("Azure correlation request ID: %s", correlationID))
+	}
+	return azure_errors.WithRequeueHint(err)
+}
+
+// rbacQueries makes the Azure queries needed to evaluate a single rule. It caches their results,
+// because a rule's permission sets often share scopes, and role assignments at different scopes
+// often share role definitions.
+type rbacQueries struct {
+	principalID string
+	daFilter    *string
+	raFilter    *string
+	// rawFilter is whether raFilter is a rule's raw filter, which may also list the role
+	// assignments of other principals.
+	rawFilter bool
+	// raQuery describes how role assignments are queried, for evidence.
+	raQuery string
+	// raQueryNoted is whether raQuery has been recorded as evidence.
+	raQueryNoted bool
+
+	// keys = scopes
+	denyAssignments map[string][]*armauthorization.DenyAssignment
+	roleAssignments map[string][]*armauthorization.RoleAssignment
+	// keys = role definition IDs
+	roleDefinitions map[string]*armauthorization.RoleDefinition
+}
+
+// newRBACQueries creates the queries of a rule for a principal. If expandGroups, the role
+// assignments of the principal's groups are included. If rawFilter isn't empty, role assignments
+// are listed with it as is instead, and those of other principals are dropped.
+func newRBACQueries(principalID string, expandGroups bool, rawFilter string) *rbacQueries {
+	raFilter := azure_utils.RoleAssignmentFilter(principalID, expandGroups)
+	raQuery := fmt.Sprintf("Role assignments were listed with filter principalId eq '%s'.", principalID)
+	switch {
+	case rawFilter != "":
+		raFilter = rawFilter
+		raQuery = fmt.Sprintf("Role assignments were listed with raw filter %s, and only those of principal %s were kept.", rawFilter, principalID)
+	case expandGroups:
+		raQuery = fmt.Sprintf("Role assignments were listed with filter assignedTo('%s'), which includes those of the principal's groups.", principalID)
+	}
+	return &rbacQueries{
+		principalID: principalID,
+		// Note that in this filter, Azure checks "principalId" to make sure it's a UUID, so we
+		// don't need to escape the principal ID user input from the spec.
+		daFilter:        util.Ptr(fmt.Sprintf("principalId eq '%s'", principalID)),
+		raFilter:        util.Ptr(raFilter),
+		rawFilter:       rawFilter != "",
+		raQuery:         raQuery,
+		denyAssignments: map[string][]*armauthorization.DenyAssignment{},
+		roleAssignments: map[string][]*armauthorization.RoleAssignment{},
+		roleDefinitions: map[string]*armauthorization.RoleDefinition{},
+	}
+}
+
+// principalRoleAssignments returns the role assignments of a principal, dropping those of other
+// principals, along with how many were dropped.
+func principalRoleAssignments(roleAssignments []*armauthorization.RoleAssignment, principalID string) ([]*armauthorization.RoleAssignment, int) {
+	kept := make([]*armauthorization.RoleAssignment, 0, len(roleAssignments))
+	for _, ra := range roleAssignments {
+		if ra.Properties != nil && ra.Properties.PrincipalID != nil && strings.EqualFold(*ra.Properties.PrincipalID, principalID) {
+			kept = append(kept, ra)
+		}
+	}
+	return kept, len(roleAssignments) - len(kept)
+}
+
+// processPermissionSet processes a permission set from the rule, recording what it examined as
+// evidence, and adding its failures to failures.
+func (s *RBACRuleService) processPermissionSet(set scopedPermissionSet, q *rbacQueries, failures *setFailures, ev *evidence) error {
 
 	// Get all deny assignments and role assignments for specified scope and principal.
-	// Note that in this filter, Azure checks "principalId" to make sure it's a UUID, so we don't
-	// need to escape the principal ID user input from the spec.
-	daFilter := util.Ptr(fmt.Sprintf("principalId eq '%s'", principalID))
-	denyAssignments, err := s.daAPI.GetDenyAssignmentsForScope(set.Scope, daFilter)
-	if err != nil {
-		return fmt.Errorf("failed to get deny assignments: %w", azure_errors.AsAugmented(err))
+	denyAssignments, ok := q.denyAssignments[set.Scope]
+	if !ok {
+		var err error
+		if denyAssignments, err = s.daAPI.GetDenyAssignmentsForScope(set.Scope, q.daFilter); err != nil {
+			return fmt.Errorf("failed to get deny assignments: %w", azure_errors.AsAugmented(err))
+		}
+		sortByID(denyAssignments, func(da *armauthorization.DenyAssignment) *string { return da.ID })
+		q.denyAssignments[set.Scope] = denyAssignments
 	}
-	// Note that Azure's Go SDK for their API has a bug where it doesn't escape the filter string
-	// for the role assignments call we do here, so we manually escape it ourselves.
-	// https://github.com/Azure/azure-sdk-for-go/issues/20847
-	raFilter := util.Ptr(url.QueryEscape(fmt.Sprintf("principalId eq '%s'", principalID)))
-	roleAssignments, err := s.raAPI.GetRoleAssignmentsForScope(set.Scope, raFilter)
-	if err != nil {
-		return fmt.Errorf("failed to get role assignments: %w", azure_errors.AsAugmented(err))
+	roleAssignments, ok := q.roleAssignments[set.Scope]
+	dropped := 0
+	if !ok {
+		var err error
+		if roleAssignments, err = s.raAPI.GetRoleAssignmentsForScope(set.Scope, q.raFilter); err != nil {
+			return fmt.Errorf("failed to get role assignments: %w", azure_errors.AsAugmented(err))
+		}
+		if q.rawFilter {
+			roleAssignments, dropped = principalRoleAssignments(roleAssignments, q.principalID)
+		}
+		sortByID(roleAssignments, func(ra *armauthorization.RoleAssignment) *string { return ra.ID })
+		q.roleAssignments[set.Scope] = roleAssignments
+	}
+
+	if !q.raQueryNoted {
+		ev.add("%s", q.raQuery)
+		q.raQueryNoted = true
 	}
+	if dropped > 0 {
+		ev.add("Dropped %d role assignment(s) of other principals that the raw filter listed at scope %s.", dropped, set.Scope)
+	}
+	ev.add("Examined %d deny assignment(s) and %d role assignment(s) for principal %s at scope %s.", len(denyAssignments), len(roleAssignments), q.principalID, set.Scope)
 
 	// For each role assignment found, get its role definition, because that's what we actually need
-	// to do validation. We need to know which Actions and DataActions the role permits.
+	// to do validation. We need to know which Actions and DataActions the role permits. Role
+	// assignments with the same role permit the same Actions and DataActions, so each role
+	// definition is only processed once, along with the ID of the first role assignment with it.
+	// Permission sets with assertions about role assignments need every role assignment, though,
+	// since they differ in when they were created, their descriptions, and their conditions.
+	asserted := set.HasAssignmentAssertions()
 	roleDefinitions := []*armauthorization.RoleDefinition{}
+	roleAssignmentIDs := []string{}
+	var assigned []assignedRole
+	seen := make(map[string]bool, len(roleAssignments))
 	for _, ra := range roleAssignments {
 		if ra.Properties == nil {
 			return fmt.Errorf("role assignment properties nil")
@@ -108,21 +328,38 @@ func (s *RBACRuleService) processPermissionSet(set v1alpha1.PermissionSet, princ
 			return fmt.Errorf("role assignment properties role definition ID nil")
 		}
 		rdID := *ra.Properties.RoleDefinitionID
+
 		// Note that, in Azure, in the role assignments API, the value is called "role definition
 		// ID", but in the role definitions API, it is called "role ID".
-		roleDefinition, err := s.rdAPI.GetByID(rdID)
-		if err != nil {
-			return fmt.Errorf("failed to get role definition using role definition ID of role assignment: %w", azure_errors.AsAugmented(err))
+		roleDefinition, ok := q.roleDefinitions[rdID]
+		if !ok {
+			var err error
+			if roleDefinition, err = s.rdAPI.GetByID(rdID); err != nil {
+				return fmt.Errorf("failed to get role definition using role definition ID of role assignment: %w", azure_errors.AsAugmented(err))
+			}
+			q.roleDefinitions[rdID] = roleDefinition
+		}
+		if asserted {
+			assigned = append(assigned, assignedRole{assignment: ra, definition: roleDefinition})
+		}
+		if seen[rdID] {
+			continue
 		}
+		seen[rdID] = true
 		roleDefinitions = append(roleDefinitions, roleDefinition)
+		roleAssignmentID := "(unknown ID)"
+		if ra.ID != nil {
+			roleAssignmentID = *ra.ID
+		}
+		roleAssignmentIDs = append(roleAssignmentIDs, roleAssignmentID)
 	}
 
 	// Convert from ActionStr to string.
-	setActions := []string{}
+	setActions := make([]string, 0, len(set.Actions))
 	for _, a := range set.Actions {
 		setActions = append(setActions, string(a))
 	}
-	setDataActions := []string{}
+	setDataActions := make([]string, 0, len(set.DataActions))
 	for _, da := range set.DataActions {
 		setDataActions = append(setDataActions, string(da))
 	}
@@ -132,20 +369,123 @@ func (s *RBACRuleService) processPermissionSet(set v1alpha1.PermissionSet, princ
 	if err != nil {
 		return fmt.Errorf("failed to determine which candidate Actions and DataActions were denied and/or unpermitted: %w", err)
 	}
-	for denied, by := range result.actions.denied {
-		*failures = append(*failures, fmt.Sprintf("Action %s denied by deny assignment %s.", denied, by))
+	var assertionFailures []string
+	if asserted {
+		a, err := newAssignmentAssertions(set.PermissionSet)
+		if err != nil {
+			return azure_errors.Terminal(err)
+		}
+		checkAssignmentAssertions("Action", setActions, false, result.actions, assigned, set.Scope, a, &assertionFailures, ev)
+		checkAssignmentAssertions("DataAction", setDataActions, true, result.dataActions, assigned, set.Scope, a, &assertionFailures, ev)
+	} else {
+		for _, a := range setActions {
+			if by := permittingRoleAssignment(a, false, result.actions, roleAssignmentIDs, roleDefinitions); by != "" {
+				ev.add("Action %s at scope %s permitted by role assignment %s.", a, set.Scope, by)
+			}
+		}
+		for _, da := range setDataActions {
+			if by := permittingRoleAssignment(da, true, result.dataActions, roleAssignmentIDs, roleDefinitions); by != "" {
+				ev.add("DataAction %s at scope %s permitted by role assignment %s.", da, set.Scope, by)
+			}
+		}
+	}
+	roles := make([]string, 0, len(roleDefinitions))
+	for _, rd := range roleDefinitions {
+		if rd.Properties != nil && rd.Properties.RoleName != nil {
+			roles = append(roles, *rd.Properties.RoleName)
+		}
+	}
+	failure := func(kind, action, denyAssignment string) messages.PermissionFailure {
+		return messages.PermissionFailure{
+			Kind:           kind,
+			Action:         action,
+			Scope:          set.failureScope(),
+			MultiScope:     set.multiScope,
+			PrincipalID:    q.principalID,
+			Roles:          roles,
+			DenyAssignment: denyAssignment,
+		}
+	}
+	failures.items = slices.Grow(failures.items, len(result.actions.denied)+len(result.actions.unpermitted)+len(result.dataActions.denied)+len(result.dataActions.unpermitted)+len(assertionFailures))
+	for _, a := range setActions {
+		if by, ok := result.actions.denied[a]; ok {
+			failures.addPermission(failure("Action", a, by))
+		}
 	}
 	for _, unpermitted := range result.actions.unpermitted {
-		*failures = append(*failures, fmt.Sprintf("Action %s unpermitted because no role assignment permits it.", unpermitted))
+		failures.addPermission(failure("Action", unpermitted, ""))
 	}
-	for denied, by := range result.dataActions.denied {
-		*failures = append(*failures, fmt.Sprintf("DataAction %s denied by deny assignment %s.", denied, by))
+	for _, da := range setDataActions {
+		if by, ok := result.dataActions.denied[da]; ok {
+			failures.addPermission(failure("DataAction", da, by))
+		}
 	}
 	for _, unpermitted := range result.dataActions.unpermitted {
-		*failures = append(*failures, fmt.Sprintf("DataAction %s unpermitted because no role assignment permits it.", unpermitted))
+		failures.addPermission(failure("DataAction", unpermitted, ""))
+	}
+	for _, failure := range assertionFailures {
+		failures.add(failure)
 	}
 
 	// The `failures` slice will have been changed appropriately by here. Calling code will handle
 	// this appropriately.
 	return nil
 }
+
+// sortByID sorts deny assignments or role assignments by ID, in place. ARM doesn't list them in a
+// stable order, and which of them deny or permit an Action is reported in failures and evidence,
+// which mustn't change between evaluations unless the assignments do. Items without an ID sort
+// first.
+func sortByID[T any](items []*T, id func(*T) *string) {
+	slices.SortStableFunc(items, func(a, b *T) int {
+		var idA, idB string
+		if a != nil && id(a) != nil {
+			idA = *id(a)
+		}
+		if b != nil && id(b) != nil {
+			idB = *id(b)
+		}
+		return cmp.Compare(idA, idB)
+	})
+}
+
+// permittingRoleAssignment returns the ID of the first role assignment whose role permits a
+// candidate Action (or DataAction), if the candidate Action was neither denied nor unpermitted.
+// Returns an empty string otherwise. Each role definition must belong to the role assignment ID at
+// the same index and must already have been validated by processAllCandidateActions.
+func permittingRoleAssignment(candidateAction string, dataAction bool, du deniedAndUnpermitted, roleAssignmentIDs []string, roleDefinitions []*armauthorization.RoleDefinition) string {
+	if _, ok := du.denied[candidateAction]; ok || slices.Contains(du.unpermitted, candidateAction) {
+		return ""
+	}
+	for i, rd := range roleDefinitions {
+		if rolePermits(rd, candidateAction, dataAction) {
+			return roleAssignmentIDs[i]
+		}
+	}
+	return ""
+}
+
+// rolePermits returns whether a role permits a candidate Action (or DataAction), ignoring deny
+// assignments. The role definition must already have been validated by processAllCandidateActions.
+func rolePermits(rd *armauthorization.RoleDefinition, candidateAction string, dataAction bool) bool {
+	permission := rd.Properties.Permissions[0]
+	actions, notActions := permission.Actions, permission.NotActions
+	if dataAction {
+		actions, notActions = permission.DataActions, permission.NotDataActions
+	}
+	return !matchesAnyPtr(candidateAction, notActions) && matchesAnyPtr(candidateAction, actions)
+}
+
+// matchesAnyPtr is candidateActionMatches for compared Actions that haven't been dereferenced
+// yet. Nil compared Actions never match.
+func matchesAnyPtr(candidateAction string, comparedActions []*string) bool {
+	for _, ptr := range comparedActions {
+		if ptr == nil {
+			continue
+		}
+		if matches, _ := candidateActionMatches(candidateAction, []string{*ptr}); matches {
+			return true
+		}
+	}
+	return false
+}
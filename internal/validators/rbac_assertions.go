@@ -0,0 +1,147 @@
+package validators
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+)
+
+// assignedRole is a role assignment and the role definition of its role.
+type assignedRole struct {
+	assignment *armauthorization.RoleAssignment
+	definition *armauthorization.RoleDefinition
+}
+
+// assignmentAssertions are what a permission set requires of a role assignment that permits each
+// of its Actions: that it was created in a window, that its description matches a pattern, and
+// that it has a condition. Each is only asserted if it's set.
+type assignmentAssertions struct {
+	window      *creationWindow
+	description *regexp.Regexp
+	condition   *string
+}
+
+func newAssignmentAssertions(set v1alpha1.PermissionSet) (assignmentAssertions, error) {
+	a := assignmentAssertions{condition: set.Condition}
+	if set.HasCreationWindow() {
+		w := newCreationWindow(set)
+		a.window = &w
+	}
+	if set.DescriptionPattern != "" {
+		re, err := regexp.Compile(set.DescriptionPattern)
+		if err != nil {
+			return a, fmt.Errorf("invalid descriptionPattern: %w", err)
+		}
+		a.description = re
+	}
+	return a, nil
+}
+
+// satisfiedBy returns whether a role assignment satisfies all of the assertions. A role assignment
+// whose creation time ARM doesn't report isn't in any creation window.
+func (a assignmentAssertions) satisfiedBy(ra *armauthorization.RoleAssignment) bool {
+	if a.window != nil && (ra.Properties.CreatedOn == nil || !a.window.contains(*ra.Properties.CreatedOn)) {
+		return false
+	}
+	if a.description != nil && !a.description.MatchString(stringOrEmpty(ra.Properties.Description)) {
+		return false
+	}
+	return a.condition == nil || stringOrEmpty(ra.Properties.Condition) == *a.condition
+}
+
+// properties describes the properties of a role assignment that are asserted, as it has them.
+func (a assignmentAssertions) properties(ra *armauthorization.RoleAssignment) string {
+	var props []string
+	if a.window != nil {
+		if ra.Properties.CreatedOn == nil {
+			props = append(props, messages.CreationUnknown)
+		} else {
+			props = append(props, fmt.Sprintf(messages.Created, formatCreatedOn(*ra.Properties.CreatedOn)))
+		}
+	}
+	if a.description != nil {
+		props = append(props, describeString(ra.Properties.Description, messages.Description, messages.NoDescription))
+	}
+	if a.condition != nil {
+		props = append(props, describeString(ra.Properties.Condition, messages.Condition, messages.NoCondition))
+	}
+	return strings.Join(props, ", ")
+}
+
+func (a assignmentAssertions) String() string {
+	var assertions []string
+	if a.window != nil {
+		assertions = append(assertions, fmt.Sprintf(messages.Created, a.window))
+	}
+	if a.description != nil {
+		assertions = append(assertions, fmt.Sprintf(messages.DescriptionMatching, a.description))
+	}
+	if a.condition != nil {
+		assertions = append(assertions, describeString(a.condition, messages.Condition, messages.NoCondition))
+	}
+	return strings.Join(assertions, ", ")
+}
+
+// failure renders the failure of an Action that is only permitted by role assignments that don't
+// satisfy the assertions, listing them. Creation windows alone keep their own failure.
+func (a assignmentAssertions) failure(kind, action, scope string, others []string) string {
+	if a.description == nil && a.condition == nil {
+		return fmt.Sprintf(messages.CreationWindowFailure, kind, action, scope, a.window, strings.Join(others, ", "))
+	}
+	return fmt.Sprintf(messages.AssignmentAssertionFailure, kind, action, scope, a, strings.Join(others, ", "))
+}
+
+// describeString renders a string with format, or returns none if it's nil or empty.
+func describeString(s *string, format, none string) string {
+	if s == nil || *s == "" {
+		return none
+	}
+	return fmt.Sprintf(format, *s)
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// checkAssignmentAssertions appends a failure for each candidate Action (or DataAction) of a
+// permission set that is only permitted by role assignments that don't satisfy the permission
+// set's assertions, naming each of them with its asserted properties. Candidates that are denied
+// or unpermitted have failed already, so they're skipped. For each of the other candidates, the
+// first role assignment that permits it and satisfies the assertions is recorded as evidence.
+func checkAssignmentAssertions(kind string, candidates []string, dataAction bool, du deniedAndUnpermitted, assigned []assignedRole, scope string, a assignmentAssertions, failures *[]string, ev *evidence) {
+	for _, candidate := range candidates {
+		if _, ok := du.denied[candidate]; ok || slices.Contains(du.unpermitted, candidate) {
+			continue
+		}
+		var others []string
+		permitted := false
+		for _, ar := range assigned {
+			if !rolePermits(ar.definition, candidate, dataAction) {
+				continue
+			}
+			id := "(unknown ID)"
+			if ar.assignment.ID != nil {
+				id = *ar.assignment.ID
+			}
+			props := fmt.Sprintf(messages.RoleAssignmentProperties, id, a.properties(ar.assignment))
+			if a.satisfiedBy(ar.assignment) {
+				ev.add("%s %s at scope %s permitted by %s.", kind, candidate, scope, props)
+				permitted = true
+				break
+			}
+			others = append(others, props)
+		}
+		if !permitted {
+			*failures = append(*failures, a.failure(kind, candidate, scope, others))
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package validators
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+func TestRBACRuleService_ReconcileRBACRule_AssignmentAssertions(t *testing.T) {
+	const (
+		scope     = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		condition = "@Resource[Microsoft.Storage/storageAccounts/blobServices/containers:name] StringEquals 'logs'"
+	)
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assignment := func(id, description, condition string) *armauthorization.RoleAssignment {
+		ra := &armauthorization.RoleAssignment{
+			ID: util.Ptr(id),
+			Properties: &armauthorization.RoleAssignmentProperties{
+				RoleDefinitionID: util.Ptr("role-1"),
+				CreatedOn:        &created,
+			},
+		}
+		if description != "" {
+			ra.Properties.Description = util.Ptr(description)
+		}
+		if condition != "" {
+			ra.Properties.Condition = util.Ptr(condition)
+		}
+		return ra
+	}
+	rdAPI := roleDefinitionAPIMock{
+		data: map[string]*armauthorization.RoleDefinition{
+			"role-1": {
+				Properties: &armauthorization.RoleDefinitionProperties{
+					RoleName: util.Ptr("Reader"),
+					Permissions: []*armauthorization.Permission{{
+						Actions:        []*string{util.Ptr("a")},
+						NotActions:     []*string{},
+						DataActions:    []*string{},
+						NotDataActions: []*string{},
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		roleAssignments    []*armauthorization.RoleAssignment
+		descriptionPattern string
+		condition          *string
+		createdAfter       *time.Time
+		wantFailures       []string
+	}{
+		{
+			name:               "Passes for a role assignment whose description matches descriptionPattern.",
+			roleAssignments:    []*armauthorization.RoleAssignment{assignment("ra-1", "owner=platform; ticket=OPS-1", "")},
+			descriptionPattern: `(^|; )owner=platform(;|$)`,
+			wantFailures:       []string{},
+		},
+		{
+			name:               "Fails for role assignments whose descriptions don't match descriptionPattern, with their descriptions.",
+			roleAssignments:    []*armauthorization.RoleAssignment{assignment("ra-1", "owner=payments", ""), assignment("ra-2", "", "")},
+			descriptionPattern: `owner=platform`,
+			wantFailures: []string{
+				`Action a at scope ` + scope + ` is only permitted by role assignments that don't satisfy all of the permission set's assertions (description matching "owner=platform"): role assignment ra-1 (description "owner=payments"), role assignment ra-2 (no description).`,
+			},
+		},
+		{
+			name:            "Passes for a role assignment with the expected condition.",
+			roleAssignments: []*armauthorization.RoleAssignment{assignment("ra-1", "", condition)},
+			condition:       util.Ptr(condition),
+			wantFailures:    []string{},
+		},
+		{
+			name:            "Passes when any of the role assignments with the role is unrestricted, if an empty condition is expected.",
+			roleAssignments: []*armauthorization.RoleAssignment{assignment("ra-1", "", condition), assignment("ra-2", "", "")},
+			condition:       util.Ptr(""),
+			wantFailures:    []string{},
+		},
+		{
+			name:            "Fails for a restricted role assignment, if an empty condition is expected, with its condition.",
+			roleAssignments: []*armauthorization.RoleAssignment{assignment("ra-1", "", condition)},
+			condition:       util.Ptr(""),
+			wantFailures: []string{
+				`Action a at scope ` + scope + ` is only permitted by role assignments that don't satisfy all of the permission set's assertions (no condition): role assignment ra-1 (condition "@Resource[Microsoft.Storage/storageAccounts/blobServices/containers:name] StringEquals 'logs'").`,
+			},
+		},
+		{
+			name: "Fails when no role assignment satisfies all assertions, even if each is satisfied by one, naming each.",
+			roleAssignments: []*armauthorization.RoleAssignment{
+				assignment("ra-1", "owner=platform", condition),
+				assignment("ra-2", "owner=payments", ""),
+			},
+			descriptionPattern: `owner=platform`,
+			condition:          util.Ptr(""),
+			createdAfter:       &created,
+			wantFailures: []string{
+				`Action a at scope ` + scope + ` is only permitted by role assignments that don't satisfy all of the permission set's assertions (created at or after 2024-01-01T00:00:00Z, description matching "owner=platform", no condition): ` +
+					`role assignment ra-1 (created 2024-01-01T00:00:00Z, description "owner=platform", condition "@Resource[Microsoft.Storage/storageAccounts/blobServices/containers:name] StringEquals 'logs'"), ` +
+					`role assignment ra-2 (created 2024-01-01T00:00:00Z, description "owner=payments", no condition).`,
+			},
+		},
+		{
+			name: "Passes when one of the role assignments satisfies all assertions.",
+			roleAssignments: []*armauthorization.RoleAssignment{
+				assignment("ra-1", "owner=platform", condition),
+				assignment("ra-2", "owner=platform", ""),
+			},
+			descriptionPattern: `owner=platform`,
+			condition:          util.Ptr(""),
+			createdAfter:       &created,
+			wantFailures:       []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raAPI := roleAssignmentAPIMock{data: tt.roleAssignments}
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil)
+
+			set := v1alpha1.PermissionSet{
+				Actions:            []v1alpha1.ActionStr{"a"},
+				Scope:              scope,
+				DescriptionPattern: tt.descriptionPattern,
+				Condition:          tt.condition,
+			}
+			if tt.createdAfter != nil {
+				set.CreatedAfter = &metav1.Time{Time: *tt.createdAfter}
+			}
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:        "rule-1",
+				Permissions: []v1alpha1.PermissionSet{set},
+				PrincipalID: "p_id",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package validators
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	benchRoleDefinitions     = 200
+	benchRoleAssignments     = 2000
+	benchActionsPerRole      = 10
+	benchPermissionSets      = 50
+	benchActionsPerSet       = 20
+	benchDenyAssignments     = 10
+	benchSubscriptionScope   = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	benchRoleDefinitionIDFmt = benchSubscriptionScope + "/providers/Microsoft.Authorization/roleDefinitions/%d"
+)
+
+// newBenchRBACRuleService returns an RBACRuleService whose APIs return a large, synthetic set of
+// Azure resources, and a rule with many permission sets to evaluate against them. Every required
+// Action is permitted by some role, and some are denied by deny assignments, so that evaluation
+// produces both evidence and failures.
+func newBenchRBACRuleService() (*RBACRuleService, v1alpha1.RBACRule) {
+	action := func(role, i int) string {
+		return fmt.Sprintf("Microsoft.Provider%d/resource%d/read", role, i)
+	}
+
+	roleDefinitions := make(map[string]*armauthorization.RoleDefinition, benchRoleDefinitions)
+	for r := 0; r < benchRoleDefinitions; r++ {
+		actions := make([]*string, 0, benchActionsPerRole)
+		dataActions := make([]*string, 0, benchActionsPerRole)
+		for i := 0; i < benchActionsPerRole; i++ {
+			actions = append(actions, util.Ptr(action(r, i)))
+			dataActions = append(dataActions, util.Ptr(action(r, i)+"/data"))
+		}
+		roleDefinitions[fmt.Sprintf(benchRoleDefinitionIDFmt, r)] = &armauthorization.RoleDefinition{
+			Properties: &armauthorization.RoleDefinitionProperties{
+				Permissions: []*armauthorization.Permission{
+					{
+						Actions:        actions,
+						DataActions:    dataActions,
+						NotActions:     []*string{},
+						NotDataActions: []*string{},
+					},
+				},
+			},
+		}
+	}
+
+	roleAssignments := make([]*armauthorization.RoleAssignment, 0, benchRoleAssignments)
+	for a := 0; a < benchRoleAssignments; a++ {
+		roleAssignments = append(roleAssignments, &armauthorization.RoleAssignment{
+			ID: util.Ptr(fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignments/%d", benchSubscriptionScope, a)),
+			Properties: &armauthorization.RoleAssignmentProperties{
+				RoleDefinitionID: util.Ptr(fmt.Sprintf(benchRoleDefinitionIDFmt, a%benchRoleDefinitions)),
+			},
+		})
+	}
+
+	denyAssignments := make([]*armauthorization.DenyAssignment, 0, benchDenyAssignments)
+	for d := 0; d < benchDenyAssignments; d++ {
+		denyAssignments = append(denyAssignments, &armauthorization.DenyAssignment{
+			ID: util.Ptr(fmt.Sprintf("%s/providers/Microsoft.Authorization/denyAssignments/%d", benchSubscriptionScope, d)),
+			Properties: &armauthorization.DenyAssignmentProperties{
+				Permissions: []*armauthorization.DenyAssignmentPermission{
+					{
+						Actions:        []*string{util.Ptr(action(d, 0))},
+						DataActions:    []*string{},
+						NotActions:     []*string{},
+						NotDataActions: []*string{},
+					},
+				},
+			},
+		})
+	}
+
+	rule := v1alpha1.RBACRule{
+		Name:        "bench",
+		PrincipalID: "00000000-0000-0000-0000-000000000001",
+	}
+	for s := 0; s < benchPermissionSets; s++ {
+		set := v1alpha1.PermissionSet{Scope: benchSubscriptionScope}
+		for i := 0; i < benchActionsPerSet; i++ {
+			role := (s*benchActionsPerSet + i) % benchRoleDefinitions
+			set.Actions = append(set.Actions, v1alpha1.ActionStr(action(role, i%benchActionsPerRole)))
+		}
+		set.DataActions = append(set.DataActions, v1alpha1.ActionStr(action(s, 0)+"/data"))
+		rule.Permissions = append(rule.Permissions, set)
+	}
+
+	svc := NewRBACRuleService(
+		logr.Discard(),
+		denyAssignmentAPIMock{data: denyAssignments},
+		roleAssignmentAPIMock{data: roleAssignments},
+		roleDefinitionAPIMock{data: roleDefinitions},
+		nil,
+	)
+	return svc, rule
+}
+
+func BenchmarkRBACRuleService_ReconcileRBACRule(b *testing.B) {
+	svc, rule := newBenchRBACRuleService()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.ReconcileRBACRule(rule); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRBACRuleService_processPermissionSet(b *testing.B) {
+	svc, rule := newBenchRBACRuleService()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		failures := &setFailures{}
+		if err := svc.processPermissionSet(scopedPermissionSet{PermissionSet: rule.Permissions[0]}, newRBACQueries(rule.PrincipalID, false, ""), failures, &evidence{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestRBACRuleService_Allocations guards the benchmarks above against allocation regressions. The
+// bounds are roughly twice the allocations measured when they were set, so that only substantial
+// regressions (e.g. processing each role assignment's role definition again) fail the test.
+func TestRBACRuleService_Allocations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping allocation bounds in short mode")
+	}
+	svc, rule := newBenchRBACRuleService()
+
+	if allocs := testing.AllocsPerRun(3, func() {
+		if _, err := svc.ReconcileRBACRule(rule); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs > 50_000 {
+		t.Errorf("ReconcileRBACRule made %.0f allocations, want at most 50000", allocs)
+	}
+
+	if allocs := testing.AllocsPerRun(3, func() {
+		failures := &setFailures{}
+		if err := svc.processPermissionSet(scopedPermissionSet{PermissionSet: rule.Permissions[0]}, newRBACQueries(rule.PrincipalID, false, ""), failures, &evidence{}); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs > 1_200 {
+		t.Errorf("processPermissionSet made %.0f allocations, want at most 1200", allocs)
+	}
+}
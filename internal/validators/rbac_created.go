@@ -0,0 +1,46 @@
+package validators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+)
+
+// creationWindow is when a permission set requires the role assignments that permit its Actions
+// to have been created: at or after after, and before before. Either may be zero.
+type creationWindow struct {
+	after, before time.Time
+}
+
+func newCreationWindow(set v1alpha1.PermissionSet) creationWindow {
+	var w creationWindow
+	if set.CreatedAfter != nil {
+		w.after = set.CreatedAfter.Time
+	}
+	if set.CreatedBefore != nil {
+		w.before = set.CreatedBefore.Time
+	}
+	return w
+}
+
+// contains returns whether a role assignment created at t was created in the window.
+func (w creationWindow) contains(t time.Time) bool {
+	return (w.after.IsZero() || !t.Before(w.after)) && (w.before.IsZero() || t.Before(w.before))
+}
+
+func (w creationWindow) String() string {
+	switch {
+	case w.before.IsZero():
+		return fmt.Sprintf(messages.CreatedAtOrAfter, formatCreatedOn(w.after))
+	case w.after.IsZero():
+		return fmt.Sprintf(messages.CreatedBefore, formatCreatedOn(w.before))
+	default:
+		return fmt.Sprintf(messages.CreatedBetween, formatCreatedOn(w.after), formatCreatedOn(w.before))
+	}
+}
+
+func formatCreatedOn(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,147 @@
+package validators
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+func TestRBACRuleService_ReconcileRBACRule_CreationWindow(t *testing.T) {
+	const scope = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	assignment := func(id, role string, createdOn *time.Time) *armauthorization.RoleAssignment {
+		return &armauthorization.RoleAssignment{
+			ID: util.Ptr(id),
+			Properties: &armauthorization.RoleAssignmentProperties{
+				RoleDefinitionID: util.Ptr(role),
+				CreatedOn:        createdOn,
+			},
+		}
+	}
+	rdAPI := roleDefinitionAPIMock{
+		data: map[string]*armauthorization.RoleDefinition{
+			"role-1": {
+				Properties: &armauthorization.RoleDefinitionProperties{
+					RoleName: util.Ptr("Reader"),
+					Permissions: []*armauthorization.Permission{{
+						Actions:        []*string{util.Ptr("a")},
+						NotActions:     []*string{},
+						DataActions:    []*string{util.Ptr("da")},
+						NotDataActions: []*string{},
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		roleAssignments []*armauthorization.RoleAssignment
+		createdAfter    *time.Time
+		createdBefore   *time.Time
+		wantFailures    []string
+	}{
+		{
+			name:            "Passes for a role assignment created at createdAfter.",
+			roleAssignments: []*armauthorization.RoleAssignment{assignment("ra-1", "role-1", &after)},
+			createdAfter:    &after,
+			createdBefore:   &before,
+			wantFailures:    []string{},
+		},
+		{
+			name:            "Fails for a role assignment created at createdBefore, with its creation time.",
+			roleAssignments: []*armauthorization.RoleAssignment{assignment("ra-1", "role-1", &before)},
+			createdAfter:    &after,
+			createdBefore:   &before,
+			wantFailures: []string{
+				"Action a at scope " + scope + " is only permitted by role assignments not created between 2024-01-01T00:00:00Z and 2024-07-01T00:00:00Z: role assignment ra-1 (created 2024-07-01T00:00:00Z).",
+				"DataAction da at scope " + scope + " is only permitted by role assignments not created between 2024-01-01T00:00:00Z and 2024-07-01T00:00:00Z: role assignment ra-1 (created 2024-07-01T00:00:00Z).",
+			},
+		},
+		{
+			name:            "Fails for a role assignment without a creation time.",
+			roleAssignments: []*armauthorization.RoleAssignment{assignment("ra-1", "role-1", nil)},
+			createdBefore:   &before,
+			wantFailures: []string{
+				"Action a at scope " + scope + " is only permitted by role assignments not created before 2024-07-01T00:00:00Z: role assignment ra-1 (creation time unknown).",
+				"DataAction da at scope " + scope + " is only permitted by role assignments not created before 2024-07-01T00:00:00Z: role assignment ra-1 (creation time unknown).",
+			},
+		},
+		{
+			name: "Passes when any of the role assignments with the role was created in the window.",
+			roleAssignments: []*armauthorization.RoleAssignment{
+				assignment("ra-1", "role-1", util.Ptr(after.Add(-time.Second))),
+				assignment("ra-2", "role-1", util.Ptr(after.Add(time.Hour))),
+			},
+			createdAfter: &after,
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when none of the role assignments with the role was created in the window, naming each.",
+			roleAssignments: []*armauthorization.RoleAssignment{
+				assignment("ra-1", "role-1", util.Ptr(after.Add(-time.Second))),
+				assignment("ra-2", "role-1", nil),
+			},
+			createdAfter: &after,
+			wantFailures: []string{
+				"Action a at scope " + scope + " is only permitted by role assignments not created at or after 2024-01-01T00:00:00Z: role assignment ra-1 (created 2023-12-31T23:59:59Z), role assignment ra-2 (creation time unknown).",
+				"DataAction da at scope " + scope + " is only permitted by role assignments not created at or after 2024-01-01T00:00:00Z: role assignment ra-1 (created 2023-12-31T23:59:59Z), role assignment ra-2 (creation time unknown).",
+			},
+		},
+		{
+			name:            "Unpermitted Actions fail the usual way, without a creation window failure.",
+			roleAssignments: []*armauthorization.RoleAssignment{},
+			createdAfter:    &after,
+			wantFailures: []string{
+				"Action a unpermitted because no role assignment permits it.",
+				"DataAction da unpermitted because no role assignment permits it.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raAPI := roleAssignmentAPIMock{data: tt.roleAssignments}
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil)
+
+			set := v1alpha1.PermissionSet{
+				Actions:     []v1alpha1.ActionStr{"a"},
+				DataActions: []v1alpha1.ActionStr{"da"},
+				Scope:       scope,
+			}
+			if tt.createdAfter != nil {
+				set.CreatedAfter = &metav1.Time{Time: *tt.createdAfter}
+			}
+			if tt.createdBefore != nil {
+				set.CreatedBefore = &metav1.Time{Time: *tt.createdBefore}
+			}
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:        "rule-1",
+				Permissions: []v1alpha1.PermissionSet{set},
+				PrincipalID: "p_id",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
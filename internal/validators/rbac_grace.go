@@ -0,0 +1,37 @@
+package validators
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// WithGracePeriod makes the RBACRuleService report rules whose only failures are permissions that
+// their principals lack as pending instead of failed, if now is before end, since role assignments
+// that were just created take a while to be visible. If end is zero, there's no grace period.
+func (s *RBACRuleService) WithGracePeriod(now, end time.Time) *RBACRuleService {
+	s.now = now
+	s.graceEnd = end
+	return s
+}
+
+// withinGracePeriod reports whether missing permissions are still attributed to role assignments
+// propagating.
+func (s *RBACRuleService) withinGracePeriod() bool {
+	return !s.graceEnd.IsZero() && s.now.Before(s.graceEnd)
+}
+
+// setPending reports a rule's condition as pending until the end of the grace period.
+func (s *RBACRuleService) setPending(state *vapi.ValidationState, condition *vapi.ValidationCondition) {
+	*state = vapi.ValidationInProgress
+	condition.Message = messages.RBACPending
+	condition.Status = corev1.ConditionUnknown
+	condition.Details = append(condition.Details, fmt.Sprintf(
+		"Missing permissions don't fail the rule until %s, as role assignments may still be propagating.",
+		s.graceEnd.UTC().Format(time.RFC3339),
+	))
+}
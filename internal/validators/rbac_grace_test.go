@@ -0,0 +1,129 @@
+package validators
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+func TestRBACRuleService_ReconcileRBACRule_GracePeriod(t *testing.T) {
+	const scope = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	raAPI := roleAssignmentAPIMock{
+		data: []*armauthorization.RoleAssignment{{
+			ID:         util.Ptr("ra-1"),
+			Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("role-1")},
+		}},
+	}
+	rdAPI := roleDefinitionAPIMock{
+		data: map[string]*armauthorization.RoleDefinition{
+			"role-1": {
+				Properties: &armauthorization.RoleDefinitionProperties{
+					RoleName: util.Ptr("Reader"),
+					Permissions: []*armauthorization.Permission{{
+						Actions:        []*string{util.Ptr("a")},
+						NotActions:     []*string{},
+						DataActions:    []*string{},
+						NotDataActions: []*string{},
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                   string
+		actions                []v1alpha1.ActionStr
+		failureMessageTemplate string
+		graceEnd               time.Time
+		wantState              vapi.ValidationState
+		wantStatus             corev1.ConditionStatus
+		wantMessage            string
+		wantFailures           int
+	}{
+		{
+			name:         "Missing permissions fail the rule right away without a grace period.",
+			actions:      []v1alpha1.ActionStr{"a", "b"},
+			wantState:    vapi.ValidationFailed,
+			wantStatus:   corev1.ConditionFalse,
+			wantMessage:  messages.RBACFailed,
+			wantFailures: 1,
+		},
+		{
+			name:         "Missing permissions leave the rule pending within its grace period.",
+			actions:      []v1alpha1.ActionStr{"a", "b"},
+			graceEnd:     now.Add(time.Minute),
+			wantState:    vapi.ValidationInProgress,
+			wantStatus:   corev1.ConditionUnknown,
+			wantMessage:  messages.RBACPending,
+			wantFailures: 1,
+		},
+		{
+			name:         "Missing permissions fail the rule once its grace period has ended.",
+			actions:      []v1alpha1.ActionStr{"a", "b"},
+			graceEnd:     now,
+			wantState:    vapi.ValidationFailed,
+			wantStatus:   corev1.ConditionFalse,
+			wantMessage:  messages.RBACFailed,
+			wantFailures: 1,
+		},
+		{
+			name:                   "Problems with the rule's spec fail the rule within its grace period.",
+			actions:                []v1alpha1.ActionStr{"a", "b"},
+			failureMessageTemplate: "{{.Action",
+			graceEnd:               now.Add(time.Minute),
+			wantState:              vapi.ValidationFailed,
+			wantStatus:             corev1.ConditionFalse,
+			wantMessage:            messages.RuleSpecInvalid,
+			wantFailures:           1,
+		},
+		{
+			name:        "Rules with all permissions pass within their grace period.",
+			actions:     []v1alpha1.ActionStr{"a"},
+			graceEnd:    now.Add(time.Minute),
+			wantState:   vapi.ValidationSucceeded,
+			wantStatus:  corev1.ConditionTrue,
+			wantMessage: messages.RBACSucceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil).WithGracePeriod(now, tt.graceEnd)
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:                   "rule-1",
+				Permissions:            []v1alpha1.PermissionSet{{Actions: tt.actions, Scope: scope}},
+				PrincipalID:            "p_id",
+				FailureMessageTemplate: tt.failureMessageTemplate,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *result.State != tt.wantState {
+				t.Errorf("got state %s, want %s", *result.State, tt.wantState)
+			}
+			if result.Condition.Status != tt.wantStatus {
+				t.Errorf("got status %s, want %s", result.Condition.Status, tt.wantStatus)
+			}
+			if result.Condition.Message != tt.wantMessage {
+				t.Errorf("got message %q, want %q", result.Condition.Message, tt.wantMessage)
+			}
+			if len(result.Condition.Failures) != tt.wantFailures {
+				t.Errorf("got failures %v, want %d", result.Condition.Failures, tt.wantFailures)
+			}
+			wantDetail := "Missing permissions don't fail the rule until 2024-01-01T12:01:00Z, as role assignments may still be propagating."
+			if pending := slices.Contains(result.Condition.Details, wantDetail); pending != (tt.wantState == vapi.ValidationInProgress) {
+				t.Errorf("got details %v, want the grace period's end only while pending", result.Condition.Details)
+			}
+		})
+	}
+}
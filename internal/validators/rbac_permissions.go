@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
-	"golang.org/x/exp/maps"
 
 	map_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/maps"
 )
@@ -75,10 +74,10 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 	// Dereference all the data from Azure, while validating it for our algorithm's constraints.
 	// Deny assignments and role assignments that exist in the user's Azure account must have at
 	// most one wildcard each.
-	denyAssignmentInfoControl := []denyAssignmentInfo{}
-	denyAssignmentInfoData := []denyAssignmentInfo{}
-	roleInfoControl := []roleInfo{}
-	roleInfoData := []roleInfo{}
+	denyAssignmentInfoControl := make([]denyAssignmentInfo, 0, len(denyAssignments))
+	denyAssignmentInfoData := make([]denyAssignmentInfo, 0, len(denyAssignments))
+	roleInfoControl := make([]roleInfo, 0, len(roles))
+	roleInfoData := make([]roleInfo, 0, len(roles))
 	for _, denyAssignment := range denyAssignments {
 		if denyAssignment == nil {
 			return result{}, errNil("deny assignment")
@@ -103,7 +102,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.Actions == nil {
 			return result{}, errNil("deny assignment Actions")
 		}
-		actions := []string{}
+		actions := make([]string, 0, len(permission.Actions))
 		for _, ptr := range permission.Actions {
 			if ptr == nil {
 				return result{}, errNil("deny assignment Action")
@@ -117,7 +116,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.NotActions == nil {
 			return result{}, errNil("deny assignment NotActions")
 		}
-		notActions := []string{}
+		notActions := make([]string, 0, len(permission.NotActions))
 		for _, ptr := range permission.NotActions {
 			if ptr == nil {
 				return result{}, errNil("deny assignment NotAction")
@@ -136,7 +135,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.DataActions == nil {
 			return result{}, errNil("deny assignment DataActions")
 		}
-		dataActions := []string{}
+		dataActions := make([]string, 0, len(permission.DataActions))
 		for _, ptr := range permission.DataActions {
 			if ptr == nil {
 				return result{}, errNil("deny assignment DataAction")
@@ -150,7 +149,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.NotDataActions == nil {
 			return result{}, errNil("deny assignment NotDataActions")
 		}
-		notDataActions := []string{}
+		notDataActions := make([]string, 0, len(permission.NotDataActions))
 		for _, ptr := range permission.NotDataActions {
 			if ptr == nil {
 				return result{}, errNil("deny assignment NotDataAction")
@@ -187,7 +186,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.Actions == nil {
 			return result{}, errNil("role Actions")
 		}
-		actions := []string{}
+		actions := make([]string, 0, len(permission.Actions))
 		for _, ptr := range permission.Actions {
 			if ptr == nil {
 				return result{}, errNil("role Action")
@@ -201,7 +200,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.NotActions == nil {
 			return result{}, errNil("role NotActions")
 		}
-		notActions := []string{}
+		notActions := make([]string, 0, len(permission.NotActions))
 		for _, ptr := range permission.NotActions {
 			if ptr == nil {
 				return result{}, errNil("role NotAction")
@@ -219,7 +218,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.DataActions == nil {
 			return result{}, errNil("role DataActions")
 		}
-		dataActions := []string{}
+		dataActions := make([]string, 0, len(permission.DataActions))
 		for _, ptr := range permission.DataActions {
 			if ptr == nil {
 				return result{}, errNil("role DataAction")
@@ -233,7 +232,7 @@ func processAllCandidateActions(candidateActions, candidateDataActions []string,
 		if permission.NotDataActions == nil {
 			return result{}, errNil("role NotDataActions")
 		}
-		notDataActions := []string{}
+		notDataActions := make([]string, 0, len(permission.NotDataActions))
 		for _, ptr := range permission.NotDataActions {
 			if ptr == nil {
 				return result{}, errNil("role NotDataAction")
@@ -315,10 +314,19 @@ candidateActions:
 	// first place. It's possible for a candidate Action to be both denied and not permitted. We
 	// report two failures for such candidate Actions so that the user gets as much info as possible
 	// each time they observe the validation result.
-	return deniedAndUnpermitted{
+	// Unpermitted candidate Actions are kept in the order they were specified in, so that failures
+	// don't reorder between evaluations.
+	du := deniedAndUnpermitted{
 		denied:      denied,
-		unpermitted: maps.Keys(unpermitted),
+		unpermitted: make([]string, 0, len(unpermitted)),
 	}
+	for _, candidateAction := range candidateActions {
+		if unpermitted[candidateAction] {
+			du.unpermitted = append(du.unpermitted, candidateAction)
+			delete(unpermitted, candidateAction)
+		}
+	}
+	return du
 }
 
 // candidateActionMatches determines whether a candidate Action matches any compared Actions, where
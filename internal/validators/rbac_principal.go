@@ -0,0 +1,141 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// principalAPI contains methods that allow getting a principal by its object ID, listing the
+// principals of a type with a display name, and getting the service principal of an application by
+// its client ID.
+type principalAPI interface {
+	GetPrincipal(objectID string) (*azure_utils.GraphDirectoryObject, error)
+	ListPrincipalsByDisplayName(odataType, displayName string) ([]*azure_utils.GraphDirectoryObject, error)
+	GetServicePrincipalByAppID(appID string) (*azure_utils.GraphDirectoryObject, error)
+}
+
+// WithPrincipals lets the RBACRuleService look up the principals of rules with verifyPrincipal or
+// principalClientId in Microsoft Graph with principalAPI.
+func (s *RBACRuleService) WithPrincipals(principalAPI principalAPI) *RBACRuleService {
+	s.principalAPI = principalAPI
+	return s
+}
+
+// verifyPrincipal looks up a principal, recording its type and display name as evidence. It
+// returns nil if the principal couldn't be looked up, e.g. because it was deleted.
+func (s *RBACRuleService) verifyPrincipal(principalID string, ev *evidence) (*azure_utils.GraphDirectoryObject, error) {
+	if s.principalAPI == nil {
+		ev.add("verifyPrincipal was ignored, because Microsoft Graph couldn't be queried.")
+		return nil, nil
+	}
+	principal, err := s.principalAPI.GetPrincipal(principalID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			ev.add("Principal %s not found in Microsoft Entra ID. It may have been deleted.", principalID)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get principal: %w", azure_errors.AsAugmented(err))
+	}
+	ev.add("Principal %s is a %s named %s.", principalID, principalType(principal), principal.DisplayName)
+	return principal, nil
+}
+
+// resolvePrincipalClientID returns the object ID of the service principal of the application with
+// a client ID, recording the resolution as evidence. If there's no such service principal, or
+// Microsoft Graph can't be queried, it appends a failure and returns an empty object ID.
+func (s *RBACRuleService) resolvePrincipalClientID(clientID string, failures *[]string, ev *evidence) (string, error) {
+	if s.principalAPI == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.PrincipalClientIDUnresolvedFailure, clientID))
+		return "", nil
+	}
+	principal, err := s.principalAPI.GetServicePrincipalByAppID(clientID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get service principal by client ID: %w", azure_errors.AsAugmented(err))
+	}
+	if principal == nil {
+		*failures = append(*failures, fmt.Sprintf(messages.PrincipalClientIDFailure, clientID))
+		return "", nil
+	}
+	ev.add("Client ID %s resolved to service principal %s named %s.", clientID, principal.ID, principal.DisplayName)
+	return principal.ID, nil
+}
+
+// checkRecreatedPrincipal records as evidence which other principals of a principal's type with
+// its display name have role assignments at the scopes where the principal has none. Those are
+// usually the principal's previous incarnations, whose role assignments weren't moved to the
+// principal when it was re-created.
+func (s *RBACRuleService) checkRecreatedPrincipal(principal *azure_utils.GraphDirectoryObject, q *rbacQueries, ev *evidence) error {
+	if principal.DisplayName == "" {
+		return nil
+	}
+	namesakes, err := s.principalAPI.ListPrincipalsByDisplayName(principal.ODataType, principal.DisplayName)
+	if err != nil {
+		return fmt.Errorf("failed to list principals by display name: %w", azure_errors.AsAugmented(err))
+	}
+	scopes := make([]string, 0, len(q.roleAssignments))
+	for scope := range q.roleAssignments {
+		scopes = append(scopes, scope)
+	}
+	slices.Sort(scopes)
+
+	assigned := []string{}
+	for _, namesake := range namesakes {
+		if namesake == nil || strings.EqualFold(namesake.ID, principal.ID) {
+			continue
+		}
+		filter := util.Ptr(azure_utils.RoleAssignmentFilter(namesake.ID, false))
+		for _, scope := range scopes {
+			roleAssignments, err := s.raAPI.GetRoleAssignmentsForScope(scope, filter)
+			if err != nil {
+				return fmt.Errorf("failed to get role assignments: %w", azure_errors.AsAugmented(err))
+			}
+			if len(roleAssignments) > 0 {
+				assigned = append(assigned, namesake.ID)
+				break
+			}
+		}
+	}
+
+	kind := principalType(principal)
+	if len(assigned) == 0 {
+		ev.add("Principal %s has no role assignments at the rule's scopes, and no other %s named %s has any either.", principal.ID, kind, principal.DisplayName)
+		return nil
+	}
+	ev.add("Principal %s has no role assignments at the rule's scopes, but other %ss named %s do: %s. The principal may have been deleted and re-created, leaving its role assignments behind with its old object ID.",
+		principal.ID, kind, principal.DisplayName, strings.Join(assigned, ", "))
+	return nil
+}
+
+// foundNoRoleAssignments reports whether role assignments were listed at at least one scope, and
+// none were found at any of them.
+func (q *rbacQueries) foundNoRoleAssignments() bool {
+	if len(q.roleAssignments) == 0 {
+		return false
+	}
+	for _, roleAssignments := range q.roleAssignments {
+		if len(roleAssignments) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// principalType returns the type of a principal without its OData namespace, e.g.
+// servicePrincipal.
+func principalType(principal *azure_utils.GraphDirectoryObject) string {
+	if principal.ODataType == "" {
+		return "principal"
+	}
+	return strings.TrimPrefix(principal.ODataType, "#microsoft.graph.")
+}
@@ -0,0 +1,278 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// principalAPIMock is a fake Microsoft Graph with the principals in its list, and the service
+// principals of applications, keyed by client ID. Getting any other principal fails with a 404,
+// unless err is set.
+type principalAPIMock struct {
+	principals        []*azure_utils.GraphDirectoryObject
+	servicePrincipals map[string]*azure_utils.GraphDirectoryObject
+	err               error
+}
+
+func (m principalAPIMock) GetPrincipal(objectID string) (*azure_utils.GraphDirectoryObject, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, p := range m.principals {
+		if p.ID == objectID {
+			return p, nil
+		}
+	}
+	return nil, &azcore.ResponseError{ErrorCode: "Request_ResourceNotFound", StatusCode: http.StatusNotFound}
+}
+
+func (m principalAPIMock) ListPrincipalsByDisplayName(odataType, displayName string) ([]*azure_utils.GraphDirectoryObject, error) {
+	var principals []*azure_utils.GraphDirectoryObject
+	for _, p := range m.principals {
+		if p.ODataType == odataType && p.DisplayName == displayName {
+			principals = append(principals, p)
+		}
+	}
+	return principals, nil
+}
+
+func (m principalAPIMock) GetServicePrincipalByAppID(appID string) (*azure_utils.GraphDirectoryObject, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.servicePrincipals[appID], nil
+}
+
+// principalRoleAssignmentAPIMock has the role assignments of each principal, keyed by the filter
+// that lists them.
+type principalRoleAssignmentAPIMock struct {
+	data map[string][]*armauthorization.RoleAssignment
+}
+
+func (m principalRoleAssignmentAPIMock) GetRoleAssignmentsForScope(_ string, filter *string) ([]*armauthorization.RoleAssignment, error) {
+	return m.data[*filter], nil
+}
+
+func TestRBACRuleService_ReconcileRBACRule_VerifyPrincipal(t *testing.T) {
+	const (
+		sub      = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		current  = "11111111-1111-1111-1111-111111111111"
+		previous = "22222222-2222-2222-2222-222222222222"
+		spType   = "#microsoft.graph.servicePrincipal"
+	)
+	principals := principalAPIMock{principals: []*azure_utils.GraphDirectoryObject{
+		{ID: current, ODataType: spType, DisplayName: "ci-deployer"},
+		{ID: previous, ODataType: spType, DisplayName: "ci-deployer"},
+		{ID: "33333333-3333-3333-3333-333333333333", ODataType: spType, DisplayName: "ci-deployer"},
+		{ID: "44444444-4444-4444-4444-444444444444", ODataType: "#microsoft.graph.user", DisplayName: "ci-deployer"},
+	}}
+	reader := &armauthorization.RoleAssignment{
+		ID:         util.Ptr(sub + "/providers/Microsoft.Authorization/roleAssignments/ra-1"),
+		Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("reader")},
+	}
+	rdAPI := roleDefinitionAPIMock{data: map[string]*armauthorization.RoleDefinition{
+		"reader": {Properties: &armauthorization.RoleDefinitionProperties{
+			RoleName: util.Ptr("Reader"),
+			Permissions: []*armauthorization.Permission{{
+				Actions:        []*string{util.Ptr("*/read")},
+				NotActions:     []*string{},
+				DataActions:    []*string{},
+				NotDataActions: []*string{},
+			}},
+		}},
+	}}
+	rule := v1alpha1.RBACRule{
+		Name:            "rule-1",
+		PrincipalID:     current,
+		VerifyPrincipal: true,
+		Permissions:     []v1alpha1.PermissionSet{{Scope: sub, Actions: []v1alpha1.ActionStr{"Microsoft.Resources/subscriptions/read"}}},
+	}
+
+	tests := []struct {
+		name        string
+		principals  principalAPI
+		data        map[string][]*armauthorization.RoleAssignment
+		wantDetails []string
+	}{
+		{
+			name:       "Reports the type and display name of the principal.",
+			principals: principals,
+			data:       map[string][]*armauthorization.RoleAssignment{azure_utils.RoleAssignmentFilter(current, false): {reader}},
+			wantDetails: []string{
+				"Principal " + current + " is a servicePrincipal named ci-deployer.",
+				"Role assignments were listed with filter principalId eq '" + current + "'.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal " + current + " at scope " + sub + ".",
+				"Action Microsoft.Resources/subscriptions/read at scope " + sub + " permitted by role assignment " + *reader.ID + ".",
+			},
+		},
+		{
+			name:       "Lists the principals with the same display name that have role assignments when the principal has none.",
+			principals: principals,
+			data:       map[string][]*armauthorization.RoleAssignment{azure_utils.RoleAssignmentFilter(previous, false): {reader}},
+			wantDetails: []string{
+				"Principal " + current + " is a servicePrincipal named ci-deployer.",
+				"Role assignments were listed with filter principalId eq '" + current + "'.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal " + current + " at scope " + sub + ".",
+				"Principal " + current + " has no role assignments at the rule's scopes, but other servicePrincipals named ci-deployer do: " + previous + ". The principal may have been deleted and re-created, leaving its role assignments behind with its old object ID.",
+			},
+		},
+		{
+			name:       "Reports when no principal with the same display name has role assignments.",
+			principals: principals,
+			data:       map[string][]*armauthorization.RoleAssignment{},
+			wantDetails: []string{
+				"Principal " + current + " is a servicePrincipal named ci-deployer.",
+				"Role assignments were listed with filter principalId eq '" + current + "'.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal " + current + " at scope " + sub + ".",
+				"Principal " + current + " has no role assignments at the rule's scopes, and no other servicePrincipal named ci-deployer has any either.",
+			},
+		},
+		{
+			name:       "Reports when the principal is missing.",
+			principals: principalAPIMock{},
+			data:       map[string][]*armauthorization.RoleAssignment{},
+			wantDetails: []string{
+				"Principal " + current + " not found in Microsoft Entra ID. It may have been deleted.",
+				"Role assignments were listed with filter principalId eq '" + current + "'.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal " + current + " at scope " + sub + ".",
+			},
+		},
+		{
+			name: "Ignores verifyPrincipal without Microsoft Graph.",
+			data: map[string][]*armauthorization.RoleAssignment{},
+			wantDetails: []string{
+				"verifyPrincipal was ignored, because Microsoft Graph couldn't be queried.",
+				"Role assignments were listed with filter principalId eq '" + current + "'.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal " + current + " at scope " + sub + ".",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, principalRoleAssignmentAPIMock{data: tt.data}, rdAPI, nil)
+			if tt.principals != nil {
+				svc.WithPrincipals(tt.principals)
+			}
+
+			result, err := svc.ReconcileRBACRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_VerifyPrincipal_Error(t *testing.T) {
+	svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, roleAssignmentAPIMock{}, roleDefinitionAPIMock{}, nil).
+		WithPrincipals(principalAPIMock{err: &azcore.ResponseError{ErrorCode: "Authorization_RequestDenied", StatusCode: http.StatusForbidden}})
+
+	result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+		Name:            "rule-1",
+		PrincipalID:     "p_id",
+		VerifyPrincipal: true,
+		Permissions:     []v1alpha1.PermissionSet{{Scope: "/subscriptions/00000000-0000-0000-0000-000000000000", Actions: []v1alpha1.ActionStr{"a"}}},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Graph error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_PrincipalClientID(t *testing.T) {
+	const (
+		sub      = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		clientID = "55555555-5555-5555-5555-555555555555"
+		objectID = "11111111-1111-1111-1111-111111111111"
+	)
+	reader := &armauthorization.RoleAssignment{
+		ID:         util.Ptr(sub + "/providers/Microsoft.Authorization/roleAssignments/ra-1"),
+		Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("reader")},
+	}
+	rdAPI := roleDefinitionAPIMock{data: map[string]*armauthorization.RoleDefinition{
+		"reader": {Properties: &armauthorization.RoleDefinitionProperties{
+			RoleName: util.Ptr("Reader"),
+			Permissions: []*armauthorization.Permission{{
+				Actions:        []*string{util.Ptr("*/read")},
+				NotActions:     []*string{},
+				DataActions:    []*string{},
+				NotDataActions: []*string{},
+			}},
+		}},
+	}}
+	// The role assignments are only listed by the object ID.
+	raAPI := principalRoleAssignmentAPIMock{data: map[string][]*armauthorization.RoleAssignment{
+		azure_utils.RoleAssignmentFilter(objectID, false): {reader},
+	}}
+	rule := v1alpha1.RBACRule{
+		Name:              "rule-1",
+		PrincipalClientID: clientID,
+		Permissions:       []v1alpha1.PermissionSet{{Scope: sub, Actions: []v1alpha1.ActionStr{"Microsoft.Resources/subscriptions/read"}}},
+	}
+
+	tests := []struct {
+		name         string
+		principals   principalAPI
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name: "Validates the service principal of the client ID by its object ID.",
+			principals: principalAPIMock{servicePrincipals: map[string]*azure_utils.GraphDirectoryObject{
+				clientID: {ID: objectID, ODataType: "#microsoft.graph.servicePrincipal", DisplayName: "ci-deployer"},
+			}},
+			wantFailures: []string{},
+			wantDetails: []string{
+				"Client ID " + clientID + " resolved to service principal " + objectID + " named ci-deployer.",
+				"Role assignments were listed with filter principalId eq '" + objectID + "'.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal " + objectID + " at scope " + sub + ".",
+				"Action Microsoft.Resources/subscriptions/read at scope " + sub + " permitted by role assignment " + *reader.ID + ".",
+			},
+		},
+		{
+			name:         "Fails when no service principal has the client ID.",
+			principals:   principalAPIMock{},
+			wantFailures: []string{"No service principal found for client ID " + clientID + "."},
+			wantDetails:  []string{},
+		},
+		{
+			name:         "Fails without Microsoft Graph.",
+			wantFailures: []string{"Client ID " + clientID + " couldn't be resolved to a service principal, because Microsoft Graph couldn't be queried. Set principalId to the service principal's object ID instead."},
+			wantDetails:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil)
+			if tt.principals != nil {
+				svc.WithPrincipals(tt.principals)
+			}
+
+			result, err := svc.ReconcileRBACRule(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+)
+
+// defaultRoleAssignmentLimit is the maximum number of role assignments in most Azure
+// subscriptions. See:
+// https://learn.microsoft.com/en-us/azure/role-based-access-control/troubleshoot-limits
+const defaultRoleAssignmentLimit = 4000
+
+// RoleAssignmentCounts caches the number of role assignments in each subscription. Counting them
+// requires listing every role assignment in the subscription, which can take dozens of pages, so a
+// single RoleAssignmentCounts should be shared by all rules evaluated in a reconcile. It isn't safe
+// for concurrent use.
+type RoleAssignmentCounts struct {
+	// keys = lowercase subscription IDs
+	counts map[string]int
+}
+
+// NewRoleAssignmentCounts creates an empty RoleAssignmentCounts.
+func NewRoleAssignmentCounts() *RoleAssignmentCounts {
+	return &RoleAssignmentCounts{counts: map[string]int{}}
+}
+
+// count returns the number of role assignments in a subscription, listing them with raAPI if they
+// haven't been counted yet. Role assignments inherited from management groups are listed too, but
+// don't count towards the subscription's limit, so they aren't counted.
+func (c *RoleAssignmentCounts) count(raAPI roleAssignmentAPI, subscriptionID string) (int, error) {
+	key := strings.ToLower(subscriptionID)
+	if n, ok := c.counts[key]; ok {
+		return n, nil
+	}
+
+	roleAssignments, err := raAPI.GetRoleAssignmentsForScope(fmt.Sprintf("/subscriptions/%s", subscriptionID), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count role assignments in subscription %s: %w", subscriptionID, azure_errors.AsAugmented(err))
+	}
+	n := 0
+	for _, ra := range roleAssignments {
+		// Role assignments whose scope is unknown are counted, so that the remaining capacity is
+		// never overestimated.
+		if ra.Properties != nil && ra.Properties.Scope != nil && !strings.EqualFold(azure_utils.SubscriptionIDFromScope(*ra.Properties.Scope), subscriptionID) {
+			continue
+		}
+		n++
+	}
+	c.counts[key] = n
+	return n, nil
+}
+
+// checkRoleAssignmentQuota appends a failure for each subscription of the rule's permission sets'
+// scopes that doesn't have room for the number of role assignments the rule's quota requires.
+// Permission sets scoped to management groups aren't checked.
+func (s *RBACRuleService) checkRoleAssignmentQuota(rule v1alpha1.RBACRule, failures *[]string, ev *evidence) error {
+	quota := rule.RoleAssignmentQuota
+	limit := quota.Limit
+	if limit <= 0 {
+		limit = defaultRoleAssignmentLimit
+	}
+
+	checked := map[string]bool{}
+	var scopes []string
+	for _, set := range rule.Permissions {
+		scopes = append(scopes, set.BaseScopes()...)
+	}
+	for _, scope := range scopes {
+		sub := azure_utils.SubscriptionIDFromScope(scope)
+		if sub == "" || checked[strings.ToLower(sub)] {
+			continue
+		}
+		checked[strings.ToLower(sub)] = true
+
+		n, err := s.raCounts.count(s.raAPI, sub)
+		if err != nil {
+			return err
+		}
+		ev.add("Counted %d role assignment(s) in subscription %s, whose limit is %d.", n, sub, limit)
+
+		if available := max(limit-n, 0); available < quota.MinAvailable {
+			*failures = append(*failures, fmt.Sprintf(messages.RoleAssignmentQuotaFailure, sub, available, quota.MinAvailable))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package validators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	armpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	quotaPageSize         = 100
+	quotaRoleDefinitionID = "/providers/Microsoft.Authorization/roleDefinitions/reader"
+)
+
+// pagedRoleAssignmentsARM is a fake ARM that lists, in pages, a number of role assignments in each
+// subscription, plus some inherited from a management group. Role assignments listed for a
+// principal (i.e. with a filter) are a single one permitting all Actions, so that rules only fail
+// because of their quota. It counts the pages listed without a filter per subscription.
+type pagedRoleAssignmentsARM struct {
+	// keys = subscription IDs
+	roleAssignments map[string]int
+	inherited       int
+
+	mu    sync.Mutex
+	pages map[string]int
+}
+
+func (a *pagedRoleAssignmentsARM) Do(req *http.Request) (*http.Response, error) {
+	sub := azure_utils.SubscriptionIDFromScope(req.URL.Path)
+	query := req.URL.Query()
+
+	type roleAssignment struct {
+		ID         string            `json:"id"`
+		Properties map[string]string `json:"properties"`
+	}
+	page := struct {
+		Value    []roleAssignment `json:"value"`
+		NextLink string           `json:"nextLink,omitempty"`
+	}{Value: []roleAssignment{}}
+	newRoleAssignment := func(i int, scope string) roleAssignment {
+		return roleAssignment{
+			ID:         fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignments/%d", scope, i),
+			Properties: map[string]string{"scope": scope, "roleDefinitionId": quotaRoleDefinitionID},
+		}
+	}
+
+	if query.Has("$filter") {
+		page.Value = append(page.Value, newRoleAssignment(0, "/subscriptions/"+sub))
+	} else {
+		a.mu.Lock()
+		a.pages[sub]++
+		a.mu.Unlock()
+
+		// The inherited role assignments are listed after those in the subscription.
+		all := a.roleAssignments[sub] + a.inherited
+		start, _ := strconv.Atoi(query.Get("$skipToken"))
+		for i := start; i < min(start+quotaPageSize, all); i++ {
+			scope := "/subscriptions/" + sub
+			if i >= a.roleAssignments[sub] {
+				scope = "/providers/Microsoft.Management/managementGroups/mg"
+			}
+			page.Value = append(page.Value, newRoleAssignment(i, scope))
+		}
+		if start+quotaPageSize < all {
+			next := *req.URL
+			query.Set("$skipToken", strconv.Itoa(start+quotaPageSize))
+			next.RawQuery = query.Encode()
+			page.NextLink = next.String()
+		}
+	}
+
+	body, err := json.Marshal(page)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}, nil
+}
+
+func (a *pagedRoleAssignmentsARM) pageCount(sub string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pages[sub]
+}
+
+// newQuotaRBACRuleService returns an RBACRuleService whose role assignments are listed from arm.
+func newQuotaRBACRuleService(t *testing.T, arm *pagedRoleAssignmentsARM, raCounts *RoleAssignmentCounts) *RBACRuleService {
+	azClient, err := armauthorization.NewRoleAssignmentsClient("", &azfake.TokenCredential{}, &armpolicy.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Transport: arm,
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdAPI := roleDefinitionAPIMock{data: map[string]*armauthorization.RoleDefinition{
+		quotaRoleDefinitionID: {
+			Properties: &armauthorization.RoleDefinitionProperties{
+				Permissions: []*armauthorization.Permission{
+					{
+						Actions:        []*string{util.Ptr("*")},
+						DataActions:    []*string{},
+						NotActions:     []*string{},
+						NotDataActions: []*string{},
+					},
+				},
+			},
+		},
+	}}
+	return NewRBACRuleService(
+		logr.Discard(),
+		denyAssignmentAPIMock{data: []*armauthorization.DenyAssignment{}},
+		azure_utils.NewAzureRoleAssignmentsClient(context.Background(), azClient),
+		rdAPI,
+		raCounts,
+	)
+}
+
+func quotaRule(name string, quota *v1alpha1.RoleAssignmentQuota, subs ...string) v1alpha1.RBACRule {
+	rule := v1alpha1.RBACRule{
+		Name:                name,
+		PrincipalID:         "00000000-0000-0000-0000-000000000001",
+		RoleAssignmentQuota: quota,
+	}
+	for _, sub := range subs {
+		rule.Permissions = append(rule.Permissions, v1alpha1.PermissionSet{
+			Scope:   fmt.Sprintf("/subscriptions/%s/resourceGroups/rg", sub),
+			Actions: []v1alpha1.ActionStr{"Microsoft.Compute/virtualMachines/read"},
+		})
+	}
+	return rule
+}
+
+func TestRBACRuleService_ReconcileRBACRule_RoleAssignmentQuota(t *testing.T) {
+	const sub = "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name            string
+		roleAssignments int
+		inherited       int
+		quota           v1alpha1.RoleAssignmentQuota
+		wantFailures    []string
+		wantPages       int
+	}{
+		{
+			name:            "Passes when the subscription has room for the required role assignments.",
+			roleAssignments: 3990,
+			quota:           v1alpha1.RoleAssignmentQuota{MinAvailable: 10},
+			wantFailures:    []string{},
+			wantPages:       40,
+		},
+		{
+			name:            "Fails when the subscription is exactly at the limit.",
+			roleAssignments: 4000,
+			quota:           v1alpha1.RoleAssignmentQuota{MinAvailable: 1},
+			wantFailures: []string{
+				"Subscription 00000000-0000-0000-0000-000000000000 has room for 0 more role assignment(s), fewer than the required 1, so creating the role assignments needed to fix this rule may fail.",
+			},
+			wantPages: 40,
+		},
+		{
+			name:            "Fails when the subscription's remaining capacity is below the threshold.",
+			roleAssignments: 3995,
+			quota:           v1alpha1.RoleAssignmentQuota{MinAvailable: 10},
+			wantFailures: []string{
+				"Subscription 00000000-0000-0000-0000-000000000000 has room for 5 more role assignment(s), fewer than the required 10, so creating the role assignments needed to fix this rule may fail.",
+			},
+			wantPages: 40,
+		},
+		{
+			name:            "Doesn't count role assignments inherited from management groups.",
+			roleAssignments: 3990,
+			inherited:       50,
+			quota:           v1alpha1.RoleAssignmentQuota{MinAvailable: 10},
+			wantFailures:    []string{},
+			wantPages:       41,
+		},
+		{
+			name:            "Uses the limit from the quota when provided.",
+			roleAssignments: 450,
+			quota:           v1alpha1.RoleAssignmentQuota{MinAvailable: 100, Limit: 500},
+			wantFailures: []string{
+				"Subscription 00000000-0000-0000-0000-000000000000 has room for 50 more role assignment(s), fewer than the required 100, so creating the role assignments needed to fix this rule may fail.",
+			},
+			wantPages: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arm := &pagedRoleAssignmentsARM{
+				roleAssignments: map[string]int{sub: tt.roleAssignments},
+				inherited:       tt.inherited,
+				pages:           map[string]int{},
+			}
+			svc := newQuotaRBACRuleService(t, arm, nil)
+
+			result, err := svc.ReconcileRBACRule(quotaRule("rule-1", &tt.quota, sub))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := result.Condition.Failures; fmt.Sprint(got) != fmt.Sprint(tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", got, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+			if got := arm.pageCount(sub); got != tt.wantPages {
+				t.Errorf("listed %d page(s), want %d", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_RoleAssignmentQuotaCached(t *testing.T) {
+	const (
+		sub1 = "00000000-0000-0000-0000-000000000001"
+		sub2 = "00000000-0000-0000-0000-000000000002"
+	)
+	arm := &pagedRoleAssignmentsARM{
+		roleAssignments: map[string]int{sub1: 250, sub2: 3999},
+		pages:           map[string]int{},
+	}
+	raCounts := NewRoleAssignmentCounts()
+	quota := &v1alpha1.RoleAssignmentQuota{MinAvailable: 5}
+
+	// Rules evaluated with the same counts (e.g. in the same reconcile) count each subscription
+	// once, even when several of their permission sets are in it.
+	for _, rule := range []v1alpha1.RBACRule{
+		quotaRule("rule-1", quota, sub1, sub1, sub2),
+		quotaRule("rule-2", quota, sub2),
+		quotaRule("rule-3", nil, sub1),
+	} {
+		result, err := newQuotaRBACRuleService(t, arm, raCounts).ReconcileRBACRule(rule)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", rule.Name, err)
+		}
+		wantFailures := 0
+		if rule.RoleAssignmentQuota != nil {
+			wantFailures = 1
+		}
+		if got := len(result.Condition.Failures); got != wantFailures {
+			t.Errorf("%s: got %d failure(s), want %d: %v", rule.Name, got, wantFailures, result.Condition.Failures)
+		}
+	}
+	if got := arm.pageCount(sub1); got != 3 {
+		t.Errorf("listed %d page(s) for subscription 1, want 3", got)
+	}
+	if got := arm.pageCount(sub2); got != 40 {
+		t.Errorf("listed %d page(s) for subscription 2, want 40", got)
+	}
+
+	// Other counts count again.
+	if _, err := newQuotaRBACRuleService(t, arm, NewRoleAssignmentCounts()).ReconcileRBACRule(quotaRule("rule-1", quota, sub1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := arm.pageCount(sub1); got != 6 {
+		t.Errorf("listed %d page(s) for subscription 1, want 6", got)
+	}
+}
@@ -0,0 +1,180 @@
+package validators
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+)
+
+// resourceGroupAPI contains methods that allow listing the resource groups of a subscription.
+type resourceGroupAPI interface {
+	ListResourceGroups(subscriptionID string) ([]string, error)
+}
+
+// WithResourceGroups lets the RBACRuleService expand the scope patterns of permission sets to the
+// resource groups that rgAPI lists.
+func (s *RBACRuleService) WithResourceGroups(rgAPI resourceGroupAPI) *RBACRuleService {
+	s.rgAPI = rgAPI
+	return s
+}
+
+// managementGroupAPI contains methods that allow listing the subscriptions beneath a management
+// group.
+type managementGroupAPI interface {
+	ListDescendantSubscriptions(groupID string) ([]azure_utils.ManagementGroupSubscription, error)
+}
+
+// WithManagementGroups lets the RBACRuleService expand the management groups of permission sets to
+// the subscriptions beneath them that mgAPI lists.
+func (s *RBACRuleService) WithManagementGroups(mgAPI managementGroupAPI) *RBACRuleService {
+	s.mgAPI = mgAPI
+	return s
+}
+
+// scopedPermissionSet is a permission set with a single scope, expanded from a permission set of a
+// rule.
+type scopedPermissionSet struct {
+	v1alpha1.PermissionSet
+	// multiScope is whether the rule's permission set has more than one scope, in which case its
+	// failures must say which scope they're at.
+	multiScope bool
+	// subscriptionName is the display name of the subscription that Scope is, if it was expanded
+	// from a management group and ARM reports it.
+	subscriptionName string
+}
+
+// failureScope returns Scope as permission failures name it: followed by the display name of the
+// subscription it is, if it's known, so that the subscriptions of management groups are told apart
+// by name.
+func (s scopedPermissionSet) failureScope() string {
+	if s.subscriptionName == "" {
+		return s.Scope
+	}
+	return fmt.Sprintf("%s (%s)", s.Scope, s.subscriptionName)
+}
+
+// expandScopes expands a rule's permission sets to one per scope, in the order of scope, scopes,
+// the resource groups matching scopePatterns, and the subscriptions beneath managementGroupId,
+// without duplicates. Scope patterns that match no resource groups fail the rule unless the
+// permission set allows them to, and management groups without subscriptions fail it.
+func (s *RBACRuleService) expandScopes(sets []v1alpha1.PermissionSet, failures *[]string, ev *evidence) ([]scopedPermissionSet, error) {
+	// key = lowercase subscription ID
+	resourceGroups := map[string][]string{}
+	// key = lowercase management group ID
+	managementGroups := map[string][]azure_utils.ManagementGroupSubscription{}
+
+	expanded := make([]scopedPermissionSet, 0, len(sets))
+	for _, set := range sets {
+		multiScope := len(set.Scopes) > 0 || len(set.ScopePatterns) > 0 || set.ManagementGroupID != ""
+		var scopes []string
+		if set.Scope != "" {
+			scopes = append(scopes, set.Scope)
+		}
+		scopes = append(scopes, set.Scopes...)
+		for _, pattern := range set.ScopePatterns {
+			matches, err := s.matchScopePattern(pattern, resourceGroups)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case len(matches) > 0:
+				ev.add("Scope pattern %s matched %d resource group(s): %s.", pattern, len(matches), strings.Join(matches, ", "))
+			case set.AllowEmptyScopePatterns:
+				ev.add("Scope pattern %s matched no resource groups.", pattern)
+			default:
+				*failures = append(*failures, fmt.Sprintf(messages.ScopePatternFailure, pattern))
+			}
+			scopes = append(scopes, matches...)
+		}
+		// key = lowercase scope
+		subscriptionNames := map[string]string{}
+		if set.ManagementGroupID != "" {
+			subs, err := s.managementGroupSubscriptions(set.ManagementGroupID, managementGroups)
+			if err != nil {
+				return nil, err
+			}
+			if len(subs) == 0 {
+				*failures = append(*failures, fmt.Sprintf(messages.ManagementGroupFailure, set.ManagementGroupID))
+			}
+			names := make([]string, 0, len(subs))
+			for _, sub := range subs {
+				scope := "/subscriptions/" + sub.ID
+				scopes = append(scopes, scope)
+				if sub.DisplayName != "" {
+					subscriptionNames[strings.ToLower(scope)] = sub.DisplayName
+					names = append(names, fmt.Sprintf("%s (%s)", sub.DisplayName, sub.ID))
+				} else {
+					names = append(names, sub.ID)
+				}
+			}
+			if len(subs) > 0 {
+				ev.add("Management group %s has %d subscription(s): %s.", set.ManagementGroupID, len(subs), strings.Join(names, ", "))
+			}
+		}
+
+		seen := make(map[string]bool, len(scopes))
+		for _, scope := range scopes {
+			if key := strings.ToLower(scope); !seen[key] {
+				seen[key] = true
+				single := set
+				single.Scope, single.Scopes, single.ScopePatterns, single.ManagementGroupID = scope, nil, nil, ""
+				expanded = append(expanded, scopedPermissionSet{PermissionSet: single, multiScope: multiScope, subscriptionName: subscriptionNames[key]})
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// matchScopePattern returns the scopes of the resource groups matching a scope pattern, in the
+// order they're listed. Each subscription's resource groups are listed at most once per
+// resourceGroups.
+func (s *RBACRuleService) matchScopePattern(pattern string, resourceGroups map[string][]string) ([]string, error) {
+	if err := checkScopePattern(pattern); err != nil {
+		return nil, err
+	}
+	segments := strings.Split(pattern, "/")
+	sub, glob := segments[2], strings.ToLower(segments[4])
+	if s.rgAPI == nil {
+		return nil, fmt.Errorf("scope pattern %s can't be expanded, because resource groups can't be listed", pattern)
+	}
+
+	names, ok := resourceGroups[strings.ToLower(sub)]
+	if !ok {
+		var err error
+		if names, err = s.rgAPI.ListResourceGroups(sub); err != nil {
+			return nil, fmt.Errorf("failed to list resource groups: %w", azure_errors.AsAugmented(err))
+		}
+		resourceGroups[strings.ToLower(sub)] = names
+	}
+	var matches []string
+	for _, name := range names {
+		if ok, _ := path.Match(glob, strings.ToLower(name)); ok {
+			matches = append(matches, fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", sub, name))
+		}
+	}
+	return matches, nil
+}
+
+// managementGroupSubscriptions returns the subscriptions beneath a management group, including
+// those of its nested management groups. Each management group's subscriptions are listed at most
+// once per managementGroups.
+func (s *RBACRuleService) managementGroupSubscriptions(groupID string, managementGroups map[string][]azure_utils.ManagementGroupSubscription) ([]azure_utils.ManagementGroupSubscription, error) {
+	if s.mgAPI == nil {
+		return nil, fmt.Errorf("management group %s can't be expanded, because its subscriptions can't be listed", groupID)
+	}
+	key := strings.ToLower(groupID)
+	if subs, ok := managementGroups[key]; ok {
+		return subs, nil
+	}
+	subs, err := s.mgAPI.ListDescendantSubscriptions(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions of management group %s: %w", groupID, azure_errors.AsAugmented(err))
+	}
+	managementGroups[key] = subs
+	return subs, nil
+}
@@ -0,0 +1,260 @@
+package validators
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+type resourceGroupAPIMock struct {
+	// key = subscription ID
+	data map[string][]string
+	err  error
+}
+
+func (m resourceGroupAPIMock) ListResourceGroups(subscriptionID string) ([]string, error) {
+	return m.data[subscriptionID], m.err
+}
+
+type managementGroupAPIMock struct {
+	// key = management group ID
+	data map[string][]azure_utils.ManagementGroupSubscription
+	err  error
+}
+
+func (m managementGroupAPIMock) ListDescendantSubscriptions(groupID string) ([]azure_utils.ManagementGroupSubscription, error) {
+	return m.data[groupID], m.err
+}
+
+// scopedRoleAssignmentAPIMock is a roleAssignmentAPI implementation for testing that lists
+// different role assignments at each scope.
+type scopedRoleAssignmentAPIMock struct {
+	// key = scope
+	data map[string][]*armauthorization.RoleAssignment
+}
+
+func (m scopedRoleAssignmentAPIMock) GetRoleAssignmentsForScope(scope string, _ *string) ([]*armauthorization.RoleAssignment, error) {
+	return m.data[scope], nil
+}
+
+func TestRBACRuleService_ReconcileRBACRule_Scopes(t *testing.T) {
+	const (
+		sub  = "00000000-0000-0000-0000-000000000000"
+		rgs  = "/subscriptions/" + sub + "/resourceGroups/"
+		role = "/providers/Microsoft.Authorization/roleDefinitions/role"
+		prod = "/subscriptions/11111111-1111-1111-1111-111111111111"
+		dev  = "/subscriptions/22222222-2222-2222-2222-222222222222"
+		test = "/subscriptions/33333333-3333-3333-3333-333333333333"
+	)
+	roleAssignment := func(scope string) []*armauthorization.RoleAssignment {
+		return []*armauthorization.RoleAssignment{{
+			ID:         util.Ptr(scope + "/providers/Microsoft.Authorization/roleAssignments/ra"),
+			Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr(role)},
+		}}
+	}
+	raAPI := scopedRoleAssignmentAPIMock{
+		data: map[string][]*armauthorization.RoleAssignment{
+			rgs + "app-1": roleAssignment(rgs + "app-1"),
+			rgs + "db":    roleAssignment(rgs + "db"),
+			prod:          roleAssignment(prod),
+		},
+	}
+	rdAPI := roleDefinitionAPIMock{
+		data: map[string]*armauthorization.RoleDefinition{
+			role: {
+				Properties: &armauthorization.RoleDefinitionProperties{
+					Permissions: []*armauthorization.Permission{{
+						Actions:        []*string{util.Ptr("a")},
+						NotActions:     []*string{},
+						DataActions:    []*string{},
+						NotDataActions: []*string{},
+					}},
+				},
+			},
+		},
+	}
+	rgAPI := resourceGroupAPIMock{
+		data: map[string][]string{sub: {"app-1", "App-2", "db"}},
+	}
+	mgAPI := managementGroupAPIMock{
+		data: map[string][]azure_utils.ManagementGroupSubscription{
+			"platform": {
+				{ID: "11111111-1111-1111-1111-111111111111", DisplayName: "Production"},
+				{ID: "22222222-2222-2222-2222-222222222222", DisplayName: "Development"},
+				{ID: "33333333-3333-3333-3333-333333333333"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		permissions  []v1alpha1.PermissionSet
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name: "Permission sets with lists of scopes are validated at each, and failures say which scope they're at.",
+			permissions: []v1alpha1.PermissionSet{{
+				Scope:   rgs + "db",
+				Scopes:  []string{rgs + "app-1", rgs + "other"},
+				Actions: []v1alpha1.ActionStr{"a"},
+			}},
+			wantFailures: []string{
+				"At scope " + rgs + "other: Action a unpermitted because no role assignment permits it.",
+			},
+			wantDetails: []string{
+				"Role assignments were listed with filter principalId eq 'p_id'.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + rgs + "db.",
+				"Action a at scope " + rgs + "db permitted by role assignment " + rgs + "db/providers/Microsoft.Authorization/roleAssignments/ra.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + rgs + "app-1.",
+				"Action a at scope " + rgs + "app-1 permitted by role assignment " + rgs + "app-1/providers/Microsoft.Authorization/roleAssignments/ra.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal p_id at scope " + rgs + "other.",
+			},
+		},
+		{
+			name: "Scope patterns are expanded case-insensitively to the subscription's resource groups, without duplicating other scopes.",
+			permissions: []v1alpha1.PermissionSet{{
+				Scope:         rgs + "app-1",
+				ScopePatterns: []string{"/subscriptions/" + sub + "/resourcegroups/APP-*"},
+				Actions:       []v1alpha1.ActionStr{"a"},
+			}},
+			wantFailures: []string{
+				"At scope " + rgs + "App-2: Action a unpermitted because no role assignment permits it.",
+			},
+			wantDetails: []string{
+				"Scope pattern /subscriptions/" + sub + "/resourcegroups/APP-* matched 2 resource group(s): " + rgs + "app-1, " + rgs + "App-2.",
+				"Role assignments were listed with filter principalId eq 'p_id'.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + rgs + "app-1.",
+				"Action a at scope " + rgs + "app-1 permitted by role assignment " + rgs + "app-1/providers/Microsoft.Authorization/roleAssignments/ra.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal p_id at scope " + rgs + "App-2.",
+			},
+		},
+		{
+			name: "Scope patterns that match no resource groups fail.",
+			permissions: []v1alpha1.PermissionSet{{
+				ScopePatterns: []string{rgs + "web-*"},
+				Actions:       []v1alpha1.ActionStr{"a"},
+			}},
+			wantFailures: []string{
+				"Scope pattern " + rgs + "web-* matched no resource groups.",
+			},
+			wantDetails: []string{},
+		},
+		{
+			name: "Scope patterns that match no resource groups pass if allowed.",
+			permissions: []v1alpha1.PermissionSet{{
+				ScopePatterns:           []string{rgs + "web-*"},
+				AllowEmptyScopePatterns: true,
+				Actions:                 []v1alpha1.ActionStr{"a"},
+			}},
+			wantFailures: []string{},
+			wantDetails: []string{
+				"Scope pattern " + rgs + "web-* matched no resource groups.",
+			},
+		},
+		{
+			name: "Management groups are expanded to their subscriptions, and failures at several of them are merged, naming each subscription.",
+			permissions: []v1alpha1.PermissionSet{{
+				ManagementGroupID: "platform",
+				Actions:           []v1alpha1.ActionStr{"a"},
+			}},
+			wantFailures: []string{
+				"At scopes " + dev + " (Development), " + test + ": Action a unpermitted because no role assignment permits it.",
+			},
+			wantDetails: []string{
+				"Management group platform has 3 subscription(s): Production (11111111-1111-1111-1111-111111111111), Development (22222222-2222-2222-2222-222222222222), 33333333-3333-3333-3333-333333333333.",
+				"Role assignments were listed with filter principalId eq 'p_id'.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + prod + ".",
+				"Action a at scope " + prod + " permitted by role assignment " + prod + "/providers/Microsoft.Authorization/roleAssignments/ra.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal p_id at scope " + dev + ".",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal p_id at scope " + test + ".",
+			},
+		},
+		{
+			name: "Management groups without subscriptions fail.",
+			permissions: []v1alpha1.PermissionSet{{
+				ManagementGroupID: "sandbox",
+				Actions:           []v1alpha1.ActionStr{"a"},
+			}},
+			wantFailures: []string{
+				"Management group sandbox has no subscriptions.",
+			},
+			wantDetails: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil).WithResourceGroups(rgAPI).WithManagementGroups(mgAPI)
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:        "rule-1",
+				Permissions: tt.permissions,
+				PrincipalID: "p_id",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_Scopes_Error(t *testing.T) {
+	pattern := v1alpha1.PermissionSet{ScopePatterns: []string{"/subscriptions/s/resourceGroups/app-*"}, Actions: []v1alpha1.ActionStr{"a"}}
+	managementGroup := v1alpha1.PermissionSet{ManagementGroupID: "platform", Actions: []v1alpha1.ActionStr{"a"}}
+	tests := []struct {
+		name  string
+		set   v1alpha1.PermissionSet
+		rgAPI resourceGroupAPI
+		mgAPI managementGroupAPI
+	}{
+		{
+			name:  "Resource groups can't be listed.",
+			set:   pattern,
+			rgAPI: resourceGroupAPIMock{err: errors.New("forbidden")},
+		},
+		{
+			name: "No resource group API was provided.",
+			set:  pattern,
+		},
+		{
+			name:  "The subscriptions of management groups can't be listed.",
+			set:   managementGroup,
+			mgAPI: managementGroupAPIMock{err: errors.New("forbidden")},
+		},
+		{
+			name: "No management group API was provided.",
+			set:  managementGroup,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, roleAssignmentAPIMock{}, roleDefinitionAPIMock{}, nil)
+			if tt.rgAPI != nil {
+				svc.WithResourceGroups(tt.rgAPI)
+			}
+			if tt.mgAPI != nil {
+				svc.WithManagementGroups(tt.mgAPI)
+			}
+			_, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:        "rule-1",
+				Permissions: []v1alpha1.PermissionSet{tt.set},
+				PrincipalID: "p_id",
+			})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
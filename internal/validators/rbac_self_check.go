@@ -0,0 +1,137 @@
+package validators
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+)
+
+// permissionAPI contains methods that allow getting the effective permissions of the plugin's own
+// identity at a scope.
+type permissionAPI interface {
+	ListPermissionsForScope(scope string) ([]*armauthorization.Permission, error)
+}
+
+// WithSelfCheck lets the RBACRuleService validate the permission sets of rules with selfCheck
+// against the effective permissions that permAPI reports, when the rule's principal is
+// selfPrincipalID, the plugin's own identity. selfPrincipalID is empty if the plugin's identity
+// couldn't be determined, in which case such rules are validated as usual.
+func (s *RBACRuleService) WithSelfCheck(permAPI permissionAPI, selfPrincipalID string) *RBACRuleService {
+	s.permAPI = permAPI
+	s.selfPrincipalID = selfPrincipalID
+	return s
+}
+
+// selfChecks reports whether a rule's permission sets may be validated against the plugin's
+// effective permissions for one of the rule's principals, explaining in the evidence why not if
+// the rule asked for it.
+func (s *RBACRuleService) selfChecks(rule v1alpha1.RBACRule, principalID string, ev *evidence) bool {
+	if !rule.SelfCheck {
+		return false
+	}
+	if s.permAPI == nil || s.selfPrincipalID == "" {
+		ev.add("selfCheck was ignored, because the plugin's identity couldn't be determined.")
+		return false
+	}
+	if !strings.EqualFold(principalID, s.selfPrincipalID) {
+		ev.add("selfCheck was ignored, because principal %s isn't the plugin's identity %s.", principalID, s.selfPrincipalID)
+		return false
+	}
+	return true
+}
+
+// hasEffectivePermissions reports whether ARM reports effective permissions at a scope, which it
+// does only for resource groups and resources.
+func hasEffectivePermissions(scope string) bool {
+	id, err := arm.ParseResourceID(scope)
+	return err == nil && id.ResourceGroupName != ""
+}
+
+// processSelfCheckPermissionSet processes a permission set from a selfCheck rule against the
+// effective permissions of the plugin's identity, principalID, at its scope. Those account for all
+// of the identity's role assignments, including inherited and group ones, but not for deny
+// assignments.
+func (s *RBACRuleService) processSelfCheckPermissionSet(set v1alpha1.PermissionSet, principalID string, failures *setFailures, ev *evidence) error {
+	permissions, err := s.permAPI.ListPermissionsForScope(set.Scope)
+	if err != nil {
+		return fmt.Errorf("failed to get effective permissions: %w", azure_errors.AsAugmented(err))
+	}
+	ev.add("Examined %d effective permission(s) of the plugin's identity at scope %s.", len(permissions), set.Scope)
+
+	setActions := make([]string, 0, len(set.Actions))
+	for _, a := range set.Actions {
+		setActions = append(setActions, string(a))
+	}
+	setDataActions := make([]string, 0, len(set.DataActions))
+	for _, da := range set.DataActions {
+		setDataActions = append(setDataActions, string(da))
+	}
+
+	actions, dataActions, err := uncoveredActions(permissions, setActions, setDataActions)
+	if err != nil {
+		return err
+	}
+	for _, a := range setActions {
+		if !slices.Contains(actions.unpermitted, a) {
+			ev.add("Action %s at scope %s permitted by the plugin's effective permissions.", a, set.Scope)
+		}
+	}
+	for _, da := range setDataActions {
+		if !slices.Contains(dataActions.unpermitted, da) {
+			ev.add("DataAction %s at scope %s permitted by the plugin's effective permissions.", da, set.Scope)
+		}
+	}
+	failure := func(kind, action string) messages.PermissionFailure {
+		return messages.PermissionFailure{Kind: kind, Action: action, Scope: set.Scope, PrincipalID: principalID, SelfCheck: true}
+	}
+	for _, unpermitted := range actions.unpermitted {
+		failures.addPermission(failure("Action", unpermitted))
+	}
+	for _, unpermitted := range dataActions.unpermitted {
+		failures.addPermission(failure("DataAction", unpermitted))
+	}
+	return nil
+}
+
+// uncoveredActions determines which candidate Actions and DataActions effective permissions don't
+// cover. Effective permissions can't be denied, so none are reported as denied.
+func uncoveredActions(permissions []*armauthorization.Permission, candidateActions, candidateDataActions []string) (deniedAndUnpermitted, deniedAndUnpermitted, error) {
+	control := make([]roleInfo, 0, len(permissions))
+	data := make([]roleInfo, 0, len(permissions))
+	for _, p := range permissions {
+		if p == nil {
+			continue
+		}
+		c := roleInfo{actions: derefAll(p.Actions), notActions: derefAll(p.NotActions)}
+		d := roleInfo{actions: derefAll(p.DataActions), notActions: derefAll(p.NotDataActions)}
+		for _, actions := range [][]string{c.actions, c.notActions, d.actions, d.notActions} {
+			for _, action := range actions {
+				if numWildcards(action) > 1 {
+					return deniedAndUnpermitted{}, deniedAndUnpermitted{}, fmt.Errorf("effective permission %s has multiple wildcards", action)
+				}
+			}
+		}
+		control = append(control, c)
+		data = append(data, d)
+	}
+	return findDeniedAndUnpermitted(candidateActions, nil, control), findDeniedAndUnpermitted(candidateDataActions, nil, data), nil
+}
+
+// derefAll dereferences Actions from ARM, skipping nil ones. ARM may omit empty lists of effective
+// permissions, so nil lists are empty.
+func derefAll(ptrs []*string) []string {
+	vals := make([]string, 0, len(ptrs))
+	for _, ptr := range ptrs {
+		if ptr != nil {
+			vals = append(vals, *ptr)
+		}
+	}
+	return vals
+}
@@ -0,0 +1,182 @@
+package validators
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+type permissionAPIMock struct {
+	// key = scope
+	data           map[string][]*armauthorization.Permission
+	err            error
+	correlationIDs []string
+}
+
+func (m permissionAPIMock) ListPermissionsForScope(scope string) ([]*armauthorization.Permission, error) {
+	return m.data[scope], m.err
+}
+
+func (m permissionAPIMock) CorrelationIDs() []string {
+	return m.correlationIDs
+}
+
+func TestRBACRuleService_ReconcileRBACRule_SelfCheck(t *testing.T) {
+	const (
+		self = "self_id"
+		sub  = "/subscriptions/00000000-0000-0000-0000-000000000000"
+		rg   = sub + "/resourceGroups/rg"
+	)
+	permAPI := permissionAPIMock{
+		data: map[string][]*armauthorization.Permission{
+			rg: {
+				// ARM omits empty lists.
+				{
+					Actions:    []*string{util.Ptr("Microsoft.Compute/*")},
+					NotActions: []*string{util.Ptr("Microsoft.Compute/virtualMachines/delete")},
+				},
+				{
+					Actions:     []*string{util.Ptr("*/read")},
+					DataActions: []*string{util.Ptr("Microsoft.Storage/storageAccounts/blobServices/containers/blobs/*")},
+					NotDataActions: []*string{
+						util.Ptr("Microsoft.Storage/storageAccounts/blobServices/containers/blobs/delete"),
+					},
+				},
+			},
+		},
+		correlationIDs: []string{"c-1"},
+	}
+
+	tests := []struct {
+		name         string
+		principalID  string
+		permissions  []v1alpha1.PermissionSet
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name:        "Actions permitted by wildcards in the effective permissions pass.",
+			principalID: self,
+			permissions: []v1alpha1.PermissionSet{{
+				Scope:       rg,
+				Actions:     []v1alpha1.ActionStr{"Microsoft.Compute/virtualMachines/write", "Microsoft.Network/virtualNetworks/read"},
+				DataActions: []v1alpha1.ActionStr{"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read"},
+			}},
+			wantFailures: []string{},
+			wantDetails: []string{
+				"ARM request IDs: c-1",
+				"Examined 2 effective permission(s) of the plugin's identity at scope " + rg + ".",
+				"Action Microsoft.Compute/virtualMachines/write at scope " + rg + " permitted by the plugin's effective permissions.",
+				"Action Microsoft.Network/virtualNetworks/read at scope " + rg + " permitted by the plugin's effective permissions.",
+				"DataAction Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read at scope " + rg + " permitted by the plugin's effective permissions.",
+			},
+		},
+		{
+			name:        "Actions excluded by NotActions, or not covered at all, fail.",
+			principalID: "SELF_ID",
+			permissions: []v1alpha1.PermissionSet{{
+				Scope:       rg,
+				Actions:     []v1alpha1.ActionStr{"Microsoft.Compute/virtualMachines/delete", "Microsoft.Network/virtualNetworks/write"},
+				DataActions: []v1alpha1.ActionStr{"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/delete"},
+			}},
+			wantFailures: []string{
+				"Action Microsoft.Compute/virtualMachines/delete at scope " + rg + " not covered by the plugin's effective permissions.",
+				"Action Microsoft.Network/virtualNetworks/write at scope " + rg + " not covered by the plugin's effective permissions.",
+				"DataAction Microsoft.Storage/storageAccounts/blobServices/containers/blobs/delete at scope " + rg + " not covered by the plugin's effective permissions.",
+			},
+			wantDetails: []string{
+				"ARM request IDs: c-1",
+				"Examined 2 effective permission(s) of the plugin's identity at scope " + rg + ".",
+			},
+		},
+		{
+			name:        "Rules for other principals are validated against their role assignments.",
+			principalID: "other_id",
+			permissions: []v1alpha1.PermissionSet{{Scope: rg, Actions: []v1alpha1.ActionStr{"a"}}},
+			wantFailures: []string{
+				"Action a unpermitted because no role assignment permits it.",
+			},
+			wantDetails: []string{
+				"ARM request IDs: c-1",
+				"selfCheck was ignored, because principal other_id isn't the plugin's identity self_id.",
+				"Role assignments were listed with filter principalId eq 'other_id'.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal other_id at scope " + rg + ".",
+			},
+		},
+		{
+			name:        "Permission sets at subscriptions are validated against role assignments.",
+			principalID: self,
+			permissions: []v1alpha1.PermissionSet{{Scope: sub, Actions: []v1alpha1.ActionStr{"a"}}},
+			wantFailures: []string{
+				"Action a unpermitted because no role assignment permits it.",
+			},
+			wantDetails: []string{
+				"ARM request IDs: c-1",
+				"Permission set at scope " + sub + " was validated against role assignments, because ARM only reports effective permissions at resource groups and resources.",
+				"Role assignments were listed with filter principalId eq 'self_id'.",
+				"Examined 0 deny assignment(s) and 0 role assignment(s) for principal self_id at scope " + sub + ".",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, roleAssignmentAPIMock{}, roleDefinitionAPIMock{}, nil).
+				WithSelfCheck(permAPI, self)
+
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:        "rule-1",
+				Permissions: tt.permissions,
+				PrincipalID: tt.principalID,
+				SelfCheck:   true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_SelfCheck_UnknownIdentity(t *testing.T) {
+	svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, roleAssignmentAPIMock{}, roleDefinitionAPIMock{}, nil).
+		WithSelfCheck(permissionAPIMock{err: errors.New("unexpected call")}, "")
+
+	result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+		Name:        "rule-1",
+		Permissions: []v1alpha1.PermissionSet{{Scope: "/subscriptions/s/resourceGroups/rg", Actions: []v1alpha1.ActionStr{"a"}}},
+		PrincipalID: "p_id",
+		SelfCheck:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "selfCheck was ignored, because the plugin's identity couldn't be determined."; result.Condition.Details[0] != want {
+		t.Errorf("got first detail %q, want %q", result.Condition.Details[0], want)
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_SelfCheck_Error(t *testing.T) {
+	svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, roleAssignmentAPIMock{}, roleDefinitionAPIMock{}, nil).
+		WithSelfCheck(permissionAPIMock{err: errors.New("forbidden")}, "p_id")
+
+	_, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+		Name:        "rule-1",
+		Permissions: []v1alpha1.PermissionSet{{Scope: "/subscriptions/s/resourceGroups/rg", Actions: []v1alpha1.ActionStr{"a"}}},
+		PrincipalID: "p_id",
+		SelfCheck:   true,
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -2,12 +2,18 @@ package validators
 
 import (
 	"errors"
+	"net/http"
+	"reflect"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
 	"github.com/spectrocloud-labs/validator/pkg/util"
@@ -24,14 +30,19 @@ func (m denyAssignmentAPIMock) GetDenyAssignmentsForScope(_ string, _ *string) (
 }
 
 type roleAssignmentAPIMock struct {
-	data []*armauthorization.RoleAssignment
-	err  error
+	data           []*armauthorization.RoleAssignment
+	err            error
+	correlationIDs []string
 }
 
 func (m roleAssignmentAPIMock) GetRoleAssignmentsForScope(_ string, _ *string) ([]*armauthorization.RoleAssignment, error) {
 	return m.data, m.err
 }
 
+func (m roleAssignmentAPIMock) CorrelationIDs() []string {
+	return m.correlationIDs
+}
+
 type roleDefinitionAPIMock struct {
 	// key = roleID
 	data map[string]*armauthorization.RoleDefinition
@@ -89,12 +100,14 @@ func TestRBACRuleService_ReconcileRBACRule(t *testing.T) {
 			raAPIMock: roleAssignmentAPIMock{
 				data: []*armauthorization.RoleAssignment{
 					{
+						ID: util.Ptr("ra_id"),
 						Properties: &armauthorization.RoleAssignmentProperties{
 							RoleDefinitionID: util.Ptr("role_id"),
 						},
 					},
 				},
-				err: nil,
+				err:            nil,
+				correlationIDs: []string{"c1", "c2", "c1"},
 			},
 			rdAPIMock: roleDefinitionAPIMock{
 				data: map[string]*armauthorization.RoleDefinition{
@@ -118,10 +131,16 @@ func TestRBACRuleService_ReconcileRBACRule(t *testing.T) {
 				Condition: &vapi.ValidationCondition{
 					ValidationType: "azure-rbac",
 					ValidationRule: "validation-rule-1",
-					Message:        "Principal has all required permissions.",
-					Details:        []string{},
-					Failures:       []string{},
-					Status:         corev1.ConditionTrue,
+					Message:        messages.RBACSucceeded,
+					Details: []string{
+						"ARM request IDs: c1, c2",
+						"Role assignments were listed with filter principalId eq 'p_id'.",
+						"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope /subscriptions/00000000-0000-0000-0000-000000000000.",
+						"Action a at scope /subscriptions/00000000-0000-0000-0000-000000000000 permitted by role assignment ra_id.",
+						"DataAction b at scope /subscriptions/00000000-0000-0000-0000-000000000000 permitted by role assignment ra_id.",
+					},
+					Failures: []string{},
+					Status:   corev1.ConditionTrue,
 				},
 				State: util.Ptr(vapi.ValidationSucceeded),
 			},
@@ -189,8 +208,11 @@ func TestRBACRuleService_ReconcileRBACRule(t *testing.T) {
 				Condition: &vapi.ValidationCondition{
 					ValidationType: "azure-rbac",
 					ValidationRule: "validation-rule-1",
-					Message:        "Principal lacks required permissions. See failures for details.",
-					Details:        []string{},
+					Message:        messages.RBACFailed,
+					Details: []string{
+						"Role assignments were listed with filter principalId eq 'p_id'.",
+						"Examined 1 deny assignment(s) and 1 role assignment(s) for principal p_id at scope /subscriptions/00000000-0000-0000-0000-000000000000.",
+					},
 					Failures: []string{
 						"Action a denied by deny assignment d.",
 						"DataAction b denied by deny assignment d.",
@@ -249,8 +271,11 @@ func TestRBACRuleService_ReconcileRBACRule(t *testing.T) {
 				Condition: &vapi.ValidationCondition{
 					ValidationType: "azure-rbac",
 					ValidationRule: "validation-rule-1",
-					Message:        "Principal lacks required permissions. See failures for details.",
-					Details:        []string{},
+					Message:        messages.RBACFailed,
+					Details: []string{
+						"Role assignments were listed with filter principalId eq 'p_id'.",
+						"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope /subscriptions/00000000-0000-0000-0000-000000000000.",
+					},
 					Failures: []string{
 						"Action a unpermitted because no role assignment permits it.",
 						"DataAction b unpermitted because no role assignment permits it.",
@@ -262,7 +287,7 @@ func TestRBACRuleService_ReconcileRBACRule(t *testing.T) {
 		},
 	}
 	for _, c := range cs {
-		svc := NewRBACRuleService(c.daAPIMock, c.raAPIMock, c.rdAPIMock)
+		svc := NewRBACRuleService(logr.Discard(), c.daAPIMock, c.raAPIMock, c.rdAPIMock, nil)
 		result, err := svc.ReconcileRBACRule(c.rule)
 		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
 	}
@@ -308,7 +333,7 @@ func TestRBACRuleService_processPermissionSet(t *testing.T) {
 	type args struct {
 		set         v1alpha1.PermissionSet
 		principalID string
-		failures    *[]string
+		failures    *setFailures
 	}
 	tests := []struct {
 		name    string
@@ -413,9 +438,394 @@ func TestRBACRuleService_processPermissionSet(t *testing.T) {
 				raAPI: tt.fields.raAPI,
 				rdAPI: tt.fields.rdAPI,
 			}
-			if err := s.processPermissionSet(tt.args.set, tt.args.principalID, tt.args.failures); (err != nil) != tt.wantErr {
+			if err := s.processPermissionSet(scopedPermissionSet{PermissionSet: tt.args.set}, newRBACQueries(tt.args.principalID, false, ""), tt.args.failures, &evidence{}); (err != nil) != tt.wantErr {
 				t.Errorf("RBACRuleService.processPermissionSet() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+// logEntry is a log entry recorded by recordingSink.
+type logEntry struct {
+	level         int
+	msg           string
+	err           error
+	keysAndValues map[string]any
+}
+
+// recordingSink is a logr.LogSink that records all log entries, at all levels, for testing.
+type recordingSink struct {
+	entries *[]logEntry
+	values  []any
+}
+
+func (s recordingSink) Init(logr.RuntimeInfo) {}
+
+func (s recordingSink) Enabled(int) bool { return true }
+
+func (s recordingSink) Info(level int, msg string, keysAndValues ...any) {
+	s.record(level, msg, nil, keysAndValues)
+}
+
+func (s recordingSink) Error(err error, msg string, keysAndValues ...any) {
+	s.record(0, msg, err, keysAndValues)
+}
+
+func (s recordingSink) WithValues(keysAndValues ...any) logr.LogSink {
+	s.values = append(append([]any{}, s.values...), keysAndValues...)
+	return s
+}
+
+func (s recordingSink) WithName(string) logr.LogSink { return s }
+
+func (s recordingSink) record(level int, msg string, err error, keysAndValues []any) {
+	kvs := map[string]any{}
+	all := append(append([]any{}, s.values...), keysAndValues...)
+	for i := 0; i+1 < len(all); i += 2 {
+		kvs[all[i].(string)] = all[i+1]
+	}
+	*s.entries = append(*s.entries, logEntry{level: level, msg: msg, err: err, keysAndValues: kvs})
+}
+
+func TestRBACRuleService_ReconcileRBACRule_Logging(t *testing.T) {
+	scope := "/subscriptions/00000000-0000-0000-0000-000000000000"
+	rule := v1alpha1.RBACRule{
+		Name:        "rule-1",
+		Permissions: []v1alpha1.PermissionSet{{Actions: []v1alpha1.ActionStr{"a"}, Scope: scope}},
+		PrincipalID: "p",
+	}
+	header := http.Header{}
+	header.Set(azure_errors.CorrelationIDHeader, "correlation-id")
+	azErr := &azcore.ResponseError{
+		ErrorCode:   "AuthorizationFailed",
+		StatusCode:  http.StatusForbidden,
+		RawResponse: &http.Response{StatusCode: http.StatusForbidden, Header: header},
+	}
+
+	entries := []logEntry{}
+	svc := NewRBACRuleService(
+		logr.New(recordingSink{entries: &entries}),
+		&fakeDAAPI{d1: []*armauthorization.DenyAssignment{}},
+		&fakeRAAPI{d2: azErr},
+		&fakeRDAPI{},
+		nil,
+	)
+	result, err := svc.ReconcileRBACRule(rule)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	want := []logEntry{
+		{
+			level: 1,
+			msg:   "Processing permission set",
+			keysAndValues: map[string]any{
+				"ruleName":       "rule-1",
+				"validationType": "azure-rbac",
+				"scope":          scope,
+			},
+		},
+		{
+			level: 0,
+			msg:   "failed to process permission set",
+			err:   err,
+			keysAndValues: map[string]any{
+				"ruleName":       "rule-1",
+				"validationType": "azure-rbac",
+				"scope":          scope,
+				"correlationID":  "correlation-id",
+				"errorCategory":  azure_errors.CategoryForbidden,
+			},
+		},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d log entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, e := range entries {
+		w := want[i]
+		if e.level != w.level || e.msg != w.msg || (w.err != nil && e.err.Error() != w.err.Error()) {
+			t.Errorf("log entry %d: got %+v, want %+v", i, e, w)
+		}
+		for k, v := range w.keysAndValues {
+			if e.keysAndValues[k] != v {
+				t.Errorf("log entry %d: got %s=%v, want %v", i, k, e.keysAndValues[k], v)
+			}
+		}
+	}
+
+	wantDetails := []string{"Error category: Forbidden", "Azure correlation request ID: correlation-id"}
+	if !reflect.DeepEqual(result.Condition.Details, wantDetails) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, wantDetails)
+	}
+}
+
+// filteredRAAPI is a raAPI implementation for testing whose role assignments depend on the filter.
+// It records the filters it's called with.
+type filteredRAAPI struct {
+	// key = filter
+	data    map[string][]*armauthorization.RoleAssignment
+	filters []string
+}
+
+func (api *filteredRAAPI) GetRoleAssignmentsForScope(_ string, filter *string) ([]*armauthorization.RoleAssignment, error) {
+	api.filters = append(api.filters, *filter)
+	return api.data[*filter], nil
+}
+
+func TestRBACRuleService_ReconcileRBACRule_RoleAssignmentFilters(t *testing.T) {
+	scope := "/subscriptions/00000000-0000-0000-0000-000000000000"
+	newRoleAssignment := func(id, principalID, roleDefinitionID string) *armauthorization.RoleAssignment {
+		return &armauthorization.RoleAssignment{
+			ID: util.Ptr(id),
+			Properties: &armauthorization.RoleAssignmentProperties{
+				PrincipalID:      util.Ptr(principalID),
+				RoleDefinitionID: util.Ptr(roleDefinitionID),
+			},
+		}
+	}
+	newRoleDefinition := func(id, action string) *armauthorization.RoleDefinition {
+		return &armauthorization.RoleDefinition{
+			ID: util.Ptr(id),
+			Properties: &armauthorization.RoleDefinitionProperties{
+				Permissions: []*armauthorization.Permission{{
+					Actions:        []*string{util.Ptr(action)},
+					NotActions:     []*string{},
+					DataActions:    []*string{},
+					NotDataActions: []*string{},
+				}},
+			},
+		}
+	}
+	direct := newRoleAssignment("ra-direct", "p_id", "rd-a")
+	viaGroup := newRoleAssignment("ra-group", "g_id", "rd-b")
+	raAPI := &filteredRAAPI{data: map[string][]*armauthorization.RoleAssignment{
+		"principalId+eq+%27p_id%27":     {direct},
+		"assignedTo%28%27p_id%27%29":    {viaGroup, direct},
+		"atScope()":                     {viaGroup, direct},
+		"principalId%20eq%20%27P_ID%27": {direct},
+	}}
+	rdAPI := roleDefinitionAPIMock{data: map[string]*armauthorization.RoleDefinition{
+		"rd-a": newRoleDefinition("rd-a", "a"),
+		"rd-b": newRoleDefinition("rd-b", "b"),
+	}}
+
+	tests := []struct {
+		name         string
+		expand       bool
+		rawFilter    string
+		wantFilter   string
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name:         "Only role assignments made to the principal count by default.",
+			wantFilter:   "principalId+eq+%27p_id%27",
+			wantFailures: []string{"Action b unpermitted because no role assignment permits it."},
+			wantDetails: []string{
+				"Role assignments were listed with filter principalId eq 'p_id'.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + scope + ".",
+				"Action a at scope " + scope + " permitted by role assignment ra-direct.",
+			},
+		},
+		{
+			name:         "Role assignments made to the principal's groups count when groups are expanded.",
+			expand:       true,
+			wantFilter:   "assignedTo%28%27p_id%27%29",
+			wantFailures: []string{},
+			wantDetails: []string{
+				"Role assignments were listed with filter assignedTo('p_id'), which includes those of the principal's groups.",
+				"Examined 0 deny assignment(s) and 2 role assignment(s) for principal p_id at scope " + scope + ".",
+				"Action a at scope " + scope + " permitted by role assignment ra-direct.",
+				"Action b at scope " + scope + " permitted by role assignment ra-group.",
+			},
+		},
+		{
+			name:         "Raw filters are passed verbatim, and role assignments of other principals they list are dropped.",
+			rawFilter:    "atScope()",
+			wantFilter:   "atScope()",
+			wantFailures: []string{"Action b unpermitted because no role assignment permits it."},
+			wantDetails: []string{
+				"Role assignments were listed with raw filter atScope(), and only those of principal p_id were kept.",
+				"Dropped 1 role assignment(s) of other principals that the raw filter listed at scope " + scope + ".",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + scope + ".",
+				"Action a at scope " + scope + " permitted by role assignment ra-direct.",
+			},
+		},
+		{
+			name:         "Role assignments of the principal are kept regardless of the case of its ID in the raw filter.",
+			rawFilter:    "principalId%20eq%20%27P_ID%27",
+			wantFilter:   "principalId%20eq%20%27P_ID%27",
+			wantFailures: []string{"Action b unpermitted because no role assignment permits it."},
+			wantDetails: []string{
+				"Role assignments were listed with raw filter principalId%20eq%20%27P_ID%27, and only those of principal p_id were kept.",
+				"Examined 0 deny assignment(s) and 1 role assignment(s) for principal p_id at scope " + scope + ".",
+				"Action a at scope " + scope + " permitted by role assignment ra-direct.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raAPI.filters = nil
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil)
+
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:                  "rule-1",
+				Permissions:           []v1alpha1.PermissionSet{{Actions: []v1alpha1.ActionStr{"a", "b"}, Scope: scope}},
+				PrincipalID:           "p_id",
+				ExpandPrincipalGroups: tt.expand,
+				RawFilter:             tt.rawFilter,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := []string{tt.wantFilter}; !reflect.DeepEqual(raAPI.filters, want) {
+				t.Errorf("got filters %v, want %v", raAPI.filters, want)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_PrincipalIDs(t *testing.T) {
+	scope := "/subscriptions/00000000-0000-0000-0000-000000000000"
+	raAPI := &filteredRAAPI{data: map[string][]*armauthorization.RoleAssignment{
+		"principalId+eq+%27kubelet%27": {{
+			ID:         util.Ptr("ra-kubelet"),
+			Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("role-1")},
+		}},
+	}}
+	rdAPI := roleDefinitionAPIMock{data: map[string]*armauthorization.RoleDefinition{
+		"role-1": {
+			Properties: &armauthorization.RoleDefinitionProperties{
+				Permissions: []*armauthorization.Permission{{
+					Actions:        []*string{util.Ptr("a")},
+					NotActions:     []*string{},
+					DataActions:    []*string{},
+					NotDataActions: []*string{},
+				}},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name         string
+		principalID  string
+		principalIDs []string
+		wantFilters  []string
+		wantFailures []string
+	}{
+		{
+			name:         "Each principal is validated, and failures start with the principal when there are several.",
+			principalID:  "kubelet",
+			principalIDs: []string{"control-plane"},
+			wantFilters:  []string{"principalId+eq+%27kubelet%27", "principalId+eq+%27control-plane%27"},
+			wantFailures: []string{"Principal control-plane: Action a unpermitted because no role assignment permits it."},
+		},
+		{
+			name:         "principalIds may replace principalId, and duplicates are validated once.",
+			principalIDs: []string{"kubelet", "KUBELET"},
+			wantFilters:  []string{"principalId+eq+%27kubelet%27"},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Failures don't start with the principal when there's one.",
+			principalID:  "control-plane",
+			wantFilters:  []string{"principalId+eq+%27control-plane%27"},
+			wantFailures: []string{"Action a unpermitted because no role assignment permits it."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raAPI.filters = nil
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil)
+
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:         "rule-1",
+				Permissions:  []v1alpha1.PermissionSet{{Actions: []v1alpha1.ActionStr{"a"}, Scope: scope}},
+				PrincipalID:  tt.principalID,
+				PrincipalIDs: tt.principalIDs,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(raAPI.filters, tt.wantFilters) {
+				t.Errorf("got filters %v, want %v", raAPI.filters, tt.wantFilters)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if want := "validation-rule-1"; result.Condition.ValidationRule != want {
+				t.Errorf("got validation rule %s, want %s", result.Condition.ValidationRule, want)
+			}
+		})
+	}
+}
+
+func TestRBACRuleService_ReconcileRBACRule_FailureMessageTemplate(t *testing.T) {
+	const scope = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	raAPI := roleAssignmentAPIMock{
+		data: []*armauthorization.RoleAssignment{{
+			ID:         util.Ptr("ra-1"),
+			Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("role-1")},
+		}},
+	}
+	rdAPI := roleDefinitionAPIMock{
+		data: map[string]*armauthorization.RoleDefinition{
+			"role-1": {
+				Properties: &armauthorization.RoleDefinitionProperties{
+					RoleName: util.Ptr("Reader"),
+					Permissions: []*armauthorization.Permission{{
+						Actions:        []*string{util.Ptr("a")},
+						NotActions:     []*string{},
+						DataActions:    []*string{},
+						NotDataActions: []*string{},
+					}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		template     string
+		wantFailures []string
+	}{
+		{
+			name:         "The built-in template is used without a failure message template.",
+			wantFailures: []string{"Action b unpermitted because no role assignment permits it."},
+		},
+		{
+			name:         "Failures are rendered with the failure message template.",
+			template:     `{{.PrincipalID}} needs {{.Action}} at {{.Scope}}, but only has {{join .Roles ", "}}.`,
+			wantFailures: []string{"p_id needs b at " + scope + ", but only has Reader."},
+		},
+		{
+			name:         "The rule isn't evaluated when the failure message template is invalid.",
+			template:     `{{.Role}} lacks {{.Action}}`,
+			wantFailures: []string{"Rule's spec has 1 problem(s), which the azure-spec condition lists."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRBACRuleService(logr.Discard(), denyAssignmentAPIMock{}, raAPI, rdAPI, nil)
+
+			result, err := svc.ReconcileRBACRule(v1alpha1.RBACRule{
+				Name:                   "rule-1",
+				Permissions:            []v1alpha1.PermissionSet{{Actions: []v1alpha1.ActionStr{"a", "b"}, Scope: scope}},
+				PrincipalID:            "p_id",
+				FailureMessageTemplate: tt.template,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// managementLockAPI contains methods that allow getting the management locks that apply to a
+// resource group or resource.
+type managementLockAPI interface {
+	ListLocksForScope(scope string) ([]*armlocks.ManagementLockObject, error)
+}
+
+type ResourceLockRuleService struct {
+	log logr.Logger
+	api managementLockAPI
+}
+
+func NewResourceLockRuleService(log logr.Logger, api managementLockAPI) *ResourceLockRuleService {
+	return &ResourceLockRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileResourceLockRule reconciles a resource lock rule from a validation config.
+func (s *ResourceLockRuleService) ReconcileResourceLockRule(rule v1alpha1.ResourceLockRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this resource lock rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.ResourceLockSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeResourceLock
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeResourceLock)
+	l.V(1).Info("Validating resource locks")
+	ev := &evidence{}
+	for _, scope := range rule.Scopes {
+		if err := s.validateScope(rule, scope, &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(l, "failed to validate resource locks", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.ResourceLockFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateScope appends a failure if no lock with the rule's level applies to the scope, or if a
+// ReadOnly lock does and the rule forbids it. A scope that doesn't exist is a failure, not an
+// error.
+func (s *ResourceLockRuleService) validateScope(rule v1alpha1.ResourceLockRule, scope string, failures *[]string, ev *evidence) error {
+	locks, err := s.api.ListLocksForScope(scope)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.ScopeNotFoundFailure, scope))
+			return nil
+		}
+		return fmt.Errorf("failed to list locks of %s: %w", scope, azure_errors.AsAugmented(err))
+	}
+	sortByID(locks, func(l *armlocks.ManagementLockObject) *string { return l.ID })
+
+	var readOnly []string
+	levels := map[string]bool{}
+	for _, l := range locks {
+		level := notSet
+		if l.Properties != nil && l.Properties.Level != nil {
+			level = string(*l.Properties.Level)
+		}
+		levels[level] = true
+		if strings.EqualFold(level, string(armlocks.LockLevelReadOnly)) {
+			readOnly = append(readOnly, strPtrValue(l.ID))
+		}
+		ev.add("Lock %s with level %s applies to scope %s.", strPtrValue(l.ID), level, scope)
+	}
+	present := lockLevels(levels)
+
+	if rule.Level != "" && !hasLockLevel(levels, rule.Level) {
+		*failures = append(*failures, fmt.Sprintf(messages.LockMissingFailure, scope, rule.Level, present))
+	}
+	if rule.ForbidReadOnly && len(readOnly) > 0 {
+		*failures = append(*failures, fmt.Sprintf(messages.ReadOnlyLockFailure, scope, strings.Join(readOnly, ", ")))
+	}
+	return nil
+}
+
+// hasLockLevel reports whether a lock with a level, or a stricter one, is among levels. ReadOnly
+// locks prevent deletion too, so they satisfy CanNotDelete.
+func hasLockLevel(levels map[string]bool, want string) bool {
+	for level := range levels {
+		if strings.EqualFold(level, want) {
+			return true
+		}
+		if strings.EqualFold(want, string(armlocks.LockLevelCanNotDelete)) && strings.EqualFold(level, string(armlocks.LockLevelReadOnly)) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockLevels lists lock levels in order, for failures.
+func lockLevels(levels map[string]bool) string {
+	if len(levels) == 0 {
+		return "(none)"
+	}
+	names := make([]string, 0, len(levels))
+	for level := range levels {
+		names = append(names, level)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
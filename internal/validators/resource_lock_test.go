@@ -0,0 +1,149 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testLockSubscription = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	testLockScope        = testLockSubscription + "/resourceGroups/prod"
+)
+
+// managementLockAPIMock is a fake ARM whose data maps scopes to the locks that apply to them.
+// Listing the locks of any other scope fails with a 404, unless err is set.
+type managementLockAPIMock struct {
+	data map[string][]*armlocks.ManagementLockObject
+	err  error
+}
+
+func (m managementLockAPIMock) ListLocksForScope(scope string) ([]*armlocks.ManagementLockObject, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	locks, ok := m.data[scope]
+	if !ok {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceGroupNotFound", StatusCode: http.StatusNotFound}
+	}
+	return locks, nil
+}
+
+// newLock returns a lock named name, created at scope, with a level.
+func newLock(scope, name string, level armlocks.LockLevel) *armlocks.ManagementLockObject {
+	return &armlocks.ManagementLockObject{
+		ID:         util.Ptr(scope + "/providers/Microsoft.Authorization/locks/" + name),
+		Name:       util.Ptr(name),
+		Properties: &armlocks.ManagementLockProperties{Level: util.Ptr(level)},
+	}
+}
+
+func TestResourceLockRuleService_ReconcileResourceLockRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		locks        []*armlocks.ManagementLockObject
+		missing      bool
+		level        string
+		forbid       bool
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when a lock with the required level applies to the scope.",
+			locks:        []*armlocks.ManagementLockObject{newLock(testLockScope, "no-delete", armlocks.LockLevelCanNotDelete)},
+			level:        "CanNotDelete",
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when the required lock is inherited from the subscription.",
+			locks:        []*armlocks.ManagementLockObject{newLock(testLockSubscription, "sub-no-delete", armlocks.LockLevelCanNotDelete)},
+			level:        "CanNotDelete",
+			forbid:       true,
+			wantFailures: []string{},
+		},
+		{
+			name:         "Passes when a ReadOnly lock applies and CanNotDelete is required.",
+			locks:        []*armlocks.ManagementLockObject{newLock(testLockScope, "read-only", armlocks.LockLevelReadOnly)},
+			level:        "CanNotDelete",
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when no lock applies.",
+			level:        "CanNotDelete",
+			wantFailures: []string{"Scope " + testLockScope + " requires a CanNotDelete lock, but the locks that apply to it have levels: (none)."},
+		},
+		{
+			name:         "Fails when only a CanNotDelete lock applies and ReadOnly is required.",
+			locks:        []*armlocks.ManagementLockObject{newLock(testLockScope, "no-delete", armlocks.LockLevelCanNotDelete)},
+			level:        "ReadOnly",
+			wantFailures: []string{"Scope " + testLockScope + " requires a ReadOnly lock, but the locks that apply to it have levels: CanNotDelete."},
+		},
+		{
+			name: "Fails when a ReadOnly lock is inherited from the subscription and ReadOnly locks are forbidden.",
+			locks: []*armlocks.ManagementLockObject{
+				newLock(testLockScope, "no-delete", armlocks.LockLevelCanNotDelete),
+				newLock(testLockSubscription, "sub-read-only", armlocks.LockLevelReadOnly),
+			},
+			level:        "CanNotDelete",
+			forbid:       true,
+			wantFailures: []string{"Scope " + testLockScope + " must not have a ReadOnly lock, but ReadOnly locks " + testLockSubscription + "/providers/Microsoft.Authorization/locks/sub-read-only apply to it."},
+		},
+		{
+			name:         "Fails when the scope doesn't exist.",
+			missing:      true,
+			level:        "CanNotDelete",
+			wantFailures: []string{"Scope " + testLockScope + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := managementLockAPIMock{data: map[string][]*armlocks.ManagementLockObject{}}
+			if !tt.missing {
+				api.data[testLockScope] = tt.locks
+			}
+			svc := NewResourceLockRuleService(logr.Discard(), api)
+
+			result, err := svc.ReconcileResourceLockRule(v1alpha1.ResourceLockRule{
+				Name:           "rule-1",
+				Scopes:         []string{testLockScope},
+				Level:          tt.level,
+				ForbidReadOnly: tt.forbid,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestResourceLockRuleService_ReconcileResourceLockRule_Error(t *testing.T) {
+	api := managementLockAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewResourceLockRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileResourceLockRule(v1alpha1.ResourceLockRule{Name: "rule-1", Scopes: []string{testLockScope}, Level: "CanNotDelete"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,188 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// defaultRoutePrefix is the address prefix of a default route, normalized.
+const defaultRoutePrefix = "0.0.0.0/0"
+
+// routeTableAPI contains methods that allow getting a route table by its resource ID.
+type routeTableAPI interface {
+	GetRouteTable(routeTableID string) (*armnetwork.RouteTable, error)
+}
+
+type RouteTableRuleService struct {
+	log           logr.Logger
+	subnetAPI     subnetAPI
+	routeTableAPI routeTableAPI
+}
+
+// NewRouteTableRuleService creates a RouteTableRuleService. subnetAPI is only used for rules that
+// discover their route table from a subnet.
+func NewRouteTableRuleService(log logr.Logger, subnetAPI subnetAPI, routeTableAPI routeTableAPI) *RouteTableRuleService {
+	return &RouteTableRuleService{
+		log:           log,
+		subnetAPI:     subnetAPI,
+		routeTableAPI: routeTableAPI,
+	}
+}
+
+// ReconcileRouteTableRule reconciles a route table rule from a validation config.
+func (s *RouteTableRuleService) ReconcileRouteTableRule(rule v1alpha1.RouteTableRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this route table rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.RouteTableSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeRouteTable
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeRouteTable, "routeTableID", rule.RouteTableID, "subnetID", rule.SubnetID)
+	l.V(1).Info("Validating route table")
+	ev := &evidence{}
+	if err := s.validateRouteTable(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate route table", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.subnetAPI, s.routeTableAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.RouteTableFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateRouteTable appends a failure for each required route that the route table doesn't have
+// or that has a different next hop, and for a forbidden default route. Subnets and route tables
+// that don't exist, and subnets without a route table, are failures, not errors.
+func (s *RouteTableRuleService) validateRouteTable(rule v1alpha1.RouteTableRule, failures *[]string, ev *evidence) error {
+	var rerr *azcore.ResponseError
+	routeTableID := rule.RouteTableID
+	if rule.SubnetID != "" {
+		subnetID := string(rule.SubnetID)
+		subnet, err := s.subnetAPI.GetSubnet(subnetID)
+		if err != nil {
+			if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+				*failures = append(*failures, fmt.Sprintf(messages.SubnetNotFoundFailure, subnetID))
+				return nil
+			}
+			return fmt.Errorf("failed to get subnet: %w", azure_errors.AsAugmented(err))
+		}
+		if subnet.Properties == nil || subnet.Properties.RouteTable == nil || subnet.Properties.RouteTable.ID == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.RouteTableMissingFailure, subnetID))
+			return nil
+		}
+		routeTableID = *subnet.Properties.RouteTable.ID
+		ev.add("Subnet %s is associated with route table %s.", subnetID, routeTableID)
+	}
+
+	table, err := s.routeTableAPI.GetRouteTable(routeTableID)
+	if err != nil {
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.RouteTableNotFoundFailure, routeTableID))
+			return nil
+		}
+		return fmt.Errorf("failed to get route table: %w", azure_errors.AsAugmented(err))
+	}
+	var routes []*armnetwork.Route
+	if table.Properties != nil {
+		for _, r := range table.Properties.Routes {
+			if r != nil && r.Properties != nil && r.Properties.AddressPrefix != nil {
+				routes = append(routes, r)
+			}
+		}
+	}
+	ev.add("Route table %s has %d route(s).", routeTableID, len(routes))
+
+	required := map[string]bool{}
+	for _, want := range rule.Routes {
+		prefix := normalizeAddressPrefix(want.AddressPrefix)
+		required[prefix] = true
+		route := findRoute(routes, prefix)
+		if route == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.RouteMissingFailure, routeTableID, want.AddressPrefix))
+			continue
+		}
+		gotType, gotIP := routeNextHop(route)
+		// The IP address of a route's next hop only matters if the rule requires one.
+		if !strings.EqualFold(gotType, want.NextHopType) || (want.NextHopIPAddress != "" && gotIP != want.NextHopIPAddress) {
+			*failures = append(*failures, fmt.Sprintf(messages.RouteNextHopFailure,
+				strPtrValue(route.Name), want.AddressPrefix, routeTableID, nextHop(want.NextHopType, want.NextHopIPAddress), nextHop(gotType, gotIP)))
+		}
+	}
+
+	if rule.ForbidDefaultRoute && !required[defaultRoutePrefix] {
+		if route := findRoute(routes, defaultRoutePrefix); route != nil {
+			*failures = append(*failures, fmt.Sprintf(messages.DefaultRouteFailure, routeTableID, strPtrValue(route.Name), *route.Properties.AddressPrefix, nextHop(routeNextHop(route))))
+		}
+	}
+
+	return nil
+}
+
+// findRoute returns the route for a normalized address prefix. Azure doesn't allow a route table
+// to have several routes for the same prefix.
+func findRoute(routes []*armnetwork.Route, prefix string) *armnetwork.Route {
+	for _, r := range routes {
+		if normalizeAddressPrefix(*r.Properties.AddressPrefix) == prefix {
+			return r
+		}
+	}
+	return nil
+}
+
+// normalizeAddressPrefix returns a CIDR in canonical form, with the host bits cleared, so that
+// prefixes that denote the same network are equal. Anything else (e.g. a service tag) is compared
+// case-insensitively.
+func normalizeAddressPrefix(prefix string) string {
+	p, err := netip.ParsePrefix(strings.TrimSpace(prefix))
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(prefix))
+	}
+	return p.Masked().String()
+}
+
+// nextHop describes a next hop in failures, e.g. "VirtualAppliance 10.0.0.4" or "Internet".
+func nextHop(hopType, ipAddress string) string {
+	if ipAddress == "" {
+		return hopType
+	}
+	return hopType + " " + ipAddress
+}
+
+// routeNextHop returns the type and IP address of a route's next hop. The IP address is empty for
+// next hop types other than VirtualAppliance.
+func routeNextHop(route *armnetwork.Route) (hopType, ipAddress string) {
+	hopType = notSet
+	if route.Properties.NextHopType != nil {
+		hopType = string(*route.Properties.NextHopType)
+	}
+	if route.Properties.NextHopIPAddress != nil {
+		ipAddress = *route.Properties.NextHopIPAddress
+	}
+	return hopType, ipAddress
+}
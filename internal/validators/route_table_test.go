@@ -0,0 +1,219 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const testRouteTableID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/routeTables/nodes-rt"
+
+// routeTableAPIMock is a fake ARM with the route table testRouteTableID, whose routes are data.
+// Getting any other route table fails with a 404, unless err is set.
+type routeTableAPIMock struct {
+	data []*armnetwork.Route
+	err  error
+}
+
+func (m routeTableAPIMock) GetRouteTable(routeTableID string) (*armnetwork.RouteTable, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if routeTableID != testRouteTableID {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return &armnetwork.RouteTable{
+		ID:         util.Ptr(testRouteTableID),
+		Properties: &armnetwork.RouteTablePropertiesFormat{Routes: m.data},
+	}, nil
+}
+
+func newRoute(name, addressPrefix string, hopType armnetwork.RouteNextHopType, ipAddress string) *armnetwork.Route {
+	route := &armnetwork.Route{
+		Name: util.Ptr(name),
+		Properties: &armnetwork.RoutePropertiesFormat{
+			AddressPrefix: util.Ptr(addressPrefix),
+			NextHopType:   util.Ptr(hopType),
+		},
+	}
+	if ipAddress != "" {
+		route.Properties.NextHopIPAddress = util.Ptr(ipAddress)
+	}
+	return route
+}
+
+func TestRouteTableRuleService_ReconcileRouteTableRule(t *testing.T) {
+	firewallRoute := newRoute("to-firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.4")
+	onPremRoute := newRoute("to-onprem", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, "")
+	subnetWithTable := &armnetwork.Subnet{Properties: &armnetwork.SubnetPropertiesFormat{
+		RouteTable: &armnetwork.RouteTable{ID: util.Ptr(testRouteTableID)},
+	}}
+
+	tests := []struct {
+		name         string
+		routes       []*armnetwork.Route
+		subnets      map[string]*armnetwork.Subnet
+		rule         v1alpha1.RouteTableRule
+		wantFailures []string
+	}{
+		{
+			name:   "Passes when the route table has all required routes.",
+			routes: []*armnetwork.Route{firewallRoute, onPremRoute},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID: testRouteTableID,
+				Routes: []v1alpha1.RequiredRoute{
+					{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance", NextHopIPAddress: "10.0.0.4"},
+					{AddressPrefix: "192.168.0.0/16", NextHopType: "VirtualNetworkGateway"},
+				},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Matches address prefixes that denote the same network.",
+			routes: []*armnetwork.Route{newRoute("to-spoke", "10.1.0.0/16", armnetwork.RouteNextHopTypeVnetLocal, "")},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID: testRouteTableID,
+				Routes:       []v1alpha1.RequiredRoute{{AddressPrefix: "10.1.2.3/16", NextHopType: "vnetlocal"}},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Matches service tags case-insensitively.",
+			routes: []*armnetwork.Route{newRoute("to-azure", "AzureCloud", armnetwork.RouteNextHopTypeInternet, "")},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID: testRouteTableID,
+				Routes:       []v1alpha1.RequiredRoute{{AddressPrefix: "azurecloud", NextHopType: "Internet"}},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails when a required route is missing.",
+			routes: []*armnetwork.Route{onPremRoute},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID: testRouteTableID,
+				Routes:       []v1alpha1.RequiredRoute{{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"}},
+			},
+			wantFailures: []string{"Route table " + testRouteTableID + " has no route for 0.0.0.0/0."},
+		},
+		{
+			name:   "Fails when a route has a different next hop type.",
+			routes: []*armnetwork.Route{newRoute("to-internet", "0.0.0.0/0", armnetwork.RouteNextHopTypeInternet, "")},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID: testRouteTableID,
+				Routes:       []v1alpha1.RequiredRoute{{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"}},
+			},
+			wantFailures: []string{"Expected route to-internet for 0.0.0.0/0 in route table " + testRouteTableID + " to have next hop VirtualAppliance, but it has next hop Internet."},
+		},
+		{
+			name:   "Fails when a route has a different next hop IP address, only if one is required.",
+			routes: []*armnetwork.Route{firewallRoute, newRoute("to-nva", "10.2.0.0/16", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.5")},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID: testRouteTableID,
+				Routes: []v1alpha1.RequiredRoute{
+					{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance", NextHopIPAddress: "10.0.0.5"},
+					{AddressPrefix: "10.2.0.0/16", NextHopType: "VirtualAppliance"},
+				},
+			},
+			wantFailures: []string{"Expected route to-firewall for 0.0.0.0/0 in route table " + testRouteTableID + " to have next hop VirtualAppliance 10.0.0.5, but it has next hop VirtualAppliance 10.0.0.4."},
+		},
+		{
+			name:   "Fails for a default route when default routes are forbidden.",
+			routes: []*armnetwork.Route{onPremRoute, firewallRoute},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID:       testRouteTableID,
+				Routes:             []v1alpha1.RequiredRoute{{AddressPrefix: "192.168.0.0/16", NextHopType: "VirtualNetworkGateway"}},
+				ForbidDefaultRoute: true,
+			},
+			wantFailures: []string{"Route table " + testRouteTableID + " has a default route to-firewall for 0.0.0.0/0 with next hop VirtualAppliance 10.0.0.4, but default routes are forbidden."},
+		},
+		{
+			name:   "Allows a required default route when default routes are forbidden.",
+			routes: []*armnetwork.Route{firewallRoute},
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID:       testRouteTableID,
+				Routes:             []v1alpha1.RequiredRoute{{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"}},
+				ForbidDefaultRoute: true,
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:    "Discovers the route table from the subnet it's associated with.",
+			routes:  []*armnetwork.Route{firewallRoute},
+			subnets: map[string]*armnetwork.Subnet{testSubnet1ID: subnetWithTable},
+			rule: v1alpha1.RouteTableRule{
+				SubnetID: testSubnet1ID,
+				Routes:   []v1alpha1.RequiredRoute{{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"}},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:    "Fails when the subnet has no route table.",
+			subnets: map[string]*armnetwork.Subnet{testSubnet1ID: newSubnet("")},
+			rule: v1alpha1.RouteTableRule{
+				SubnetID: testSubnet1ID,
+				Routes:   []v1alpha1.RequiredRoute{{AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"}},
+			},
+			wantFailures: []string{"Subnet " + testSubnet1ID + " has no route table."},
+		},
+		{
+			name: "Fails when the subnet doesn't exist.",
+			rule: v1alpha1.RouteTableRule{
+				SubnetID:           testSubnet1ID,
+				ForbidDefaultRoute: true,
+			},
+			wantFailures: []string{"Subnet " + testSubnet1ID + " not found."},
+		},
+		{
+			name: "Fails when the route table doesn't exist.",
+			rule: v1alpha1.RouteTableRule{
+				RouteTableID:       testRouteTableID + "-deleted",
+				ForbidDefaultRoute: true,
+			},
+			wantFailures: []string{"Route table " + testRouteTableID + "-deleted not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRouteTableRuleService(logr.Discard(), networkAPIMock{subnets: tt.subnets}, routeTableAPIMock{data: tt.routes})
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileRouteTableRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestRouteTableRuleService_ReconcileRouteTableRule_Error(t *testing.T) {
+	api := routeTableAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewRouteTableRuleService(logr.Discard(), networkAPIMock{}, api)
+
+	result, err := svc.ReconcileRouteTableRule(v1alpha1.RouteTableRule{Name: "rule-1", RouteTableID: testRouteTableID, ForbidDefaultRoute: true})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
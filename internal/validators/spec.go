@@ -0,0 +1,136 @@
+package validators
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// SpecProblem is a problem with the spec of a rule that keeps the rule from being evaluated, e.g.
+// a scope that isn't an ARM resource ID. The webhook rejects specs with such problems, but rules
+// from rulesFrom ConfigMaps, templates from ConfigMaps, and AzureValidators created while the
+// webhook wasn't serving aren't checked by it.
+type SpecProblem struct {
+	// RuleName is the name of the rule whose spec has the problem.
+	RuleName string
+	// Err is the problem, under the path of the rule's field that has it.
+	Err *field.Error
+}
+
+// RBACRuleSpecErrors returns the problems with an RBAC rule's spec, under path. Only problems that
+// keep the rule from being evaluated are checked for, so principal IDs needn't be UUIDs, as the
+// webhook requires.
+func RBACRuleSpecErrors(rule v1alpha1.RBACRule, rulePath *field.Path) field.ErrorList {
+	errs := rule.PrincipalErrors(rulePath)
+	for i, set := range rule.Permissions {
+		setPath := rulePath.Child("permissionSets").Index(i)
+		if set.Scope == "" && len(set.Scopes) == 0 && len(set.ScopePatterns) == 0 && set.ManagementGroupID == "" {
+			errs = append(errs, field.Required(setPath.Child("scope"), "at least one of scope, scopes, scopePatterns, and managementGroupId must be provided"))
+		}
+		if set.Scope != "" {
+			errs = appendScopeError(errs, setPath.Child("scope"), set.Scope)
+		}
+		for j, scope := range set.Scopes {
+			errs = appendScopeError(errs, setPath.Child("scopes").Index(j), scope)
+		}
+		for j, pattern := range set.ScopePatterns {
+			if err := checkScopePattern(pattern); err != nil {
+				errs = append(errs, field.Invalid(setPath.Child("scopePatterns").Index(j), pattern, err.Error()))
+			}
+		}
+		if strings.Contains(set.ManagementGroupID, "/") {
+			errs = append(errs, field.Invalid(setPath.Child("managementGroupId"), set.ManagementGroupID, "must be the ID of a management group, not its resource ID"))
+		}
+		if _, err := regexp.Compile(set.DescriptionPattern); err != nil {
+			errs = append(errs, field.Invalid(setPath.Child("descriptionPattern"), set.DescriptionPattern, err.Error()))
+		}
+	}
+	errs = append(errs, rule.RawFilterErrors(rulePath)...)
+	return appendFailureMessageTemplateError(errs, rulePath, rule.FailureMessageTemplate)
+}
+
+// TemplatePermissionRuleSpecErrors returns the problems with a template permission rule's spec,
+// under path. The rule's template must already have been resolved into rule.Template.
+func TemplatePermissionRuleSpecErrors(rule v1alpha1.TemplatePermissionRule, rulePath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if rule.PrincipalID == "" {
+		errs = append(errs, field.Required(rulePath.Child("principalId"), "the principal must be set"))
+	}
+	errs = appendScopeError(errs, rulePath.Child("scope"), rule.Scope)
+	if _, _, err := templateActions(rule, &[]string{}); err != nil {
+		// Templates are too long to be repeated in the problem.
+		errs = append(errs, &field.Error{Type: field.ErrorTypeInvalid, Field: rulePath.Child("template").String(), BadValue: field.OmitValueType{}, Detail: fmt.Sprintf("not a valid ARM template: %v", err)})
+	}
+	return appendFailureMessageTemplateError(errs, rulePath, rule.FailureMessageTemplate)
+}
+
+func appendScopeError(errs field.ErrorList, scopePath *field.Path, scope string) field.ErrorList {
+	if _, err := arm.ParseResourceID(scope); err != nil {
+		errs = append(errs, field.Invalid(scopePath, scope, "must be an ARM resource ID, e.g. /subscriptions/{id}/resourceGroups/{rg}"))
+	}
+	return errs
+}
+
+func appendFailureMessageTemplateError(errs field.ErrorList, rulePath *field.Path, text string) field.ErrorList {
+	if _, err := messages.NewTemplates(text); err != nil {
+		errs = append(errs, field.Invalid(rulePath.Child("failureMessageTemplate"), text, err.Error()))
+	}
+	return errs
+}
+
+// checkScopePattern checks that a scope pattern is in the form
+// /subscriptions/<id>/resourceGroups/<glob>, with a valid glob.
+func checkScopePattern(pattern string) error {
+	segments := strings.Split(pattern, "/")
+	if len(segments) != 5 || segments[0] != "" || !strings.EqualFold(segments[1], "subscriptions") || !strings.EqualFold(segments[3], "resourceGroups") {
+		return fmt.Errorf("scope pattern %s isn't in the form /subscriptions/<id>/resourceGroups/<glob>", pattern)
+	}
+	if _, err := path.Match(strings.ToLower(segments[4]), ""); err != nil {
+		return fmt.Errorf("scope pattern %s has an invalid glob: %w", pattern, err)
+	}
+	return nil
+}
+
+// setSpecInvalid fails the condition of a rule whose spec has problems, instead of evaluating the
+// rule. The problems themselves are reported by the spec condition, which SpecResult builds.
+func setSpecInvalid(state *vapi.ValidationState, condition *vapi.ValidationCondition, errs field.ErrorList) {
+	*state = vapi.ValidationFailed
+	condition.Message = messages.RuleSpecInvalid
+	condition.Status = corev1.ConditionFalse
+	condition.Failures = append(condition.Failures, fmt.Sprintf(messages.RuleSpecFailure, len(errs), constants.SpecRuleName))
+}
+
+// SpecResult builds the spec condition, which lists the problems with the specs of all of an
+// AzureValidator's rules, in the order they were found, along with the rules that have them. The
+// rules themselves fail without being evaluated.
+func SpecResult(problems []SpecProblem) *vapitypes.ValidationRuleResult {
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.SpecSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, constants.SpecRuleName)
+	latestCondition.ValidationType = constants.ValidationTypeSpec
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	for _, p := range problems {
+		latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf(messages.SpecProblemFailure, p.RuleName, p.Err.Error()))
+	}
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.SpecFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+	return validationResult
+}
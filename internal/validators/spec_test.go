@@ -0,0 +1,176 @@
+package validators
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+func errorStrings(errs field.ErrorList) []string {
+	strs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		strs = append(strs, err.Error())
+	}
+	return strs
+}
+
+func TestRBACRuleSpecErrors(t *testing.T) {
+	const scope = "/subscriptions/00000000-0000-0000-0000-000000000000"
+	tests := []struct {
+		name     string
+		rule     v1alpha1.RBACRule
+		wantErrs []string
+	}{
+		{
+			name: "Rules that can be evaluated have no problems, even if their principal IDs aren't UUIDs.",
+			rule: v1alpha1.RBACRule{
+				PrincipalID: "p_id",
+				Permissions: []v1alpha1.PermissionSet{{Scope: scope, ScopePatterns: []string{scope + "/resourceGroups/app-*"}, Actions: []v1alpha1.ActionStr{"a"}}},
+			},
+			wantErrs: []string{},
+		},
+		{
+			name: "Rules without principals or scopes have problems.",
+			rule: v1alpha1.RBACRule{
+				Permissions: []v1alpha1.PermissionSet{{Actions: []v1alpha1.ActionStr{"a"}}},
+			},
+			wantErrs: []string{
+				"spec.rbacRules[0].principalId: Required value: principalId, principalIds, or principalClientId must be set",
+				"spec.rbacRules[0].permissionSets[0].scope: Required value: at least one of scope, scopes, scopePatterns, and managementGroupId must be provided",
+			},
+		},
+		{
+			name: "Scopes that aren't ARM resource IDs and malformed scope patterns are problems.",
+			rule: v1alpha1.RBACRule{
+				PrincipalID: "p_id",
+				Permissions: []v1alpha1.PermissionSet{{
+					Scope:         "subscriptions/s",
+					Scopes:        []string{scope, "/subscription/s"},
+					ScopePatterns: []string{"/subscriptions/s*", scope + "/resourceGroups/app-["},
+					Actions:       []v1alpha1.ActionStr{"a"},
+				}},
+			},
+			wantErrs: []string{
+				`spec.rbacRules[0].permissionSets[0].scope: Invalid value: "subscriptions/s": must be an ARM resource ID, e.g. /subscriptions/{id}/resourceGroups/{rg}`,
+				`spec.rbacRules[0].permissionSets[0].scopes[1]: Invalid value: "/subscription/s": must be an ARM resource ID, e.g. /subscriptions/{id}/resourceGroups/{rg}`,
+				`spec.rbacRules[0].permissionSets[0].scopePatterns[0]: Invalid value: "/subscriptions/s*": scope pattern /subscriptions/s* isn't in the form /subscriptions/<id>/resourceGroups/<glob>`,
+				`spec.rbacRules[0].permissionSets[0].scopePatterns[1]: Invalid value: "` + scope + `/resourceGroups/app-[": scope pattern ` + scope + `/resourceGroups/app-[ has an invalid glob: syntax error in pattern`,
+			},
+		},
+		{
+			name: "Principal client IDs alongside principal IDs are problems.",
+			rule: v1alpha1.RBACRule{
+				PrincipalID:       "p_id",
+				PrincipalClientID: "c_id",
+				Permissions:       []v1alpha1.PermissionSet{{Scope: scope, Actions: []v1alpha1.ActionStr{"a"}}},
+			},
+			wantErrs: []string{
+				"spec.rbacRules[0].principalClientId: Forbidden: principalClientId can't be combined with principalId or principalIds",
+			},
+		},
+		{
+			name: "Raw filters that aren't URL-encoded or are combined with expandPrincipalGroups are problems.",
+			rule: v1alpha1.RBACRule{
+				PrincipalID:           "p_id",
+				Permissions:           []v1alpha1.PermissionSet{{Scope: scope, Actions: []v1alpha1.ActionStr{"a"}}},
+				RawFilter:             "atScope() and principalId eq 'p_id'",
+				ExpandPrincipalGroups: true,
+			},
+			wantErrs: []string{
+				`spec.rbacRules[0].rawFilter: Invalid value: "atScope() and principalId eq 'p_id'": must be URL-encoded, without whitespace, & or #`,
+				"spec.rbacRules[0].rawFilter: Forbidden: rawFilter can't be combined with expandPrincipalGroups",
+			},
+		},
+		{
+			name: "Description patterns that aren't regular expressions are problems.",
+			rule: v1alpha1.RBACRule{
+				PrincipalID: "p_id",
+				Permissions: []v1alpha1.PermissionSet{{Scope: scope, Actions: []v1alpha1.ActionStr{"a"}, DescriptionPattern: "owner=("}},
+			},
+			wantErrs: []string{
+				"spec.rbacRules[0].permissionSets[0].descriptionPattern: Invalid value: \"owner=(\": error parsing regexp: missing closing ): `owner=(`",
+			},
+		},
+		{
+			name: "Invalid failure message templates are problems.",
+			rule: v1alpha1.RBACRule{
+				PrincipalID:            "p_id",
+				Permissions:            []v1alpha1.PermissionSet{{Scope: scope, Actions: []v1alpha1.ActionStr{"a"}}},
+				FailureMessageTemplate: "{{.Action",
+			},
+			wantErrs: []string{
+				`spec.rbacRules[0].failureMessageTemplate: Invalid value: "{{.Action": template: failureMessageTemplate:1: unclosed action`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := RBACRuleSpecErrors(tt.rule, field.NewPath("spec", "rbacRules").Index(0))
+			if got := errorStrings(errs); !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("got problems %q, want %q", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestTemplatePermissionRuleSpecErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     v1alpha1.TemplatePermissionRule
+		wantErrs []string
+	}{
+		{
+			name:     "Rules that can be evaluated have no problems.",
+			rule:     v1alpha1.TemplatePermissionRule{PrincipalID: "p_id", Scope: "/subscriptions/s/resourceGroups/rg", Template: testTemplate},
+			wantErrs: []string{},
+		},
+		{
+			name: "Rules without a principal, whose scope isn't an ARM resource ID, or whose template isn't valid JSON have problems.",
+			rule: v1alpha1.TemplatePermissionRule{Scope: "rg", Template: `{"resources": [`},
+			wantErrs: []string{
+				"spec.templatePermissionRules[0].principalId: Required value: the principal must be set",
+				`spec.templatePermissionRules[0].scope: Invalid value: "rg": must be an ARM resource ID, e.g. /subscriptions/{id}/resourceGroups/{rg}`,
+				"spec.templatePermissionRules[0].template: Invalid value: not a valid ARM template: unexpected end of JSON input",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := TemplatePermissionRuleSpecErrors(tt.rule, field.NewPath("spec", "templatePermissionRules").Index(0))
+			if got := errorStrings(errs); !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("got problems %q, want %q", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestSpecResult(t *testing.T) {
+	result := SpecResult(nil)
+	if *result.State != vapi.ValidationSucceeded || result.Condition.Message != messages.SpecSucceeded || len(result.Condition.Failures) != 0 {
+		t.Errorf("got state %s, message %q, and failures %v without problems, want success", *result.State, result.Condition.Message, result.Condition.Failures)
+	}
+	if result.Condition.ValidationType != constants.ValidationTypeSpec {
+		t.Errorf("got validation type %s, want %s", result.Condition.ValidationType, constants.ValidationTypeSpec)
+	}
+
+	result = SpecResult([]SpecProblem{
+		{RuleName: "rule-2", Err: field.Required(field.NewPath("spec", "rbacRules").Index(1).Child("principalId"), "must be set")},
+		{RuleName: "rule-1", Err: field.Invalid(field.NewPath("spec", "templatePermissionRules").Index(0).Child("scope"), "rg", "must be an ARM resource ID")},
+	})
+	wantFailures := []string{
+		"Rule rule-2: spec.rbacRules[1].principalId: Required value: must be set",
+		`Rule rule-1: spec.templatePermissionRules[0].scope: Invalid value: "rg": must be an ARM resource ID`,
+	}
+	if *result.State != vapi.ValidationFailed || result.Condition.Message != messages.SpecFailed {
+		t.Errorf("got state %s and message %q with problems, want failure", *result.State, result.Condition.Message)
+	}
+	if !reflect.DeepEqual(result.Condition.Failures, wantFailures) {
+		t.Errorf("got failures %q, want %q", result.Condition.Failures, wantFailures)
+	}
+}
@@ -0,0 +1,159 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// sqlServerAPI contains methods that allow getting an Azure SQL logical server, and its Azure AD
+// administrator, by the server's resource ID.
+type sqlServerAPI interface {
+	GetServer(serverID string) (*armsql.Server, error)
+	GetAzureADAdministrator(serverID string) (*armsql.ServerAzureADAdministrator, error)
+}
+
+type SQLServerRuleService struct {
+	log logr.Logger
+	api sqlServerAPI
+}
+
+func NewSQLServerRuleService(log logr.Logger, api sqlServerAPI) *SQLServerRuleService {
+	return &SQLServerRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileSQLServerRule reconciles a SQL server rule from a validation config.
+func (s *SQLServerRuleService) ReconcileSQLServerRule(rule v1alpha1.SQLServerRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this SQL server rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.SQLServerSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeSQLServer
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeSQLServer, "serverID", rule.ServerID)
+	l.V(1).Info("Validating SQL server")
+	ev := &evidence{}
+	if err := s.validateServer(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate SQL server", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.SQLServerFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateServer appends a failure for each of the rule's settings that the server doesn't have,
+// with the server's actual value. A server that doesn't exist is a single failure, not an error.
+func (s *SQLServerRuleService) validateServer(rule v1alpha1.SQLServerRule, failures *[]string, ev *evidence) error {
+	server, err := s.api.GetServer(rule.ServerID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.SQLServerNotFoundFailure, rule.ServerID))
+			return nil
+		}
+		return fmt.Errorf("failed to get SQL server: %w", azure_errors.AsAugmented(err))
+	}
+	props := server.Properties
+	if props == nil {
+		props = &armsql.ServerProperties{}
+	}
+
+	if rule.RequireAzureADOnlyAuthentication || rule.AzureADAdministratorID != "" {
+		if err := s.validateAdministrator(rule, failures, ev); err != nil {
+			return err
+		}
+	}
+
+	publicAccess := notSet
+	if props.PublicNetworkAccess != nil {
+		publicAccess = string(*props.PublicNetworkAccess)
+	}
+	if rule.PublicNetworkAccess != "" && !strings.EqualFold(publicAccess, rule.PublicNetworkAccess) {
+		*failures = append(*failures, fmt.Sprintf(messages.SQLServerPublicNetworkAccessFailure, rule.ServerID, publicAccess, rule.PublicNetworkAccess))
+	}
+
+	tlsVersion := strPtrValue(props.MinimalTLSVersion)
+	if rule.MinimalTLSVersion != "" && !tlsVersionAtLeast(tlsVersion, rule.MinimalTLSVersion) {
+		*failures = append(*failures, fmt.Sprintf(messages.SQLServerTLSVersionFailure, rule.ServerID, tlsVersion, rule.MinimalTLSVersion))
+	}
+
+	ev.add("SQL server %s has public network access %s and minimal TLS version %s.", rule.ServerID, publicAccess, tlsVersion)
+	return nil
+}
+
+// validateAdministrator appends a failure if the server's Azure AD administrator isn't the rule's,
+// and if the rule requires Azure AD-only authentication and the server doesn't have it. A server
+// without an Azure AD administrator can't have Azure AD-only authentication.
+func (s *SQLServerRuleService) validateAdministrator(rule v1alpha1.SQLServerRule, failures *[]string, ev *evidence) error {
+	admin, err := s.api.GetAzureADAdministrator(rule.ServerID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("failed to get Azure AD administrator: %w", azure_errors.AsAugmented(err))
+		}
+		admin = &armsql.ServerAzureADAdministrator{}
+	}
+	props := admin.Properties
+	if props == nil {
+		if rule.AzureADAdministratorID != "" {
+			*failures = append(*failures, fmt.Sprintf(messages.SQLServerNoAdministratorFailure, rule.ServerID, rule.AzureADAdministratorID))
+		}
+		if rule.RequireAzureADOnlyAuthentication {
+			*failures = append(*failures, fmt.Sprintf(messages.SQLServerNoAdministratorAuthFailure, rule.ServerID))
+		}
+		ev.add("SQL server %s has no Azure AD administrator.", rule.ServerID)
+		return nil
+	}
+
+	sid := strPtrValue(props.Sid)
+	if rule.AzureADAdministratorID != "" && !strings.EqualFold(sid, rule.AzureADAdministratorID) {
+		*failures = append(*failures, fmt.Sprintf(messages.SQLServerAdministratorFailure, rule.ServerID, strPtrValue(props.Login), sid, rule.AzureADAdministratorID))
+	}
+	adOnly := props.AzureADOnlyAuthentication != nil && *props.AzureADOnlyAuthentication
+	if rule.RequireAzureADOnlyAuthentication && !adOnly {
+		*failures = append(*failures, fmt.Sprintf(messages.SQLServerADOnlyAuthFailure, rule.ServerID))
+	}
+	ev.add("SQL server %s has Azure AD administrator %s (%s). Azure AD-only authentication enabled: %t.", rule.ServerID, strPtrValue(props.Login), sid, adOnly)
+	return nil
+}
+
+// tlsVersionAtLeast returns whether a TLS version (e.g. 1.2) is at least min. Versions that aren't
+// numbers, such as None, are lower than all others.
+func tlsVersionAtLeast(version, min string) bool {
+	v, err := strconv.ParseFloat(version, 64)
+	if err != nil {
+		return false
+	}
+	m, err := strconv.ParseFloat(min, 64)
+	return err == nil && v >= m
+}
@@ -0,0 +1,214 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testSQLServerID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Sql/servers/server-1"
+	testSQLAdminID  = "11111111-1111-1111-1111-111111111111"
+)
+
+// sqlServerAPIMock is a fake ARM with the SQL servers and Azure AD administrators in its maps,
+// keyed by server resource ID. Getting any other server or administrator fails with a 404, unless
+// err is set.
+type sqlServerAPIMock struct {
+	servers map[string]*armsql.Server
+	admins  map[string]*armsql.ServerAzureADAdministrator
+	err     error
+}
+
+func (m sqlServerAPIMock) GetServer(serverID string) (*armsql.Server, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.servers, serverID)
+}
+
+func (m sqlServerAPIMock) GetAzureADAdministrator(serverID string) (*armsql.ServerAzureADAdministrator, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.admins, serverID)
+}
+
+// newSQLServer returns a SQL server with public network access disabled and minimal TLS version
+// 1.2.
+func newSQLServer() *armsql.Server {
+	return &armsql.Server{
+		Properties: &armsql.ServerProperties{
+			PublicNetworkAccess: util.Ptr(armsql.ServerNetworkAccessFlagDisabled),
+			MinimalTLSVersion:   util.Ptr("1.2"),
+		},
+	}
+}
+
+// newSQLAdmin returns an Azure AD administrator with an object ID, and Azure AD-only
+// authentication enabled or not.
+func newSQLAdmin(sid string, adOnly bool) *armsql.ServerAzureADAdministrator {
+	return &armsql.ServerAzureADAdministrator{
+		Properties: &armsql.AdministratorProperties{
+			AdministratorType:         util.Ptr(armsql.AdministratorTypeActiveDirectory),
+			Login:                     util.Ptr("sql-admins"),
+			Sid:                       util.Ptr(sid),
+			AzureADOnlyAuthentication: util.Ptr(adOnly),
+		},
+	}
+}
+
+func TestSQLServerRuleService_ReconcileSQLServerRule(t *testing.T) {
+	allChecks := v1alpha1.SQLServerRule{
+		RequireAzureADOnlyAuthentication: true,
+		AzureADAdministratorID:           testSQLAdminID,
+		PublicNetworkAccess:              "Disabled",
+		MinimalTLSVersion:                "1.2",
+	}
+	tests := []struct {
+		name         string
+		server       *armsql.Server
+		admin        *armsql.ServerAzureADAdministrator
+		rule         v1alpha1.SQLServerRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the server is configured as expected.",
+			server:       newSQLServer(),
+			admin:        newSQLAdmin(testSQLAdminID, true),
+			rule:         allChecks,
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when the server has a higher minimal TLS version, and the administrator's object ID is in another case.",
+			server: func() *armsql.Server {
+				s := newSQLServer()
+				s.Properties.MinimalTLSVersion = util.Ptr("1.3")
+				return s
+			}(),
+			admin:        newSQLAdmin("11111111-1111-1111-1111-11111111111A", false),
+			rule:         v1alpha1.SQLServerRule{AzureADAdministratorID: "11111111-1111-1111-1111-11111111111a", MinimalTLSVersion: "1.2"},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails when the server doesn't have Azure AD-only authentication enabled.",
+			server: newSQLServer(),
+			admin:  newSQLAdmin(testSQLAdminID, false),
+			rule:   v1alpha1.SQLServerRule{RequireAzureADOnlyAuthentication: true},
+			wantFailures: []string{
+				"SQL server " + testSQLServerID + " doesn't have Azure AD-only authentication enabled.",
+			},
+		},
+		{
+			name:   "Fails when the server has another Azure AD administrator.",
+			server: newSQLServer(),
+			admin:  newSQLAdmin("22222222-2222-2222-2222-222222222222", true),
+			rule:   v1alpha1.SQLServerRule{AzureADAdministratorID: testSQLAdminID},
+			wantFailures: []string{
+				"SQL server " + testSQLServerID + " has Azure AD administrator sql-admins (22222222-2222-2222-2222-222222222222), not " + testSQLAdminID + ".",
+			},
+		},
+		{
+			name:   "Fails when the server has no Azure AD administrator.",
+			server: newSQLServer(),
+			rule:   v1alpha1.SQLServerRule{RequireAzureADOnlyAuthentication: true, AzureADAdministratorID: testSQLAdminID},
+			wantFailures: []string{
+				"SQL server " + testSQLServerID + " has no Azure AD administrator, not " + testSQLAdminID + ".",
+				"SQL server " + testSQLServerID + " doesn't have Azure AD-only authentication enabled: it has no Azure AD administrator.",
+			},
+		},
+		{
+			name: "Fails when the server allows public network access.",
+			server: func() *armsql.Server {
+				s := newSQLServer()
+				s.Properties.PublicNetworkAccess = util.Ptr(armsql.ServerNetworkAccessFlagEnabled)
+				return s
+			}(),
+			rule: v1alpha1.SQLServerRule{PublicNetworkAccess: "Disabled"},
+			wantFailures: []string{
+				"SQL server " + testSQLServerID + " has public network access Enabled, not Disabled.",
+			},
+		},
+		{
+			name: "Fails when the server has a lower minimal TLS version.",
+			server: func() *armsql.Server {
+				s := newSQLServer()
+				s.Properties.MinimalTLSVersion = util.Ptr("1.0")
+				return s
+			}(),
+			rule: v1alpha1.SQLServerRule{MinimalTLSVersion: "1.2"},
+			wantFailures: []string{
+				"SQL server " + testSQLServerID + " has minimal TLS version 1.0, lower than 1.2.",
+			},
+		},
+		{
+			name:   "Fails on each assertion when none are met.",
+			server: &armsql.Server{Properties: &armsql.ServerProperties{PublicNetworkAccess: util.Ptr(armsql.ServerNetworkAccessFlagEnabled), MinimalTLSVersion: util.Ptr("None")}},
+			admin:  newSQLAdmin("22222222-2222-2222-2222-222222222222", false),
+			rule:   allChecks,
+			wantFailures: []string{
+				"SQL server " + testSQLServerID + " has Azure AD administrator sql-admins (22222222-2222-2222-2222-222222222222), not " + testSQLAdminID + ".",
+				"SQL server " + testSQLServerID + " doesn't have Azure AD-only authentication enabled.",
+				"SQL server " + testSQLServerID + " has public network access Enabled, not Disabled.",
+				"SQL server " + testSQLServerID + " has minimal TLS version None, lower than 1.2.",
+			},
+		},
+		{
+			name:         "Fails once when the server is missing.",
+			rule:         allChecks,
+			wantFailures: []string{"SQL server " + testSQLServerID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := sqlServerAPIMock{servers: map[string]*armsql.Server{}, admins: map[string]*armsql.ServerAzureADAdministrator{}}
+			if tt.server != nil {
+				api.servers[testSQLServerID] = tt.server
+			}
+			if tt.admin != nil {
+				api.admins[testSQLServerID] = tt.admin
+			}
+			svc := NewSQLServerRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.ServerID = testSQLServerID
+			result, err := svc.ReconcileSQLServerRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestSQLServerRuleService_ReconcileSQLServerRule_Error(t *testing.T) {
+	api := sqlServerAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewSQLServerRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileSQLServerRule(v1alpha1.SQLServerRule{Name: "rule-1", ServerID: testSQLServerID, PublicNetworkAccess: "Disabled"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,148 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// storageAccountAPI contains methods that allow getting storage accounts by their resource IDs.
+type storageAccountAPI interface {
+	GetStorageAccount(storageAccountID string) (*armstorage.Account, error)
+}
+
+type StorageNetworkRuleService struct {
+	log logr.Logger
+	api storageAccountAPI
+}
+
+func NewStorageNetworkRuleService(log logr.Logger, api storageAccountAPI) *StorageNetworkRuleService {
+	return &StorageNetworkRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileStorageNetworkRule reconciles a storage network rule from a validation config.
+func (s *StorageNetworkRuleService) ReconcileStorageNetworkRule(rule v1alpha1.StorageNetworkRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this storage network rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.StorageNetworkSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeStorageNetwork
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeStorageNetwork, "storageAccountID", rule.StorageAccountID)
+	l.V(1).Info("Validating storage account network rules")
+	ev := &evidence{}
+	if err := s.validateStorageNetwork(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate storage account network rules", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.StorageNetworkFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateStorageNetwork appends a failure if the storage account doesn't exist, if its firewall's
+// default action isn't Deny, and for each of the rule's subnets and IP ranges that the firewall
+// doesn't allow. If the account has public network access disabled, its firewall doesn't apply,
+// so subnets and IP ranges can't reach it at all, which is one failure.
+func (s *StorageNetworkRuleService) validateStorageNetwork(rule v1alpha1.StorageNetworkRule, failures *[]string, ev *evidence) error {
+	account, err := s.api.GetStorageAccount(rule.StorageAccountID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.StorageAccountNotFoundFailure, rule.StorageAccountID))
+			return nil
+		}
+		return fmt.Errorf("failed to get storage account: %w", azure_errors.AsAugmented(err))
+	}
+	props := account.Properties
+	if props == nil {
+		props = &armstorage.AccountProperties{}
+	}
+	acls := props.NetworkRuleSet
+	if acls == nil {
+		acls = &armstorage.NetworkRuleSet{}
+	}
+
+	// Accounts without network rules allow access from all networks.
+	defaultAction := string(armstorage.DefaultActionAllow)
+	if acls.DefaultAction != nil {
+		defaultAction = string(*acls.DefaultAction)
+	}
+	ev.add("Storage account %s has default network action %s, %d virtual network rule(s), and %d IP rule(s).", rule.StorageAccountID, defaultAction, len(acls.VirtualNetworkRules), len(acls.IPRules))
+	if !strings.EqualFold(defaultAction, string(armstorage.DefaultActionDeny)) {
+		*failures = append(*failures, fmt.Sprintf(messages.StorageDefaultActionFailure, rule.StorageAccountID, defaultAction))
+	}
+
+	if props.PublicNetworkAccess != nil && *props.PublicNetworkAccess == armstorage.PublicNetworkAccessDisabled {
+		if len(rule.SubnetIDs) > 0 || len(rule.IPRanges) > 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.StoragePublicNetworkAccessFailure, rule.StorageAccountID))
+		}
+		return nil
+	}
+
+	for _, subnetID := range rule.SubnetIDs {
+		i := slices.IndexFunc(acls.VirtualNetworkRules, func(r *armstorage.VirtualNetworkRule) bool {
+			return r != nil && strings.EqualFold(strPtrValue(r.VirtualNetworkResourceID), subnetID)
+		})
+		if i < 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.StorageVirtualNetworkRuleMissingFailure, rule.StorageAccountID, subnetID))
+			continue
+		}
+		if state := acls.VirtualNetworkRules[i].State; state != nil && *state != armstorage.StateSucceeded {
+			*failures = append(*failures, fmt.Sprintf(messages.StorageVirtualNetworkRuleStateFailure, rule.StorageAccountID, subnetID, *state))
+		}
+	}
+
+	allowed := make([]string, 0, len(acls.IPRules))
+	for _, r := range acls.IPRules {
+		if r != nil && r.IPAddressOrRange != nil {
+			allowed = append(allowed, normalizeIPRange(*r.IPAddressOrRange))
+		}
+	}
+	for _, ipRange := range rule.IPRanges {
+		if !slices.Contains(allowed, normalizeIPRange(ipRange)) {
+			*failures = append(*failures, fmt.Sprintf(messages.StorageIPRuleMissingFailure, rule.StorageAccountID, ipRange))
+		}
+	}
+	return nil
+}
+
+// normalizeIPRange returns an IP address as a CIDR for it alone, and anything else as a normalized
+// address prefix, so that storage account IP rules, which may be either, compare equal if they
+// denote the same network.
+func normalizeIPRange(r string) string {
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r)); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()).String()
+	}
+	return normalizeAddressPrefix(r)
+}
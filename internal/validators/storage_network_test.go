@@ -0,0 +1,161 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testNodeSubnetID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/nodes"
+	testPodSubnetID  = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pods"
+)
+
+// storageAccountAPIMock is a fake ARM with the storage accounts in its map, keyed by resource ID.
+// Getting any other storage account fails with a 404, unless err is set.
+type storageAccountAPIMock struct {
+	accounts map[string]*armstorage.Account
+	err      error
+}
+
+func (m storageAccountAPIMock) GetStorageAccount(storageAccountID string) (*armstorage.Account, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.accounts, storageAccountID)
+}
+
+func newStorageAccount(acls *armstorage.NetworkRuleSet) map[string]*armstorage.Account {
+	return map[string]*armstorage.Account{
+		testBackupAccountID: {Properties: &armstorage.AccountProperties{NetworkRuleSet: acls}},
+	}
+}
+
+func TestStorageNetworkRuleService_ReconcileStorageNetworkRule(t *testing.T) {
+	acls := &armstorage.NetworkRuleSet{
+		DefaultAction: util.Ptr(armstorage.DefaultActionDeny),
+		VirtualNetworkRules: []*armstorage.VirtualNetworkRule{
+			// Azure returns resource IDs in whatever case they were created with.
+			{VirtualNetworkResourceID: util.Ptr("/subscriptions/00000000-0000-0000-0000-000000000000/resourcegroups/RG/providers/Microsoft.Network/virtualNetworks/vnet/subnets/nodes"), State: util.Ptr(armstorage.StateSucceeded)},
+			{VirtualNetworkResourceID: util.Ptr(testPodSubnetID), State: util.Ptr(armstorage.StateNetworkSourceDeleted)},
+		},
+		IPRules: []*armstorage.IPRule{
+			{IPAddressOrRange: util.Ptr("203.0.113.7")},
+			{IPAddressOrRange: util.Ptr("198.51.100.0/24")},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		api          storageAccountAPIMock
+		rule         v1alpha1.StorageNetworkRule
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the firewall denies by default and allows the subnets and IP ranges.",
+			api:  storageAccountAPIMock{accounts: newStorageAccount(acls)},
+			rule: v1alpha1.StorageNetworkRule{
+				SubnetIDs: []string{testNodeSubnetID},
+				IPRanges:  []string{"203.0.113.7/32", "198.51.100.0/24"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for each subnet and IP range that isn't allowed, and for rules that aren't in effect.",
+			api:  storageAccountAPIMock{accounts: newStorageAccount(acls)},
+			rule: v1alpha1.StorageNetworkRule{
+				SubnetIDs: []string{testNodeSubnetID, testPodSubnetID, testNodeSubnetID + "-2"},
+				IPRanges:  []string{"203.0.113.0/24", "198.51.100.9/24"},
+			},
+			wantFailures: []string{
+				"Virtual network rule of storage account " + testBackupAccountID + " for subnet " + testPodSubnetID + " is not in effect: its state is NetworkSourceDeleted.",
+				"Storage account " + testBackupAccountID + " has no virtual network rule for subnet " + testNodeSubnetID + "-2.",
+				"Storage account " + testBackupAccountID + " has no IP rule for 203.0.113.0/24.",
+			},
+		},
+		{
+			name: "Fails when the firewall allows access by default.",
+			api: storageAccountAPIMock{accounts: newStorageAccount(&armstorage.NetworkRuleSet{
+				DefaultAction: util.Ptr(armstorage.DefaultActionAllow),
+				IPRules:       acls.IPRules,
+			})},
+			rule: v1alpha1.StorageNetworkRule{IPRanges: []string{"203.0.113.7"}},
+			wantFailures: []string{
+				"Storage account " + testBackupAccountID + " allows network access by default: its default network action is Allow, not Deny.",
+			},
+		},
+		{
+			name: "Fails when the account has no network rules.",
+			api:  storageAccountAPIMock{accounts: newStorageAccount(nil)},
+			rule: v1alpha1.StorageNetworkRule{SubnetIDs: []string{testNodeSubnetID}},
+			wantFailures: []string{
+				"Storage account " + testBackupAccountID + " allows network access by default: its default network action is Allow, not Deny.",
+				"Storage account " + testBackupAccountID + " has no virtual network rule for subnet " + testNodeSubnetID + ".",
+			},
+		},
+		{
+			name: "Fails once for subnets when public network access is disabled.",
+			api: storageAccountAPIMock{accounts: map[string]*armstorage.Account{
+				testBackupAccountID: {Properties: &armstorage.AccountProperties{
+					NetworkRuleSet:      acls,
+					PublicNetworkAccess: util.Ptr(armstorage.PublicNetworkAccessDisabled),
+				}},
+			}},
+			rule: v1alpha1.StorageNetworkRule{SubnetIDs: []string{testNodeSubnetID, testPodSubnetID}},
+			wantFailures: []string{
+				"Storage account " + testBackupAccountID + " has public network access disabled, so its virtual network and IP rules don't apply, and it's only reachable via private endpoints.",
+			},
+		},
+		{
+			name:         "Fails when the storage account is missing.",
+			api:          storageAccountAPIMock{},
+			rule:         v1alpha1.StorageNetworkRule{SubnetIDs: []string{testNodeSubnetID}},
+			wantFailures: []string{"Storage account " + testBackupAccountID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewStorageNetworkRuleService(logr.Discard(), tt.api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.StorageAccountID = testBackupAccountID
+			result, err := svc.ReconcileStorageNetworkRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestStorageNetworkRuleService_ReconcileStorageNetworkRule_Error(t *testing.T) {
+	api := storageAccountAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewStorageNetworkRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileStorageNetworkRule(v1alpha1.StorageNetworkRule{Name: "rule-1", StorageAccountID: testBackupAccountID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,136 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+type SubnetDelegationRuleService struct {
+	log logr.Logger
+	api subnetAPI
+}
+
+func NewSubnetDelegationRuleService(log logr.Logger, api subnetAPI) *SubnetDelegationRuleService {
+	return &SubnetDelegationRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileSubnetDelegationRule reconciles a subnet delegation rule from a validation config.
+func (s *SubnetDelegationRuleService) ReconcileSubnetDelegationRule(rule v1alpha1.SubnetDelegationRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this subnet delegation rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.SubnetDelegationSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeSubnetDelegation
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeSubnetDelegation)
+	ev := &evidence{}
+	for _, subnetID := range rule.SubnetIDs {
+		sl := l.WithValues("subnetID", subnetID)
+		sl.V(1).Info("Validating subnet's delegations and network policies")
+		if err := s.validateSubnet(rule, string(subnetID), &latestCondition.Failures, ev); err != nil {
+			return validationResult, recordError(sl, "failed to validate subnet's delegations and network policies", err, &latestCondition)
+		}
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.SubnetDelegationFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateSubnet appends a failure for each of a subnet's delegations and service association
+// links that isn't for the rule's allowed service, and for each of its network policies that isn't
+// what the rule expects. Subnets that don't exist are failures, not errors.
+func (s *SubnetDelegationRuleService) validateSubnet(rule v1alpha1.SubnetDelegationRule, subnetID string, failures *[]string, ev *evidence) error {
+	subnet, err := s.api.GetSubnet(subnetID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.SubnetNotFoundFailure, subnetID))
+			return nil
+		}
+		return fmt.Errorf("failed to get subnet: %w", azure_errors.AsAugmented(err))
+	}
+	props := subnet.Properties
+	if props == nil {
+		props = &armnetwork.SubnetPropertiesFormat{}
+	}
+
+	delegations := make([]string, 0, len(props.Delegations))
+	for _, d := range props.Delegations {
+		if d == nil {
+			continue
+		}
+		service := notSet
+		if d.Properties != nil && d.Properties.ServiceName != nil {
+			service = *d.Properties.ServiceName
+		}
+		delegations = append(delegations, service)
+		if rule.AllowedDelegation == "" || !strings.EqualFold(service, rule.AllowedDelegation) {
+			*failures = append(*failures, fmt.Sprintf(messages.SubnetDelegationConflictFailure, subnetID, strPtrValue(d.Name), service))
+		}
+	}
+
+	links := make([]string, 0, len(props.ServiceAssociationLinks))
+	for _, link := range props.ServiceAssociationLinks {
+		if link == nil {
+			continue
+		}
+		linkedType := notSet
+		if link.Properties != nil && link.Properties.LinkedResourceType != nil {
+			linkedType = *link.Properties.LinkedResourceType
+		}
+		links = append(links, linkedType)
+		// Services link subnets delegated to them, so a link is only expected from the allowed one.
+		if rule.AllowedDelegation == "" || !strings.EqualFold(linkedType, rule.AllowedDelegation) {
+			*failures = append(*failures, fmt.Sprintf(messages.SubnetServiceLinkConflictFailure, subnetID, strPtrValue(link.Name), linkedType))
+		}
+	}
+
+	endpointPolicies := notSet
+	if props.PrivateEndpointNetworkPolicies != nil {
+		endpointPolicies = string(*props.PrivateEndpointNetworkPolicies)
+	}
+	linkServicePolicies := notSet
+	if props.PrivateLinkServiceNetworkPolicies != nil {
+		linkServicePolicies = string(*props.PrivateLinkServiceNetworkPolicies)
+	}
+	ev.add("Subnet %s is delegated to %s, has service association links from %s, and has private endpoint network policies %s and private link service network policies %s.",
+		subnetID, joinOrNone(delegations), joinOrNone(links), endpointPolicies, linkServicePolicies)
+
+	if rule.PrivateEndpointNetworkPolicies != "" && !strings.EqualFold(endpointPolicies, rule.PrivateEndpointNetworkPolicies) {
+		*failures = append(*failures, fmt.Sprintf(messages.SubnetPrivateEndpointPoliciesFailure, subnetID, endpointPolicies, rule.PrivateEndpointNetworkPolicies))
+	}
+	if rule.PrivateLinkServiceNetworkPolicies != "" && !strings.EqualFold(linkServicePolicies, rule.PrivateLinkServiceNetworkPolicies) {
+		*failures = append(*failures, fmt.Sprintf(messages.SubnetPrivateLinkServicePoliciesFailure, subnetID, linkServicePolicies, rule.PrivateLinkServiceNetworkPolicies))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package validators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// subnetPayload unmarshals a subnet as ARM returns it.
+func subnetPayload(t *testing.T, payload string) *armnetwork.Subnet {
+	t.Helper()
+	subnet := &armnetwork.Subnet{}
+	if err := json.Unmarshal([]byte(payload), subnet); err != nil {
+		t.Fatalf("failed to unmarshal subnet: %v", err)
+	}
+	return subnet
+}
+
+func TestSubnetDelegationRuleService_ReconcileSubnetDelegationRule(t *testing.T) {
+	api := networkAPIMock{subnets: map[string]*armnetwork.Subnet{
+		testSubnet1ID: subnetPayload(t, `{
+			"name": "nodes",
+			"properties": {
+				"addressPrefix": "10.0.0.0/22",
+				"delegations": [],
+				"privateEndpointNetworkPolicies": "Disabled",
+				"privateLinkServiceNetworkPolicies": "Enabled"
+			}
+		}`),
+		testSubnet2ID: subnetPayload(t, `{
+			"name": "pods",
+			"properties": {
+				"addressPrefix": "10.0.4.0/22",
+				"delegations": [{
+					"name": "aks-delegation",
+					"properties": {"serviceName": "Microsoft.ContainerService/managedClusters"}
+				}],
+				"serviceAssociationLinks": [{
+					"name": "aks-link",
+					"properties": {"linkedResourceType": "Microsoft.ContainerService/managedClusters"}
+				}],
+				"privateEndpointNetworkPolicies": "Disabled",
+				"privateLinkServiceNetworkPolicies": "Enabled"
+			}
+		}`),
+		testVNetID + "/subnets/apps": subnetPayload(t, `{
+			"name": "apps",
+			"properties": {
+				"addressPrefix": "10.0.8.0/24",
+				"delegations": [{
+					"name": "webapp",
+					"properties": {"serviceName": "Microsoft.Web/serverFarms"}
+				}],
+				"serviceAssociationLinks": [{
+					"name": "AppServiceLink",
+					"properties": {"linkedResourceType": "Microsoft.Web/serverfarms"}
+				}],
+				"privateEndpointNetworkPolicies": "Enabled",
+				"privateLinkServiceNetworkPolicies": "Disabled"
+			}
+		}`),
+	}}
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.SubnetDelegationRule
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the subnets have no delegations.",
+			rule:         v1alpha1.SubnetDelegationRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Passes when the subnets are delegated and linked to the allowed service, with the expected policies.",
+			rule: v1alpha1.SubnetDelegationRule{
+				SubnetIDs:                         []v1alpha1.SubnetID{testSubnet1ID, testSubnet2ID},
+				AllowedDelegation:                 "microsoft.containerservice/managedclusters",
+				PrivateEndpointNetworkPolicies:    "Disabled",
+				PrivateLinkServiceNetworkPolicies: "Enabled",
+			},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for each delegation and service association link when no delegation is allowed.",
+			rule: v1alpha1.SubnetDelegationRule{SubnetIDs: []v1alpha1.SubnetID{testSubnet2ID}},
+			wantFailures: []string{
+				"Subnet " + testSubnet2ID + " has delegation aks-delegation to Microsoft.ContainerService/managedClusters, which conflicts with the rule.",
+				"Subnet " + testSubnet2ID + " has service association link aks-link from Microsoft.ContainerService/managedClusters, which conflicts with the rule.",
+			},
+		},
+		{
+			name: "Fails for conflicting delegations, service association links, and policies.",
+			rule: v1alpha1.SubnetDelegationRule{
+				SubnetIDs:                         []v1alpha1.SubnetID{testVNetID + "/subnets/apps"},
+				AllowedDelegation:                 "Microsoft.ContainerService/managedClusters",
+				PrivateEndpointNetworkPolicies:    "Disabled",
+				PrivateLinkServiceNetworkPolicies: "Enabled",
+			},
+			wantFailures: []string{
+				"Subnet " + testVNetID + "/subnets/apps has delegation webapp to Microsoft.Web/serverFarms, which conflicts with the rule.",
+				"Subnet " + testVNetID + "/subnets/apps has service association link AppServiceLink from Microsoft.Web/serverfarms, which conflicts with the rule.",
+				"Subnet " + testVNetID + "/subnets/apps has private endpoint network policies Enabled, not Disabled.",
+				"Subnet " + testVNetID + "/subnets/apps has private link service network policies Disabled, not Enabled.",
+			},
+		},
+		{
+			name:         "Fails when the subnet is missing.",
+			rule:         v1alpha1.SubnetDelegationRule{SubnetIDs: []v1alpha1.SubnetID{testVNetID + "/subnets/deleted"}},
+			wantFailures: []string{"Subnet " + testVNetID + "/subnets/deleted not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSubnetDelegationRuleService(logr.Discard(), api)
+
+			tt.rule.Name = "rule-1"
+			result, err := svc.ReconcileSubnetDelegationRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestSubnetDelegationRuleService_ReconcileSubnetDelegationRule_Error(t *testing.T) {
+	api := networkAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewSubnetDelegationRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileSubnetDelegationRule(v1alpha1.SubnetDelegationRule{Name: "rule-1", SubnetIDs: []v1alpha1.SubnetID{testSubnet1ID}})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,246 @@
+package validators
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// deploymentActions are needed to deploy any template.
+var deploymentActions = []string{
+	"Microsoft.Resources/deployments/read",
+	"Microsoft.Resources/deployments/validate/action",
+	"Microsoft.Resources/deployments/write",
+}
+
+// implicitActions are Actions that deploying resources of a type commonly needs on other
+// resources, keyed by lowercase resource type. Rules can add to them with resourceTypeActions.
+var implicitActions = map[string][]string{
+	"microsoft.compute/virtualmachines":          {"Microsoft.Network/networkInterfaces/join/action"},
+	"microsoft.compute/virtualmachinescalesets":  {"Microsoft.Network/virtualNetworks/subnets/join/action"},
+	"microsoft.network/networkinterfaces":        {"Microsoft.Network/virtualNetworks/subnets/join/action"},
+	"microsoft.network/privateendpoints":         {"Microsoft.Network/virtualNetworks/subnets/join/action"},
+	"microsoft.containerservice/managedclusters": {"Microsoft.Network/virtualNetworks/subnets/join/action"},
+}
+
+// TemplatePermissionRuleService validates template permission rules by deriving a permission set
+// from the template and processing it like an RBACRuleService does.
+type TemplatePermissionRuleService struct {
+	log  logr.Logger
+	rbac *RBACRuleService
+}
+
+func NewTemplatePermissionRuleService(log logr.Logger, daAPI denyAssignmentAPI, raAPI roleAssignmentAPI, rdAPI roleDefinitionAPI) *TemplatePermissionRuleService {
+	return &TemplatePermissionRuleService{
+		log:  log,
+		rbac: NewRBACRuleService(log, daAPI, raAPI, rdAPI, nil),
+	}
+}
+
+// ReconcileTemplatePermissionRule reconciles a template permission rule from a validation config.
+// The rule's template must already have been resolved into rule.Template.
+func (s *TemplatePermissionRuleService) ReconcileTemplatePermissionRule(rule v1alpha1.TemplatePermissionRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this template permission rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.TemplatePermissionSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeTemplatePermission
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	// Rules whose specs have problems, e.g. templates that aren't valid ARM templates, fail without
+	// being evaluated. The spec condition lists the problems.
+	if errs := TemplatePermissionRuleSpecErrors(rule, nil); len(errs) > 0 {
+		setSpecInvalid(&state, &latestCondition, errs)
+		return validationResult, nil
+	}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeTemplatePermission, "scope", rule.Scope)
+	ev := &evidence{}
+	msgs, err := messages.NewTemplates(rule.FailureMessageTemplate)
+	if err != nil {
+		return validationResult, azure_errors.Terminal(err)
+	}
+	actions, resourceTypes, err := templateActions(rule, &latestCondition.Failures)
+	if err != nil {
+		return validationResult, azure_errors.Terminal(err)
+	}
+	ev.add("Template has %d resource type(s) (%s), whose deployment requires %d Action(s).", len(resourceTypes), strings.Join(resourceTypes, ", "), len(actions))
+
+	set := v1alpha1.PermissionSet{Scope: rule.Scope}
+	for _, a := range actions {
+		set.Actions = append(set.Actions, v1alpha1.ActionStr(a))
+	}
+	l.V(1).Info("Processing permission set derived from template", "actions", len(actions))
+	failures := &setFailures{}
+	if err := s.rbac.processPermissionSet(scopedPermissionSet{PermissionSet: set}, newRBACQueries(rule.PrincipalID, rule.ExpandPrincipalGroups, ""), failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to process permission set derived from template", err, &latestCondition)
+	}
+	latestCondition.Failures = append(latestCondition.Failures, failures.render(msgs)...)
+
+	ev.addRequestIDs(s.rbac.daAPI, s.rbac.raAPI, s.rbac.rdAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.TemplatePermissionFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// armTemplate is the part of an ARM template that the Actions needed to deploy it are derived
+// from.
+type armTemplate struct {
+	// Either an array of resources, or, in templates with languageVersion 2.0, an object whose
+	// keys are the resources' symbolic names.
+	Resources json.RawMessage `json:"resources"`
+}
+
+type armResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// Existing resources are only referenced by the template, not deployed.
+	Existing   bool            `json:"existing"`
+	Resources  json.RawMessage `json:"resources"`
+	Properties struct {
+		// Set for nested deployments with an inline template.
+		Template *armTemplate `json:"template"`
+		// Set for nested deployments with a linked template.
+		TemplateLink json.RawMessage `json:"templateLink"`
+	} `json:"properties"`
+}
+
+// templateActions returns the sorted Actions needed to deploy a rule's template, and the sorted
+// resource types that they were derived from. Resources whose Actions can't be derived (e.g.
+// because their type is a template expression) are appended to failures.
+func templateActions(rule v1alpha1.TemplatePermissionRule, failures *[]string) ([]string, []string, error) {
+	var template armTemplate
+	if err := json.Unmarshal([]byte(rule.Template), &template); err != nil {
+		return nil, nil, err
+	}
+
+	extraActions := map[string][]string{}
+	for _, rta := range rule.ResourceTypeActions {
+		key := strings.ToLower(rta.ResourceType)
+		for _, a := range rta.Actions {
+			extraActions[key] = append(extraActions[key], string(a))
+		}
+	}
+
+	// Actions and resource types are deduplicated case-insensitively, keeping the first spelling.
+	actions := map[string]string{}
+	addAction := func(a string) {
+		if _, ok := actions[strings.ToLower(a)]; !ok {
+			actions[strings.ToLower(a)] = a
+		}
+	}
+	resourceTypes := map[string]string{}
+	for _, a := range deploymentActions {
+		addAction(a)
+	}
+
+	var walk func(raw json.RawMessage, parentType string) error
+	walk = func(raw json.RawMessage, parentType string) error {
+		resources, err := templateResources(raw)
+		if err != nil {
+			return err
+		}
+		for _, r := range resources {
+			if strings.HasPrefix(r.Type, "[") {
+				*failures = append(*failures, fmt.Sprintf(messages.TemplateExpressionTypeFailure, r.Type, r.Name))
+				continue
+			}
+			resourceType := r.Type
+			// Nested child resources may have a type relative to their parent's (e.g. subnets).
+			if parentType != "" && !strings.Contains(strings.SplitN(r.Type, "/", 2)[0], ".") {
+				resourceType = parentType + "/" + r.Type
+			}
+			key := strings.ToLower(resourceType)
+			if _, ok := resourceTypes[key]; !ok {
+				resourceTypes[key] = resourceType
+			}
+
+			if r.Existing {
+				addAction(resourceType + "/read")
+				continue
+			}
+			addAction(resourceType + "/write")
+			for _, a := range implicitActions[key] {
+				addAction(a)
+			}
+			for _, a := range extraActions[key] {
+				addAction(a)
+			}
+
+			if r.Properties.Template != nil {
+				if err := walk(r.Properties.Template.Resources, ""); err != nil {
+					return fmt.Errorf("nested deployment %s: %w", r.Name, err)
+				}
+			} else if len(r.Properties.TemplateLink) > 0 {
+				*failures = append(*failures, fmt.Sprintf(messages.LinkedTemplateFailure, r.Name))
+			}
+			if err := walk(r.Resources, resourceType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(template.Resources, ""); err != nil {
+		return nil, nil, err
+	}
+
+	return sortedValues(actions), sortedValues(resourceTypes), nil
+}
+
+// templateResources parses the resources of a template or of a resource, which are either an array
+// or an object keyed by symbolic name.
+func templateResources(raw json.RawMessage) ([]armResource, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var resources []armResource
+	if raw[0] == '{' {
+		bySymbolicName := map[string]armResource{}
+		if err := json.Unmarshal(raw, &bySymbolicName); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(bySymbolicName))
+		for name := range bySymbolicName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			resources = append(resources, bySymbolicName[name])
+		}
+		return resources, nil
+	}
+	if err := json.Unmarshal(raw, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func sortedValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
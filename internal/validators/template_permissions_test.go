@@ -0,0 +1,214 @@
+package validators
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// testTemplate deploys a storage account and a virtual network with a subnet, which is a nested
+// child resource with a relative type.
+const testTemplate = `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "resources": [
+    {
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[parameters('storageAccountName')]"
+    },
+    {
+      "type": "Microsoft.Network/virtualNetworks",
+      "apiVersion": "2023-09-01",
+      "name": "vnet",
+      "resources": [
+        {"type": "subnets", "apiVersion": "2023-09-01", "name": "nodes"}
+      ]
+    }
+  ]
+}`
+
+// newTemplateRoleAPIs returns a role assignment API and role definition API for a single
+// role that permits actions.
+func newTemplateRoleAPIs(actions ...string) (roleAssignmentAPIMock, roleDefinitionAPIMock) {
+	permitted := make([]*string, 0, len(actions))
+	for _, a := range actions {
+		permitted = append(permitted, util.Ptr(a))
+	}
+	ra := roleAssignmentAPIMock{data: []*armauthorization.RoleAssignment{{
+		ID:         util.Ptr("ra_id"),
+		Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: util.Ptr("role_id")},
+	}}}
+	rd := roleDefinitionAPIMock{data: map[string]*armauthorization.RoleDefinition{
+		"role_id": {Properties: &armauthorization.RoleDefinitionProperties{
+			Permissions: []*armauthorization.Permission{{
+				Actions:        permitted,
+				DataActions:    []*string{},
+				NotActions:     []*string{},
+				NotDataActions: []*string{},
+			}},
+		}},
+	}}
+	return ra, rd
+}
+
+func TestTemplatePermissionRuleService_ReconcileTemplatePermissionRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     string
+		extraActions []v1alpha1.ResourceTypeActions
+		permitted    []string
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when the principal may deploy every resource type of the template.",
+			template:     testTemplate,
+			permitted:    []string{"Microsoft.Resources/deployments/*", "Microsoft.Storage/storageAccounts/write", "Microsoft.Network/virtualNetworks/*"},
+			wantFailures: []string{},
+		},
+		{
+			name:      "Fails for each Action that the principal lacks for the template.",
+			template:  testTemplate,
+			permitted: []string{"Microsoft.Resources/deployments/*", "Microsoft.Network/virtualNetworks/write"},
+			wantFailures: []string{
+				"Action Microsoft.Network/virtualNetworks/subnets/write unpermitted because no role assignment permits it.",
+				"Action Microsoft.Storage/storageAccounts/write unpermitted because no role assignment permits it.",
+			},
+		},
+		{
+			name:         "Fails for the Actions added by resourceTypeActions.",
+			template:     testTemplate,
+			extraActions: []v1alpha1.ResourceTypeActions{{ResourceType: "microsoft.storage/storageaccounts", Actions: []v1alpha1.ActionStr{"Microsoft.Storage/storageAccounts/listKeys/action"}}},
+			permitted:    []string{"Microsoft.Resources/deployments/*", "Microsoft.Storage/storageAccounts/write", "Microsoft.Network/virtualNetworks/*"},
+			wantFailures: []string{"Action Microsoft.Storage/storageAccounts/listKeys/action unpermitted because no role assignment permits it."},
+		},
+		{
+			name:         "Fails when the template isn't valid JSON, without querying Azure.",
+			template:     `{"resources": [`,
+			wantFailures: []string{"Rule's spec has 1 problem(s), which the azure-spec condition lists."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ra, rd := newTemplateRoleAPIs(tt.permitted...)
+			svc := NewTemplatePermissionRuleService(logr.Discard(), denyAssignmentAPIMock{}, ra, rd)
+
+			result, err := svc.ReconcileTemplatePermissionRule(v1alpha1.TemplatePermissionRule{
+				Name:                "rule-1",
+				PrincipalID:         "p_id",
+				Scope:               "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg",
+				Template:            tt.template,
+				ResourceTypeActions: tt.extraActions,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func Test_templateActions(t *testing.T) {
+	tests := []struct {
+		name              string
+		template          string
+		wantActions       []string
+		wantResourceTypes []string
+		wantFailures      []string
+	}{
+		{
+			name:     "Derives write Actions, including for nested child resources.",
+			template: testTemplate,
+			wantActions: []string{
+				"Microsoft.Network/virtualNetworks/subnets/write",
+				"Microsoft.Network/virtualNetworks/write",
+				"Microsoft.Resources/deployments/read",
+				"Microsoft.Resources/deployments/validate/action",
+				"Microsoft.Resources/deployments/write",
+				"Microsoft.Storage/storageAccounts/write",
+			},
+			wantResourceTypes: []string{"Microsoft.Network/virtualNetworks", "Microsoft.Network/virtualNetworks/subnets", "Microsoft.Storage/storageAccounts"},
+		},
+		{
+			name: "Derives read Actions for existing resources and implicit Actions, in templates with symbolic names.",
+			template: `{"languageVersion": "2.0", "resources": {
+				"subnet": {"type": "Microsoft.Network/virtualNetworks/subnets", "existing": true, "name": "vnet/nodes"},
+				"nic": {"type": "Microsoft.Network/networkInterfaces", "name": "nic"}
+			}}`,
+			wantActions: []string{
+				"Microsoft.Network/networkInterfaces/write",
+				"Microsoft.Network/virtualNetworks/subnets/join/action",
+				"Microsoft.Network/virtualNetworks/subnets/read",
+				"Microsoft.Resources/deployments/read",
+				"Microsoft.Resources/deployments/validate/action",
+				"Microsoft.Resources/deployments/write",
+			},
+			wantResourceTypes: []string{"Microsoft.Network/networkInterfaces", "Microsoft.Network/virtualNetworks/subnets"},
+		},
+		{
+			name: "Derives Actions for the resources of inline nested deployments.",
+			template: `{"resources": [
+				{"type": "Microsoft.Resources/deployments", "name": "nested", "properties": {"template": {"resources": [
+					{"type": "Microsoft.ManagedIdentity/userAssignedIdentities", "name": "id"}
+				]}}}
+			]}`,
+			wantActions: []string{
+				"Microsoft.ManagedIdentity/userAssignedIdentities/write",
+				"Microsoft.Resources/deployments/read",
+				"Microsoft.Resources/deployments/validate/action",
+				"Microsoft.Resources/deployments/write",
+			},
+			wantResourceTypes: []string{"Microsoft.ManagedIdentity/userAssignedIdentities", "Microsoft.Resources/deployments"},
+		},
+		{
+			name: "Fails for resources whose Actions can't be derived.",
+			template: `{"resources": [
+				{"type": "[variables('type')]", "name": "dynamic"},
+				{"type": "Microsoft.Resources/deployments", "name": "linked", "properties": {"templateLink": {"uri": "https://example.com/t.json"}}}
+			]}`,
+			wantActions: []string{
+				"Microsoft.Resources/deployments/read",
+				"Microsoft.Resources/deployments/validate/action",
+				"Microsoft.Resources/deployments/write",
+			},
+			wantResourceTypes: []string{"Microsoft.Resources/deployments"},
+			wantFailures: []string{
+				"Type [variables('type')] of resource dynamic is a template expression, so the Actions needed to deploy it can't be derived.",
+				"Nested deployment linked links to its template, so the Actions needed to deploy it can't be derived.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failures []string
+			actions, resourceTypes, err := templateActions(v1alpha1.TemplatePermissionRule{Template: tt.template}, &failures)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actions, tt.wantActions) {
+				t.Errorf("got actions %v, want %v", actions, tt.wantActions)
+			}
+			if !reflect.DeepEqual(resourceTypes, tt.wantResourceTypes) {
+				t.Errorf("got resource types %v, want %v", resourceTypes, tt.wantResourceTypes)
+			}
+			if !reflect.DeepEqual(failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", failures, tt.wantFailures)
+			}
+		})
+	}
+}
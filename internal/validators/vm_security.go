@@ -0,0 +1,166 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// imageSecurityTypes are, for each security type, the values of an image definition's SecurityType
+// feature with which VMs can be created with the security type.
+var imageSecurityTypes = map[string][]string{
+	securityTypeTrustedLaunch:  {"TrustedLaunch", "TrustedLaunchSupported", "TrustedLaunchAndConfidentialVmSupported"},
+	securityTypeConfidentialVM: {"ConfidentialVM", "ConfidentialVmSupported", "TrustedLaunchAndConfidentialVmSupported"},
+}
+
+type VMSecurityRuleService struct {
+	log      logr.Logger
+	imageAPI galleryImageAPI
+	skuAPI   resourceSKUAPI
+}
+
+func NewVMSecurityRuleService(log logr.Logger, imageAPI galleryImageAPI, skuAPI resourceSKUAPI) *VMSecurityRuleService {
+	return &VMSecurityRuleService{
+		log:      log,
+		imageAPI: imageAPI,
+		skuAPI:   skuAPI,
+	}
+}
+
+// ReconcileVMSecurityRule reconciles a VM security rule from a validation config.
+func (s *VMSecurityRuleService) ReconcileVMSecurityRule(rule v1alpha1.VMSecurityRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this VM security rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = fmt.Sprintf(messages.VMSecuritySucceeded, rule.SecurityType)
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeVMSecurity
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeVMSecurity, "securityType", rule.SecurityType, "location", rule.Location)
+	l.V(1).Info("Validating VM sizes' support for security type")
+	ev := &evidence{}
+	if err := s.validateSecurity(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate VM security", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.imageAPI, s.skuAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = fmt.Sprintf(messages.VMSecurityFailed, rule.SecurityType)
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateSecurity appends a failure for the image definition, if the rule has one and it doesn't
+// support the security type, and for each capability that a VM size lacks for the security type.
+// Image definitions that don't exist and VM sizes that aren't available in the location are
+// failures, not errors.
+func (s *VMSecurityRuleService) validateSecurity(rule v1alpha1.VMSecurityRule, failures *[]string, ev *evidence) error {
+	if rule.ImageDefinitionID != "" {
+		image, err := s.imageAPI.GetGalleryImage(rule.ImageDefinitionID)
+		if err != nil {
+			var rerr *azcore.ResponseError
+			if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusNotFound {
+				return fmt.Errorf("failed to get image definition: %w", azure_errors.AsAugmented(err))
+			}
+			*failures = append(*failures, fmt.Sprintf(messages.ImageDefinitionNotFoundFailure, rule.ImageDefinitionID))
+		} else {
+			imageSecurityType := imageSecurityType(image)
+			if slices.ContainsFunc(imageSecurityTypes[rule.SecurityType], func(t string) bool { return strings.EqualFold(t, imageSecurityType) }) {
+				ev.add("Image definition %s has security type %s, which supports %s.", rule.ImageDefinitionID, imageSecurityType, rule.SecurityType)
+			} else {
+				*failures = append(*failures, fmt.Sprintf(messages.ImageSecurityTypeFailure, rule.ImageDefinitionID, imageSecurityType, rule.SecurityType))
+			}
+		}
+	}
+
+	skus, err := s.skuAPI.ListVirtualMachineSKUs(rule.Location)
+	if err != nil {
+		return fmt.Errorf("failed to list VM sizes: %w", azure_errors.AsAugmented(err))
+	}
+	for _, size := range rule.VMSizes {
+		i := slices.IndexFunc(skus, func(sku *armcompute.ResourceSKU) bool {
+			return sku.Name != nil && strings.EqualFold(*sku.Name, size)
+		})
+		if i < 0 {
+			*failures = append(*failures, fmt.Sprintf(messages.VMSizeUnavailableFailure, size, rule.Location))
+			continue
+		}
+		lacking := securityIncompatibilities(rule, size, skuCapabilities(skus[i]))
+		if len(lacking) == 0 {
+			ev.add("VM size %s supports %s.", size, rule.SecurityType)
+		}
+		*failures = append(*failures, lacking...)
+	}
+
+	return nil
+}
+
+// imageSecurityType returns the value of an image definition's SecurityType feature. Images without
+// the feature are Standard, like Azure assumes.
+func imageSecurityType(image *armcompute.GalleryImage) string {
+	if image.Properties != nil {
+		for _, f := range image.Properties.Features {
+			if f != nil && f.Name != nil && f.Value != nil && strings.EqualFold(*f.Name, featureSecurityType) {
+				return *f.Value
+			}
+		}
+	}
+	return "Standard"
+}
+
+// securityIncompatibilities returns a failure for each capability that a VM size lacks to create
+// VMs with a rule's security type. Both trusted launch and confidential VMs are Gen2 only.
+func securityIncompatibilities(rule v1alpha1.VMSecurityRule, size string, capabilities map[string]string) []string {
+	var failures []string
+
+	// Sizes without the capability only support Gen1.
+	generations := string(armcompute.HyperVGenerationV1)
+	if v, ok := capabilities[strings.ToLower(capabilityHyperVGenerations)]; ok {
+		generations = v
+	}
+	if !slices.ContainsFunc(strings.Split(generations, ","), func(g string) bool {
+		return strings.EqualFold(strings.TrimSpace(g), string(armcompute.HyperVGenerationV2))
+	}) {
+		failures = append(failures, fmt.Sprintf(messages.VMSizeGenerationFailure, size, capabilityHyperVGenerations, rule.SecurityType, generations))
+	}
+
+	switch rule.SecurityType {
+	case securityTypeTrustedLaunch:
+		if v := capabilities[strings.ToLower(capabilityTrustedLaunchDisabled)]; strings.EqualFold(v, "true") {
+			failures = append(failures, fmt.Sprintf(messages.VMSizeTrustedLaunchFailure, size, capabilityTrustedLaunchDisabled, v))
+		}
+	case securityTypeConfidentialVM:
+		v := capabilities[strings.ToLower(capabilityConfidentialComputingType)]
+		switch {
+		case v == "":
+			failures = append(failures, fmt.Sprintf(messages.VMSizeConfidentialMissingFailure, size, capabilityConfidentialComputingType))
+		case rule.ConfidentialComputingType != "" && !strings.EqualFold(v, rule.ConfidentialComputingType):
+			failures = append(failures, fmt.Sprintf(messages.VMSizeConfidentialTypeFailure, size, capabilityConfidentialComputingType, v, rule.ConfidentialComputingType))
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,284 @@
+package validators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// vmSecuritySKUs are VM sizes as the Resource SKUs API returns them, trimmed to the capabilities
+// that matter: Standard_D2_v2 is Gen1 only and doesn't support trusted launch, Standard_D4s_v5
+// supports trusted launch, Standard_DC4as_v5 is an AMD SEV-SNP confidential size, and
+// Standard_DC4es_v5 is an Intel TDX confidential size.
+const vmSecuritySKUs = `[
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D2_v2",
+		"tier": "Standard",
+		"size": "D2_v2",
+		"family": "standardDv2Family",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "2"},
+			{"name": "MemoryGB", "value": "7"},
+			{"name": "HyperVGenerations", "value": "V1"},
+			{"name": "CpuArchitectureType", "value": "x64"},
+			{"name": "TrustedLaunchDisabled", "value": "True"}
+		]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D4s_v5",
+		"tier": "Standard",
+		"size": "D4s_v5",
+		"family": "standardDSv5Family",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "4"},
+			{"name": "MemoryGB", "value": "16"},
+			{"name": "HyperVGenerations", "value": "V1,V2"},
+			{"name": "CpuArchitectureType", "value": "x64"},
+			{"name": "PremiumIO", "value": "True"}
+		]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_DC4as_v5",
+		"tier": "Standard",
+		"size": "DC4as_v5",
+		"family": "standardDCASv5Family",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "4"},
+			{"name": "MemoryGB", "value": "16"},
+			{"name": "HyperVGenerations", "value": "V2"},
+			{"name": "CpuArchitectureType", "value": "x64"},
+			{"name": "ConfidentialComputingType", "value": "SNP"},
+			{"name": "TrustedLaunchDisabled", "value": "True"}
+		]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_DC4es_v5",
+		"tier": "Standard",
+		"size": "DC4es_v5",
+		"family": "standardDCESv5Family",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "4"},
+			{"name": "MemoryGB", "value": "16"},
+			{"name": "HyperVGenerations", "value": "V2"},
+			{"name": "CpuArchitectureType", "value": "x64"},
+			{"name": "ConfidentialComputingType", "value": "TDX"},
+			{"name": "TrustedLaunchDisabled", "value": "True"}
+		]
+	}
+]`
+
+func parseSKUs(t *testing.T, payload string) []*armcompute.ResourceSKU {
+	t.Helper()
+	var skus []*armcompute.ResourceSKU
+	if err := json.Unmarshal([]byte(payload), &skus); err != nil {
+		t.Fatalf("failed to parse SKUs: %v", err)
+	}
+	return skus
+}
+
+func Test_securityIncompatibilities(t *testing.T) {
+	skus := parseSKUs(t, vmSecuritySKUs)
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.VMSecurityRule
+		size         int
+		wantFailures []string
+	}{
+		{
+			name: "A Gen1 size without trusted launch lacks both capabilities.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "TrustedLaunch"},
+			size: 0,
+			wantFailures: []string{
+				"VM size Standard_D2_v2 lacks capability HyperVGenerations=V2, which TrustedLaunch requires; it has V1.",
+				"VM size Standard_D2_v2 doesn't support trusted launch: its capability TrustedLaunchDisabled is True.",
+			},
+		},
+		{
+			name: "A Gen2 size without TrustedLaunchDisabled supports trusted launch.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "TrustedLaunch"},
+			size: 1,
+		},
+		{
+			name: "A size without ConfidentialComputingType doesn't support confidential VMs.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "ConfidentialVM"},
+			size: 1,
+			wantFailures: []string{
+				"VM size Standard_D4s_v5 doesn't support confidential VMs: it lacks capability ConfidentialComputingType.",
+			},
+		},
+		{
+			name: "A confidential size supports confidential VMs of any type if none is requested.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "ConfidentialVM"},
+			size: 3,
+		},
+		{
+			name: "A confidential size supports confidential VMs of its type, regardless of case.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "ConfidentialVM", ConfidentialComputingType: "snp"},
+			size: 2,
+		},
+		{
+			name: "A confidential size of another type doesn't support the requested type.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "ConfidentialVM", ConfidentialComputingType: "SNP"},
+			size: 3,
+			wantFailures: []string{
+				"VM size Standard_DC4es_v5 has capability ConfidentialComputingType=TDX, not SNP.",
+			},
+		},
+		{
+			name: "A confidential size doesn't support trusted launch.",
+			rule: v1alpha1.VMSecurityRule{SecurityType: "TrustedLaunch"},
+			size: 2,
+			wantFailures: []string{
+				"VM size Standard_DC4as_v5 doesn't support trusted launch: its capability TrustedLaunchDisabled is True.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := securityIncompatibilities(tt.rule, *skus[tt.size].Name, skuCapabilities(skus[tt.size]))
+			if !reflect.DeepEqual(got, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestVMSecurityRuleService_ReconcileVMSecurityRule(t *testing.T) {
+	skus := parseSKUs(t, vmSecuritySKUs)
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.VMSecurityRule
+		image        *armcompute.GalleryImage
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name: "Passes when all sizes and the image support trusted launch.",
+			rule: v1alpha1.VMSecurityRule{
+				SecurityType:      "TrustedLaunch",
+				ImageDefinitionID: testImageDefinitionID,
+				VMSizes:           []string{"standard_d4s_v5"},
+			},
+			image:        newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, "TrustedLaunchSupported"),
+			wantFailures: []string{},
+			wantDetails: []string{
+				"Image definition " + testImageDefinitionID + " has security type TrustedLaunchSupported, which supports TrustedLaunch.",
+				"VM size standard_d4s_v5 supports TrustedLaunch.",
+			},
+		},
+		{
+			name: "Fails for each size lacking a capability and for a size that isn't available.",
+			rule: v1alpha1.VMSecurityRule{
+				SecurityType:              "ConfidentialVM",
+				ConfidentialComputingType: "TDX",
+				VMSizes:                   []string{"Standard_DC4es_v5", "Standard_DC4as_v5", "Standard_D2_v2", "Standard_F2"},
+			},
+			wantFailures: []string{
+				"VM size Standard_DC4as_v5 has capability ConfidentialComputingType=SNP, not TDX.",
+				"VM size Standard_D2_v2 lacks capability HyperVGenerations=V2, which ConfidentialVM requires; it has V1.",
+				"VM size Standard_D2_v2 doesn't support confidential VMs: it lacks capability ConfidentialComputingType.",
+				"VM size Standard_F2 is not available in location eastus.",
+			},
+			wantDetails: []string{"VM size Standard_DC4es_v5 supports ConfidentialVM."},
+		},
+		{
+			name: "Fails when the image doesn't support the security type.",
+			rule: v1alpha1.VMSecurityRule{
+				SecurityType:      "ConfidentialVM",
+				ImageDefinitionID: testImageDefinitionID,
+				VMSizes:           []string{"Standard_DC4as_v5"},
+			},
+			image: newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, "TrustedLaunchSupported"),
+			wantFailures: []string{
+				"Image definition " + testImageDefinitionID + " has security type TrustedLaunchSupported, which doesn't support ConfidentialVM.",
+			},
+			wantDetails: []string{"VM size Standard_DC4as_v5 supports ConfidentialVM."},
+		},
+		{
+			name: "Treats images without a security type as Standard.",
+			rule: v1alpha1.VMSecurityRule{
+				SecurityType:      "TrustedLaunch",
+				ImageDefinitionID: testImageDefinitionID,
+				VMSizes:           []string{"Standard_D4s_v5"},
+			},
+			image: newGalleryImage(armcompute.HyperVGenerationV2, armcompute.ArchitectureX64, ""),
+			wantFailures: []string{
+				"Image definition " + testImageDefinitionID + " has security type Standard, which doesn't support TrustedLaunch.",
+			},
+			wantDetails: []string{"VM size Standard_D4s_v5 supports TrustedLaunch."},
+		},
+		{
+			name: "Fails when the image definition doesn't exist.",
+			rule: v1alpha1.VMSecurityRule{
+				SecurityType:      "TrustedLaunch",
+				ImageDefinitionID: testImageDefinitionID,
+				VMSizes:           []string{"Standard_D4s_v5"},
+			},
+			wantFailures: []string{"Image definition " + testImageDefinitionID + " not found."},
+			wantDetails:  []string{"VM size Standard_D4s_v5 supports TrustedLaunch."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewVMSecurityRuleService(logr.Discard(), galleryImageAPIMock{data: tt.image}, resourceSKUAPIMock{data: skus})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.Location = "eastus"
+			result, err := svc.ReconcileVMSecurityRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestVMSecurityRuleService_ReconcileVMSecurityRule_Error(t *testing.T) {
+	skuAPI := resourceSKUAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewVMSecurityRuleService(logr.Discard(), galleryImageAPIMock{}, skuAPI)
+
+	result, err := svc.ReconcileVMSecurityRule(v1alpha1.VMSecurityRule{
+		Name:         "rule-1",
+		SecurityType: "TrustedLaunch",
+		Location:     "eastus",
+		VMSizes:      []string{"Standard_D4s_v5"},
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
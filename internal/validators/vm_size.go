@@ -0,0 +1,154 @@
+package validators
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// Names of the VM size capabilities that VM size rules check.
+const (
+	capabilityAcceleratedNetworkingEnabled = "AcceleratedNetworkingEnabled"
+	capabilityMaxNetworkInterfaces         = "MaxNetworkInterfaces"
+	capabilityVCPUs                        = "vCPUs"
+)
+
+type VMSizeRuleService struct {
+	log    logr.Logger
+	skuAPI resourceSKUAPI
+}
+
+func NewVMSizeRuleService(log logr.Logger, skuAPI resourceSKUAPI) *VMSizeRuleService {
+	return &VMSizeRuleService{
+		log:    log,
+		skuAPI: skuAPI,
+	}
+}
+
+// ReconcileVMSizeRule reconciles a VM size rule from a validation config.
+func (s *VMSizeRuleService) ReconcileVMSizeRule(rule v1alpha1.VMSizeRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this VM size rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.VMSizeSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeVMSize
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeVMSize, "location", rule.Location)
+	l.V(1).Info("Validating VM sizes' capabilities")
+	ev := &evidence{}
+	skus, err := s.skuAPI.ListVirtualMachineSKUs(rule.Location)
+	if err != nil {
+		err = fmt.Errorf("failed to list VM sizes: %w", azure_errors.AsAugmented(err))
+		return validationResult, recordError(l, "failed to validate VM sizes", err, &latestCondition)
+	}
+	for _, size := range rule.VMSizes {
+		i := slices.IndexFunc(skus, func(sku *armcompute.ResourceSKU) bool {
+			return sku.Name != nil && strings.EqualFold(*sku.Name, size)
+		})
+		if i < 0 {
+			latestCondition.Failures = append(latestCondition.Failures, fmt.Sprintf(messages.VMSizeUnavailableFailure, size, rule.Location))
+			continue
+		}
+		observed, lacking := sizeIncompatibilities(rule, size, skuCapabilities(skus[i]))
+		if len(lacking) == 0 {
+			ev.add("VM size %s has %s.", size, strings.Join(observed, ", "))
+		}
+		latestCondition.Failures = append(latestCondition.Failures, lacking...)
+	}
+
+	ev.addRequestIDs(s.skuAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.VMSizeFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// sizeIncompatibilities returns the capabilities of a VM size that a rule checks, as name=value,
+// and a failure for each of them that the size lacks or whose value doesn't meet the rule's
+// requirement. Capabilities with values that can't be parsed are failures too.
+func sizeIncompatibilities(rule v1alpha1.VMSizeRule, size string, capabilities map[string]string) (observed, failures []string) {
+	if rule.AcceleratedNetworking {
+		enabled, ok, err := boolCapability(capabilities, capabilityAcceleratedNetworkingEnabled)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf(messages.VMSizeCheckFailure, size, err))
+		case !ok:
+			failures = append(failures, fmt.Sprintf(messages.AcceleratedNetworkingMissingFailure, size, capabilityAcceleratedNetworkingEnabled))
+		case !enabled:
+			failures = append(failures, fmt.Sprintf(messages.AcceleratedNetworkingUnsupportedFailure, size, capabilityAcceleratedNetworkingEnabled, capabilities[strings.ToLower(capabilityAcceleratedNetworkingEnabled)]))
+		default:
+			observed = append(observed, fmt.Sprintf("%s=%t", capabilityAcceleratedNetworkingEnabled, enabled))
+		}
+	}
+
+	atLeast := func(name string, min int) {
+		if min == 0 {
+			return
+		}
+		n, ok, err := intCapability(capabilities, name)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf(messages.VMSizeCheckFailure, size, err))
+		case !ok:
+			failures = append(failures, fmt.Sprintf(messages.VMSizeCapabilityMissingFailure, size, name))
+		case n < min:
+			failures = append(failures, fmt.Sprintf(messages.VMSizeCapabilityFailure, size, name, n, min))
+		default:
+			observed = append(observed, fmt.Sprintf("%s=%d", name, n))
+		}
+	}
+	atLeast(capabilityMaxNetworkInterfaces, rule.MinNetworkInterfaces)
+	atLeast(capabilityVCPUs, rule.MinVCPUs)
+
+	return observed, failures
+}
+
+// boolCapability parses a boolean capability of a VM size, e.g. "True". ok is false if the size
+// lacks the capability.
+func boolCapability(capabilities map[string]string, name string) (value, ok bool, err error) {
+	v, ok := capabilities[strings.ToLower(name)]
+	if !ok {
+		return false, false, nil
+	}
+	value, err = strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return false, true, fmt.Errorf("capability %s has value %q, which isn't a boolean", name, v)
+	}
+	return value, true, nil
+}
+
+// intCapability parses an integer capability of a VM size, e.g. "8". ok is false if the size lacks
+// the capability.
+func intCapability(capabilities map[string]string, name string) (value int, ok bool, err error) {
+	v, ok := capabilities[strings.ToLower(name)]
+	if !ok {
+		return 0, false, nil
+	}
+	value, err = strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, true, fmt.Errorf("capability %s has value %q, which isn't an integer", name, v)
+	}
+	return value, true, nil
+}
@@ -0,0 +1,223 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// vmSizeSKUs are VM sizes as the Resource SKUs API returns them, trimmed to the capabilities that
+// matter. Standard_A1_v2 doesn't support accelerated networking, and Standard_B1s has a malformed
+// vCPUs capability and no MaxNetworkInterfaces one.
+const vmSizeSKUs = `[
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_A1_v2",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "1"},
+			{"name": "MaxNetworkInterfaces", "value": "2"},
+			{"name": "AcceleratedNetworkingEnabled", "value": "False"}
+		]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D4s_v5",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "4"},
+			{"name": "vCPUsAvailable", "value": "4"},
+			{"name": "MaxNetworkInterfaces", "value": "2"},
+			{"name": "AcceleratedNetworkingEnabled", "value": "True"}
+		]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D16s_v5",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "16"},
+			{"name": "vCPUsAvailable", "value": "16"},
+			{"name": "MaxNetworkInterfaces", "value": "8"},
+			{"name": "AcceleratedNetworkingEnabled", "value": "True"}
+		]
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_B1s",
+		"locations": ["eastus"],
+		"capabilities": [
+			{"name": "vCPUs", "value": "1.5"}
+		]
+	}
+]`
+
+func Test_boolCapability(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		absent    bool
+		wantValue bool
+		wantOK    bool
+		wantErr   bool
+	}{
+		{name: "Parses True.", value: "True", wantValue: true, wantOK: true},
+		{name: "Parses False.", value: "False", wantOK: true},
+		{name: "Parses lowercase values.", value: "true", wantValue: true, wantOK: true},
+		{name: "Reports absent capabilities.", absent: true},
+		{name: "Fails for values that aren't booleans.", value: "Yes", wantOK: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capabilities := map[string]string{}
+			if !tt.absent {
+				capabilities["acceleratednetworkingenabled"] = tt.value
+			}
+			value, ok, err := boolCapability(capabilities, capabilityAcceleratedNetworkingEnabled)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if value != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("got (%t, %t), want (%t, %t)", value, ok, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_intCapability(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		absent    bool
+		wantValue int
+		wantOK    bool
+		wantErr   bool
+	}{
+		{name: "Parses integers.", value: "8", wantValue: 8, wantOK: true},
+		{name: "Ignores surrounding whitespace.", value: " 16 ", wantValue: 16, wantOK: true},
+		{name: "Reports absent capabilities.", absent: true},
+		{name: "Fails for values that aren't integers.", value: "1.5", wantOK: true, wantErr: true},
+		{name: "Fails for empty values.", value: "", wantOK: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capabilities := map[string]string{}
+			if !tt.absent {
+				capabilities["maxnetworkinterfaces"] = tt.value
+			}
+			value, ok, err := intCapability(capabilities, capabilityMaxNetworkInterfaces)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if value != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("got (%d, %t), want (%d, %t)", value, ok, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestVMSizeRuleService_ReconcileVMSizeRule(t *testing.T) {
+	skus := parseSKUs(t, vmSizeSKUs)
+
+	tests := []struct {
+		name         string
+		rule         v1alpha1.VMSizeRule
+		wantFailures []string
+		wantDetails  []string
+	}{
+		{
+			name: "Passes when all sizes have the required capabilities.",
+			rule: v1alpha1.VMSizeRule{
+				VMSizes:               []string{"Standard_D4s_v5", "standard_d16s_v5"},
+				AcceleratedNetworking: true,
+				MinNetworkInterfaces:  2,
+				MinVCPUs:              4,
+			},
+			wantFailures: []string{},
+			wantDetails: []string{
+				"VM size Standard_D4s_v5 has AcceleratedNetworkingEnabled=true, MaxNetworkInterfaces=2, vCPUs=4.",
+				"VM size standard_d16s_v5 has AcceleratedNetworkingEnabled=true, MaxNetworkInterfaces=8, vCPUs=16.",
+			},
+		},
+		{
+			name: "Fails for each capability that a size lacks, or that is too small.",
+			rule: v1alpha1.VMSizeRule{
+				VMSizes:               []string{"Standard_A1_v2", "Standard_D4s_v5", "Standard_D16s_v5"},
+				AcceleratedNetworking: true,
+				MinNetworkInterfaces:  4,
+			},
+			wantFailures: []string{
+				"VM size Standard_A1_v2 doesn't support accelerated networking: its capability AcceleratedNetworkingEnabled is False.",
+				"VM size Standard_A1_v2 has capability MaxNetworkInterfaces=2, but at least 4 is required.",
+				"VM size Standard_D4s_v5 has capability MaxNetworkInterfaces=2, but at least 4 is required.",
+			},
+			wantDetails: []string{"VM size Standard_D16s_v5 has AcceleratedNetworkingEnabled=true, MaxNetworkInterfaces=8."},
+		},
+		{
+			name: "Fails for absent and malformed capabilities, and sizes that aren't available.",
+			rule: v1alpha1.VMSizeRule{
+				VMSizes:               []string{"Standard_B1s", "Standard_F2"},
+				AcceleratedNetworking: true,
+				MinNetworkInterfaces:  1,
+				MinVCPUs:              1,
+			},
+			wantFailures: []string{
+				"VM size Standard_B1s doesn't support accelerated networking: it lacks capability AcceleratedNetworkingEnabled.",
+				"VM size Standard_B1s lacks capability MaxNetworkInterfaces.",
+				`Failed to check VM size Standard_B1s: capability vCPUs has value "1.5", which isn't an integer.`,
+				"VM size Standard_F2 is not available in location eastus.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewVMSizeRuleService(logr.Discard(), resourceSKUAPIMock{data: skus})
+
+			tt.rule.Name = "rule-1"
+			tt.rule.Location = "eastus"
+			result, err := svc.ReconcileVMSizeRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			if len(result.Condition.Details)+len(tt.wantDetails) > 0 && !reflect.DeepEqual(result.Condition.Details, tt.wantDetails) {
+				t.Errorf("got details %v, want %v", result.Condition.Details, tt.wantDetails)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestVMSizeRuleService_ReconcileVMSizeRule_Error(t *testing.T) {
+	skuAPI := resourceSKUAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewVMSizeRuleService(logr.Discard(), skuAPI)
+
+	result, err := svc.ReconcileVMSizeRule(v1alpha1.VMSizeRule{
+		Name:     "rule-1",
+		Location: "eastus",
+		VMSizes:  []string{"Standard_D4s_v5"},
+		MinVCPUs: 4,
+	})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
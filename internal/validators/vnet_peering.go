@@ -0,0 +1,144 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// vnetPeeringAPI contains methods that allow getting the peerings of a virtual network by its
+// resource ID.
+type vnetPeeringAPI interface {
+	ListPeerings(virtualNetworkID string) ([]*armnetwork.VirtualNetworkPeering, error)
+}
+
+type VNetPeeringRuleService struct {
+	log logr.Logger
+	api vnetPeeringAPI
+}
+
+func NewVNetPeeringRuleService(log logr.Logger, api vnetPeeringAPI) *VNetPeeringRuleService {
+	return &VNetPeeringRuleService{
+		log: log,
+		api: api,
+	}
+}
+
+// ReconcileVNetPeeringRule reconciles a virtual network peering rule from a validation config.
+func (s *VNetPeeringRuleService) ReconcileVNetPeeringRule(rule v1alpha1.VNetPeeringRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this peering rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.VNetPeeringSucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeVNetPeering
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeVNetPeering, "virtualNetworkID", rule.VirtualNetworkID)
+	l.V(1).Info("Validating virtual network peerings")
+	ev := &evidence{}
+	if err := s.validatePeerings(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate virtual network peerings", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.api)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.VNetPeeringFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validatePeerings appends a failure for each expected peering that the virtual network doesn't
+// have, and for each setting of an existing peering that differs, with the peering's actual value.
+// A virtual network that doesn't exist is a failure, not an error.
+func (s *VNetPeeringRuleService) validatePeerings(rule v1alpha1.VNetPeeringRule, failures *[]string, ev *evidence) error {
+	peerings, err := s.api.ListPeerings(rule.VirtualNetworkID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.VirtualNetworkNotFoundFailure, rule.VirtualNetworkID))
+			return nil
+		}
+		return fmt.Errorf("failed to list peerings: %w", azure_errors.AsAugmented(err))
+	}
+	ev.add("Virtual network %s has %d peering(s).", rule.VirtualNetworkID, len(peerings))
+
+	for _, expected := range rule.Peerings {
+		peering := findPeering(peerings, expected.RemoteVirtualNetworkID)
+		if peering == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.PeeringMissingFailure, rule.VirtualNetworkID, expected.RemoteVirtualNetworkID))
+			continue
+		}
+
+		name := strPtrValue(peering.Name)
+		props := peering.Properties
+		mismatch := func(setting, want, got string) {
+			*failures = append(*failures, fmt.Sprintf(messages.PeeringSettingFailure, setting, name, expected.RemoteVirtualNetworkID, want, got))
+		}
+
+		peeringState := notSet
+		if props.PeeringState != nil {
+			peeringState = string(*props.PeeringState)
+		}
+		if !strings.EqualFold(peeringState, string(armnetwork.VirtualNetworkPeeringStateConnected)) {
+			mismatch("peeringState", string(armnetwork.VirtualNetworkPeeringStateConnected), peeringState)
+		}
+
+		flags := []struct {
+			setting   string
+			want, got *bool
+		}{
+			{"allowVirtualNetworkAccess", expected.AllowVirtualNetworkAccess, props.AllowVirtualNetworkAccess},
+			{"allowForwardedTraffic", expected.AllowForwardedTraffic, props.AllowForwardedTraffic},
+			{"useRemoteGateways", expected.UseRemoteGateways, props.UseRemoteGateways},
+		}
+		for _, f := range flags {
+			if f.want == nil {
+				continue
+			}
+			if f.got == nil {
+				mismatch(f.setting, strconv.FormatBool(*f.want), notSet)
+			} else if *f.got != *f.want {
+				mismatch(f.setting, strconv.FormatBool(*f.want), strconv.FormatBool(*f.got))
+			}
+		}
+	}
+
+	return nil
+}
+
+// findPeering returns the peering with a remote virtual network, comparing resource IDs
+// case-insensitively like ARM does. Its properties are never nil.
+func findPeering(peerings []*armnetwork.VirtualNetworkPeering, remoteVirtualNetworkID string) *armnetwork.VirtualNetworkPeering {
+	for _, p := range peerings {
+		if p == nil || p.Properties == nil || p.Properties.RemoteVirtualNetwork == nil || p.Properties.RemoteVirtualNetwork.ID == nil {
+			continue
+		}
+		if strings.EqualFold(*p.Properties.RemoteVirtualNetwork.ID, remoteVirtualNetworkID) {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testSpokeVNetID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/spoke-rg/providers/Microsoft.Network/virtualNetworks/spoke"
+	testHubVNetID   = "/subscriptions/11111111-1111-1111-1111-111111111111/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub"
+	testOtherVNetID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/spoke-rg/providers/Microsoft.Network/virtualNetworks/other"
+)
+
+// vnetPeeringAPIMock is a fake ARM with the virtual network testSpokeVNetID, whose peerings are
+// data. Listing the peerings of any other virtual network fails with a 404, unless err is set.
+type vnetPeeringAPIMock struct {
+	data []*armnetwork.VirtualNetworkPeering
+	err  error
+}
+
+func (m vnetPeeringAPIMock) ListPeerings(virtualNetworkID string) ([]*armnetwork.VirtualNetworkPeering, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if virtualNetworkID != testSpokeVNetID {
+		return nil, &azcore.ResponseError{ErrorCode: "ResourceNotFound", StatusCode: http.StatusNotFound}
+	}
+	return m.data, nil
+}
+
+// newHubPeering returns a connected peering with the hub that allows virtual network access and
+// forwarded traffic, and uses the hub's gateways.
+func newHubPeering() *armnetwork.VirtualNetworkPeering {
+	return &armnetwork.VirtualNetworkPeering{
+		Name: util.Ptr("spoke-to-hub"),
+		Properties: &armnetwork.VirtualNetworkPeeringPropertiesFormat{
+			RemoteVirtualNetwork:      &armnetwork.SubResource{ID: util.Ptr(testHubVNetID)},
+			PeeringState:              util.Ptr(armnetwork.VirtualNetworkPeeringStateConnected),
+			AllowVirtualNetworkAccess: util.Ptr(true),
+			AllowForwardedTraffic:     util.Ptr(true),
+			UseRemoteGateways:         util.Ptr(true),
+		},
+	}
+}
+
+func TestVNetPeeringRuleService_ReconcileVNetPeeringRule(t *testing.T) {
+	mismatch := func(setting, want, got string) string {
+		return fmt.Sprintf("Expected %s of peering spoke-to-hub with %s to be %s, but it is %s.", setting, testHubVNetID, want, got)
+	}
+
+	tests := []struct {
+		name         string
+		peering      func(p *armnetwork.VirtualNetworkPeering)
+		vnetID       string
+		expected     []v1alpha1.VNetPeering
+		wantFailures []string
+	}{
+		{
+			name: "Passes when the peering is connected and has all expected settings.",
+			expected: []v1alpha1.VNetPeering{{
+				RemoteVirtualNetworkID:    testHubVNetID,
+				AllowVirtualNetworkAccess: util.Ptr(true),
+				AllowForwardedTraffic:     util.Ptr(true),
+				UseRemoteGateways:         util.Ptr(true),
+			}},
+			wantFailures: []string{},
+		},
+		{
+			name:         "Matches the remote virtual network's ID case-insensitively.",
+			expected:     []v1alpha1.VNetPeering{{RemoteVirtualNetworkID: strings.ToLower(testHubVNetID)}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when the peering is disconnected.",
+			peering: func(p *armnetwork.VirtualNetworkPeering) {
+				p.Properties.PeeringState = util.Ptr(armnetwork.VirtualNetworkPeeringStateDisconnected)
+			},
+			expected:     []v1alpha1.VNetPeering{{RemoteVirtualNetworkID: testHubVNetID}},
+			wantFailures: []string{mismatch("peeringState", "Connected", "Disconnected")},
+		},
+		{
+			name:         "Fails when virtual network access differs.",
+			peering:      func(p *armnetwork.VirtualNetworkPeering) { p.Properties.AllowVirtualNetworkAccess = util.Ptr(false) },
+			expected:     []v1alpha1.VNetPeering{{RemoteVirtualNetworkID: testHubVNetID, AllowVirtualNetworkAccess: util.Ptr(true)}},
+			wantFailures: []string{mismatch("allowVirtualNetworkAccess", "true", "false")},
+		},
+		{
+			name:         "Fails when forwarded traffic isn't allowed.",
+			peering:      func(p *armnetwork.VirtualNetworkPeering) { p.Properties.AllowForwardedTraffic = nil },
+			expected:     []v1alpha1.VNetPeering{{RemoteVirtualNetworkID: testHubVNetID, AllowForwardedTraffic: util.Ptr(true)}},
+			wantFailures: []string{mismatch("allowForwardedTraffic", "true", "(not set)")},
+		},
+		{
+			name:         "Fails when the remote gateways are used but mustn't be.",
+			expected:     []v1alpha1.VNetPeering{{RemoteVirtualNetworkID: testHubVNetID, UseRemoteGateways: util.Ptr(false)}},
+			wantFailures: []string{mismatch("useRemoteGateways", "false", "true")},
+		},
+		{
+			name:    "Ignores settings that aren't expected.",
+			peering: func(p *armnetwork.VirtualNetworkPeering) { p.Properties.UseRemoteGateways = util.Ptr(false) },
+			expected: []v1alpha1.VNetPeering{{
+				RemoteVirtualNetworkID: testHubVNetID,
+				AllowForwardedTraffic:  util.Ptr(true),
+			}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails for a missing peering separately from a misconfigured one.",
+			peering: func(p *armnetwork.VirtualNetworkPeering) {
+				p.Properties.PeeringState = util.Ptr(armnetwork.VirtualNetworkPeeringStateInitiated)
+			},
+			expected: []v1alpha1.VNetPeering{
+				{RemoteVirtualNetworkID: testOtherVNetID},
+				{RemoteVirtualNetworkID: testHubVNetID},
+			},
+			wantFailures: []string{
+				"Virtual network " + testSpokeVNetID + " has no peering with " + testOtherVNetID + ".",
+				mismatch("peeringState", "Connected", "Initiated"),
+			},
+		},
+		{
+			name:         "Fails when the virtual network doesn't exist.",
+			vnetID:       testOtherVNetID,
+			expected:     []v1alpha1.VNetPeering{{RemoteVirtualNetworkID: testHubVNetID}},
+			wantFailures: []string{"Virtual network " + testOtherVNetID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peering := newHubPeering()
+			if tt.peering != nil {
+				tt.peering(peering)
+			}
+			api := vnetPeeringAPIMock{data: []*armnetwork.VirtualNetworkPeering{peering}}
+			svc := NewVNetPeeringRuleService(logr.Discard(), api)
+
+			vnetID := testSpokeVNetID
+			if tt.vnetID != "" {
+				vnetID = tt.vnetID
+			}
+			result, err := svc.ReconcileVNetPeeringRule(v1alpha1.VNetPeeringRule{
+				Name:             "rule-1",
+				VirtualNetworkID: vnetID,
+				Peerings:         tt.expected,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestVNetPeeringRuleService_ReconcileVNetPeeringRule_Error(t *testing.T) {
+	api := vnetPeeringAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewVNetPeeringRuleService(logr.Discard(), api)
+
+	result, err := svc.ReconcileVNetPeeringRule(v1alpha1.VNetPeeringRule{Name: "rule-1", VirtualNetworkID: testSpokeVNetID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
@@ -0,0 +1,167 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// virtualNetworkGatewayAPI contains methods that allow getting a virtual network gateway by its
+// resource ID.
+type virtualNetworkGatewayAPI interface {
+	GetVirtualNetworkGateway(gatewayID string) (*armnetwork.VirtualNetworkGateway, error)
+}
+
+type VPNGatewayRuleService struct {
+	log           logr.Logger
+	gatewayAPI    virtualNetworkGatewayAPI
+	connectionAPI gatewayConnectionAPI
+}
+
+func NewVPNGatewayRuleService(log logr.Logger, gatewayAPI virtualNetworkGatewayAPI, connectionAPI gatewayConnectionAPI) *VPNGatewayRuleService {
+	return &VPNGatewayRuleService{
+		log:           log,
+		gatewayAPI:    gatewayAPI,
+		connectionAPI: connectionAPI,
+	}
+}
+
+// ReconcileVPNGatewayRule reconciles a VPN gateway rule from a validation config.
+func (s *VPNGatewayRuleService) ReconcileVPNGatewayRule(rule v1alpha1.VPNGatewayRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this VPN gateway rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.VPNGatewaySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeVPNGateway
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeVPNGateway, "gatewayID", rule.GatewayID)
+	l.V(1).Info("Validating VPN gateway")
+	ev := &evidence{}
+	if err := s.validateGateway(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate VPN gateway", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.gatewayAPI, s.connectionAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.VPNGatewayFailed
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateGateway appends a failure for each of the rule's settings that the gateway doesn't have,
+// with the gateway's actual value, and validates the rule's connections. A gateway that doesn't
+// exist is a single failure, not an error.
+func (s *VPNGatewayRuleService) validateGateway(rule v1alpha1.VPNGatewayRule, failures *[]string, ev *evidence) error {
+	gateway, err := s.gatewayAPI.GetVirtualNetworkGateway(rule.GatewayID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.VPNGatewayNotFoundFailure, rule.GatewayID))
+			return nil
+		}
+		return fmt.Errorf("failed to get virtual network gateway: %w", azure_errors.AsAugmented(err))
+	}
+
+	sku, generation, activeActive := notSet, notSet, false
+	if props := gateway.Properties; props != nil {
+		if props.SKU != nil && props.SKU.Name != nil {
+			sku = string(*props.SKU.Name)
+		}
+		if props.VPNGatewayGeneration != nil {
+			generation = string(*props.VPNGatewayGeneration)
+		}
+		activeActive = props.Active != nil && *props.Active
+	}
+	if rule.SKU != "" && !strings.EqualFold(sku, rule.SKU) {
+		*failures = append(*failures, fmt.Sprintf(messages.VPNGatewaySKUFailure, rule.GatewayID, sku, rule.SKU))
+	}
+	if rule.Generation != "" && !strings.EqualFold(generation, rule.Generation) {
+		*failures = append(*failures, fmt.Sprintf(messages.VPNGatewayGenerationFailure, rule.GatewayID, generation, rule.Generation))
+	}
+	if rule.RequireActiveActive && !activeActive {
+		*failures = append(*failures, fmt.Sprintf(messages.VPNGatewayActiveActiveFailure, rule.GatewayID))
+	}
+	ev.add("Virtual network gateway %s has SKU %s and is %s. Active-active: %t.", rule.GatewayID, sku, generation, activeActive)
+
+	if len(rule.Connections) == 0 {
+		return nil
+	}
+	id, err := arm.ParseResourceID(rule.GatewayID)
+	if err != nil {
+		return fmt.Errorf("failed to parse virtual network gateway ID %s: %w", rule.GatewayID, err)
+	}
+	for _, name := range rule.Connections {
+		connectionID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/connections/%s", id.SubscriptionID, id.ResourceGroupName, name)
+		if err := s.validateConnection(rule, connectionID, failures, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateConnection appends a failure if a connection isn't of the rule's gateway, one if it isn't
+// Connected, and, if the rule requires traffic, one if it hasn't transferred bytes both ways. A
+// connection that doesn't exist is a failure, not an error.
+func (s *VPNGatewayRuleService) validateConnection(rule v1alpha1.VPNGatewayRule, connectionID string, failures *[]string, ev *evidence) error {
+	connection, err := s.connectionAPI.GetVirtualNetworkGatewayConnection(connectionID)
+	if err != nil {
+		var rerr *azcore.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusNotFound {
+			*failures = append(*failures, fmt.Sprintf(messages.GatewayConnectionNotFoundFailure, connectionID))
+			return nil
+		}
+		return fmt.Errorf("failed to get virtual network gateway connection: %w", azure_errors.AsAugmented(err))
+	}
+
+	gatewayID, status := notSet, notSet
+	var ingress, egress int64
+	if props := connection.Properties; props != nil {
+		if props.VirtualNetworkGateway1 != nil {
+			gatewayID = strPtrValue(props.VirtualNetworkGateway1.ID)
+		}
+		if props.ConnectionStatus != nil {
+			status = string(*props.ConnectionStatus)
+		}
+		if props.IngressBytesTransferred != nil {
+			ingress = *props.IngressBytesTransferred
+		}
+		if props.EgressBytesTransferred != nil {
+			egress = *props.EgressBytesTransferred
+		}
+	}
+	if !strings.EqualFold(gatewayID, rule.GatewayID) {
+		*failures = append(*failures, fmt.Sprintf(messages.VPNConnectionGatewayFailure, connectionID, gatewayID, rule.GatewayID))
+	}
+	if status != string(armnetwork.VirtualNetworkGatewayConnectionStatusConnected) {
+		*failures = append(*failures, fmt.Sprintf(messages.GatewayConnectionStatusFailure, connectionID, status))
+	}
+	if rule.RequireTraffic && (ingress == 0 || egress == 0) {
+		*failures = append(*failures, fmt.Sprintf(messages.VPNConnectionTrafficFailure, connectionID, ingress, egress))
+	}
+	ev.add("Virtual network gateway connection %s has connection status %s, and has transferred %d ingress and %d egress bytes.", connectionID, status, ingress, egress)
+	return nil
+}
@@ -0,0 +1,207 @@
+package validators
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	testVPNGatewayID     = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworkGateways/gateway-1"
+	testVPNConnectionID1 = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/connections/site-1"
+	testVPNConnectionID2 = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/connections/site-2"
+)
+
+// vpnGatewayAPIMock is a fake ARM with the virtual network gateways and connections in its maps,
+// keyed by resource ID. Getting any other gateway or connection fails with a 404, unless err is
+// set.
+type vpnGatewayAPIMock struct {
+	gateways    map[string]*armnetwork.VirtualNetworkGateway
+	connections map[string]*armnetwork.VirtualNetworkGatewayConnection
+	err         error
+}
+
+func (m vpnGatewayAPIMock) GetVirtualNetworkGateway(gatewayID string) (*armnetwork.VirtualNetworkGateway, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.gateways, gatewayID)
+}
+
+func (m vpnGatewayAPIMock) GetVirtualNetworkGatewayConnection(connectionID string) (*armnetwork.VirtualNetworkGatewayConnection, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return getOrNotFound(m.connections, connectionID)
+}
+
+// newVPNGateway returns an active-active VpnGw2AZ Generation2 gateway.
+func newVPNGateway() *armnetwork.VirtualNetworkGateway {
+	return &armnetwork.VirtualNetworkGateway{
+		Properties: &armnetwork.VirtualNetworkGatewayPropertiesFormat{
+			GatewayType:          util.Ptr(armnetwork.VirtualNetworkGatewayTypeVPN),
+			SKU:                  &armnetwork.VirtualNetworkGatewaySKU{Name: util.Ptr(armnetwork.VirtualNetworkGatewaySKUNameVPNGw2AZ)},
+			VPNGatewayGeneration: util.Ptr(armnetwork.VPNGatewayGenerationGeneration2),
+			Active:               util.Ptr(true),
+		},
+	}
+}
+
+// newVPNConnection returns a site-to-site connection of the test gateway with a connection status
+// and transferred bytes.
+func newVPNConnection(status armnetwork.VirtualNetworkGatewayConnectionStatus, ingress, egress int64) *armnetwork.VirtualNetworkGatewayConnection {
+	return &armnetwork.VirtualNetworkGatewayConnection{
+		Properties: &armnetwork.VirtualNetworkGatewayConnectionPropertiesFormat{
+			ConnectionType:          util.Ptr(armnetwork.VirtualNetworkGatewayConnectionTypeIPsec),
+			VirtualNetworkGateway1:  &armnetwork.VirtualNetworkGateway{ID: util.Ptr(testVPNGatewayID)},
+			ConnectionStatus:        util.Ptr(status),
+			IngressBytesTransferred: util.Ptr(ingress),
+			EgressBytesTransferred:  util.Ptr(egress),
+		},
+	}
+}
+
+func TestVPNGatewayRuleService_ReconcileVPNGatewayRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		gateway      *armnetwork.VirtualNetworkGateway
+		connections  map[string]*armnetwork.VirtualNetworkGatewayConnection
+		rule         v1alpha1.VPNGatewayRule
+		wantFailures []string
+	}{
+		{
+			name:    "Passes when the gateway is configured as expected and its connections are connected.",
+			gateway: newVPNGateway(),
+			connections: map[string]*armnetwork.VirtualNetworkGatewayConnection{
+				testVPNConnectionID1: newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnected, 100, 200),
+				testVPNConnectionID2: newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnected, 1, 1),
+			},
+			rule: v1alpha1.VPNGatewayRule{
+				SKU:                 "vpngw2az",
+				Generation:          "Generation2",
+				RequireActiveActive: true,
+				Connections:         []string{"site-1", "site-2"},
+				RequireTraffic:      true,
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:    "Passes when a connection hasn't transferred bytes and traffic isn't required.",
+			gateway: newVPNGateway(),
+			connections: map[string]*armnetwork.VirtualNetworkGatewayConnection{
+				testVPNConnectionID1: newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnected, 0, 0),
+			},
+			rule:         v1alpha1.VPNGatewayRule{Connections: []string{"site-1"}},
+			wantFailures: []string{},
+		},
+		{
+			name: "Fails when the gateway has another SKU and generation, and isn't active-active.",
+			gateway: func() *armnetwork.VirtualNetworkGateway {
+				g := newVPNGateway()
+				g.Properties.SKU.Name = util.Ptr(armnetwork.VirtualNetworkGatewaySKUNameVPNGw1)
+				g.Properties.VPNGatewayGeneration = util.Ptr(armnetwork.VPNGatewayGenerationGeneration1)
+				g.Properties.Active = util.Ptr(false)
+				return g
+			}(),
+			rule: v1alpha1.VPNGatewayRule{SKU: "VpnGw2AZ", Generation: "Generation2", RequireActiveActive: true},
+			wantFailures: []string{
+				"Virtual network gateway " + testVPNGatewayID + " has SKU VpnGw1, not VpnGw2AZ.",
+				"Virtual network gateway " + testVPNGatewayID + " is Generation1, not Generation2.",
+				"Virtual network gateway " + testVPNGatewayID + " isn't active-active.",
+			},
+		},
+		{
+			name:    "Fails when the gateway exists but a connection is connecting.",
+			gateway: newVPNGateway(),
+			connections: map[string]*armnetwork.VirtualNetworkGatewayConnection{
+				testVPNConnectionID1: newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnected, 100, 200),
+				testVPNConnectionID2: newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnecting, 0, 0),
+			},
+			rule: v1alpha1.VPNGatewayRule{Connections: []string{"site-1", "site-2"}},
+			wantFailures: []string{
+				"Virtual network gateway connection " + testVPNConnectionID2 + " has connection status Connecting, not Connected.",
+			},
+		},
+		{
+			name:    "Fails when a connection hasn't transferred bytes both ways and traffic is required.",
+			gateway: newVPNGateway(),
+			connections: map[string]*armnetwork.VirtualNetworkGatewayConnection{
+				testVPNConnectionID1: newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnected, 100, 0),
+			},
+			rule: v1alpha1.VPNGatewayRule{Connections: []string{"site-1"}, RequireTraffic: true},
+			wantFailures: []string{
+				"Virtual network gateway connection " + testVPNConnectionID1 + " has transferred 100 ingress and 0 egress bytes; both must be above zero.",
+			},
+		},
+		{
+			name:    "Fails when a connection is of another gateway, or missing.",
+			gateway: newVPNGateway(),
+			connections: map[string]*armnetwork.VirtualNetworkGatewayConnection{
+				testVPNConnectionID1: func() *armnetwork.VirtualNetworkGatewayConnection {
+					c := newVPNConnection(armnetwork.VirtualNetworkGatewayConnectionStatusConnected, 1, 1)
+					c.Properties.VirtualNetworkGateway1.ID = util.Ptr("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworkGateways/gateway-2")
+					return c
+				}(),
+			},
+			rule: v1alpha1.VPNGatewayRule{Connections: []string{"site-1", "site-2"}},
+			wantFailures: []string{
+				"Virtual network gateway connection " + testVPNConnectionID1 + " is of /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworkGateways/gateway-2, not virtual network gateway " + testVPNGatewayID + ".",
+				"Virtual network gateway connection " + testVPNConnectionID2 + " not found.",
+			},
+		},
+		{
+			name:         "Fails once when the gateway is missing.",
+			rule:         v1alpha1.VPNGatewayRule{SKU: "VpnGw2AZ", Connections: []string{"site-1"}},
+			wantFailures: []string{"Virtual network gateway " + testVPNGatewayID + " not found."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := vpnGatewayAPIMock{gateways: map[string]*armnetwork.VirtualNetworkGateway{}, connections: tt.connections}
+			if tt.gateway != nil {
+				api.gateways[testVPNGatewayID] = tt.gateway
+			}
+			svc := NewVPNGatewayRuleService(logr.Discard(), api, api)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.GatewayID = testVPNGatewayID
+			result, err := svc.ReconcileVPNGatewayRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestVPNGatewayRuleService_ReconcileVPNGatewayRule_Error(t *testing.T) {
+	api := vpnGatewayAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewVPNGatewayRuleService(logr.Discard(), api, api)
+
+	result, err := svc.ReconcileVPNGatewayRule(v1alpha1.VPNGatewayRule{Name: "rule-1", GatewayID: testVPNGatewayID})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
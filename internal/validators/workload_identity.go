@@ -0,0 +1,167 @@
+package validators
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// noMatchingFederatedCredentialCode prefixes the Microsoft Entra ID error codes of token exchanges
+// that no federated identity credential matches: AADSTS70021 in general, and AADSTS700211,
+// AADSTS700212, and AADSTS700213 for the issuer, audience, and subject in particular.
+const noMatchingFederatedCredentialCode = "AADSTS70021"
+
+// federatedCredentialAPI contains methods that allow getting the federated identity credentials of
+// an application.
+type federatedCredentialAPI interface {
+	ListFederatedIdentityCredentials(appID string) ([]*azure_utils.GraphFederatedIdentityCredential, error)
+}
+
+type WorkloadIdentityService struct {
+	log      logr.Logger
+	fcAPI    federatedCredentialAPI
+	identity azure_utils.WorkloadIdentity
+}
+
+// NewWorkloadIdentityService creates a WorkloadIdentityService for the plugin's own workload
+// identity.
+func NewWorkloadIdentityService(log logr.Logger, fcAPI federatedCredentialAPI, identity azure_utils.WorkloadIdentity) *WorkloadIdentityService {
+	return &WorkloadIdentityService{
+		log:      log,
+		fcAPI:    fcAPI,
+		identity: identity,
+	}
+}
+
+// ReconcileWorkloadIdentity checks that the issuer, subject, and audience of the plugin's projected
+// service account token match a federated identity credential of its workload identity's app
+// registration, so that misconfigured credentials are reported with the values to fix, rather
+// than with the opaque AADSTS70021 errors of the rules. The token is read, but never logged or
+// reported; only its claims are.
+func (s *WorkloadIdentityService) ReconcileWorkloadIdentity() (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for the workload identity check.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = messages.WorkloadIdentitySucceeded
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, constants.WorkloadIdentityRuleName)
+	latestCondition.ValidationType = constants.ValidationTypeWorkloadIdentity
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("validationType", constants.ValidationTypeWorkloadIdentity, "clientID", s.identity.ClientID)
+	l.V(1).Info("Checking plugin's workload identity")
+	ev := &evidence{}
+	verified, err := s.checkWorkloadIdentity(&latestCondition.Failures, ev)
+	if err != nil {
+		return validationResult, recordError(l, "failed to check plugin's workload identity", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.fcAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	switch {
+	case len(latestCondition.Failures) > 0:
+		state = vapi.ValidationFailed
+		latestCondition.Message = messages.WorkloadIdentityFailed
+		latestCondition.Status = corev1.ConditionFalse
+	case !verified:
+		latestCondition.Message = messages.WorkloadIdentityUnverified
+	}
+
+	return validationResult, nil
+}
+
+// checkWorkloadIdentity appends a failure if the plugin's pod isn't configured for workload
+// identity, or if its service account token matches none of the federated identity credentials of
+// its client ID. Returns false if the credentials couldn't be read, e.g. because the client ID is
+// a managed identity's, so the token wasn't compared with them.
+func (s *WorkloadIdentityService) checkWorkloadIdentity(failures *[]string, ev *evidence) (bool, error) {
+	if s.identity.TokenFile == "" {
+		*failures = append(*failures, messages.FederatedTokenFileUnsetFailure)
+		return true, nil
+	}
+	if s.identity.ClientID == "" {
+		*failures = append(*failures, messages.ClientIDUnsetFailure)
+		return true, nil
+	}
+
+	token, err := os.ReadFile(s.identity.TokenFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	claims, err := azure_utils.ParseServiceAccountToken(string(token))
+	if err != nil {
+		return false, err
+	}
+	ev.add("Service account token %s has issuer %q, subject %q, and audience(s) %s.", s.identity.TokenFile, claims.Issuer, claims.Subject, quoteAll(claims.Audiences))
+	wanted := fmt.Sprintf(messages.FederatedCredentialClaims, claims.Issuer, claims.Subject, quoteAll(claims.Audiences))
+
+	credentials, err := s.fcAPI.ListFederatedIdentityCredentials(s.identity.ClientID)
+	switch {
+	case err != nil && strings.Contains(err.Error(), noMatchingFederatedCredentialCode):
+		// The credentials can't be read because the plugin can't authenticate, since Microsoft
+		// Entra ID found no credential matching the token.
+		*failures = append(*failures, fmt.Sprintf(messages.FederatedCredentialNoMatchFailure, s.identity.ClientID, noMatchingFederatedCredentialCode, wanted))
+		return true, nil
+	case err != nil:
+		ev.add("Federated identity credentials of client ID %s couldn't be read, so they weren't compared with the service account token: %v", s.identity.ClientID, err)
+		return false, nil
+	case len(credentials) == 0:
+		*failures = append(*failures, fmt.Sprintf(messages.FederatedCredentialsMissingFailure, s.identity.ClientID, wanted))
+		return true, nil
+	}
+
+	var mismatches []string
+	for _, c := range credentials {
+		m := federatedCredentialMismatches(c, claims)
+		if len(m) == 0 {
+			ev.add("Federated identity credential %s of client ID %s matches the service account token.", c.Name, s.identity.ClientID)
+			return true, nil
+		}
+		mismatches = append(mismatches, m...)
+	}
+	*failures = append(*failures, mismatches...)
+	return true, nil
+}
+
+// federatedCredentialMismatches returns a failure for each claim of a service account token that a
+// federated identity credential doesn't match. Microsoft Entra ID compares issuers and subjects
+// exactly, including case and trailing slashes, and requires one of the token's audiences to be
+// one of the credential's.
+func federatedCredentialMismatches(c *azure_utils.GraphFederatedIdentityCredential, claims *azure_utils.ServiceAccountTokenClaims) []string {
+	var mismatches []string
+	if c.Issuer != claims.Issuer {
+		mismatches = append(mismatches, fmt.Sprintf(messages.FederatedCredentialIssuerFailure, c.Name, c.Issuer, claims.Issuer))
+	}
+	if c.Subject != claims.Subject {
+		mismatches = append(mismatches, fmt.Sprintf(messages.FederatedCredentialSubjectFailure, c.Name, c.Subject, claims.Subject))
+	}
+	if !slices.ContainsFunc(claims.Audiences, func(aud string) bool { return slices.Contains(c.Audiences, aud) }) {
+		mismatches = append(mismatches, fmt.Sprintf(messages.FederatedCredentialAudienceFailure, c.Name, quoteAll(c.Audiences), quoteAll(claims.Audiences)))
+	}
+	return mismatches
+}
+
+// quoteAll quotes each of a list of values and joins them, or returns "(none)" if there are none.
+func quoteAll(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(quoted, ", ")
+}
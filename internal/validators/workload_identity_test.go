@@ -0,0 +1,155 @@
+package validators
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	azure_utils "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+const (
+	testWorkloadClientID = "88888888-8888-8888-8888-888888888888"
+	testIssuer           = "https://oidc.prod-aks.azure.com/t_id/"
+	testSubject          = "system:serviceaccount:validator:validator-plugin-azure"
+	testAudience         = "api://AzureADTokenExchange"
+)
+
+// federatedCredentialAPIMock is a fake Microsoft Graph with the federated identity credentials of
+// an application. Listing them fails with err, if it's set.
+type federatedCredentialAPIMock struct {
+	credentials []*azure_utils.GraphFederatedIdentityCredential
+	err         error
+}
+
+func (m federatedCredentialAPIMock) ListFederatedIdentityCredentials(appID string) ([]*azure_utils.GraphFederatedIdentityCredential, error) {
+	return m.credentials, m.err
+}
+
+// serviceAccountTokenFile writes an unsigned service account token with the test issuer, subject,
+// and audience to a file, and returns its path and the token.
+func serviceAccountTokenFile(t *testing.T) (string, string) {
+	claims := `{"iss": "` + testIssuer + `", "sub": "` + testSubject + `", "aud": ["` + testAudience + `"]}`
+	token := "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+	path := filepath.Join(t.TempDir(), "azure-identity-token")
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, token
+}
+
+func TestWorkloadIdentityService_ReconcileWorkloadIdentity(t *testing.T) {
+	tokenFile, token := serviceAccountTokenFile(t)
+	identity := azure_utils.WorkloadIdentity{ClientID: testWorkloadClientID, TenantID: "t_id", TokenFile: tokenFile}
+	matching := &azure_utils.GraphFederatedIdentityCredential{Name: "validator", Issuer: testIssuer, Subject: testSubject, Audiences: []string{testAudience}}
+	wanted := `issuer "` + testIssuer + `", subject "` + testSubject + `", and audience "` + testAudience + `"`
+
+	tests := []struct {
+		name         string
+		identity     azure_utils.WorkloadIdentity
+		api          federatedCredentialAPIMock
+		wantMessage  string
+		wantFailures []string
+	}{
+		{
+			name:         "Passes when a federated identity credential matches the token.",
+			identity:     identity,
+			api:          federatedCredentialAPIMock{credentials: []*azure_utils.GraphFederatedIdentityCredential{{Name: "other", Issuer: "https://other/"}, matching}},
+			wantMessage:  messages.WorkloadIdentitySucceeded,
+			wantFailures: []string{},
+		},
+		{
+			name:     "Fails for each claim that the federated identity credentials don't match.",
+			identity: identity,
+			api: federatedCredentialAPIMock{credentials: []*azure_utils.GraphFederatedIdentityCredential{
+				{Name: "validator", Issuer: strings.TrimSuffix(testIssuer, "/"), Subject: "system:serviceaccount:validator:default", Audiences: []string{"api://other"}},
+			}},
+			wantMessage: messages.WorkloadIdentityFailed,
+			wantFailures: []string{
+				`Federated identity credential validator expects issuer "https://oidc.prod-aks.azure.com/t_id", but the service account token's issuer is "` + testIssuer + `".`,
+				`Federated identity credential validator expects subject "system:serviceaccount:validator:default", but the service account token's subject is "` + testSubject + `".`,
+				`Federated identity credential validator expects audience "api://other", but the service account token's audience is "` + testAudience + `".`,
+			},
+		},
+		{
+			name:         "Fails when the client ID has no federated identity credentials.",
+			identity:     identity,
+			api:          federatedCredentialAPIMock{},
+			wantMessage:  messages.WorkloadIdentityFailed,
+			wantFailures: []string{"Client ID " + testWorkloadClientID + " has no federated identity credentials. Add one with " + wanted + "."},
+		},
+		{
+			name:        "Fails when Microsoft Entra ID found no matching federated identity credential.",
+			identity:    identity,
+			api:         federatedCredentialAPIMock{err: errors.New("AADSTS700213: No matching federated identity record found for presented assertion subject")},
+			wantMessage: messages.WorkloadIdentityFailed,
+			wantFailures: []string{
+				"Microsoft Entra ID found no federated identity credential of client ID " + testWorkloadClientID + " that matches the plugin's service account token (AADSTS70021). Add one with " + wanted + ".",
+			},
+		},
+		{
+			name:         "Passes unverified when the federated identity credentials can't be read.",
+			identity:     identity,
+			api:          federatedCredentialAPIMock{err: errors.New("Request_ResourceNotFound")},
+			wantMessage:  messages.WorkloadIdentityUnverified,
+			wantFailures: []string{},
+		},
+		{
+			name:         "Fails when the pod has no projected service account token.",
+			identity:     azure_utils.WorkloadIdentity{ClientID: testWorkloadClientID},
+			wantMessage:  messages.WorkloadIdentityFailed,
+			wantFailures: []string{"AZURE_FEDERATED_TOKEN_FILE isn't set in the plugin's pod, so it doesn't use workload identity."},
+		},
+		{
+			name:         "Fails when the pod has no client ID.",
+			identity:     azure_utils.WorkloadIdentity{TokenFile: tokenFile},
+			wantMessage:  messages.WorkloadIdentityFailed,
+			wantFailures: []string{"AZURE_CLIENT_ID isn't set in the plugin's pod, so its service account token can't be exchanged for an access token."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewWorkloadIdentityService(logr.Discard(), tt.api, tt.identity)
+
+			result, err := svc.ReconcileWorkloadIdentity()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %q, want %q", result.Condition.Failures, tt.wantFailures)
+			}
+			if result.Condition.Message != tt.wantMessage {
+				t.Errorf("got message %q, want %q", result.Condition.Message, tt.wantMessage)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+			for _, s := range append(result.Condition.Details, result.Condition.Failures...) {
+				if strings.Contains(s, token) {
+					t.Errorf("got the service account token in %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkloadIdentityService_ReconcileWorkloadIdentity_Error(t *testing.T) {
+	identity := azure_utils.WorkloadIdentity{ClientID: testWorkloadClientID, TokenFile: filepath.Join(t.TempDir(), "missing")}
+	svc := NewWorkloadIdentityService(logr.Discard(), federatedCredentialAPIMock{}, identity)
+
+	if _, err := svc.ReconcileWorkloadIdentity(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want an error for the missing token file", err)
+	}
+}
@@ -0,0 +1,170 @@
+package validators
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/constants"
+	azure_errors "github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/azure-errors"
+	"github.com/spectrocloud-labs/validator-plugin-azure/internal/utils/messages"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// Services that zone redundancy rules check, and the minimum number of zones that compute must be
+// offered in if a rule doesn't set one.
+const (
+	serviceStandardLoadBalancer = "StandardLoadBalancer"
+	serviceZoneRedundantStorage = "ZoneRedundantStorage"
+	defaultMinZones             = 3
+)
+
+// storageSKUAPI contains methods that allow getting the storage account SKUs available to a
+// subscription.
+type storageSKUAPI interface {
+	ListStorageSKUs() ([]*armstorage.SKUInformation, error)
+}
+
+type ZoneRedundancyRuleService struct {
+	log           logr.Logger
+	skuAPI        resourceSKUAPI
+	storageSKUAPI storageSKUAPI
+}
+
+func NewZoneRedundancyRuleService(log logr.Logger, skuAPI resourceSKUAPI, storageSKUAPI storageSKUAPI) *ZoneRedundancyRuleService {
+	return &ZoneRedundancyRuleService{
+		log:           log,
+		skuAPI:        skuAPI,
+		storageSKUAPI: storageSKUAPI,
+	}
+}
+
+// ReconcileZoneRedundancyRule reconciles a zone redundancy rule from a validation config.
+func (s *ZoneRedundancyRuleService) ReconcileZoneRedundancyRule(rule v1alpha1.ZoneRedundancyRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this zone redundancy rule.
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = []string{}
+	latestCondition.Message = fmt.Sprintf(messages.ZoneRedundancySucceeded, rule.Location)
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeZoneRedundancy
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	l := s.log.WithValues("ruleName", rule.Name, "validationType", constants.ValidationTypeZoneRedundancy, "location", rule.Location)
+	l.V(1).Info("Validating availability zone support")
+	ev := &evidence{}
+	if err := s.validateZones(rule, &latestCondition.Failures, ev); err != nil {
+		return validationResult, recordError(l, "failed to validate availability zone support", err, &latestCondition)
+	}
+
+	ev.addRequestIDs(s.skuAPI, s.storageSKUAPI)
+	latestCondition.Details = append(latestCondition.Details, ev.conditionDetails()...)
+
+	if len(latestCondition.Failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Message = fmt.Sprintf(messages.ZoneRedundancyFailed, rule.Location)
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// validateZones appends a failure, stating the actual number of zones, if compute or one of the
+// rule's VM sizes is offered in fewer than the rule's minimum number of zones of its location. It
+// then checks each of the rule's services.
+func (s *ZoneRedundancyRuleService) validateZones(rule v1alpha1.ZoneRedundancyRule, failures *[]string, ev *evidence) error {
+	minZones := int(rule.MinZones)
+	if minZones == 0 {
+		minZones = defaultMinZones
+	}
+	vmSKUs, err := s.skuAPI.ListVirtualMachineSKUs(rule.Location)
+	if err != nil {
+		return fmt.Errorf("failed to list VM sizes: %w", azure_errors.AsAugmented(err))
+	}
+
+	// The location's zones are those that any VM size is offered in.
+	var zones []string
+	for _, sku := range vmSKUs {
+		zones = append(zones, offeredZones(sku, rule.Location)...)
+	}
+	slices.Sort(zones)
+	zones = slices.Compact(zones)
+	ev.add("Location %s offers %d VM sizes in availability zones %s.", rule.Location, len(vmSKUs), joinZones(zones))
+	if len(zones) < minZones {
+		*failures = append(*failures, fmt.Sprintf(messages.ComputeZonesFailure, rule.Location, len(zones), joinZones(zones), minZones))
+	}
+
+	for _, size := range rule.VMSizes {
+		sku := findSKU(vmSKUs, size)
+		if sku == nil {
+			*failures = append(*failures, fmt.Sprintf(messages.VMSizeUnavailableFailure, size, rule.Location))
+			continue
+		}
+		sizeZones := offeredZones(sku, rule.Location)
+		if len(sizeZones) < minZones {
+			*failures = append(*failures, fmt.Sprintf(messages.VMSizeZonesFailure, size, len(sizeZones), rule.Location, joinZones(sizeZones), minZones))
+			continue
+		}
+		ev.add("VM size %s is offered in availability zones %s of location %s.", size, joinZones(sizeZones), rule.Location)
+	}
+
+	for _, service := range rule.Services {
+		switch service {
+		case serviceStandardLoadBalancer:
+			// Standard load balancers' frontends can be zone-redundant in every location with
+			// availability zones, so there's no SKU to look up.
+			if len(zones) < 2 {
+				*failures = append(*failures, fmt.Sprintf(messages.LoadBalancerZonesFailure, rule.Location, len(zones)))
+				continue
+			}
+			ev.add("Standard load balancers can have frontends that are zone-redundant across availability zones %s of location %s.", joinZones(zones), rule.Location)
+		case serviceZoneRedundantStorage:
+			if err := s.validateZoneRedundantStorage(rule.Location, failures, ev); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateZoneRedundantStorage appends a failure if storage accounts can't have the Standard_ZRS
+// SKU in a location, with the reason it's restricted, if it is.
+func (s *ZoneRedundancyRuleService) validateZoneRedundantStorage(location string, failures *[]string, ev *evidence) error {
+	skus, err := s.storageSKUAPI.ListStorageSKUs()
+	if err != nil {
+		return fmt.Errorf("failed to list storage SKUs: %w", azure_errors.AsAugmented(err))
+	}
+	inLocation := func(locations []*string) bool {
+		return slices.ContainsFunc(derefAll(locations), func(l string) bool { return normalizeRegion(l) == normalizeRegion(location) })
+	}
+	for _, sku := range skus {
+		if sku == nil || sku.Name == nil || *sku.Name != armstorage.SKUNameStandardZRS || !inLocation(sku.Locations) {
+			continue
+		}
+		if sku.ResourceType != nil && !strings.EqualFold(*sku.ResourceType, "storageAccounts") {
+			continue
+		}
+		for _, r := range sku.Restrictions {
+			if r != nil && inLocation(r.Values) {
+				reason := "unknown"
+				if r.ReasonCode != nil {
+					reason = string(*r.ReasonCode)
+				}
+				*failures = append(*failures, fmt.Sprintf(messages.StorageSKURestrictedFailure, armstorage.SKUNameStandardZRS, location, reason))
+				return nil
+			}
+		}
+		ev.add("Storage SKU %s is available in location %s.", armstorage.SKUNameStandardZRS, location)
+		return nil
+	}
+	*failures = append(*failures, fmt.Sprintf(messages.StorageSKUUnavailableFailure, armstorage.SKUNameStandardZRS, location))
+	return nil
+}
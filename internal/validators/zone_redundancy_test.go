@@ -0,0 +1,182 @@
+package validators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+)
+
+// zonalVMSKUs are VM sizes as the Resource SKUs API returns them in a location with availability
+// zones. Standard_D4s_v5 is offered in all three zones, and Standard_M128s is restricted in zone 3
+// for the subscription and not offered in zone 2.
+const zonalVMSKUs = `[
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D4s_v5",
+		"locations": ["eastus"],
+		"locationInfo": [{"location": "eastus", "zones": ["1", "2", "3"]}],
+		"restrictions": []
+	},
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_M128s",
+		"locations": ["eastus"],
+		"locationInfo": [{"location": "eastus", "zones": ["1", "3"]}],
+		"restrictions": [{
+			"type": "Zone",
+			"values": ["eastus"],
+			"restrictionInfo": {"locations": ["eastus"], "zones": ["3"]},
+			"reasonCode": "NotAvailableForSubscription"
+		}]
+	}
+]`
+
+// zonelessVMSKUs are VM sizes as the Resource SKUs API returns them in a location without
+// availability zones.
+const zonelessVMSKUs = `[
+	{
+		"resourceType": "virtualMachines",
+		"name": "Standard_D4s_v5",
+		"locations": ["eastus"],
+		"locationInfo": [{"location": "eastus", "zones": []}],
+		"restrictions": []
+	}
+]`
+
+// storageSKUAPIMock is a fake ARM with the storage SKUs in its list, unless err is set.
+type storageSKUAPIMock struct {
+	skus []*armstorage.SKUInformation
+	err  error
+}
+
+func (m storageSKUAPIMock) ListStorageSKUs() ([]*armstorage.SKUInformation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.skus, nil
+}
+
+func parseStorageSKUs(t *testing.T, payload string) []*armstorage.SKUInformation {
+	t.Helper()
+	var skus []*armstorage.SKUInformation
+	if err := json.Unmarshal([]byte(payload), &skus); err != nil {
+		t.Fatalf("failed to parse storage SKUs: %v", err)
+	}
+	return skus
+}
+
+func TestZoneRedundancyRuleService_ReconcileZoneRedundancyRule(t *testing.T) {
+	zrs := storageSKUAPIMock{skus: parseStorageSKUs(t, `[
+		{"name": "Standard_LRS", "resourceType": "storageAccounts", "locations": ["eastus"], "restrictions": []},
+		{"name": "Standard_ZRS", "resourceType": "storageAccounts", "locations": ["eastus"], "restrictions": []}
+	]`)}
+	restrictedZRS := storageSKUAPIMock{skus: parseStorageSKUs(t, `[
+		{"name": "Standard_ZRS", "resourceType": "storageAccounts", "locations": ["eastus"], "restrictions": [
+			{"type": "Location", "values": ["eastus"], "reasonCode": "NotAvailableForSubscription"}
+		]}
+	]`)}
+	noZRS := storageSKUAPIMock{skus: parseStorageSKUs(t, `[
+		{"name": "Standard_LRS", "resourceType": "storageAccounts", "locations": ["eastus"], "restrictions": []}
+	]`)}
+
+	tests := []struct {
+		name         string
+		vmSKUs       string
+		storageSKUs  storageSKUAPIMock
+		rule         v1alpha1.ZoneRedundancyRule
+		wantFailures []string
+	}{
+		{
+			name:        "Passes when compute, the VM sizes, and the services are zonal.",
+			vmSKUs:      zonalVMSKUs,
+			storageSKUs: zrs,
+			rule: v1alpha1.ZoneRedundancyRule{
+				VMSizes:  []string{"Standard_D4s_v5"},
+				Services: []v1alpha1.ZoneRedundantService{"StandardLoadBalancer", "ZoneRedundantStorage"},
+			},
+			wantFailures: []string{},
+		},
+		{
+			name:   "Fails with the actual zone count for VM sizes offered in too few zones.",
+			vmSKUs: zonalVMSKUs,
+			rule:   v1alpha1.ZoneRedundancyRule{VMSizes: []string{"Standard_M128s", "Standard_NC6"}},
+			wantFailures: []string{
+				"VM size Standard_M128s is offered in 1 availability zones of location eastus (1), fewer than 3.",
+				"VM size Standard_NC6 is not available in location eastus.",
+			},
+		},
+		{
+			name:         "Passes for VM sizes offered in the minimum number of zones.",
+			vmSKUs:       zonalVMSKUs,
+			rule:         v1alpha1.ZoneRedundancyRule{MinZones: 1, VMSizes: []string{"Standard_M128s"}},
+			wantFailures: []string{},
+		},
+		{
+			name:        "Fails for a location without availability zones.",
+			vmSKUs:      zonelessVMSKUs,
+			storageSKUs: noZRS,
+			rule:        v1alpha1.ZoneRedundancyRule{VMSizes: []string{"Standard_D4s_v5"}, Services: []v1alpha1.ZoneRedundantService{"StandardLoadBalancer", "ZoneRedundantStorage"}},
+			wantFailures: []string{
+				"Location eastus offers compute in 0 availability zones (none), fewer than 3.",
+				"VM size Standard_D4s_v5 is offered in 0 availability zones of location eastus (none), fewer than 3.",
+				"Standard load balancers can't have zone-redundant frontends in location eastus, which has 0 availability zones.",
+				"Storage SKU Standard_ZRS is not available in location eastus, so storage accounts can't be zone-redundant there.",
+			},
+		},
+		{
+			name:        "Fails when zone-redundant storage is restricted for the subscription.",
+			vmSKUs:      zonalVMSKUs,
+			storageSKUs: restrictedZRS,
+			rule:        v1alpha1.ZoneRedundancyRule{Services: []v1alpha1.ZoneRedundantService{"ZoneRedundantStorage"}},
+			wantFailures: []string{
+				"Storage SKU Standard_ZRS is restricted for the subscription in location eastus: reason NotAvailableForSubscription.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewZoneRedundancyRuleService(logr.Discard(), resourceSKUAPIMock{data: parseSKUs(t, tt.vmSKUs)}, tt.storageSKUs)
+
+			tt.rule.Name = "rule-1"
+			tt.rule.SubscriptionID = testSubscriptionID
+			tt.rule.Location = "eastus"
+			result, err := svc.ReconcileZoneRedundancyRule(tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Condition.Failures, tt.wantFailures) {
+				t.Errorf("got failures %v, want %v", result.Condition.Failures, tt.wantFailures)
+			}
+			wantState := vapi.ValidationSucceeded
+			if len(tt.wantFailures) > 0 {
+				wantState = vapi.ValidationFailed
+			}
+			if *result.State != wantState {
+				t.Errorf("got state %s, want %s", *result.State, wantState)
+			}
+		})
+	}
+}
+
+func TestZoneRedundancyRuleService_ReconcileZoneRedundancyRule_Error(t *testing.T) {
+	skuAPI := resourceSKUAPIMock{err: &azcore.ResponseError{ErrorCode: "AuthorizationFailed", StatusCode: http.StatusForbidden}}
+	svc := NewZoneRedundancyRuleService(logr.Discard(), skuAPI, storageSKUAPIMock{})
+
+	result, err := svc.ReconcileZoneRedundancyRule(v1alpha1.ZoneRedundancyRule{Name: "rule-1", SubscriptionID: testSubscriptionID, Location: "eastus"})
+	var rerr *azcore.ResponseError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected the Azure error, got %v", err)
+	}
+	if want := []string{"Error category: Forbidden"}; !reflect.DeepEqual(result.Condition.Details, want) {
+		t.Errorf("got details %v, want %v", result.Condition.Details, want)
+	}
+}
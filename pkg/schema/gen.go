@@ -0,0 +1,27 @@
+//go:build ignore
+
+// gen writes the schema of v1alpha1 AzureValidators, from their CRD, to azurevalidator.schema.json.
+// Run it with go generate, after regenerating the CRD.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/pkg/schema/internal/crdschema"
+)
+
+func main() {
+	data, err := os.ReadFile("../../config/crd/bases/validation.spectrocloud.labs_azurevalidators.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := crdschema.FromCRD(data, v1alpha1.GroupVersion.Version)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("azurevalidator.schema.json", out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,33 @@
+// Package crdschema extracts the schema of a version of a custom resource from its CRD.
+package crdschema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// FromCRD returns the OpenAPI v3 schema of a version of a custom resource, as indented JSON, from
+// its CRD in YAML.
+func FromCRD(data []byte, version string) ([]byte, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.UnmarshalStrict(data, crd); err != nil {
+		return nil, fmt.Errorf("failed to parse CRD: %w", err)
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			return nil, fmt.Errorf("version %s of CRD %s has no schema", version, crd.Name)
+		}
+		out, err := json.MarshalIndent(v.Schema.OpenAPIV3Schema, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	}
+	return nil, fmt.Errorf("CRD %s has no version %s", crd.Name, version)
+}
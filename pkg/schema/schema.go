@@ -0,0 +1,121 @@
+// Package schema exposes the schema of AzureValidators, and validates AzureValidator documents
+// without a cluster, for tools that generate them.
+package schema
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"sigs.k8s.io/yaml"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+)
+
+//go:generate go run gen.go
+
+// schemaJSON is the OpenAPI v3 schema of v1alpha1 AzureValidators, from their CRD.
+//
+//go:embed azurevalidator.schema.json
+var schemaJSON []byte
+
+// JSON returns the OpenAPI v3 schema of v1alpha1 AzureValidators, as JSON. It's the structural
+// schema of the AzureValidator CRD, including its x-kubernetes-validations CEL rules.
+func JSON() []byte {
+	return bytes.Clone(schemaJSON)
+}
+
+// validators validate AzureValidators against their schema, like the API server does.
+type validators struct {
+	structural *structuralschema.Structural
+	schema     validation.SchemaValidator
+	cel        *cel.Validator
+}
+
+var loadValidators = sync.OnceValues(func() (*validators, error) {
+	var props apiextensionsv1.JSONSchemaProps
+	if err := utiljson.Unmarshal(schemaJSON, &props); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	var internal apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&props, &internal, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+	structural, err := structuralschema.NewStructural(&internal)
+	if err != nil {
+		return nil, fmt.Errorf("schema isn't structural: %w", err)
+	}
+	schema, _, err := validation.NewSchemaValidator(&internal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema validator: %w", err)
+	}
+	return &validators{
+		structural: structural,
+		schema:     schema,
+		cel:        cel.NewValidator(structural, true, celconfig.PerCallLimit),
+	}, nil
+})
+
+// ValidateSpec validates an AzureValidator document, in YAML or JSON, like the API server does when
+// it's created: its defaults are applied, then it's validated against the schema returned by JSON,
+// then with the schema's CEL rules, and then with the validation that the AzureValidator webhook
+// applies. Each step only runs if the previous one found no errors. Unlike the API server, it also
+// rejects unknown fields rather than dropping them, like the lint subcommand. Returns nil if the
+// document is valid.
+func ValidateSpec(data []byte) []error {
+	v, err := loadValidators()
+	if err != nil {
+		return []error{err}
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return []error{err}
+	}
+	var obj map[string]any
+	if err := utiljson.Unmarshal(jsonData, &obj); err != nil {
+		return []error{err}
+	}
+	if obj["apiVersion"] != v1alpha1.GroupVersion.String() || obj["kind"] != "AzureValidator" {
+		return []error{fmt.Errorf("not a %s AzureValidator", v1alpha1.GroupVersion)}
+	}
+
+	// CEL rules are only evaluated once the document matches the schema, since they may assume
+	// that it does. Either way, the API server rejects the AzureValidator before the webhook sees
+	// it.
+	structuraldefaulting.Default(obj, v.structural)
+	if errs := validation.ValidateCustomResource(nil, obj, v.schema); len(errs) > 0 {
+		return toErrors(errs)
+	}
+	if errs, _ := v.cel.Validate(context.Background(), nil, v.structural, obj, nil, celconfig.RuntimeCELCostBudget); len(errs) > 0 {
+		return toErrors(errs)
+	}
+
+	validator := &v1alpha1.AzureValidator{}
+	if err := yaml.UnmarshalStrict(data, validator); err != nil {
+		return []error{err}
+	}
+	return toErrors(validator.Spec.Validate(field.NewPath("spec")))
+}
+
+func toErrors(errs field.ErrorList) []error {
+	if len(errs) == 0 {
+		return nil
+	}
+	out := make([]error, len(errs))
+	for i, e := range errs {
+		out[i] = e
+	}
+	return out
+}
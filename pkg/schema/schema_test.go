@@ -0,0 +1,231 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+	yamlv3 "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/spectrocloud-labs/validator-plugin-azure/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-azure/pkg/schema/internal/crdschema"
+)
+
+const crdPath = "../../config/crd/bases/validation.spectrocloud.labs_azurevalidators.yaml"
+
+func TestJSON_UpToDate(t *testing.T) {
+	data, err := os.ReadFile(crdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := crdschema.FromCRD(data, v1alpha1.GroupVersion.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(JSON(), want) {
+		t.Error("azurevalidator.schema.json is out of date with the CRD; run go generate ./pkg/schema")
+	}
+}
+
+// schemaValid validates a document against the schema returned by JSON with a generic OpenAPI
+// schema validator, as a tool that only has the schema would. CEL rules aren't evaluated.
+func schemaValid(t *testing.T, doc []byte) bool {
+	t.Helper()
+	s := &spec.Schema{}
+	if err := json.Unmarshal(JSON(), s); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	jsonDoc, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj any
+	if err := utiljson.Unmarshal(jsonDoc, &obj); err != nil {
+		t.Fatal(err)
+	}
+	return validate.NewSchemaValidator(s, nil, "", strfmt.Default).Validate(obj).IsValid()
+}
+
+func TestValidateSpec(t *testing.T) {
+	const header = `apiVersion: validation.spectrocloud.labs/v1alpha1
+kind: AzureValidator
+metadata:
+  name: azurevalidator
+spec:
+  auth:
+    implicit: true
+`
+	tests := []struct {
+		name string
+		doc  string
+		// wantSchemaValid is whether the document is valid against the schema alone, without its
+		// CEL rules.
+		wantSchemaValid bool
+		// wantErrors are substrings of the errors ValidateSpec returns, in order.
+		wantErrors []string
+	}{
+		{
+			name:            "Accepts a valid AzureValidator.",
+			wantSchemaValid: true,
+			doc: header + `  rbacRules:
+  - name: rule-1
+    principalId: a83574a7-53ef-4b37-b85e-99f956f0985a
+    permissionSets:
+    - scope: /subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745
+      actions:
+      - Microsoft.Compute/virtualMachines/read
+`,
+		},
+		{
+			name: "Rejects fields of the wrong type.",
+			doc: header + `  rbacRules: rule-1
+`,
+			wantErrors: []string{`spec.rbacRules: Invalid value: "string": spec.rbacRules in body must be of type array`},
+		},
+		{
+			name: "Rejects missing required fields.",
+			doc: header + `  rbacRules:
+  - principalId: a83574a7-53ef-4b37-b85e-99f956f0985a
+    permissionSets:
+    - scope: /subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745
+      actions:
+      - Microsoft.Compute/virtualMachines/read
+`,
+			wantErrors: []string{"spec.rbacRules[0].name: Required value"},
+		},
+		{
+			name: "Rejects values that aren't in an enum.",
+			doc: header + `  defenderPlanRules:
+  - name: rule-1
+    subscriptionId: 9b16dd0b-1bea-4c9a-a291-65e6f44c4745
+    plans:
+    - name: VirtualMachines
+      pricingTier: Premium
+`,
+			wantErrors: []string{`spec.defenderPlanRules[0].plans[0].pricingTier: Unsupported value: "Premium"`},
+		},
+		{
+			name:            "Rejects documents that break the schema's CEL rules.",
+			wantSchemaValid: true,
+			doc: header + `  rbacRules:
+  - name: rule-1
+    permissionSets:
+    - scope: /subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745
+      actions:
+      - Microsoft.Compute/virtualMachines/read
+`,
+			wantErrors: []string{"spec.rbacRules[0]: Invalid value: \"object\": principalId, principalIds, or principalClientId must be set"},
+		},
+		{
+			name:            "Rejects documents that the webhook rejects.",
+			wantSchemaValid: true,
+			doc: header + `  rbacRules:
+  - name: rule-1
+    principalIds:
+    - not-a-uuid
+    permissionSets:
+    - scope: subscriptions/9b16dd0b-1bea-4c9a-a291-65e6f44c4745
+      actions:
+      - Microsoft.Compute/virtualMachines/read
+`,
+			wantErrors: []string{"spec.rbacRules[0].principalIds[0]", "spec.rbacRules[0].permissionSets[0].scope"},
+		},
+		{
+			name:            "Rejects unknown fields.",
+			wantSchemaValid: true,
+			doc: header + `  rbacRule: []
+`,
+			wantErrors: []string{`unknown field "rbacRule"`},
+		},
+		{
+			name:            "Rejects documents that aren't AzureValidators.",
+			wantSchemaValid: true,
+			doc: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: rules
+`,
+			wantErrors: []string{"not a validation.spectrocloud.labs/v1alpha1 AzureValidator"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaValid(t, []byte(tt.doc)); got != tt.wantSchemaValid {
+				t.Errorf("got valid = %t against the schema, want %t", got, tt.wantSchemaValid)
+			}
+			errs := ValidateSpec([]byte(tt.doc))
+			if len(errs) != len(tt.wantErrors) {
+				t.Fatalf("got errors %v, want %d", errs, len(tt.wantErrors))
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tt.wantErrors[i]) {
+					t.Errorf("got error %q, want it to contain %q", err, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+// TestValidateSpec_Samples checks that the schema and ValidateSpec agree on the AzureValidators of
+// config/samples: the invalid ones are rejected, and the others are accepted by both.
+func TestValidateSpec_Samples(t *testing.T) {
+	paths, err := filepath.Glob("../../config/samples/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			invalid := strings.Contains(filepath.Base(path), "invalid")
+			for _, doc := range azureValidatorDocuments(t, data) {
+				schemaOK := schemaValid(t, doc)
+				errs := ValidateSpec(doc)
+				switch {
+				case !schemaOK && len(errs) == 0:
+					t.Error("ValidateSpec accepted a document that's invalid against the schema")
+				case invalid && len(errs) == 0:
+					t.Error("ValidateSpec accepted an invalid sample")
+				case !invalid && (!schemaOK || len(errs) > 0):
+					t.Errorf("sample is rejected: valid against the schema = %t, errors %v", schemaOK, errs)
+				}
+			}
+		})
+	}
+}
+
+// azureValidatorDocuments returns the AzureValidator documents of a file.
+func azureValidatorDocuments(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var docs [][]byte
+	dec := yamlv3.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]any
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if doc["kind"] != "AzureValidator" {
+			continue
+		}
+		out, err := yamlv3.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, out)
+	}
+	return docs
+}